@@ -0,0 +1,154 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/karcli/config"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/karcli/job"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/karcli/queue"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/karcli/version"
+)
+
+// arbctl submits batch jobs to kar-scheduler, e.g.
+//
+//	arbctl run --name train --image tensorflow/tensorflow --replicas 4 --min-available 4 --queue research
+func main() {
+	rootCmd := cobra.Command{
+		Use:   "arbctl",
+		Short: "Submit batch jobs to kar-scheduler",
+	}
+
+	runCmd := &cobra.Command{
+		Use:   "run",
+		Short: "Create a QueueJob, and wait for its gang to start",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := job.SubmitJob(cmd.Flags()); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to run job: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	job.InitSubmitFlags(runCmd)
+	rootCmd.AddCommand(runCmd)
+
+	describeCmd := &cobra.Command{
+		Use:   "describe",
+		Short: "Show the details of jobs",
+	}
+
+	describeJobCmd := &cobra.Command{
+		Use:   "job <name>",
+		Short: "Show the status, unschedulable reasons by node and events of a job",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := job.DescribeJob(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to describe job: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	job.InitDescribeFlags(describeJobCmd)
+	describeCmd.AddCommand(describeJobCmd)
+	rootCmd.AddCommand(describeCmd)
+
+	queueCmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Manage the queues of kar-scheduler",
+	}
+
+	drainCmd := &cobra.Command{
+		Use:   "drain <name>",
+		Short: "Close a queue, and wait out or evict its running gangs",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := queue.DrainQueue(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to drain queue: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	queue.InitDrainFlags(drainCmd)
+	queueCmd.AddCommand(drainCmd)
+	rootCmd.AddCommand(queueCmd)
+
+	topCmd := &cobra.Command{
+		Use:   "top",
+		Short: "Show the resources of queues or jobs",
+	}
+
+	topQueuesCmd := &cobra.Command{
+		Use:   "queues",
+		Short: "Show the deserved and allocated resources of queues",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := queue.TopQueues(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to top queues: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	queue.InitTopFlags(topQueuesCmd)
+	topCmd.AddCommand(topQueuesCmd)
+
+	topJobsCmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Show the requested and allocated resources of jobs",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := job.TopJobs(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to top jobs: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	job.InitTopFlags(topJobsCmd)
+	topCmd.AddCommand(topJobsCmd)
+	rootCmd.AddCommand(topCmd)
+
+	versionCmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show the version of arbctl and kar-scheduler, and the policy of kar-scheduler",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := version.ShowVersion(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to show version: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	version.InitVersionFlags(versionCmd)
+	rootCmd.AddCommand(versionCmd)
+
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check the actions and plugins of a scheduler config",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := config.ValidateConfig(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to validate scheduler config: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	config.InitValidateFlags(validateCmd)
+	rootCmd.AddCommand(validateCmd)
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -17,13 +17,38 @@ limitations under the License.
 package options
 
 import (
+	"time"
+
+	"github.com/golang/glog"
 	"github.com/spf13/pflag"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
 )
 
 // ServerOption is the main context object for the controller manager.
 type ServerOption struct {
-	Master     string
-	Kubeconfig string
+	Master              string
+	Kubeconfig          string
+	KubeAPIQPS          float32
+	KubeAPIBurst        int
+	KubeAPIContentType  string
+	LeaderElect         bool
+	LockObjectNamespace string
+	SchedulerName       string
+	GroupNameLabel      string
+	NamespaceQueues     []string
+	EnablePDB           bool
+	EnableKubeflow      bool
+	FederationMembers   string
+	EnableRebalancer    bool
+	RebalancePeriod     time.Duration
+	RebalanceMaxGangs   int
+	ListenAddress       string
+	EnablePprof         bool
+
+	AdmissionListenAddress string
+	AdmissionTLSCertFile   string
+	AdmissionTLSKeyFile    string
 }
 
 // NewServerOption creates a new CMServer with a default config.
@@ -36,8 +61,49 @@ func NewServerOption() *ServerOption {
 func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.Master, "master", s.Master, "The address of the Kubernetes API server (overrides any value in kubeconfig)")
 	fs.StringVar(&s.Kubeconfig, "kubeconfig", s.Kubeconfig, "Path to kubeconfig file with authorization and master location information.")
+	fs.Float32Var(&s.KubeAPIQPS, "kube-api-qps", 50, "The QPS to use while talking with the Kubernetes API server")
+	fs.IntVar(&s.KubeAPIBurst, "kube-api-burst", 100, "The burst to use while talking with the Kubernetes API server")
+	fs.StringVar(&s.KubeAPIContentType, "kube-api-content-type", "application/vnd.kubernetes.protobuf", "The content "+
+		"type of requests sent to the Kubernetes API server; the custom resources are always sent in JSON")
+	fs.BoolVar(&s.LeaderElect, "leader-elect", s.LeaderElect, "Start a leader election client and gain leadership before "+
+		"executing the main loop. Enable this when running replicated kar-controllers for high availability.")
+	fs.StringVar(&s.LockObjectNamespace, "lock-object-namespace", "kube-system", "Define the namespace of the lock object.")
+	// the schedulerName set to the pods of gang workloads
+	fs.StringVar(&s.SchedulerName, "scheduler-name", "kar-scheduler", "The scheduler name set to the pods of workloads annotated as gang")
+	// pods without controller are grouped into one job by the value of this label
+	fs.StringVar(&s.GroupNameLabel, "group-name-label", api.DefaultGroupNameLabel, "The label to group pods "+
+		"without controller into one job, the same as kar-scheduler's; empty to disable label grouping")
+	fs.StringSliceVar(&s.NamespaceQueues, "namespace-queues", s.NamespaceQueues, "The Queue of the workloads "+
+		"in namespaces in the form of <namespace>=<queue>, which overrides the queue of their SchedulingSpecs")
+	fs.BoolVar(&s.EnablePDB, "enable-pdb", s.EnablePDB, "Create PodDisruptionBudget for each SchedulingSpec by its minAvailable")
+	fs.BoolVar(&s.EnableKubeflow, "enable-kubeflow", s.EnableKubeflow, "Create SchedulingSpec for the TFJobs and "+
+		"MPIJobs whose pods are scheduled by scheduler-name")
+	fs.StringVar(&s.FederationMembers, "federation-members-file", s.FederationMembers, "The file of member clusters "+
+		"in YAML or JSON; if set, it runs in federation mode which dispatches the QueueJobs to member clusters, "+
+		"and other controllers are not started")
+	fs.BoolVar(&s.EnableRebalancer, "enable-rebalancer", s.EnableRebalancer, "Mark the running gangs whose placement "+
+		"is degraded, e.g. fragmented, to be re-scheduled by the shuffle action of kar-scheduler")
+	fs.DurationVar(&s.RebalancePeriod, "rebalance-period", 10*time.Minute, "The interval between two placement checks of rebalancer")
+	fs.IntVar(&s.RebalanceMaxGangs, "rebalance-max-gangs", 1, "The maximal number of gangs marked to re-schedule in "+
+		"a placement check; 0 means no limit")
+	fs.StringVar(&s.ListenAddress, "listen-address", ":8081", "The address to listen on for HTTP requests if enable-pprof is set")
+	fs.BoolVar(&s.EnablePprof, "enable-pprof", s.EnablePprof, "Enable the pprof and expvar handlers under /debug/ on listen-address")
+	fs.StringVar(&s.AdmissionListenAddress, "admission-listen-address", ":8443", "The address to serve the admission "+
+		"webhook on if admission-tls-cert-file is set")
+	fs.StringVar(&s.AdmissionTLSCertFile, "admission-tls-cert-file", s.AdmissionTLSCertFile, "The TLS certificate of "+
+		"the admission webhook, which rejects the pods and QueueJobs exceeding the remaining capability of their Queue "+
+		"at /validate; the webhook is not served if it's not set")
+	fs.StringVar(&s.AdmissionTLSKeyFile, "admission-tls-key-file", s.AdmissionTLSKeyFile, "The TLS key of the admission webhook")
 }
 
 func (s *ServerOption) CheckOptionOrDie() {
-
+	if s.KubeAPIQPS <= 0 || s.KubeAPIBurst <= 0 {
+		glog.Fatalf("kube-api-qps and kube-api-burst should be positive")
+	}
+	if s.EnableRebalancer && (s.RebalancePeriod <= 0 || s.RebalanceMaxGangs < 0) {
+		glog.Fatalf("rebalance-period should be positive and rebalance-max-gangs should not be negative")
+	}
+	if (len(s.AdmissionTLSCertFile) == 0) != (len(s.AdmissionTLSKeyFile) == 0) {
+		glog.Fatalf("admission-tls-cert-file and admission-tls-key-file should be set together")
+	}
 }
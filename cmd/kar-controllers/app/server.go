@@ -17,11 +17,31 @@ limitations under the License.
 package app
 
 import (
+	"fmt"
+	"net/http"
+
+	"github.com/golang/glog"
+
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/kubernetes-incubator/kube-arbitrator/cmd/kar-controllers/app/options"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/admission"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/cronqueuejob"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/federation"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/garbagecollector"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/kubeflow"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/pdb"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/queue"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/queuejob"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/rebalancer"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/workload"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/leaderelection"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/profiling"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 )
@@ -38,13 +58,118 @@ func Run(opt *options.ServerOption) error {
 	if err != nil {
 		return err
 	}
+	config.QPS = opt.KubeAPIQPS
+	config.Burst = opt.KubeAPIBurst
+	config.ContentType = opt.KubeAPIContentType
+
+	if utils.NamespaceQueues, err = utils.ParseNamespaceQueues(opt.NamespaceQueues); err != nil {
+		return err
+	}
+	// The gangs are grouped into jobs the same way as kar-scheduler, e.g. by
+	// garbage collector.
+	api.GroupNameLabel = opt.GroupNameLabel
 
-	neverStop := make(chan struct{})
+	if opt.EnablePprof {
+		go startHTTPServer(opt.ListenAddress)
+	}
+
+	if len(opt.FederationMembers) != 0 {
+		return runFederation(opt, config)
+	}
+
+	// The admission webhook is served by all replicas, whether leading or not.
+	if len(opt.AdmissionTLSCertFile) != 0 {
+		wh := admission.NewWebhook(config, opt.GroupNameLabel)
+		go wh.Run(opt.AdmissionListenAddress, opt.AdmissionTLSCertFile, opt.AdmissionTLSKeyFile, make(chan struct{}))
+	}
 
 	queuejobctrl := queuejob.NewQueueJobController(config)
-	queuejobctrl.Run(neverStop)
+	gc := garbagecollector.NewGarbageCollector(config)
+	workloadctrl := workload.NewWorkloadController(config, opt.SchedulerName)
+	queuectrl := queue.NewQueueController(config, opt.GroupNameLabel)
+	cronqueuejobctrl := cronqueuejob.NewCronQueueJobController(config)
+
+	var pdbctrl *pdb.Controller
+	if opt.EnablePDB {
+		pdbctrl = pdb.NewPDBController(config)
+	}
+
+	var kubeflowctrl *kubeflow.Controller
+	if opt.EnableKubeflow {
+		kubeflowctrl = kubeflow.NewKubeflowController(config, opt.SchedulerName)
+	}
+
+	var rb *rebalancer.Rebalancer
+	if opt.EnableRebalancer {
+		rb = rebalancer.NewRebalancer(config, opt.RebalancePeriod, opt.RebalanceMaxGangs)
+	}
+
+	run := func(stopCh <-chan struct{}) {
+		queuejobctrl.Run(stopCh)
+		go gc.Run(stopCh)
+		go workloadctrl.Run(stopCh)
+		go queuectrl.Run(stopCh)
+		go cronqueuejobctrl.Run(stopCh)
+		if pdbctrl != nil {
+			go pdbctrl.Run(stopCh)
+		}
+		if kubeflowctrl != nil {
+			go kubeflowctrl.Run(stopCh)
+		}
+		if rb != nil {
+			go rb.Run(stopCh)
+		}
+		<-stopCh
+	}
+
+	return runLeading(opt, config, run)
+}
+
+// runFederation runs federation controller only, which dispatches the
+// QueueJobs in the cluster to member clusters.
+func runFederation(opt *options.ServerOption, config *rest.Config) error {
+	members, err := federation.LoadMembers(opt.FederationMembers)
+	if err != nil {
+		return err
+	}
+
+	fc, err := federation.NewFederationController(config, members)
+	if err != nil {
+		return err
+	}
+	glog.Infof("Run in federation mode with %d member clusters", len(members))
+
+	return runLeading(opt, config, func(stopCh <-chan struct{}) {
+		fc.Run(stopCh)
+		<-stopCh
+	})
+}
+
+// runLeading calls run after gaining leadership if leader election is enabled.
+func runLeading(opt *options.ServerOption, config *rest.Config, run func(stopCh <-chan struct{})) error {
+	if !opt.LeaderElect {
+		run(make(chan struct{}))
+		return fmt.Errorf("finished without leader elect")
+	}
+
+	leConfig, err := leaderelection.NewConfig(kubernetes.NewForConfigOrDie(config),
+		opt.LockObjectNamespace, "kar-controllers")
+	if err != nil {
+		return err
+	}
+	leConfig.OnStartedLeading = run
+	leConfig.OnStoppedLeading = func() {
+		glog.Fatalf("leaderelection lost")
+	}
+
+	leaderelection.Run(leConfig)
+	return fmt.Errorf("lost lease")
+}
 
-	<-neverStop
+func startHTTPServer(address string) {
+	mux := http.NewServeMux()
+	profiling.Install(mux)
+	mux.Handle("/debug/flags/v", logging.VerbosityHandler())
 
-	return nil
+	glog.Fatalf("Failed to serve HTTP on %s: %v", address, http.ListenAndServe(address, mux))
 }
@@ -17,14 +17,74 @@ limitations under the License.
 package options
 
 import (
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
 	"github.com/spf13/pflag"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/actions/garantee"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	schedcache "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/cache"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/plugins/binpack"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/plugins/usage"
 )
 
 // ServerOption is the main context object for the controller manager.
 type ServerOption struct {
-	Master        string
-	Kubeconfig    string
-	SchedulerName string
+	Master                   string
+	Kubeconfig               string
+	KubeAPIQPS               float32
+	KubeAPIBurst             int
+	KubeAPIContentType       string
+	LeaderElect              bool
+	WarmStandby              bool
+	LockObjectNamespace      string
+	SchedulerName            string
+	GroupNameLabel           string
+	NamespaceQueues          []string
+	NodePoolLabel            string
+	SystemReserved           string
+	PercentageOfNodesToScore int32
+	ListenAddress            string
+	EnablePprof              bool
+	TracingEndpoint          string
+	AuditLogPath             string
+	AuditLogMaxSize          int
+	AuditLogMaxBackup        int
+	EmitPlanFile             string
+	Profile                  string
+	TraceFile                string
+	WarmRestartFile          string
+	WarmRestartMaxAge        time.Duration
+	ValidateAccounting       bool
+	APIWriteWorkers          int
+	APIWriteQPS              float32
+	APIWriteBurst            int
+	ListPageSize             int64
+	ResyncPeriod             time.Duration
+	Namespace                string
+	ExtenderPolicyFile       string
+	EnableBinpack            bool
+	SimulatedNodesFile       string
+	BinpackGPUWeight         float64
+	EnableUsageScoring       bool
+	RuntimeClassesFile       string
+	NodeHeartbeatGracePeriod time.Duration
+	UsagePullPeriod          time.Duration
+	SchedulePeriod           time.Duration
+	EventDrivenSessions      bool
+	MaxSessionInterval       time.Duration
+	SessionDebounce          time.Duration
+	SessionMaxWait           time.Duration
+	SchedulerConfigFile      string
+	ConfigReloadPeriod       time.Duration
+	GangTimeout              time.Duration
+	GangReleaseRunning       bool
+	GangResolveDeadlock      bool
+	NodeFailureToleration    time.Duration
 }
 
 // NewServerOption creates a new CMServer with a default config.
@@ -37,10 +97,167 @@ func NewServerOption() *ServerOption {
 func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.Master, "master", s.Master, "The address of the Kubernetes API server (overrides any value in kubeconfig)")
 	fs.StringVar(&s.Kubeconfig, "kubeconfig", s.Kubeconfig, "Path to kubeconfig file with authorization and master location information.")
+	fs.Float32Var(&s.KubeAPIQPS, "kube-api-qps", 50, "The QPS to use while talking with the Kubernetes API server")
+	fs.IntVar(&s.KubeAPIBurst, "kube-api-burst", 100, "The burst to use while talking with the Kubernetes API server")
+	fs.StringVar(&s.KubeAPIContentType, "kube-api-content-type", "application/vnd.kubernetes.protobuf", "The content "+
+		"type of requests sent to the Kubernetes API server; the custom resources are always sent in JSON")
+	fs.BoolVar(&s.LeaderElect, "leader-elect", s.LeaderElect, "Start a leader election client and gain leadership before "+
+		"executing the main loop. Enable this when running replicated kar-scheduler for high availability.")
+	fs.BoolVar(&s.WarmStandby, "warm-standby", s.WarmStandby, "Keep the cache of kar-scheduler synced "+
+		"without leadership, so it starts scheduling in seconds once it becomes leader; only used with leader-elect.")
+	fs.StringVar(&s.LockObjectNamespace, "lock-object-namespace", "kube-system", "Define the namespace of the lock object.")
 	// kube-arbitrator will ignore pods with scheduler names other than specified with the option
 	fs.StringVar(&s.SchedulerName, "scheduler-name", "kar-scheduler", "kube-arbitrator will handle pods with the scheduler-name")
+	// pods without controller are grouped into one job by the value of this label
+	fs.StringVar(&s.GroupNameLabel, "group-name-label", api.DefaultGroupNameLabel,
+		"The label to group pods without controller into one job, empty to disable label grouping")
+	fs.StringSliceVar(&s.NamespaceQueues, "namespace-queues", s.NamespaceQueues, "The Queue of the workloads "+
+		"in namespaces in the form of <namespace>=<queue>, which overrides the queue of their SchedulingSpecs")
+	fs.StringVar(&s.NodePoolLabel, "node-pool-label", s.NodePoolLabel, "The label of nodes whose value is the node pool; "+
+		"node metrics are aggregated by pool instead of exported per node if set")
+	fs.StringVar(&s.SystemReserved, "system-reserved", s.SystemReserved, "The resources subtracted "+
+		"from the allocatable resources of each node, e.g. cpu=500m,memory=1Gi for the daemons not run as pods")
+	fs.Int32Var(&s.PercentageOfNodesToScore, "percentage-of-nodes-to-score", framework.DefaultPercentageOfNodesToScore,
+		"The percentage of all nodes that, once found feasible for a task, the scheduler stops searching more "+
+			"feasible nodes in large clusters; 0 or 100 means all nodes")
+	fs.StringVar(&s.ListenAddress, "listen-address", ":8080", "The address to listen on for HTTP requests, e.g. /metrics and /debug/flags/v")
+	fs.StringVar(&s.TracingEndpoint, "tracing-endpoint", s.TracingEndpoint, "The Zipkin v2 API to report the spans of "+
+		"scheduling sessions, e.g. http://zipkin:9411/api/v2/spans; empty to disable tracing")
+	fs.StringVar(&s.AuditLogPath, "audit-log-path", s.AuditLogPath, "If set, all scheduling decisions are written "+
+		"to this file in JSON, one decision per line")
+	fs.IntVar(&s.AuditLogMaxSize, "audit-log-maxsize", 100, "The maximum size in megabytes of the audit log file before it gets rotated")
+	fs.IntVar(&s.AuditLogMaxBackup, "audit-log-maxbackup", 3, "The maximum number of rotated audit log files to retain")
+	fs.StringVar(&s.EmitPlanFile, "emit-plan", s.EmitPlanFile, "If set, the task to node assignments and "+
+		"evictions of each session are written to this file in JSON before they're executed")
+	fs.StringVar(&s.Profile, "profile", s.Profile, "The built-in policy profile, one of "+
+		strings.Join(scheduler.ProfileNames(), ", ")+"; it presets the actions, plugins and the flags not set")
+	fs.StringVar(&s.TraceFile, "trace-file", s.TraceFile, "If set, the inputs and decisions of each session are "+
+		"appended to this file as JSON lines, gzipped if it ends with .gz, to be replayed by kar-simulator")
+	fs.StringVar(&s.WarmRestartFile, "warm-restart-file", s.WarmRestartFile, "If set, the binds, evictions "+
+		"and nominations not written to apiserver are exported to this file on SIGTERM, and imported on start")
+	fs.DurationVar(&s.WarmRestartMaxAge, "warm-restart-max-age", scheduler.WarmRestartMaxAge, "The maximum "+
+		"age of the state in warm-restart-file to import")
+	fs.DurationVar(&s.SchedulePeriod, "schedule-period", scheduler.SchedulePeriod, "The interval between "+
+		"scheduling sessions if event-driven-sessions is disabled")
+	fs.BoolVar(&s.EventDrivenSessions, "event-driven-sessions", scheduler.EventDrivenSessions, "Trigger scheduling "+
+		"sessions by events, e.g. a job is added or resources are released, instead of every schedule-period")
+	fs.DurationVar(&s.MaxSessionInterval, "max-session-interval", scheduler.MaxSessionInterval, "The maximum "+
+		"interval between event driven sessions, e.g. to retry unschedulable jobs without any event")
+	fs.DurationVar(&s.SessionDebounce, "session-debounce", scheduler.SessionDebounce, "The time to wait for "+
+		"more events after an event before starting an event driven session")
+	fs.DurationVar(&s.SessionMaxWait, "session-max-wait", scheduler.SessionMaxWait, "The maximum time to wait "+
+		"for more events after the first event before starting an event driven session")
+	fs.StringVar(&s.SchedulerConfigFile, "scheduler-config", s.SchedulerConfigFile, "The file of actions and "+
+		"disabled plugins in YAML or JSON, e.g. a mounted ConfigMap; it's reloaded on change and applied at the "+
+		"next session. All actions and plugins are used if empty")
+	fs.DurationVar(&s.ConfigReloadPeriod, "scheduler-config-reload-period", scheduler.ConfigReloadPeriod,
+		"The interval between two checks of scheduler-config for change")
+	fs.DurationVar(&s.GangTimeout, "gang-timeout", garantee.GangTimeout, "If positive, the tasks of a gang "+
+		"which holds resources for part of its minAvailable tasks longer than this while the rest can not be "+
+		"placed are evicted, so the gang is placed again as a whole")
+	fs.BoolVar(&s.GangReleaseRunning, "gang-release-running", garantee.ReleaseRunning, "Also evict the bound "+
+		"and running tasks of a started gang by gang-timeout or deadlock, e.g. a running gang whose recreated "+
+		"task does not fit; only its tasks not bound yet are evicted if false")
+	fs.BoolVar(&s.GangResolveDeadlock, "gang-resolve-deadlock", garantee.ResolveDeadlock, "Evict the tasks "+
+		"of the partially allocated gangs which wait for the resources held by each other in every session, "+
+		"without waiting for gang-timeout")
+	fs.IntVar(&s.APIWriteWorkers, "api-write-workers", schedcache.DispatchWorkers, "The maximum number of "+
+		"concurrent API writes, e.g. binding pods, updating status and evicting pods")
+	fs.Float32Var(&s.APIWriteQPS, "api-write-qps", schedcache.DispatchQPS, "The maximum QPS of API writes")
+	fs.IntVar(&s.APIWriteBurst, "api-write-burst", schedcache.DispatchBurst, "The maximum burst of API writes")
+	fs.Int64Var(&s.ListPageSize, "list-page-size", schedcache.ListPageSize, "The maximum number of pods "+
+		"in a page of the initial list, so large clusters are listed in chunks; 0 to list all pods at once")
+	fs.DurationVar(&s.ResyncPeriod, "resync-period", schedcache.ResyncPeriod, "The interval to resync the "+
+		"informers of scheduler cache; 0 to disable resync")
+	fs.StringVar(&s.Namespace, "namespace", schedcache.Namespace, "The namespace whose pods are scheduled, "+
+		"all namespaces if empty; the pods of other namespaces are still accounted on their nodes")
+	fs.StringVar(&s.ExtenderPolicyFile, "extender-policy-file", s.ExtenderPolicyFile, "The policy file of "+
+		"kube-scheduler in JSON whose extenders are called to filter, score and bind; other parts are ignored")
+	fs.BoolVar(&s.EnableBinpack, "enable-binpack", binpack.Enabled, "Score nodes by their utilization after "+
+		"placing a task, so tasks are packed onto fewer nodes; the tasks without GPU avoid the nodes with idle GPUs")
+	fs.Float64Var(&s.BinpackGPUWeight, "binpack-gpu-weight", binpack.GPUWeight, "The weight of GPU "+
+		"relative to CPU and memory when scoring nodes by binpack")
+	fs.BoolVar(&s.EnableUsageScoring, "enable-usage-scoring", usage.Enabled, "Pull the usage of nodes from "+
+		"metrics-server, and score nodes by their actual load besides the requests of pods")
+	fs.DurationVar(&s.UsagePullPeriod, "usage-pull-period", usage.PullPeriod, "The interval between two pulls "+
+		"of node usage from metrics-server if enable-usage-scoring is set")
+	fs.DurationVar(&s.NodeHeartbeatGracePeriod, "node-heartbeat-grace-period", schedcache.NodeHeartbeatGracePeriod,
+		"The age of the last heartbeat of node after which no task is placed on it, before node controller marks "+
+			"it NotReady; 0 to disable")
+	fs.DurationVar(&s.NodeFailureToleration, "node-failure-toleration", api.NodeFailureToleration, "If positive, "+
+		"how long the pods tolerate the NoExecute taints of nodes not ready or unreachable, bounding their "+
+		"tolerationSeconds; no task is placed on such nodes, and the gangs broken by them are re-scheduled after it")
+	fs.StringVar(&s.RuntimeClassesFile, "runtime-classes-file", s.RuntimeClassesFile, "The file of RuntimeClasses "+
+		"in YAML or JSON, whose pod overhead is added to the requests of the pods annotated by "+
+		api.RuntimeClassAnnotation)
+	fs.StringVar(&s.SimulatedNodesFile, "simulated-nodes-file", s.SimulatedNodesFile, "The file of node "+
+		"templates in YAML or JSON, whose nodes are added besides the nodes of cluster for scale testing; "+
+		"the pods are assumed on them without binding")
+	fs.BoolVar(&s.ValidateAccounting, "validate-resource-accounting", s.ValidateAccounting, "Validate the aggregated "+
+		"resources of nodes and jobs against their tasks on every change; it's expensive and only for debugging")
+	fs.BoolVar(&s.EnablePprof, "enable-pprof", s.EnablePprof, "Enable the pprof and expvar handlers under /debug/ on listen-address")
+}
+
+// ApplyProfileOrDie sets the flags of the profile which are not set on
+// command line.
+func (s *ServerOption) ApplyProfileOrDie(fs *pflag.FlagSet) {
+	if len(s.Profile) == 0 {
+		return
+	}
+
+	profile, err := scheduler.GetProfile(s.Profile)
+	if err != nil {
+		glog.Fatalf("%v", err)
+	}
+	for name, value := range profile.Flags {
+		if fs.Changed(name) {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			glog.Fatalf("Failed to set %s of profile %s: %v", name, s.Profile, err)
+		}
+	}
 }
 
 func (s *ServerOption) CheckOptionOrDie() {
+	if s.KubeAPIQPS <= 0 || s.KubeAPIBurst <= 0 {
+		glog.Fatalf("kube-api-qps and kube-api-burst should be positive")
+	}
+	if s.PercentageOfNodesToScore < 0 || s.PercentageOfNodesToScore > 100 {
+		glog.Fatalf("percentage-of-nodes-to-score %d should be in [0, 100]", s.PercentageOfNodesToScore)
+	}
+	if s.APIWriteWorkers <= 0 || s.APIWriteQPS <= 0 || s.APIWriteBurst <= 0 {
+		glog.Fatalf("api-write-workers, api-write-qps and api-write-burst should be positive")
+	}
+	if s.SchedulePeriod <= 0 || s.MaxSessionInterval <= 0 || s.SessionDebounce <= 0 || s.SessionMaxWait <= 0 {
+		glog.Fatalf("schedule-period, max-session-interval, session-debounce and session-max-wait should be positive")
+	}
+	if s.ResyncPeriod < 0 {
+		glog.Fatalf("resync-period should not be negative")
+	}
+	if s.ListPageSize < 0 {
+		glog.Fatalf("list-page-size %d should not be negative", s.ListPageSize)
+	}
+	if s.BinpackGPUWeight < 0 {
+		glog.Fatalf("binpack-gpu-weight %v should not be negative", s.BinpackGPUWeight)
+	}
+	if s.NodeHeartbeatGracePeriod < 0 {
+		glog.Fatalf("node-heartbeat-grace-period should not be negative")
+	}
+	if s.EnableUsageScoring && s.UsagePullPeriod <= 0 {
+		glog.Fatalf("usage-pull-period should be positive")
+	}
+	if len(s.SchedulerConfigFile) != 0 && s.ConfigReloadPeriod <= 0 {
+		glog.Fatalf("scheduler-config-reload-period should be positive")
+	}
+	if s.WarmRestartMaxAge <= 0 {
+		glog.Fatalf("warm-restart-max-age should be positive")
+	}
+	if s.GangTimeout < 0 {
+		glog.Fatalf("gang-timeout should not be negative")
+	}
+	if s.NodeFailureToleration < 0 {
+		glog.Fatalf("node-failure-toleration should not be negative")
+	}
 
 }
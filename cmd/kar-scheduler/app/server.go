@@ -17,11 +17,33 @@ limitations under the License.
 package app
 
 import (
+	"fmt"
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/spf13/pflag"
+
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/kubernetes-incubator/kube-arbitrator/cmd/kar-scheduler/app/options"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/leaderelection"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/profiling"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/actions/garantee"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/audit"
+	schedcache "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/cache"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/extender"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/metrics"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/plugins/binpack"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/plugins/usage"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/tracing"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/version"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 )
@@ -34,12 +56,106 @@ func buildConfig(master, kubeconfig string) (*rest.Config, error) {
 }
 
 func Run(opt *options.ServerOption) error {
+	logging.Info("Starting kar-scheduler", "version", version.Get().String())
+
 	config, err := buildConfig(opt.Master, opt.Kubeconfig)
 	if err != nil {
 		return err
 	}
+	config.QPS = opt.KubeAPIQPS
+	config.Burst = opt.KubeAPIBurst
+	config.ContentType = opt.KubeAPIContentType
+
+	if utils.NamespaceQueues, err = utils.ParseNamespaceQueues(opt.NamespaceQueues); err != nil {
+		return err
+	}
+
+	systemReserved, err := api.ParseResourceList(opt.SystemReserved)
+	if err != nil {
+		return fmt.Errorf("invalid system-reserved %q: %v", opt.SystemReserved, err)
+	}
+	if len(systemReserved) != 0 {
+		api.SystemReserved = api.NewResource(systemReserved)
+	}
+
+	api.GroupNameLabel = opt.GroupNameLabel
+	api.NodePoolLabel = opt.NodePoolLabel
+	api.ValidateAccounting = opt.ValidateAccounting
+	schedcache.DispatchWorkers = opt.APIWriteWorkers
+	schedcache.DispatchQPS = opt.APIWriteQPS
+	schedcache.DispatchBurst = opt.APIWriteBurst
+	schedcache.ListPageSize = opt.ListPageSize
+	schedcache.ResyncPeriod = opt.ResyncPeriod
+	schedcache.Namespace = opt.Namespace
+	schedcache.NodeHeartbeatGracePeriod = opt.NodeHeartbeatGracePeriod
+	scheduler.SchedulePeriod = opt.SchedulePeriod
+	scheduler.EventDrivenSessions = opt.EventDrivenSessions
+	scheduler.MaxSessionInterval = opt.MaxSessionInterval
+	scheduler.SessionDebounce = opt.SessionDebounce
+	scheduler.SessionMaxWait = opt.SessionMaxWait
+	scheduler.ConfigFile = opt.SchedulerConfigFile
+	scheduler.ConfigReloadPeriod = opt.ConfigReloadPeriod
+	scheduler.PlanFile = opt.EmitPlanFile
+	scheduler.TraceFile = opt.TraceFile
+	scheduler.WarmRestartFile = opt.WarmRestartFile
+	scheduler.WarmRestartMaxAge = opt.WarmRestartMaxAge
+	if len(opt.Profile) != 0 {
+		profile, err := scheduler.GetProfile(opt.Profile)
+		if err != nil {
+			return err
+		}
+		if err := profile.Apply(); err != nil {
+			return err
+		}
+	}
+	framework.PercentageOfNodesToScore = opt.PercentageOfNodesToScore
+	garantee.GangTimeout = opt.GangTimeout
+	garantee.ReleaseRunning = opt.GangReleaseRunning
+	garantee.ResolveDeadlock = opt.GangResolveDeadlock
+	api.NodeFailureToleration = opt.NodeFailureToleration
+	binpack.Enabled = opt.EnableBinpack
+	binpack.GPUWeight = opt.BinpackGPUWeight
+	usage.Enabled = opt.EnableUsageScoring
+	usage.PullPeriod = opt.UsagePullPeriod
+
+	if len(opt.ExtenderPolicyFile) != 0 {
+		configs, err := extender.LoadPolicy(opt.ExtenderPolicyFile)
+		if err != nil {
+			return err
+		}
+		extender.SetExtenders(configs)
+		if binder := extender.Binder(); binder != nil {
+			schedcache.BindExtender = binder
+		}
+	}
+
+	if len(opt.RuntimeClassesFile) != 0 {
+		if api.RuntimeClassOverheads, err = api.LoadRuntimeClassOverheads(opt.RuntimeClassesFile); err != nil {
+			return err
+		}
+	}
+
+	if len(opt.SimulatedNodesFile) != 0 {
+		nodes, err := schedcache.LoadSimulatedNodes(opt.SimulatedNodesFile)
+		if err != nil {
+			return err
+		}
+		logging.Warning("Add simulated nodes into cache, pods are not bound to them",
+			"file", opt.SimulatedNodesFile, "nodes", len(nodes))
+		schedcache.SimulatedNodes = nodes
+	}
+
+	if len(opt.TracingEndpoint) != 0 {
+		tracing.SetExporter(tracing.NewZipkinExporter(opt.TracingEndpoint, opt.SchedulerName))
+	}
 
-	neverStop := make(chan struct{})
+	if len(opt.AuditLogPath) != 0 {
+		sink, err := audit.NewFileSink(opt.AuditLogPath, int64(opt.AuditLogMaxSize)*1024*1024, opt.AuditLogMaxBackup)
+		if err != nil {
+			return err
+		}
+		audit.SetSink(sink)
+	}
 
 	// Start policy controller to allocate resources.
 	sched, err := scheduler.NewScheduler(config, opt.SchedulerName)
@@ -47,9 +163,70 @@ func Run(opt *options.ServerOption) error {
 		panic(err)
 	}
 
-	sched.Run(neverStop)
+	go startHTTPServer(opt, sched)
+	go sched.HandleDumpSignals()
+	if len(opt.WarmRestartFile) != 0 {
+		go sched.HandleTerminationSignals()
+	}
+
+	run := func(stopCh <-chan struct{}) {
+		if usage.Enabled {
+			usage.Run(config, stopCh)
+		}
+		sched.Run(stopCh)
+		<-stopCh
+	}
+
+	if !opt.LeaderElect {
+		run(make(chan struct{}))
+		return fmt.Errorf("finished without leader elect")
+	}
 
-	<-neverStop
+	leConfig, err := leaderelection.NewConfig(kubernetes.NewForConfigOrDie(config),
+		opt.LockObjectNamespace, "kar-scheduler")
+	if err != nil {
+		return err
+	}
+	leConfig.OnStartedLeading = run
+	leConfig.OnStoppedLeading = func() {
+		glog.Fatalf("leaderelection lost")
+	}
+
+	// The cache of standby keeps running after it becomes leader, and the
+	// process exits once the leadership is lost.
+	if opt.WarmStandby {
+		sched.WarmUp(make(chan struct{}))
+	}
+
+	leaderelection.Run(leConfig)
+	return fmt.Errorf("lost lease")
+}
+
+func startHTTPServer(opt *options.ServerOption, sched *scheduler.Scheduler) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/healthz", sched.HealthzHandler())
+	mux.Handle("/readyz", sched.ReadyzHandler())
+	mux.Handle("/federation/capacity", sched.CapacityHandler())
+	mux.Handle("/debug/unschedulable", sched.UnschedulableHandler())
+	mux.Handle("/debug/defragmentation", sched.DefragHandler())
+	mux.Handle("/debug/last-session", sched.LastSessionHandler())
+	mux.Handle("/debug/cluster-state", sched.ClusterStateHandler())
+	mux.Handle("/debug/flags/v", logging.VerbosityHandler())
+	mux.Handle("/version", version.Handler())
+	mux.Handle("/configz", sched.ConfigzHandler(flagValues(pflag.CommandLine)))
+	if opt.EnablePprof {
+		profiling.Install(mux)
+	}
+
+	glog.Fatalf("Failed to serve HTTP on %s: %v", opt.ListenAddress, http.ListenAndServe(opt.ListenAddress, mux))
+}
 
-	return nil
+// flagValues returns the values of flags by name.
+func flagValues(fs *pflag.FlagSet) map[string]string {
+	values := map[string]string{}
+	fs.VisitAll(func(f *pflag.Flag) {
+		values[f.Name] = f.Value.String()
+	})
+	return values
 }
@@ -37,6 +37,7 @@ func main() {
 	s.AddFlags(pflag.CommandLine)
 
 	flag.InitFlags()
+	s.ApplyProfileOrDie(pflag.CommandLine)
 	s.CheckOptionOrDie()
 
 	// The default glog flush interval is 30 seconds, which is frighteningly long.
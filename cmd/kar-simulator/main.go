@@ -0,0 +1,134 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/golang/glog"
+	"github.com/spf13/pflag"
+
+	"k8s.io/apiserver/pkg/util/flag"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler"
+	schedcache "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/cache"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/simulator"
+)
+
+var (
+	clusterState  = pflag.String("cluster-state", "", "The cluster state in YAML or JSON, e.g. dumped by /debug/cluster-state of kar-scheduler; the live cluster is listed if not set")
+	master        = pflag.String("master", "", "The address of the Kubernetes API server to list the live cluster")
+	kubeconfig    = pflag.String("kubeconfig", "", "Path to kubeconfig file to list the live cluster")
+	config        = pflag.String("config", "", "The proposed scheduler config in YAML or JSON; all actions and plugins of scheduler, one session if not set")
+	schedulerName = pflag.String("scheduler-name", "kar-scheduler", "The name of scheduler whose pods are scheduled")
+	output        = pflag.String("output", "text", "The format of report, text or json")
+	trace         = pflag.String("trace", "", "The trace file recorded by --trace-file of kar-scheduler; the session is replayed by its recorded state and config instead of --cluster-state and --config")
+	traceSession  = pflag.String("trace-session", "", "The session in --trace to replay; the first one if not set")
+)
+
+// kar-simulator replays a cluster state by the sessions of kar-scheduler
+// without any API writes, and reports the placements, preemptions and the
+// fairness of queues.
+func main() {
+	flag.InitFlags()
+	defer glog.Flush()
+
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if *output != "text" && *output != "json" {
+		return fmt.Errorf("invalid output %q, expected text or json", *output)
+	}
+
+	if len(*trace) != 0 {
+		return replay()
+	}
+
+	state, err := loadState()
+	if err != nil {
+		return err
+	}
+
+	cfg := &simulator.Config{}
+	if len(*config) != 0 {
+		if cfg, err = simulator.LoadConfig(*config); err != nil {
+			return err
+		}
+	}
+
+	// The heartbeats of a dumped state are stale when it's replayed.
+	schedcache.NodeHeartbeatGracePeriod = 0
+
+	sim, err := simulator.New(state, cfg, *schedulerName)
+	if err != nil {
+		return err
+	}
+	report := sim.Run()
+
+	if *output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	report.Print(os.Stdout)
+	return nil
+}
+
+// replay replays the session of trace, and reports the differences of its
+// decisions from the recorded ones.
+func replay() error {
+	t, err := scheduler.LoadSessionTrace(*trace, *traceSession)
+	if err != nil {
+		return err
+	}
+
+	schedcache.NodeHeartbeatGracePeriod = 0
+
+	report, err := simulator.Replay(t, *schedulerName)
+	if err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	report.Print(os.Stdout)
+	return nil
+}
+
+func loadState() (*schedcache.ClusterState, error) {
+	if len(*clusterState) != 0 {
+		return schedcache.LoadClusterState(*clusterState)
+	}
+
+	restConfig, err := clientcmd.BuildConfigFromFlags(*master, *kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return schedcache.ListClusterState(restConfig)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/karcli/plugin"
+)
+
+// kubectl-arb is a kubectl plugin, e.g. "kubectl arb jobs", showing the queues
+// and jobs of kar-scheduler, and why the jobs are pending.
+func main() {
+	rootCmd := cobra.Command{
+		Use:   "kubectl-arb",
+		Short: "Show the queues and jobs of kar-scheduler",
+	}
+
+	queuesCmd := &cobra.Command{
+		Use:   "queues",
+		Short: "Show the weight, capability and usage of queues",
+		Run: func(cmd *cobra.Command, args []string) {
+			checkError(cmd, plugin.ListQueues())
+		},
+	}
+	plugin.InitQueuesFlags(queuesCmd)
+	rootCmd.AddCommand(queuesCmd)
+
+	jobsCmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Show the gang size, queue and tasks of jobs",
+		Run: func(cmd *cobra.Command, args []string) {
+			checkError(cmd, plugin.ListJobs())
+		},
+	}
+	plugin.InitJobsFlags(jobsCmd)
+	rootCmd.AddCommand(jobsCmd)
+
+	pendingReasonsCmd := &cobra.Command{
+		Use:   "pending-reasons [job]",
+		Short: "Show why jobs can not be scheduled",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			checkError(cmd, plugin.ShowPendingReasons(args))
+		},
+	}
+	plugin.InitPendingReasonsFlags(pendingReasonsCmd)
+	rootCmd.AddCommand(pendingReasonsCmd)
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func checkError(cmd *cobra.Command, err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to show %s: %v\n", cmd.Name(), err)
+		os.Exit(1)
+	}
+}
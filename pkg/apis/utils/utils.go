@@ -17,9 +17,18 @@ limitations under the License.
 package utils
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
 )
 
 func GetController(obj interface{}) types.UID {
@@ -35,3 +44,107 @@ func GetController(obj interface{}) types.UID {
 
 	return ""
 }
+
+// GetTaskSpecs returns the tasks of QueueJob; Spec.Replicas and Spec.Template
+// are used as an anonymous task if no TaskSpecs.
+func GetTaskSpecs(qj *arbv1.QueueJob) []arbv1.TaskSpec {
+	if len(qj.Spec.TaskSpecs) != 0 {
+		return qj.Spec.TaskSpecs
+	}
+
+	return []arbv1.TaskSpec{
+		{
+			Replicas: qj.Spec.Replicas,
+			Template: qj.Spec.Template,
+		},
+	}
+}
+
+// GetTaskIndex returns the index of QueueJob's pod in its task.
+func GetTaskIndex(pod *v1.Pod) (int32, error) {
+	value, found := pod.Annotations[arbv1.TaskIndexAnnotation]
+	if !found {
+		return 0, fmt.Errorf("no annotation %s", arbv1.TaskIndexAnnotation)
+	}
+
+	ix, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid task index %q: %v", value, err)
+	}
+
+	return int32(ix), nil
+}
+
+// TaskPodName returns the name of the ix-th pod of task in QueueJob; it's
+// stable so the re-created pod has the same name.
+func TaskPodName(qjName, taskName string, ix int32) string {
+	if len(taskName) == 0 {
+		return fmt.Sprintf("%s-%d", qjName, ix)
+	}
+	return fmt.Sprintf("%s-%s-%d", qjName, taskName, ix)
+}
+
+// nodeSelectorOperators maps the operators of node selector to the label
+// selector.
+var nodeSelectorOperators = map[v1.NodeSelectorOperator]selection.Operator{
+	v1.NodeSelectorOpIn:           selection.In,
+	v1.NodeSelectorOpNotIn:        selection.NotIn,
+	v1.NodeSelectorOpExists:       selection.Exists,
+	v1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	v1.NodeSelectorOpGt:           selection.GreaterThan,
+	v1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// MatchNodeSelectorTerm returns whether the node labels match all the
+// requirements of term; an empty or invalid term matches nothing.
+func MatchNodeSelectorTerm(term v1.NodeSelectorTerm, nodeLabels map[string]string) bool {
+	if len(term.MatchExpressions) == 0 {
+		return false
+	}
+
+	selector := labels.NewSelector()
+	for _, expr := range term.MatchExpressions {
+		op, found := nodeSelectorOperators[expr.Operator]
+		if !found {
+			return false
+		}
+		r, err := labels.NewRequirement(expr.Key, op, expr.Values)
+		if err != nil {
+			return false
+		}
+		selector = selector.Add(*r)
+	}
+
+	return selector.Matches(labels.Set(nodeLabels))
+}
+
+// NamespaceQueues is the Queue of the workloads in each namespace, so the
+// workloads of a tenant are always in its Queue; the Queue of SchedulingSpec
+// is overridden by the Queue of its namespace if set.
+var NamespaceQueues map[string]string
+
+// SchedulingSpecQueue returns the Queue of SchedulingSpec by NamespaceQueues,
+// default to the Queue in its spec.
+func SchedulingSpecQueue(ss *arbv1.SchedulingSpec) string {
+	if queue, found := NamespaceQueues[ss.Namespace]; found {
+		return queue
+	}
+	return ss.Spec.Queue
+}
+
+// ParseNamespaceQueues parses the Queues of namespaces in the form of
+// <namespace>=<queue>.
+func ParseNamespaceQueues(values []string) (map[string]string, error) {
+	queues := map[string]string{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			return nil, fmt.Errorf("invalid namespace queue %q, expected <namespace>=<queue>", value)
+		}
+		if _, found := queues[parts[0]]; found {
+			return nil, fmt.Errorf("duplicated queue of namespace %s", parts[0])
+		}
+		queues[parts[0]] = parts[1]
+	}
+	return queues, nil
+}
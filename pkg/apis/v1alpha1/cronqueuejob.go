@@ -0,0 +1,120 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CronQueueJobPlural is the plural of CronQueueJob
+const CronQueueJobPlural = "cronqueuejobs"
+
+// CronQueueJob creates QueueJobs on a cron schedule, e.g. nightly training.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CronQueueJob struct {
+	metav1.TypeMeta `json:",inline"`
+
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Specification of the desired behavior of the cron queuejob.
+	Spec CronQueueJobSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+
+	// Current status of the cron queuejob.
+	Status CronQueueJobStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// CronQueueJobSpec represents the template of CronQueueJob.
+type CronQueueJobSpec struct {
+	// Schedule is the schedule in cron format, e.g. "0 2 * * *"; the
+	// descriptors "@hourly", "@daily", "@weekly", "@monthly" and "@yearly" are
+	// also supported. The schedule is in the time zone of controller.
+	Schedule string `json:"schedule" protobuf:"bytes,1,opt,name=schedule"`
+
+	// StartingDeadlineSeconds is the deadline in seconds for starting the
+	// QueueJob if it missed the scheduled time; the missed QueueJob is skipped
+	// after the deadline. No deadline if not set.
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty" protobuf:"varint,2,opt,name=startingDeadlineSeconds"`
+
+	// ConcurrencyPolicy specifies how to treat concurrent QueueJobs:
+	// "Allow" (default), "Forbid" or "Replace".
+	// +optional
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty" protobuf:"bytes,3,opt,name=concurrencyPolicy"`
+
+	// Suspend tells the controller to suspend the subsequent QueueJobs; the
+	// running QueueJobs are not affected.
+	// +optional
+	Suspend *bool `json:"suspend,omitempty" protobuf:"varint,4,opt,name=suspend"`
+
+	// JobTemplate is the QueueJob that will be created when executing the
+	// CronQueueJob.
+	JobTemplate QueueJobTemplateSpec `json:"jobTemplate" protobuf:"bytes,5,opt,name=jobTemplate"`
+
+	// SuccessfulJobsHistoryLimit is the number of completed QueueJobs to keep;
+	// default to 3.
+	// +optional
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty" protobuf:"varint,6,opt,name=successfulJobsHistoryLimit"`
+
+	// FailedJobsHistoryLimit is the number of failed or aborted QueueJobs to
+	// keep; default to 1.
+	// +optional
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty" protobuf:"varint,7,opt,name=failedJobsHistoryLimit"`
+}
+
+// ConcurrencyPolicy specifies how to treat concurrent QueueJobs of CronQueueJob.
+type ConcurrencyPolicy string
+
+const (
+	// AllowConcurrent allows QueueJobs to run concurrently.
+	AllowConcurrent ConcurrencyPolicy = "Allow"
+	// ForbidConcurrent skips the next run if the previous one is not finished.
+	ForbidConcurrent ConcurrencyPolicy = "Forbid"
+	// ReplaceConcurrent deletes the unfinished QueueJobs and starts a new one.
+	ReplaceConcurrent ConcurrencyPolicy = "Replace"
+)
+
+// QueueJobTemplateSpec describes the QueueJob that will be created from a
+// template.
+type QueueJobTemplateSpec struct {
+	// Standard object's metadata of the QueueJobs created from this template.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Specification of the desired behavior of the QueueJob.
+	// +optional
+	Spec QueueJobSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}
+
+// CronQueueJobStatus represents the status of CronQueueJob.
+type CronQueueJobStatus struct {
+	// Active is the references of the unfinished QueueJobs.
+	// +optional
+	Active []v1.ObjectReference `json:"active,omitempty" protobuf:"bytes,1,rep,name=active"`
+
+	// LastScheduleTime is the last time the QueueJob was scheduled.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty" protobuf:"bytes,2,opt,name=lastScheduleTime"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type CronQueueJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []CronQueueJob `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
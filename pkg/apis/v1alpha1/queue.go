@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// QueuePlural is the plural of Queue
+const QueuePlural = "queues"
+
+// QueueFinalizer is the finalizer of Queue; it's removed by Queue controller
+// after all workloads in the Queue finished.
+const QueueFinalizer = "queue.arbitrator.incubator.k8s.io"
+
+// Queue is a cluster level object which groups the workloads (SchedulingSpec)
+// sharing cluster resources.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type Queue struct {
+	metav1.TypeMeta `json:",inline"`
+
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Specification of the desired behavior of the queue.
+	Spec QueueSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+
+	// Current status of the queue, maintained by Queue controller.
+	Status QueueStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// QueueSpec represents the template of Queue.
+type QueueSpec struct {
+	// Weight is the relative weight of the queue when sharing cluster resources.
+	Weight int32 `json:"weight,omitempty" protobuf:"bytes,1,opt,name=weight"`
+
+	// Capability is the upper limit of resources of the queue; the queue is
+	// over-subscribed if the requests of its workloads exceed it.
+	// +optional
+	Capability v1.ResourceList `json:"capability,omitempty" protobuf:"bytes,2,opt,name=capability"`
+
+	// State is the state of the queue, Open if empty; a Closed queue admits
+	// no new QueueJobs, and its workloads not started yet are not scheduled.
+	// +optional
+	State QueueState `json:"state,omitempty" protobuf:"bytes,3,opt,name=state"`
+}
+
+// QueueState is the state of Queue.
+type QueueState string
+
+const (
+	// QueueStateOpen means the queue admits and schedules workloads.
+	QueueStateOpen QueueState = "Open"
+	// QueueStateClosed means the queue is closed, e.g. being drained; the
+	// workloads already started keep running.
+	QueueStateClosed QueueState = "Closed"
+)
+
+// QueueStatus represents the status of Queue.
+type QueueStatus struct {
+	// The number of SchedulingSpecs whose minAvailable pods are not running yet.
+	// +optional
+	Pending int32 `json:"pending,omitempty" protobuf:"bytes,1,opt,name=pending"`
+
+	// The number of SchedulingSpecs whose minAvailable pods are running.
+	// +optional
+	Running int32 `json:"running,omitempty" protobuf:"bytes,2,opt,name=running"`
+
+	// Requested is the total resource requests of the unfinished pods in the queue.
+	// +optional
+	Requested v1.ResourceList `json:"requested,omitempty" protobuf:"bytes,3,opt,name=requested"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type QueueList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []Queue `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
@@ -23,6 +23,21 @@ import (
 
 const QueueJobPlural = "queuejobs"
 
+const (
+	// QueueJobLabel label string for queuejob name
+	QueueJobLabel string = "queuejob.kube-arbitrator.k8s.io"
+
+	// TaskSpecLabel label string for the task (role) name of QueueJob's pod
+	TaskSpecLabel string = "queuejob.kube-arbitrator.k8s.io/task-spec"
+
+	// TaskIndexAnnotation annotation string for the index of QueueJob's pod in its task
+	TaskIndexAnnotation string = "queuejob.kube-arbitrator.k8s.io/task-index"
+
+	// ClusterAnnotation annotation string for the member cluster which the
+	// QueueJob is dispatched to by federation controller
+	ClusterAnnotation string = "queuejob.kube-arbitrator.k8s.io/cluster"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 type QueueJob struct {
 	metav1.TypeMeta `json:",inline"`
@@ -51,8 +66,122 @@ type QueueJobSpec struct {
 
 	// Specifies the pod that will be created when executing a QueueJob
 	Template v1.PodTemplateSpec `json:"template,omitempty" protobuf:"bytes,3,opt,name=template"`
+
+	// TaskSpecs specifies the tasks (roles) of this QueueJob, e.g. ps and
+	// worker; each task has its own replicas and pod template. If empty,
+	// Replicas and Template are used as the only task of the QueueJob.
+	// +optional
+	TaskSpecs []TaskSpec `json:"taskSpecs,omitempty" protobuf:"bytes,4,rep,name=taskSpecs"`
+
+	// MaxRetry is the max number of retries (re-creating failed pods) of each
+	// task; the QueueJob is Failed once it's exceeded. Unlimited if not set.
+	// +optional
+	MaxRetry *int32 `json:"maxRetry,omitempty" protobuf:"varint,5,opt,name=maxRetry"`
+
+	// BackoffLimit is the upper limit, in seconds, of the exponential backoff
+	// delay before re-creating the failed pods of a task; default to 360.
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty" protobuf:"varint,6,opt,name=backoffLimit"`
+
+	// Plugins is the name of the controller plugins enabled for this QueueJob,
+	// e.g. "svc" creates a headless Service for its pods.
+	// +optional
+	Plugins []string `json:"plugins,omitempty" protobuf:"bytes,7,rep,name=plugins"`
+
+	// CompletionPolicy specifies when the QueueJob is completed: "All" (default)
+	// if all tasks finished, or "Any" if any task finished, e.g. the chief of
+	// TensorFlow job. A task is finished if all of its pods succeeded.
+	// +optional
+	CompletionPolicy CompletionPolicy `json:"completionPolicy,omitempty" protobuf:"bytes,8,opt,name=completionPolicy"`
+
+	// Policies specifies the actions of QueueJob when the events happen to its
+	// pods, e.g. RestartJob on PodFailed. If no policy matched, the failed pod is
+	// re-created unless its RestartPolicy is Never.
+	// +optional
+	Policies []LifecyclePolicy `json:"policies,omitempty" protobuf:"bytes,9,rep,name=policies"`
+
+	// DependsOn is the name of QueueJobs in the same namespace that this
+	// QueueJob depends on; its pods are not created until all of them are
+	// Completed. The QueueJob is Failed if any of them is Failed or Aborted,
+	// or the dependencies are a cycle.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty" protobuf:"bytes,10,rep,name=dependsOn"`
+}
+
+// Event is the event happened to the pods of QueueJob.
+type Event string
+
+const (
+	// PodFailedEvent is the event that the pod of QueueJob failed.
+	PodFailedEvent Event = "PodFailed"
+	// PodEvictedEvent is the event that the pod of QueueJob was evicted; the
+	// policy of PodFailedEvent is used if no policy for it.
+	PodEvictedEvent Event = "PodEvicted"
+)
+
+// Action is the action of QueueJob controller when the event happened.
+type Action string
+
+const (
+	// RestartTaskAction terminates and re-creates all pods of the task.
+	RestartTaskAction Action = "RestartTask"
+	// RestartJobAction terminates and re-creates all pods of the QueueJob.
+	RestartJobAction Action = "RestartJob"
+	// AbortJobAction terminates all pods of the QueueJob, and marks it Aborted.
+	AbortJobAction Action = "AbortJob"
+)
+
+// LifecyclePolicy specifies the action of QueueJob when the event happened.
+type LifecyclePolicy struct {
+	Event  Event  `json:"event" protobuf:"bytes,1,opt,name=event"`
+	Action Action `json:"action" protobuf:"bytes,2,opt,name=action"`
+}
+
+// CompletionPolicy specifies when the QueueJob is completed.
+type CompletionPolicy string
+
+const (
+	// CompletionPolicyAll means QueueJob is completed if all tasks finished.
+	CompletionPolicyAll CompletionPolicy = "All"
+	// CompletionPolicyAny means QueueJob is completed if any task finished.
+	CompletionPolicyAny CompletionPolicy = "Any"
+)
+
+// TaskSpec specifies the replicas and pod template of a task (role) in QueueJob.
+type TaskSpec struct {
+	// Name specifies the name of the task, it's unique in QueueJob.
+	Name string `json:"name,omitempty" protobuf:"bytes,1,opt,name=name"`
+
+	// Replicas specifies the replicas of this task.
+	Replicas int32 `json:"replicas,omitempty" protobuf:"bytes,2,opt,name=replicas"`
+
+	// Specifies the pod that will be created for this task when executing a QueueJob.
+	// The failed pods are re-created unless its RestartPolicy is Never.
+	Template v1.PodTemplateSpec `json:"template,omitempty" protobuf:"bytes,3,opt,name=template"`
 }
 
+// QueueJobPhase is the phase of QueueJob.
+type QueueJobPhase string
+
+const (
+	// QueueJobPhasePending means the minAvailable pods of QueueJob are not running yet.
+	QueueJobPhasePending QueueJobPhase = "Pending"
+	// QueueJobPhaseRunning means the minAvailable pods of QueueJob are running.
+	QueueJobPhaseRunning QueueJobPhase = "Running"
+	// QueueJobPhaseFailed means QueueJob is failed, e.g. a task exceeded MaxRetry;
+	// the controller does not manage its pods any more.
+	QueueJobPhaseFailed QueueJobPhase = "Failed"
+	// QueueJobPhaseCompleted means QueueJob is completed by its CompletionPolicy;
+	// the remaining pods are terminated.
+	QueueJobPhaseCompleted QueueJobPhase = "Completed"
+	// QueueJobPhaseAborted means QueueJob is aborted by its policies; all pods
+	// are terminated.
+	QueueJobPhaseAborted QueueJobPhase = "Aborted"
+	// QueueJobPhaseSuspended means QueueJob is suspended by its SchedulingSpec;
+	// all pods are terminated until it's resumed.
+	QueueJobPhaseSuspended QueueJobPhase = "Suspended"
+)
+
 // QueueJobStatus represents the current state of a QueueJob
 type QueueJobStatus struct {
 	// The number of pending pods.
@@ -74,6 +203,19 @@ type QueueJobStatus struct {
 	// The minimal available pods to run for this QueueJob
 	// +optional
 	MinAvailable int32 `json:"minAvailable,omitempty" protobuf:"bytes,4,opt,name=minAvailable"`
+
+	// The phase of QueueJob.
+	// +optional
+	Phase QueueJobPhase `json:"phase,omitempty" protobuf:"bytes,5,opt,name=phase"`
+
+	// Retries is the number of retries of each task, by task name.
+	// +optional
+	Retries map[string]int32 `json:"retries,omitempty" protobuf:"bytes,6,rep,name=retries"`
+
+	// Message is a human readable message of the phase, e.g. the dependency
+	// which QueueJob is pending on or failed by.
+	// +optional
+	Message string `json:"message,omitempty" protobuf:"bytes,7,opt,name=message"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
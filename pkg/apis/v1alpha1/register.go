@@ -45,6 +45,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&SchedulingSpecList{},
 		&QueueJob{},
 		&QueueJobList{},
+		&Queue{},
+		&QueueList{},
+		&CronQueueJob{},
+		&CronQueueJobList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
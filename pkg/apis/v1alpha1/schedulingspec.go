@@ -17,23 +17,92 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // SchedulingSpecPlural is the plural of SchedulingSpec
 const SchedulingSpecPlural = "schedulingspecs"
 
+// RescheduleAnnotation is set to SchedulingSpec by the rebalancer to request
+// the gang to be re-scheduled, e.g. when its placement is fragmented; the value
+// is the request time in RFC3339, and the pods created before it are evicted by
+// the shuffle action of scheduler.
+const RescheduleAnnotation = "schedulingspec.kube-arbitrator.k8s.io/reschedule"
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 type SchedulingSpec struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata"`
 
-	Spec SchedulingSpecTemplate `json:"spec"`
+	Spec   SchedulingSpecTemplate `json:"spec"`
+	Status SchedulingSpecStatus   `json:"status,omitempty"`
 }
 
 type SchedulingSpecTemplate struct {
 	NodeSelector map[string]string `json:"nodeSelector,omitempty" protobuf:"bytes,1,rep,name=nodeSelector"`
 	MinAvailable int               `json:"minAvailable,omitempty" protobuf:"bytes,2,rep,name=minAvailable"`
+
+	// TTLSecondsAfterFinished limits the lifetime of the gang after all of its
+	// pods finished (Succeeded or Failed). Once the TTL expires, the gang
+	// objects (SchedulingSpec, PDB and QueueJob owner) are deleted.
+	// If unset, the gang is never garbage collected by TTL.
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty" protobuf:"varint,3,opt,name=ttlSecondsAfterFinished"`
+
+	// Queue is the name of the Queue which the gang belongs to.
+	// +optional
+	Queue string `json:"queue,omitempty" protobuf:"bytes,4,opt,name=queue"`
+
+	// Suspend tells the scheduler to skip the gang, and QueueJob controller
+	// to terminate its pods; the gang is admitted again after it's cleared.
+	// +optional
+	Suspend bool `json:"suspend,omitempty" protobuf:"varint,5,opt,name=suspend"`
+
+	// MaxScheduleRetries is the number of consecutive sessions in which the
+	// gang is unschedulable before scheduler gives up on it; its Unschedulable
+	// condition is kept until its spec or pods are changed. Zero means
+	// unlimited.
+	// +optional
+	MaxScheduleRetries int32 `json:"maxScheduleRetries,omitempty" protobuf:"varint,6,opt,name=maxScheduleRetries"`
+}
+
+// SchedulingSpecConditionType is the type of SchedulingSpec condition.
+type SchedulingSpecConditionType string
+
+const (
+	// SchedulingSpecUnschedulable means the pending pods of the gang can not
+	// be scheduled; the message is the reasons why the nodes can not fit.
+	SchedulingSpecUnschedulable SchedulingSpecConditionType = "Unschedulable"
+
+	// SchedulingSpecScheduled means the minAvailable pods of the gang are
+	// scheduled; its lastTransitionTime to True is when the gang is scheduled.
+	SchedulingSpecScheduled SchedulingSpecConditionType = "Scheduled"
+)
+
+// SchedulingSpecCondition is the observed condition of the gang, updated by
+// scheduler.
+type SchedulingSpecCondition struct {
+	Type   SchedulingSpecConditionType `json:"type" protobuf:"bytes,1,opt,name=type,casttype=SchedulingSpecConditionType"`
+	Status v1.ConditionStatus          `json:"status" protobuf:"bytes,2,opt,name=status,casttype=k8s.io/api/core/v1.ConditionStatus"`
+
+	// Last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty" protobuf:"bytes,3,opt,name=lastTransitionTime"`
+
+	// Unique, one-word, CamelCase reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty" protobuf:"bytes,4,opt,name=reason"`
+
+	// Human-readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty" protobuf:"bytes,5,opt,name=message"`
+}
+
+// SchedulingSpecStatus represents the current state of the gang.
+type SchedulingSpecStatus struct {
+	// +optional
+	Conditions []SchedulingSpecCondition `json:"conditions,omitempty" protobuf:"bytes,1,rep,name=conditions"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
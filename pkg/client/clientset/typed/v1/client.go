@@ -28,6 +28,8 @@ type ArbV1Interface interface {
 	RESTClient() rest.Interface
 	SchedulingSpecGetter
 	QueueJobGetter
+	QueueGetter
+	CronQueueJobGetter
 }
 
 // ArbV1Client is used to interact with features provided by the  group.
@@ -43,6 +45,14 @@ func (c *ArbV1Client) QueueJobs(namespace string) QueueJobInterface {
 	return newQueueJobs(c, namespace)
 }
 
+func (c *ArbV1Client) Queues() QueueInterface {
+	return newQueues(c)
+}
+
+func (c *ArbV1Client) CronQueueJobs(namespace string) CronQueueJobInterface {
+	return newCronQueueJobs(c, namespace)
+}
+
 // NewForConfig creates a new ArbV1Client for the given config.
 func NewForConfig(c *rest.Config) (*ArbV1Client, error) {
 	config := *c
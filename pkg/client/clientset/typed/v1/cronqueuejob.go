@@ -0,0 +1,129 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	v1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset/scheme"
+
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+type CronQueueJobGetter interface {
+	CronQueueJobs(namespaces string) CronQueueJobInterface
+}
+
+type CronQueueJobInterface interface {
+	Create(*v1.CronQueueJob) (*v1.CronQueueJob, error)
+	Update(*v1.CronQueueJob) (*v1.CronQueueJob, error)
+	UpdateStatus(*v1.CronQueueJob) (*v1.CronQueueJob, error)
+	Delete(name string, options *meta_v1.DeleteOptions) error
+	Get(name string, options meta_v1.GetOptions) (*v1.CronQueueJob, error)
+	List(opts meta_v1.ListOptions) (*v1.CronQueueJobList, error)
+}
+
+// cronqueuejobs implements CronQueueJobInterface
+type cronqueuejobs struct {
+	client rest.Interface
+	ns     string
+}
+
+// newCronQueueJobs returns a CronQueueJobs
+func newCronQueueJobs(c *ArbV1Client, namespace string) *cronqueuejobs {
+	return &cronqueuejobs{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Create takes the representation of a cronqueuejob and creates it.  Returns the server's representation of the cronqueuejob, and an error, if there is any.
+func (c *cronqueuejobs) Create(cronqueuejob *v1.CronQueueJob) (result *v1.CronQueueJob, err error) {
+	result = &v1.CronQueueJob{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource(v1.CronQueueJobPlural).
+		Body(cronqueuejob).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a cronqueuejob and updates it. Returns the server's representation of the cronqueuejob, and an error, if there is any.
+func (c *cronqueuejobs) Update(cronqueuejob *v1.CronQueueJob) (result *v1.CronQueueJob, err error) {
+	result = &v1.CronQueueJob{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource(v1.CronQueueJobPlural).
+		Name(cronqueuejob.Name).
+		Body(cronqueuejob).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *cronqueuejobs) UpdateStatus(cronqueuejob *v1.CronQueueJob) (result *v1.CronQueueJob, err error) {
+	result = &v1.CronQueueJob{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource(v1.CronQueueJobPlural).
+		Name(cronqueuejob.Name).
+		SubResource("status").
+		Body(cronqueuejob).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the cronqueuejob and deletes it. Returns an error if one occurs.
+func (c *cronqueuejobs) Delete(name string, options *meta_v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource(v1.CronQueueJobPlural).
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Get takes name of the cronqueuejob, and returns the corresponding cronqueuejob object, and an error if there is any.
+func (c *cronqueuejobs) Get(name string, options meta_v1.GetOptions) (result *v1.CronQueueJob, err error) {
+	result = &v1.CronQueueJob{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource(v1.CronQueueJobPlural).
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of CronQueueJobs that match those selectors.
+func (c *cronqueuejobs) List(opts meta_v1.ListOptions) (result *v1.CronQueueJobList, err error) {
+	result = &v1.CronQueueJobList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource(v1.CronQueueJobPlural).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// EventRecorder creates events of the objects in API server; it's a simplified
+// version of client-go's recorder which does not aggregate events.
+type EventRecorder struct {
+	clients   kubernetes.Interface
+	component string
+}
+
+// NewEventRecorder creates an EventRecorder whose events are from component.
+func NewEventRecorder(clients kubernetes.Interface, component string) *EventRecorder {
+	return &EventRecorder{
+		clients:   clients,
+		component: component,
+	}
+}
+
+// Eventf creates an event of the object referred by ref; the error is logged
+// instead of returned, as events are informative.
+func (r *EventRecorder) Eventf(ref *v1.ObjectReference, eventType, reason, messageFmt string, args ...interface{}) {
+	// The events of cluster scoped objects are in default namespace.
+	namespace := ref.Namespace
+	if len(namespace) == 0 {
+		namespace = metav1.NamespaceDefault
+	}
+
+	now := metav1.NewTime(time.Now())
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%v.%x", ref.Name, now.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject: *ref,
+		Reason:         reason,
+		Message:        fmt.Sprintf(messageFmt, args...),
+		Source:         v1.EventSource{Component: r.component},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           eventType,
+	}
+
+	if _, err := r.clients.CoreV1().Events(namespace).Create(event); err != nil {
+		glog.Errorf("Failed to create event %s/%s (%s): %v", namespace, event.Name, reason, err)
+	}
+}
@@ -113,6 +113,10 @@ type SharedInformerFactory interface {
 	SchedulingSpec() arbclient.Interface
 
 	QueueJob() arbclient.Interface
+
+	Queue() arbclient.Interface
+
+	CronQueueJob() arbclient.Interface
 }
 
 func (f *sharedInformerFactory) SchedulingSpec() arbclient.Interface {
@@ -122,3 +126,11 @@ func (f *sharedInformerFactory) SchedulingSpec() arbclient.Interface {
 func (f *sharedInformerFactory) QueueJob() arbclient.Interface {
 	return arbclient.New(f)
 }
+
+func (f *sharedInformerFactory) Queue() arbclient.Interface {
+	return arbclient.New(f)
+}
+
+func (f *sharedInformerFactory) CronQueueJob() arbclient.Interface {
+	return arbclient.New(f)
+}
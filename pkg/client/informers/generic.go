@@ -63,6 +63,16 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 			resource: resource.GroupResource(),
 			informer: f.SchedulingSpec().SchedulingSpecs().Informer(),
 		}, nil
+	case arbv1.SchemeGroupVersion.WithResource("queues"):
+		return &genericInformer{
+			resource: resource.GroupResource(),
+			informer: f.Queue().Queues().Informer(),
+		}, nil
+	case arbv1.SchemeGroupVersion.WithResource("cronqueuejobs"):
+		return &genericInformer{
+			resource: resource.GroupResource(),
+			informer: f.CronQueueJob().CronQueueJobs().Informer(),
+		}, nil
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
@@ -26,6 +26,10 @@ type Interface interface {
 	SchedulingSpecs() SchedulingSpecInformer
 	// QueueJobs returns a QueueJobInformer.
 	QueueJobs() QueueJobInformer
+	// Queues returns a QueueInformer.
+	Queues() QueueInformer
+	// CronQueueJobs returns a CronQueueJobInformer.
+	CronQueueJobs() CronQueueJobInformer
 }
 
 type version struct {
@@ -46,3 +50,13 @@ func (v *version) SchedulingSpecs() SchedulingSpecInformer {
 func (v *version) QueueJobs() QueueJobInformer {
 	return &queueJobInformer{factory: v.SharedInformerFactory}
 }
+
+// Queues returns a QueueInformer.
+func (v *version) Queues() QueueInformer {
+	return &queueInformer{factory: v.SharedInformerFactory}
+}
+
+// CronQueueJobs returns a CronQueueJobInformer.
+func (v *version) CronQueueJobs() CronQueueJobInformer {
+	return &cronQueueJobInformer{factory: v.SharedInformerFactory}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CronQueueJobLister helps list CronQueueJobs.
+type CronQueueJobLister interface {
+	// List lists all CronQueueJobs in the indexer.
+	List(selector labels.Selector) (ret []*arbv1.CronQueueJob, err error)
+	// CronQueueJobs returns an object that can list and get CronQueueJobs.
+	CronQueueJobs(namespace string) CronQueueJobNamespaceLister
+}
+
+// cronQueueJobLister implements the CronQueueJobLister interface.
+type cronQueueJobLister struct {
+	indexer cache.Indexer
+}
+
+// NewCronQueueJobLister returns a new CronQueueJobLister.
+func NewCronQueueJobLister(indexer cache.Indexer) CronQueueJobLister {
+	return &cronQueueJobLister{indexer: indexer}
+}
+
+// List lists all CronQueueJobs in the indexer.
+func (s *cronQueueJobLister) List(selector labels.Selector) (ret []*arbv1.CronQueueJob, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*arbv1.CronQueueJob))
+	})
+	return ret, err
+}
+
+// CronQueueJobs returns an object that can list and get CronQueueJobs.
+func (s *cronQueueJobLister) CronQueueJobs(namespace string) CronQueueJobNamespaceLister {
+	return cronQueueJobNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// CronQueueJobNamespaceLister helps list and get CronQueueJobs.
+type CronQueueJobNamespaceLister interface {
+	// List lists all CronQueueJobs in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*arbv1.CronQueueJob, err error)
+	// Get retrieves the CronQueueJob from the indexer for a given namespace and name.
+	Get(name string) (*arbv1.CronQueueJob, error)
+}
+
+// cronQueueJobNamespaceLister implements the CronQueueJobNamespaceLister
+// interface.
+type cronQueueJobNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all CronQueueJobs in the indexer for a given namespace.
+func (s cronQueueJobNamespaceLister) List(selector labels.Selector) (ret []*arbv1.CronQueueJob, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*arbv1.CronQueueJob))
+	})
+	return ret, err
+}
+
+// Get retrieves the CronQueueJob from the indexer for a given namespace and name.
+func (s cronQueueJobNamespaceLister) Get(name string) (*arbv1.CronQueueJob, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(arbv1.Resource("cronqueuejobs"), name)
+	}
+	return obj.(*arbv1.CronQueueJob), nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+)
+
+// OwnerExists returns whether the owner referenced by ref exists in namespace;
+// the owner re-created with the same name is taken as a different one. The
+// owner of unknown kind is always taken as existing, so its dependents are
+// never taken as orphans.
+func OwnerExists(clients kubernetes.Interface, arbclients clientset.Interface,
+	namespace string, ref *metav1.OwnerReference) (bool, error) {
+	var owner metav1.Object
+	var err error
+
+	switch ref.Kind {
+	case "QueueJob":
+		owner, err = arbclients.ArbV1().QueueJobs(namespace).Get(ref.Name, metav1.GetOptions{})
+	case "ReplicaSet":
+		owner, err = clients.AppsV1().ReplicaSets(namespace).Get(ref.Name, metav1.GetOptions{})
+	case "StatefulSet":
+		owner, err = clients.AppsV1().StatefulSets(namespace).Get(ref.Name, metav1.GetOptions{})
+	case "Deployment":
+		owner, err = clients.AppsV1().Deployments(namespace).Get(ref.Name, metav1.GetOptions{})
+	case "Job":
+		owner, err = clients.BatchV1().Jobs(namespace).Get(ref.Name, metav1.GetOptions{})
+	default:
+		return true, nil
+	}
+
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return owner.GetUID() == ref.UID, nil
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client"
+	arbinformers "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers"
+	informersv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers/v1"
+	listersv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/listers/v1"
+)
+
+// metrics are exposed by expvar, e.g. "admission": {"rejected": 1, ...}.
+var (
+	metrics         = expvar.NewMap("admission")
+	admittedMetric  = "admitted"
+	rejectedMetric  = "rejected"
+	malformedMetric = "malformed"
+)
+
+// Webhook is a validating admission webhook, which rejects the pods and
+// QueueJobs whose requests exceed the remaining capability of their Queue,
+// i.e. the capability minus the requests of the unfinished pods in the
+// Queue, instead of letting them pend forever. The resources not in the
+// capability of Queue are unlimited; the objects without Queue are admitted.
+// The QueueJobs of a closed Queue are rejected, while the pods are admitted
+// so the started QueueJobs keep running.
+//
+// The webhook is registered by a ValidatingWebhookConfiguration of the
+// CREATE of pods and QueueJobs at /validate; the objects are admitted if
+// their Queue is not found.
+type Webhook struct {
+	queueInformer          informersv1.QueueInformer
+	schedulingSpecInformer informersv1.SchedulingSpecInformer
+
+	queueLister          listersv1.QueueLister
+	schedulingSpecLister listersv1.SchedulingSpecLister
+
+	// groupNameLabel is the label grouping pods without controller into the
+	// job of SchedulingSpec, the same as kar-scheduler's; empty to disable it.
+	groupNameLabel string
+}
+
+// NewWebhook creates a new admission Webhook.
+func NewWebhook(config *rest.Config, groupNameLabel string) *Webhook {
+	arbClient, _, err := client.NewClient(config)
+	if err != nil {
+		panic(err)
+	}
+
+	arbInformerFactory := arbinformers.NewSharedInformerFactory(arbClient, 0)
+
+	wh := &Webhook{
+		queueInformer:          arbInformerFactory.Queue().Queues(),
+		schedulingSpecInformer: arbInformerFactory.SchedulingSpec().SchedulingSpecs(),
+		groupNameLabel:         groupNameLabel,
+	}
+	wh.queueLister = wh.queueInformer.Lister()
+	wh.schedulingSpecLister = wh.schedulingSpecInformer.Lister()
+
+	return wh
+}
+
+// Run starts the informers of Webhook, and serves the admission reviews at
+// address by TLS until it fails.
+func (wh *Webhook) Run(address, certFile, keyFile string, stopCh <-chan struct{}) {
+	go wh.queueInformer.Informer().Run(stopCh)
+	go wh.schedulingSpecInformer.Informer().Run(stopCh)
+
+	cache.WaitForCacheSync(stopCh,
+		wh.queueInformer.Informer().HasSynced,
+		wh.schedulingSpecInformer.Informer().HasSynced)
+
+	mux := http.NewServeMux()
+	mux.Handle("/validate", wh)
+
+	glog.Fatalf("Failed to serve admission webhook on %s: %v",
+		address, http.ListenAndServeTLS(address, certFile, keyFile, mux))
+}
+
+// ServeHTTP reviews the AdmissionReview in request.
+func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	review := &AdmissionReview{}
+	if err := json.NewDecoder(r.Body).Decode(review); err != nil || review.Request == nil {
+		metrics.Add(malformedMetric, 1)
+		http.Error(w, "malformed admission review", http.StatusBadRequest)
+		return
+	}
+
+	response := &AdmissionResponse{UID: review.Request.UID, Allowed: true}
+	if msg, err := wh.review(review.Request); err != nil {
+		glog.Errorf("Failed to review %s <%s/%s>: %v", review.Request.Kind.Kind,
+			review.Request.Namespace, review.Request.UID, err)
+	} else if len(msg) != 0 {
+		response.Allowed = false
+		response.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonForbidden,
+			Message: msg,
+			Code:    http.StatusForbidden,
+		}
+	}
+
+	if response.Allowed {
+		metrics.Add(admittedMetric, 1)
+	} else {
+		metrics.Add(rejectedMetric, 1)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&AdmissionReview{TypeMeta: review.TypeMeta, Response: response})
+}
+
+// review returns the reason to reject the object in request, empty if it's
+// admitted.
+func (wh *Webhook) review(req *AdmissionRequest) (string, error) {
+	if req.Operation != "CREATE" {
+		return "", nil
+	}
+
+	var queue string
+	var requests v1.ResourceList
+
+	switch req.Kind.Kind {
+	case "Pod":
+		pod := &v1.Pod{}
+		if err := json.Unmarshal(req.Object.Raw, pod); err != nil {
+			return "", err
+		}
+		if len(pod.Namespace) == 0 {
+			pod.Namespace = req.Namespace
+		}
+
+		var err error
+		if queue, err = wh.podQueue(pod); err != nil {
+			return "", err
+		}
+		requests = podRequests(&pod.Spec)
+	case "QueueJob":
+		qj := &arbv1.QueueJob{}
+		if err := json.Unmarshal(req.Object.Raw, qj); err != nil {
+			return "", err
+		}
+		if len(qj.Namespace) == 0 {
+			qj.Namespace = req.Namespace
+		}
+
+		queue = utils.SchedulingSpecQueue(&arbv1.SchedulingSpec{
+			ObjectMeta: metav1.ObjectMeta{Namespace: qj.Namespace},
+			Spec:       qj.Spec.SchedSpec,
+		})
+		requests = queueJobRequests(qj)
+	default:
+		return "", nil
+	}
+
+	if len(queue) == 0 {
+		return "", nil
+	}
+
+	q, err := wh.queueLister.Get(queue)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if q.Spec.State == arbv1.QueueStateClosed && req.Kind.Kind == "QueueJob" {
+		return fmt.Sprintf("Queue %s is closed", queue), nil
+	}
+
+	if exceeded := exceededResources(requests, q); len(exceeded) != 0 {
+		return fmt.Sprintf("%s requests exceed the remaining capability of Queue %s: %s",
+			req.Kind.Kind, queue, strings.Join(exceeded, ", ")), nil
+	}
+
+	return "", nil
+}
+
+// podQueue returns the Queue of pod by its SchedulingSpec, in the same way
+// as Queue controller; it's empty if pod has no SchedulingSpec.
+func (wh *Webhook) podQueue(pod *v1.Pod) (string, error) {
+	if queue, found := utils.NamespaceQueues[pod.Namespace]; found {
+		return queue, nil
+	}
+
+	specs, err := wh.schedulingSpecLister.SchedulingSpecs(pod.Namespace).List(labels.Everything())
+	if err != nil {
+		return "", err
+	}
+
+	owner := utils.GetController(pod)
+	for _, ss := range specs {
+		if len(owner) != 0 && utils.GetController(ss) == owner {
+			return utils.SchedulingSpecQueue(ss), nil
+		}
+		if len(wh.groupNameLabel) != 0 && pod.Labels[wh.groupNameLabel] == ss.Name {
+			return utils.SchedulingSpecQueue(ss), nil
+		}
+	}
+
+	return "", nil
+}
+
+// exceededResources returns the resources of requests exceeding the remaining
+// capability of queue, with their requests and remaining.
+func exceededResources(requests v1.ResourceList, queue *arbv1.Queue) []string {
+	var exceeded []string
+	for name, capability := range queue.Spec.Capability {
+		request, found := requests[name]
+		if !found {
+			continue
+		}
+
+		remaining := *capability.Copy()
+		if requested, found := queue.Status.Requested[name]; found {
+			remaining.Sub(requested)
+		}
+
+		if request.Cmp(remaining) > 0 {
+			exceeded = append(exceeded, fmt.Sprintf("%s %s > %s", name, request.String(), remaining.String()))
+		}
+	}
+
+	sort.Strings(exceeded)
+	return exceeded
+}
+
+// podRequests returns the sum of the resource requests of the containers.
+func podRequests(spec *v1.PodSpec) v1.ResourceList {
+	result := v1.ResourceList{}
+	for _, c := range spec.Containers {
+		addResourceList(result, c.Resources.Requests, 1)
+	}
+	return result
+}
+
+// queueJobRequests returns the total requests of the pods of QueueJob.
+func queueJobRequests(qj *arbv1.QueueJob) v1.ResourceList {
+	result := v1.ResourceList{}
+	for _, ts := range utils.GetTaskSpecs(qj) {
+		addResourceList(result, podRequests(&ts.Template.Spec), ts.Replicas)
+	}
+	return result
+}
+
+// addResourceList adds the resources in new to list by times.
+func addResourceList(list, new v1.ResourceList, times int32) {
+	for name, quantity := range new {
+		for i := int32(0); i < times; i++ {
+			if value, ok := list[name]; !ok {
+				list[name] = *quantity.Copy()
+			} else {
+				value.Add(quantity)
+				list[name] = value
+			}
+		}
+	}
+}
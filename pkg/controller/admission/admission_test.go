@@ -0,0 +1,137 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	listersv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/listers/v1"
+)
+
+func buildWebhook(queue *arbv1.Queue, ss *arbv1.SchedulingSpec) *Webhook {
+	queueIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	queueIndexer.Add(queue)
+	ssIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	ssIndexer.Add(ss)
+
+	return &Webhook{
+		queueLister:          listersv1.NewQueueLister(queueIndexer),
+		schedulingSpecLister: listersv1.NewSchedulingSpecLister(ssIndexer),
+	}
+}
+
+func review(t *testing.T, wh *Webhook, kind string, obj interface{}) *AdmissionResponse {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(&AdmissionReview{Request: &AdmissionRequest{
+		UID:       "r1",
+		Kind:      metav1.GroupVersionKind{Kind: kind},
+		Namespace: "c1",
+		Operation: "CREATE",
+		Object:    runtime.RawExtension{Raw: raw},
+	}})
+
+	w := httptest.NewRecorder()
+	wh.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+
+	result := &AdmissionReview{}
+	if err := json.NewDecoder(w.Body).Decode(result); err != nil || result.Response == nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return result.Response
+}
+
+func TestWebhook(t *testing.T) {
+	owner := metav1.OwnerReference{UID: "job1", Controller: new(bool)}
+	*owner.Controller = true
+
+	queue := &arbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec:       arbv1.QueueSpec{Capability: v1.ResourceList{v1.ResourceCPU: resource.MustParse("10")}},
+		Status:     arbv1.QueueStatus{Requested: v1.ResourceList{v1.ResourceCPU: resource.MustParse("6")}},
+	}
+	ss := &arbv1.SchedulingSpec{
+		ObjectMeta: metav1.ObjectMeta{Name: "ss1", Namespace: "c1", OwnerReferences: []metav1.OwnerReference{owner}},
+		Spec:       arbv1.SchedulingSpecTemplate{Queue: "q1"},
+	}
+	wh := buildWebhook(queue, ss)
+
+	podSpec := func(cpu string) v1.PodSpec {
+		return v1.PodSpec{Containers: []v1.Container{{
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+			},
+		}}}
+	}
+	pod := func(cpu string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "c1", OwnerReferences: []metav1.OwnerReference{owner}},
+			Spec:       podSpec(cpu),
+		}
+	}
+
+	if resp := review(t, wh, "Pod", pod("4")); !resp.Allowed {
+		t.Errorf("expected pod within remaining capability admitted, got %v", resp.Result)
+	}
+	if resp := review(t, wh, "Pod", pod("5")); resp.Allowed {
+		t.Errorf("expected pod exceeding remaining capability rejected")
+	}
+
+	qj := &arbv1.QueueJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "qj1", Namespace: "c1"},
+		Spec: arbv1.QueueJobSpec{
+			Replicas:  3,
+			SchedSpec: arbv1.SchedulingSpecTemplate{Queue: "q1"},
+			Template:  v1.PodTemplateSpec{Spec: podSpec("2")},
+		},
+	}
+	if resp := review(t, wh, "QueueJob", qj); resp.Allowed {
+		t.Errorf("expected QueueJob requesting 6 cpu rejected")
+	}
+
+	qj.Spec.SchedSpec.Queue = "unknown"
+	if resp := review(t, wh, "QueueJob", qj); !resp.Allowed {
+		t.Errorf("expected QueueJob of unknown Queue admitted, got %v", resp.Result)
+	}
+
+	// The pods of a closed Queue are admitted, but not its new QueueJobs.
+	queue.Spec.State = arbv1.QueueStateClosed
+	qj.Spec.Replicas = 1
+	qj.Spec.SchedSpec.Queue = "q1"
+	if resp := review(t, wh, "QueueJob", qj); resp.Allowed {
+		t.Errorf("expected QueueJob of closed Queue rejected")
+	}
+	if resp := review(t, wh, "Pod", pod("1")); !resp.Allowed {
+		t.Errorf("expected pod of closed Queue admitted, got %v", resp.Result)
+	}
+}
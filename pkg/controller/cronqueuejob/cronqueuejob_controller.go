@@ -0,0 +1,403 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronqueuejob
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+	arbinformers "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers"
+	informersv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers/v1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/workqueue"
+)
+
+const (
+	componentName = "cronqueuejob-controller"
+
+	// InvalidScheduleReason is the reason of the event when the schedule of
+	// CronQueueJob is invalid.
+	InvalidScheduleReason = "InvalidSchedule"
+	// SuccessfulCreateReason is the reason of the event when a QueueJob is
+	// created.
+	SuccessfulCreateReason = "SuccessfulCreate"
+)
+
+// Controller creates QueueJobs by the schedule of CronQueueJobs.
+type Controller struct {
+	config     *rest.Config
+	arbclients *clientset.Clientset
+	recorder   *client.EventRecorder
+
+	cronQueueJobInformer informersv1.CronQueueJobInformer
+	queueJobInformer     informersv1.QueueJobInformer
+
+	// queue of CronQueueJob keys that need to sync up
+	queue workqueue.RateLimitingInterface
+}
+
+// NewCronQueueJobController creates a new CronQueueJob Controller.
+func NewCronQueueJobController(config *rest.Config) *Controller {
+	cc := &Controller{
+		config:     config,
+		arbclients: clientset.NewForConfigOrDie(config),
+		recorder:   client.NewEventRecorder(kubernetes.NewForConfigOrDie(config), componentName),
+		queue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "cronqueuejob"),
+	}
+
+	arbClient, _, err := client.NewClient(config)
+	if err != nil {
+		panic(err)
+	}
+
+	arbInformerFactory := arbinformers.NewSharedInformerFactory(arbClient, 0)
+
+	cc.cronQueueJobInformer = arbInformerFactory.CronQueueJob().CronQueueJobs()
+	cc.cronQueueJobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: cc.enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			cc.enqueue(newObj)
+		},
+	})
+
+	cc.queueJobInformer = arbInformerFactory.QueueJob().QueueJobs()
+	cc.queueJobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: cc.enqueueQueueJobOwner,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			cc.enqueueQueueJobOwner(newObj)
+		},
+		DeleteFunc: cc.enqueueQueueJobOwner,
+	})
+
+	return cc
+}
+
+// Run starts CronQueueJob Controller.
+func (cc *Controller) Run(stopCh <-chan struct{}) {
+	if err := createCronQueueJobKind(cc.config); err != nil {
+		glog.Errorf("Failed to create CronQueueJob CRD: %v", err)
+	}
+
+	go cc.cronQueueJobInformer.Informer().Run(stopCh)
+	go cc.queueJobInformer.Informer().Run(stopCh)
+
+	cache.WaitForCacheSync(stopCh,
+		cc.cronQueueJobInformer.Informer().HasSynced,
+		cc.queueJobInformer.Informer().HasSynced)
+
+	go wait.Until(cc.worker, time.Second, stopCh)
+
+	go func() {
+		<-stopCh
+		cc.queue.ShutDown()
+	}()
+}
+
+func (cc *Controller) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		glog.Errorf("Failed to get key of %v: %v", obj, err)
+		return
+	}
+
+	cc.queue.Add(key)
+}
+
+// enqueueQueueJobOwner enqueues the CronQueueJob which controls the QueueJob.
+func (cc *Controller) enqueueQueueJobOwner(obj interface{}) {
+	var qj *arbv1.QueueJob
+	switch t := obj.(type) {
+	case *arbv1.QueueJob:
+		qj = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		qj, ok = t.Obj.(*arbv1.QueueJob)
+		if !ok {
+			glog.Errorf("Cannot convert to *arbv1.QueueJob: %v", t.Obj)
+			return
+		}
+	default:
+		glog.Errorf("Cannot convert to *arbv1.QueueJob: %v", t)
+		return
+	}
+
+	ref := metav1.GetControllerOf(qj)
+	if ref == nil || ref.Kind != cronQueueJobKind.Kind {
+		return
+	}
+
+	cc.queue.Add(qj.Namespace + "/" + ref.Name)
+}
+
+func (cc *Controller) worker() {
+	for cc.processNextItem() {
+	}
+}
+
+func (cc *Controller) processNextItem() bool {
+	key, shutdown := cc.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer cc.queue.Done(key)
+
+	requeueAfter, err := cc.sync(key.(string))
+	if err != nil {
+		glog.Errorf("Failed to sync CronQueueJob %s (retried %d times): %v",
+			key, cc.queue.NumRequeues(key), err)
+		cc.queue.AddRateLimited(key)
+		return true
+	}
+
+	cc.queue.Forget(key)
+
+	// Re-sync at the next schedule time.
+	if requeueAfter > 0 {
+		cc.queue.AddAfter(key, requeueAfter)
+	}
+
+	return true
+}
+
+// sync creates the QueueJob of CronQueueJob if its schedule time is up; it
+// returns the duration to the next schedule time.
+func (cc *Controller) sync(key string) (time.Duration, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return 0, err
+	}
+
+	cqj, err := cc.cronQueueJobInformer.Lister().CronQueueJobs(namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// The QueueJobs are garbage collected with their CronQueueJob.
+			return 0, nil
+		}
+		return 0, err
+	}
+	cqj = cqj.DeepCopy()
+
+	qjs, err := cc.getQueueJobsForCronQueueJob(cqj)
+	if err != nil {
+		return 0, err
+	}
+
+	status := cqj.Status.DeepCopy()
+	status.Active = nil
+
+	var active, finished []*arbv1.QueueJob
+	for _, qj := range qjs {
+		if isJobFinished(qj) {
+			finished = append(finished, qj)
+		} else {
+			active = append(active, qj)
+			status.Active = append(status.Active, queueJobReference(qj))
+		}
+	}
+
+	if err := cc.cleanupFinishedJobs(cqj, finished); err != nil {
+		return 0, err
+	}
+
+	if cqj.Spec.Suspend != nil && *cqj.Spec.Suspend {
+		glog.V(4).Infof("CronQueueJob %s is suspended", key)
+		return 0, cc.updateStatus(cqj, status)
+	}
+
+	sched, err := parseSchedule(cqj.Spec.Schedule)
+	if err != nil {
+		// The invalid schedule is not retried until CronQueueJob is updated.
+		glog.Errorf("Failed to parse schedule <%s> of CronQueueJob %s: %v", cqj.Spec.Schedule, key, err)
+		cc.recorder.Eventf(cronQueueJobReference(cqj), v1.EventTypeWarning, InvalidScheduleReason,
+			"Invalid schedule <%s>: %v", cqj.Spec.Schedule, err)
+		return 0, cc.updateStatus(cqj, status)
+	}
+
+	now := time.Now()
+	requeueAfter := time.Duration(0)
+	if next := sched.next(now); !next.IsZero() {
+		requeueAfter = next.Sub(now)
+	}
+
+	// The schedule times missed by StartingDeadlineSeconds are skipped.
+	scheduledTime, missed := mostRecentScheduleTime(cqj, sched, now)
+	if scheduledTime.IsZero() {
+		return requeueAfter, cc.updateStatus(cqj, status)
+	}
+	if missed > 1 {
+		glog.V(3).Infof("CronQueueJob %s missed %d schedule times, start the latest at %v",
+			key, missed, scheduledTime)
+	}
+
+	qj := newQueueJob(cqj, scheduledTime)
+
+	// The QueueJob of scheduledTime may be created already, e.g. the sync is
+	// triggered by its creation before LastScheduleTime is updated in the
+	// lister; it's neither concurrent with itself, nor replaced by itself.
+	var concurrent []*arbv1.QueueJob
+	for _, a := range active {
+		if a.Name != qj.Name {
+			concurrent = append(concurrent, a)
+		}
+	}
+
+	switch cqj.Spec.ConcurrencyPolicy {
+	case arbv1.ForbidConcurrent:
+		if len(concurrent) != 0 {
+			// It's re-synced once the active QueueJobs finished.
+			glog.V(3).Infof("Skip schedule time %v of CronQueueJob %s, as %d QueueJobs are still active",
+				scheduledTime, key, len(concurrent))
+			return requeueAfter, cc.updateStatus(cqj, status)
+		}
+	case arbv1.ReplaceConcurrent:
+		status.Active = nil
+		for _, a := range active {
+			if a.Name == qj.Name {
+				status.Active = append(status.Active, queueJobReference(a))
+				continue
+			}
+			glog.V(3).Infof("Delete active QueueJob %s/%s of CronQueueJob %s to replace it",
+				a.Namespace, a.Name, key)
+			if err := cc.deleteQueueJob(a); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	created, err := cc.arbclients.ArbV1().QueueJobs(qj.Namespace).Create(qj)
+	if err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return 0, err
+		}
+		created = nil
+	}
+
+	if created != nil {
+		glog.V(3).Infof("Created QueueJob %s/%s of CronQueueJob %s for schedule time %v",
+			created.Namespace, created.Name, key, scheduledTime)
+		cc.recorder.Eventf(cronQueueJobReference(cqj), v1.EventTypeNormal, SuccessfulCreateReason,
+			"Created QueueJob %s", created.Name)
+		status.Active = append(status.Active, queueJobReference(created))
+	}
+
+	status.LastScheduleTime = &metav1.Time{Time: scheduledTime}
+
+	return requeueAfter, cc.updateStatus(cqj, status)
+}
+
+// getQueueJobsForCronQueueJob returns the QueueJobs controlled by cqj, sorted
+// by creation time.
+func (cc *Controller) getQueueJobsForCronQueueJob(cqj *arbv1.CronQueueJob) ([]*arbv1.QueueJob, error) {
+	qjs, err := cc.queueJobInformer.Lister().QueueJobs(cqj.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*arbv1.QueueJob
+	for _, qj := range qjs {
+		if ref := metav1.GetControllerOf(qj); ref != nil && ref.UID == cqj.UID {
+			result = append(result, qj)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreationTimestamp.Before(&result[j].CreationTimestamp)
+	})
+
+	return result, nil
+}
+
+// cleanupFinishedJobs deletes the oldest finished QueueJobs beyond the history
+// limits of cqj.
+func (cc *Controller) cleanupFinishedJobs(cqj *arbv1.CronQueueJob, finished []*arbv1.QueueJob) error {
+	var succeeded, failed []*arbv1.QueueJob
+	for _, qj := range finished {
+		if qj.Status.Phase == arbv1.QueueJobPhaseCompleted {
+			succeeded = append(succeeded, qj)
+		} else {
+			failed = append(failed, qj)
+		}
+	}
+
+	var toDelete []*arbv1.QueueJob
+	if limit := historyLimit(cqj.Spec.SuccessfulJobsHistoryLimit, defaultSuccessfulJobsHistoryLimit); len(succeeded) > limit {
+		toDelete = append(toDelete, succeeded[:len(succeeded)-limit]...)
+	}
+	if limit := historyLimit(cqj.Spec.FailedJobsHistoryLimit, defaultFailedJobsHistoryLimit); len(failed) > limit {
+		toDelete = append(toDelete, failed[:len(failed)-limit]...)
+	}
+
+	for _, qj := range toDelete {
+		glog.V(3).Infof("Delete finished QueueJob %s/%s beyond history limit of CronQueueJob %s/%s",
+			qj.Namespace, qj.Name, cqj.Namespace, cqj.Name)
+		if err := cc.deleteQueueJob(qj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cc *Controller) deleteQueueJob(qj *arbv1.QueueJob) error {
+	policy := metav1.DeletePropagationBackground
+	err := cc.arbclients.ArbV1().QueueJobs(qj.Namespace).Delete(qj.Name, &metav1.DeleteOptions{
+		PropagationPolicy: &policy,
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (cc *Controller) updateStatus(cqj *arbv1.CronQueueJob, status *arbv1.CronQueueJobStatus) error {
+	if reflect.DeepEqual(&cqj.Status, status) {
+		return nil
+	}
+
+	cqj.Status = *status
+
+	// TODO: replaced it with `UpdateStatus` after CRD supports status sub-resource.
+	_, err := cc.arbclients.ArbV1().CronQueueJobs(cqj.Namespace).Update(cqj)
+	return err
+}
+
+func createCronQueueJobKind(config *rest.Config) error {
+	extensionscs, err := apiextensionsclient.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	_, err = client.CreateCronQueueJobKind(extensionscs)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronqueuejob
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+)
+
+// fakeServer records the requests to apiserver; the QueueJobs are created
+// already, and the other requests are succeeded.
+type fakeServer struct {
+	sync.Mutex
+	requests []string
+}
+
+func (fs *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.Lock()
+	fs.requests = append(fs.requests, r.Method+" "+r.URL.Path)
+	fs.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/queuejobs"):
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"AlreadyExists","code":409}`)
+	case r.Method == http.MethodDelete:
+		fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Success"}`)
+	default:
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	}
+}
+
+func (fs *fakeServer) deleted() []string {
+	fs.Lock()
+	defer fs.Unlock()
+
+	var names []string
+	for _, r := range fs.requests {
+		if strings.HasPrefix(r, http.MethodDelete+" ") {
+			names = append(names, r[strings.LastIndex(r, "/")+1:])
+		}
+	}
+	return names
+}
+
+func TestSyncReplaceConcurrent(t *testing.T) {
+	fs := &fakeServer{}
+	server := httptest.NewServer(fs)
+	defer server.Close()
+
+	cc := NewCronQueueJobController(&rest.Config{Host: server.URL})
+
+	cqj := &arbv1.CronQueueJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "cqj",
+			Namespace:         "c1",
+			UID:               types.UID("cqj"),
+			CreationTimestamp: metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+		},
+		Spec: arbv1.CronQueueJobSpec{
+			Schedule:          "0 * * * *",
+			ConcurrencyPolicy: arbv1.ReplaceConcurrent,
+		},
+	}
+	if err := cc.cronQueueJobInformer.Informer().GetIndexer().Add(cqj); err != nil {
+		t.Fatal(err)
+	}
+
+	sched, err := parseSchedule(cqj.Spec.Schedule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduledTime, _ := mostRecentScheduleTime(cqj, sched, time.Now())
+
+	// The QueueJob of scheduledTime was created by last sync, but
+	// LastScheduleTime was not updated yet.
+	current := newQueueJob(cqj, scheduledTime)
+	current.CreationTimestamp = metav1.Time{Time: scheduledTime}
+	old := newQueueJob(cqj, scheduledTime.Add(-time.Hour))
+	old.CreationTimestamp = metav1.Time{Time: scheduledTime.Add(-time.Hour)}
+	for _, qj := range []*arbv1.QueueJob{old, current} {
+		if err := cc.queueJobInformer.Informer().GetIndexer().Add(qj); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := cc.sync("c1/cqj"); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+
+	deleted := fs.deleted()
+	if len(deleted) != 1 || deleted[0] != old.Name {
+		t.Errorf("expected to delete only %s, got %v", old.Name, deleted)
+	}
+}
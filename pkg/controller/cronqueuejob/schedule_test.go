@@ -0,0 +1,126 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronqueuejob
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleError(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"1-2-3 * * * *",
+		"a * * * *",
+	}
+
+	for _, spec := range tests {
+		if _, err := parseSchedule(spec); err == nil {
+			t.Errorf("expected error of schedule <%s>, got nil", spec)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	tests := []struct {
+		spec     string
+		from     string
+		expected string
+	}{
+		{
+			spec:     "* * * * *",
+			from:     "2018-06-01T10:20:30Z",
+			expected: "2018-06-01T10:21:00Z",
+		},
+		{
+			spec:     "*/15 * * * *",
+			from:     "2018-06-01T10:20:00Z",
+			expected: "2018-06-01T10:30:00Z",
+		},
+		{
+			spec:     "@daily",
+			from:     "2018-12-31T10:20:00Z",
+			expected: "2019-01-01T00:00:00Z",
+		},
+		{
+			spec:     "@hourly",
+			from:     "2018-06-01T10:00:00Z",
+			expected: "2018-06-01T11:00:00Z",
+		},
+		{
+			spec:     "30 2 * * mon-fri",
+			from:     "2018-06-01T10:20:00Z", // Friday
+			expected: "2018-06-04T02:30:00Z",
+		},
+		{
+			spec:     "0 0 1,15 * *",
+			from:     "2018-06-02T00:00:00Z",
+			expected: "2018-06-15T00:00:00Z",
+		},
+		{
+			// Either day-of-month or day-of-week matches, as both restricted.
+			spec:     "0 0 13 * 5",
+			from:     "2018-06-02T00:00:00Z",
+			expected: "2018-06-08T00:00:00Z",
+		},
+		{
+			spec:     "0 0 * * 7",
+			from:     "2018-06-01T00:00:00Z",
+			expected: "2018-06-03T00:00:00Z",
+		},
+		{
+			spec:     "0 0 29 feb *",
+			from:     "2018-06-01T00:00:00Z",
+			expected: "2020-02-29T00:00:00Z",
+		},
+		{
+			spec:     "0 0 30 2 *",
+			from:     "2018-06-01T00:00:00Z",
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		s, err := parseSchedule(test.spec)
+		if err != nil {
+			t.Errorf("failed to parse schedule <%s>: %v", test.spec, err)
+			continue
+		}
+
+		from, _ := time.Parse(time.RFC3339, test.from)
+		next := s.next(from)
+
+		var expected time.Time
+		if len(test.expected) != 0 {
+			expected, _ = time.Parse(time.RFC3339, test.expected)
+		}
+
+		if !next.Equal(expected) {
+			t.Errorf("expected next of <%s> from %v to be %v, got %v",
+				test.spec, from, expected, next)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cronqueuejob
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+)
+
+var cronQueueJobKind = arbv1.SchemeGroupVersion.WithKind("CronQueueJob")
+
+const (
+	defaultSuccessfulJobsHistoryLimit = 3
+	defaultFailedJobsHistoryLimit     = 1
+)
+
+func isJobFinished(qj *arbv1.QueueJob) bool {
+	return qj.Status.Phase == arbv1.QueueJobPhaseFailed ||
+		qj.Status.Phase == arbv1.QueueJobPhaseCompleted ||
+		qj.Status.Phase == arbv1.QueueJobPhaseAborted
+}
+
+// mostRecentScheduleTime returns the latest schedule time of cqj which is not
+// later than now, and the number of missed schedule times; zero time if no
+// schedule time since last scheduled.
+func mostRecentScheduleTime(cqj *arbv1.CronQueueJob, s *schedule, now time.Time) (time.Time, int) {
+	earliest := cqj.CreationTimestamp.Time
+	if cqj.Status.LastScheduleTime != nil {
+		earliest = cqj.Status.LastScheduleTime.Time
+	}
+
+	// The schedule times before the deadline are skipped anyway.
+	if cqj.Spec.StartingDeadlineSeconds != nil {
+		deadline := now.Add(-time.Duration(*cqj.Spec.StartingDeadlineSeconds) * time.Second)
+		if deadline.After(earliest) {
+			earliest = deadline
+		}
+	}
+
+	var latest time.Time
+	missed := 0
+	for t := s.next(earliest); !t.IsZero() && !t.After(now); t = s.next(t) {
+		latest = t
+		missed++
+	}
+
+	return latest, missed
+}
+
+// newQueueJob creates the QueueJob from the template of cqj, scheduled at
+// scheduledTime; its name is determined by scheduledTime, so the QueueJob
+// is not created twice for the same schedule time.
+func newQueueJob(cqj *arbv1.CronQueueJob, scheduledTime time.Time) *arbv1.QueueJob {
+	qj := &arbv1.QueueJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-%d", cqj.Name, scheduledTime.Unix()/60),
+			Namespace:   cqj.Namespace,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cqj, cronQueueJobKind),
+			},
+		},
+		Spec: *cqj.Spec.JobTemplate.Spec.DeepCopy(),
+	}
+
+	for k, v := range cqj.Spec.JobTemplate.Labels {
+		qj.Labels[k] = v
+	}
+	for k, v := range cqj.Spec.JobTemplate.Annotations {
+		qj.Annotations[k] = v
+	}
+
+	return qj
+}
+
+func queueJobReference(qj *arbv1.QueueJob) v1.ObjectReference {
+	return v1.ObjectReference{
+		Kind:       "QueueJob",
+		APIVersion: arbv1.SchemeGroupVersion.String(),
+		Namespace:  qj.Namespace,
+		Name:       qj.Name,
+		UID:        qj.UID,
+	}
+}
+
+func cronQueueJobReference(cqj *arbv1.CronQueueJob) *v1.ObjectReference {
+	return &v1.ObjectReference{
+		Kind:            cronQueueJobKind.Kind,
+		APIVersion:      arbv1.SchemeGroupVersion.String(),
+		Namespace:       cqj.Namespace,
+		Name:            cqj.Name,
+		UID:             cqj.UID,
+		ResourceVersion: cqj.ResourceVersion,
+	}
+}
+
+func historyLimit(limit *int32, defaultLimit int) int {
+	if limit == nil {
+		return defaultLimit
+	}
+	return int(*limit)
+}
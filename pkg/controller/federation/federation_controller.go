@@ -0,0 +1,246 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federation
+
+import (
+	"expvar"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+const (
+	// dispatchPeriod is the interval between two dispatches of QueueJobs.
+	dispatchPeriod = 10 * time.Second
+
+	// staleCapacity is the age after which the capacity report of a member
+	// cluster is not used, e.g. its scheduler is not leading.
+	staleCapacity = time.Minute
+)
+
+// metrics are exposed by expvar, e.g. "federation": {"dispatched": 1, ...}.
+var (
+	metrics              = expvar.NewMap("federation")
+	dispatchedMetric     = "dispatched"
+	dispatchFailedMetric = "dispatch_failed"
+)
+
+// Controller dispatches the QueueJobs in the host cluster to member clusters:
+// the QueueJob is created in the member cluster whose reported idle resources
+// fit it best, i.e. the least idle left after placing it, and the QueueJob in
+// host cluster is annotated by the member cluster. It's a prototype: the
+// status of QueueJob in member cluster is not synced back to host cluster.
+//
+// The host cluster only keeps the QueueJobs to dispatch, so QueueJob
+// controller should not run in it.
+type Controller struct {
+	arbclients *clientset.Clientset
+	members    []*memberCluster
+}
+
+// NewFederationController creates a new federation Controller of the member
+// clusters.
+func NewFederationController(config *rest.Config, members []Member) (*Controller, error) {
+	fc := &Controller{
+		arbclients: clientset.NewForConfigOrDie(config),
+	}
+
+	for _, m := range members {
+		mc, err := newMemberCluster(m)
+		if err != nil {
+			return nil, err
+		}
+		fc.members = append(fc.members, mc)
+	}
+
+	return fc, nil
+}
+
+// Run starts federation Controller.
+func (fc *Controller) Run(stopCh <-chan struct{}) {
+	go wait.Until(fc.dispatch, dispatchPeriod, stopCh)
+}
+
+func (fc *Controller) dispatch() {
+	glog.V(4).Infof("Start dispatching QueueJobs ...")
+	defer glog.V(4).Infof("End dispatching QueueJobs ...")
+
+	fc.refreshCapacity()
+
+	list, err := fc.arbclients.ArbV1().QueueJobs(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		glog.Errorf("Failed to list QueueJobs: %v", err)
+		return
+	}
+
+	var queueJobs []*arbv1.QueueJob
+	for i := range list.Items {
+		qj := &list.Items[i]
+		if _, dispatched := qj.Annotations[arbv1.ClusterAnnotation]; dispatched || qj.DeletionTimestamp != nil {
+			continue
+		}
+		queueJobs = append(queueJobs, qj)
+	}
+
+	// Dispatch the QueueJobs in the order of creation.
+	sort.Slice(queueJobs, func(i, j int) bool {
+		return queueJobs[i].CreationTimestamp.Before(&queueJobs[j].CreationTimestamp)
+	})
+
+	for _, qj := range queueJobs {
+		total, maxTask := queueJobRequest(qj)
+
+		mc := bestFit(fc.members, total, maxTask)
+		if mc == nil {
+			glog.V(3).Infof("No member cluster fits QueueJob <%v/%v> requesting %v, keep it pending",
+				qj.Namespace, qj.Name, total)
+			continue
+		}
+
+		if err := fc.dispatchTo(qj, mc); err != nil {
+			glog.Errorf("Failed to dispatch QueueJob <%v/%v> to cluster %s: %v",
+				qj.Namespace, qj.Name, mc.Name, err)
+			metrics.Add(dispatchFailedMetric, 1)
+			continue
+		}
+
+		glog.V(3).Infof("Dispatched QueueJob <%v/%v> to cluster %s", qj.Namespace, qj.Name, mc.Name)
+		metrics.Add(dispatchedMetric, 1)
+
+		// Deduct the QueueJob until the next report of cluster.
+		mc.capacity.Idle.Sub(total)
+		mc.capacity.PendingJobs++
+	}
+}
+
+// refreshCapacity fetches the capacity reports of member clusters; the
+// cluster whose report is not available is not dispatched to.
+func (fc *Controller) refreshCapacity() {
+	for _, mc := range fc.members {
+		capacity, err := mc.fetchCapacity()
+		if err != nil {
+			glog.Errorf("Failed to fetch capacity of cluster %s: %v", mc.Name, err)
+			mc.capacity = nil
+			continue
+		}
+
+		if age := time.Since(capacity.Timestamp); age > staleCapacity {
+			glog.Warningf("Capacity of cluster %s is stale (%v ago), skip it", mc.Name, age)
+			mc.capacity = nil
+			continue
+		}
+
+		mc.capacity = capacity
+	}
+}
+
+// dispatchTo creates the QueueJob in member cluster, and annotates the
+// QueueJob in host cluster by the member cluster.
+func (fc *Controller) dispatchTo(qj *arbv1.QueueJob, mc *memberCluster) error {
+	_, err := mc.arbclients.ArbV1().QueueJobs(qj.Namespace).Create(&arbv1.QueueJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        qj.Name,
+			Namespace:   qj.Namespace,
+			Labels:      qj.Labels,
+			Annotations: qj.Annotations,
+		},
+		Spec: *qj.Spec.DeepCopy(),
+	})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	qj = qj.DeepCopy()
+	if qj.Annotations == nil {
+		qj.Annotations = map[string]string{}
+	}
+	qj.Annotations[arbv1.ClusterAnnotation] = mc.Name
+
+	_, err = fc.arbclients.ArbV1().QueueJobs(qj.Namespace).Update(qj)
+	return err
+}
+
+// queueJobRequest returns the total requests of the pods of QueueJob, and the
+// maximum requests of its pods.
+func queueJobRequest(qj *arbv1.QueueJob) (*api.Resource, *api.Resource) {
+	total := api.EmptyResource()
+	maxTask := api.EmptyResource()
+
+	for _, ts := range utils.GetTaskSpecs(qj) {
+		req := api.EmptyResource()
+		for _, c := range ts.Template.Spec.Containers {
+			req.Add(api.NewResource(c.Resources.Requests))
+		}
+
+		for i := int32(0); i < ts.Replicas; i++ {
+			total.Add(req)
+		}
+
+		if req.MilliCPU > maxTask.MilliCPU {
+			maxTask.MilliCPU = req.MilliCPU
+		}
+		if req.Memory > maxTask.Memory {
+			maxTask.Memory = req.Memory
+		}
+		if req.GPU > maxTask.GPU {
+			maxTask.GPU = req.GPU
+		}
+	}
+
+	return total, maxTask
+}
+
+// bestFit returns the member cluster which fits the request best: the least
+// share of allocatable resources idle after placing it, and the fewest
+// pending jobs if tie; nil if no cluster fits.
+func bestFit(members []*memberCluster, total, maxTask *api.Resource) *memberCluster {
+	var best *memberCluster
+	bestScore := 0.0
+
+	for _, mc := range members {
+		c := mc.capacity
+		if c == nil || !total.LessEqual(c.Idle) || !maxTask.LessEqual(c.MaxNodeIdle) {
+			continue
+		}
+
+		score := 0.0
+		for _, rn := range api.ResourceNames() {
+			if allocatable := c.Allocatable.Get(rn); allocatable > 0 {
+				score += (c.Idle.Get(rn) - total.Get(rn)) / allocatable
+			}
+		}
+
+		if best == nil || score < bestScore ||
+			(score == bestScore && c.PendingJobs < best.capacity.PendingJobs) {
+			best = mc
+			bestScore = score
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federation
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+func buildMember(name string, allocatable, idle, maxNodeIdle float64) *memberCluster {
+	return &memberCluster{
+		Member: Member{Name: name},
+		capacity: &api.ClusterCapacity{
+			Allocatable: &api.Resource{MilliCPU: allocatable, Memory: allocatable},
+			Idle:        &api.Resource{MilliCPU: idle, Memory: idle},
+			MaxNodeIdle: &api.Resource{MilliCPU: maxNodeIdle, Memory: maxNodeIdle},
+		},
+	}
+}
+
+func TestQueueJobRequest(t *testing.T) {
+	qj := &arbv1.QueueJob{
+		Spec: arbv1.QueueJobSpec{
+			Replicas: 3,
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	total, maxTask := queueJobRequest(qj)
+	if total.MilliCPU != 6000 || maxTask.MilliCPU != 2000 {
+		t.Errorf("expected total 6000m and max task 2000m cpu, got %v and %v", total, maxTask)
+	}
+}
+
+func TestBestFit(t *testing.T) {
+	large := buildMember("large", 100000, 50000, 8000)
+	small := buildMember("small", 20000, 10000, 8000)
+	fragmented := buildMember("fragmented", 100000, 50000, 1000)
+	members := []*memberCluster{large, small, fragmented}
+
+	// The small cluster has the least idle share left.
+	req := &api.Resource{MilliCPU: 6000, Memory: 6000}
+	task := &api.Resource{MilliCPU: 2000, Memory: 2000}
+	if mc := bestFit(members, req, task); mc != small {
+		t.Errorf("expected small cluster fits best, got %v", mc)
+	}
+
+	req = &api.Resource{MilliCPU: 20000, Memory: 20000}
+	if mc := bestFit(members, req, task); mc != large {
+		t.Errorf("expected the large cluster whose nodes fit the tasks, got %v", mc)
+	}
+
+	task = &api.Resource{MilliCPU: 10000, Memory: 10000}
+	if mc := bestFit(members, req, task); mc != nil {
+		t.Errorf("expected no cluster fits the task, got %v", mc.Name)
+	}
+}
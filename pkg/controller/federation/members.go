@@ -0,0 +1,131 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+// Member is a member cluster which QueueJobs are dispatched to.
+type Member struct {
+	// Name is the unique name of the cluster.
+	Name string `json:"name"`
+	// Kubeconfig is the path of kubeconfig file to access the cluster.
+	Kubeconfig string `json:"kubeconfig"`
+	// CapacityURL is the capacity report of kar-scheduler in the cluster,
+	// e.g. http://kar-scheduler.kube-system:8080/federation/capacity.
+	CapacityURL string `json:"capacityURL"`
+}
+
+// MembersConfig is the file of member clusters in YAML or JSON, e.g.
+//
+//	members:
+//	- name: cluster-a
+//	  kubeconfig: /etc/federation/cluster-a.kubeconfig
+//	  capacityURL: http://kar-scheduler.cluster-a:8080/federation/capacity
+type MembersConfig struct {
+	Members []Member `json:"members"`
+}
+
+// LoadMembers loads the member clusters from path.
+func LoadMembers(path string) ([]Member, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config := &MembersConfig{}
+	if err := yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(config); err != nil {
+		return nil, fmt.Errorf("failed to parse member clusters %s: %v", path, err)
+	}
+
+	names := map[string]bool{}
+	for _, m := range config.Members {
+		if len(m.Name) == 0 || len(m.Kubeconfig) == 0 || len(m.CapacityURL) == 0 {
+			return nil, fmt.Errorf("name, kubeconfig and capacityURL of member clusters are required in %s", path)
+		}
+		if names[m.Name] {
+			return nil, fmt.Errorf("duplicated member cluster %s in %s", m.Name, path)
+		}
+		names[m.Name] = true
+	}
+	if len(config.Members) == 0 {
+		return nil, fmt.Errorf("no member clusters in %s", path)
+	}
+
+	return config.Members, nil
+}
+
+// memberCluster is the clients and the latest capacity of a member cluster.
+type memberCluster struct {
+	Member
+
+	arbclients *clientset.Clientset
+	httpClient *http.Client
+
+	// capacity is the latest capacity reported by scheduler, with the
+	// QueueJobs dispatched after it's reported deducted; nil if it's not
+	// available.
+	capacity *api.ClusterCapacity
+}
+
+func newMemberCluster(m Member) (*memberCluster, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", m.Kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig of member cluster %s: %v", m.Name, err)
+	}
+
+	return &memberCluster{
+		Member:     m,
+		arbclients: clientset.NewForConfigOrDie(config),
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}, nil
+}
+
+// fetchCapacity fetches the capacity report of member cluster.
+func (mc *memberCluster) fetchCapacity() (*api.ClusterCapacity, error) {
+	resp, err := mc.httpClient.Get(mc.CapacityURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s of %s", resp.Status, mc.CapacityURL)
+	}
+
+	capacity := &api.ClusterCapacity{}
+	if err := json.NewDecoder(resp.Body).Decode(capacity); err != nil {
+		return nil, fmt.Errorf("failed to decode capacity of %s: %v", mc.CapacityURL, err)
+	}
+	if capacity.Allocatable == nil || capacity.Idle == nil || capacity.MaxNodeIdle == nil {
+		return nil, fmt.Errorf("incomplete capacity of %s", mc.CapacityURL)
+	}
+
+	return capacity, nil
+}
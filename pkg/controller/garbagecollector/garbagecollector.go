@@ -0,0 +1,325 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package garbagecollector
+
+import (
+	"expvar"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	policyinformers "k8s.io/client-go/informers/policy/v1beta1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+	arbinformers "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers"
+	informersv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers/v1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+const (
+	// gcPeriod is the interval between two garbage collection sweeps.
+	gcPeriod = 30 * time.Second
+
+	queueJobKind = "QueueJob"
+)
+
+// metrics are exposed by expvar, e.g. "garbagecollector": {"orphan_schedulingspecs": 1, ...};
+// they're the number of orphaned objects deleted.
+var (
+	metrics                     = expvar.NewMap("garbagecollector")
+	orphanSchedulingSpecsMetric = "orphan_schedulingspecs"
+	orphanPDBsMetric            = "orphan_pdbs"
+)
+
+// GarbageCollector deletes gang objects (SchedulingSpec, PDB and QueueJob)
+// which are no longer needed, e.g. finished gangs whose TTL expired, or the
+// SchedulingSpecs and PDBs whose owner workload was deleted.
+type GarbageCollector struct {
+	clients    *kubernetes.Clientset
+	arbclients *clientset.Clientset
+
+	schedulingSpecInformer informersv1.SchedulingSpecInformer
+	podInformer            coreinformers.PodInformer
+	pdbInformer            policyinformers.PodDisruptionBudgetInformer
+}
+
+// NewGarbageCollector creates a new GarbageCollector.
+func NewGarbageCollector(config *rest.Config) *GarbageCollector {
+	gc := &GarbageCollector{
+		clients:    kubernetes.NewForConfigOrDie(config),
+		arbclients: clientset.NewForConfigOrDie(config),
+	}
+
+	arbClient, _, err := client.NewClient(config)
+	if err != nil {
+		panic(err)
+	}
+
+	gc.schedulingSpecInformer = arbinformers.NewSharedInformerFactory(arbClient, 0).SchedulingSpec().SchedulingSpecs()
+
+	informerFactory := informers.NewSharedInformerFactory(gc.clients, 0)
+	gc.podInformer = informerFactory.Core().V1().Pods()
+	gc.pdbInformer = informerFactory.Policy().V1beta1().PodDisruptionBudgets()
+
+	return gc
+}
+
+// Run starts the garbage collection loop.
+func (gc *GarbageCollector) Run(stopCh <-chan struct{}) {
+	go gc.schedulingSpecInformer.Informer().Run(stopCh)
+	go gc.podInformer.Informer().Run(stopCh)
+	go gc.pdbInformer.Informer().Run(stopCh)
+
+	cache.WaitForCacheSync(stopCh,
+		gc.schedulingSpecInformer.Informer().HasSynced,
+		gc.podInformer.Informer().HasSynced,
+		gc.pdbInformer.Informer().HasSynced)
+
+	go wait.Until(gc.sweep, gcPeriod, stopCh)
+}
+
+func (gc *GarbageCollector) sweep() {
+	glog.V(4).Infof("Start garbage collection ...")
+	defer glog.V(4).Infof("End garbage collection ...")
+
+	specs, err := gc.schedulingSpecInformer.Lister().List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to list SchedulingSpecs: %v", err)
+		return
+	}
+
+	pods, err := gc.podInformer.Lister().List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to list pods: %v", err)
+		return
+	}
+	owned := podsByJob(pods)
+
+	for _, ss := range specs {
+		expireAt, finished := expireTime(ss, owned[api.SchedulingSpecJobID(ss)])
+		if !finished {
+			continue
+		}
+
+		if time.Now().Before(expireAt) {
+			glog.V(4).Infof("SchedulingSpec <%v/%v> finished, will be deleted at %v",
+				ss.Namespace, ss.Name, expireAt)
+			continue
+		}
+
+		glog.V(3).Infof("TTL of SchedulingSpec <%v/%v> expired, deleting gang objects",
+			ss.Namespace, ss.Name)
+		if err := gc.deleteGang(ss); err != nil {
+			glog.Errorf("Failed to delete gang objects of SchedulingSpec <%v/%v>: %v",
+				ss.Namespace, ss.Name, err)
+		}
+	}
+
+	gc.sweepOrphans(specs, owned)
+}
+
+// sweepOrphans deletes the SchedulingSpecs and PDBs whose owner workload no
+// longer exists. Only the objects without pods of their owner are checked, as
+// the pods are deleted together with the owner; owned is the pods grouped by
+// their jobs.
+func (gc *GarbageCollector) sweepOrphans(specs []*arbv1.SchedulingSpec, owned map[api.JobID][]*v1.Pod) {
+	options := &metav1.DeleteOptions{}
+
+	for _, ss := range specs {
+		orphan, err := gc.isOrphan(ss, owned)
+		if err != nil {
+			glog.Errorf("Failed to check owner of SchedulingSpec <%v/%v>: %v", ss.Namespace, ss.Name, err)
+			continue
+		}
+		if !orphan {
+			continue
+		}
+
+		glog.V(3).Infof("Owner of SchedulingSpec <%v/%v> was deleted, delete it as orphan", ss.Namespace, ss.Name)
+		err = gc.arbclients.ArbV1().SchedulingSpecs(ss.Namespace).Delete(ss.Name, options)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				glog.Errorf("Failed to delete orphaned SchedulingSpec <%v/%v>: %v", ss.Namespace, ss.Name, err)
+			}
+			continue
+		}
+		metrics.Add(orphanSchedulingSpecsMetric, 1)
+	}
+
+	pdbs, err := gc.pdbInformer.Lister().List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to list PDBs: %v", err)
+		return
+	}
+
+	for _, pdb := range pdbs {
+		orphan, err := gc.isOrphan(pdb, owned)
+		if err != nil {
+			glog.Errorf("Failed to check owner of PDB <%v/%v>: %v", pdb.Namespace, pdb.Name, err)
+			continue
+		}
+		if !orphan {
+			continue
+		}
+
+		glog.V(3).Infof("Owner of PDB <%v/%v> was deleted, delete it as orphan", pdb.Namespace, pdb.Name)
+		err = gc.clients.PolicyV1beta1().PodDisruptionBudgets(pdb.Namespace).Delete(pdb.Name, options)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				glog.Errorf("Failed to delete orphaned PDB <%v/%v>: %v", pdb.Namespace, pdb.Name, err)
+			}
+			continue
+		}
+		metrics.Add(orphanPDBsMetric, 1)
+	}
+}
+
+// isOrphan returns whether the controller of obj no longer exists; the object
+// without controller, or with pods of its controller, is not an orphan.
+func (gc *GarbageCollector) isOrphan(obj metav1.Object, owned map[api.JobID][]*v1.Pod) (bool, error) {
+	ref := metav1.GetControllerOf(obj)
+	if ref == nil {
+		return false, nil
+	}
+
+	if len(owned[api.JobID(ref.UID)]) != 0 {
+		return false, nil
+	}
+
+	exists, err := client.OwnerExists(gc.clients, gc.arbclients, obj.GetNamespace(), ref)
+	if err != nil {
+		return false, err
+	}
+
+	return !exists, nil
+}
+
+// podsByJob groups pods by their jobs, the same way as scheduler, so the pods
+// of each gang are found without listing all pods per gang.
+func podsByJob(pods []*v1.Pod) map[api.JobID][]*v1.Pod {
+	owned := map[api.JobID][]*v1.Pod{}
+	for _, pod := range pods {
+		job := api.PodJobID(pod)
+		owned[job] = append(owned[job], pod)
+	}
+	return owned
+}
+
+// expireTime returns the time when the TTL of the gang of ss expires, given
+// the pods of its job; the gang is finished only if ss has TTL and a job,
+// i.e. a controller or pod group, and the gang has pods and all of them are
+// terminated.
+func expireTime(ss *arbv1.SchedulingSpec, pods []*v1.Pod) (time.Time, bool) {
+	if ss.Spec.TTLSecondsAfterFinished == nil || len(api.SchedulingSpecJobID(ss)) == 0 {
+		return time.Time{}, false
+	}
+
+	finishedAt, finished := finishedTime(pods)
+	if !finished {
+		return time.Time{}, false
+	}
+
+	ttl := time.Duration(*ss.Spec.TTLSecondsAfterFinished) * time.Second
+	return finishedAt.Add(ttl), true
+}
+
+// finishedTime returns the time when the last pod of the gang finished; the
+// gang is finished only if it has pods and all of them are terminated.
+func finishedTime(pods []*v1.Pod) (time.Time, bool) {
+	var finishedAt time.Time
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed {
+			return time.Time{}, false
+		}
+
+		if t := podFinishedTime(pod); t.After(finishedAt) {
+			finishedAt = t
+		}
+	}
+
+	return finishedAt, len(pods) != 0
+}
+
+// podFinishedTime returns the latest termination time of the pod's containers;
+// fall back to the pod's creation time if no container reported it.
+func podFinishedTime(pod *v1.Pod) time.Time {
+	finishedAt := pod.CreationTimestamp.Time
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Terminated != nil && cs.State.Terminated.FinishedAt.After(finishedAt) {
+			finishedAt = cs.State.Terminated.FinishedAt.Time
+		}
+	}
+	return finishedAt
+}
+
+// deleteGang deletes the SchedulingSpec and the PDBs of the gang; if the gang is
+// owned by a QueueJob, the QueueJob is deleted instead so the QueueJob controller
+// will not re-create them.
+func (gc *GarbageCollector) deleteGang(ss *arbv1.SchedulingSpec) error {
+	policy := metav1.DeletePropagationBackground
+	options := &metav1.DeleteOptions{PropagationPolicy: &policy}
+
+	if ref := metav1.GetControllerOf(ss); ref != nil && ref.Kind == queueJobKind {
+		err := gc.arbclients.ArbV1().QueueJobs(ss.Namespace).Delete(ref.Name, options)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if err := gc.deletePDBs(ss.Namespace, api.SchedulingSpecJobID(ss), options); err != nil {
+		return err
+	}
+
+	err := gc.arbclients.ArbV1().SchedulingSpecs(ss.Namespace).Delete(ss.Name, options)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (gc *GarbageCollector) deletePDBs(namespace string, job api.JobID, options *metav1.DeleteOptions) error {
+	pdbs, err := gc.pdbInformer.Lister().PodDisruptionBudgets(namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	for _, pdb := range pdbs {
+		if api.PDBJobID(pdb) != job {
+			continue
+		}
+
+		err := gc.clients.PolicyV1beta1().PodDisruptionBudgets(namespace).Delete(pdb.Name, options)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package garbagecollector
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+var (
+	created  = time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	isOwner  = true
+	ownerRef = metav1.OwnerReference{Kind: queueJobKind, Name: "qj", UID: "qj-uid", Controller: &isOwner}
+)
+
+func buildPod(name string, phase v1.PodPhase, finishedAt *time.Time) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "c1",
+			CreationTimestamp: metav1.NewTime(created),
+			OwnerReferences:   []metav1.OwnerReference{ownerRef},
+		},
+		Status: v1.PodStatus{Phase: phase},
+	}
+	if finishedAt != nil {
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{{
+			State: v1.ContainerState{
+				Terminated: &v1.ContainerStateTerminated{FinishedAt: metav1.NewTime(*finishedAt)},
+			},
+		}}
+	}
+	return pod
+}
+
+func buildSchedulingSpec(ttl *int32, owned bool) *arbv1.SchedulingSpec {
+	ss := &arbv1.SchedulingSpec{
+		ObjectMeta: metav1.ObjectMeta{Name: "qj", Namespace: "c1"},
+		Spec:       arbv1.SchedulingSpecTemplate{TTLSecondsAfterFinished: ttl},
+	}
+	if owned {
+		ss.OwnerReferences = []metav1.OwnerReference{ownerRef}
+	}
+	return ss
+}
+
+func TestExpireTime(t *testing.T) {
+	ttl := int32(60)
+	first := created.Add(time.Minute)
+	last := created.Add(2 * time.Minute)
+
+	tests := []struct {
+		name     string
+		noGroup  bool
+		ss       *arbv1.SchedulingSpec
+		pods     []*v1.Pod
+		finished bool
+		expireAt time.Time
+	}{
+		{
+			name: "no TTL",
+			ss:   buildSchedulingSpec(nil, true),
+			pods: []*v1.Pod{buildPod("p1", v1.PodSucceeded, &first)},
+		},
+		{
+			name:    "no controller nor pod group",
+			noGroup: true,
+			ss:      buildSchedulingSpec(&ttl, false),
+			pods:    []*v1.Pod{buildPod("p1", v1.PodSucceeded, &first)},
+		},
+		{
+			name:     "pod group",
+			ss:       buildSchedulingSpec(&ttl, false),
+			pods:     []*v1.Pod{buildPod("p1", v1.PodSucceeded, &first)},
+			finished: true,
+			expireAt: first.Add(time.Minute),
+		},
+		{
+			name: "no pods",
+			ss:   buildSchedulingSpec(&ttl, true),
+		},
+		{
+			name: "running pod",
+			ss:   buildSchedulingSpec(&ttl, true),
+			pods: []*v1.Pod{
+				buildPod("p1", v1.PodSucceeded, &first),
+				buildPod("p2", v1.PodRunning, nil),
+			},
+		},
+		{
+			name: "last finished pod",
+			ss:   buildSchedulingSpec(&ttl, true),
+			pods: []*v1.Pod{
+				buildPod("p1", v1.PodSucceeded, &last),
+				buildPod("p2", v1.PodFailed, &first),
+			},
+			finished: true,
+			expireAt: last.Add(time.Minute),
+		},
+		{
+			name:     "creation time without terminated containers",
+			ss:       buildSchedulingSpec(&ttl, true),
+			pods:     []*v1.Pod{buildPod("p1", v1.PodFailed, nil)},
+			finished: true,
+			expireAt: created.Add(time.Minute),
+		},
+	}
+
+	defer func() { api.GroupNameLabel = api.DefaultGroupNameLabel }()
+	for _, test := range tests {
+		api.GroupNameLabel = api.DefaultGroupNameLabel
+		if test.noGroup {
+			api.GroupNameLabel = ""
+		}
+
+		expireAt, finished := expireTime(test.ss, test.pods)
+		if finished != test.finished {
+			t.Errorf("%s: expected finished %v, got %v", test.name, test.finished, finished)
+			continue
+		}
+		if finished && !expireAt.Equal(test.expireAt) {
+			t.Errorf("%s: expected expired at %v, got %v", test.name, test.expireAt, expireAt)
+		}
+	}
+}
+
+func TestPodsByJob(t *testing.T) {
+	grouped := buildPod("p3", v1.PodRunning, nil)
+	grouped.OwnerReferences = nil
+	grouped.Labels = map[string]string{api.DefaultGroupNameLabel: "group"}
+
+	owned := podsByJob([]*v1.Pod{
+		buildPod("p1", v1.PodRunning, nil),
+		buildPod("p2", v1.PodRunning, nil),
+		grouped,
+	})
+
+	if len(owned) != 2 || len(owned[api.JobID("qj-uid")]) != 2 || len(owned[api.GroupJobID("c1", "group")]) != 1 {
+		t.Errorf("expected 2 pods grouped by controller and 1 by label, got %v", owned)
+	}
+}
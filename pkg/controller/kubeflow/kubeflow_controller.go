@@ -0,0 +1,271 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeflow
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/workload"
+)
+
+const (
+	// syncPeriod is the interval between two syncs of Kubeflow jobs.
+	syncPeriod = 10 * time.Second
+
+	kubeflowGroup = "kubeflow.org"
+)
+
+var (
+	// TFJobVersion is the API version of TFJob served by tf-operator.
+	TFJobVersion = "v1alpha2"
+	// MPIJobVersion is the API version of MPIJob served by mpi-operator.
+	MPIJobVersion = "v1alpha1"
+)
+
+// The roles of TFJob not required to start the training together, so they're
+// not counted in the gang.
+var optionalTFRoles = map[string]bool{
+	"Evaluator": true,
+}
+
+// Controller creates SchedulingSpec for the TFJobs and MPIJobs whose pods are
+// scheduled by kar-scheduler, so Kubeflow jobs are gang-scheduled without
+// changing their operators. The minAvailable of gang is the sum of the
+// minimums of its roles, unless it's set by MinAvailableAnnotation of the job.
+//
+// The pods of TFJob are controlled by the TFJob, so its SchedulingSpec is
+// controlled by the TFJob too. The pods of MPIJob are controlled by a Job and a
+// StatefulSet, and grouped by their MPIJob name label in scheduler; so its
+// SchedulingSpec is named by the MPIJob and owned by it without controller,
+// which requires the label grouping of scheduler.
+type Controller struct {
+	clients       *kubernetes.Clientset
+	arbclients    *clientset.Clientset
+	schedulerName string
+}
+
+// NewKubeflowController creates a new Kubeflow Controller.
+func NewKubeflowController(config *rest.Config, schedulerName string) *Controller {
+	return &Controller{
+		clients:       kubernetes.NewForConfigOrDie(config),
+		arbclients:    clientset.NewForConfigOrDie(config),
+		schedulerName: schedulerName,
+	}
+}
+
+// Run starts Kubeflow Controller.
+func (kc *Controller) Run(stopCh <-chan struct{}) {
+	go wait.Until(kc.sync, syncPeriod, stopCh)
+}
+
+func (kc *Controller) sync() {
+	glog.V(4).Infof("Start syncing Kubeflow jobs ...")
+	defer glog.V(4).Infof("End syncing Kubeflow jobs ...")
+
+	tfJobs := &TFJobList{}
+	if kc.list(TFJobVersion, "tfjobs", tfJobs) {
+		kind := schema.GroupVersionKind{Group: kubeflowGroup, Version: TFJobVersion, Kind: "TFJob"}
+		for i := range tfJobs.Items {
+			job := &tfJobs.Items[i]
+
+			var templates []*v1.PodTemplateSpec
+			for _, spec := range job.Spec.TFReplicaSpecs {
+				if spec != nil {
+					templates = append(templates, &spec.Template)
+				}
+			}
+			if !kc.scheduledBy(templates) {
+				continue
+			}
+
+			if err := kc.syncSchedulingSpec(&job.ObjectMeta, kind, true, tfJobMinAvailable(job)); err != nil {
+				glog.Errorf("Failed to sync SchedulingSpec of TFJob <%v/%v>: %v", job.Namespace, job.Name, err)
+			}
+		}
+	}
+
+	mpiJobs := &MPIJobList{}
+	if kc.list(MPIJobVersion, "mpijobs", mpiJobs) {
+		kind := schema.GroupVersionKind{Group: kubeflowGroup, Version: MPIJobVersion, Kind: "MPIJob"}
+		for i := range mpiJobs.Items {
+			job := &mpiJobs.Items[i]
+			if !kc.scheduledBy([]*v1.PodTemplateSpec{&job.Spec.Template}) {
+				continue
+			}
+
+			if err := kc.syncSchedulingSpec(&job.ObjectMeta, kind, false, mpiJobMinAvailable(job)); err != nil {
+				glog.Errorf("Failed to sync SchedulingSpec of MPIJob <%v/%v>: %v", job.Namespace, job.Name, err)
+			}
+		}
+	}
+}
+
+// list lists the Kubeflow jobs of resource in all namespaces into list; it
+// returns false if they can't be listed, e.g. the operator is not installed.
+func (kc *Controller) list(version, resource string, list interface{}) bool {
+	data, err := kc.clients.CoreV1().RESTClient().Get().
+		AbsPath("/apis", kubeflowGroup, version, resource).DoRaw()
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			glog.V(4).Infof("Resource %s/%s of %s is not found, skip it", version, resource, kubeflowGroup)
+		} else {
+			glog.Errorf("Failed to list %s/%s of %s: %v", version, resource, kubeflowGroup, err)
+		}
+		return false
+	}
+
+	if err := json.Unmarshal(data, list); err != nil {
+		glog.Errorf("Failed to decode %s/%s of %s: %v", version, resource, kubeflowGroup, err)
+		return false
+	}
+	return true
+}
+
+// scheduledBy returns whether the pods of templates are scheduled by
+// kar-scheduler.
+func (kc *Controller) scheduledBy(templates []*v1.PodTemplateSpec) bool {
+	for _, t := range templates {
+		if t.Spec.SchedulerName == kc.schedulerName {
+			return true
+		}
+	}
+	return false
+}
+
+// syncSchedulingSpec makes sure the SchedulingSpec of owner exists with the
+// expected MinAvailable; it's controlled by owner if controller is true.
+func (kc *Controller) syncSchedulingSpec(owner *metav1.ObjectMeta, kind schema.GroupVersionKind, controller bool, min int) error {
+	ssClient := kc.arbclients.ArbV1().SchedulingSpecs(owner.Namespace)
+
+	ss, err := ssClient.Get(owner.Name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		ref := metav1.NewControllerRef(owner, kind)
+		ref.Controller = &controller
+
+		glog.V(3).Infof("Create SchedulingSpec <%v/%v> for %v, minAvailable %v",
+			owner.Namespace, owner.Name, kind.Kind, min)
+		_, err := ssClient.Create(&arbv1.SchedulingSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            owner.Name,
+				Namespace:       owner.Namespace,
+				OwnerReferences: []metav1.OwnerReference{*ref},
+			},
+			Spec: arbv1.SchedulingSpecTemplate{
+				MinAvailable: min,
+			},
+		})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+		return nil
+	}
+
+	if !ownedBy(ss, owner) {
+		glog.V(3).Infof("SchedulingSpec <%v/%v> is not owned by %v, ignore it",
+			ss.Namespace, ss.Name, kind.Kind)
+		return nil
+	}
+
+	if ss.Spec.MinAvailable != min {
+		glog.V(3).Infof("Update minAvailable of SchedulingSpec <%v/%v>: %v -> %v",
+			ss.Namespace, ss.Name, ss.Spec.MinAvailable, min)
+		ss.Spec.MinAvailable = min
+		if _, err := ssClient.Update(ss); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ownedBy(ss *arbv1.SchedulingSpec, owner *metav1.ObjectMeta) bool {
+	for _, ref := range ss.OwnerReferences {
+		if ref.UID == owner.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// tfJobMinAvailable returns the minAvailable of TFJob: all pods of its roles
+// except the optional ones, e.g. PS, Worker and Chief.
+func tfJobMinAvailable(job *TFJob) int {
+	if min, found := annotatedMinAvailable(&job.ObjectMeta); found {
+		return min
+	}
+
+	min := 0
+	for role, spec := range job.Spec.TFReplicaSpecs {
+		if spec == nil || optionalTFRoles[role] {
+			continue
+		}
+		min += replicas(spec.Replicas)
+	}
+	return min
+}
+
+// mpiJobMinAvailable returns the minAvailable of MPIJob: the launcher and all
+// of its workers.
+func mpiJobMinAvailable(job *MPIJob) int {
+	if min, found := annotatedMinAvailable(&job.ObjectMeta); found {
+		return min
+	}
+
+	return 1 + replicas(job.Spec.Replicas)
+}
+
+// annotatedMinAvailable returns the minAvailable set by MinAvailableAnnotation
+// of the job.
+func annotatedMinAvailable(job *metav1.ObjectMeta) (int, bool) {
+	value, found := job.Annotations[workload.MinAvailableAnnotation]
+	if !found {
+		return 0, false
+	}
+
+	min, err := strconv.Atoi(value)
+	if err != nil || min < 0 {
+		glog.Warningf("Invalid value %q of annotation %s of <%v/%v>, use the minimums of roles instead",
+			value, workload.MinAvailableAnnotation, job.Namespace, job.Name)
+		return 0, false
+	}
+	return min, true
+}
+
+func replicas(r *int32) int {
+	if r == nil {
+		return 1
+	}
+	return int(*r)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeflow
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTFJobMinAvailable(t *testing.T) {
+	job := &TFJob{}
+	if err := json.Unmarshal([]byte(`{
+		"metadata": {"name": "mnist", "namespace": "c1"},
+		"spec": {"tfReplicaSpecs": {
+			"PS": {"replicas": 2},
+			"Worker": {"replicas": 4},
+			"Chief": {},
+			"Evaluator": {"replicas": 1}
+		}}
+	}`), job); err != nil {
+		t.Fatal(err)
+	}
+
+	// The Evaluator is not counted, and Chief is 1 replica by default.
+	if min := tfJobMinAvailable(job); min != 7 {
+		t.Errorf("expected minAvailable 7, got %d", min)
+	}
+
+	job.Annotations = map[string]string{"arbitrator/min-available": "3"}
+	if min := tfJobMinAvailable(job); min != 3 {
+		t.Errorf("expected annotated minAvailable 3, got %d", min)
+	}
+}
+
+func TestMPIJobMinAvailable(t *testing.T) {
+	job := &MPIJob{}
+	if err := json.Unmarshal([]byte(`{"spec": {"replicas": 4}}`), job); err != nil {
+		t.Fatal(err)
+	}
+
+	if min := mpiJobMinAvailable(job); min != 5 {
+		t.Errorf("expected launcher and 4 workers, got %d", min)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeflow
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The types below are the subset of Kubeflow CRDs used to build the gangs of
+// jobs; they're decoded from the JSON of API server, so the operators' clients
+// are not required.
+
+// TFJob is the TFJob of tf-operator.
+type TFJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec TFJobSpec `json:"spec"`
+}
+
+// TFJobSpec is the spec of TFJob, whose replicas are keyed by the role, e.g.
+// PS, Worker and Chief.
+type TFJobSpec struct {
+	TFReplicaSpecs map[string]*ReplicaSpec `json:"tfReplicaSpecs"`
+}
+
+// ReplicaSpec is the pods of a role of TFJob.
+type ReplicaSpec struct {
+	// Replicas is the number of pods of the role; default to 1.
+	Replicas *int32             `json:"replicas,omitempty"`
+	Template v1.PodTemplateSpec `json:"template,omitempty"`
+}
+
+// TFJobList is the list of TFJob.
+type TFJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TFJob `json:"items"`
+}
+
+// MPIJob is the MPIJob of mpi-operator.
+type MPIJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec MPIJobSpec `json:"spec"`
+}
+
+// MPIJobSpec is the spec of MPIJob; it has one launcher and the workers of
+// the template.
+type MPIJobSpec struct {
+	// Replicas is the number of workers.
+	Replicas *int32             `json:"replicas,omitempty"`
+	Template v1.PodTemplateSpec `json:"template,omitempty"`
+}
+
+// MPIJobList is the list of MPIJob.
+type MPIJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []MPIJob `json:"items"`
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdb
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/golang/glog"
+
+	policyv1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	policyinformers "k8s.io/client-go/informers/policy/v1beta1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client"
+	arbinformers "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers"
+	informersv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers/v1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/workqueue"
+)
+
+// Controller creates a PodDisruptionBudget for each SchedulingSpec, whose
+// minAvailable is the same as the gang, so the running gangs are protected
+// from the evictions of other components, e.g. node drain.
+//
+// The PDB has the same name and controller as the SchedulingSpec, so the
+// scheduler takes it as the PDB of the same job; the SchedulingSpec without
+// controller (grouped by label) is ignored.
+type Controller struct {
+	clients *kubernetes.Clientset
+
+	schedulingSpecInformer informersv1.SchedulingSpecInformer
+	pdbInformer            policyinformers.PodDisruptionBudgetInformer
+
+	// queue of SchedulingSpec keys that need to sync up
+	queue workqueue.RateLimitingInterface
+}
+
+// NewPDBController creates a new PDB Controller.
+func NewPDBController(config *rest.Config) *Controller {
+	pc := &Controller{
+		clients: kubernetes.NewForConfigOrDie(config),
+		queue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "pdb"),
+	}
+
+	arbClient, _, err := client.NewClient(config)
+	if err != nil {
+		panic(err)
+	}
+
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc: pc.enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			pc.enqueue(newObj)
+		},
+		DeleteFunc: pc.enqueue,
+	}
+
+	pc.schedulingSpecInformer = arbinformers.NewSharedInformerFactory(arbClient, 0).SchedulingSpec().SchedulingSpecs()
+	pc.schedulingSpecInformer.Informer().AddEventHandler(handler)
+
+	// The PDB is synced by its SchedulingSpec of the same name, e.g. re-created
+	// after deleted by others.
+	pc.pdbInformer = informers.NewSharedInformerFactory(pc.clients, 0).Policy().V1beta1().PodDisruptionBudgets()
+	pc.pdbInformer.Informer().AddEventHandler(handler)
+
+	return pc
+}
+
+// Run starts PDB Controller.
+func (pc *Controller) Run(stopCh <-chan struct{}) {
+	go pc.schedulingSpecInformer.Informer().Run(stopCh)
+	go pc.pdbInformer.Informer().Run(stopCh)
+
+	cache.WaitForCacheSync(stopCh,
+		pc.schedulingSpecInformer.Informer().HasSynced,
+		pc.pdbInformer.Informer().HasSynced)
+
+	go wait.Until(pc.worker, time.Second, stopCh)
+
+	go func() {
+		<-stopCh
+		pc.queue.ShutDown()
+	}()
+}
+
+func (pc *Controller) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		glog.Errorf("Failed to get key of %v: %v", obj, err)
+		return
+	}
+
+	pc.queue.Add(key)
+}
+
+func (pc *Controller) worker() {
+	for pc.processNextItem() {
+	}
+}
+
+func (pc *Controller) processNextItem() bool {
+	key, shutdown := pc.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer pc.queue.Done(key)
+
+	if err := pc.sync(key.(string)); err != nil {
+		glog.Errorf("Failed to sync PDB of SchedulingSpec %s (retried %d times): %v",
+			key, pc.queue.NumRequeues(key), err)
+		pc.queue.AddRateLimited(key)
+		return true
+	}
+
+	pc.queue.Forget(key)
+	return true
+}
+
+func (pc *Controller) sync(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+
+	ss, err := pc.schedulingSpecInformer.Lister().SchedulingSpecs(namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// The PDB is garbage collected with the controller of SchedulingSpec.
+			return nil
+		}
+		return err
+	}
+
+	ref := metav1.GetControllerOf(ss)
+	if ref == nil {
+		glog.V(4).Infof("SchedulingSpec %s has no controller, skip its PDB", key)
+		return nil
+	}
+
+	pdb, err := pc.pdbInformer.Lister().PodDisruptionBudgets(namespace).Get(name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		pdb = nil
+	}
+
+	if pdb != nil {
+		if pdbRef := metav1.GetControllerOf(pdb); pdbRef == nil || pdbRef.UID != ref.UID {
+			glog.V(3).Infof("PDB %s is not controlled by the controller of SchedulingSpec, ignore it", key)
+			return nil
+		}
+	}
+
+	if ss.Spec.MinAvailable <= 0 {
+		if pdb != nil {
+			return pc.deletePDB(pdb)
+		}
+		return nil
+	}
+
+	selector, err := pc.podSelector(namespace, ref)
+	if err != nil {
+		return err
+	}
+
+	desired := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       namespace,
+			OwnerReferences: []metav1.OwnerReference{*ref},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: intOrStringPtr(intstr.FromInt(ss.Spec.MinAvailable)),
+			Selector:     selector,
+		},
+	}
+
+	if pdb != nil {
+		if reflect.DeepEqual(pdb.Spec.MinAvailable, desired.Spec.MinAvailable) &&
+			reflect.DeepEqual(pdb.Spec.Selector, desired.Spec.Selector) {
+			return nil
+		}
+
+		// The spec of PDB is immutable, so re-create it.
+		glog.V(3).Infof("Re-create PDB %s as its spec changed", key)
+		if err := pc.deletePDB(pdb); err != nil {
+			return err
+		}
+	}
+
+	glog.V(3).Infof("Create PDB %s with minAvailable %d", key, ss.Spec.MinAvailable)
+	_, err = pc.clients.PolicyV1beta1().PodDisruptionBudgets(namespace).Create(desired)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (pc *Controller) deletePDB(pdb *policyv1.PodDisruptionBudget) error {
+	err := pc.clients.PolicyV1beta1().PodDisruptionBudgets(pdb.Namespace).Delete(pdb.Name, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// podSelector returns the selector of the pods controlled by ref.
+func (pc *Controller) podSelector(namespace string, ref *metav1.OwnerReference) (*metav1.LabelSelector, error) {
+	switch ref.Kind {
+	case "QueueJob":
+		return &metav1.LabelSelector{
+			MatchLabels: map[string]string{arbv1.QueueJobLabel: ref.Name},
+		}, nil
+	case "ReplicaSet":
+		rs, err := pc.clients.AppsV1().ReplicaSets(namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return rs.Spec.Selector, nil
+	case "StatefulSet":
+		sts, err := pc.clients.AppsV1().StatefulSets(namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return sts.Spec.Selector, nil
+	}
+
+	return nil, fmt.Errorf("un-supported controller kind <%s> of SchedulingSpec", ref.Kind)
+}
+
+func intOrStringPtr(v intstr.IntOrString) *intstr.IntOrString {
+	return &v
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdb
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+)
+
+func TestPodSelector(t *testing.T) {
+	rsSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"app": "rs1"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/apps/v1/namespaces/c1/replicasets/rs1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&appsv1.ReplicaSet{
+			TypeMeta:   metav1.TypeMeta{Kind: "ReplicaSet", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "rs1", Namespace: "c1"},
+			Spec:       appsv1.ReplicaSetSpec{Selector: rsSelector},
+		})
+	}))
+	defer server.Close()
+
+	pc := &Controller{
+		clients: kubernetes.NewForConfigOrDie(&rest.Config{Host: server.URL}),
+	}
+
+	tests := []struct {
+		name     string
+		ref      *metav1.OwnerReference
+		expected *metav1.LabelSelector
+		err      bool
+	}{
+		{
+			name: "QueueJob",
+			ref:  &metav1.OwnerReference{Kind: "QueueJob", Name: "qj1"},
+			expected: &metav1.LabelSelector{
+				MatchLabels: map[string]string{arbv1.QueueJobLabel: "qj1"},
+			},
+		},
+		{
+			name:     "ReplicaSet",
+			ref:      &metav1.OwnerReference{Kind: "ReplicaSet", Name: "rs1"},
+			expected: rsSelector,
+		},
+		{
+			name: "ReplicaSet not found",
+			ref:  &metav1.OwnerReference{Kind: "ReplicaSet", Name: "rs2"},
+			err:  true,
+		},
+		{
+			name: "un-supported kind",
+			ref:  &metav1.OwnerReference{Kind: "Deployment", Name: "d1"},
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		selector, err := pc.podSelector("c1", test.ref)
+		if test.err {
+			if err == nil {
+				t.Errorf("case <%s>: expected error, got selector %v", test.name, selector)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case <%s>: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(selector, test.expected) {
+			t.Errorf("case <%s>: expected selector %v, got %v", test.name, test.expected, selector)
+		}
+	}
+}
@@ -0,0 +1,443 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+	arbinformers "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers"
+	informersv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers/v1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/workqueue"
+)
+
+const (
+	// resyncPeriod is the interval to re-sync all Queues, in case the events
+	// of their SchedulingSpecs or pods are missed.
+	resyncPeriod = 30 * time.Second
+
+	componentName = "queue-controller"
+
+	// OverSubscribedReason is the reason of the event when Queue's requests
+	// exceed its capability.
+	OverSubscribedReason = "OverSubscribed"
+
+	// jobIndex indexes the SchedulingSpecs and pods by their jobs.
+	jobIndex = "job"
+)
+
+// Controller maintains the status and finalizer of Queues.
+type Controller struct {
+	config     *rest.Config
+	clients    *kubernetes.Clientset
+	arbclients *clientset.Clientset
+	recorder   *client.EventRecorder
+
+	// groupNameLabel is the label grouping pods without controller into the
+	// job of SchedulingSpec, the same as kar-scheduler's; empty to disable it.
+	groupNameLabel string
+
+	queueInformer          informersv1.QueueInformer
+	schedulingSpecInformer informersv1.SchedulingSpecInformer
+	podInformer            coreinformers.PodInformer
+
+	// queue of Queue names that need to sync up
+	queue workqueue.RateLimitingInterface
+}
+
+// NewQueueController creates a new Queue Controller.
+func NewQueueController(config *rest.Config, groupNameLabel string) *Controller {
+	qc := &Controller{
+		config:         config,
+		clients:        kubernetes.NewForConfigOrDie(config),
+		arbclients:     clientset.NewForConfigOrDie(config),
+		groupNameLabel: groupNameLabel,
+		queue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "queue"),
+	}
+	qc.recorder = client.NewEventRecorder(qc.clients, componentName)
+
+	arbClient, _, err := client.NewClient(config)
+	if err != nil {
+		panic(err)
+	}
+
+	arbInformerFactory := arbinformers.NewSharedInformerFactory(arbClient, 0)
+
+	qc.queueInformer = arbInformerFactory.Queue().Queues()
+	qc.queueInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: qc.enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			qc.enqueue(newObj)
+		},
+	})
+
+	qc.schedulingSpecInformer = arbInformerFactory.SchedulingSpec().SchedulingSpecs()
+	qc.schedulingSpecInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: qc.enqueueSchedulingSpecQueue,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			qc.enqueueSchedulingSpecQueue(oldObj)
+			qc.enqueueSchedulingSpecQueue(newObj)
+		},
+		DeleteFunc: qc.enqueueSchedulingSpecQueue,
+	})
+	qc.schedulingSpecInformer.Informer().AddIndexers(cache.Indexers{jobIndex: qc.schedulingSpecJobKeys})
+
+	qc.podInformer = informers.NewSharedInformerFactory(qc.clients, 0).Core().V1().Pods()
+	qc.podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: qc.enqueuePodQueue,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPod, ok1 := oldObj.(*v1.Pod)
+			newPod, ok2 := newObj.(*v1.Pod)
+			// The requests of pods are counted by their phase.
+			if ok1 && ok2 && oldPod.Status.Phase == newPod.Status.Phase {
+				return
+			}
+			qc.enqueuePodQueue(newObj)
+		},
+		DeleteFunc: qc.enqueuePodQueue,
+	})
+	qc.podInformer.Informer().AddIndexers(cache.Indexers{jobIndex: qc.podJobKeys})
+
+	return qc
+}
+
+// Run starts Queue Controller.
+func (qc *Controller) Run(stopCh <-chan struct{}) {
+	if err := createQueueKind(qc.config); err != nil {
+		glog.Errorf("Failed to create Queue CRD: %v", err)
+	}
+
+	go qc.queueInformer.Informer().Run(stopCh)
+	go qc.schedulingSpecInformer.Informer().Run(stopCh)
+	go qc.podInformer.Informer().Run(stopCh)
+
+	cache.WaitForCacheSync(stopCh,
+		qc.queueInformer.Informer().HasSynced,
+		qc.schedulingSpecInformer.Informer().HasSynced,
+		qc.podInformer.Informer().HasSynced)
+
+	go wait.Until(qc.resync, resyncPeriod, stopCh)
+	go wait.Until(qc.worker, time.Second, stopCh)
+
+	go func() {
+		<-stopCh
+		qc.queue.ShutDown()
+	}()
+}
+
+func (qc *Controller) enqueue(obj interface{}) {
+	queue, ok := obj.(*arbv1.Queue)
+	if !ok {
+		glog.Errorf("Un-supported type of %v", obj)
+		return
+	}
+
+	qc.queue.Add(queue.Name)
+}
+
+func (qc *Controller) enqueueSchedulingSpecQueue(obj interface{}) {
+	var ss *arbv1.SchedulingSpec
+	switch t := obj.(type) {
+	case *arbv1.SchedulingSpec:
+		ss = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		ss, ok = t.Obj.(*arbv1.SchedulingSpec)
+		if !ok {
+			glog.Errorf("Cannot convert to *arbv1.SchedulingSpec: %v", t.Obj)
+			return
+		}
+	default:
+		glog.Errorf("Cannot convert to *arbv1.SchedulingSpec: %v", t)
+		return
+	}
+
+	queue := utils.SchedulingSpecQueue(ss)
+	if len(queue) == 0 {
+		return
+	}
+
+	qc.queue.Add(queue)
+}
+
+// enqueuePodQueue enqueues the Queue of the pod's SchedulingSpec, so the
+// status of Queue is refreshed by the changes of its pods.
+func (qc *Controller) enqueuePodQueue(obj interface{}) {
+	var pod *v1.Pod
+	switch t := obj.(type) {
+	case *v1.Pod:
+		pod = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		pod, ok = t.Obj.(*v1.Pod)
+		if !ok {
+			glog.Errorf("Cannot convert to *v1.Pod: %v", t.Obj)
+			return
+		}
+	default:
+		glog.Errorf("Cannot convert to *v1.Pod: %v", t)
+		return
+	}
+
+	keys, _ := qc.podJobKeys(pod)
+	for _, key := range keys {
+		objs, err := qc.schedulingSpecInformer.Informer().GetIndexer().ByIndex(jobIndex, key)
+		if err != nil {
+			glog.Errorf("Failed to get SchedulingSpecs of pod %v/%v: %v", pod.Namespace, pod.Name, err)
+			return
+		}
+		for _, obj := range objs {
+			qc.enqueueSchedulingSpecQueue(obj)
+		}
+	}
+}
+
+// schedulingSpecJobKeys returns the key of the job which SchedulingSpec belongs
+// to, in the same way as scheduler: its controller, or the pod group of its
+// name if it has no controller.
+func (qc *Controller) schedulingSpecJobKeys(obj interface{}) ([]string, error) {
+	ss, ok := obj.(*arbv1.SchedulingSpec)
+	if !ok {
+		return nil, nil
+	}
+
+	if owner := utils.GetController(ss); len(owner) != 0 {
+		return []string{string(owner)}, nil
+	}
+	if len(qc.groupNameLabel) != 0 {
+		return []string{groupJobKey(ss.Namespace, ss.Name)}, nil
+	}
+	return nil, nil
+}
+
+// podJobKeys returns the keys of the jobs which pod may belong to: its
+// controller, and the pod group labeled by groupNameLabel.
+func (qc *Controller) podJobKeys(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return nil, nil
+	}
+
+	var keys []string
+	if owner := utils.GetController(pod); len(owner) != 0 {
+		keys = append(keys, string(owner))
+	}
+	if len(qc.groupNameLabel) != 0 {
+		if name := pod.Labels[qc.groupNameLabel]; len(name) != 0 {
+			keys = append(keys, groupJobKey(pod.Namespace, name))
+		}
+	}
+	return keys, nil
+}
+
+func groupJobKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+func (qc *Controller) resync() {
+	queues, err := qc.queueInformer.Lister().List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to list Queues: %v", err)
+		return
+	}
+
+	for _, queue := range queues {
+		qc.enqueue(queue)
+	}
+}
+
+func (qc *Controller) worker() {
+	for qc.processNextItem() {
+	}
+}
+
+func (qc *Controller) processNextItem() bool {
+	item, shutdown := qc.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer qc.queue.Done(item)
+
+	name := item.(string)
+
+	// Always sync the latest Queue in cache.
+	queue, err := qc.queueInformer.Lister().Get(name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			glog.Errorf("Failed to get Queue <%v>: %v", name, err)
+			qc.queue.AddRateLimited(item)
+		}
+		return true
+	}
+
+	if err := qc.syncQueue(queue.DeepCopy()); err != nil {
+		glog.Errorf("Failed to sync Queue <%v> (retried %d times): %v",
+			name, qc.queue.NumRequeues(item), err)
+		qc.queue.AddRateLimited(item)
+		return true
+	}
+
+	qc.queue.Forget(item)
+	return true
+}
+
+func (qc *Controller) syncQueue(queue *arbv1.Queue) error {
+	status, err := qc.calculateStatus(queue)
+	if err != nil {
+		return err
+	}
+
+	if overSubscribed(status.Requested, queue.Spec.Capability) &&
+		!overSubscribed(queue.Status.Requested, queue.Spec.Capability) {
+		qc.recorder.Eventf(queueReference(queue), v1.EventTypeWarning, OverSubscribedReason,
+			"Requests %v of Queue exceed its capability %v",
+			resourceListString(status.Requested), resourceListString(queue.Spec.Capability))
+	}
+
+	updated := false
+	if !statusEqual(&queue.Status, status) {
+		queue.Status = *status
+		updated = true
+	}
+
+	// Keep Queue until all workloads in it finished.
+	live := status.Pending+status.Running > 0
+	if queue.DeletionTimestamp == nil {
+		if !hasFinalizer(queue) {
+			queue.Finalizers = append(queue.Finalizers, arbv1.QueueFinalizer)
+			updated = true
+		}
+	} else if !live && hasFinalizer(queue) {
+		glog.V(3).Infof("Remove finalizer of Queue <%v> as no workloads in it", queue.Name)
+		removeFinalizer(queue)
+		updated = true
+	}
+
+	if !updated {
+		return nil
+	}
+
+	// TODO: replaced it with `UpdateStatus` after CRD supports status sub-resource.
+	_, err = qc.arbclients.ArbV1().Queues().Update(queue)
+	return err
+}
+
+// calculateStatus counts the SchedulingSpecs and requests of the pods in queue.
+func (qc *Controller) calculateStatus(queue *arbv1.Queue) (*arbv1.QueueStatus, error) {
+	specs, err := qc.schedulingSpecInformer.Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	status := &arbv1.QueueStatus{
+		Requested: v1.ResourceList{},
+	}
+
+	for _, ss := range specs {
+		if utils.SchedulingSpecQueue(ss) != queue.Name {
+			continue
+		}
+
+		pods, err := qc.getPodsForSchedulingSpec(ss)
+		if err != nil {
+			return nil, err
+		}
+
+		running, active := 0, 0
+		for _, pod := range pods {
+			switch pod.Status.Phase {
+			case v1.PodRunning:
+				running++
+				active++
+			case v1.PodPending, v1.PodUnknown:
+				active++
+			default:
+				continue
+			}
+
+			addResourceList(status.Requested, podRequests(pod))
+		}
+
+		// The SchedulingSpec without unfinished pods is finished, except the
+		// pods are not created yet.
+		if active == 0 && len(pods) != 0 {
+			continue
+		}
+
+		minAvailable := ss.Spec.MinAvailable
+		if minAvailable == 0 {
+			minAvailable = 1
+		}
+		if running >= minAvailable {
+			status.Running++
+		} else {
+			status.Pending++
+		}
+	}
+
+	return status, nil
+}
+
+// getPodsForSchedulingSpec returns the pods of SchedulingSpec, in the same way
+// as scheduler: pods with the same controller, or pods in the group labeled
+// by the name of SchedulingSpec.
+func (qc *Controller) getPodsForSchedulingSpec(ss *arbv1.SchedulingSpec) ([]*v1.Pod, error) {
+	keys, _ := qc.schedulingSpecJobKeys(ss)
+
+	var result []*v1.Pod
+	for _, key := range keys {
+		objs, err := qc.podInformer.Informer().GetIndexer().ByIndex(jobIndex, key)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			if pod, ok := obj.(*v1.Pod); ok {
+				result = append(result, pod)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func createQueueKind(config *rest.Config) error {
+	extensionscs, err := apiextensionsclient.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	_, err = client.CreateQueueKind(extensionscs)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+)
+
+const groupNameLabel = "group"
+
+// fakeServer records the Queues updated and the reasons of events created.
+type fakeServer struct {
+	sync.Mutex
+	updated []*arbv1.Queue
+	events  []string
+}
+
+func (fs *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.Lock()
+	defer fs.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, "/apis/"+arbv1.GroupName+"/v1alpha1/queues/"):
+		queue := &arbv1.Queue{}
+		json.NewDecoder(r.Body).Decode(queue)
+		fs.updated = append(fs.updated, queue)
+		json.NewEncoder(w).Encode(queue)
+	case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/events"):
+		event := &v1.Event{}
+		json.NewDecoder(r.Body).Decode(event)
+		fs.events = append(fs.events, event.Reason)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(event)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestController(t *testing.T, server *httptest.Server, specs []*arbv1.SchedulingSpec, pods []*v1.Pod) *Controller {
+	qc := NewQueueController(&rest.Config{Host: server.URL}, groupNameLabel)
+	for _, ss := range specs {
+		if err := qc.schedulingSpecInformer.Informer().GetIndexer().Add(ss); err != nil {
+			t.Fatalf("failed to add SchedulingSpec: %v", err)
+		}
+	}
+	for _, pod := range pods {
+		if err := qc.podInformer.Informer().GetIndexer().Add(pod); err != nil {
+			t.Fatalf("failed to add pod: %v", err)
+		}
+	}
+	return qc
+}
+
+func buildSchedulingSpec(name, queue string, owner types.UID) *arbv1.SchedulingSpec {
+	ss := &arbv1.SchedulingSpec{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1"},
+		Spec:       arbv1.SchedulingSpecTemplate{Queue: queue},
+	}
+	if len(owner) != 0 {
+		controller := true
+		ss.OwnerReferences = []metav1.OwnerReference{{UID: owner, Controller: &controller}}
+	}
+	return ss
+}
+
+func buildPod(name string, owner types.UID, group string, phase v1.PodPhase, cpu string) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1", UID: types.UID(name)},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+				},
+			}},
+		},
+		Status: v1.PodStatus{Phase: phase},
+	}
+	if len(owner) != 0 {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{UID: owner, Controller: &controller}}
+	}
+	if len(group) != 0 {
+		pod.Labels = map[string]string{groupNameLabel: group}
+	}
+	return pod
+}
+
+func TestSyncQueue(t *testing.T) {
+	now := metav1.Now()
+
+	tests := []struct {
+		name      string
+		queue     *arbv1.Queue
+		specs     []*arbv1.SchedulingSpec
+		pods      []*v1.Pod
+		updated   bool
+		finalizer bool
+		running   int32
+		pending   int32
+		events    []string
+	}{
+		{
+			name:      "add finalizer",
+			queue:     &arbv1.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q1"}},
+			updated:   true,
+			finalizer: true,
+		},
+		{
+			name: "keep finalizer of deleting Queue with workloads",
+			queue: &arbv1.Queue{ObjectMeta: metav1.ObjectMeta{
+				Name: "q1", DeletionTimestamp: &now, Finalizers: []string{arbv1.QueueFinalizer},
+			}},
+			specs: []*arbv1.SchedulingSpec{
+				buildSchedulingSpec("ss1", "q1", "job1"),
+				buildSchedulingSpec("ss2", "q1", ""),
+			},
+			pods: []*v1.Pod{
+				buildPod("p1", "job1", "", v1.PodRunning, "1"),
+				buildPod("p2", "", "ss2", v1.PodPending, "1"),
+				buildPod("p3", "job3", "", v1.PodRunning, "1"),
+			},
+			updated:   true,
+			finalizer: true,
+			running:   1,
+			pending:   1,
+		},
+		{
+			name: "remove finalizer of deleting Queue without workloads",
+			queue: &arbv1.Queue{ObjectMeta: metav1.ObjectMeta{
+				Name: "q1", DeletionTimestamp: &now, Finalizers: []string{arbv1.QueueFinalizer},
+			}},
+			specs:   []*arbv1.SchedulingSpec{buildSchedulingSpec("ss1", "q1", "job1")},
+			pods:    []*v1.Pod{buildPod("p1", "job1", "", v1.PodSucceeded, "1")},
+			updated: true,
+		},
+		{
+			name: "over-subscribed",
+			queue: &arbv1.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1", Finalizers: []string{arbv1.QueueFinalizer}},
+				Spec: arbv1.QueueSpec{
+					Capability: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+				},
+			},
+			specs:     []*arbv1.SchedulingSpec{buildSchedulingSpec("ss1", "q1", "job1")},
+			pods:      []*v1.Pod{buildPod("p1", "job1", "", v1.PodRunning, "2")},
+			updated:   true,
+			finalizer: true,
+			running:   1,
+			events:    []string{OverSubscribedReason},
+		},
+		{
+			name: "over-subscribed already",
+			queue: &arbv1.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1", Finalizers: []string{arbv1.QueueFinalizer}},
+				Spec: arbv1.QueueSpec{
+					Capability: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+				},
+				Status: arbv1.QueueStatus{
+					Running:   1,
+					Requested: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+				},
+			},
+			specs:     []*arbv1.SchedulingSpec{buildSchedulingSpec("ss1", "q1", "job1")},
+			pods:      []*v1.Pod{buildPod("p1", "job1", "", v1.PodRunning, "2")},
+			finalizer: true,
+			running:   1,
+		},
+	}
+
+	for _, test := range tests {
+		fs := &fakeServer{}
+		server := httptest.NewServer(fs)
+		qc := newTestController(t, server, test.specs, test.pods)
+
+		err := qc.syncQueue(test.queue)
+		server.Close()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+
+		if len(fs.events) != len(test.events) || (len(fs.events) != 0 && fs.events[0] != test.events[0]) {
+			t.Errorf("%s: expected events %v, got %v", test.name, test.events, fs.events)
+		}
+
+		if !test.updated {
+			if len(fs.updated) != 0 {
+				t.Errorf("%s: expected Queue not updated, got %+v", test.name, fs.updated[0])
+			}
+			continue
+		}
+		if len(fs.updated) != 1 {
+			t.Errorf("%s: expected Queue updated once, got %d", test.name, len(fs.updated))
+			continue
+		}
+
+		queue := fs.updated[0]
+		if hasFinalizer(queue) != test.finalizer {
+			t.Errorf("%s: expected finalizer %v, got %v", test.name, test.finalizer, queue.Finalizers)
+		}
+		if queue.Status.Running != test.running || queue.Status.Pending != test.pending {
+			t.Errorf("%s: expected running %d, pending %d, got %d, %d", test.name,
+				test.running, test.pending, queue.Status.Running, queue.Status.Pending)
+		}
+	}
+}
+
+func TestEnqueuePodQueue(t *testing.T) {
+	server := httptest.NewServer(&fakeServer{})
+	defer server.Close()
+
+	qc := newTestController(t, server, []*arbv1.SchedulingSpec{
+		buildSchedulingSpec("ss1", "q1", "job1"),
+		buildSchedulingSpec("ss2", "q2", ""),
+		buildSchedulingSpec("ss3", "q3", "job3"),
+	}, nil)
+	defer qc.queue.ShutDown()
+
+	qc.enqueuePodQueue(buildPod("p1", "job1", "", v1.PodRunning, "1"))
+	qc.enqueuePodQueue(buildPod("p2", "", "ss2", v1.PodPending, "1"))
+	qc.enqueuePodQueue(buildPod("p4", "job4", "", v1.PodRunning, "1"))
+
+	enqueued := map[string]bool{}
+	for qc.queue.Len() != 0 {
+		item, _ := qc.queue.Get()
+		enqueued[item.(string)] = true
+		qc.queue.Done(item)
+	}
+
+	if len(enqueued) != 2 || !enqueued["q1"] || !enqueued["q2"] {
+		t.Errorf("expected Queues q1 and q2 enqueued, got %v", enqueued)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/api/core/v1"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+)
+
+// podRequests returns the sum of the resource requests of pod's containers.
+func podRequests(pod *v1.Pod) v1.ResourceList {
+	result := v1.ResourceList{}
+	for _, c := range pod.Spec.Containers {
+		addResourceList(result, c.Resources.Requests)
+	}
+	return result
+}
+
+// addResourceList adds the resources in new to list.
+func addResourceList(list, new v1.ResourceList) {
+	for name, quantity := range new {
+		if value, ok := list[name]; !ok {
+			list[name] = *quantity.Copy()
+		} else {
+			value.Add(quantity)
+			list[name] = value
+		}
+	}
+}
+
+// overSubscribed returns true if any resource in requested exceeds capability;
+// the resources not in capability are unlimited.
+func overSubscribed(requested, capability v1.ResourceList) bool {
+	for name, limit := range capability {
+		if value, found := requested[name]; found && value.Cmp(limit) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func statusEqual(l, r *arbv1.QueueStatus) bool {
+	if l.Pending != r.Pending || l.Running != r.Running {
+		return false
+	}
+
+	if len(l.Requested) != len(r.Requested) {
+		return false
+	}
+	for name, lv := range l.Requested {
+		if rv, found := r.Requested[name]; !found || lv.Cmp(rv) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func resourceListString(list v1.ResourceList) string {
+	var items []string
+	for name, quantity := range list {
+		items = append(items, fmt.Sprintf("%s: %s", name, quantity.String()))
+	}
+	sort.Strings(items)
+	return "<" + strings.Join(items, ", ") + ">"
+}
+
+func hasFinalizer(queue *arbv1.Queue) bool {
+	for _, f := range queue.Finalizers {
+		if f == arbv1.QueueFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+func removeFinalizer(queue *arbv1.Queue) {
+	var finalizers []string
+	for _, f := range queue.Finalizers {
+		if f != arbv1.QueueFinalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	queue.Finalizers = finalizers
+}
+
+func queueReference(queue *arbv1.Queue) *v1.ObjectReference {
+	return &v1.ObjectReference{
+		Kind:            "Queue",
+		APIVersion:      arbv1.SchemeGroupVersion.String(),
+		Name:            queue.Name,
+		UID:             queue.UID,
+		ResourceVersion: queue.ResourceVersion,
+	}
+}
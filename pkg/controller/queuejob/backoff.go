@@ -0,0 +1,157 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queuejob
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+)
+
+const (
+	// initialBackoff is the delay before the first retry of a task.
+	initialBackoff = 10 * time.Second
+
+	// defaultBackoffLimit is the upper limit of the delay if QueueJob's
+	// BackoffLimit is not set.
+	defaultBackoffLimit = 360 * time.Second
+)
+
+// retryBackoff records when the failed pods of each task can be re-created;
+// it's in memory, so the delay is reset if the controller restarted.
+type retryBackoff struct {
+	sync.Mutex
+	retryAt map[string]time.Time
+}
+
+func newRetryBackoff() *retryBackoff {
+	return &retryBackoff{
+		retryAt: map[string]time.Time{},
+	}
+}
+
+func backoffKey(qj *arbv1.QueueJob, taskName string) string {
+	return string(qj.UID) + "/" + taskName
+}
+
+// backoffLimit returns the upper limit of the delay of QueueJob's retries.
+func backoffLimit(qj *arbv1.QueueJob) time.Duration {
+	if qj.Spec.BackoffLimit == nil {
+		return defaultBackoffLimit
+	}
+	return time.Duration(*qj.Spec.BackoffLimit) * time.Second
+}
+
+// backoffDelay returns the delay of the n-th retry: it's doubled by each retry
+// and capped by limit.
+func backoffDelay(retries int32, limit time.Duration) time.Duration {
+	delay := initialBackoff
+	for i := int32(1); i < retries && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+	return delay
+}
+
+// next records that the task identified by key can not be retried in delay.
+func (b *retryBackoff) next(key string, delay time.Duration) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.retryAt[key] = time.Now().Add(delay)
+}
+
+// remaining returns the delay before the task identified by key can be retried;
+// zero means it can be retried now.
+func (b *retryBackoff) remaining(key string) time.Duration {
+	b.Lock()
+	defer b.Unlock()
+
+	at, found := b.retryAt[key]
+	if !found {
+		return 0
+	}
+
+	if d := at.Sub(time.Now()); d > 0 {
+		return d
+	}
+
+	delete(b.retryAt, key)
+	return 0
+}
+
+// deleteJob removes the records of all tasks of the QueueJob.
+func (b *retryBackoff) deleteJob(uid types.UID) {
+	b.Lock()
+	defer b.Unlock()
+
+	prefix := string(uid) + "/"
+	for key := range b.retryAt {
+		if strings.HasPrefix(key, prefix) {
+			delete(b.retryAt, key)
+		}
+	}
+}
+
+// retriedPods records the failed pods counted in the retries of QueueJobs, so
+// a failure is counted once although several syncs see the pod failed before
+// its deletion is observed.
+type retriedPods struct {
+	sync.Mutex
+	uids map[types.UID]bool
+}
+
+func newRetriedPods() *retriedPods {
+	return &retriedPods{
+		uids: map[types.UID]bool{},
+	}
+}
+
+// add records the failed ones of pods as counted.
+func (r *retriedPods) add(pods []*v1.Pod) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, pod := range pods {
+		if pod.Status.Phase == v1.PodFailed {
+			r.uids[pod.UID] = true
+		}
+	}
+}
+
+// counted returns true if the pod was counted in the retries.
+func (r *retriedPods) counted(pod *v1.Pod) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.uids[pod.UID]
+}
+
+// remove forgets the pod, e.g. its deletion was observed.
+func (r *retriedPods) remove(pod *v1.Pod) {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.uids, pod.UID)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queuejob
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		retries  int32
+		limit    time.Duration
+		expected time.Duration
+	}{
+		{retries: 1, limit: defaultBackoffLimit, expected: 10 * time.Second},
+		{retries: 2, limit: defaultBackoffLimit, expected: 20 * time.Second},
+		{retries: 4, limit: defaultBackoffLimit, expected: 80 * time.Second},
+		{retries: 6, limit: defaultBackoffLimit, expected: 320 * time.Second},
+		{retries: 7, limit: defaultBackoffLimit, expected: defaultBackoffLimit},
+		{retries: 100, limit: defaultBackoffLimit, expected: defaultBackoffLimit},
+		{retries: 3, limit: 30 * time.Second, expected: 30 * time.Second},
+		{retries: 1, limit: 5 * time.Second, expected: 5 * time.Second},
+		{retries: 1, limit: 0, expected: 0},
+	}
+
+	for _, test := range tests {
+		if delay := backoffDelay(test.retries, test.limit); delay != test.expected {
+			t.Errorf("retries %d, limit %v: expected delay %v, got %v",
+				test.retries, test.limit, test.expected, delay)
+		}
+	}
+}
+
+func TestBackoffLimit(t *testing.T) {
+	qj := &arbv1.QueueJob{}
+	if limit := backoffLimit(qj); limit != defaultBackoffLimit {
+		t.Errorf("expected default limit %v, got %v", defaultBackoffLimit, limit)
+	}
+
+	limit := int32(60)
+	qj.Spec.BackoffLimit = &limit
+	if limit := backoffLimit(qj); limit != time.Minute {
+		t.Errorf("expected limit 1m, got %v", limit)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	b := newRetryBackoff()
+	qj := &arbv1.QueueJob{ObjectMeta: metav1.ObjectMeta{UID: "qj-uid"}}
+
+	b.next(backoffKey(qj, "ps"), time.Hour)
+	b.next(backoffKey(qj, "worker"), -time.Second)
+
+	if d := b.remaining(backoffKey(qj, "ps")); d <= 0 || d > time.Hour {
+		t.Errorf("expected remaining delay of ps in 1h, got %v", d)
+	}
+	if d := b.remaining(backoffKey(qj, "worker")); d != 0 {
+		t.Errorf("expected expired delay of worker, got %v", d)
+	}
+
+	b.deleteJob(qj.UID)
+	if d := b.remaining(backoffKey(qj, "ps")); d != 0 {
+		t.Errorf("expected no delay after QueueJob deleted, got %v", d)
+	}
+}
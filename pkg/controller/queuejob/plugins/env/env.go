@@ -0,0 +1,137 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/queuejob/plugins"
+)
+
+const (
+	// PluginName is the name of env plugin.
+	PluginName = "env"
+
+	// TaskNameEnv is the env of the pod's task name.
+	TaskNameEnv = "QJ_TASK_NAME"
+	// TaskIndexEnv is the env of the pod's index in its task.
+	TaskIndexEnv = "QJ_TASK_INDEX"
+	// RankEnv is the env of the pod's index in QueueJob, by the order of tasks.
+	RankEnv = "QJ_RANK"
+	// WorldSizeEnv is the env of the total replicas of QueueJob.
+	WorldSizeEnv = "QJ_WORLD_SIZE"
+	// hostsEnvSuffix is the suffix of the env of a task's hostnames, e.g.
+	// QJ_WORKER_HOSTS; it's QJ_HOSTS for the anonymous task.
+	hostsEnvSuffix = "HOSTS"
+)
+
+func init() {
+	plugins.RegisterPluginBuilder(PluginName, New)
+}
+
+// envPlugin injects the peer-discovery env into each container of the pods:
+// the task name, index and rank of the pod, and the hostnames of each task.
+// The hostnames are resolvable if svc plugin is also enabled.
+type envPlugin struct{}
+
+// New creates env plugin.
+func New(clients kubernetes.Interface) plugins.Plugin {
+	return &envPlugin{}
+}
+
+func (ep *envPlugin) Name() string {
+	return PluginName
+}
+
+func (ep *envPlugin) OnJobAdd(qj *arbv1.QueueJob) error {
+	return nil
+}
+
+func (ep *envPlugin) OnPodCreate(pod *v1.Pod, qj *arbv1.QueueJob) error {
+	taskName := pod.Labels[arbv1.TaskSpecLabel]
+	ix, err := utils.GetTaskIndex(pod)
+	if err != nil {
+		return err
+	}
+
+	envs := []v1.EnvVar{
+		{Name: TaskNameEnv, Value: taskName},
+		{Name: TaskIndexEnv, Value: strconv.Itoa(int(ix))},
+	}
+
+	rank, worldSize := int32(-1), int32(0)
+	for _, ts := range utils.GetTaskSpecs(qj) {
+		if ts.Name == taskName {
+			rank = worldSize + ix
+		}
+		worldSize += ts.Replicas
+
+		var hosts []string
+		for i := int32(0); i < ts.Replicas; i++ {
+			hosts = append(hosts, fmt.Sprintf("%s.%s", utils.TaskPodName(qj.Name, ts.Name, i), qj.Name))
+		}
+		envs = append(envs, v1.EnvVar{
+			Name:  hostsEnvName(ts.Name),
+			Value: strings.Join(hosts, ","),
+		})
+	}
+
+	if rank < 0 {
+		return fmt.Errorf("task <%s> of pod %s/%s not found in QueueJob", taskName, pod.Namespace, pod.Name)
+	}
+
+	envs = append(envs,
+		v1.EnvVar{Name: RankEnv, Value: strconv.Itoa(int(rank))},
+		v1.EnvVar{Name: WorldSizeEnv, Value: strconv.Itoa(int(worldSize))},
+	)
+
+	for i := range pod.Spec.InitContainers {
+		pod.Spec.InitContainers[i].Env = append(pod.Spec.InitContainers[i].Env, envs...)
+	}
+	for i := range pod.Spec.Containers {
+		pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, envs...)
+	}
+
+	return nil
+}
+
+// hostsEnvName returns the env name of task's hostnames, e.g. QJ_WORKER_HOSTS.
+func hostsEnvName(taskName string) string {
+	if len(taskName) == 0 {
+		return "QJ_" + hostsEnvSuffix
+	}
+
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, taskName)
+
+	return "QJ_" + name + "_" + hostsEnvSuffix
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package env
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+)
+
+func buildTaskPod(task, ix string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "qj-" + task + "-" + ix,
+			Namespace:   "c1",
+			Labels:      map[string]string{arbv1.TaskSpecLabel: task},
+			Annotations: map[string]string{arbv1.TaskIndexAnnotation: ix},
+		},
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{{Name: "init"}},
+			Containers:     []v1.Container{{Name: "main"}},
+		},
+	}
+}
+
+func TestOnPodCreate(t *testing.T) {
+	qj := &arbv1.QueueJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "qj", Namespace: "c1"},
+		Spec: arbv1.QueueJobSpec{
+			TaskSpecs: []arbv1.TaskSpec{
+				{Name: "ps", Replicas: 1},
+				{Name: "worker-gpu", Replicas: 2},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		pod      *v1.Pod
+		expected map[string]string
+	}{
+		{
+			name: "first task",
+			pod:  buildTaskPod("ps", "0"),
+			expected: map[string]string{
+				TaskNameEnv:           "ps",
+				TaskIndexEnv:          "0",
+				RankEnv:               "0",
+				WorldSizeEnv:          "3",
+				"QJ_PS_HOSTS":         "qj-ps-0.qj",
+				"QJ_WORKER_GPU_HOSTS": "qj-worker-gpu-0.qj,qj-worker-gpu-1.qj",
+			},
+		},
+		{
+			name: "second task",
+			pod:  buildTaskPod("worker-gpu", "1"),
+			expected: map[string]string{
+				TaskNameEnv:           "worker-gpu",
+				TaskIndexEnv:          "1",
+				RankEnv:               "2",
+				WorldSizeEnv:          "3",
+				"QJ_PS_HOSTS":         "qj-ps-0.qj",
+				"QJ_WORKER_GPU_HOSTS": "qj-worker-gpu-0.qj,qj-worker-gpu-1.qj",
+			},
+		},
+	}
+
+	ep := New(nil)
+	for _, test := range tests {
+		if err := ep.OnPodCreate(test.pod, qj); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+
+		for _, c := range append(test.pod.Spec.InitContainers, test.pod.Spec.Containers...) {
+			envs := map[string]string{}
+			for _, env := range c.Env {
+				envs[env.Name] = env.Value
+			}
+			if len(envs) != len(test.expected) {
+				t.Errorf("%s: expected env %v in container %s, got %v", test.name, test.expected, c.Name, envs)
+				continue
+			}
+			for name, value := range test.expected {
+				if envs[name] != value {
+					t.Errorf("%s: expected env %s=%q in container %s, got %q",
+						test.name, name, value, c.Name, envs[name])
+				}
+			}
+		}
+	}
+
+	if err := ep.OnPodCreate(buildTaskPod("launcher", "0"), qj); err == nil {
+		t.Errorf("expected error for the pod of unknown task")
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"sync"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+)
+
+// Plugin is the interface of QueueJob controller plugins, which create the
+// objects and update the pods for the common requirements of jobs, e.g. the
+// headless Service for peer discovery.
+type Plugin interface {
+	// The unique name of Plugin.
+	Name() string
+
+	// OnJobAdd is called when QueueJob is synced, before its pods are created;
+	// it should be idempotent.
+	OnJobAdd(qj *arbv1.QueueJob) error
+
+	// OnPodCreate is called before the pod of QueueJob is created.
+	OnPodCreate(pod *v1.Pod, qj *arbv1.QueueJob) error
+}
+
+// PluginBuilder builds the Plugin with Kubernetes clients.
+type PluginBuilder func(clients kubernetes.Interface) Plugin
+
+// Plugin management
+var pluginBuilders = map[string]PluginBuilder{}
+var pluginMutex sync.Mutex
+
+// RegisterPluginBuilder registers the builder of plugin by name.
+func RegisterPluginBuilder(name string, pb PluginBuilder) {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	pluginBuilders[name] = pb
+}
+
+// GetPluginBuilder returns the builder of plugin by name.
+func GetPluginBuilder(name string) (PluginBuilder, bool) {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	pb, found := pluginBuilders[name]
+	return pb, found
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/queuejob/plugins"
+)
+
+const (
+	// PluginName is the name of ssh plugin.
+	PluginName = "ssh"
+
+	// SSHPath is the path where the ssh keys are mounted in containers.
+	SSHPath = "/root/.ssh"
+
+	sshVolumeName = "queuejob-ssh"
+	sshKeyBits    = 2048
+
+	privateKeyFile     = "id_rsa"
+	publicKeyFile      = "id_rsa.pub"
+	authorizedKeysFile = "authorized_keys"
+	configFile         = "config"
+)
+
+var queueJobKind = arbv1.SchemeGroupVersion.WithKind("QueueJob")
+
+func init() {
+	plugins.RegisterPluginBuilder(PluginName, New)
+}
+
+// sshPlugin generates a ssh key pair for QueueJob, and mounts it into all
+// containers, so the pods of QueueJob can ssh to each other without password,
+// e.g. mpirun.
+type sshPlugin struct {
+	clients kubernetes.Interface
+}
+
+// New creates ssh plugin.
+func New(clients kubernetes.Interface) plugins.Plugin {
+	return &sshPlugin{clients: clients}
+}
+
+func (sp *sshPlugin) Name() string {
+	return PluginName
+}
+
+func (sp *sshPlugin) OnJobAdd(qj *arbv1.QueueJob) error {
+	// The key pair is generated only once, so it's the same for all pods.
+	_, err := sp.clients.CoreV1().Secrets(qj.Namespace).Get(secretName(qj), metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	data, err := generateKeys()
+	if err != nil {
+		return err
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName(qj),
+			Namespace: qj.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(qj, queueJobKind),
+			},
+		},
+		Data: data,
+	}
+
+	_, err = sp.clients.CoreV1().Secrets(qj.Namespace).Create(secret)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (sp *sshPlugin) OnPodCreate(pod *v1.Pod, qj *arbv1.QueueJob) error {
+	mode := int32(0600)
+	pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+		Name: sshVolumeName,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName:  secretName(qj),
+				DefaultMode: &mode,
+			},
+		},
+	})
+
+	mount := v1.VolumeMount{
+		Name:      sshVolumeName,
+		MountPath: SSHPath,
+		ReadOnly:  true,
+	}
+	for i := range pod.Spec.InitContainers {
+		pod.Spec.InitContainers[i].VolumeMounts = append(pod.Spec.InitContainers[i].VolumeMounts, mount)
+	}
+	for i := range pod.Spec.Containers {
+		pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, mount)
+	}
+
+	return nil
+}
+
+func secretName(qj *arbv1.QueueJob) string {
+	return qj.Name + "-" + PluginName
+}
+
+// generateKeys generates a RSA key pair, and returns the files of ssh directory.
+func generateKeys() (map[string][]byte, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, sshKeyBits)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+
+	publicKey := authorizedKey(&privateKey.PublicKey)
+
+	return map[string][]byte{
+		privateKeyFile:     privateKeyPEM,
+		publicKeyFile:      publicKey,
+		authorizedKeysFile: publicKey,
+		configFile:         []byte("StrictHostKeyChecking no\nUserKnownHostsFile /dev/null\n"),
+	}, nil
+}
+
+// authorizedKey encodes the public key in the format of authorized_keys,
+// see RFC 4253 section 6.6.
+func authorizedKey(key *rsa.PublicKey) []byte {
+	wire := &bytes.Buffer{}
+	writeString(wire, []byte("ssh-rsa"))
+	writeMPInt(wire, big.NewInt(int64(key.E)))
+	writeMPInt(wire, key.N)
+
+	return []byte("ssh-rsa " + base64.StdEncoding.EncodeToString(wire.Bytes()) + "\n")
+}
+
+func writeString(buf *bytes.Buffer, s []byte) {
+	length := make([]byte, 4)
+	binary.BigEndian.PutUint32(length, uint32(len(s)))
+	buf.Write(length)
+	buf.Write(s)
+}
+
+// writeMPInt writes the non-negative integer as mpint, see RFC 4251 section 5.
+func writeMPInt(buf *bytes.Buffer, n *big.Int) {
+	b := n.Bytes()
+	// Leading zero for the positive number whose highest bit is set.
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	writeString(buf, b)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// readString reads a string of the ssh wire format, see RFC 4251 section 5.
+func readString(b []byte) ([]byte, []byte, bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+	length := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < length {
+		return nil, nil, false
+	}
+	return b[4 : 4+length], b[4+length:], true
+}
+
+func TestGenerateKeys(t *testing.T) {
+	data, err := generateKeys()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	block, _ := pem.Decode(data[privateKeyFile])
+	if block == nil || block.Type != "RSA PRIVATE KEY" {
+		t.Fatalf("expected RSA private key in %s, got %q", privateKeyFile, data[privateKeyFile])
+	}
+	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse private key: %v", err)
+	}
+
+	if !bytes.Equal(data[publicKeyFile], data[authorizedKeysFile]) {
+		t.Errorf("expected %s same as %s", authorizedKeysFile, publicKeyFile)
+	}
+
+	fields := strings.Fields(string(data[publicKeyFile]))
+	if len(fields) != 2 || fields[0] != "ssh-rsa" {
+		t.Fatalf("expected \"ssh-rsa <key>\" in %s, got %q", publicKeyFile, data[publicKeyFile])
+	}
+	wire, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		t.Fatalf("failed to decode public key: %v", err)
+	}
+
+	keyType, rest, ok := readString(wire)
+	if !ok || string(keyType) != "ssh-rsa" {
+		t.Fatalf("expected key type ssh-rsa, got %q", keyType)
+	}
+	e, rest, ok := readString(rest)
+	if !ok || new(big.Int).SetBytes(e).Int64() != int64(privateKey.E) {
+		t.Errorf("expected exponent %d, got %v", privateKey.E, e)
+	}
+	n, rest, ok := readString(rest)
+	if !ok || len(rest) != 0 {
+		t.Fatalf("expected modulus at the end of public key")
+	}
+	// The modulus has its highest bit set, so it's led by zero as mpint.
+	if n[0] != 0 || new(big.Int).SetBytes(n).Cmp(privateKey.N) != 0 {
+		t.Errorf("expected modulus of private key in public key")
+	}
+
+	if !strings.Contains(string(data[configFile]), "StrictHostKeyChecking no") {
+		t.Errorf("expected StrictHostKeyChecking disabled in %s, got %q", configFile, data[configFile])
+	}
+}
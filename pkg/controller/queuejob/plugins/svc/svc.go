@@ -0,0 +1,106 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package svc
+
+import (
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/queuejob/plugins"
+)
+
+// PluginName is the name of svc plugin.
+const PluginName = "svc"
+
+var queueJobKind = arbv1.SchemeGroupVersion.WithKind("QueueJob")
+
+func init() {
+	plugins.RegisterPluginBuilder(PluginName, New)
+}
+
+// svcPlugin creates a headless Service named as QueueJob for its pods, so
+// each pod is resolvable by "<pod>.<queuejob>" in the namespace.
+type svcPlugin struct {
+	clients kubernetes.Interface
+}
+
+// New creates svc plugin.
+func New(clients kubernetes.Interface) plugins.Plugin {
+	return &svcPlugin{clients: clients}
+}
+
+func (sp *svcPlugin) Name() string {
+	return PluginName
+}
+
+func (sp *svcPlugin) OnJobAdd(qj *arbv1.QueueJob) error {
+	// The Service is created only once, instead of by every sync.
+	_, err := sp.clients.CoreV1().Services(qj.Namespace).Get(qj.Name, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      qj.Name,
+			Namespace: qj.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(qj, queueJobKind),
+			},
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: v1.ClusterIPNone,
+			Selector: map[string]string{
+				arbv1.QueueJobLabel: qj.Name,
+			},
+			// Peers are resolvable before ready, e.g. MPI job.
+			PublishNotReadyAddresses: true,
+		},
+	}
+
+	_, err = sp.clients.CoreV1().Services(qj.Namespace).Create(svc)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (sp *svcPlugin) OnPodCreate(pod *v1.Pod, qj *arbv1.QueueJob) error {
+	// The hostname must be a DNS label, e.g. at most 63 characters; otherwise
+	// the pod is created without it, instead of being rejected.
+	if errs := validation.IsDNS1123Label(pod.Name); len(errs) != 0 {
+		glog.V(3).Infof("Pod %v/%v is not resolvable by its name: %s",
+			pod.Namespace, pod.Name, strings.Join(errs, "; "))
+		return nil
+	}
+
+	pod.Spec.Hostname = pod.Name
+	pod.Spec.Subdomain = qj.Name
+
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package svc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+)
+
+func TestOnJobAdd(t *testing.T) {
+	var created *v1.Service
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v1/namespaces/c1/services/qj" && created == nil:
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(&metav1.Status{
+				TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+				Status:   metav1.StatusFailure,
+				Reason:   metav1.StatusReasonNotFound,
+				Code:     http.StatusNotFound,
+			})
+		case r.Method == "GET" && r.URL.Path == "/api/v1/namespaces/c1/services/qj":
+			json.NewEncoder(w).Encode(created)
+		case r.Method == "POST" && r.URL.Path == "/api/v1/namespaces/c1/services":
+			if created != nil {
+				t.Errorf("expected Service created once")
+			}
+			created = &v1.Service{}
+			json.NewDecoder(r.Body).Decode(created)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(created)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	sp := New(kubernetes.NewForConfigOrDie(&rest.Config{Host: server.URL}))
+	qj := &arbv1.QueueJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "qj", Namespace: "c1", UID: "qj-uid"},
+	}
+
+	// The second sync finds the Service created by the first one.
+	for i := 0; i < 2; i++ {
+		if err := sp.OnJobAdd(qj); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if created == nil {
+		t.Fatalf("expected Service created")
+	}
+	if created.Name != "qj" || created.Spec.ClusterIP != v1.ClusterIPNone ||
+		created.Spec.Selector[arbv1.QueueJobLabel] != "qj" || !created.Spec.PublishNotReadyAddresses {
+		t.Errorf("expected headless Service qj selecting the pods of QueueJob, got %+v", created)
+	}
+	if len(created.OwnerReferences) != 1 || created.OwnerReferences[0].UID != qj.UID {
+		t.Errorf("expected Service owned by QueueJob, got %v", created.OwnerReferences)
+	}
+}
+
+func TestOnPodCreate(t *testing.T) {
+	qj := &arbv1.QueueJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "qj", Namespace: "c1"},
+	}
+
+	tests := []struct {
+		name     string
+		podName  string
+		hostname string
+	}{
+		{
+			name:     "hostname by pod name",
+			podName:  "qj-worker-0",
+			hostname: "qj-worker-0",
+		},
+		{
+			name:    "pod name too long for hostname",
+			podName: "qj-" + strings.Repeat("w", 60) + "-0",
+		},
+	}
+
+	sp := New(nil)
+	for _, test := range tests {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: test.podName, Namespace: "c1"}}
+		if err := sp.OnPodCreate(pod, qj); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if pod.Spec.Hostname != test.hostname {
+			t.Errorf("%s: expected hostname %q, got %q", test.name, test.hostname, pod.Spec.Hostname)
+		}
+		if len(test.hostname) != 0 && pod.Spec.Subdomain != qj.Name {
+			t.Errorf("%s: expected subdomain %q, got %q", test.name, qj.Name, pod.Spec.Subdomain)
+		}
+	}
+}
@@ -18,6 +18,8 @@ package queuejob
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,7 +27,6 @@ import (
 
 	"k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -43,11 +44,19 @@ import (
 	arbinformers "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers"
 	informersv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers/v1"
 	listersv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/listers/v1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/queuejob/plugins"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/workqueue"
 )
 
 const (
 	// QueueJobLabel label string for queuejob name
-	QueueJobLabel string = "queuejob.kube-arbitrator.k8s.io"
+	QueueJobLabel = arbv1.QueueJobLabel
+
+	// TaskSpecLabel label string for the task (role) name of QueueJob's pod
+	TaskSpecLabel = arbv1.TaskSpecLabel
+
+	// TaskIndexAnnotation annotation string for the index of QueueJob's pod in its task
+	TaskIndexAnnotation = arbv1.TaskIndexAnnotation
 )
 
 // Controller the QueueJob Controller type
@@ -66,8 +75,14 @@ type Controller struct {
 	podStore  corelisters.PodLister
 	podSynced func() bool
 
-	// eventQueue that need to sync up
-	eventQueue *cache.FIFO
+	// queue of QueueJob keys that need to sync up
+	queue workqueue.RateLimitingInterface
+
+	// backoff of re-creating failed pods
+	backoff *retryBackoff
+
+	// failed pods counted in the retries
+	retried *retriedPods
 }
 
 // NewQueueJobController create new QueueJob Controller
@@ -76,7 +91,9 @@ func NewQueueJobController(config *rest.Config) *Controller {
 		config:     config,
 		clients:    kubernetes.NewForConfigOrDie(config),
 		arbclients: clientset.NewForConfigOrDie(config),
-		eventQueue: cache.NewFIFO(eventKey),
+		queue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "queuejob"),
+		backoff:    newRetryBackoff(),
+		retried:    newRetriedPods(),
 	}
 
 	queueJobClient, _, err := client.NewClient(cc.config)
@@ -129,7 +146,7 @@ func NewQueueJobController(config *rest.Config) *Controller {
 }
 
 // Run start QueueJob Controller
-func (cc *Controller) Run(stopCh chan struct{}) {
+func (cc *Controller) Run(stopCh <-chan struct{}) {
 	// initialized
 	createQueueJobKind(cc.config)
 
@@ -139,6 +156,11 @@ func (cc *Controller) Run(stopCh chan struct{}) {
 	cache.WaitForCacheSync(stopCh, cc.queueJobSynced, cc.podSynced)
 
 	go wait.Until(cc.worker, time.Second, stopCh)
+
+	go func() {
+		<-stopCh
+		cc.queue.ShutDown()
+	}()
 }
 
 func (cc *Controller) addQueueJob(obj interface{}) {
@@ -159,6 +181,12 @@ func (cc *Controller) updateQueueJob(oldObj, newObj interface{}) {
 	}
 
 	cc.enqueue(newQJ)
+
+	// The dependents are pending until it's finished, either completed or not.
+	oldQJ, ok := oldObj.(*arbv1.QueueJob)
+	if ok && oldQJ.Status.Phase != newQJ.Status.Phase && isFinished(newQJ.Status.Phase) {
+		cc.enqueueDependents(newQJ)
+	}
 }
 
 func (cc *Controller) deleteQueueJob(obj interface{}) {
@@ -168,27 +196,30 @@ func (cc *Controller) deleteQueueJob(obj interface{}) {
 		return
 	}
 
+	cc.backoff.deleteJob(qj.UID)
+
 	cc.enqueue(qj)
+	cc.enqueueDependents(qj)
 }
 
 func (cc *Controller) addPod(obj interface{}) {
 	pod, ok := obj.(*v1.Pod)
 	if !ok {
-		glog.Error("Failed to convert %v to v1.Pod", obj)
+		glog.Errorf("Failed to convert %v to v1.Pod", obj)
 		return
 	}
 
-	cc.enqueue(pod)
+	cc.enqueuePodOwner(pod)
 }
 
 func (cc *Controller) updatePod(oldObj, newObj interface{}) {
 	pod, ok := newObj.(*v1.Pod)
 	if !ok {
-		glog.Error("Failed to convert %v to v1.Pod", newObj)
+		glog.Errorf("Failed to convert %v to v1.Pod", newObj)
 		return
 	}
 
-	cc.enqueue(pod)
+	cc.enqueuePodOwner(pod)
 }
 
 func (cc *Controller) deletePod(obj interface{}) {
@@ -208,72 +239,82 @@ func (cc *Controller) deletePod(obj interface{}) {
 		return
 	}
 
-	queuejobs, err := cc.queueJobLister.List(labels.Everything())
+	cc.retried.remove(pod)
+	cc.enqueuePodOwner(pod)
+}
+
+// enqueueDependents enqueues the QueueJobs which depend on qj.
+func (cc *Controller) enqueueDependents(qj *arbv1.QueueJob) {
+	qjs, err := cc.queueJobLister.QueueJobs(qj.Namespace).List(labels.Everything())
 	if err != nil {
-		glog.Errorf("Failed to list QueueJobs for Pod %v/%v", pod.Namespace, pod.Name)
+		glog.Errorf("Failed to list QueueJobs in namespace %s: %v", qj.Namespace, err)
+		return
 	}
 
-	ctl := utils.GetController(pod)
-	for _, qj := range queuejobs {
-		if qj.UID == ctl {
-			cc.enqueue(qj)
-			break
+	for _, dependent := range qjs {
+		for _, name := range dependent.Spec.DependsOn {
+			if name == qj.Name {
+				cc.enqueue(dependent)
+				break
+			}
 		}
 	}
 }
 
-func (cc *Controller) enqueue(obj interface{}) {
-	err := cc.eventQueue.Add(obj)
-	if err != nil {
-		glog.Errorf("Fail to enqueue QueueJob to updateQueue, err %#v", err)
+// enqueuePodOwner enqueues the QueueJob which controls the pod.
+func (cc *Controller) enqueuePodOwner(pod *v1.Pod) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil || ref.Kind != queueJobKind.Kind {
+		return
 	}
-}
 
-func (cc *Controller) worker() {
-	if _, err := cc.eventQueue.Pop(func(obj interface{}) error {
-		var queuejob *arbv1.QueueJob
-		switch v := obj.(type) {
-		case *arbv1.QueueJob:
-			queuejob = v
-		case *v1.Pod:
-			queuejobs, err := cc.queueJobLister.List(labels.Everything())
-			if err != nil {
-				glog.Errorf("Failed to list QueueJobs for Pod %v/%v", v.Namespace, v.Name)
-			}
+	cc.queue.Add(pod.Namespace + "/" + ref.Name)
+}
 
-			ctl := utils.GetController(v)
-			for _, qj := range queuejobs {
-				if qj.UID == ctl {
-					queuejob = qj
-					break
-				}
-			}
+func (cc *Controller) enqueue(qj *arbv1.QueueJob) {
+	key, err := cache.MetaNamespaceKeyFunc(qj)
+	if err != nil {
+		glog.Errorf("Failed to get key of QueueJob %v/%v: %v", qj.Namespace, qj.Name, err)
+		return
+	}
 
-		default:
-			glog.Errorf("Un-supported type of %v", obj)
-			return nil
-		}
+	cc.queue.Add(key)
+}
 
-		if queuejob == nil {
-			if acc, err := meta.Accessor(obj); err != nil {
-				glog.Warningf("Failed to get QueueJob for %v/%v", acc.GetNamespace(), acc.GetName())
-			}
+// enqueueAfter adds QueueJob into queue after delay, e.g. the backoff expired.
+func (cc *Controller) enqueueAfter(qj *arbv1.QueueJob, delay time.Duration) {
+	key, err := cache.MetaNamespaceKeyFunc(qj)
+	if err != nil {
+		glog.Errorf("Failed to get key of QueueJob %v/%v: %v", qj.Namespace, qj.Name, err)
+		return
+	}
 
-			return nil
-		}
+	cc.queue.AddAfter(key, delay)
+}
 
-		// sync Pods for a QueueJob
-		if err := cc.syncQueueJob(queuejob); err != nil {
-			glog.Errorf("Failed to sync QueueJob %s, err %#v", queuejob.Name, err)
-			// If any error, requeue it.
-			return err
-		}
+func (cc *Controller) worker() {
+	for cc.processNextItem() {
+	}
+}
 
-		return nil
-	}); err != nil {
-		glog.Errorf("Fail to pop item from updateQueue, err %#v", err)
-		return
+func (cc *Controller) processNextItem() bool {
+	key, shutdown := cc.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer cc.queue.Done(key)
+
+	// sync Pods for a QueueJob
+	if err := cc.syncQueueJob(key.(string)); err != nil {
+		glog.Errorf("Failed to sync QueueJob %s (retried %d times), err %#v",
+			key, cc.queue.NumRequeues(key), err)
+		// If any error, requeue it with rate limit.
+		cc.queue.AddRateLimited(key)
+		return true
 	}
+
+	cc.queue.Forget(key)
+	return true
 }
 
 // filterActivePods returns pods that have not terminated.
@@ -296,11 +337,16 @@ func isPodActive(p *v1.Pod) bool {
 		p.DeletionTimestamp == nil
 }
 
-func (cc *Controller) syncQueueJob(qj *arbv1.QueueJob) error {
-	queueJob, err := cc.queueJobLister.QueueJobs(qj.Namespace).Get(qj.Name)
+func (cc *Controller) syncQueueJob(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+
+	queueJob, err := cc.queueJobLister.QueueJobs(namespace).Get(name)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			glog.V(3).Infof("Job has been deleted: %v", qj.Name)
+			glog.V(3).Infof("Job has been deleted: %v", key)
 			return nil
 		}
 		return err
@@ -311,93 +357,428 @@ func (cc *Controller) syncQueueJob(qj *arbv1.QueueJob) error {
 		return err
 	}
 
-	return cc.manageQueueJob(queueJob, pods)
+	return cc.manageQueueJob(queueJob.DeepCopy(), pods)
 }
 
 func (cc *Controller) getPodsForQueueJob(qj *arbv1.QueueJob) ([]*v1.Pod, error) {
-	selector, err := metav1.LabelSelectorAsSelector(qj.Spec.Selector)
+	// List all pods in QueueJob's namespace, and filter them by controller
+	// because the pod templates of tasks may have different labels.
+	pods, err := cc.podStore.Pods(qj.Namespace).List(labels.Everything())
 	if err != nil {
-		return nil, fmt.Errorf("couldn't convert QueueJob selector: %v", err)
+		return nil, err
 	}
 
-	// List all pods under QueueJob
-	pods, err := cc.podStore.Pods(qj.Namespace).List(selector)
-	if err != nil {
-		return nil, err
+	var result []*v1.Pod
+	for _, pod := range pods {
+		if utils.GetController(pod) == qj.UID {
+			result = append(result, pod)
+		}
 	}
 
-	return pods, nil
+	return result, nil
 }
 
-// manageQueueJob is the core method responsible for managing the number of running
-// pods according to what is specified in the job.Spec.
-// Does NOT modify <activePods>.
+// manageQueueJob is the core method responsible for managing the pods of each task
+// according to what is specified in the job.Spec: missing pods are created, failed
+// pods are deleted (and re-created by next sync) unless its RestartPolicy is Never,
+// and pods out of replicas are deleted.
+// Does NOT modify <pods>.
 func (cc *Controller) manageQueueJob(qj *arbv1.QueueJob, pods []*v1.Pod) error {
-	var err error
-
-	replicas := qj.Spec.Replicas
-
 	running := int32(filterPods(pods, v1.PodRunning))
 	pending := int32(filterPods(pods, v1.PodPending))
 	succeeded := int32(filterPods(pods, v1.PodSucceeded))
 	failed := int32(filterPods(pods, v1.PodFailed))
 
-	glog.V(3).Infof("There are %d pods of QueueJob %s: replicas %d, pending %d, running %d, succeeded %d, failed %d",
-		len(pods), qj.Name, replicas, pending, running, succeeded, failed)
+	glog.V(3).Infof("There are %d pods of QueueJob %s: pending %d, running %d, succeeded %d, failed %d",
+		len(pods), qj.Name, pending, running, succeeded, failed)
 
-	ss, err := cc.arbclients.ArbV1().SchedulingSpecs(qj.Namespace).List(metav1.ListOptions{
-		FieldSelector: fmt.Sprintf("metadata.name=%s", qj.Name),
-	})
+	status := arbv1.QueueJobStatus{
+		Pending:      pending,
+		Running:      running,
+		Succeeded:    succeeded,
+		Failed:       failed,
+		MinAvailable: int32(schedulingSpecTemplate(qj).MinAvailable),
+		Phase:        qj.Status.Phase,
+		Retries:      map[string]int32{},
+	}
+	for name, retries := range qj.Status.Retries {
+		status.Retries[name] = retries
+	}
 
-	if len(ss.Items) == 0 {
-		schedSpc := createQueueJobSchedulingSpec(qj)
-		_, err := cc.arbclients.ArbV1().SchedulingSpecs(qj.Namespace).Create(schedSpc)
+	// The pods of finished QueueJob are not managed any more.
+	if isFinished(status.Phase) {
+		status.Message = qj.Status.Message
+		return cc.updateStatus(qj, &status)
+	}
+
+	// The pods of suspended QueueJob are terminated, and re-created after it's
+	// resumed.
+	if qj.Spec.SchedSpec.Suspend {
+		if err := cc.syncSchedulingSpec(qj); err != nil {
+			return err
+		}
+
+		var podsToDelete []*v1.Pod
+		for _, pod := range pods {
+			if isPodActive(pod) {
+				podsToDelete = append(podsToDelete, pod)
+			}
+		}
+		if err := cc.deletePods(qj, podsToDelete); err != nil {
+			return err
+		}
+		cc.backoff.deleteJob(qj.UID)
+
+		status.Phase = arbv1.QueueJobPhaseSuspended
+		return cc.updateStatus(qj, &status)
+	}
+
+	// The QueueJob is not admitted until its dependencies are completed, and
+	// fails if they'll never be; it's re-enqueued when a dependency finished.
+	if len(pods) == 0 {
+		phase, message, err := cc.dependencyPhase(qj)
 		if err != nil {
-			glog.Errorf("Failed to create SchedulingSpec for QueueJob %v/%v: %v",
-				qj.Namespace, qj.Name, err)
+			return err
+		}
+		if len(phase) != 0 {
+			glog.V(3).Infof("QueueJob %v/%v is %v: %s", qj.Namespace, qj.Name, phase, message)
+			status.Phase = phase
+			status.Message = message
+			return cc.updateStatus(qj, &status)
 		}
-	} else {
-		glog.V(3).Infof("There's %v SchedulingSpec for QueueJob %v/%v",
-			len(ss.Items), qj.Namespace, qj.Name)
 	}
 
-	// Create pod if necessary
-	if diff := replicas - pending - running - succeeded; diff > 0 {
-		glog.V(3).Infof("Try to create %v Pods for QueueJob %v/%v", diff, qj.Namespace, qj.Name)
-
-		var errs []error
-		wait := sync.WaitGroup{}
-		wait.Add(int(diff))
-		for i := int32(0); i < diff; i++ {
-			go func(ix int32) {
-				defer wait.Done()
-				newPod := createQueueJobPod(qj, ix)
-				_, err := cc.clients.Core().Pods(newPod.Namespace).Create(newPod)
-				if err != nil {
-					// Failed to create Pod, wait a moment and then create it again
-					// This is to ensure all pods under the same QueueJob created
-					// So gang-scheduling could schedule the QueueJob successfully
-					glog.Errorf("Failed to create pod %s for QueueJob %s, err %#v",
-						newPod.Name, qj.Name, err)
-					errs = append(errs, err)
+	if err := cc.syncSchedulingSpec(qj); err != nil {
+		return err
+	}
+
+	jobPlugins := cc.getPlugins(qj)
+	for _, plugin := range jobPlugins {
+		if err := plugin.OnJobAdd(qj); err != nil {
+			glog.Errorf("Failed to execute plugin <%s> for QueueJob %v/%v: %v",
+				plugin.Name(), qj.Namespace, qj.Name, err)
+			return err
+		}
+	}
+
+	// Index pods by task and its index in task.
+	podsByTask := map[string]map[int32]*v1.Pod{}
+	for _, pod := range pods {
+		taskName := pod.Labels[TaskSpecLabel]
+		ix, err := utils.GetTaskIndex(pod)
+		if err != nil {
+			glog.V(3).Infof("Ignore pod %v/%v of QueueJob %v: %v",
+				pod.Namespace, pod.Name, qj.Name, err)
+			continue
+		}
+		if _, found := podsByTask[taskName]; !found {
+			podsByTask[taskName] = map[int32]*v1.Pod{}
+		}
+		podsByTask[taskName][ix] = pod
+	}
+
+	var podsToCreate, podsToDelete, podsToScaleDown []*v1.Pod
+	taskSpecs := utils.GetTaskSpecs(qj)
+
+	if isCompleted(qj, taskSpecs, podsByTask) {
+		glog.V(3).Infof("QueueJob %v/%v is completed by policy <%v>, terminate the remaining pods",
+			qj.Namespace, qj.Name, qj.Spec.CompletionPolicy)
+		for _, pod := range pods {
+			if isPodActive(pod) {
+				podsToDelete = append(podsToDelete, pod)
+			}
+		}
+		if err := cc.deletePods(qj, podsToDelete); err != nil {
+			return err
+		}
+
+		status.Phase = arbv1.QueueJobPhaseCompleted
+		return cc.updateStatus(qj, &status)
+	}
+
+	if handled, podsToDelete := cc.handlePolicies(qj, &status, pods); handled {
+		if err := cc.deletePods(qj, podsToDelete); err != nil {
+			return err
+		}
+		if err := cc.updateStatus(qj, &status); err != nil {
+			return err
+		}
+		cc.retried.add(podsToDelete)
+		return nil
+	}
+	for i := range taskSpecs {
+		ts := &taskSpecs[i]
+		taskPods := podsByTask[ts.Name]
+		delete(podsByTask, ts.Name)
+
+		key := backoffKey(qj, ts.Name)
+		for ix := int32(0); ix < ts.Replicas; ix++ {
+			pod, found := taskPods[ix]
+			if !found {
+				// Wait for the backoff of the task's retry.
+				if delay := cc.backoff.remaining(key); delay > 0 {
+					glog.V(3).Infof("Delay %v to create pod %d of task <%s> in QueueJob %v/%v",
+						delay, ix, ts.Name, qj.Namespace, qj.Name)
+					continue
 				}
-			}(i)
+				podsToCreate = append(podsToCreate, createQueueJobPod(qj, ts, ix))
+				continue
+			}
+
+			// The failed pod is deleted, and will be re-created with the same
+			// name after it's removed and the backoff expired.
+			if pod.Status.Phase == v1.PodFailed && pod.DeletionTimestamp == nil &&
+				pod.Spec.RestartPolicy != v1.RestartPolicyNever && !cc.retried.counted(pod) {
+				if !cc.retry(qj, &status, ts.Name, []string{ts.Name}) {
+					return cc.updateStatus(qj, &status)
+				}
+				podsToDelete = append(podsToDelete, pod)
+			}
 		}
-		wait.Wait()
 
-		if len(errs) != 0 {
-			return fmt.Errorf("failed to create %d pods of %d", len(errs), diff)
+		for ix, pod := range taskPods {
+			if ix >= ts.Replicas && pod.DeletionTimestamp == nil {
+				podsToScaleDown = append(podsToScaleDown, pod)
+			}
 		}
 	}
 
-	qj.Status = arbv1.QueueJobStatus{
-		Pending:      pending,
-		Running:      running,
-		Succeeded:    succeeded,
-		Failed:       failed,
-		MinAvailable: int32(qj.Spec.SchedSpec.MinAvailable),
+	// Delete the pods whose task was removed from QueueJob.
+	for _, taskPods := range podsByTask {
+		for _, pod := range taskPods {
+			if pod.DeletionTimestamp == nil {
+				podsToScaleDown = append(podsToScaleDown, pod)
+			}
+		}
+	}
+	podsToDelete = append(podsToDelete, selectPodsToScaleDown(podsToScaleDown, running, status.MinAvailable)...)
+
+	if err := cc.deletePods(qj, podsToDelete); err != nil {
+		return err
+	}
+
+	for _, pod := range podsToCreate {
+		for _, plugin := range jobPlugins {
+			if err := plugin.OnPodCreate(pod, qj); err != nil {
+				glog.Errorf("Failed to execute plugin <%s> for pod %v/%v: %v",
+					plugin.Name(), pod.Namespace, pod.Name, err)
+				return err
+			}
+		}
+	}
+
+	// Create pod if necessary
+	if err := cc.createPods(qj, podsToCreate); err != nil {
+		return err
+	}
+
+	minAvailable := status.MinAvailable
+	if minAvailable == 0 {
+		minAvailable = 1
+	}
+	if running >= minAvailable {
+		status.Phase = arbv1.QueueJobPhaseRunning
+	} else {
+		status.Phase = arbv1.QueueJobPhasePending
+	}
+
+	if err := cc.updateStatus(qj, &status); err != nil {
+		return err
+	}
+	// The failed pods are counted once the retries are persisted.
+	cc.retried.add(podsToDelete)
+	return nil
+}
+
+// handlePolicies executes the actions of the policies matched by failed pods
+// on status, and returns the pods to delete by them; it returns true if any
+// action executed, so the pods are not managed by manageQueueJob in this round.
+func (cc *Controller) handlePolicies(qj *arbv1.QueueJob, status *arbv1.QueueJobStatus, pods []*v1.Pod) (bool, []*v1.Pod) {
+	var abortJob, restartJob bool
+	var failedTasks []string
+	restartTasks := map[string]bool{}
+
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodFailed || pod.DeletionTimestamp != nil || cc.retried.counted(pod) {
+			continue
+		}
+
+		taskName := pod.Labels[TaskSpecLabel]
+		switch getPolicyAction(qj, pod) {
+		case arbv1.AbortJobAction:
+			abortJob = true
+		case arbv1.RestartJobAction:
+			restartJob = true
+			failedTasks = append(failedTasks, taskName)
+		case arbv1.RestartTaskAction:
+			if !restartTasks[taskName] {
+				restartTasks[taskName] = true
+				failedTasks = append(failedTasks, taskName)
+			}
+		}
 	}
 
+	var podsToDelete []*v1.Pod
+	switch {
+	case abortJob:
+		glog.V(3).Infof("Abort QueueJob %v/%v by its policies", qj.Namespace, qj.Name)
+		for _, pod := range pods {
+			if pod.DeletionTimestamp == nil && pod.Status.Phase != v1.PodSucceeded {
+				podsToDelete = append(podsToDelete, pod)
+			}
+		}
+		status.Phase = arbv1.QueueJobPhaseAborted
+
+	case restartJob:
+		glog.V(3).Infof("Restart QueueJob %v/%v by its policies", qj.Namespace, qj.Name)
+		var allTasks []string
+		for _, ts := range utils.GetTaskSpecs(qj) {
+			allTasks = append(allTasks, ts.Name)
+		}
+		// The tasks are re-created together after backoff, as a gang.
+		if !cc.retry(qj, status, failedTasks[0], allTasks) {
+			break
+		}
+		for _, pod := range pods {
+			if pod.DeletionTimestamp == nil {
+				podsToDelete = append(podsToDelete, pod)
+			}
+		}
+
+	case len(restartTasks) != 0:
+		for _, taskName := range failedTasks {
+			glog.V(3).Infof("Restart task <%s> of QueueJob %v/%v by its policies",
+				taskName, qj.Namespace, qj.Name)
+			if !cc.retry(qj, status, taskName, []string{taskName}) {
+				break
+			}
+		}
+		if status.Phase == arbv1.QueueJobPhaseFailed {
+			break
+		}
+		for _, pod := range pods {
+			if pod.DeletionTimestamp == nil && restartTasks[pod.Labels[TaskSpecLabel]] {
+				podsToDelete = append(podsToDelete, pod)
+			}
+		}
+
+	default:
+		return false, nil
+	}
+
+	return true, podsToDelete
+}
+
+// retry records a retry of the task, and delays the re-creation of the pods of
+// backoffTasks; it returns false and marks QueueJob failed if the task exceeded
+// MaxRetry.
+func (cc *Controller) retry(qj *arbv1.QueueJob, status *arbv1.QueueJobStatus, taskName string, backoffTasks []string) bool {
+	status.Retries[taskName]++
+	if qj.Spec.MaxRetry != nil && status.Retries[taskName] > *qj.Spec.MaxRetry {
+		glog.V(3).Infof("Task <%s> of QueueJob %v/%v exceeded max retry %d, mark it failed",
+			taskName, qj.Namespace, qj.Name, *qj.Spec.MaxRetry)
+		status.Phase = arbv1.QueueJobPhaseFailed
+		return false
+	}
+
+	delay := backoffDelay(status.Retries[taskName], backoffLimit(qj))
+	for _, name := range backoffTasks {
+		cc.backoff.next(backoffKey(qj, name), delay)
+	}
+	cc.enqueueAfter(qj, delay)
+
+	return true
+}
+
+// dependencyPhase returns the phase of qj by the QueueJobs that it depends on:
+// Pending if any of them is not Completed yet, or Failed if any of them will
+// never be, i.e. it's Failed or Aborted, or the dependencies are a cycle; it's
+// empty if all of them are Completed. The message tells the dependency.
+func (cc *Controller) dependencyPhase(qj *arbv1.QueueJob) (arbv1.QueueJobPhase, string, error) {
+	if len(qj.Spec.DependsOn) == 0 {
+		return "", "", nil
+	}
+
+	cycle, err := cc.dependencyCycle(qj)
+	if err != nil {
+		return "", "", err
+	}
+	if len(cycle) != 0 {
+		return arbv1.QueueJobPhaseFailed,
+			fmt.Sprintf("dependencies are a cycle: %s", strings.Join(cycle, " -> ")), nil
+	}
+
+	for _, name := range qj.Spec.DependsOn {
+		dep, err := cc.queueJobLister.QueueJobs(qj.Namespace).Get(name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return arbv1.QueueJobPhasePending,
+					fmt.Sprintf("waiting for dependency <%s> to be created", name), nil
+			}
+			return "", "", err
+		}
+
+		switch dep.Status.Phase {
+		case arbv1.QueueJobPhaseCompleted:
+		case arbv1.QueueJobPhaseFailed, arbv1.QueueJobPhaseAborted:
+			return arbv1.QueueJobPhaseFailed,
+				fmt.Sprintf("dependency <%s> is %s", name, dep.Status.Phase), nil
+		default:
+			return arbv1.QueueJobPhasePending,
+				fmt.Sprintf("waiting for dependency <%s> to be completed", name), nil
+		}
+	}
+
+	return "", "", nil
+}
+
+// dependencyCycle returns the cycle of dependencies through qj, e.g.
+// [a b a] if a depends on b and b depends on a; it's nil if no cycle. The
+// dependencies not found are ignored.
+func (cc *Controller) dependencyCycle(qj *arbv1.QueueJob) ([]string, error) {
+	visited := map[string]bool{}
+	path := []string{qj.Name}
+
+	var visit func(deps []string) (bool, error)
+	visit = func(deps []string) (bool, error) {
+		for _, name := range deps {
+			if name == qj.Name {
+				path = append(path, name)
+				return true, nil
+			}
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+
+			dep, err := cc.queueJobLister.QueueJobs(qj.Namespace).Get(name)
+			if err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return false, err
+			}
+
+			path = append(path, name)
+			found, err := visit(dep.Spec.DependsOn)
+			if found || err != nil {
+				return found, err
+			}
+			path = path[:len(path)-1]
+		}
+		return false, nil
+	}
+
+	found, err := visit(qj.Spec.DependsOn)
+	if !found || err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+func (cc *Controller) updateStatus(qj *arbv1.QueueJob, status *arbv1.QueueJobStatus) error {
+	qj.Status = *status
+
 	// TODO(k82cn): replaced it with `UpdateStatus`
 	if _, err := cc.arbclients.ArbV1().QueueJobs(qj.Namespace).Update(qj); err != nil {
 		glog.Errorf("Failed to update status of QueueJob %v/%v: %v",
@@ -405,5 +786,125 @@ func (cc *Controller) manageQueueJob(qj *arbv1.QueueJob, pods []*v1.Pod) error {
 		return err
 	}
 
-	return err
+	return nil
+}
+
+// getPlugins builds the plugins enabled by QueueJob; the unknown plugins are ignored.
+func (cc *Controller) getPlugins(qj *arbv1.QueueJob) []plugins.Plugin {
+	var result []plugins.Plugin
+	for _, name := range qj.Spec.Plugins {
+		pb, found := plugins.GetPluginBuilder(name)
+		if !found {
+			glog.Warningf("Plugin <%s> of QueueJob %v/%v not found, ignore it",
+				name, qj.Namespace, qj.Name)
+			continue
+		}
+		result = append(result, pb(cc.clients))
+	}
+	return result
+}
+
+// syncSchedulingSpec creates the SchedulingSpec of QueueJob if not found, and
+// updates it if it's different from QueueJob's SchedSpec, e.g. MinAvailable changed.
+func (cc *Controller) syncSchedulingSpec(qj *arbv1.QueueJob) error {
+	ss, err := cc.arbclients.ArbV1().SchedulingSpecs(qj.Namespace).Get(qj.Name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		schedSpc := createQueueJobSchedulingSpec(qj)
+		if _, err := cc.arbclients.ArbV1().SchedulingSpecs(qj.Namespace).Create(schedSpc); err != nil {
+			glog.Errorf("Failed to create SchedulingSpec for QueueJob %v/%v: %v",
+				qj.Namespace, qj.Name, err)
+			return err
+		}
+		return nil
+	}
+
+	spec := schedulingSpecTemplate(qj)
+	if reflect.DeepEqual(ss.Spec, spec) {
+		return nil
+	}
+
+	glog.V(3).Infof("Update SchedulingSpec of QueueJob %v/%v: minAvailable %v -> %v",
+		qj.Namespace, qj.Name, ss.Spec.MinAvailable, spec.MinAvailable)
+
+	ss.Spec = spec
+	if _, err := cc.arbclients.ArbV1().SchedulingSpecs(qj.Namespace).Update(ss); err != nil {
+		glog.Errorf("Failed to update SchedulingSpec for QueueJob %v/%v: %v",
+			qj.Namespace, qj.Name, err)
+		return err
+	}
+
+	return nil
+}
+
+func (cc *Controller) createPods(qj *arbv1.QueueJob, pods []*v1.Pod) error {
+	if len(pods) == 0 {
+		return nil
+	}
+
+	glog.V(3).Infof("Try to create %v Pods for QueueJob %v/%v", len(pods), qj.Namespace, qj.Name)
+
+	var errs []error
+	var lock sync.Mutex
+	wait := sync.WaitGroup{}
+	wait.Add(len(pods))
+	for _, pod := range pods {
+		go func(newPod *v1.Pod) {
+			defer wait.Done()
+			_, err := cc.clients.Core().Pods(newPod.Namespace).Create(newPod)
+			if err != nil && !apierrors.IsAlreadyExists(err) {
+				// Failed to create Pod, wait a moment and then create it again
+				// This is to ensure all pods under the same QueueJob created
+				// So gang-scheduling could schedule the QueueJob successfully
+				glog.Errorf("Failed to create pod %s for QueueJob %s, err %#v",
+					newPod.Name, qj.Name, err)
+				lock.Lock()
+				errs = append(errs, err)
+				lock.Unlock()
+			}
+		}(pod)
+	}
+	wait.Wait()
+
+	if len(errs) != 0 {
+		return fmt.Errorf("failed to create %d pods of %d", len(errs), len(pods))
+	}
+
+	return nil
+}
+
+func (cc *Controller) deletePods(qj *arbv1.QueueJob, pods []*v1.Pod) error {
+	if len(pods) == 0 {
+		return nil
+	}
+
+	glog.V(3).Infof("Try to delete %v Pods of QueueJob %v/%v", len(pods), qj.Namespace, qj.Name)
+
+	var errs []error
+	var lock sync.Mutex
+	wait := sync.WaitGroup{}
+	wait.Add(len(pods))
+	for _, pod := range pods {
+		go func(p *v1.Pod) {
+			defer wait.Done()
+			err := cc.clients.Core().Pods(p.Namespace).Delete(p.Name, &metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				glog.Errorf("Failed to delete pod %s for QueueJob %s, err %#v",
+					p.Name, qj.Name, err)
+				lock.Lock()
+				errs = append(errs, err)
+				lock.Unlock()
+			}
+		}(pod)
+	}
+	wait.Wait()
+
+	if len(errs) != 0 {
+		return fmt.Errorf("failed to delete %d pods of %d", len(errs), len(pods))
+	}
+
+	return nil
 }
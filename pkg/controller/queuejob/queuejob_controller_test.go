@@ -0,0 +1,306 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queuejob
+
+import (
+	"sort"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	listersv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/listers/v1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/workqueue"
+)
+
+func buildPolicyPod(name, task string, phase v1.PodPhase, reason string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "c1",
+			UID:       types.UID(name),
+			Labels:    map[string]string{TaskSpecLabel: task},
+		},
+		Status: v1.PodStatus{Phase: phase, Reason: reason},
+	}
+}
+
+func TestHandlePolicies(t *testing.T) {
+	one := int32(1)
+
+	tests := []struct {
+		name     string
+		policies []arbv1.LifecyclePolicy
+		maxRetry *int32
+		retries  map[string]int32
+		pods     []*v1.Pod
+		counted  []*v1.Pod
+		handled  bool
+		deleted  []string
+		phase    arbv1.QueueJobPhase
+		retried  map[string]int32
+	}{
+		{
+			name:     "no failed pods",
+			policies: []arbv1.LifecyclePolicy{{Event: arbv1.PodFailedEvent, Action: arbv1.AbortJobAction}},
+			pods:     []*v1.Pod{buildPolicyPod("ps-0", "ps", v1.PodRunning, "")},
+		},
+		{
+			name: "no policy matched",
+			pods: []*v1.Pod{buildPolicyPod("ps-0", "ps", v1.PodFailed, "")},
+		},
+		{
+			name:     "abort job",
+			policies: []arbv1.LifecyclePolicy{{Event: arbv1.PodFailedEvent, Action: arbv1.AbortJobAction}},
+			pods: []*v1.Pod{
+				buildPolicyPod("ps-0", "ps", v1.PodFailed, ""),
+				buildPolicyPod("worker-0", "worker", v1.PodRunning, ""),
+				buildPolicyPod("worker-1", "worker", v1.PodSucceeded, ""),
+			},
+			handled: true,
+			deleted: []string{"ps-0", "worker-0"},
+			phase:   arbv1.QueueJobPhaseAborted,
+		},
+		{
+			name:     "restart job",
+			policies: []arbv1.LifecyclePolicy{{Event: arbv1.PodFailedEvent, Action: arbv1.RestartJobAction}},
+			pods: []*v1.Pod{
+				buildPolicyPod("ps-0", "ps", v1.PodRunning, ""),
+				buildPolicyPod("worker-0", "worker", v1.PodFailed, ""),
+			},
+			handled: true,
+			deleted: []string{"ps-0", "worker-0"},
+			retried: map[string]int32{"worker": 1},
+		},
+		{
+			name:     "restart task",
+			policies: []arbv1.LifecyclePolicy{{Event: arbv1.PodFailedEvent, Action: arbv1.RestartTaskAction}},
+			pods: []*v1.Pod{
+				buildPolicyPod("ps-0", "ps", v1.PodRunning, ""),
+				buildPolicyPod("worker-0", "worker", v1.PodFailed, ""),
+				buildPolicyPod("worker-1", "worker", v1.PodRunning, ""),
+			},
+			handled: true,
+			deleted: []string{"worker-0", "worker-1"},
+			retried: map[string]int32{"worker": 1},
+		},
+		{
+			name:     "restart task exceeding max retry",
+			policies: []arbv1.LifecyclePolicy{{Event: arbv1.PodFailedEvent, Action: arbv1.RestartTaskAction}},
+			maxRetry: &one,
+			retries:  map[string]int32{"worker": 1},
+			pods: []*v1.Pod{
+				buildPolicyPod("worker-0", "worker", v1.PodFailed, ""),
+				buildPolicyPod("worker-1", "worker", v1.PodRunning, ""),
+			},
+			handled: true,
+			phase:   arbv1.QueueJobPhaseFailed,
+			retried: map[string]int32{"worker": 2},
+		},
+		{
+			name:     "failed pod counted by previous sync",
+			policies: []arbv1.LifecyclePolicy{{Event: arbv1.PodFailedEvent, Action: arbv1.RestartTaskAction}},
+			retries:  map[string]int32{"worker": 1},
+			pods: []*v1.Pod{
+				buildPolicyPod("worker-0", "worker", v1.PodFailed, ""),
+				buildPolicyPod("worker-1", "worker", v1.PodRunning, ""),
+			},
+			counted: []*v1.Pod{buildPolicyPod("worker-0", "worker", v1.PodFailed, "")},
+			retried: map[string]int32{"worker": 1},
+		},
+		{
+			name: "evicted pod matches PodEvicted first",
+			policies: []arbv1.LifecyclePolicy{
+				{Event: arbv1.PodFailedEvent, Action: arbv1.AbortJobAction},
+				{Event: arbv1.PodEvictedEvent, Action: arbv1.RestartTaskAction},
+			},
+			pods: []*v1.Pod{
+				buildPolicyPod("ps-0", "ps", v1.PodRunning, ""),
+				buildPolicyPod("worker-0", "worker", v1.PodFailed, podEvictedReason),
+			},
+			handled: true,
+			deleted: []string{"worker-0"},
+			retried: map[string]int32{"worker": 1},
+		},
+	}
+
+	for _, test := range tests {
+		cc := &Controller{
+			queue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "queuejob-test"),
+			backoff: newRetryBackoff(),
+			retried: newRetriedPods(),
+		}
+		cc.retried.add(test.counted)
+
+		qj := buildQueueJob(arbv1.TaskSpec{Name: "ps", Replicas: 1}, arbv1.TaskSpec{Name: "worker", Replicas: 2})
+		qj.Spec.Policies = test.policies
+		qj.Spec.MaxRetry = test.maxRetry
+
+		status := &arbv1.QueueJobStatus{Retries: map[string]int32{}}
+		for name, retries := range test.retries {
+			status.Retries[name] = retries
+		}
+
+		handled, podsToDelete := cc.handlePolicies(qj, status, test.pods)
+		cc.queue.ShutDown()
+
+		if handled != test.handled {
+			t.Errorf("%s: expected handled %v, got %v", test.name, test.handled, handled)
+			continue
+		}
+
+		var deleted []string
+		for _, pod := range podsToDelete {
+			deleted = append(deleted, pod.Name)
+		}
+		sort.Strings(deleted)
+		if len(deleted) != len(test.deleted) {
+			t.Errorf("%s: expected pods %v deleted, got %v", test.name, test.deleted, deleted)
+		} else {
+			for i := range deleted {
+				if deleted[i] != test.deleted[i] {
+					t.Errorf("%s: expected pods %v deleted, got %v", test.name, test.deleted, deleted)
+					break
+				}
+			}
+		}
+
+		if status.Phase != test.phase {
+			t.Errorf("%s: expected phase %q, got %q", test.name, test.phase, status.Phase)
+		}
+		for name, retries := range test.retried {
+			if status.Retries[name] != retries {
+				t.Errorf("%s: expected %d retries of task %s, got %d",
+					test.name, retries, name, status.Retries[name])
+			}
+		}
+	}
+}
+
+func buildDependentQueueJob(name string, phase arbv1.QueueJobPhase, dependsOn ...string) *arbv1.QueueJob {
+	return &arbv1.QueueJob{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1"},
+		Spec:       arbv1.QueueJobSpec{DependsOn: dependsOn},
+		Status:     arbv1.QueueJobStatus{Phase: phase},
+	}
+}
+
+func TestDependencyPhase(t *testing.T) {
+	tests := []struct {
+		name    string
+		qjs     []*arbv1.QueueJob
+		phase   arbv1.QueueJobPhase
+		message string
+	}{
+		{
+			name: "no dependencies",
+			qjs:  []*arbv1.QueueJob{buildDependentQueueJob("qj", "")},
+		},
+		{
+			name: "dependencies completed",
+			qjs: []*arbv1.QueueJob{
+				buildDependentQueueJob("qj", "", "a", "b"),
+				buildDependentQueueJob("a", arbv1.QueueJobPhaseCompleted),
+				buildDependentQueueJob("b", arbv1.QueueJobPhaseCompleted),
+			},
+		},
+		{
+			name: "dependency running",
+			qjs: []*arbv1.QueueJob{
+				buildDependentQueueJob("qj", "", "a", "b"),
+				buildDependentQueueJob("a", arbv1.QueueJobPhaseCompleted),
+				buildDependentQueueJob("b", arbv1.QueueJobPhaseRunning),
+			},
+			phase:   arbv1.QueueJobPhasePending,
+			message: "waiting for dependency <b> to be completed",
+		},
+		{
+			name:    "dependency not found",
+			qjs:     []*arbv1.QueueJob{buildDependentQueueJob("qj", "", "a")},
+			phase:   arbv1.QueueJobPhasePending,
+			message: "waiting for dependency <a> to be created",
+		},
+		{
+			name: "dependency failed",
+			qjs: []*arbv1.QueueJob{
+				buildDependentQueueJob("qj", "", "a"),
+				buildDependentQueueJob("a", arbv1.QueueJobPhaseFailed),
+			},
+			phase:   arbv1.QueueJobPhaseFailed,
+			message: "dependency <a> is Failed",
+		},
+		{
+			name: "dependency aborted",
+			qjs: []*arbv1.QueueJob{
+				buildDependentQueueJob("qj", "", "a"),
+				buildDependentQueueJob("a", arbv1.QueueJobPhaseAborted),
+			},
+			phase:   arbv1.QueueJobPhaseFailed,
+			message: "dependency <a> is Aborted",
+		},
+		{
+			name: "dependency cycle",
+			qjs: []*arbv1.QueueJob{
+				buildDependentQueueJob("qj", "", "a"),
+				buildDependentQueueJob("a", arbv1.QueueJobPhasePending, "c", "b"),
+				buildDependentQueueJob("b", arbv1.QueueJobPhasePending, "qj"),
+				buildDependentQueueJob("c", arbv1.QueueJobPhaseCompleted),
+			},
+			phase:   arbv1.QueueJobPhaseFailed,
+			message: "dependencies are a cycle: qj -> a -> b -> qj",
+		},
+		{
+			name: "depends on itself",
+			qjs: []*arbv1.QueueJob{
+				buildDependentQueueJob("qj", "", "qj"),
+			},
+			phase:   arbv1.QueueJobPhaseFailed,
+			message: "dependencies are a cycle: qj -> qj",
+		},
+		{
+			name: "cycle not through QueueJob",
+			qjs: []*arbv1.QueueJob{
+				buildDependentQueueJob("qj", "", "a"),
+				buildDependentQueueJob("a", arbv1.QueueJobPhasePending, "b"),
+				buildDependentQueueJob("b", arbv1.QueueJobPhasePending, "a"),
+			},
+			phase:   arbv1.QueueJobPhasePending,
+			message: "waiting for dependency <a> to be completed",
+		},
+	}
+
+	for _, test := range tests {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		for _, qj := range test.qjs {
+			indexer.Add(qj)
+		}
+		cc := &Controller{queueJobLister: listersv1.NewQueueJobLister(indexer)}
+
+		phase, message, err := cc.dependencyPhase(test.qjs[0])
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if phase != test.phase || message != test.message {
+			t.Errorf("%s: expected phase %q with message %q, got %q with %q",
+				test.name, test.phase, test.message, phase, message)
+		}
+	}
+}
@@ -18,21 +18,28 @@ package queuejob
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/golang/glog"
 
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/rest"
 
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
 	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client"
 )
 
 var queueJobKind = arbv1.SchemeGroupVersion.WithKind("QueueJob")
 
+// podEvictedReason is the reason of the pod evicted by kubelet.
+const podEvictedReason = "Evicted"
+
 func generateUUID() string {
 	id := uuid.NewUUID()
 
@@ -57,15 +64,6 @@ func filterPods(pods []*corev1.Pod, phase corev1.PodPhase) int {
 	return result
 }
 
-func eventKey(obj interface{}) (string, error) {
-	accessor, err := meta.Accessor(obj)
-	if err != nil {
-		return "", err
-	}
-
-	return string(accessor.GetUID()), nil
-}
-
 func createQueueJobSchedulingSpec(qj *arbv1.QueueJob) *arbv1.SchedulingSpec {
 	return &arbv1.SchedulingSpec{
 		ObjectMeta: metav1.ObjectMeta{
@@ -75,23 +73,112 @@ func createQueueJobSchedulingSpec(qj *arbv1.QueueJob) *arbv1.SchedulingSpec {
 				*metav1.NewControllerRef(qj, queueJobKind),
 			},
 		},
-		Spec: qj.Spec.SchedSpec,
+		Spec: schedulingSpecTemplate(qj),
+	}
+}
+
+// schedulingSpecTemplate returns the SchedSpec of QueueJob; its minAvailable
+// is capped by the total replicas, so the gang is still schedulable after the
+// QueueJob is scaled down.
+func schedulingSpecTemplate(qj *arbv1.QueueJob) arbv1.SchedulingSpecTemplate {
+	spec := *qj.Spec.SchedSpec.DeepCopy()
+
+	replicas := 0
+	for _, ts := range utils.GetTaskSpecs(qj) {
+		replicas += int(ts.Replicas)
+	}
+	if spec.MinAvailable > replicas {
+		spec.MinAvailable = replicas
+	}
+
+	return spec
+}
+
+// isFinished returns whether the phase of QueueJob is final, so its pods
+// are not managed any more.
+func isFinished(phase arbv1.QueueJobPhase) bool {
+	return phase == arbv1.QueueJobPhaseFailed ||
+		phase == arbv1.QueueJobPhaseCompleted ||
+		phase == arbv1.QueueJobPhaseAborted
+}
+
+// getPolicyAction returns the action of QueueJob's policy for the failed pod;
+// the evicted pod matches the policy of PodEvicted first, then PodFailed.
+func getPolicyAction(qj *arbv1.QueueJob, pod *corev1.Pod) arbv1.Action {
+	events := []arbv1.Event{arbv1.PodFailedEvent}
+	if pod.Status.Reason == podEvictedReason {
+		events = []arbv1.Event{arbv1.PodEvictedEvent, arbv1.PodFailedEvent}
+	}
+
+	for _, event := range events {
+		for _, policy := range qj.Spec.Policies {
+			if policy.Event == event {
+				return policy.Action
+			}
+		}
+	}
+
+	return ""
+}
+
+// isCompleted returns whether QueueJob is completed by its CompletionPolicy;
+// a task is finished if all of its pods succeeded, and the tasks without
+// replicas are ignored.
+func isCompleted(qj *arbv1.QueueJob, taskSpecs []arbv1.TaskSpec, podsByTask map[string]map[int32]*corev1.Pod) bool {
+	tasks, finished := 0, 0
+	for i := range taskSpecs {
+		if taskSpecs[i].Replicas == 0 {
+			continue
+		}
+		tasks++
+		if taskFinished(&taskSpecs[i], podsByTask[taskSpecs[i].Name]) {
+			finished++
+		}
+	}
+
+	if qj.Spec.CompletionPolicy == arbv1.CompletionPolicyAny {
+		return finished > 0
+	}
+
+	return tasks != 0 && finished == tasks
+}
+
+func taskFinished(ts *arbv1.TaskSpec, taskPods map[int32]*corev1.Pod) bool {
+	for ix := int32(0); ix < ts.Replicas; ix++ {
+		pod, found := taskPods[ix]
+		if !found || pod.Status.Phase != corev1.PodSucceeded {
+			return false
+		}
 	}
+
+	return true
 }
 
-func createQueueJobPod(qj *arbv1.QueueJob, ix int32) *corev1.Pod {
-	templateCopy := qj.Spec.Template.DeepCopy()
+func createQueueJobPod(qj *arbv1.QueueJob, ts *arbv1.TaskSpec, ix int32) *corev1.Pod {
+	templateCopy := ts.Template.DeepCopy()
+
+	labels := templateCopy.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[QueueJobLabel] = qj.Name
+	labels[TaskSpecLabel] = ts.Name
 
-	podName := fmt.Sprintf("%s-%d-%s", qj.Name, ix, generateUUID())
+	annotations := templateCopy.Annotations
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[TaskIndexAnnotation] = strconv.Itoa(int(ix))
 
 	return &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      podName,
+			Name:      utils.TaskPodName(qj.Name, ts.Name, ix),
 			Namespace: qj.Namespace,
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(qj, queueJobKind),
 			},
-			Labels: templateCopy.Labels,
+			Labels:      labels,
+			Annotations: annotations,
 		},
 		Spec: templateCopy.Spec,
 	}
@@ -108,3 +195,28 @@ func createQueueJobKind(config *rest.Config) error {
 	}
 	return nil
 }
+
+// selectPodsToScaleDown returns the pods to delete when QueueJob is scaled
+// down: the pods not running are deleted first, and the running pods are kept
+// if the running gang would be broken below minAvailable; they're deleted after
+// the other members of gang are running.
+func selectPodsToScaleDown(pods []*corev1.Pod, running, minAvailable int32) []*corev1.Pod {
+	sort.SliceStable(pods, func(i, j int) bool {
+		return pods[i].Status.Phase != corev1.PodRunning && pods[j].Status.Phase == corev1.PodRunning
+	})
+
+	var result []*corev1.Pod
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning {
+			if running <= minAvailable {
+				glog.V(3).Infof("Keep running pod %v/%v to scale down later, as only %d pods running (minAvailable %d)",
+					pod.Namespace, pod.Name, running, minAvailable)
+				continue
+			}
+			running--
+		}
+		result = append(result, pod)
+	}
+
+	return result
+}
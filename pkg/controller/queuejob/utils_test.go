@@ -0,0 +1,172 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queuejob
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+)
+
+func buildQueueJob(tasks ...arbv1.TaskSpec) *arbv1.QueueJob {
+	return &arbv1.QueueJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "qj", Namespace: "c1", UID: "qj-uid"},
+		Spec:       arbv1.QueueJobSpec{TaskSpecs: tasks},
+	}
+}
+
+func TestCreateQueueJobPod(t *testing.T) {
+	qj := buildQueueJob(
+		arbv1.TaskSpec{
+			Name:     "ps",
+			Replicas: 2,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "tf"}},
+			},
+		},
+		arbv1.TaskSpec{Name: "worker", Replicas: 4},
+	)
+
+	for i, test := range []struct {
+		task int
+		ix   int32
+		name string
+	}{
+		{task: 0, ix: 1, name: "qj-ps-1"},
+		{task: 1, ix: 3, name: "qj-worker-3"},
+	} {
+		ts := &qj.Spec.TaskSpecs[test.task]
+		pod := createQueueJobPod(qj, ts, test.ix)
+
+		if pod.Name != test.name {
+			t.Errorf("case %d: expected pod name %s, got %s", i, test.name, pod.Name)
+		}
+		if pod.Labels[QueueJobLabel] != qj.Name || pod.Labels[TaskSpecLabel] != ts.Name {
+			t.Errorf("case %d: expected labels of QueueJob and task, got %v", i, pod.Labels)
+		}
+		if ix, err := utils.GetTaskIndex(pod); err != nil || ix != test.ix {
+			t.Errorf("case %d: expected task index %d, got %d (%v)", i, test.ix, ix, err)
+		}
+		if utils.GetController(pod) != qj.UID {
+			t.Errorf("case %d: expected pod controlled by QueueJob", i)
+		}
+	}
+
+	// The labels of template are kept, and not shared by pods.
+	pod := createQueueJobPod(qj, &qj.Spec.TaskSpecs[0], 0)
+	if pod.Labels["app"] != "tf" {
+		t.Errorf("expected labels of template kept, got %v", pod.Labels)
+	}
+	if _, found := qj.Spec.TaskSpecs[0].Template.Labels[TaskSpecLabel]; found {
+		t.Errorf("expected template of QueueJob not modified")
+	}
+
+	// Spec.Template is an anonymous task.
+	qj = buildQueueJob()
+	qj.Spec.Replicas = 1
+	if pod := createQueueJobPod(qj, &utils.GetTaskSpecs(qj)[0], 0); pod.Name != "qj-0" {
+		t.Errorf("expected pod name qj-0 of anonymous task, got %s", pod.Name)
+	}
+}
+
+func buildTaskPods(phases ...corev1.PodPhase) map[int32]*corev1.Pod {
+	pods := map[int32]*corev1.Pod{}
+	for ix, phase := range phases {
+		pods[int32(ix)] = &corev1.Pod{Status: corev1.PodStatus{Phase: phase}}
+	}
+	return pods
+}
+
+func TestIsCompleted(t *testing.T) {
+	tasks := []arbv1.TaskSpec{
+		{Name: "ps", Replicas: 1},
+		{Name: "worker", Replicas: 2},
+		{Name: "evaluator", Replicas: 0},
+	}
+
+	tests := []struct {
+		name       string
+		policy     arbv1.CompletionPolicy
+		podsByTask map[string]map[int32]*corev1.Pod
+		expected   bool
+	}{
+		{
+			name:   "all tasks succeeded",
+			policy: arbv1.CompletionPolicyAll,
+			podsByTask: map[string]map[int32]*corev1.Pod{
+				"ps":     buildTaskPods(corev1.PodSucceeded),
+				"worker": buildTaskPods(corev1.PodSucceeded, corev1.PodSucceeded),
+			},
+			expected: true,
+		},
+		{
+			name:   "one task running",
+			policy: arbv1.CompletionPolicyAll,
+			podsByTask: map[string]map[int32]*corev1.Pod{
+				"ps":     buildTaskPods(corev1.PodRunning),
+				"worker": buildTaskPods(corev1.PodSucceeded, corev1.PodSucceeded),
+			},
+		},
+		{
+			name:   "pod of task missing",
+			policy: arbv1.CompletionPolicyAll,
+			podsByTask: map[string]map[int32]*corev1.Pod{
+				"ps":     buildTaskPods(corev1.PodSucceeded),
+				"worker": buildTaskPods(corev1.PodSucceeded),
+			},
+		},
+		{
+			name:   "any task succeeded",
+			policy: arbv1.CompletionPolicyAny,
+			podsByTask: map[string]map[int32]*corev1.Pod{
+				"ps":     buildTaskPods(corev1.PodRunning),
+				"worker": buildTaskPods(corev1.PodSucceeded, corev1.PodSucceeded),
+			},
+			expected: true,
+		},
+		{
+			name:   "no task succeeded",
+			policy: arbv1.CompletionPolicyAny,
+			podsByTask: map[string]map[int32]*corev1.Pod{
+				"ps":     buildTaskPods(corev1.PodFailed),
+				"worker": buildTaskPods(corev1.PodSucceeded, corev1.PodRunning),
+			},
+		},
+		{
+			name:   "no pods",
+			policy: arbv1.CompletionPolicyAll,
+		},
+	}
+
+	for _, test := range tests {
+		qj := buildQueueJob(tasks...)
+		qj.Spec.CompletionPolicy = test.policy
+		if completed := isCompleted(qj, tasks, test.podsByTask); completed != test.expected {
+			t.Errorf("%s: expected completed %v, got %v", test.name, test.expected, completed)
+		}
+	}
+
+	// The QueueJob without tasks of replicas is never completed.
+	qj := buildQueueJob(arbv1.TaskSpec{Name: "ps"})
+	if isCompleted(qj, qj.Spec.TaskSpecs, nil) {
+		t.Errorf("expected QueueJob without replicas not completed")
+	}
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rebalancer
+
+import (
+	"expvar"
+	"math"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+	arbinformers "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers"
+	informersv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers/v1"
+)
+
+const (
+	// defaultPeriod is the default interval between two checks.
+	defaultPeriod = 10 * time.Minute
+
+	// cooldown is the minimal interval between two re-schedules of a gang,
+	// so a gang is not shuffled repeatedly when it can't be placed better.
+	cooldown = time.Hour
+
+	// spreadThreshold is how many times of the minimal nodes a gang may be
+	// spread over before it's fragmented.
+	spreadThreshold = 2
+	// minExtraNodes is the minimal number of nodes more than needed for a
+	// fragmented gang, so small gangs are not re-scheduled for one node.
+	minExtraNodes = 2
+)
+
+// metrics are exposed by expvar, e.g. "rebalancer": {"fragmented": 1, ...};
+// they're the number of gangs marked to be re-scheduled by the reason.
+var (
+	metrics            = expvar.NewMap("rebalancer")
+	fragmentedMetric   = "fragmented"
+	softAffinityMetric = "soft_affinity_violated"
+	markFailedMetric   = "mark_failed"
+)
+
+// Rebalancer periodically checks the placement of running gangs, and marks
+// the degraded ones by RescheduleAnnotation, so they're evicted and placed
+// again by the shuffle action of scheduler. A gang is degraded if it's
+// fragmented over much more nodes than it needs, or most of its pods violate
+// their preferred node affinity.
+type Rebalancer struct {
+	clients    *kubernetes.Clientset
+	arbclients *clientset.Clientset
+
+	// period is the interval between two checks.
+	period time.Duration
+	// maxGangs is the maximal number of gangs marked in a check.
+	maxGangs int
+
+	schedulingSpecInformer informersv1.SchedulingSpecInformer
+	podInformer            coreinformers.PodInformer
+	nodeInformer           coreinformers.NodeInformer
+}
+
+// NewRebalancer creates a new Rebalancer; the default period is used if
+// period is not positive.
+func NewRebalancer(config *rest.Config, period time.Duration, maxGangs int) *Rebalancer {
+	if period <= 0 {
+		period = defaultPeriod
+	}
+
+	rb := &Rebalancer{
+		clients:    kubernetes.NewForConfigOrDie(config),
+		arbclients: clientset.NewForConfigOrDie(config),
+		period:     period,
+		maxGangs:   maxGangs,
+	}
+
+	arbClient, _, err := client.NewClient(config)
+	if err != nil {
+		panic(err)
+	}
+
+	rb.schedulingSpecInformer = arbinformers.NewSharedInformerFactory(arbClient, 0).SchedulingSpec().SchedulingSpecs()
+
+	informerFactory := informers.NewSharedInformerFactory(rb.clients, 0)
+	rb.podInformer = informerFactory.Core().V1().Pods()
+	rb.nodeInformer = informerFactory.Core().V1().Nodes()
+
+	return rb
+}
+
+// Run starts the rebalance loop.
+func (rb *Rebalancer) Run(stopCh <-chan struct{}) {
+	go rb.schedulingSpecInformer.Informer().Run(stopCh)
+	go rb.podInformer.Informer().Run(stopCh)
+	go rb.nodeInformer.Informer().Run(stopCh)
+
+	cache.WaitForCacheSync(stopCh,
+		rb.schedulingSpecInformer.Informer().HasSynced,
+		rb.podInformer.Informer().HasSynced,
+		rb.nodeInformer.Informer().HasSynced)
+
+	go wait.Until(rb.rebalance, rb.period, stopCh)
+}
+
+func (rb *Rebalancer) rebalance() {
+	glog.V(4).Infof("Start rebalance ...")
+	defer glog.V(4).Infof("End rebalance ...")
+
+	specs, err := rb.schedulingSpecInformer.Lister().List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to list SchedulingSpecs: %v", err)
+		return
+	}
+
+	nodeList, err := rb.nodeInformer.Lister().List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to list nodes: %v", err)
+		return
+	}
+	nodes := make(map[string]*v1.Node, len(nodeList))
+	for _, node := range nodeList {
+		nodes[node.Name] = node
+	}
+
+	marked := 0
+	for _, ss := range specs {
+		if rb.maxGangs > 0 && marked >= rb.maxGangs {
+			glog.V(3).Infof("Marked %d gangs to re-schedule, skip others until next check", marked)
+			return
+		}
+
+		if ss.Spec.Suspend || inCooldown(ss, time.Now()) {
+			continue
+		}
+
+		pods, err := rb.gangPods(ss)
+		if err != nil {
+			glog.Errorf("Failed to list pods of SchedulingSpec <%v/%v>: %v", ss.Namespace, ss.Name, err)
+			continue
+		}
+		if !isPlaced(ss, pods) {
+			continue
+		}
+
+		var reason string
+		switch {
+		case isFragmented(pods, nodes):
+			reason = fragmentedMetric
+		case violatesSoftAffinity(pods, nodes):
+			reason = softAffinityMetric
+		default:
+			continue
+		}
+
+		glog.V(3).Infof("Placement of SchedulingSpec <%v/%v> is degraded (%s), mark it to re-schedule",
+			ss.Namespace, ss.Name, reason)
+		if err := rb.markReschedule(ss); err != nil {
+			glog.Errorf("Failed to mark SchedulingSpec <%v/%v> to re-schedule: %v", ss.Namespace, ss.Name, err)
+			metrics.Add(markFailedMetric, 1)
+			continue
+		}
+		metrics.Add(reason, 1)
+		marked++
+	}
+}
+
+// gangPods returns the pods of the gang of SchedulingSpec.
+func (rb *Rebalancer) gangPods(ss *arbv1.SchedulingSpec) ([]*v1.Pod, error) {
+	owner := utils.GetController(ss)
+	if len(owner) == 0 {
+		return nil, nil
+	}
+
+	pods, err := rb.podInformer.Lister().Pods(ss.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	var gang []*v1.Pod
+	for _, pod := range pods {
+		if utils.GetController(pod) == owner {
+			gang = append(gang, pod)
+		}
+	}
+	return gang, nil
+}
+
+func (rb *Rebalancer) markReschedule(ss *arbv1.SchedulingSpec) error {
+	ss = ss.DeepCopy()
+	if ss.Annotations == nil {
+		ss.Annotations = map[string]string{}
+	}
+	ss.Annotations[arbv1.RescheduleAnnotation] = time.Now().UTC().Format(time.RFC3339)
+
+	_, err := rb.arbclients.ArbV1().SchedulingSpecs(ss.Namespace).Update(ss)
+	return err
+}
+
+// inCooldown returns whether the gang was marked to re-schedule recently.
+func inCooldown(ss *arbv1.SchedulingSpec, now time.Time) bool {
+	value, found := ss.Annotations[arbv1.RescheduleAnnotation]
+	if !found {
+		return false
+	}
+
+	markedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return false
+	}
+	return now.Sub(markedAt) < cooldown
+}
+
+// isPlaced returns whether all pods of gang are running, and at least
+// minAvailable of them; only such gangs are re-scheduled, so the gangs being
+// scheduled or finishing are not disrupted.
+func isPlaced(ss *arbv1.SchedulingSpec, pods []*v1.Pod) bool {
+	if len(pods) == 0 || len(pods) < ss.Spec.MinAvailable {
+		return false
+	}
+
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodRunning || len(pod.Spec.NodeName) == 0 || pod.DeletionTimestamp != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isFragmented returns whether the pods are spread over many more nodes than
+// they need; the nodes needed are estimated by the largest node they're on.
+func isFragmented(pods []*v1.Pod, nodes map[string]*v1.Node) bool {
+	var milliCPU, memory, maxMilliCPU, maxMemory int64
+	used := map[string]bool{}
+
+	for _, pod := range pods {
+		for _, c := range pod.Spec.Containers {
+			milliCPU += c.Resources.Requests.Cpu().MilliValue()
+			memory += c.Resources.Requests.Memory().Value()
+		}
+
+		if used[pod.Spec.NodeName] {
+			continue
+		}
+		used[pod.Spec.NodeName] = true
+
+		if node, found := nodes[pod.Spec.NodeName]; found {
+			if cpu := node.Status.Allocatable.Cpu().MilliValue(); cpu > maxMilliCPU {
+				maxMilliCPU = cpu
+			}
+			if mem := node.Status.Allocatable.Memory().Value(); mem > maxMemory {
+				maxMemory = mem
+			}
+		}
+	}
+
+	if maxMilliCPU == 0 || maxMemory == 0 {
+		return false
+	}
+
+	minNodes := int(math.Ceil(math.Max(
+		float64(milliCPU)/float64(maxMilliCPU),
+		float64(memory)/float64(maxMemory))))
+	if minNodes < 1 {
+		minNodes = 1
+	}
+
+	return len(used) > minNodes*spreadThreshold && len(used)-minNodes >= minExtraNodes
+}
+
+// violatesSoftAffinity returns whether more than half of the pods with
+// preferred node affinity are on nodes matching none of their preferences.
+func violatesSoftAffinity(pods []*v1.Pod, nodes map[string]*v1.Node) bool {
+	preferred, violated := 0, 0
+
+	for _, pod := range pods {
+		if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil {
+			continue
+		}
+		terms := pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+		if len(terms) == 0 {
+			continue
+		}
+
+		node, found := nodes[pod.Spec.NodeName]
+		if !found {
+			continue
+		}
+
+		preferred++
+		matched := false
+		for _, term := range terms {
+			if term.Weight > 0 && utils.MatchNodeSelectorTerm(term.Preference, node.Labels) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			violated++
+		}
+	}
+
+	return violated*2 > preferred
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rebalancer
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func buildNode(name, pool string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": pool}},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("8"),
+				v1.ResourceMemory: resource.MustParse("32Gi"),
+			},
+		},
+	}
+}
+
+func buildPod(name, nodeName, cpu string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1"},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []v1.Container{{
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceCPU:    resource.MustParse(cpu),
+						v1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+				},
+			}},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+func TestIsFragmented(t *testing.T) {
+	nodes := map[string]*v1.Node{}
+	for i := 0; i < 8; i++ {
+		name := fmt.Sprintf("n%d", i)
+		nodes[name] = buildNode(name, "default")
+	}
+
+	// 8 pods of 1 cpu fit on one node.
+	var spread, packed []*v1.Pod
+	for i := 0; i < 8; i++ {
+		spread = append(spread, buildPod(fmt.Sprintf("p%d", i), fmt.Sprintf("n%d", i), "1"))
+		packed = append(packed, buildPod(fmt.Sprintf("p%d", i), fmt.Sprintf("n%d", i%2), "1"))
+	}
+
+	if !isFragmented(spread, nodes) {
+		t.Errorf("expected gang spread over 8 nodes fragmented")
+	}
+	if isFragmented(packed, nodes) {
+		t.Errorf("expected gang on 2 nodes not fragmented")
+	}
+}
+
+func TestViolatesSoftAffinity(t *testing.T) {
+	nodes := map[string]*v1.Node{
+		"gpu":     buildNode("gpu", "gpu"),
+		"default": buildNode("default", "default"),
+	}
+
+	preferGPU := &v1.Affinity{
+		NodeAffinity: &v1.NodeAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{{
+				Weight: 1,
+				Preference: v1.NodeSelectorTerm{
+					MatchExpressions: []v1.NodeSelectorRequirement{{
+						Key:      "pool",
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{"gpu"},
+					}},
+				},
+			}},
+		},
+	}
+
+	var pods []*v1.Pod
+	for i, nodeName := range []string{"gpu", "default", "default"} {
+		pod := buildPod(fmt.Sprintf("p%d", i), nodeName, "1")
+		pod.Spec.Affinity = preferGPU
+		pods = append(pods, pod)
+	}
+
+	if !violatesSoftAffinity(pods, nodes) {
+		t.Errorf("expected most pods violating preferred affinity")
+	}
+	if violatesSoftAffinity(pods[:2], nodes) {
+		t.Errorf("expected half of pods not violating preferred affinity")
+	}
+}
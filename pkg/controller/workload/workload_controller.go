@@ -0,0 +1,386 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	appsinformers "k8s.io/client-go/informers/apps/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/controller/workqueue"
+)
+
+const (
+	// GangAnnotation enables gang-scheduling of the workload if it's "true".
+	GangAnnotation = "arbitrator/gang"
+
+	// MinAvailableAnnotation is the min available pods of the workload's gang;
+	// default to the replicas of the workload.
+	MinAvailableAnnotation = "arbitrator/min-available"
+)
+
+var (
+	deploymentKind  = appsv1.SchemeGroupVersion.WithKind("Deployment")
+	replicaSetKind  = appsv1.SchemeGroupVersion.WithKind("ReplicaSet")
+	statefulSetKind = appsv1.SchemeGroupVersion.WithKind("StatefulSet")
+)
+
+// Controller creates SchedulingSpec for the annotated Deployments and StatefulSets,
+// and sets their pods' schedulerName, so existing workloads can be gang-scheduled.
+//
+// The scheduler groups pods into job by their controller, so the SchedulingSpec of
+// Deployment is created per ReplicaSet, and the SchedulingSpec of StatefulSet is
+// created for the StatefulSet itself; the SchedulingSpec is controlled by the same
+// object, so it's garbage collected together.
+type Controller struct {
+	clients       *kubernetes.Clientset
+	arbclients    *clientset.Clientset
+	schedulerName string
+
+	deploymentInformer  appsinformers.DeploymentInformer
+	replicaSetInformer  appsinformers.ReplicaSetInformer
+	statefulSetInformer appsinformers.StatefulSetInformer
+
+	// queue of workload keys that need to sync up
+	queue workqueue.RateLimitingInterface
+}
+
+// workloadKey is the key of workload in queue.
+type workloadKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// NewWorkloadController creates a new workload Controller.
+func NewWorkloadController(config *rest.Config, schedulerName string) *Controller {
+	wc := &Controller{
+		clients:       kubernetes.NewForConfigOrDie(config),
+		arbclients:    clientset.NewForConfigOrDie(config),
+		schedulerName: schedulerName,
+		queue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "workload"),
+	}
+
+	informerFactory := informers.NewSharedInformerFactory(wc.clients, 0)
+
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc: wc.enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			wc.enqueue(newObj)
+		},
+	}
+
+	wc.deploymentInformer = informerFactory.Apps().V1().Deployments()
+	wc.deploymentInformer.Informer().AddEventHandler(handler)
+
+	wc.replicaSetInformer = informerFactory.Apps().V1().ReplicaSets()
+	wc.replicaSetInformer.Informer().AddEventHandler(handler)
+
+	wc.statefulSetInformer = informerFactory.Apps().V1().StatefulSets()
+	wc.statefulSetInformer.Informer().AddEventHandler(handler)
+
+	return wc
+}
+
+// Run starts workload Controller.
+func (wc *Controller) Run(stopCh <-chan struct{}) {
+	go wc.deploymentInformer.Informer().Run(stopCh)
+	go wc.replicaSetInformer.Informer().Run(stopCh)
+	go wc.statefulSetInformer.Informer().Run(stopCh)
+
+	cache.WaitForCacheSync(stopCh,
+		wc.deploymentInformer.Informer().HasSynced,
+		wc.replicaSetInformer.Informer().HasSynced,
+		wc.statefulSetInformer.Informer().HasSynced)
+
+	go wait.Until(wc.worker, time.Second, stopCh)
+
+	go func() {
+		<-stopCh
+		wc.queue.ShutDown()
+	}()
+}
+
+func (wc *Controller) enqueue(obj interface{}) {
+	var kind string
+	switch obj.(type) {
+	case *appsv1.Deployment:
+		kind = deploymentKind.Kind
+	case *appsv1.ReplicaSet:
+		kind = replicaSetKind.Kind
+	case *appsv1.StatefulSet:
+		kind = statefulSetKind.Kind
+	default:
+		glog.Errorf("Un-supported type of %v", obj)
+		return
+	}
+
+	acc, err := meta.Accessor(obj)
+	if err != nil {
+		glog.Errorf("Failed to get meta of %v: %v", obj, err)
+		return
+	}
+
+	wc.queue.Add(workloadKey{
+		kind:      kind,
+		namespace: acc.GetNamespace(),
+		name:      acc.GetName(),
+	})
+}
+
+func (wc *Controller) worker() {
+	for wc.processNextItem() {
+	}
+}
+
+func (wc *Controller) processNextItem() bool {
+	item, shutdown := wc.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer wc.queue.Done(item)
+
+	key := item.(workloadKey)
+	if err := wc.sync(key); err != nil {
+		glog.Errorf("Failed to sync %v %v/%v (retried %d times): %v",
+			key.kind, key.namespace, key.name, wc.queue.NumRequeues(item), err)
+		wc.queue.AddRateLimited(item)
+		return true
+	}
+
+	wc.queue.Forget(item)
+	return true
+}
+
+// sync syncs the latest workload in cache; it's ignored if not found, as the
+// SchedulingSpec is garbage collected with its owner.
+func (wc *Controller) sync(key workloadKey) error {
+	var err error
+	switch key.kind {
+	case deploymentKind.Kind:
+		var d *appsv1.Deployment
+		if d, err = wc.deploymentInformer.Lister().Deployments(key.namespace).Get(key.name); err == nil {
+			return wc.syncDeployment(d)
+		}
+	case replicaSetKind.Kind:
+		var rs *appsv1.ReplicaSet
+		if rs, err = wc.replicaSetInformer.Lister().ReplicaSets(key.namespace).Get(key.name); err == nil {
+			return wc.syncReplicaSet(rs)
+		}
+	case statefulSetKind.Kind:
+		var sts *appsv1.StatefulSet
+		if sts, err = wc.statefulSetInformer.Lister().StatefulSets(key.namespace).Get(key.name); err == nil {
+			return wc.syncStatefulSet(sts)
+		}
+	}
+
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (wc *Controller) syncDeployment(d *appsv1.Deployment) error {
+	if isGang(d.Annotations) && d.Spec.Template.Spec.SchedulerName != wc.schedulerName {
+		patch := schedulerNamePatch(wc.schedulerName)
+		if _, err := wc.clients.AppsV1().Deployments(d.Namespace).Patch(
+			d.Name, types.StrategicMergePatchType, patch); err != nil {
+			return err
+		}
+	}
+
+	// Sync ReplicaSets of Deployment, as its annotations may be changed.
+	rss, err := wc.replicaSetInformer.Lister().ReplicaSets(d.Namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	for _, rs := range rss {
+		if metav1.IsControlledBy(rs, d) {
+			if err := wc.syncReplicaSet(rs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (wc *Controller) syncReplicaSet(rs *appsv1.ReplicaSet) error {
+	var d *appsv1.Deployment
+	if ref := metav1.GetControllerOf(rs); ref != nil && ref.Kind == "Deployment" {
+		deploy, err := wc.deploymentInformer.Lister().Deployments(rs.Namespace).Get(ref.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if deploy != nil && deploy.UID == ref.UID {
+			d = deploy
+		}
+	}
+
+	if d == nil || !isGang(d.Annotations) {
+		return wc.syncSchedulingSpec(rs, replicaSetKind, false, 0)
+	}
+
+	min, gang := replicaSetMinAvailable(d, rs)
+	return wc.syncSchedulingSpec(rs, replicaSetKind, gang, min)
+}
+
+// replicaSetMinAvailable returns the minAvailable of rs controlled by the gang
+// Deployment d, and whether rs is a gang. The minAvailable is capped by the
+// replicas of rs, as the new ReplicaSet is scaled up and the old ones are
+// scaled down in a rolling update; rs scaled to 0 is not a gang.
+func replicaSetMinAvailable(d *appsv1.Deployment, rs *appsv1.ReplicaSet) (int, bool) {
+	replicas := 1
+	if rs.Spec.Replicas != nil {
+		replicas = int(*rs.Spec.Replicas)
+	}
+	if replicas == 0 {
+		return 0, false
+	}
+
+	min := minAvailable(d.Annotations, d.Spec.Replicas)
+	if min > replicas {
+		min = replicas
+	}
+	return min, true
+}
+
+func (wc *Controller) syncStatefulSet(sts *appsv1.StatefulSet) error {
+	if !isGang(sts.Annotations) {
+		return wc.syncSchedulingSpec(sts, statefulSetKind, false, 0)
+	}
+
+	if sts.Spec.Template.Spec.SchedulerName != wc.schedulerName {
+		patch := schedulerNamePatch(wc.schedulerName)
+		if _, err := wc.clients.AppsV1().StatefulSets(sts.Namespace).Patch(
+			sts.Name, types.StrategicMergePatchType, patch); err != nil {
+			return err
+		}
+	}
+
+	return wc.syncSchedulingSpec(sts, statefulSetKind, true,
+		minAvailable(sts.Annotations, sts.Spec.Replicas))
+}
+
+// syncSchedulingSpec makes sure the SchedulingSpec controlled by owner exists with
+// the expected MinAvailable if gang is true, or is deleted if gang is false.
+func (wc *Controller) syncSchedulingSpec(owner metav1.Object, kind schema.GroupVersionKind, gang bool, min int) error {
+	ssClient := wc.arbclients.ArbV1().SchedulingSpecs(owner.GetNamespace())
+
+	ss, err := ssClient.Get(owner.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		ss = nil
+	}
+
+	if ss != nil && !metav1.IsControlledBy(ss, owner) {
+		glog.V(3).Infof("SchedulingSpec <%v/%v> is not controlled by %v, ignore it",
+			ss.Namespace, ss.Name, kind.Kind)
+		return nil
+	}
+
+	if !gang {
+		if ss == nil {
+			return nil
+		}
+		glog.V(3).Infof("Delete SchedulingSpec <%v/%v> as %v is not gang or scaled to 0",
+			ss.Namespace, ss.Name, kind.Kind)
+		err := ssClient.Delete(ss.Name, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	if ss == nil {
+		glog.V(3).Infof("Create SchedulingSpec <%v/%v> for %v, minAvailable %v",
+			owner.GetNamespace(), owner.GetName(), kind.Kind, min)
+		_, err := ssClient.Create(&arbv1.SchedulingSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      owner.GetName(),
+				Namespace: owner.GetNamespace(),
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(owner, kind),
+				},
+			},
+			Spec: arbv1.SchedulingSpecTemplate{
+				MinAvailable: min,
+			},
+		})
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+		return nil
+	}
+
+	if ss.Spec.MinAvailable != min {
+		glog.V(3).Infof("Update minAvailable of SchedulingSpec <%v/%v>: %v -> %v",
+			ss.Namespace, ss.Name, ss.Spec.MinAvailable, min)
+		ss.Spec.MinAvailable = min
+		if _, err := ssClient.Update(ss); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isGang(annotations map[string]string) bool {
+	return annotations[GangAnnotation] == "true"
+}
+
+// minAvailable returns the value of MinAvailableAnnotation, default to replicas.
+func minAvailable(annotations map[string]string, replicas *int32) int {
+	if value, found := annotations[MinAvailableAnnotation]; found {
+		if min, err := strconv.Atoi(value); err == nil && min >= 0 {
+			return min
+		}
+		glog.Warningf("Invalid value %q of annotation %s, use replicas instead",
+			value, MinAvailableAnnotation)
+	}
+
+	if replicas == nil {
+		return 1
+	}
+
+	return int(*replicas)
+}
+
+func schedulerNamePatch(schedulerName string) []byte {
+	return []byte(fmt.Sprintf(`{"spec":{"template":{"spec":{"schedulerName":%q}}}}`, schedulerName))
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workload
+
+import (
+	"encoding/json"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestMinAvailable(t *testing.T) {
+	replicas := int32(4)
+
+	tests := []struct {
+		annotations map[string]string
+		replicas    *int32
+		expected    int
+	}{
+		{replicas: &replicas, expected: 4},
+		{replicas: nil, expected: 1},
+		{annotations: map[string]string{MinAvailableAnnotation: "2"}, replicas: &replicas, expected: 2},
+		{annotations: map[string]string{MinAvailableAnnotation: "0"}, replicas: &replicas, expected: 0},
+		{annotations: map[string]string{MinAvailableAnnotation: "-1"}, replicas: &replicas, expected: 4},
+		{annotations: map[string]string{MinAvailableAnnotation: "two"}, replicas: &replicas, expected: 4},
+	}
+
+	for i, test := range tests {
+		if min := minAvailable(test.annotations, test.replicas); min != test.expected {
+			t.Errorf("case %d: expected minAvailable %d, got %d", i, test.expected, min)
+		}
+	}
+}
+
+func TestIsGang(t *testing.T) {
+	if !isGang(map[string]string{GangAnnotation: "true"}) {
+		t.Errorf("expected gang annotated with true")
+	}
+	if isGang(map[string]string{GangAnnotation: "yes"}) || isGang(nil) {
+		t.Errorf("expected not gang without annotation true")
+	}
+}
+
+func TestSchedulerNamePatch(t *testing.T) {
+	var patch struct {
+		Spec struct {
+			Template struct {
+				Spec struct {
+					SchedulerName string `json:"schedulerName"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(schedulerNamePatch("kar-scheduler"), &patch); err != nil {
+		t.Fatal(err)
+	}
+	if name := patch.Spec.Template.Spec.SchedulerName; name != "kar-scheduler" {
+		t.Errorf("expected schedulerName kar-scheduler in patch, got %q", name)
+	}
+}
+
+func TestReplicaSetMinAvailable(t *testing.T) {
+	int32Ptr := func(i int32) *int32 { return &i }
+
+	// A rolling update of a gang Deployment of 4 replicas, with maxSurge 1.
+	d := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(4)},
+	}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		replicas    *int32
+		expected    int
+		gang        bool
+	}{
+		{name: "new ReplicaSet surged", replicas: int32Ptr(1), expected: 1, gang: true},
+		{name: "old ReplicaSet not scaled down", replicas: int32Ptr(4), expected: 4, gang: true},
+		{name: "old ReplicaSet scaled down", replicas: int32Ptr(3), expected: 3, gang: true},
+		{name: "old ReplicaSet scaled to 0", replicas: int32Ptr(0), gang: false},
+		{name: "replicas not set", replicas: nil, expected: 1, gang: true},
+		{
+			name:        "minAvailable below replicas",
+			annotations: map[string]string{MinAvailableAnnotation: "2"},
+			replicas:    int32Ptr(3),
+			expected:    2,
+			gang:        true,
+		},
+	}
+
+	for _, test := range tests {
+		d.Annotations = test.annotations
+		rs := &appsv1.ReplicaSet{Spec: appsv1.ReplicaSetSpec{Replicas: test.replicas}}
+		min, gang := replicaSetMinAvailable(d, rs)
+		if gang != test.gang || (gang && min != test.expected) {
+			t.Errorf("case <%s>: expected minAvailable %d (gang %v), got %d (gang %v)",
+				test.name, test.expected, test.gang, min, gang)
+		}
+	}
+}
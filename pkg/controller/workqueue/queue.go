@@ -0,0 +1,196 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package workqueue is a simplified version of client-go's workqueue: the
+// items are de-duplicated, an item is never processed by multiple workers at
+// the same time, and the failed items are re-queued with rate limit.
+package workqueue
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// metrics are exposed by expvar, e.g. "workqueue": {"queuejob": {"depth": 1, ...}}.
+var metrics = expvar.NewMap("workqueue")
+
+// RateLimitingInterface is a queue which re-queues the failed items with rate limit.
+type RateLimitingInterface interface {
+	// Add marks item as needing processing.
+	Add(item interface{})
+	// Len returns the number of items waiting for processing.
+	Len() int
+	// Get blocks until it can return an item to be processed; shutdown is true
+	// if the queue is shut down. Done must be called after the item processed.
+	Get() (item interface{}, shutdown bool)
+	// Done marks item as done processing; it's re-queued if it was marked
+	// dirty again during processing.
+	Done(item interface{})
+	// ShutDown makes the queue ignore all new items, and Get returns shutdown
+	// after the queued items drained.
+	ShutDown()
+
+	// AddAfter adds item after the delay.
+	AddAfter(item interface{}, duration time.Duration)
+	// AddRateLimited adds item after the rate limiter says it's ok.
+	AddRateLimited(item interface{})
+	// Forget indicates that the item is finished retrying, so the rate limiter
+	// stops tracking it.
+	Forget(item interface{})
+	// NumRequeues returns how many times the item was re-queued.
+	NumRequeues(item interface{}) int
+}
+
+type queue struct {
+	cond *sync.Cond
+
+	// The items in order of processing; an item is in queue if it's in dirty
+	// and not in processing.
+	queue []interface{}
+	// The items that need to be processed.
+	dirty map[interface{}]struct{}
+	// The items being processed.
+	processing map[interface{}]struct{}
+
+	shuttingDown bool
+
+	rateLimiter RateLimiter
+	metrics     *expvar.Map
+}
+
+// NewNamedRateLimitingQueue creates a queue with the rate limiter; its metrics
+// are exposed by name.
+func NewNamedRateLimitingQueue(rateLimiter RateLimiter, name string) RateLimitingInterface {
+	q := &queue{
+		cond:        sync.NewCond(&sync.Mutex{}),
+		dirty:       map[interface{}]struct{}{},
+		processing:  map[interface{}]struct{}{},
+		rateLimiter: rateLimiter,
+		metrics:     new(expvar.Map).Init(),
+	}
+
+	metrics.Set(name, q.metrics)
+
+	return q
+}
+
+func (q *queue) Add(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	if q.shuttingDown {
+		return
+	}
+	if _, found := q.dirty[item]; found {
+		return
+	}
+
+	q.metrics.Add("adds", 1)
+
+	q.dirty[item] = struct{}{}
+	if _, found := q.processing[item]; found {
+		return
+	}
+
+	q.queue = append(q.queue, item)
+	q.metrics.Add("depth", 1)
+	q.cond.Signal()
+}
+
+func (q *queue) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	return len(q.queue)
+}
+
+func (q *queue) Get() (interface{}, bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	for len(q.queue) == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	if len(q.queue) == 0 {
+		// We must be shutting down.
+		return nil, true
+	}
+
+	item := q.queue[0]
+	q.queue = q.queue[1:]
+	q.metrics.Add("depth", -1)
+
+	q.processing[item] = struct{}{}
+	delete(q.dirty, item)
+
+	return item, false
+}
+
+func (q *queue) Done(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	delete(q.processing, item)
+	if _, found := q.dirty[item]; found {
+		q.queue = append(q.queue, item)
+		q.metrics.Add("depth", 1)
+		q.cond.Signal()
+	}
+}
+
+func (q *queue) ShutDown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+func (q *queue) isShuttingDown() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+
+	return q.shuttingDown
+}
+
+func (q *queue) AddAfter(item interface{}, duration time.Duration) {
+	if q.isShuttingDown() {
+		return
+	}
+
+	if duration <= 0 {
+		q.Add(item)
+		return
+	}
+
+	time.AfterFunc(duration, func() {
+		q.Add(item)
+	})
+}
+
+func (q *queue) AddRateLimited(item interface{}) {
+	q.metrics.Add("retries", 1)
+	q.AddAfter(item, q.rateLimiter.When(item))
+}
+
+func (q *queue) Forget(item interface{}) {
+	q.rateLimiter.Forget(item)
+}
+
+func (q *queue) NumRequeues(item interface{}) int {
+	return q.rateLimiter.NumRequeues(item)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workqueue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueDeduplicate(t *testing.T) {
+	q := NewNamedRateLimitingQueue(DefaultControllerRateLimiter(), "test-deduplicate")
+
+	q.Add("a")
+	q.Add("b")
+	q.Add("a")
+	if q.Len() != 2 {
+		t.Fatalf("expected 2 items in queue, got %d", q.Len())
+	}
+
+	item, _ := q.Get()
+	if item != "a" {
+		t.Fatalf("expected item <a>, got <%v>", item)
+	}
+
+	// The item being processed is re-queued after done.
+	q.Add("a")
+	if q.Len() != 1 {
+		t.Fatalf("expected 1 item in queue during processing, got %d", q.Len())
+	}
+	q.Done("a")
+	if q.Len() != 2 {
+		t.Fatalf("expected 2 items in queue after done, got %d", q.Len())
+	}
+
+	q.ShutDown()
+	for i := 0; i < 2; i++ {
+		if _, shutdown := q.Get(); shutdown {
+			t.Fatalf("expected queued items drained before shutdown")
+		}
+	}
+	if _, shutdown := q.Get(); !shutdown {
+		t.Fatalf("expected queue shutdown")
+	}
+}
+
+func TestItemExponentialFailureRateLimiter(t *testing.T) {
+	limiter := NewItemExponentialFailureRateLimiter(time.Millisecond, 4*time.Millisecond)
+
+	expected := []time.Duration{
+		1 * time.Millisecond,
+		2 * time.Millisecond,
+		4 * time.Millisecond,
+		4 * time.Millisecond,
+	}
+	for i, e := range expected {
+		if d := limiter.When("a"); d != e {
+			t.Errorf("case %d: expected delay %v, got %v", i, e, d)
+		}
+	}
+
+	if n := limiter.NumRequeues("a"); n != len(expected) {
+		t.Errorf("expected %d requeues, got %d", len(expected), n)
+	}
+
+	limiter.Forget("a")
+	if d := limiter.When("a"); d != time.Millisecond {
+		t.Errorf("expected delay reset to %v after forget, got %v", time.Millisecond, d)
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workqueue
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter decides how long an item should wait before it's re-queued.
+type RateLimiter interface {
+	// When returns how long the item should wait.
+	When(item interface{}) time.Duration
+	// Forget indicates that the item is finished retrying.
+	Forget(item interface{})
+	// NumRequeues returns how many times the item was re-queued.
+	NumRequeues(item interface{}) int
+}
+
+// DefaultControllerRateLimiter is the rate limiter of controllers: the
+// exponential per-item backoff, and the overall 10 qps with 100 burst.
+func DefaultControllerRateLimiter() RateLimiter {
+	return NewMaxOfRateLimiter(
+		NewItemExponentialFailureRateLimiter(5*time.Millisecond, 1000*time.Second),
+		NewBucketRateLimiter(10, 100),
+	)
+}
+
+// itemExponentialFailureRateLimiter delays baseDelay*2^<num-failures> for
+// each item, and the delay is capped by maxDelay.
+type itemExponentialFailureRateLimiter struct {
+	sync.Mutex
+	failures map[interface{}]int
+
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+// NewItemExponentialFailureRateLimiter creates the per-item exponential rate limiter.
+func NewItemExponentialFailureRateLimiter(baseDelay, maxDelay time.Duration) RateLimiter {
+	return &itemExponentialFailureRateLimiter{
+		failures:  map[interface{}]int{},
+		baseDelay: baseDelay,
+		maxDelay:  maxDelay,
+	}
+}
+
+func (r *itemExponentialFailureRateLimiter) When(item interface{}) time.Duration {
+	r.Lock()
+	defer r.Unlock()
+
+	exp := r.failures[item]
+	r.failures[item]++
+
+	backoff := float64(r.baseDelay.Nanoseconds()) * math.Pow(2, float64(exp))
+	if backoff > math.MaxInt64 || time.Duration(backoff) > r.maxDelay {
+		return r.maxDelay
+	}
+
+	return time.Duration(backoff)
+}
+
+func (r *itemExponentialFailureRateLimiter) Forget(item interface{}) {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.failures, item)
+}
+
+func (r *itemExponentialFailureRateLimiter) NumRequeues(item interface{}) int {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.failures[item]
+}
+
+// bucketRateLimiter is the overall token bucket rate limiter; the token is
+// reserved by When, so the delay is the time to wait for the token.
+type bucketRateLimiter struct {
+	sync.Mutex
+	qps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewBucketRateLimiter creates the overall token bucket rate limiter.
+func NewBucketRateLimiter(qps float64, burst int) RateLimiter {
+	return &bucketRateLimiter{
+		qps:    qps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (r *bucketRateLimiter) When(item interface{}) time.Duration {
+	r.Lock()
+	defer r.Unlock()
+
+	now := time.Now()
+	r.tokens = math.Min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.qps)
+	r.last = now
+
+	r.tokens--
+	if r.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-r.tokens / r.qps * float64(time.Second))
+}
+
+func (r *bucketRateLimiter) Forget(item interface{}) {
+}
+
+func (r *bucketRateLimiter) NumRequeues(item interface{}) int {
+	return 0
+}
+
+// maxOfRateLimiter returns the worst case of its rate limiters.
+type maxOfRateLimiter struct {
+	limiters []RateLimiter
+}
+
+// NewMaxOfRateLimiter creates the rate limiter which returns the longest delay
+// of limiters.
+func NewMaxOfRateLimiter(limiters ...RateLimiter) RateLimiter {
+	return &maxOfRateLimiter{limiters: limiters}
+}
+
+func (r *maxOfRateLimiter) When(item interface{}) time.Duration {
+	ret := time.Duration(0)
+	for _, limiter := range r.limiters {
+		if curr := limiter.When(item); curr > ret {
+			ret = curr
+		}
+	}
+
+	return ret
+}
+
+func (r *maxOfRateLimiter) Forget(item interface{}) {
+	for _, limiter := range r.limiters {
+		limiter.Forget(item)
+	}
+}
+
+func (r *maxOfRateLimiter) NumRequeues(item interface{}) int {
+	ret := 0
+	for _, limiter := range r.limiters {
+		if curr := limiter.NumRequeues(item); curr > ret {
+			ret = curr
+		}
+	}
+
+	return ret
+}
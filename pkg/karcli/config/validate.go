@@ -0,0 +1,61 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler"
+)
+
+type validateFlags struct {
+	SchedulerConfig string
+}
+
+var validateConfigFlags = &validateFlags{}
+
+func InitValidateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&validateConfigFlags.SchedulerConfig, "scheduler-config", "", "",
+		"the file of actions and plugins passed to kar-scheduler by its --scheduler-config")
+}
+
+// ValidateConfig checks the scheduler config against the actions and plugins
+// of kar-scheduler, and prints all its problems.
+func ValidateConfig() error {
+	if validateConfigFlags.SchedulerConfig == "" {
+		return fmt.Errorf("--scheduler-config is required")
+	}
+
+	data, err := ioutil.ReadFile(validateConfigFlags.SchedulerConfig)
+	if err != nil {
+		return err
+	}
+
+	errs := scheduler.CheckConfig(data)
+	for _, err := range errs {
+		fmt.Printf("%s: %v\n", validateConfigFlags.SchedulerConfig, err)
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("%d problems found in %s", len(errs), validateConfigFlags.SchedulerConfig)
+	}
+
+	fmt.Printf("%s is valid\n", validateConfigFlags.SchedulerConfig)
+	return nil
+}
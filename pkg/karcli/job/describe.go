@@ -0,0 +1,266 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+// maxEvents is the number of the latest events of job shown.
+const maxEvents = 20
+
+// maxReasonNodes is the number of nodes shown for each reason.
+const maxReasonNodes = 5
+
+type describeFlags struct {
+	commonFlags
+
+	Namespace        string
+	SchedulerService string
+}
+
+var describeJobFlags = &describeFlags{}
+
+func InitDescribeFlags(cmd *cobra.Command) {
+	initFlags(cmd, &describeJobFlags.commonFlags)
+
+	cmd.Flags().StringVarP(&describeJobFlags.Namespace, "namespace", "", "default", "the namespace of job")
+	cmd.Flags().StringVarP(&describeJobFlags.SchedulerService, "scheduler-service", "", "kube-system/kar-scheduler:8080",
+		"the service of kar-scheduler as <namespace>/<name>:<port>, whose unschedulable jobs are accessed by the proxy of apiserver")
+}
+
+// unschedulableJob is a job which can not be scheduled in the last session,
+// reported by the /debug/unschedulable endpoint of kar-scheduler.
+type unschedulableJob struct {
+	UID       string `json:"uid"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Task      string `json:"task,omitempty"`
+	Message   string `json:"message"`
+
+	FailedNodes map[string][]string `json:"failedNodes"`
+}
+
+// reasonNodes is the nodes which can not fit a task by the same reason.
+type reasonNodes struct {
+	Reason string
+	Nodes  []string
+}
+
+// DescribeJob shows the conditions of the SchedulingSpec of job, its tasks by
+// status, why its pending tasks are not scheduled in the last session of
+// kar-scheduler by node, and its latest events.
+func DescribeJob(name string) error {
+	config, err := buildConfig(describeJobFlags.Master, describeJobFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	kubeClient := kubernetes.NewForConfigOrDie(config)
+	queueClient := clientset.NewForConfigOrDie(config)
+	ns := describeJobFlags.Namespace
+
+	ss, err := queueClient.ArbV1().SchedulingSpecs(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	jobID := api.SchedulingSpecJobID(ss)
+
+	pods, err := kubeClient.CoreV1().Pods(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var tasks []*api.TaskInfo
+	uids := map[string]bool{string(ss.UID): true}
+	for i := range pods.Items {
+		if task := api.NewTaskInfo(&pods.Items[i]); task.Job == jobID {
+			tasks = append(tasks, task)
+			uids[string(task.UID)] = true
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Name < tasks[j].Name
+	})
+
+	queue := utils.SchedulingSpecQueue(ss)
+	if len(queue) == 0 {
+		queue = "-"
+	}
+	fmt.Printf("Name:          %s\n", ss.Name)
+	fmt.Printf("Namespace:     %s\n", ss.Namespace)
+	fmt.Printf("Queue:         %s\n", queue)
+	fmt.Printf("Min Available: %d\n", ss.Spec.MinAvailable)
+
+	fmt.Printf("Conditions:\n")
+	for _, c := range ss.Status.Conditions {
+		fmt.Printf("  %s=%s\t%s\t%s (%s)\n", c.Type, c.Status, c.Reason, c.Message,
+			c.LastTransitionTime.Format("2006-01-02T15:04:05Z07:00"))
+	}
+
+	var pending []string
+	started := 0
+	counts := map[api.TaskStatus]int{}
+	for _, task := range tasks {
+		counts[task.Status]++
+		if task.Status == api.Pending {
+			pending = append(pending, task.Name)
+		} else if api.OccupiedResources(task.Status) || task.Status == api.Succeeded {
+			started++
+		}
+	}
+	var statuses []string
+	for status, count := range counts {
+		statuses = append(statuses, fmt.Sprintf("%d %v", count, status))
+	}
+	sort.Strings(statuses)
+	fmt.Printf("Tasks:         %d (%s)\n", len(tasks), strings.Join(statuses, ", "))
+	if missing := ss.Spec.MinAvailable - started; missing > 0 {
+		fmt.Printf("Gang:          %d more task(s) to start, pending: %s\n", missing, strings.Join(pending, ", "))
+	}
+
+	if len(pending) != 0 {
+		fmt.Printf("Unschedulable:\n")
+		describeUnschedulable(kubeClient, describeJobFlags.SchedulerService, string(jobID))
+	}
+
+	events, err := kubeClient.CoreV1().Events(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Events:\n")
+	for _, e := range jobEvents(events.Items, uids) {
+		fmt.Printf("  %s\t%s\t%s/%s\t%s\n", e.LastTimestamp.Format("2006-01-02T15:04:05Z07:00"),
+			e.Reason, strings.ToLower(e.InvolvedObject.Kind), e.InvolvedObject.Name, e.Message)
+	}
+
+	return nil
+}
+
+// describeUnschedulable shows why the job is not scheduled in the last
+// session of kar-scheduler, by the nodes of each reason.
+func describeUnschedulable(kubeClient *kubernetes.Clientset, service, jobID string) {
+	jobs, err := fetchUnschedulableJobs(kubeClient, service)
+	if err != nil {
+		fmt.Printf("  <failed to get unschedulable jobs of kar-scheduler: %v>\n", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if job.UID != jobID {
+			continue
+		}
+
+		fmt.Printf("  %s\n", job.Message)
+		if len(job.Task) != 0 {
+			fmt.Printf("  Nodes rejecting task %s:\n", job.Task)
+		}
+		for _, rn := range breakdownReasons(job.FailedNodes) {
+			nodes := rn.Nodes
+			more := ""
+			if len(nodes) > maxReasonNodes {
+				more = fmt.Sprintf(" and %d more", len(nodes)-maxReasonNodes)
+				nodes = nodes[:maxReasonNodes]
+			}
+			fmt.Printf("    %s: %s%s\n", rn.Reason, strings.Join(nodes, ", "), more)
+		}
+		return
+	}
+
+	fmt.Printf("  <not reported by the last session of kar-scheduler>\n")
+}
+
+func fetchUnschedulableJobs(kubeClient *kubernetes.Clientset, service string) ([]*unschedulableJob, error) {
+	parts := strings.SplitN(service, "/", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return nil, fmt.Errorf("invalid scheduler service %q, expected <namespace>/<name>:<port>", service)
+	}
+
+	data, err := kubeClient.CoreV1().RESTClient().Get().
+		AbsPath("/api/v1/namespaces", parts[0], "services", parts[1], "proxy/debug/unschedulable").
+		DoRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*unschedulableJob
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// breakdownReasons groups the nodes by the reasons they can not fit the task,
+// the reason of most nodes first.
+func breakdownReasons(failedNodes map[string][]string) []*reasonNodes {
+	byReason := map[string]*reasonNodes{}
+	for node, reasons := range failedNodes {
+		for _, reason := range reasons {
+			rn, found := byReason[reason]
+			if !found {
+				rn = &reasonNodes{Reason: reason}
+				byReason[reason] = rn
+			}
+			rn.Nodes = append(rn.Nodes, node)
+		}
+	}
+
+	result := make([]*reasonNodes, 0, len(byReason))
+	for _, rn := range byReason {
+		sort.Strings(rn.Nodes)
+		result = append(result, rn)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].Nodes) != len(result[j].Nodes) {
+			return len(result[i].Nodes) > len(result[j].Nodes)
+		}
+		return result[i].Reason < result[j].Reason
+	})
+
+	return result
+}
+
+// jobEvents returns the latest events of the objects of uids, the oldest
+// first.
+func jobEvents(events []v1.Event, uids map[string]bool) []*v1.Event {
+	var result []*v1.Event
+	for i := range events {
+		if uids[string(events[i].InvolvedObject.UID)] {
+			result = append(result, &events[i])
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastTimestamp.Before(&result[j].LastTimestamp)
+	})
+	if len(result) > maxEvents {
+		result = result[len(result)-maxEvents:]
+	}
+	return result
+}
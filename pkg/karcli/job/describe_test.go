@@ -0,0 +1,63 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestBreakdownReasons(t *testing.T) {
+	failedNodes := map[string][]string{
+		"n1": {"Insufficient cpu"},
+		"n2": {"Insufficient cpu", "Insufficient memory"},
+		"n3": {"node(s) didn't match node selector"},
+		"n4": {"Insufficient cpu"},
+	}
+
+	rns := breakdownReasons(failedNodes)
+	if len(rns) != 3 {
+		t.Fatalf("expected 3 reasons, got %d", len(rns))
+	}
+	if rns[0].Reason != "Insufficient cpu" || len(rns[0].Nodes) != 3 || rns[0].Nodes[0] != "n1" {
+		t.Errorf("expected Insufficient cpu of n1, n2 and n4 first, got %+v", rns[0])
+	}
+	if rns[1].Reason != "Insufficient memory" || rns[2].Reason != "node(s) didn't match node selector" {
+		t.Errorf("expected the reasons of one node by name, got %+v and %+v", rns[1], rns[2])
+	}
+}
+
+func TestJobEvents(t *testing.T) {
+	now := time.Now()
+	event := func(uid string, age time.Duration) v1.Event {
+		return v1.Event{
+			InvolvedObject: v1.ObjectReference{UID: types.UID("uid-" + uid)},
+			LastTimestamp:  metav1.NewTime(now.Add(-age)),
+			Reason:         uid,
+		}
+	}
+
+	events := []v1.Event{event("p1", time.Minute), event("other", 0), event("ss", 2*time.Minute)}
+	result := jobEvents(events, map[string]bool{"uid-ss": true, "uid-p1": true})
+	if len(result) != 2 || result[0].Reason != "ss" || result[1].Reason != "p1" {
+		t.Errorf("expected the events of ss and p1, the oldest first, got %v", result)
+	}
+}
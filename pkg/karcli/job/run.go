@@ -65,32 +65,44 @@ func RunJob() error {
 		return err
 	}
 
-	qj := &arbv1.QueueJob{
+	qj := buildQueueJob(launchJobFlags, req)
+
+	if _, err := queueClient.ArbV1().QueueJobs(launchJobFlags.Namespace).Create(qj); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// buildQueueJob builds the QueueJob of a single task by rf, whose pods
+// request req.
+func buildQueueJob(rf *runFlags, req v1.ResourceList) *arbv1.QueueJob {
+	return &arbv1.QueueJob{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      launchJobFlags.Name,
-			Namespace: launchJobFlags.Namespace,
+			Name:      rf.Name,
+			Namespace: rf.Namespace,
 		},
 		Spec: arbv1.QueueJobSpec{
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{
-					queueJobName: launchJobFlags.Name,
+					queueJobName: rf.Name,
 				},
 			},
-			Replicas: int32(launchJobFlags.Replicas),
+			Replicas: int32(rf.Replicas),
 			SchedSpec: arbv1.SchedulingSpecTemplate{
-				MinAvailable: launchJobFlags.MinAvailable,
+				MinAvailable: rf.MinAvailable,
 			},
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{queueJobName: launchJobFlags.Name},
+					Labels: map[string]string{queueJobName: rf.Name},
 				},
 				Spec: v1.PodSpec{
-					SchedulerName: launchJobFlags.SchedulerName,
+					SchedulerName: rf.SchedulerName,
 					RestartPolicy: v1.RestartPolicyNever,
 					Containers: []v1.Container{
 						{
-							Image:           launchJobFlags.Image,
-							Name:            launchJobFlags.Name,
+							Image:           rf.Image,
+							Name:            rf.Name,
 							ImagePullPolicy: v1.PullIfNotPresent,
 							Resources: v1.ResourceRequirements{
 								Requests: req,
@@ -101,10 +113,4 @@ func RunJob() error {
 			},
 		},
 	}
-
-	if _, err := queueClient.ArbV1().QueueJobs(launchJobFlags.Namespace).Create(qj); err != nil {
-		return err
-	}
-
-	return nil
 }
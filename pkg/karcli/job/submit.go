@@ -0,0 +1,220 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+)
+
+// statusPeriod is the interval between two checks of the status of QueueJob
+// when waiting for it.
+const statusPeriod = 2 * time.Second
+
+type submitFlags struct {
+	runFlags
+
+	Queue    string
+	Filename string
+	Wait     bool
+	Timeout  time.Duration
+}
+
+var submitJobFlags = &submitFlags{}
+
+func InitSubmitFlags(cmd *cobra.Command) {
+	initFlags(cmd, &submitJobFlags.commonFlags)
+
+	cmd.Flags().StringVarP(&submitJobFlags.Image, "image", "", "busybox", "the container image of job")
+	cmd.Flags().StringVarP(&submitJobFlags.Namespace, "namespace", "", "default", "the namespace of job")
+	cmd.Flags().StringVarP(&submitJobFlags.Name, "name", "", "test", "the name of job")
+	cmd.Flags().IntVarP(&submitJobFlags.MinAvailable, "min-available", "", 1, "the minimal available tasks of job")
+	cmd.Flags().IntVarP(&submitJobFlags.Replicas, "replicas", "", 1, "the total tasks of job")
+	cmd.Flags().StringVarP(&submitJobFlags.Requests, "requests", "", "cpu=1000m,memory=100Mi", "the resource request of the task")
+	cmd.Flags().StringVarP(&submitJobFlags.Queue, "queue", "", "", "the queue of job")
+	cmd.Flags().StringVarP(&submitJobFlags.Filename, "filename", "f", "",
+		"the QueueJob template in YAML or JSON; the flags set explicitly override its fields")
+	cmd.Flags().BoolVarP(&submitJobFlags.Wait, "wait", "", true, "wait for the minimal available tasks of job to start")
+	cmd.Flags().DurationVarP(&submitJobFlags.Timeout, "timeout", "", 0, "the timeout of waiting, 0 to wait forever")
+}
+
+// SubmitJob creates the QueueJob by the flags or template, and waits for its
+// gang to start if required; flags is the flags of command, to know which
+// ones are set explicitly.
+func SubmitJob(flags *pflag.FlagSet) error {
+	config, err := buildConfig(submitJobFlags.Master, submitJobFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	queueClient := clientset.NewForConfigOrDie(config)
+
+	qj, err := submitQueueJob(submitJobFlags, flags)
+	if err != nil {
+		return err
+	}
+
+	qj, err = queueClient.ArbV1().QueueJobs(qj.Namespace).Create(qj)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("QueueJob %s/%s created\n", qj.Namespace, qj.Name)
+
+	if !submitJobFlags.Wait {
+		return nil
+	}
+
+	return waitForQueueJob(queueClient, qj.Namespace, qj.Name, submitJobFlags.Timeout)
+}
+
+// submitQueueJob builds the QueueJob by the template of sf, overridden by the
+// flags set explicitly; or by the flags only if there's no template.
+func submitQueueJob(sf *submitFlags, flags *pflag.FlagSet) (*arbv1.QueueJob, error) {
+	req, err := populateResourceListV1(sf.Requests)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sf.Filename) == 0 {
+		qj := buildQueueJob(&sf.runFlags, req)
+		qj.Spec.SchedSpec.Queue = sf.Queue
+		return qj, nil
+	}
+
+	qj, err := loadQueueJob(sf.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if flags.Changed("name") || len(qj.Name) == 0 {
+		qj.Name = sf.Name
+	}
+	if flags.Changed("namespace") || len(qj.Namespace) == 0 {
+		qj.Namespace = sf.Namespace
+	}
+	if flags.Changed("replicas") {
+		qj.Spec.Replicas = int32(sf.Replicas)
+	}
+	if flags.Changed("min-available") {
+		qj.Spec.SchedSpec.MinAvailable = sf.MinAvailable
+	}
+	if flags.Changed("queue") {
+		qj.Spec.SchedSpec.Queue = sf.Queue
+	}
+	if flags.Changed("scheduler") || len(qj.Spec.Template.Spec.SchedulerName) == 0 {
+		qj.Spec.Template.Spec.SchedulerName = sf.SchedulerName
+	}
+
+	for i := range qj.Spec.Template.Spec.Containers {
+		c := &qj.Spec.Template.Spec.Containers[i]
+		if flags.Changed("image") {
+			c.Image = sf.Image
+		}
+		if flags.Changed("requests") {
+			c.Resources.Requests = req
+		}
+	}
+
+	return qj, nil
+}
+
+// loadQueueJob loads the QueueJob template from path.
+func loadQueueJob(path string) (*arbv1.QueueJob, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	qj := &arbv1.QueueJob{}
+	if err := yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(qj); err != nil {
+		return nil, fmt.Errorf("failed to parse QueueJob template %s: %v", path, err)
+	}
+
+	return qj, nil
+}
+
+// waitForQueueJob prints the aggregate status of QueueJob when it changes,
+// until its minimal available tasks are started, or it's finished.
+func waitForQueueJob(queueClient *clientset.Clientset, namespace, name string, timeout time.Duration) error {
+	fmt.Printf("%-12s%-12s%-8s%-12s%-12s%-12s%-12s\n",
+		"Phase", "Replicas", "Min", "Pending", "Running", "Succeeded", "Failed")
+
+	var last *arbv1.QueueJobStatus
+	var failed error
+	condition := func() (bool, error) {
+		qj, err := queueClient.ArbV1().QueueJobs(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		status := &qj.Status
+		if last == nil || statusChanged(last, status) {
+			fmt.Printf("%-12s%-12d%-8d%-12d%-12d%-12d%-12d\n",
+				status.Phase, qj.Spec.Replicas, status.MinAvailable,
+				status.Pending, status.Running, status.Succeeded, status.Failed)
+			last = status
+		}
+
+		switch status.Phase {
+		case arbv1.QueueJobPhaseFailed, arbv1.QueueJobPhaseAborted:
+			failed = fmt.Errorf("QueueJob %s/%s is %s", namespace, name, status.Phase)
+			return true, nil
+		case arbv1.QueueJobPhaseCompleted:
+			fmt.Printf("QueueJob %s/%s completed\n", namespace, name)
+			return true, nil
+		}
+
+		if status.MinAvailable > 0 && status.Running+status.Succeeded >= status.MinAvailable {
+			fmt.Printf("QueueJob %s/%s started: %d/%d tasks running\n",
+				namespace, name, status.Running, qj.Spec.Replicas)
+			return true, nil
+		}
+
+		return false, nil
+	}
+
+	var err error
+	if timeout > 0 {
+		err = wait.PollImmediate(statusPeriod, timeout, condition)
+	} else {
+		err = wait.PollImmediateInfinite(statusPeriod, condition)
+	}
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("QueueJob %s/%s did not start in %v", namespace, name, timeout)
+	}
+	if err != nil {
+		return err
+	}
+
+	return failed
+}
+
+func statusChanged(l, r *arbv1.QueueJobStatus) bool {
+	return l.Phase != r.Phase || l.MinAvailable != r.MinAvailable || l.Pending != r.Pending ||
+		l.Running != r.Running || l.Succeeded != r.Succeeded || l.Failed != r.Failed
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+const queueJobTemplate = `
+apiVersion: arbitrator.incubator.k8s.io/v1
+kind: QueueJob
+metadata:
+  name: train
+spec:
+  replicas: 4
+  schedulingSpec:
+    minAvailable: 4
+    queue: research
+  template:
+    spec:
+      containers:
+      - name: worker
+        image: tensorflow/tensorflow
+`
+
+func TestSubmitQueueJob(t *testing.T) {
+	f, err := ioutil.TempFile("", "queuejob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(queueJobTemplate); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	cmd := &cobra.Command{}
+	InitSubmitFlags(cmd)
+	if err := cmd.Flags().Parse([]string{"-f", f.Name(), "--replicas", "8", "--queue", "dev"}); err != nil {
+		t.Fatal(err)
+	}
+
+	qj, err := submitQueueJob(submitJobFlags, cmd.Flags())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if qj.Name != "train" || qj.Namespace != "default" {
+		t.Errorf("expected QueueJob default/train, got %s/%s", qj.Namespace, qj.Name)
+	}
+	if qj.Spec.Replicas != 8 || qj.Spec.SchedSpec.MinAvailable != 4 || qj.Spec.SchedSpec.Queue != "dev" {
+		t.Errorf("expected 8 replicas, min available 4 and queue dev, got %d, %d and %s",
+			qj.Spec.Replicas, qj.Spec.SchedSpec.MinAvailable, qj.Spec.SchedSpec.Queue)
+	}
+
+	c := qj.Spec.Template.Spec.Containers[0]
+	if c.Image != "tensorflow/tensorflow" || len(c.Resources.Requests) != 0 {
+		t.Errorf("expected the image and requests of template kept, got %s and %v", c.Image, c.Resources.Requests)
+	}
+	if qj.Spec.Template.Spec.SchedulerName != "kar-scheduler" {
+		t.Errorf("expected scheduler kar-scheduler, got %s", qj.Spec.Template.Spec.SchedulerName)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+type topFlags struct {
+	commonFlags
+
+	Namespace     string
+	AllNamespaces bool
+}
+
+var topJobFlags = &topFlags{}
+
+func InitTopFlags(cmd *cobra.Command) {
+	initFlags(cmd, &topJobFlags.commonFlags)
+
+	cmd.Flags().StringVarP(&topJobFlags.Namespace, "namespace", "", "default", "the namespace of jobs")
+	cmd.Flags().BoolVarP(&topJobFlags.AllNamespaces, "all-namespaces", "A", false, "show the jobs of all namespaces")
+}
+
+// jobUsage is the resources requested by the unfinished tasks of a job, and
+// the resources allocated to its tasks on nodes.
+type jobUsage struct {
+	Requested *api.Resource
+	Allocated *api.Resource
+}
+
+// TopJobs shows the requested and allocated resources of the jobs with
+// SchedulingSpec.
+func TopJobs() error {
+	config, err := buildConfig(topJobFlags.Master, topJobFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	kubeClient := kubernetes.NewForConfigOrDie(config)
+	queueClient := clientset.NewForConfigOrDie(config)
+
+	ns := topJobFlags.Namespace
+	if topJobFlags.AllNamespaces {
+		ns = metav1.NamespaceAll
+	}
+
+	specs, err := queueClient.ArbV1().SchedulingSpecs(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	pods, err := kubeClient.CoreV1().Pods(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	if len(specs.Items) == 0 {
+		fmt.Printf("No resources found\n")
+		return nil
+	}
+
+	usages := jobUsages(pods.Items)
+
+	type row struct {
+		ss    *arbv1.SchedulingSpec
+		usage *jobUsage
+	}
+	rows := make([]row, 0, len(specs.Items))
+	for i := range specs.Items {
+		ss := &specs.Items[i]
+		u, found := usages[api.SchedulingSpecJobID(ss)]
+		if !found {
+			u = &jobUsage{Requested: api.EmptyResource(), Allocated: api.EmptyResource()}
+		}
+		rows = append(rows, row{ss: ss, usage: u})
+	}
+
+	// The jobs allocated most CPU first.
+	sort.Slice(rows, func(i, j int) bool {
+		l, r := rows[i], rows[j]
+		if l.usage.Allocated.MilliCPU != r.usage.Allocated.MilliCPU {
+			return l.usage.Allocated.MilliCPU > r.usage.Allocated.MilliCPU
+		}
+		if l.ss.Namespace != r.ss.Namespace {
+			return l.ss.Namespace < r.ss.Namespace
+		}
+		return l.ss.Name < r.ss.Name
+	})
+
+	fmt.Printf("%-20s%-30s%-20s%-12s%-12s%-14s%-14s%-10s%-10s\n",
+		"Namespace", "Name", "Queue", "CPU(req)", "CPU(alloc)",
+		"Memory(req)", "Memory(alloc)", "GPU(req)", "GPU(alloc)")
+
+	for _, row := range rows {
+		ss := row.ss
+		requested, allocated := row.usage.Requested.ResourceList(), row.usage.Allocated.ResourceList()
+
+		queue := utils.SchedulingSpecQueue(ss)
+		if len(queue) == 0 {
+			queue = "-"
+		}
+
+		gpuRequested, gpuAllocated := requested[api.GPUResourceName], allocated[api.GPUResourceName]
+		fmt.Printf("%-20s%-30s%-20s%-12s%-12s%-14s%-14s%-10s%-10s\n",
+			ss.Namespace, ss.Name, queue,
+			requested.Cpu().String(), allocated.Cpu().String(),
+			requested.Memory().String(), allocated.Memory().String(),
+			gpuRequested.String(), gpuAllocated.String())
+	}
+
+	return nil
+}
+
+// jobUsages sums the resources of pods by job; the pods are grouped into jobs
+// the same way as scheduler.
+func jobUsages(pods []v1.Pod) map[api.JobID]*jobUsage {
+	usages := map[api.JobID]*jobUsage{}
+
+	for i := range pods {
+		task := api.NewTaskInfo(&pods[i])
+		if len(task.Job) == 0 || task.Status == api.Succeeded || task.Status == api.Failed {
+			continue
+		}
+
+		u, found := usages[task.Job]
+		if !found {
+			u = &jobUsage{Requested: api.EmptyResource(), Allocated: api.EmptyResource()}
+			usages[task.Job] = u
+		}
+
+		u.Requested.Add(task.Resreq)
+		if api.OccupiedResources(task.Status) {
+			u.Allocated.Add(task.Resreq)
+		}
+	}
+
+	return usages
+}
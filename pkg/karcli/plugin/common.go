@@ -0,0 +1,95 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+)
+
+type commonFlags struct {
+	Master     string
+	Kubeconfig string
+}
+
+type namespaceFlags struct {
+	commonFlags
+	Namespace     string
+	AllNamespaces bool
+}
+
+func initFlags(cmd *cobra.Command, cf *commonFlags) {
+	cmd.Flags().StringVarP(&cf.Master, "master", "s", "", "the address of apiserver")
+	cmd.Flags().StringVarP(&cf.Kubeconfig, "kubeconfig", "", defaultKubeconfig(), "(optional) absolute path to the kubeconfig file")
+}
+
+func initNamespaceFlags(cmd *cobra.Command, nf *namespaceFlags) {
+	initFlags(cmd, &nf.commonFlags)
+	cmd.Flags().StringVarP(&nf.Namespace, "namespace", "n", "default", "the namespace of jobs")
+	cmd.Flags().BoolVarP(&nf.AllNamespaces, "all-namespaces", "A", false, "show the jobs of all namespaces")
+}
+
+// namespace returns the namespace to list, empty for all namespaces.
+func (nf *namespaceFlags) namespace() string {
+	if nf.AllNamespaces {
+		return ""
+	}
+	return nf.Namespace
+}
+
+// defaultKubeconfig returns the kubeconfig of kubectl: $KUBECONFIG, or
+// ~/.kube/config; kubectl passes no flags to plugins.
+func defaultKubeconfig() string {
+	if kubeconfig := os.Getenv("KUBECONFIG"); kubeconfig != "" {
+		return filepath.SplitList(kubeconfig)[0]
+	}
+	if home := homeDir(); home != "" {
+		return filepath.Join(home, ".kube", "config")
+	}
+	return ""
+}
+
+func homeDir() string {
+	if h := os.Getenv("HOME"); h != "" {
+		return h
+	}
+	return os.Getenv("USERPROFILE") // windows
+}
+
+func buildClients(cf *commonFlags) (*kubernetes.Clientset, *clientset.Clientset, error) {
+	config, err := clientcmd.BuildConfigFromFlags(cf.Master, cf.Kubeconfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	arbClient, err := clientset.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return kubeClient, arbClient, nil
+}
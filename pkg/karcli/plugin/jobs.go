@@ -0,0 +1,152 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+var jobsFlags = &namespaceFlags{}
+
+func InitJobsFlags(cmd *cobra.Command) {
+	initNamespaceFlags(cmd, jobsFlags)
+}
+
+// jobSummary is the tasks of a job by status.
+type jobSummary struct {
+	Pending, Running, Succeeded, Failed int
+}
+
+// ListJobs shows the gang size, queue and tasks of the jobs with
+// SchedulingSpec.
+func ListJobs() error {
+	kubeClient, arbClient, err := buildClients(&jobsFlags.commonFlags)
+	if err != nil {
+		return err
+	}
+
+	ns := jobsFlags.namespace()
+	specs, err := arbClient.ArbV1().SchedulingSpecs(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	pods, err := kubeClient.CoreV1().Pods(ns).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	if len(specs.Items) == 0 {
+		fmt.Printf("No resources found\n")
+		return nil
+	}
+
+	summaries := summarizeJobs(pods.Items)
+
+	sort.Slice(specs.Items, func(i, j int) bool {
+		l, r := specs.Items[i], specs.Items[j]
+		if l.Namespace != r.Namespace {
+			return l.Namespace < r.Namespace
+		}
+		return l.Name < r.Name
+	})
+
+	fmt.Printf("%-20s%-30s%-20s%-8s%-10s%-10s%-12s%-10s%-15s\n",
+		"Namespace", "Name", "Queue", "Min", "Pending", "Running", "Succeeded", "Failed", "Status")
+
+	for i := range specs.Items {
+		ss := &specs.Items[i]
+		s := summaries[api.SchedulingSpecJobID(ss)]
+		if s == nil {
+			s = &jobSummary{}
+		}
+
+		queue := ss.Spec.Queue
+		if len(queue) == 0 {
+			queue = "-"
+		}
+
+		fmt.Printf("%-20s%-30s%-20s%-8d%-10d%-10d%-12d%-10d%-15s\n",
+			ss.Namespace, ss.Name, queue, ss.Spec.MinAvailable,
+			s.Pending, s.Running, s.Succeeded, s.Failed, jobStatus(ss))
+	}
+
+	return nil
+}
+
+// summarizeJobs counts the tasks of pods by status, by job; the pods are
+// grouped into jobs the same way as scheduler.
+func summarizeJobs(pods []v1.Pod) map[api.JobID]*jobSummary {
+	summaries := map[api.JobID]*jobSummary{}
+
+	for i := range pods {
+		task := api.NewTaskInfo(&pods[i])
+		if len(task.Job) == 0 {
+			continue
+		}
+
+		s, found := summaries[task.Job]
+		if !found {
+			s = &jobSummary{}
+			summaries[task.Job] = s
+		}
+
+		switch {
+		case task.Status == api.Pending:
+			s.Pending++
+		case task.Status == api.Succeeded:
+			s.Succeeded++
+		case task.Status == api.Failed:
+			s.Failed++
+		case api.OccupiedResources(task.Status):
+			s.Running++
+		}
+	}
+
+	return summaries
+}
+
+// jobStatus returns the status of job by its SchedulingSpec: Suspended, or the
+// type of its latest true condition, "-" if none.
+func jobStatus(ss *arbv1.SchedulingSpec) string {
+	if ss.Spec.Suspend {
+		return "Suspended"
+	}
+
+	var latest *arbv1.SchedulingSpecCondition
+	for i := range ss.Status.Conditions {
+		c := &ss.Status.Conditions[i]
+		if c.Status != v1.ConditionTrue {
+			continue
+		}
+		if latest == nil || latest.LastTransitionTime.Before(&c.LastTransitionTime) {
+			latest = c
+		}
+	}
+
+	if latest == nil {
+		return "-"
+	}
+	return string(latest.Type)
+}
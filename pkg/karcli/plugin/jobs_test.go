@@ -0,0 +1,89 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+func buildPod(name string, owner types.UID, nodeName string, phase v1.PodPhase) v1.Pod {
+	controller := true
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "ns",
+			UID:             types.UID(name),
+			OwnerReferences: []metav1.OwnerReference{{UID: owner, Controller: &controller}},
+		},
+		Spec:   v1.PodSpec{NodeName: nodeName},
+		Status: v1.PodStatus{Phase: phase},
+	}
+}
+
+func TestSummarizeJobs(t *testing.T) {
+	pods := []v1.Pod{
+		buildPod("p1", "job1", "", v1.PodPending),
+		buildPod("p2", "job1", "n1", v1.PodRunning),
+		buildPod("p3", "job1", "n1", v1.PodSucceeded),
+		buildPod("p4", "job2", "", v1.PodPending),
+	}
+
+	summaries := summarizeJobs(pods)
+	if s := summaries[api.JobID("job1")]; s == nil || s.Pending != 1 || s.Running != 1 || s.Succeeded != 1 {
+		t.Errorf("expected job1 with 1 pending, 1 running and 1 succeeded task, got %+v", s)
+	}
+	if s := summaries[api.JobID("job2")]; s == nil || s.Pending != 1 {
+		t.Errorf("expected job2 with 1 pending task, got %+v", s)
+	}
+}
+
+func TestJobStatus(t *testing.T) {
+	now := time.Now()
+	ss := &arbv1.SchedulingSpec{
+		Status: arbv1.SchedulingSpecStatus{
+			Conditions: []arbv1.SchedulingSpecCondition{
+				{
+					Type:               arbv1.SchedulingSpecUnschedulable,
+					Status:             v1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(now.Add(-time.Minute)),
+				},
+				{
+					Type:               arbv1.SchedulingSpecScheduled,
+					Status:             v1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(now),
+				},
+			},
+		},
+	}
+
+	if status := jobStatus(ss); status != string(arbv1.SchedulingSpecScheduled) {
+		t.Errorf("expected the latest condition Scheduled, got %s", status)
+	}
+
+	ss.Spec.Suspend = true
+	if status := jobStatus(ss); status != "Suspended" {
+		t.Errorf("expected Suspended, got %s", status)
+	}
+}
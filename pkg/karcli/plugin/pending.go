@@ -0,0 +1,172 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+)
+
+type pendingFlags struct {
+	namespaceFlags
+	SchedulerService string
+	Nodes            bool
+}
+
+var pendingReasonsFlags = &pendingFlags{}
+
+func InitPendingReasonsFlags(cmd *cobra.Command) {
+	initNamespaceFlags(cmd, &pendingReasonsFlags.namespaceFlags)
+	cmd.Flags().StringVarP(&pendingReasonsFlags.SchedulerService, "scheduler-service", "", "kube-system/kar-scheduler:8080",
+		"the service of kar-scheduler as <namespace>/<name>:<port>, whose debug endpoint is accessed by the proxy of apiserver")
+	cmd.Flags().BoolVarP(&pendingReasonsFlags.Nodes, "nodes", "", false, "show why each node can not fit the job")
+}
+
+// unschedulableJob is the job which can not be scheduled in the last session,
+// served by the /debug/unschedulable endpoint of kar-scheduler.
+type unschedulableJob struct {
+	UID         string              `json:"uid"`
+	Namespace   string              `json:"namespace"`
+	Name        string              `json:"name"`
+	Task        string              `json:"task,omitempty"`
+	Message     string              `json:"message"`
+	FailedNodes map[string][]string `json:"failedNodes"`
+}
+
+// ShowPendingReasons shows why the jobs, or the job of args, can not be
+// scheduled: by the debug endpoint of kar-scheduler, or by the Unschedulable
+// conditions of SchedulingSpecs if the endpoint is not available.
+func ShowPendingReasons(args []string) error {
+	kubeClient, arbClient, err := buildClients(&pendingReasonsFlags.commonFlags)
+	if err != nil {
+		return err
+	}
+
+	name := ""
+	if len(args) != 0 {
+		name = args[0]
+	}
+	ns := pendingReasonsFlags.namespace()
+
+	jobs, err := fetchUnschedulableJobs(kubeClient, pendingReasonsFlags.SchedulerService)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to fetch unschedulable jobs from kar-scheduler, "+
+			"show the conditions of SchedulingSpecs instead: %v\n", err)
+
+		specs, err := arbClient.ArbV1().SchedulingSpecs(ns).List(metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		jobs = conditionJobs(specs.Items)
+	}
+
+	found := false
+	for _, job := range jobs {
+		if (len(ns) != 0 && job.Namespace != ns) || (len(name) != 0 && job.Name != name) {
+			continue
+		}
+		found = true
+
+		if len(job.Task) != 0 {
+			fmt.Printf("%s/%s (task %s): %s\n", job.Namespace, job.Name, job.Task, job.Message)
+		} else {
+			fmt.Printf("%s/%s: %s\n", job.Namespace, job.Name, job.Message)
+		}
+
+		if pendingReasonsFlags.Nodes {
+			printFailedNodes(job.FailedNodes)
+		}
+	}
+
+	if !found {
+		fmt.Printf("No unschedulable jobs found\n")
+	}
+
+	return nil
+}
+
+// fetchUnschedulableJobs fetches the unschedulable jobs of the last session
+// from the service of kar-scheduler by the proxy of apiserver.
+func fetchUnschedulableJobs(kubeClient *kubernetes.Clientset, service string) ([]*unschedulableJob, error) {
+	parts := strings.SplitN(service, "/", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return nil, fmt.Errorf("invalid scheduler service %q, expected <namespace>/<name>:<port>", service)
+	}
+
+	data, err := kubeClient.CoreV1().RESTClient().Get().
+		AbsPath("/api/v1/namespaces", parts[0], "services", parts[1], "proxy/debug/unschedulable").
+		DoRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*unschedulableJob
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to decode unschedulable jobs: %v", err)
+	}
+
+	return jobs, nil
+}
+
+// conditionJobs returns the jobs whose SchedulingSpecs have true Unschedulable
+// condition, with the message of condition.
+func conditionJobs(specs []arbv1.SchedulingSpec) []*unschedulableJob {
+	var jobs []*unschedulableJob
+	for _, ss := range specs {
+		for _, c := range ss.Status.Conditions {
+			if c.Type == arbv1.SchedulingSpecUnschedulable && c.Status == v1.ConditionTrue {
+				jobs = append(jobs, &unschedulableJob{
+					Namespace: ss.Namespace,
+					Name:      ss.Name,
+					Message:   c.Message,
+				})
+			}
+		}
+	}
+	return jobs
+}
+
+// printFailedNodes prints the nodes by the reason why they can not fit job.
+func printFailedNodes(failedNodes map[string][]string) {
+	nodes := map[string][]string{}
+	for node, reasons := range failedNodes {
+		for _, reason := range reasons {
+			nodes[reason] = append(nodes[reason], node)
+		}
+	}
+
+	reasons := make([]string, 0, len(nodes))
+	for reason := range nodes {
+		reasons = append(reasons, reason)
+		sort.Strings(nodes[reason])
+	}
+	sort.Strings(reasons)
+
+	for _, reason := range reasons {
+		fmt.Printf("  %s: %s\n", reason, strings.Join(nodes[reason], ", "))
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var queuesFlags = &commonFlags{}
+
+func InitQueuesFlags(cmd *cobra.Command) {
+	initFlags(cmd, queuesFlags)
+}
+
+// ListQueues shows the weight, capability and usage of queues.
+func ListQueues() error {
+	_, arbClient, err := buildClients(queuesFlags)
+	if err != nil {
+		return err
+	}
+
+	queues, err := arbClient.ArbV1().Queues().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	if len(queues.Items) == 0 {
+		fmt.Printf("No resources found\n")
+		return nil
+	}
+
+	sort.Slice(queues.Items, func(i, j int) bool {
+		return queues.Items[i].Name < queues.Items[j].Name
+	})
+
+	fmt.Printf("%-25s%-8s%-10s%-10s%-35s%-35s\n",
+		"Name", "Weight", "Pending", "Running", "Capability", "Requested")
+
+	for _, q := range queues.Items {
+		fmt.Printf("%-25s%-8d%-10d%-10d%-35s%-35s\n",
+			q.Name, q.Spec.Weight, q.Status.Pending, q.Status.Running,
+			formatResources(q.Spec.Capability), formatResources(q.Status.Requested))
+	}
+
+	return nil
+}
+
+// formatResources formats rl as <resource>=<value> sorted by resource, or
+// "-" if it's empty.
+func formatResources(rl v1.ResourceList) string {
+	if len(rl) == 0 {
+		return "-"
+	}
+
+	items := make([]string, 0, len(rl))
+	for name, quantity := range rl {
+		items = append(items, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+	sort.Strings(items)
+
+	return strings.Join(items, ",")
+}
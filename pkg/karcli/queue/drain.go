@@ -0,0 +1,216 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+// The policies to drain the running gangs of a queue.
+const (
+	// GracefulDrain waits for the running gangs to finish.
+	GracefulDrain = "graceful"
+	// DeadlineDrain waits for the running gangs until the deadline, then
+	// evicts the remaining ones.
+	DeadlineDrain = "deadline"
+	// ForceDrain evicts the running gangs at once.
+	ForceDrain = "force"
+)
+
+// drainPeriod is the interval to check the running gangs of queue.
+const drainPeriod = 5 * time.Second
+
+type drainFlags struct {
+	commonFlags
+
+	Policy   string
+	Deadline time.Duration
+}
+
+var drainQueueFlags = &drainFlags{}
+
+func InitDrainFlags(cmd *cobra.Command) {
+	initFlags(cmd, &drainQueueFlags.commonFlags)
+
+	cmd.Flags().StringVarP(&drainQueueFlags.Policy, "policy", "", GracefulDrain,
+		"how to drain the running gangs: graceful waits for them to finish, deadline evicts the remaining ones "+
+			"after --deadline, force evicts them at once")
+	cmd.Flags().DurationVarP(&drainQueueFlags.Deadline, "deadline", "", 0,
+		"the time to wait for the running gangs before evicting them with the deadline policy")
+}
+
+// DrainQueue closes the queue, so it admits no new QueueJobs and its jobs not
+// started yet are not scheduled by kar-scheduler; then it waits out or evicts
+// the running gangs of queue by the policy. The gangs are evicted as a whole,
+// i.e. all their pods are evicted at once, so no partial gang is left
+// holding resources; the pods whose eviction is rejected by a PDB are
+// evicted again at the next check.
+func DrainQueue(name string) error {
+	var deadline time.Time
+	switch drainQueueFlags.Policy {
+	case GracefulDrain:
+	case DeadlineDrain:
+		if drainQueueFlags.Deadline <= 0 {
+			return fmt.Errorf("--deadline should be positive with the %s policy", DeadlineDrain)
+		}
+		deadline = time.Now().Add(drainQueueFlags.Deadline)
+	case ForceDrain:
+		deadline = time.Now()
+	default:
+		return fmt.Errorf("unknown drain policy %q, expected %s, %s or %s",
+			drainQueueFlags.Policy, GracefulDrain, DeadlineDrain, ForceDrain)
+	}
+
+	kubeClient, arbClient, err := buildClients(&drainQueueFlags.commonFlags)
+	if err != nil {
+		return err
+	}
+
+	if err := closeQueue(arbClient, name); err != nil {
+		return err
+	}
+	fmt.Printf("Queue %s closed\n", name)
+
+	last := -1
+	return wait.PollImmediateInfinite(drainPeriod, func() (bool, error) {
+		gangs, err := listRunningGangs(kubeClient, arbClient, name)
+		if err != nil {
+			return false, err
+		}
+
+		if len(gangs) == 0 {
+			fmt.Printf("Queue %s drained\n", name)
+			return true, nil
+		}
+		if len(gangs) != last {
+			fmt.Printf("%d gangs running in queue %s\n", len(gangs), name)
+			last = len(gangs)
+		}
+
+		if deadline.IsZero() || time.Now().Before(deadline) {
+			return false, nil
+		}
+		for job, pods := range gangs {
+			evicted, err := evictGang(kubeClient, pods)
+			if err != nil {
+				return false, err
+			}
+			if evicted != 0 {
+				fmt.Printf("Evicted %d pods of job %s\n", evicted, job)
+			}
+		}
+		return false, nil
+	})
+}
+
+// closeQueue sets the state of queue to Closed.
+func closeQueue(arbClient *clientset.Clientset, name string) error {
+	queue, err := arbClient.ArbV1().Queues().Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if queue.Spec.State == arbv1.QueueStateClosed {
+		return nil
+	}
+
+	queue.Spec.State = arbv1.QueueStateClosed
+	_, err = arbClient.ArbV1().Queues().Update(queue)
+	return err
+}
+
+func listRunningGangs(kubeClient *kubernetes.Clientset, arbClient *clientset.Clientset,
+	queue string) (map[api.JobID][]*v1.Pod, error) {
+	specs, err := arbClient.ArbV1().SchedulingSpecs(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := kubeClient.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return runningGangs(queue, specs.Items, pods.Items), nil
+}
+
+// runningGangs returns the pods occupying resources of the jobs in queue, by
+// job, including the ones being deleted; the pods are grouped into jobs the
+// same way as scheduler.
+func runningGangs(queue string, specs []arbv1.SchedulingSpec, pods []v1.Pod) map[api.JobID][]*v1.Pod {
+	jobs := map[api.JobID]bool{}
+	for i := range specs {
+		if utils.SchedulingSpecQueue(&specs[i]) == queue {
+			jobs[api.SchedulingSpecJobID(&specs[i])] = true
+		}
+	}
+
+	gangs := map[api.JobID][]*v1.Pod{}
+	for i := range pods {
+		task := api.NewTaskInfo(&pods[i])
+		if !jobs[task.Job] || !api.OccupiedResources(task.Status) {
+			continue
+		}
+		gangs[task.Job] = append(gangs[task.Job], &pods[i])
+	}
+
+	return gangs
+}
+
+// evictGang evicts the pods of a gang by the Eviction subresource, the same
+// way as scheduler evicts them, so PDBs are respected; the pods being deleted
+// or already gone are skipped, and so are the ones whose eviction is rejected
+// by a PDB for now. It returns the number of pods evicted.
+func evictGang(kubeClient *kubernetes.Clientset, pods []*v1.Pod) (int, error) {
+	evicted := 0
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		uid := pod.UID
+		eviction := &policy.Eviction{
+			ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name},
+			DeleteOptions: &metav1.DeleteOptions{
+				Preconditions: &metav1.Preconditions{UID: &uid},
+			},
+		}
+		err := kubeClient.PolicyV1beta1().Evictions(pod.Namespace).Evict(eviction)
+		switch {
+		case err == nil:
+			evicted++
+		case apierrors.IsNotFound(err):
+		case apierrors.IsTooManyRequests(err):
+			fmt.Printf("Eviction of pod %s/%s is rejected by PDB, retry later\n", pod.Namespace, pod.Name)
+		default:
+			return evicted, fmt.Errorf("failed to evict pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+	return evicted, nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+func ownedBy(owner types.UID) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{UID: owner, Controller: &controller}}
+}
+
+func buildPod(name string, owner types.UID, nodeName string, phase v1.PodPhase) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "ns",
+			UID:             types.UID(name),
+			OwnerReferences: ownedBy(owner),
+		},
+		Spec:   v1.PodSpec{NodeName: nodeName},
+		Status: v1.PodStatus{Phase: phase},
+	}
+}
+
+func buildSchedulingSpec(name string, owner types.UID, queue string) arbv1.SchedulingSpec {
+	return arbv1.SchedulingSpec{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", OwnerReferences: ownedBy(owner)},
+		Spec:       arbv1.SchedulingSpecTemplate{Queue: queue},
+	}
+}
+
+func TestRunningGangs(t *testing.T) {
+	specs := []arbv1.SchedulingSpec{
+		buildSchedulingSpec("ss1", "job1", "q1"),
+		buildSchedulingSpec("ss2", "job2", "q1"),
+		buildSchedulingSpec("ss3", "job3", "q2"),
+	}
+	pods := []v1.Pod{
+		buildPod("p1", "job1", "n1", v1.PodRunning),
+		buildPod("p2", "job1", "n2", v1.PodRunning),
+		buildPod("p3", "job1", "n1", v1.PodSucceeded),
+		// job2 is not started.
+		buildPod("p4", "job2", "", v1.PodPending),
+		buildPod("p5", "job3", "n1", v1.PodRunning),
+	}
+
+	gangs := runningGangs("q1", specs, pods)
+	if len(gangs) != 1 || len(gangs[api.JobID("job1")]) != 2 {
+		t.Errorf("expected 2 running pods of job1 only, got %v", gangs)
+	}
+}
+
+func TestEvictGang(t *testing.T) {
+	// The pods are named by the status code of their eviction.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		parts := strings.Split(r.URL.Path, "/")
+		if r.Method != "POST" || len(parts) != 8 || parts[7] != "eviction" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+
+		eviction := &policy.Eviction{}
+		json.NewDecoder(r.Body).Decode(eviction)
+		if eviction.Name != parts[6] || eviction.DeleteOptions == nil ||
+			eviction.DeleteOptions.Preconditions == nil ||
+			*eviction.DeleteOptions.Preconditions.UID != types.UID(parts[6]) {
+			t.Errorf("expected eviction of pod %s with its UID, got %+v", parts[6], eviction)
+		}
+
+		switch parts[6] {
+		case "created":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(&metav1.Status{Status: metav1.StatusSuccess})
+		case "not-found":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(&metav1.Status{
+				Status: metav1.StatusFailure, Reason: metav1.StatusReasonNotFound, Code: http.StatusNotFound,
+			})
+		case "too-many-requests":
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(&metav1.Status{
+				Status: metav1.StatusFailure, Reason: metav1.StatusReasonTooManyRequests, Code: http.StatusTooManyRequests,
+			})
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(&metav1.Status{
+				Status: metav1.StatusFailure, Reason: metav1.StatusReasonInternalError, Code: http.StatusInternalServerError,
+			})
+		}
+	}))
+	defer server.Close()
+
+	kubeClient := kubernetes.NewForConfigOrDie(&rest.Config{Host: server.URL})
+
+	now := metav1.Now()
+	deleting := buildPod("deleting", "job1", "n1", v1.PodRunning)
+	deleting.DeletionTimestamp = &now
+	created := buildPod("created", "job1", "n1", v1.PodRunning)
+	notFound := buildPod("not-found", "job1", "n1", v1.PodRunning)
+	tooManyRequests := buildPod("too-many-requests", "job1", "n1", v1.PodRunning)
+	internalError := buildPod("internal-error", "job1", "n1", v1.PodRunning)
+
+	evicted, err := evictGang(kubeClient, []*v1.Pod{&deleting, &created, &notFound, &tooManyRequests})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if evicted != 1 {
+		t.Errorf("expected 1 pod evicted, got %d", evicted)
+	}
+
+	evicted, err = evictGang(kubeClient, []*v1.Pod{&created, &internalError})
+	if err == nil {
+		t.Errorf("expected error of evicting pod internal-error")
+	}
+	if evicted != 1 {
+		t.Errorf("expected 1 pod evicted before error, got %d", evicted)
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+type topFlags struct {
+	commonFlags
+
+	SchedulerService string
+}
+
+var topQueuesFlags = &topFlags{}
+
+func InitTopFlags(cmd *cobra.Command) {
+	initFlags(cmd, &topQueuesFlags.commonFlags)
+
+	cmd.Flags().StringVarP(&topQueuesFlags.SchedulerService, "scheduler-service", "", "kube-system/kar-scheduler:8080",
+		"the service of kar-scheduler as <namespace>/<name>:<port>, whose metrics are accessed by the proxy of apiserver")
+}
+
+// queueShare is the deserved and allocated resources of a queue in the last
+// session, exported by the proportion plugin of kar-scheduler.
+type queueShare struct {
+	Deserved  *api.Resource
+	Allocated *api.Resource
+}
+
+func newQueueShare() *queueShare {
+	return &queueShare{Deserved: api.EmptyResource(), Allocated: api.EmptyResource()}
+}
+
+// share returns the dominant share of the allocated resources of queue to its
+// deserved resources; 1 means the queue is allocated as it deserves.
+func (qs *queueShare) share() float64 {
+	share := 0.0
+	for _, rn := range api.ResourceNames() {
+		if deserved := qs.Deserved.Get(rn); deserved > 0 {
+			if s := qs.Allocated.Get(rn) / deserved; s > share {
+				share = s
+			}
+		}
+	}
+	return share
+}
+
+// TopQueues shows the deserved and allocated resources of queues by the
+// metrics of kar-scheduler, with the workloads of queues by their status.
+func TopQueues() error {
+	kubeClient, arbClient, err := buildClients(&topQueuesFlags.commonFlags)
+	if err != nil {
+		return err
+	}
+
+	data, err := fetchSchedulerMetrics(kubeClient, topQueuesFlags.SchedulerService)
+	if err != nil {
+		return fmt.Errorf("failed to fetch metrics of kar-scheduler: %v", err)
+	}
+	shares := parseQueueShares(data)
+
+	queues, err := arbClient.ArbV1().Queues().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	if len(queues.Items) == 0 {
+		fmt.Printf("No resources found\n")
+		return nil
+	}
+
+	sort.Slice(queues.Items, func(i, j int) bool {
+		return queues.Items[i].Name < queues.Items[j].Name
+	})
+
+	fmt.Printf("%-25s%-8s%-10s%-10s%-14s%-14s%-14s%-14s%-10s%-10s%-8s\n",
+		"Name", "Weight", "Pending", "Running", "CPU(des)", "CPU(alloc)",
+		"Memory(des)", "Memory(alloc)", "GPU(des)", "GPU(alloc)", "Share")
+
+	for _, q := range queues.Items {
+		qs, found := shares[q.Name]
+		if !found {
+			qs = newQueueShare()
+		}
+		deserved, allocated := qs.Deserved.ResourceList(), qs.Allocated.ResourceList()
+		gpuDeserved, gpuAllocated := deserved[api.GPUResourceName], allocated[api.GPUResourceName]
+
+		fmt.Printf("%-25s%-8d%-10d%-10d%-14s%-14s%-14s%-14s%-10s%-10s%-8s\n",
+			q.Name, q.Spec.Weight, q.Status.Pending, q.Status.Running,
+			deserved.Cpu().String(), allocated.Cpu().String(),
+			deserved.Memory().String(), allocated.Memory().String(),
+			gpuDeserved.String(), gpuAllocated.String(),
+			fmt.Sprintf("%.0f%%", qs.share()*100))
+	}
+
+	return nil
+}
+
+// fetchSchedulerMetrics fetches the metrics of kar-scheduler from its service
+// by the proxy of apiserver.
+func fetchSchedulerMetrics(kubeClient *kubernetes.Clientset, service string) ([]byte, error) {
+	parts := strings.SplitN(service, "/", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return nil, fmt.Errorf("invalid scheduler service %q, expected <namespace>/<name>:<port>", service)
+	}
+
+	return kubeClient.CoreV1().RESTClient().Get().
+		AbsPath("/api/v1/namespaces", parts[0], "services", parts[1], "proxy/metrics").
+		DoRaw()
+}
+
+var labelPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// parseQueueShares parses the queue shares of the metrics in text format,
+// e.g. scheduler_queue_deserved{queue="q1",resource="cpu"} 4000.
+func parseQueueShares(data []byte) map[string]*queueShare {
+	shares := map[string]*queueShare{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		begin, end := strings.Index(line, "{"), strings.LastIndex(line, "}")
+		if strings.HasPrefix(line, "#") || begin < 0 || end < begin {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(strings.TrimSpace(line[end+1:]), 64)
+		if err != nil {
+			continue
+		}
+		labels := map[string]string{}
+		for _, m := range labelPattern.FindAllStringSubmatch(line[begin:end], -1) {
+			labels[m[1]] = m[2]
+		}
+
+		name := labels["queue"]
+		if len(name) == 0 {
+			continue
+		}
+		qs, found := shares[name]
+		if !found {
+			qs = newQueueShare()
+		}
+
+		switch line[:begin] {
+		case "scheduler_queue_deserved":
+			setResource(qs.Deserved, labels["resource"], value)
+		case "scheduler_queue_allocated":
+			setResource(qs.Allocated, labels["resource"], value)
+		default:
+			continue
+		}
+		shares[name] = qs
+	}
+
+	return shares
+}
+
+func setResource(r *api.Resource, name string, value float64) {
+	switch v1.ResourceName(name) {
+	case v1.ResourceCPU:
+		r.MilliCPU = value
+	case v1.ResourceMemory:
+		r.Memory = value
+	case api.GPUResourceName:
+		r.GPU = int64(value)
+	}
+}
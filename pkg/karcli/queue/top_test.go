@@ -0,0 +1,50 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"testing"
+)
+
+func TestParseQueueShares(t *testing.T) {
+	data := []byte(`# HELP scheduler_queue_deserved Deserved resources of the queue
+# TYPE scheduler_queue_deserved gauge
+scheduler_queue_deserved{queue="q1",resource="cpu"} 4000
+scheduler_queue_deserved{queue="q1",resource="memory"} 1024
+scheduler_queue_allocated{queue="q1",resource="cpu"} 6000
+scheduler_queue_allocated{queue="q1",resource="memory"} 512
+scheduler_queue_request{queue="q1",resource="cpu"} 8000
+scheduler_queue_allocated{queue="q2",resource="nvidia.com/gpu"} 2
+scheduler_action_scheduling_latency_microseconds_count{action="allocate"} 3
+`)
+
+	shares := parseQueueShares(data)
+	if len(shares) != 2 {
+		t.Fatalf("expected shares of 2 queues, got %v", shares)
+	}
+
+	q1 := shares["q1"]
+	if q1.Deserved.MilliCPU != 4000 || q1.Allocated.MilliCPU != 6000 || q1.Allocated.Memory != 512 {
+		t.Errorf("unexpected share of q1: deserved %v, allocated %v", q1.Deserved, q1.Allocated)
+	}
+	if share := q1.share(); share != 1.5 {
+		t.Errorf("expected dominant share 1.5 of q1, got %v", share)
+	}
+	if shares["q2"].Allocated.GPU != 2 {
+		t.Errorf("expected 2 GPUs allocated to q2, got %v", shares["q2"].Allocated)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/version"
+)
+
+type versionFlags struct {
+	Master     string
+	Kubeconfig string
+
+	SchedulerService string
+	Client           bool
+}
+
+var showVersionFlags = &versionFlags{}
+
+func InitVersionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&showVersionFlags.Master, "master", "s", "", "the address of apiserver")
+
+	if home := homeDir(); home != "" {
+		cmd.Flags().StringVarP(&showVersionFlags.Kubeconfig, "kubeconfig", "", filepath.Join(home, ".kube", "config"), "(optional) absolute path to the kubeconfig file")
+	} else {
+		cmd.Flags().StringVarP(&showVersionFlags.Kubeconfig, "kubeconfig", "", "", "(optional) absolute path to the kubeconfig file")
+	}
+
+	cmd.Flags().StringVarP(&showVersionFlags.SchedulerService, "scheduler-service", "", "kube-system/kar-scheduler:8080",
+		"the service of kar-scheduler as <namespace>/<name>:<port>, whose configz is accessed by the proxy of apiserver")
+	cmd.Flags().BoolVarP(&showVersionFlags.Client, "client", "", false, "show the version of arbctl only")
+}
+
+func homeDir() string {
+	if h := os.Getenv("HOME"); h != "" {
+		return h
+	}
+	return os.Getenv("USERPROFILE") // windows
+}
+
+// ShowVersion shows the version of arbctl, and the version and the policy of
+// kar-scheduler reported by its /configz endpoint.
+func ShowVersion() error {
+	fmt.Printf("Client Version: %s\n", version.Get())
+	if showVersionFlags.Client {
+		return nil
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags(showVersionFlags.Master, showVersionFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	data, err := fetchConfigz(kubeClient, showVersionFlags.SchedulerService)
+	if err != nil {
+		return fmt.Errorf("failed to get configz of kar-scheduler: %v", err)
+	}
+	configz := &scheduler.Configz{}
+	if err := json.Unmarshal(data, configz); err != nil {
+		return fmt.Errorf("failed to parse configz of kar-scheduler: %v", err)
+	}
+
+	printConfigz(configz)
+	return nil
+}
+
+func fetchConfigz(kubeClient *kubernetes.Clientset, service string) ([]byte, error) {
+	parts := strings.SplitN(service, "/", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return nil, fmt.Errorf("invalid scheduler service %q, expected <namespace>/<name>:<port>", service)
+	}
+
+	return kubeClient.CoreV1().RESTClient().Get().
+		AbsPath("/api/v1/namespaces", parts[0], "services", parts[1], "proxy/configz").
+		DoRaw()
+}
+
+func printConfigz(c *scheduler.Configz) {
+	fmt.Printf("Server Version: %s\n", c.Version)
+
+	configFile := c.ConfigFile
+	if len(configFile) == 0 {
+		configFile = "<none>"
+	}
+	fmt.Printf("Config File: %s\n", configFile)
+	fmt.Printf("Actions: %s\n", strings.Join(c.Actions, ", "))
+	fmt.Printf("Plugins: %s\n", strings.Join(c.Plugins, ", "))
+	fmt.Printf("Disabled Plugins: %s\n", strings.Join(c.DisabledPlugins, ", "))
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package leaderelection implements leader election of kube-arbitrator components
+// by the lock in a ConfigMap's annotation, which is compatible with the
+// ConfigMapLock of client-go; only the leader runs the components, so multiple
+// replicas can be deployed for HA.
+//
+// The Lease of coordination.k8s.io, i.e. the LeaseLock of client-go, is not
+// used, as the coordination API is not in the vendored client-go; the lock is
+// behind the lock interface, so it can be replaced once it's vendored.
+package leaderelection
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang/glog"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// LeaderElectionRecordAnnotationKey is the annotation of the lock ConfigMap.
+	LeaderElectionRecordAnnotationKey = "control-plane.alpha.kubernetes.io/leader"
+
+	// DefaultLeaseDuration is the duration that non-leader candidates wait
+	// to force acquire leadership.
+	DefaultLeaseDuration = 15 * time.Second
+	// DefaultRenewDeadline is the duration that the leader retries refreshing
+	// leadership before giving up.
+	DefaultRenewDeadline = 10 * time.Second
+	// DefaultRetryPeriod is the duration the candidates wait between tries of actions.
+	DefaultRetryPeriod = 2 * time.Second
+)
+
+// LeaderElectionRecord is the record of leader in the lock.
+type LeaderElectionRecord struct {
+	HolderIdentity       string      `json:"holderIdentity"`
+	LeaseDurationSeconds int         `json:"leaseDurationSeconds"`
+	AcquireTime          metav1.Time `json:"acquireTime"`
+	RenewTime            metav1.Time `json:"renewTime"`
+	LeaderTransitions    int         `json:"leaderTransitions"`
+}
+
+// Config is the configuration of leader election.
+type Config struct {
+	Client kubernetes.Interface
+
+	// The namespace and name of the lock ConfigMap.
+	Namespace string
+	Name      string
+
+	// Identity is the unique identity of the candidate.
+	Identity string
+
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+
+	// OnStartedLeading is called when the candidate becomes leader; stopCh
+	// is closed when it stops leading.
+	OnStartedLeading func(stopCh <-chan struct{})
+	// OnStoppedLeading is called when the leader stops leading.
+	OnStoppedLeading func()
+}
+
+// NewConfig creates Config with default durations, and the identity of hostname
+// with a random suffix.
+func NewConfig(client kubernetes.Interface, namespace, name string) (*Config, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get hostname: %v", err)
+	}
+
+	return &Config{
+		Client:        client,
+		Namespace:     namespace,
+		Name:          name,
+		Identity:      hostname + "_" + string(uuid.NewUUID()),
+		LeaseDuration: DefaultLeaseDuration,
+		RenewDeadline: DefaultRenewDeadline,
+		RetryPeriod:   DefaultRetryPeriod,
+	}, nil
+}
+
+type leaderElector struct {
+	config *Config
+	lock   lock
+
+	// The record observed last time, and the local time when it's changed;
+	// the local time is used to avoid clock skew among candidates.
+	observedRecord LeaderElectionRecord
+	observedTime   time.Time
+}
+
+// Run blocks until the candidate becomes leader, then calls OnStartedLeading
+// and keeps renewing the leadership; it returns after the leadership is lost.
+func Run(config *Config) {
+	run(config, newConfigMapLock(config.Client, config.Namespace, config.Name))
+}
+
+func run(config *Config, lock lock) {
+	le := &leaderElector{config: config, lock: lock}
+
+	le.acquire()
+
+	stopCh := make(chan struct{})
+	go config.OnStartedLeading(stopCh)
+
+	le.renew()
+	close(stopCh)
+
+	if config.OnStoppedLeading != nil {
+		config.OnStoppedLeading()
+	}
+}
+
+// acquire loops until the leadership is acquired.
+func (le *leaderElector) acquire() {
+	stop := make(chan struct{})
+	glog.Infof("Attempting to acquire leader lease %v/%v ...", le.config.Namespace, le.config.Name)
+	wait.JitterUntil(func() {
+		if le.tryAcquireOrRenew() {
+			glog.Infof("Successfully acquired lease %v/%v", le.config.Namespace, le.config.Name)
+			close(stop)
+			return
+		}
+		glog.V(4).Infof("Failed to acquire lease %v/%v", le.config.Namespace, le.config.Name)
+	}, le.config.RetryPeriod, 1.2, true, stop)
+}
+
+// renew loops until the leadership can not be renewed in RenewDeadline.
+func (le *leaderElector) renew() {
+	stop := make(chan struct{})
+	wait.Until(func() {
+		err := wait.Poll(le.config.RetryPeriod, le.config.RenewDeadline, func() (bool, error) {
+			return le.tryAcquireOrRenew(), nil
+		})
+		if err == nil {
+			glog.V(5).Infof("Successfully renewed lease %v/%v", le.config.Namespace, le.config.Name)
+			return
+		}
+		glog.Errorf("Failed to renew lease %v/%v: %v", le.config.Namespace, le.config.Name, err)
+		close(stop)
+	}, le.config.RetryPeriod, stop)
+}
+
+// tryAcquireOrRenew tries to acquire the leadership if it's not held by other
+// candidate or expired, or renews it if it's held by this candidate.
+func (le *leaderElector) tryAcquireOrRenew() bool {
+	// The times are truncated to seconds as they're encoded in the lock, so the
+	// observed record is equal to the one read back.
+	now := metav1.Now().Rfc3339Copy()
+	record := LeaderElectionRecord{
+		HolderIdentity:       le.config.Identity,
+		LeaseDurationSeconds: int(le.config.LeaseDuration / time.Second),
+		AcquireTime:          now,
+		RenewTime:            now,
+	}
+
+	oldRecord, err := le.lock.Get()
+	if err != nil {
+		glog.Errorf("Failed to get lock %v/%v: %v", le.config.Namespace, le.config.Name, err)
+		return false
+	}
+	if oldRecord == nil {
+		if err := le.lock.Create(record); err != nil {
+			glog.Errorf("Failed to create lock %v/%v: %v", le.config.Namespace, le.config.Name, err)
+			return false
+		}
+
+		le.observedRecord = record
+		le.observedTime = time.Now()
+		return true
+	}
+
+	if !recordEqual(oldRecord, &le.observedRecord) {
+		le.observedRecord = *oldRecord
+		le.observedTime = time.Now()
+	}
+
+	isLeader := oldRecord.HolderIdentity == le.config.Identity
+	if !isLeader && len(oldRecord.HolderIdentity) != 0 &&
+		le.observedTime.Add(le.config.LeaseDuration).After(time.Now()) {
+		glog.V(4).Infof("Lock is held by %v and has not yet expired", oldRecord.HolderIdentity)
+		return false
+	}
+
+	if isLeader {
+		record.AcquireTime = oldRecord.AcquireTime
+		record.LeaderTransitions = oldRecord.LeaderTransitions
+	} else {
+		record.LeaderTransitions = oldRecord.LeaderTransitions + 1
+	}
+
+	// The update fails by conflict if other candidate updated the lock.
+	if err := le.lock.Update(record); err != nil {
+		glog.Errorf("Failed to update lock %v/%v: %v", le.config.Namespace, le.config.Name, err)
+		return false
+	}
+
+	le.observedRecord = record
+	le.observedTime = time.Now()
+	return true
+}
+
+// recordEqual returns whether the records are the same; the times are compared
+// by Equal, as the records decoded from lock have no monotonic clock reading
+// nor the same location.
+func recordEqual(l, r *LeaderElectionRecord) bool {
+	return l.HolderIdentity == r.HolderIdentity &&
+		l.LeaseDurationSeconds == r.LeaseDurationSeconds &&
+		l.AcquireTime.Equal(&r.AcquireTime) &&
+		l.RenewTime.Equal(&r.RenewTime) &&
+		l.LeaderTransitions == r.LeaderTransitions
+}
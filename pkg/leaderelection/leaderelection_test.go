@@ -0,0 +1,261 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeLock stores the record encoded as the ConfigMap lock, and fails the
+// update by conflict if it's updated since the last Get of the candidate.
+type fakeLock struct {
+	sync.Mutex
+
+	data    string
+	version int
+	err     error
+}
+
+// candidateLock is the view of fakeLock by a candidate.
+type candidateLock struct {
+	*fakeLock
+	observed int
+}
+
+func (cl *candidateLock) Get() (*LeaderElectionRecord, error) {
+	cl.Lock()
+	defer cl.Unlock()
+
+	if cl.err != nil {
+		return nil, cl.err
+	}
+	if len(cl.data) == 0 {
+		return nil, nil
+	}
+	cl.observed = cl.version
+
+	record := &LeaderElectionRecord{}
+	if err := json.Unmarshal([]byte(cl.data), record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (cl *candidateLock) Create(record LeaderElectionRecord) error {
+	cl.Lock()
+	defer cl.Unlock()
+
+	if len(cl.data) != 0 {
+		return fmt.Errorf("already exists")
+	}
+	return cl.set(record)
+}
+
+func (cl *candidateLock) Update(record LeaderElectionRecord) error {
+	cl.Lock()
+	defer cl.Unlock()
+
+	if cl.err != nil {
+		return cl.err
+	}
+	if cl.observed != cl.version {
+		return fmt.Errorf("conflict")
+	}
+	return cl.set(record)
+}
+
+func (cl *candidateLock) set(record LeaderElectionRecord) error {
+	data, err := json.Marshal(&record)
+	if err != nil {
+		return err
+	}
+	cl.data = string(data)
+	cl.version++
+	cl.observed = cl.version
+	return nil
+}
+
+func (fl *fakeLock) record(t *testing.T) *LeaderElectionRecord {
+	record, err := (&candidateLock{fakeLock: fl}).Get()
+	if err != nil || record == nil {
+		t.Fatalf("failed to get record: %v", err)
+	}
+	return record
+}
+
+func newElector(identity string, fl *fakeLock) *leaderElector {
+	return &leaderElector{
+		config: &Config{
+			Namespace:     "kube-system",
+			Name:          "test",
+			Identity:      identity,
+			LeaseDuration: DefaultLeaseDuration,
+			RenewDeadline: DefaultRenewDeadline,
+			RetryPeriod:   DefaultRetryPeriod,
+		},
+		lock: &candidateLock{fakeLock: fl},
+	}
+}
+
+func TestAcquire(t *testing.T) {
+	fl := &fakeLock{}
+	le1 := newElector("c1", fl)
+	le2 := newElector("c2", fl)
+
+	if !le1.tryAcquireOrRenew() {
+		t.Fatalf("expected c1 to acquire the new lock")
+	}
+	if le2.tryAcquireOrRenew() {
+		t.Errorf("expected c2 not to acquire the lock held by c1")
+	}
+
+	// c2 takes over after the lease of c1 expired by its local time.
+	le2.observedTime = time.Now().Add(-DefaultLeaseDuration)
+	if !le2.tryAcquireOrRenew() {
+		t.Fatalf("expected c2 to acquire the expired lock")
+	}
+	if record := fl.record(t); record.HolderIdentity != "c2" || record.LeaderTransitions != 1 {
+		t.Errorf("expected c2 holding the lock after 1 transition, got %+v", record)
+	}
+
+	if le1.tryAcquireOrRenew() {
+		t.Errorf("expected c1 not to renew the lock acquired by c2")
+	}
+}
+
+func TestRenew(t *testing.T) {
+	acquired := metav1.NewTime(time.Now().Add(-time.Hour)).Rfc3339Copy()
+
+	fl := &fakeLock{}
+	le := newElector("c1", fl)
+	(&candidateLock{fakeLock: fl}).Create(LeaderElectionRecord{
+		HolderIdentity:    "c1",
+		AcquireTime:       acquired,
+		RenewTime:         acquired,
+		LeaderTransitions: 3,
+	})
+
+	if !le.tryAcquireOrRenew() {
+		t.Fatalf("expected c1 to renew its lock")
+	}
+
+	record := fl.record(t)
+	if !record.AcquireTime.Equal(&acquired) || record.LeaderTransitions != 3 {
+		t.Errorf("expected acquire time and transitions kept by renew, got %+v", record)
+	}
+	if !record.RenewTime.After(acquired.Time) {
+		t.Errorf("expected renew time updated, got %v", record.RenewTime)
+	}
+}
+
+func TestObservedRecord(t *testing.T) {
+	fl := &fakeLock{}
+	le1 := newElector("c1", fl)
+	le2 := newElector("c2", fl)
+
+	if !le1.tryAcquireOrRenew() {
+		t.Fatalf("expected c1 to acquire the new lock")
+	}
+
+	// The record written by c1 is equal to the one read back, so c1 does not
+	// take its own record as changed.
+	if !recordEqual(fl.record(t), &le1.observedRecord) {
+		t.Errorf("expected record of c1 equal to the one in lock")
+	}
+
+	// c2 observes the record of c1; the lease is not extended if it's not
+	// changed, but restarts once c1 renewed it.
+	le2.tryAcquireOrRenew()
+	le2.observedTime = time.Now().Add(-DefaultLeaseDuration)
+
+	renewed := *fl.record(t)
+	renewed.RenewTime = metav1.NewTime(renewed.RenewTime.Add(time.Second))
+	cl := &candidateLock{fakeLock: fl}
+	cl.Get()
+	if err := cl.Update(renewed); err != nil {
+		t.Fatalf("failed to renew record of c1: %v", err)
+	}
+	if le2.tryAcquireOrRenew() {
+		t.Errorf("expected c2 not to acquire the lock renewed by c1")
+	}
+
+	le2.observedTime = time.Now().Add(-DefaultLeaseDuration)
+	if !le2.tryAcquireOrRenew() {
+		t.Errorf("expected c2 to acquire the lock not renewed in lease duration")
+	}
+}
+
+func TestLostLeadership(t *testing.T) {
+	fl := &fakeLock{}
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	stoppedLeading := false
+
+	config := &Config{
+		Namespace:     "kube-system",
+		Name:          "test",
+		Identity:      "c1",
+		LeaseDuration: 100 * time.Millisecond,
+		RenewDeadline: 50 * time.Millisecond,
+		RetryPeriod:   10 * time.Millisecond,
+		OnStartedLeading: func(stopCh <-chan struct{}) {
+			close(started)
+			<-stopCh
+			close(stopped)
+		},
+		OnStoppedLeading: func() {
+			stoppedLeading = true
+		},
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(config, &candidateLock{fakeLock: fl})
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timeout waiting for leadership")
+	}
+
+	fl.Lock()
+	fl.err = fmt.Errorf("apiserver unavailable")
+	fl.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timeout waiting for leadership lost")
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected stopCh of leader closed")
+	}
+	if !stoppedLeading {
+		t.Errorf("expected OnStoppedLeading called")
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"encoding/json"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// lock stores the record of leader.
+type lock interface {
+	// Get returns the record in lock, or nil if the lock is not found.
+	Get() (*LeaderElectionRecord, error)
+
+	// Create creates the lock with record.
+	Create(record LeaderElectionRecord) error
+
+	// Update updates the record in lock; it fails if the lock was updated
+	// since the last Get, e.g. by other candidate.
+	Update(record LeaderElectionRecord) error
+}
+
+// configMapLock stores the record in the annotation of a ConfigMap.
+type configMapLock struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+
+	// cm is the ConfigMap of last Get, whose ResourceVersion guards Update.
+	cm *v1.ConfigMap
+}
+
+func newConfigMapLock(client kubernetes.Interface, namespace, name string) *configMapLock {
+	return &configMapLock{
+		client:    client,
+		namespace: namespace,
+		name:      name,
+	}
+}
+
+func (cl *configMapLock) Get() (*LeaderElectionRecord, error) {
+	cm, err := cl.client.CoreV1().ConfigMaps(cl.namespace).Get(cl.name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	cl.cm = cm
+
+	record := &LeaderElectionRecord{}
+	if value, found := cm.Annotations[LeaderElectionRecordAnnotationKey]; found {
+		if err := json.Unmarshal([]byte(value), record); err != nil {
+			return nil, err
+		}
+	}
+
+	return record, nil
+}
+
+func (cl *configMapLock) Create(record LeaderElectionRecord) error {
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cl.namespace,
+			Name:      cl.name,
+		},
+	}
+	if err := setRecord(cm, &record); err != nil {
+		return err
+	}
+
+	cm, err := cl.client.CoreV1().ConfigMaps(cl.namespace).Create(cm)
+	if err != nil {
+		return err
+	}
+	cl.cm = cm
+
+	return nil
+}
+
+func (cl *configMapLock) Update(record LeaderElectionRecord) error {
+	if cl.cm == nil {
+		return apierrors.NewNotFound(v1.Resource("configmaps"), cl.name)
+	}
+
+	cm := cl.cm.DeepCopy()
+	if err := setRecord(cm, &record); err != nil {
+		return err
+	}
+
+	cm, err := cl.client.CoreV1().ConfigMaps(cl.namespace).Update(cm)
+	if err != nil {
+		return err
+	}
+	cl.cm = cm
+
+	return nil
+}
+
+func setRecord(cm *v1.ConfigMap, record *LeaderElectionRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if cm.Annotations == nil {
+		cm.Annotations = map[string]string{}
+	}
+	cm.Annotations[LeaderElectionRecordAnnotationKey] = string(data)
+
+	return nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logging is a structured logger on top of glog; the message is
+// followed by key/value pairs, e.g.
+//
+//	logging.V(3).Info("Bind task", "job", job.UID, "task", task.Name, "node", hostname)
+//
+// is written as `Bind task job=<uid> task=<name> node=<hostname>`, so logs can
+// be filtered by job, task, node or action.
+package logging
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// Verbose is the logger of V level, which is enabled if the level is not more
+// than -v flag.
+type Verbose bool
+
+// V returns the logger of level. As glog.V is called from this package, the
+// -vmodule flag does not work for the logs by V.
+func V(level glog.Level) Verbose {
+	return Verbose(glog.V(level))
+}
+
+// Info logs msg with key/value pairs if v is enabled.
+func (v Verbose) Info(msg string, keysAndValues ...interface{}) {
+	if v {
+		glog.InfoDepth(1, format(msg, keysAndValues))
+	}
+}
+
+// Info logs msg with key/value pairs.
+func Info(msg string, keysAndValues ...interface{}) {
+	glog.InfoDepth(1, format(msg, keysAndValues))
+}
+
+// Warning logs msg with key/value pairs as warning.
+func Warning(msg string, keysAndValues ...interface{}) {
+	glog.WarningDepth(1, format(msg, keysAndValues))
+}
+
+// Error logs msg with key/value pairs, and err if it's not nil, as error.
+func Error(err error, msg string, keysAndValues ...interface{}) {
+	if err != nil {
+		keysAndValues = append(keysAndValues, "err", err)
+	}
+	glog.ErrorDepth(1, format(msg, keysAndValues))
+}
+
+// Flush flushes all pending log I/O.
+func Flush() {
+	glog.Flush()
+}
+
+func format(msg string, keysAndValues []interface{}) string {
+	var buf bytes.Buffer
+	buf.WriteString(msg)
+
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var value interface{} = "(MISSING)"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		fmt.Fprintf(&buf, " %v=%s", keysAndValues[i], formatValue(value))
+	}
+
+	return buf.String()
+}
+
+// formatValue quotes the value if it's empty or has spaces, so key/value
+// pairs can be split by spaces.
+func formatValue(value interface{}) string {
+	str := fmt.Sprintf("%v", value)
+	if len(str) == 0 || strings.ContainsAny(str, " \t\n\"=") {
+		return strconv.Quote(str)
+	}
+	return str
+}
+
+// VerbosityHandler returns the HTTP handler of -v flag: GET returns the current
+// verbosity, and PUT sets it by the request body, e.g.
+// `curl -X PUT -d 4 http://<address>/debug/flags/v`.
+func VerbosityHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := flag.Lookup("v")
+		if v == nil {
+			http.Error(w, "flag v is not registered", http.StatusInternalServerError)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			level := strings.TrimSpace(string(body))
+			if _, err := strconv.ParseInt(level, 10, 32); err != nil {
+				http.Error(w, fmt.Sprintf("invalid verbosity %q: %v", level, err), http.StatusBadRequest)
+				return
+			}
+			if err := v.Value.Set(level); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			glog.Infof("Set verbosity to %s", level)
+		default:
+			http.Error(w, "only GET and PUT are allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		fmt.Fprintln(w, v.Value.String())
+	})
+}
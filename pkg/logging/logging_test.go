@@ -0,0 +1,50 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		msg           string
+		keysAndValues []interface{}
+		expected      string
+	}{
+		{
+			msg:      "Start scheduling",
+			expected: "Start scheduling",
+		},
+		{
+			msg:           "Bind task to node",
+			keysAndValues: []interface{}{"task", "uid-1", "node", "n1", "nodes", 3},
+			expected:      "Bind task to node task=uid-1 node=n1 nodes=3",
+		},
+		{
+			msg:           "Job is unschedulable",
+			keysAndValues: []interface{}{"name", "", "reason", `0/1 nodes are available: 1 Insufficient cpu.`, "job"},
+			expected:      `Job is unschedulable name="" reason="0/1 nodes are available: 1 Insufficient cpu." job=(MISSING)`,
+		},
+	}
+
+	for i, test := range tests {
+		if got := format(test.msg, test.keysAndValues); got != test.expected {
+			t.Errorf("case %d: expected: %s, got: %s", i, test.expected, got)
+		}
+	}
+}
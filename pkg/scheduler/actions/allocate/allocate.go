@@ -17,15 +17,22 @@ limitations under the License.
 package allocate
 
 import (
-	"github.com/golang/glog"
+	"sort"
 
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/metrics"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/util"
 )
 
 type allocateAction struct {
 	ssn *framework.Session
+
+	// nextStartNodeIndex is the index of node to start searching feasible
+	// nodes for next task, so all nodes are checked evenly if the search
+	// stops early.
+	nextStartNodeIndex int
 }
 
 func New() *allocateAction {
@@ -39,16 +46,12 @@ func (alloc *allocateAction) Name() string {
 func (alloc *allocateAction) Initialize() {}
 
 func (alloc *allocateAction) Execute(ssn *framework.Session) {
-	glog.V(3).Infof("Enter Allocate ...")
-	defer glog.V(3).Infof("Leaving Allocate ...")
-
-	jobs := util.NewPriorityQueue(ssn.JobOrderFn)
+	logging.V(3).Info("Enter action", "action", alloc.Name())
+	defer logging.V(3).Info("Leave action", "action", alloc.Name())
 
-	for _, job := range ssn.Jobs {
-		jobs.Push(job)
-	}
+	jobs := ssn.JobQueue()
 
-	glog.V(3).Infof("Try to allocate resource to %d Jobs", jobs.Len())
+	logging.V(3).Info("Try to allocate resource to jobs", "jobs", jobs.Len())
 
 	pendingTasks := map[api.JobID]*util.PriorityQueue{}
 
@@ -68,41 +71,50 @@ func (alloc *allocateAction) Execute(ssn *framework.Session) {
 		}
 		tasks := pendingTasks[job.UID]
 
-		glog.V(3).Infof("Try to allocate resource to %d tasks of Job <%v:%v>",
-			tasks.Len(), job.UID, job.Name)
+		logging.V(3).Info("Try to allocate resource to tasks",
+			"job", job.UID, "name", job.Name, "tasks", tasks.Len())
 
 		for !tasks.Empty() {
 			task := tasks.Pop().(*api.TaskInfo)
 
 			assigned := false
 
-			// If candidates is nil, it means all nodes.
+			// If candidates is nil, it means all nodes, which are shortlisted
+			// by idle resources.
 			// If candidates is empty, it means none.
 			nodes := job.Candidates
+			numNodesToFind := framework.NumFeasibleNodesToFind(len(nodes))
 			if nodes == nil {
-				nodes = ssn.Nodes
+				nodes = ssn.NodesWithIdle(task.Resreq)
+				numNodesToFind = framework.NumFeasibleNodesToFind(len(ssn.Nodes))
 			}
 
-			glog.V(3).Infof("there are <%d> nodes for Job <%v:%v>", len(nodes), job.UID, job.Name)
-
-			for _, node := range nodes {
-				glog.V(3).Infof("Considering Task <%v/%v> on node <%v>: <%v> vs. <%v>",
-					task.Job, task.UID, node.Name, task.Resreq, node.Idle)
-				if task.Resreq.LessEqual(node.Idle) {
-					glog.V(3).Infof("binding Task <%v/%v> to node <%v>",
-						task.Job, task.UID, node.Name)
-					if err := ssn.Bind(task, node.Name); err != nil {
-						glog.Errorf("Failed to bind Task %v on %v in Session %v",
-							task.UID, node.Name, ssn.ID)
-						continue
-					}
-					assigned = true
-					break
+			logging.V(3).Info("Got nodes for job", "job", job.UID, "name", job.Name, "nodes", len(nodes))
+
+			fitErrors := api.NewFitErrors(task, len(ssn.Nodes))
+			fitErrors.SetCandidateErrors(ssn.Nodes, job.Candidates)
+
+			for _, node := range alloc.feasibleNodes(ssn, task, nodes, numNodesToFind, fitErrors) {
+				logging.V(3).Info("Bind task to node", "job", task.Job, "task", task.UID, "node", node.Name)
+				if err := ssn.Bind(task, node.Name); err != nil {
+					logging.Error(err, "Failed to bind task to node",
+						"task", task.UID, "node", node.Name, "session", ssn.ID)
+					continue
 				}
+				assigned = true
+				break
 			}
 
 			if assigned {
 				jobs.Push(job)
+			} else {
+				// The nodes out of the shortlist are not checked, but their
+				// reasons are reported too.
+				if job.Candidates == nil {
+					setShortlistErrors(ssn, task, fitErrors)
+				}
+				metrics.UpdateScheduleAttempts(metrics.UnschedulableResult)
+				ssn.JobUnschedulable(job, fitErrors)
 			}
 
 			// Handle one pending task in each loop.
@@ -111,4 +123,52 @@ func (alloc *allocateAction) Execute(ssn *framework.Session) {
 	}
 }
 
+// feasibleNodes returns the nodes which can fit task, ordered by score; it
+// stops searching once numNodesToFind nodes passing the filters are found.
+// The reasons of the nodes which can not fit task are set in fitErrors.
+func (alloc *allocateAction) feasibleNodes(ssn *framework.Session, task *api.TaskInfo,
+	nodes []*api.NodeInfo, numNodesToFind int, fitErrors *api.FitErrors) []*api.NodeInfo {
+	// Always start from the first node if all nodes are searched.
+	start := 0
+	if numNodesToFind < len(nodes) {
+		start = alloc.nextStartNodeIndex % len(nodes)
+	}
+
+	feasible, processed := ssn.FeasibleNodes(task, nodes, start, numNodesToFind, func(node *api.NodeInfo) bool {
+		logging.V(3).Info("Considering task on node", "job", task.Job, "task", task.UID,
+			"node", node.Name, "request", task.Resreq, "idle", node.Idle)
+		if task.Resreq.LessEqual(node.Idle) {
+			return true
+		}
+		fitErrors.SetNodeError(node.Name, api.InsufficientReasons(task.Resreq, node.Idle)...)
+		return false
+	}, fitErrors)
+	if len(nodes) != 0 {
+		alloc.nextStartNodeIndex = (start + processed) % len(nodes)
+	}
+
+	scores := ssn.ScoreNodes(task, feasible)
+	sort.SliceStable(feasible, func(i, j int) bool {
+		return scores[feasible[i].Name] > scores[feasible[j].Name]
+	})
+
+	logging.V(4).Info("Found feasible nodes for task", "job", task.Job, "task", task.UID,
+		"feasible", len(feasible), "processed", processed, "nodes", len(nodes))
+
+	return feasible
+}
+
+// setShortlistErrors sets the reasons of the nodes which are not shortlisted
+// for task, as they're not checked by feasibleNodes.
+func setShortlistErrors(ssn *framework.Session, task *api.TaskInfo, fitErrors *api.FitErrors) {
+	for _, node := range ssn.Nodes {
+		if _, found := fitErrors.FailedNodes[node.Name]; found {
+			continue
+		}
+		if reasons := api.InsufficientReasons(task.Resreq, node.Idle); len(reasons) != 0 {
+			fitErrors.SetNodeError(node.Name, reasons...)
+		}
+	}
+}
+
 func (alloc *allocateAction) UnInitialize() {}
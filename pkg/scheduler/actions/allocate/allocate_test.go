@@ -124,6 +124,25 @@ func (fb *fakeBinder) Bind(p *v1.Pod, hostname string) error {
 	return nil
 }
 
+type fakeStatusUpdater struct{}
+
+func (fsu *fakeStatusUpdater) UpdateSchedulingSpec(ss *arbv1.SchedulingSpec) error {
+	return nil
+}
+
+func (fsu *fakeStatusUpdater) UpdatePodCondition(pod *v1.Pod, condition *v1.PodCondition) error {
+	return nil
+}
+
+func (fsu *fakeStatusUpdater) UpdatePodNominatedNode(pod *v1.Pod, nodeName string) error {
+	return nil
+}
+
+type fakeRecorder struct{}
+
+func (fr *fakeRecorder) Eventf(ref *v1.ObjectReference, eventType, reason, messageFmt string, args ...interface{}) {
+}
+
 func TestAllocate(t *testing.T) {
 	owner1 := buildOwnerReference("owner1")
 	owner2 := buildOwnerReference("owner2")
@@ -205,9 +224,11 @@ func TestAllocate(t *testing.T) {
 			c:     make(chan string),
 		}
 		schedulerCache := &cache.SchedulerCache{
-			Nodes:  make(map[string]*api.NodeInfo),
-			Jobs:   make(map[api.JobID]*api.JobInfo),
-			Binder: binder,
+			Nodes:         make(map[string]*api.NodeInfo),
+			Jobs:          make(map[api.JobID]*api.JobInfo),
+			Binder:        binder,
+			StatusUpdater: &fakeStatusUpdater{},
+			Recorder:      &fakeRecorder{},
 		}
 		for _, node := range test.nodes {
 			schedulerCache.AddNode(node)
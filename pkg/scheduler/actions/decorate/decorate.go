@@ -17,9 +17,7 @@ limitations under the License.
 package decorate
 
 import (
-	"k8s.io/apimachinery/pkg/labels"
-
-	"github.com/golang/glog"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
 	arbapi "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
 )
@@ -39,37 +37,26 @@ func (alloc *decorateAction) Name() string {
 func (alloc *decorateAction) Initialize() {}
 
 func (alloc *decorateAction) Execute(ssn *framework.Session) {
-	glog.V(3).Infof("Enter Decorate ...")
-	defer glog.V(3).Infof("Leaving Decorate ...")
+	logging.V(3).Info("Enter action", "action", alloc.Name())
+	defer logging.V(3).Info("Leave action", "action", alloc.Name())
 
 	// fetch the nodes that match PodSet NodeSelector and NodeAffinity
 	// and store it for following DRF assignment
-	jobs := ssn.Jobs
-	nodes := ssn.Nodes
-
-	for _, job := range jobs {
-		job.Candidates = fetchMatchNodeForPodSet(job, nodes)
-		glog.V(3).Infof("Got %d candidate nodes for QueueJob %v", len(job.Candidates), job.UID)
+	for _, job := range ssn.Jobs {
+		job.Candidates = fetchMatchNodeForPodSet(ssn, job)
+		logging.V(3).Info("Got candidate nodes", "job", job.UID, "nodes", len(job.Candidates))
 	}
 }
 
 func (alloc *decorateAction) UnInitialize() {}
 
-func fetchMatchNodeForPodSet(job *arbapi.JobInfo, nodes []*arbapi.NodeInfo) []*arbapi.NodeInfo {
+func fetchMatchNodeForPodSet(ssn *framework.Session, job *arbapi.JobInfo) []*arbapi.NodeInfo {
 	if len(job.NodeSelector) == 0 {
 		// nil slice means select everything.
 		return nil
 	}
 
-	// Empty slice means no object selected.
-	matchNodes := []*arbapi.NodeInfo{}
-	selector := labels.SelectorFromSet(labels.Set(job.NodeSelector))
-
-	for _, node := range nodes {
-		if selector.Matches(labels.Set(node.Node.Labels)) {
-			matchNodes = append(matchNodes, node)
-		}
-	}
-
-	return matchNodes
+	// Empty slice means no object selected; the nodes are found by the label
+	// index of session instead of matching every node.
+	return ssn.NodesWithLabels(job.NodeSelector)
 }
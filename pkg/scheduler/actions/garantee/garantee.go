@@ -17,19 +17,65 @@ limitations under the License.
 package garantee
 
 import (
-	"github.com/golang/glog"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"k8s.io/api/core/v1"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/metrics"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/util"
 )
 
+const (
+	// GangTimeoutReason is the reason of evicting the tasks of a gang which
+	// was partially allocated longer than GangTimeout.
+	GangTimeoutReason = "GangTimeout"
+	// GangDeadlockReason is the reason of evicting the tasks of a partially
+	// allocated gang, which held the resources needed by another one while
+	// waiting for the resources held by it.
+	GangDeadlockReason = "GangDeadlock"
+)
+
+// GangTimeout is the maximal time a gang holds the resources of part of its
+// minAvailable tasks while the rest can not be placed; then the tasks of the
+// part are evicted, so two partially allocated gangs do not wait for each
+// other forever, and the gang is placed again as a whole. 0 disables it.
+var GangTimeout time.Duration
+
+// ReleaseRunning is whether the bound and running tasks of a started gang,
+// i.e. whose minAvailable tasks have been ready together, are released too if
+// it's partially allocated again. By default they're not, so a running gang
+// degraded by a failed or recreated task, whose replacement does not fit,
+// keeps its running tasks; the gangs not started yet are released anyway.
+var ReleaseRunning bool
+
+// ResolveDeadlock is whether the stuck gangs which wait for the resources held
+// by each other are released in every session without waiting for
+// GangTimeout.
+var ResolveDeadlock bool
+
 type garanteeAction struct {
 	ssn *framework.Session
+
+	// partialSince is when the gangs were found partially allocated and not
+	// fit, kept across sessions.
+	partialSince map[api.JobID]time.Time
+	// started is the gangs whose minAvailable tasks have been ready
+	// together, kept across sessions.
+	started map[api.JobID]bool
 }
 
 func New() *garanteeAction {
-	return &garanteeAction{}
+	return &garanteeAction{
+		partialSince: map[api.JobID]time.Time{},
+		started:      map[api.JobID]bool{},
+	}
 }
 
 func (alloc *garanteeAction) Name() string {
@@ -39,14 +85,29 @@ func (alloc *garanteeAction) Name() string {
 func (alloc *garanteeAction) Initialize() {}
 
 func (alloc *garanteeAction) Execute(ssn *framework.Session) {
-	glog.V(3).Infof("Enter Garantee ...")
-	defer glog.V(3).Infof("Leaving Garantee ...")
+	logging.V(3).Info("Enter action", "action", alloc.Name())
+	defer logging.V(3).Info("Leave action", "action", alloc.Name())
 
 	jobs := ssn.Jobs
 
+	for _, job := range jobs {
+		if gangStarted(job) {
+			alloc.started[job.UID] = true
+		}
+	}
+	for uid := range alloc.started {
+		if _, found := ssn.JobIndex[uid]; !found {
+			delete(alloc.started, uid)
+		}
+	}
+
+	// The partially allocated gangs which can not be placed in this session.
+	partial := map[api.JobID]bool{}
+	var stuck []*stuckGang
+
 	for _, job := range jobs {
 		if len(job.TaskStatusIndex[api.Pending]) == 0 {
-			glog.V(3).Infof("No pending tasks in Job <%v>", job.UID)
+			logging.V(3).Info("No pending tasks in job", "job", job.UID)
 			continue
 		}
 
@@ -55,87 +116,369 @@ func (alloc *garanteeAction) Execute(ssn *framework.Session) {
 			tasks.Push(task)
 		}
 
-		start := 0
-		for status, tasks := range job.TaskStatusIndex {
-			// Also include succeeded task.
-			if api.OccupiedResources(status) || status == api.Succeeded {
-				start = start + len(tasks)
-			}
-		}
+		// Also include succeeded task.
+		start := job.ReadyTaskNum()
 
 		if job.MinAvailable < start {
-			glog.V(3).Infof("QueueJob %v already starts enough Tasks (min %v, start %v).",
-				job.Name, job.MinAvailable, start)
+			logging.V(3).Info("Job already starts enough tasks",
+				"job", job.UID, "name", job.Name, "minAvailable", job.MinAvailable, "started", start)
 			continue
 		}
 
 		if tasks.Len() < job.MinAvailable-start {
-			glog.V(3).Infof("Not enough pending tasks %v in QueueJob %v to start (min %v, occupied %v).",
-				tasks.Len(), job.Name, job.MinAvailable, start)
+			logging.V(3).Info("Not enough pending tasks in job to start", "job", job.UID, "name", job.Name,
+				"pending", tasks.Len(), "minAvailable", job.MinAvailable, "started", start)
 			continue
 		}
 
-		binds := map[api.TaskID]string{}
-		allocates := map[string]*api.Resource{}
+		// The tasks to start together in order.
+		gang := make([]*api.TaskInfo, 0, job.MinAvailable-start)
+		for len(gang) < job.MinAvailable-start {
+			gang = append(gang, tasks.Pop().(*api.TaskInfo))
+		}
+
+		nodes := job.Candidates
+		// If candidate list is nil, it means all nodes.
+		if job.Candidates == nil {
+			nodes = ssn.Nodes
+		}
+
+		// The gang is not tried node by node if the idle resources of all
+		// its nodes are not enough, e.g. for GPU jobs in a busy cluster.
+		if fitErrors := checkMinResources(ssn, job, gang, nodes, nodeIdle); fitErrors != nil {
+			if waitingForReleasing(ssn, job, gang, nodes) {
+				logging.V(3).Info("Wait for releasing resources for tasks in job to start", "job", job.UID,
+					"name", job.Name, "tasks", len(gang))
+				ssn.ForgetJob(job)
+				continue
+			}
+			logging.V(3).Info("Not enough idle resources for tasks in job to start", "job", job.UID,
+				"name", job.Name, "tasks", len(gang), "reason", fitErrors.Error())
+			for range job.TaskStatusIndex[api.Pending] {
+				metrics.UpdateScheduleAttempts(metrics.UnschedulableResult)
+			}
+			ssn.JobUnschedulable(job, fitErrors)
+			if g := alloc.stuck(ssn, job, gang, nodes); g != nil {
+				partial[job.UID] = true
+				stuck = append(stuck, g)
+			}
+			ssn.ForgetJob(job)
+			continue
+		}
 
-		glog.V(3).Infof("Try to allocate resource to <%d> Tasks of Job <%s:%s>",
-			job.MinAvailable-start, job.UID, job.Name)
+		logging.V(3).Info("Try to allocate resource to tasks",
+			"job", job.UID, "name", job.Name, "tasks", len(gang))
 
-		for ; start < job.MinAvailable; start++ {
-			task := tasks.Pop().(*api.TaskInfo)
+		binds, fitErrors := assignGang(ssn, job, gang, nodes, nodeIdle)
 
-			if task == nil {
-				break
+		// Got enough occupied, bind them all.
+		if binds != nil {
+			for _, task := range gang {
+				host := binds[task.UID]
+				ssn.Bind(task, host)
+				logging.V(3).Info("Bind task to node", "job", task.Job, "task", task.UID, "node", host)
 			}
+			continue
+		}
 
-			assigned := false
+		// If job can not get enough resource, forget it for following
+		// actions. The gang which fits once the releasing tasks are deleted
+		// is not unschedulable, but waits for them, so it's not reported
+		// unschedulable and preempted for again in every session until then.
+		if waitingForReleasing(ssn, job, gang, nodes) {
+			logging.V(3).Info("Wait for releasing resources for tasks in job to start", "job", job.UID,
+				"name", job.Name, "tasks", len(gang))
+			ssn.ForgetJob(job)
+			continue
+		}
+		for range job.TaskStatusIndex[api.Pending] {
+			metrics.UpdateScheduleAttempts(metrics.UnschedulableResult)
+		}
+		if fitErrors != nil {
+			ssn.JobUnschedulable(job, fitErrors)
+		}
+		if g := alloc.stuck(ssn, job, gang, nodes); g != nil {
+			partial[job.UID] = true
+			stuck = append(stuck, g)
+		}
+		ssn.ForgetJob(job)
+	}
+
+	if ResolveDeadlock {
+		for _, g := range resolveDeadlock(ssn, stuck) {
+			partial[g.job.UID] = false
+		}
+	}
 
-			nodes := job.Candidates
-			// If candidate list is nil, it means all nodes.
-			if job.Candidates == nil {
-				nodes = ssn.Nodes
+	// The gangs not stuck in this session are not timed any more.
+	for uid := range alloc.partialSince {
+		if !partial[uid] {
+			delete(alloc.partialSince, uid)
+		}
+	}
+}
+
+// stuckGang is the gang of a job which holds the resources of part of its
+// minAvailable tasks, while the rest can not be placed.
+type stuckGang struct {
+	job      *api.JobInfo
+	gang     []*api.TaskInfo
+	nodes    []*api.NodeInfo
+	occupied []*api.TaskInfo
+}
+
+// stuck returns the stuck gang of job if it holds resources for part of its
+// tasks, nil if not; the tasks holding resources are evicted instead if it's
+// been stuck for GangTimeout, so the job is placed again as a whole once
+// they're deleted. Only the tasks not bound yet are taken as holding
+// resources for a started gang, unless ReleaseRunning.
+func (alloc *garanteeAction) stuck(ssn *framework.Session, job *api.JobInfo, gang []*api.TaskInfo,
+	nodes []*api.NodeInfo) *stuckGang {
+	statuses := []api.TaskStatus{api.Allocated, api.Binding}
+	if ReleaseRunning || !alloc.started[job.UID] {
+		statuses = append(statuses, api.Bound, api.Running)
+	}
+	occupied := job.GetTasks(statuses...)
+	if len(occupied) == 0 {
+		return nil
+	}
+
+	since, found := alloc.partialSince[job.UID]
+	if !found {
+		since = time.Now()
+		alloc.partialSince[job.UID] = since
+	}
+	if GangTimeout <= 0 || time.Since(since) < GangTimeout {
+		return &stuckGang{job: job, gang: gang, nodes: nodes, occupied: occupied}
+	}
+
+	logging.Info("Release tasks of partially allocated job", "job", job.UID, "name", job.Name,
+		"tasks", len(occupied), "minAvailable", job.MinAvailable, "since", since)
+	release(ssn, occupied, GangTimeoutReason)
+	return nil
+}
+
+// gangStarted returns whether the minAvailable tasks of job are ready, or
+// were ready by the Scheduled condition of its SchedulingSpec, e.g. before
+// the scheduler restarted.
+func gangStarted(job *api.JobInfo) bool {
+	if job.MinAvailable > 0 && job.ReadyTaskNum() >= job.MinAvailable {
+		return true
+	}
+	if job.SchedSpec == nil {
+		return false
+	}
+	for _, c := range job.SchedSpec.Status.Conditions {
+		if c.Type == arbv1.SchedulingSpecScheduled && c.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveDeadlock releases the stuck gangs which hold the resources needed by
+// another stuck gang, while they need the resources held by it, e.g. two gangs
+// each holding half of a cluster. It's resolved by job order: the first gang
+// which fits once the gangs after it are released is kept, and the fewest
+// gangs from the last are released; it returns the released gangs.
+func resolveDeadlock(ssn *framework.Session, stuck []*stuckGang) []*stuckGang {
+	if len(stuck) < 2 {
+		return nil
+	}
+
+	sort.SliceStable(stuck, func(i, j int) bool {
+		return ssn.JobOrderFn(stuck[i].job, stuck[j].job)
+	})
+
+	for i, winner := range stuck {
+		for j := len(stuck) - 1; j > i; j-- {
+			losers := stuck[j:]
+			if !fitsReleasing(ssn, winner, losers) || !waitsFor(ssn, losers, winner) {
+				continue
 			}
 
-			for _, node := range nodes {
-				currentIdle := node.Idle.Clone()
-
-				if alloc, found := allocates[node.Name]; found {
-					currentIdle.Sub(alloc)
-				}
-
-				glog.V(3).Infof("Considering Task <%v/%v> on node <%v>: <%v> vs. <%v>",
-					task.Job, task.UID, node.Name, task.Resreq, currentIdle)
-
-				if task.Resreq.LessEqual(currentIdle) {
-					binds[task.UID] = node.Name
-					if _, found := allocates[node.Name]; !found {
-						allocates[node.Name] = api.EmptyResource()
-					}
-					allocates[node.Name].Add(task.Resreq)
-					assigned = true
-					break
-				}
+			names := make([]string, 0, len(losers))
+			for _, loser := range losers {
+				names = append(names, loser.job.Namespace+"/"+loser.job.Name)
+				logging.Info("Release tasks of job in deadlock", "job", loser.job.UID, "name", loser.job.Name,
+					"tasks", len(loser.occupied), "winner", winner.job.UID)
+				release(ssn, loser.occupied, GangDeadlockReason)
+				ssn.RecordJobEvent(loser.job, v1.EventTypeWarning, GangDeadlockReason, fmt.Sprintf(
+					"Released %d tasks, which held resources needed by job %s/%s while both waited for "+
+						"resources held by each other; the job is placed again as a whole",
+					len(loser.occupied), winner.job.Namespace, winner.job.Name))
 			}
+			ssn.RecordJobEvent(winner.job, v1.EventTypeNormal, GangDeadlockReason, fmt.Sprintf(
+				"Released the tasks of jobs %s, which held resources needed by this job while waiting "+
+					"for resources held by it", strings.Join(names, ", ")))
+			return losers
+		}
+	}
+	return nil
+}
 
-			if !assigned {
-				break
+// fitsReleasing returns whether the gang of g fits once the releasing tasks
+// on nodes and the tasks of others holding resources are deleted.
+func fitsReleasing(ssn *framework.Session, g *stuckGang, others []*stuckGang) bool {
+	released := map[string]*api.Resource{}
+	for _, other := range others {
+		for _, task := range other.occupied {
+			if _, found := released[task.NodeName]; !found {
+				released[task.NodeName] = api.EmptyResource()
 			}
+			released[task.NodeName].Add(task.Resreq)
 		}
+	}
 
-		// Got enough occupied, bind them all.
-		if start >= job.MinAvailable {
-			for taskID, host := range binds {
-				task := job.Tasks[taskID]
-				ssn.Bind(task, host)
-				glog.V(3).Infof("Bind task <%v/%v> to host <%v>",
-					task.Namespace, task.Name, host)
+	idle := func(node *api.NodeInfo) *api.Resource {
+		idle := node.FutureIdle()
+		if r, found := released[node.Name]; found {
+			idle.Add(r)
+		}
+		return idle
+	}
+	binds, _ := assignGang(ssn, g.job, g.gang, g.nodes, idle)
+	return binds != nil
+}
+
+// waitsFor returns whether any of gangs fits once the tasks of g holding
+// resources are deleted, i.e. they wait for each other.
+func waitsFor(ssn *framework.Session, gangs []*stuckGang, g *stuckGang) bool {
+	for _, gang := range gangs {
+		if fitsReleasing(ssn, gang, []*stuckGang{g}) {
+			return true
+		}
+	}
+	return false
+}
+
+// release evicts tasks by reason.
+func release(ssn *framework.Session, tasks []*api.TaskInfo, reason string) {
+	for _, task := range tasks {
+		if err := ssn.Evict(task, reason); err != nil {
+			logging.Error(err, "Failed to evict task of partially allocated job",
+				"job", task.Job, "task", task.UID, "reason", reason)
+		}
+	}
+}
+
+// nodeIdle returns the idle resources of node.
+func nodeIdle(node *api.NodeInfo) *api.Resource {
+	return node.Idle
+}
+
+// assignGang returns the nodes of the tasks in gang by the resources of nodes
+// returned by idle; it returns nil and the errors of the first task which
+// does not fit, if any.
+func assignGang(ssn *framework.Session, job *api.JobInfo, gang []*api.TaskInfo, nodes []*api.NodeInfo,
+	idle func(node *api.NodeInfo) *api.Resource) (map[api.TaskID]string, *api.FitErrors) {
+	binds := map[api.TaskID]string{}
+	allocates := map[string]*api.Resource{}
+	// The GPU shares of the tasks assigned before, so a shared GPU is not
+	// over-booked by the tasks of gang.
+	shares := api.GPUShares{}
+
+	for _, task := range gang {
+		fitErrors := api.NewFitErrors(task, len(ssn.Nodes))
+		fitErrors.SetCandidateErrors(ssn.Nodes, job.Candidates)
+
+		// The nodes with enough resources are filtered by the nodes filter
+		// funcs, e.g. extenders, in batches; the first ones passed are used.
+		numNodesToFind := framework.NumFeasibleNodesToFind(len(nodes))
+		fitNodes, _ := ssn.FeasibleNodes(task, nodes, 0, numNodesToFind, func(node *api.NodeInfo) bool {
+			currentIdle := idle(node).Clone()
+
+			if alloc, found := allocates[node.Name]; found {
+				currentIdle.Sub(alloc)
 			}
-		} else {
-			// If job can not get enough resource, forget it for following
-			// actions.
-			ssn.ForgetJob(job)
+
+			logging.V(3).Info("Considering task on node", "job", task.Job, "task", task.UID,
+				"node", node.Name, "request", task.Resreq, "idle", currentIdle)
+
+			if !task.Resreq.LessEqual(currentIdle) {
+				fitErrors.SetNodeError(node.Name, api.InsufficientReasons(task.Resreq, currentIdle)...)
+				return false
+			}
+			if task.GPUShare != nil && node.SharesGPU() && shares.FitGPUShare(node, task.GPUShare) < 0 {
+				fitErrors.SetNodeError(node.Name, api.NodeGPUShareNotFit)
+				return false
+			}
+			return true
+		}, fitErrors)
+		if len(fitNodes) == 0 {
+			return nil, fitErrors
+		}
+
+		// The node of the highest score is used, e.g. by binpack.
+		scores := ssn.ScoreNodes(task, fitNodes)
+		node := fitNodes[0]
+		for _, n := range fitNodes[1:] {
+			if scores[n.Name] > scores[node.Name] {
+				node = n
+			}
+		}
+		binds[task.UID] = node.Name
+		if _, found := allocates[node.Name]; !found {
+			allocates[node.Name] = api.EmptyResource()
+		}
+		allocates[node.Name].Add(task.Resreq)
+		shares.Add(node, task.GPUShare)
+	}
+
+	return binds, nil
+}
+
+// waitingForReleasing returns whether gang, which does not fit the idle
+// resources of nodes, fits once the releasing tasks on nodes are deleted,
+// e.g. the victims of preemption or the tasks of a finished job.
+func waitingForReleasing(ssn *framework.Session, job *api.JobInfo, gang []*api.TaskInfo, nodes []*api.NodeInfo) bool {
+	releasing := false
+	for _, node := range nodes {
+		if !node.Releasing.IsEmpty() {
+			releasing = true
+			break
 		}
 	}
+	if !releasing {
+		return false
+	}
+
+	if checkMinResources(ssn, job, gang, nodes, (*api.NodeInfo).FutureIdle) != nil {
+		return false
+	}
+	binds, _ := assignGang(ssn, job, gang, nodes, (*api.NodeInfo).FutureIdle)
+	return binds != nil
 }
 
 func (alloc *garanteeAction) UnInitialize() {}
+
+// checkMinResources returns the errors of gang if the sum of its requests is
+// more than the resources of nodes returned by idle, nil if it may fit.
+func checkMinResources(ssn *framework.Session, job *api.JobInfo, gang []*api.TaskInfo, nodes []*api.NodeInfo,
+	idle func(node *api.NodeInfo) *api.Resource) *api.FitErrors {
+	minResources := api.EmptyResource()
+	for _, task := range gang {
+		minResources.Add(task.Resreq)
+	}
+
+	total := api.EmptyResource()
+	for _, node := range nodes {
+		// The idle resources of an overcommitted node are negative.
+		if !node.Overcommitted() {
+			total.Add(idle(node))
+		}
+	}
+
+	if minResources.LessEqual(total) {
+		return nil
+	}
+
+	fitErrors := api.NewFitErrors(gang[0], len(ssn.Nodes))
+	fitErrors.SetCandidateErrors(ssn.Nodes, job.Candidates)
+	reasons := api.InsufficientReasons(minResources, total)
+	for _, node := range nodes {
+		fitErrors.SetNodeError(node.Name, reasons...)
+	}
+
+	return fitErrors
+}
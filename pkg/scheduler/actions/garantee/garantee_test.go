@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -153,3 +154,283 @@ func TestExecute(t *testing.T) {
 
 	}
 }
+
+func buildPod(name string, node string, phase v1.PodPhase, req v1.ResourceList, owner metav1.OwnerReference) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:             types.UID("c1-" + name),
+			Name:            name,
+			Namespace:       "c1",
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Status: v1.PodStatus{
+			Phase: phase,
+		},
+		Spec: v1.PodSpec{
+			NodeName: node,
+			Containers: []v1.Container{
+				{Resources: v1.ResourceRequirements{Requests: req}},
+			},
+		},
+	}
+}
+
+func TestWaitForReleasing(t *testing.T) {
+	for _, releasing := range []bool{true, false} {
+		// The gang of j1 fits n1 once the task of j2 is deleted.
+		running := buildPod("p0", "n1", v1.PodRunning, buildResourceList("2", "2Gi"), buildOwnerReference("j2"))
+		if releasing {
+			running.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		}
+
+		schedulerCache := &cache.SchedulerCache{
+			Nodes: make(map[string]*api.NodeInfo),
+			Jobs:  make(map[api.JobID]*api.JobInfo),
+		}
+		schedulerCache.AddNode(buildNode("n1", buildResourceList("2", "4Gi"), nil))
+		schedulerCache.AddPod(running)
+		schedulerCache.AddPod(buildPod("p1", "", v1.PodPending, buildResourceList("1", "1Gi"), buildOwnerReference("j1")))
+		schedulerCache.AddPod(buildPod("p2", "", v1.PodPending, buildResourceList("1", "1Gi"), buildOwnerReference("j1")))
+		for _, owner := range []string{"j1", "j2"} {
+			schedulerCache.AddSchedulingSpec(&arbv1.SchedulingSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            owner,
+					Namespace:       "c1",
+					OwnerReferences: []metav1.OwnerReference{buildOwnerReference(owner)},
+				},
+				Spec: arbv1.SchedulingSpecTemplate{MinAvailable: 2},
+			})
+		}
+
+		ssn := framework.OpenSession(schedulerCache)
+		New().Execute(ssn)
+
+		if _, found := ssn.FitErrors["j1"]; found == releasing {
+			t.Errorf("expected unschedulable %v of j1 with releasing %v, got %v", !releasing, releasing, ssn.FitErrors["j1"])
+		}
+		if _, found := ssn.JobIndex["j1"]; !found || len(ssn.JobIndex["j1"].TaskStatusIndex[api.Pending]) != 2 {
+			t.Errorf("expected tasks of j1 pending with releasing %v", releasing)
+		}
+		for _, job := range ssn.Jobs {
+			if job.UID == "j1" {
+				t.Errorf("expected j1 forgotten with releasing %v", releasing)
+			}
+		}
+	}
+}
+
+type fakeEvictor struct {
+	c chan string
+}
+
+func (fe *fakeEvictor) Evict(p *v1.Pod) error {
+	fe.c <- p.Namespace + "/" + p.Name
+	return nil
+}
+
+type fakeBinder struct {
+	c chan string
+}
+
+func (fb *fakeBinder) Bind(p *v1.Pod, hostname string) error {
+	fb.c <- p.Namespace + "/" + p.Name
+	return nil
+}
+
+type fakeRecorder struct{}
+
+func (fr *fakeRecorder) Eventf(ref *v1.ObjectReference, eventType, reason, messageFmt string, args ...interface{}) {
+}
+
+func TestGangTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { GangTimeout = timeout }(GangTimeout)
+	GangTimeout = time.Minute
+
+	// The gang of j1 holds n1 by p1, but p2 does not fit the rest of it.
+	evictor := &fakeEvictor{c: make(chan string, 1)}
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:    make(map[string]*api.NodeInfo),
+		Jobs:     make(map[api.JobID]*api.JobInfo),
+		Evictor:  evictor,
+		Recorder: &fakeRecorder{},
+	}
+	schedulerCache.AddNode(buildNode("n1", buildResourceList("2", "4Gi"), nil))
+	schedulerCache.AddPod(buildPod("p1", "n1", v1.PodRunning, buildResourceList("1", "1Gi"), buildOwnerReference("j1")))
+	schedulerCache.AddPod(buildPod("p2", "", v1.PodPending, buildResourceList("2", "1Gi"), buildOwnerReference("j1")))
+	schedulerCache.AddSchedulingSpec(&arbv1.SchedulingSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "j1",
+			Namespace:       "c1",
+			OwnerReferences: []metav1.OwnerReference{buildOwnerReference("j1")},
+		},
+		Spec: arbv1.SchedulingSpecTemplate{MinAvailable: 2},
+	})
+
+	garantee := New()
+	garantee.Execute(framework.OpenSession(schedulerCache))
+	if _, found := garantee.partialSince["j1"]; !found {
+		t.Fatalf("expected j1 timed as partially allocated")
+	}
+	select {
+	case key := <-evictor.c:
+		t.Fatalf("expected no task evicted before timeout, got %s", key)
+	default:
+	}
+
+	garantee.partialSince["j1"] = time.Now().Add(-GangTimeout)
+	garantee.Execute(framework.OpenSession(schedulerCache))
+	select {
+	case key := <-evictor.c:
+		if key != "c1/p1" {
+			t.Errorf("expected c1/p1 evicted, got %s", key)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("expected the task of j1 evicted after timeout")
+	}
+	if _, found := garantee.partialSince["j1"]; found {
+		t.Errorf("expected j1 not timed after its tasks evicted")
+	}
+}
+
+func TestDegradedRunningGang(t *testing.T) {
+	defer func(timeout time.Duration) { GangTimeout = timeout }(GangTimeout)
+	GangTimeout = time.Minute
+
+	evictor := &fakeEvictor{c: make(chan string, 1)}
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:    make(map[string]*api.NodeInfo),
+		Jobs:     make(map[api.JobID]*api.JobInfo),
+		Evictor:  evictor,
+		Recorder: &fakeRecorder{},
+	}
+	schedulerCache.AddNode(buildNode("n1", buildResourceList("2", "4Gi"), nil))
+	p2 := buildPod("p2", "n1", v1.PodRunning, buildResourceList("1", "1Gi"), buildOwnerReference("j1"))
+	schedulerCache.AddPod(buildPod("p1", "n1", v1.PodRunning, buildResourceList("1", "1Gi"), buildOwnerReference("j1")))
+	schedulerCache.AddPod(p2)
+	schedulerCache.AddSchedulingSpec(&arbv1.SchedulingSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "j1",
+			Namespace:       "c1",
+			OwnerReferences: []metav1.OwnerReference{buildOwnerReference("j1")},
+		},
+		Spec: arbv1.SchedulingSpecTemplate{MinAvailable: 2},
+	})
+
+	// The gang of j1 started, then p2 is recreated as p3, which does not fit.
+	garantee := New()
+	garantee.Execute(framework.OpenSession(schedulerCache))
+	schedulerCache.DeletePod(p2)
+	schedulerCache.AddPod(buildPod("p3", "", v1.PodPending, buildResourceList("2", "1Gi"), buildOwnerReference("j1")))
+
+	garantee.partialSince["j1"] = time.Now().Add(-GangTimeout)
+	garantee.Execute(framework.OpenSession(schedulerCache))
+	select {
+	case key := <-evictor.c:
+		t.Fatalf("expected running task of degraded gang kept, got %s evicted", key)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if _, found := garantee.partialSince["j1"]; found {
+		t.Errorf("expected running gang not timed as partially allocated")
+	}
+}
+
+func TestResolveDeadlock(t *testing.T) {
+	defer func(resolve bool) { ResolveDeadlock = resolve }(ResolveDeadlock)
+
+	for _, resolve := range []bool{false, true} {
+		ResolveDeadlock = resolve
+		testResolveDeadlock(t, resolve)
+	}
+}
+
+func testResolveDeadlock(t *testing.T, resolve bool) {
+	// j1 and j2 each hold a node, and need the node held by the other.
+	evictor := &fakeEvictor{c: make(chan string, 2)}
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:    make(map[string]*api.NodeInfo),
+		Jobs:     make(map[api.JobID]*api.JobInfo),
+		Evictor:  evictor,
+		Recorder: &fakeRecorder{},
+	}
+	for _, node := range []string{"n1", "n2"} {
+		schedulerCache.AddNode(buildNode(node, buildResourceList("2", "4Gi"), nil))
+	}
+	schedulerCache.AddPod(buildPod("p1", "n1", v1.PodRunning, buildResourceList("2", "1Gi"), buildOwnerReference("j1")))
+	schedulerCache.AddPod(buildPod("p2", "", v1.PodPending, buildResourceList("2", "1Gi"), buildOwnerReference("j1")))
+	schedulerCache.AddPod(buildPod("q1", "n2", v1.PodRunning, buildResourceList("2", "1Gi"), buildOwnerReference("j2")))
+	schedulerCache.AddPod(buildPod("q2", "", v1.PodPending, buildResourceList("2", "1Gi"), buildOwnerReference("j2")))
+	for _, owner := range []string{"j1", "j2"} {
+		schedulerCache.AddSchedulingSpec(&arbv1.SchedulingSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            owner,
+				Namespace:       "c1",
+				OwnerReferences: []metav1.OwnerReference{buildOwnerReference(owner)},
+			},
+			Spec: arbv1.SchedulingSpecTemplate{MinAvailable: 2},
+		})
+	}
+
+	ssn := framework.OpenSession(schedulerCache)
+	New().Execute(ssn)
+
+	if !resolve {
+		select {
+		case key := <-evictor.c:
+			t.Errorf("expected no task evicted without resolving deadlock, got %s", key)
+		case <-time.After(100 * time.Millisecond):
+		}
+		return
+	}
+
+	// j2 is released for j1 by job order.
+	select {
+	case key := <-evictor.c:
+		if key != "c1/q1" {
+			t.Errorf("expected c1/q1 evicted, got %s", key)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("expected the task of j2 evicted")
+	}
+	if len(ssn.JobIndex["j1"].TaskStatusIndex[api.Running]) != 1 || len(ssn.JobIndex["j2"].TaskStatusIndex[api.Releasing]) != 1 {
+		t.Errorf("expected the task of j1 kept, and the one of j2 released")
+	}
+}
+
+func TestGangGPUShare(t *testing.T) {
+	// The GPU of n1 fits only one of the GPU shares of the gang.
+	binder := &fakeBinder{c: make(chan string, 2)}
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:    make(map[string]*api.NodeInfo),
+		Jobs:     make(map[api.JobID]*api.JobInfo),
+		Binder:   binder,
+		Recorder: &fakeRecorder{},
+	}
+	node := buildNode("n1", buildResourceList("4", "4Gi"), nil)
+	node.Annotations = map[string]string{api.NodeGPUDevicesAnnotation: "1"}
+	schedulerCache.AddNode(node)
+	for _, name := range []string{"p1", "p2"} {
+		pod := buildPod(name, "", v1.PodPending, buildResourceList("1", "1Gi"), buildOwnerReference("j1"))
+		pod.Annotations = map[string]string{api.GPUCoreAnnotation: "60"}
+		schedulerCache.AddPod(pod)
+	}
+	schedulerCache.AddSchedulingSpec(&arbv1.SchedulingSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "j1",
+			Namespace:       "c1",
+			OwnerReferences: []metav1.OwnerReference{buildOwnerReference("j1")},
+		},
+		Spec: arbv1.SchedulingSpecTemplate{MinAvailable: 2},
+	})
+
+	ssn := framework.OpenSession(schedulerCache)
+	New().Execute(ssn)
+
+	select {
+	case key := <-binder.c:
+		t.Errorf("expected no task of gang bound, got %s", key)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if _, found := ssn.FitErrors["j1"]; !found {
+		t.Errorf("expected j1 unschedulable")
+	}
+}
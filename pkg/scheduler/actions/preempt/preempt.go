@@ -0,0 +1,382 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preempt
+
+import (
+	"sort"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/util"
+)
+
+// PreemptedReason is the reason of evicting the tasks preempted by the tasks
+// of higher priority.
+const PreemptedReason = "Preempted"
+
+// preemptAction evicts the tasks of lower priority for the pending jobs which
+// can not be allocated, and nominates the preemptors to the nodes of the
+// victims by NominatedNodeName as the default scheduler does; the preemptors
+// are bound by allocate once the victims are deleted. The victims of a job are
+// evicted only if its gang, i.e. minAvailable tasks, can be placed; the tasks
+// whose preemption policy is Never do not preempt. The victims of a task are
+// the ones of least disruption, i.e. breaking the fewest gangs first.
+type preemptAction struct {
+	ssn *framework.Session
+}
+
+func New() *preemptAction {
+	return &preemptAction{}
+}
+
+func (preempt *preemptAction) Name() string {
+	return "preempt"
+}
+
+func (preempt *preemptAction) Initialize() {}
+
+func (preempt *preemptAction) Execute(ssn *framework.Session) {
+	logging.V(3).Info("Enter action", "action", preempt.Name())
+	defer logging.V(3).Info("Leave action", "action", preempt.Name())
+
+	jobs := ssn.JobQueue()
+	for !jobs.Empty() {
+		job := jobs.Pop().(*api.JobInfo)
+
+		pending := job.TaskStatusIndex[api.Pending]
+		if len(pending) == 0 || waitingForVictims(ssn, job) {
+			continue
+		}
+
+		need := job.MinAvailable - job.ReadyTaskNum()
+		if need < 1 {
+			need = 1
+		}
+		if need > len(pending) {
+			continue
+		}
+
+		tasks := util.NewPriorityQueue(ssn.TaskOrderFn)
+		for _, task := range pending {
+			tasks.Push(task)
+		}
+
+		if plan := planPreemption(ssn, job, tasks, need); plan != nil {
+			preempt.execute(ssn, job, plan)
+		}
+	}
+}
+
+func (preempt *preemptAction) UnInitialize() {}
+
+// nomination is a preemptor nominated to the node, where the victims are
+// evicted for it.
+type nomination struct {
+	task    *api.TaskInfo
+	node    string
+	victims []*api.TaskInfo
+}
+
+// planPreemption returns the nominations of need tasks of job, nil if any of
+// them can not be placed by preempting the tasks of lower priority. The
+// resources of the nominated tasks and victims are accounted in the
+// simulation, so the tasks of a job don't preempt for the same resources.
+func planPreemption(ssn *framework.Session, job *api.JobInfo, tasks *util.PriorityQueue, need int) []*nomination {
+	// available is the idle resources of node after the nominations; evicted
+	// is the tasks chosen as victims.
+	available := map[string]*api.Resource{}
+	evicted := map[api.TaskID]bool{}
+
+	var plan []*nomination
+	for len(plan) < need && !tasks.Empty() {
+		task := tasks.Pop().(*api.TaskInfo)
+
+		nodes := job.Candidates
+		if nodes == nil {
+			nodes = ssn.Nodes
+		}
+		fitErrors := api.NewFitErrors(task, len(ssn.Nodes))
+		nodes = ssn.FilterNodes(task, nodes, fitErrors)
+
+		// The task which never preempts is only placed in idle resources,
+		// e.g. left by the victims of other tasks of the job.
+		never := api.NeverPreempts(job, task)
+
+		victims := map[string][]*api.TaskInfo{}
+		for _, node := range nodes {
+			if _, found := available[node.Name]; !found {
+				available[node.Name] = node.Idle.Clone()
+			}
+			if never {
+				if task.Resreq.LessEqual(available[node.Name]) {
+					victims[node.Name] = nil
+				}
+				continue
+			}
+			if vs, fit := searchVictims(ssn, task, node, available[node.Name], evicted); fit {
+				victims[node.Name] = vs
+			}
+		}
+
+		victims, err := ssn.FilterVictims(task, victims)
+		if err != nil {
+			logging.Error(err, "Failed to filter victims for task", "job", job.UID, "task", task.UID)
+			return nil
+		}
+
+		n := bestNomination(ssn, task, victims, evicted)
+		if n == nil {
+			logging.V(3).Info("No node for task to preempt", "job", job.UID, "task", task.UID)
+			return nil
+		}
+
+		idle := available[n.node]
+		for _, v := range n.victims {
+			evicted[v.UID] = true
+			idle.Add(v.Resreq)
+		}
+		idle.Sub(task.Resreq)
+
+		plan = append(plan, n)
+	}
+
+	if len(plan) < need {
+		return nil
+	}
+
+	return plan
+}
+
+// maxVictimCandidates is the number of the preemptees of lowest priority on a
+// node, whose subsets are searched for the victims of least disruption.
+const maxVictimCandidates = 10
+
+// preemptees returns the running tasks of lower priority on node which can
+// be preempted by task, ordered by the lower priority first, then the ones
+// created later, which lose less work. Only the tasks of the jobs in session
+// are preempted: the pods of other schedulers, or without SchedulingSpec, are
+// only accounted on nodes, and evicting them would not be tracked by plugins.
+func preemptees(ssn *framework.Session, task *api.TaskInfo, node *api.NodeInfo, evicted map[api.TaskID]bool) []*api.TaskInfo {
+	var preemptees []*api.TaskInfo
+	for _, t := range node.Tasks {
+		if t.Job == task.Job || evicted[t.UID] || t.Priority >= task.Priority {
+			continue
+		}
+		if _, found := ssn.JobIndex[t.Job]; !found {
+			continue
+		}
+		if t.Status == api.Running || t.Status == api.Bound {
+			preemptees = append(preemptees, t)
+		}
+	}
+	sort.Slice(preemptees, func(i, j int) bool {
+		if preemptees[i].Priority != preemptees[j].Priority {
+			return preemptees[i].Priority < preemptees[j].Priority
+		}
+		return api.TaskCreatedBefore(preemptees[j], preemptees[i])
+	})
+	return preemptees
+}
+
+// selectVictims returns the fewest tasks of lower priority on node, which fit
+// task in idle resources after they're evicted; the tasks of lower priority
+// are evicted first, then the ones created later, which lose less work.
+func selectVictims(ssn *framework.Session, task *api.TaskInfo, node *api.NodeInfo, idle *api.Resource, evicted map[api.TaskID]bool) ([]*api.TaskInfo, bool) {
+	if task.Resreq.LessEqual(idle) {
+		return nil, true
+	}
+
+	freed := idle.Clone()
+	preemptees := preemptees(ssn, task, node, evicted)
+	for i, t := range preemptees {
+		freed.Add(t.Resreq)
+		if task.Resreq.LessEqual(freed) {
+			return preemptees[:i+1], true
+		}
+	}
+
+	return nil, false
+}
+
+// searchVictims returns the victims on node of the least disruption, which
+// fit task in idle resources after they're evicted. The subsets of the
+// maxVictimCandidates preemptees of lowest priority are searched, and the
+// victims are selected by selectVictims from all preemptees if none fits.
+func searchVictims(ssn *framework.Session, task *api.TaskInfo, node *api.NodeInfo, idle *api.Resource, evicted map[api.TaskID]bool) ([]*api.TaskInfo, bool) {
+	if task.Resreq.LessEqual(idle) {
+		return nil, true
+	}
+
+	candidates := preemptees(ssn, task, node, evicted)
+	if len(candidates) > maxVictimCandidates {
+		candidates = candidates[:maxVictimCandidates]
+	}
+
+	var best []*api.TaskInfo
+	var bestCost *disruption
+	var search func(i int, victims []*api.TaskInfo, freed *api.Resource)
+	search = func(i int, victims []*api.TaskInfo, freed *api.Resource) {
+		if task.Resreq.LessEqual(freed) {
+			// More victims only disrupt more, so the search stops here.
+			if cost := newDisruption(ssn, task, victims, evicted); bestCost == nil || cost.less(bestCost) {
+				best = append([]*api.TaskInfo{}, victims...)
+				bestCost = cost
+			}
+			return
+		}
+		if bestCost != nil {
+			// The jobs broken and victims are not decreased by more
+			// victims, and at least one more victim is needed.
+			if broken := brokenJobs(ssn, victims, evicted); broken > bestCost.broken ||
+				(broken == bestCost.broken && len(victims)+1 > bestCost.victims) {
+				return
+			}
+		}
+		for j := i; j < len(candidates); j++ {
+			f := freed.Clone()
+			f.Add(candidates[j].Resreq)
+			search(j+1, append(victims, candidates[j]), f)
+		}
+	}
+	search(0, nil, idle.Clone())
+
+	if best == nil {
+		return selectVictims(ssn, task, node, idle, evicted)
+	}
+	return best, true
+}
+
+// disruption is the cost of evicting victims for a task, compared in order:
+// the jobs broken below minAvailable by the victims, the number of victims,
+// the highest priority of victims, and the cost of victims cost funcs.
+type disruption struct {
+	broken   int
+	victims  int
+	priority int32
+	cost     float64
+}
+
+func newDisruption(ssn *framework.Session, task *api.TaskInfo, victims []*api.TaskInfo, evicted map[api.TaskID]bool) *disruption {
+	return &disruption{
+		broken:   brokenJobs(ssn, victims, evicted),
+		victims:  len(victims),
+		priority: maxPriority(victims),
+		cost:     ssn.VictimsCost(task, victims),
+	}
+}
+
+func (d *disruption) less(o *disruption) bool {
+	if d.broken != o.broken {
+		return d.broken < o.broken
+	}
+	if d.victims != o.victims {
+		return d.victims < o.victims
+	}
+	if d.priority != o.priority {
+		return d.priority < o.priority
+	}
+	return d.cost < o.cost
+}
+
+// brokenJobs returns the number of jobs whose ready tasks are at least
+// minAvailable after the evicted tasks are evicted, but less than that after
+// victims are evicted too.
+func brokenJobs(ssn *framework.Session, victims []*api.TaskInfo, evicted map[api.TaskID]bool) int {
+	lost := map[api.JobID]int{}
+	for _, v := range victims {
+		lost[v.Job]++
+	}
+
+	broken := 0
+	for id, n := range lost {
+		job, found := ssn.JobIndex[id]
+		if !found {
+			continue
+		}
+		ready := job.ReadyTaskNum()
+		for uid := range job.Tasks {
+			if evicted[uid] {
+				ready--
+			}
+		}
+		if ready >= job.MinAvailable && ready-n < job.MinAvailable {
+			broken++
+		}
+	}
+	return broken
+}
+
+// bestNomination returns the nomination of task to the node whose victims are
+// of the least disruption, and the node of lower name if tie.
+func bestNomination(ssn *framework.Session, task *api.TaskInfo, victims map[string][]*api.TaskInfo, evicted map[api.TaskID]bool) *nomination {
+	var best *nomination
+	var bestCost *disruption
+	for node, vs := range victims {
+		cost := newDisruption(ssn, task, vs, evicted)
+		if best == nil || cost.less(bestCost) || (!bestCost.less(cost) && node < best.node) {
+			best = &nomination{task: task, node: node, victims: vs}
+			bestCost = cost
+		}
+	}
+	return best
+}
+
+func maxPriority(tasks []*api.TaskInfo) int32 {
+	var max int32
+	for i, t := range tasks {
+		if i == 0 || t.Priority > max {
+			max = t.Priority
+		}
+	}
+	return max
+}
+
+// execute evicts the victims of the nominations and nominates the preemptors;
+// the preemptors fit in idle resources are left to allocate.
+func (preempt *preemptAction) execute(ssn *framework.Session, job *api.JobInfo, plan []*nomination) {
+	for _, n := range plan {
+		if len(n.victims) == 0 {
+			continue
+		}
+
+		logging.V(3).Info("Preempt tasks for task", "job", job.UID, "task", n.task.UID,
+			"node", n.node, "victims", len(n.victims))
+		for _, v := range n.victims {
+			if err := ssn.Evict(v, PreemptedReason); err != nil {
+				logging.Error(err, "Failed to evict task for preemptor",
+					"task", v.UID, "preemptor", n.task.UID)
+			}
+		}
+		ssn.Nominate(n.task, n.node, n.victims)
+	}
+}
+
+// waitingForVictims returns whether a pending task of job is nominated to a
+// node whose tasks are still releasing, so the victims are not evicted again
+// before they're deleted.
+func waitingForVictims(ssn *framework.Session, job *api.JobInfo) bool {
+	for _, task := range job.TaskStatusIndex[api.Pending] {
+		if task.Pod == nil || len(task.Pod.Status.NominatedNodeName) == 0 {
+			continue
+		}
+		if node, found := ssn.NodeIndex[task.Pod.Status.NominatedNodeName]; found && !node.Releasing.IsEmpty() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preempt
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/cache"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+
+	_ "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/plugins/drf"
+)
+
+func buildTask(job api.JobID, uid api.TaskID, cpu float64, priority int32, status api.TaskStatus) *api.TaskInfo {
+	return &api.TaskInfo{
+		UID:      uid,
+		Job:      job,
+		Resreq:   &api.Resource{MilliCPU: cpu},
+		Priority: priority,
+		Status:   status,
+	}
+}
+
+func TestSelectVictims(t *testing.T) {
+	node := &api.NodeInfo{
+		Name: "n1",
+		Tasks: map[api.TaskID]*api.TaskInfo{
+			"low":       buildTask("j1", "low", 1000, 1, api.Running),
+			"mid":       buildTask("j2", "mid", 2000, 5, api.Running),
+			"high":      buildTask("j3", "high", 4000, 20, api.Running),
+			"releasing": buildTask("j4", "releasing", 4000, 0, api.Releasing),
+			"sibling":   buildTask("preemptor", "sibling", 4000, 0, api.Running),
+			"foreign":   buildTask("other", "foreign", 4000, 0, api.Running),
+		},
+	}
+	task := buildTask("preemptor", "p", 2500, 10, api.Pending)
+
+	// The task of job "other" is not in session, e.g. a pod of another
+	// scheduler, so it's never a victim.
+	ssn := &framework.Session{JobIndex: map[api.JobID]*api.JobInfo{}}
+	for _, id := range []api.JobID{"preemptor", "j1", "j2", "j3", "j4"} {
+		ssn.JobIndex[id] = api.NewJobInfo(id)
+	}
+
+	victims, fit := selectVictims(ssn, task, node, &api.Resource{MilliCPU: 500}, map[api.TaskID]bool{})
+	if !fit || len(victims) != 2 || victims[0].UID != "low" || victims[1].UID != "mid" {
+		t.Errorf("expected victims low and mid, got %v (fit %v)", victims, fit)
+	}
+
+	// The victims chosen for other tasks are not counted again.
+	if _, fit := selectVictims(ssn, task, node, &api.Resource{MilliCPU: 500}, map[api.TaskID]bool{"mid": true}); fit {
+		t.Errorf("expected task doesn't fit without mid")
+	}
+
+	if victims, fit := selectVictims(ssn, task, node, &api.Resource{MilliCPU: 3000}, map[api.TaskID]bool{}); !fit || len(victims) != 0 {
+		t.Errorf("expected task fits in idle without victims, got %v (fit %v)", victims, fit)
+	}
+}
+
+func TestBestNomination(t *testing.T) {
+	task := buildTask("preemptor", "p", 1000, 10, api.Pending)
+	victims := map[string][]*api.TaskInfo{
+		"n1": {buildTask("j1", "a", 1000, 1, api.Running), buildTask("j1", "b", 1000, 1, api.Running)},
+		"n2": {buildTask("j2", "c", 1000, 5, api.Running)},
+		"n3": {buildTask("j3", "d", 1000, 3, api.Running)},
+	}
+
+	ssn := &framework.Session{}
+	if n := bestNomination(ssn, task, victims, map[api.TaskID]bool{}); n == nil || n.node != "n3" {
+		t.Errorf("expected node n3 with the fewest victims of lowest priority, got %v", n)
+	}
+
+	if n := bestNomination(ssn, task, map[string][]*api.TaskInfo{}, map[api.TaskID]bool{}); n != nil {
+		t.Errorf("expected no nomination, got %v", n)
+	}
+}
+
+func TestSearchVictims(t *testing.T) {
+	// The gang j1 is broken by evicting any of its tasks, but j2 and j3 are
+	// not, as they have more ready tasks than minAvailable.
+	gang := buildTask("j1", "gang", 2000, 1, api.Running)
+	small1 := buildTask("j2", "small1", 1000, 2, api.Running)
+	small2 := buildTask("j3", "small2", 1000, 2, api.Running)
+	node := &api.NodeInfo{
+		Name: "n1",
+		Tasks: map[api.TaskID]*api.TaskInfo{
+			gang.UID:   gang,
+			small1.UID: small1,
+			small2.UID: small2,
+		},
+	}
+
+	ssn := &framework.Session{JobIndex: map[api.JobID]*api.JobInfo{}}
+	for _, j := range []struct {
+		id           api.JobID
+		minAvailable int
+		tasks        []*api.TaskInfo
+	}{
+		{"j1", 1, []*api.TaskInfo{gang}},
+		{"j2", 1, []*api.TaskInfo{small1, buildTask("j2", "other1", 1000, 2, api.Running)}},
+		{"j3", 1, []*api.TaskInfo{small2, buildTask("j3", "other2", 1000, 2, api.Running)}},
+	} {
+		job := api.NewJobInfo(j.id)
+		job.MinAvailable = j.minAvailable
+		for _, task := range j.tasks {
+			job.AddTaskInfo(task)
+		}
+		ssn.JobIndex[j.id] = job
+	}
+
+	// The two victims of jobs kept ready are chosen instead of the fewest
+	// victims of lowest priority, which break the gang.
+	task := buildTask("preemptor", "p", 2000, 10, api.Pending)
+	victims, fit := searchVictims(ssn, task, node, api.EmptyResource(), map[api.TaskID]bool{})
+	if !fit || len(victims) != 2 || victims[0].UID == "gang" || victims[1].UID == "gang" {
+		t.Errorf("expected victims small1 and small2, got %v (fit %v)", victims, fit)
+	}
+
+	// Once other1 is evicted for another task, evicting small1 breaks j2,
+	// and the single victim gang is chosen.
+	victims, fit = searchVictims(ssn, task, node, api.EmptyResource(), map[api.TaskID]bool{"other1": true})
+	if !fit || len(victims) != 1 || victims[0].UID != "gang" {
+		t.Errorf("expected victim gang, got %v (fit %v)", victims, fit)
+	}
+}
+
+func buildPod(name, node, scheduler string, phase v1.PodPhase, cpu string, priority int32, owner string) *v1.Pod {
+	controller := true
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       types.UID("c1-" + name),
+			Name:      name,
+			Namespace: "c1",
+			OwnerReferences: []metav1.OwnerReference{
+				{Controller: &controller, UID: types.UID(owner)},
+			},
+		},
+		Status: v1.PodStatus{
+			Phase: phase,
+		},
+		Spec: v1.PodSpec{
+			NodeName:      node,
+			SchedulerName: scheduler,
+			Priority:      &priority,
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+					},
+				},
+			},
+		},
+	}
+}
+
+type fakeEvictor struct {
+	c chan string
+}
+
+func (fe *fakeEvictor) Evict(p *v1.Pod) error {
+	fe.c <- p.Namespace + "/" + p.Name
+	return nil
+}
+
+type fakeStatusUpdater struct{}
+
+func (fsu *fakeStatusUpdater) UpdateSchedulingSpec(ss *arbv1.SchedulingSpec) error {
+	return nil
+}
+
+func (fsu *fakeStatusUpdater) UpdatePodCondition(pod *v1.Pod, condition *v1.PodCondition) error {
+	return nil
+}
+
+func (fsu *fakeStatusUpdater) UpdatePodNominatedNode(pod *v1.Pod, nodeName string) error {
+	return nil
+}
+
+type fakeRecorder struct{}
+
+func (fr *fakeRecorder) Eventf(ref *v1.ObjectReference, eventType, reason, messageFmt string, args ...interface{}) {
+}
+
+func TestExecuteSkipsTasksNotInSession(t *testing.T) {
+	// n1 is full of a pod of another scheduler and a pod without
+	// SchedulingSpec; n2 is full of the task of j2, which is the only one
+	// preemptable by the gang j1 of higher priority.
+	evictor := &fakeEvictor{c: make(chan string, 3)}
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Evictor:       evictor,
+		StatusUpdater: &fakeStatusUpdater{},
+		Recorder:      &fakeRecorder{},
+	}
+	for _, name := range []string{"n1", "n2"} {
+		cpu := resource.MustParse("2")
+		schedulerCache.AddNode(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{
+				Capacity:    v1.ResourceList{v1.ResourceCPU: cpu},
+				Allocatable: v1.ResourceList{v1.ResourceCPU: cpu},
+			},
+		})
+	}
+	schedulerCache.AddPod(buildPod("foreign", "n1", "other-scheduler", v1.PodRunning, "1", 0, "other"))
+	schedulerCache.AddPod(buildPod("nospec", "n1", "", v1.PodRunning, "1", 0, "nospec"))
+	schedulerCache.AddPod(buildPod("q1", "n2", "", v1.PodRunning, "2", 1, "j2"))
+	schedulerCache.AddPod(buildPod("p1", "", "", v1.PodPending, "2", 10, "j1"))
+	for _, owner := range []string{"j1", "j2"} {
+		controller := true
+		schedulerCache.AddSchedulingSpec(&arbv1.SchedulingSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      owner,
+				Namespace: "c1",
+				OwnerReferences: []metav1.OwnerReference{
+					{Controller: &controller, UID: types.UID(owner)},
+				},
+			},
+			Spec: arbv1.SchedulingSpecTemplate{MinAvailable: 1},
+		})
+	}
+
+	ssn := framework.OpenSession(schedulerCache)
+	defer framework.CloseSession(ssn)
+
+	New().Execute(ssn)
+
+	select {
+	case key := <-evictor.c:
+		if key != "c1/q1" {
+			t.Errorf("expected c1/q1 evicted, got %s", key)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("expected the task of j2 evicted")
+	}
+	select {
+	case key := <-evictor.c:
+		t.Errorf("expected only c1/q1 evicted, got %s", key)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
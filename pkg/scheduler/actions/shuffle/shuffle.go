@@ -0,0 +1,162 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shuffle
+
+import (
+	"time"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+// RebalanceReason is the reason of evicting the tasks of gang which is
+// requested to be re-scheduled.
+const RebalanceReason = "Rebalance"
+
+// NodeFailureReason is the reason of evicting the tasks of gang which is
+// broken by a node not ready or unreachable.
+const NodeFailureReason = "NodeFailure"
+
+// shuffleAction evicts the gangs marked by the rebalancer, so their pods are
+// re-created by their workload controller and placed again by allocate. At
+// most one gang is evicted in a session to limit the disruption. The gangs
+// broken by failed nodes are evicted as well, once their tasks tolerated the
+// failure for api.NodeFailureToleration.
+type shuffleAction struct {
+	ssn *framework.Session
+}
+
+func New() *shuffleAction {
+	return &shuffleAction{}
+}
+
+func (shuffle *shuffleAction) Name() string {
+	return "shuffle"
+}
+
+func (shuffle *shuffleAction) Initialize() {}
+
+func (shuffle *shuffleAction) Execute(ssn *framework.Session) {
+	logging.V(3).Info("Enter action", "action", shuffle.Name())
+	defer logging.V(3).Info("Leave action", "action", shuffle.Name())
+
+	evictFailedGangs(ssn, time.Now())
+
+	for _, job := range ssn.Jobs {
+		requestedAt, found := rescheduleTime(job)
+		if !found {
+			continue
+		}
+
+		// The gang is evicted only if it's fully placed, so it's not evicted
+		// again while its pods are being re-created.
+		if len(job.TaskStatusIndex[api.Pending]) != 0 || len(job.TaskStatusIndex[api.Releasing]) != 0 {
+			continue
+		}
+
+		victims := staleTasks(job, requestedAt)
+		if len(victims) == 0 {
+			continue
+		}
+
+		logging.V(3).Info("Re-schedule job", "job", job.UID, "name", job.Name,
+			"tasks", len(victims), "requested", requestedAt)
+		for _, task := range victims {
+			if err := ssn.Evict(task, RebalanceReason); err != nil {
+				logging.Error(err, "Failed to evict task to re-schedule job",
+					"job", job.UID, "task", task.UID)
+			}
+		}
+
+		return
+	}
+}
+
+func (shuffle *shuffleAction) UnInitialize() {}
+
+// rescheduleTime returns the time when the job was requested to be
+// re-scheduled by RescheduleAnnotation of its SchedulingSpec.
+func rescheduleTime(job *api.JobInfo) (time.Time, bool) {
+	if job.SchedSpec == nil {
+		return time.Time{}, false
+	}
+
+	value, found := job.SchedSpec.Annotations[arbv1.RescheduleAnnotation]
+	if !found {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		logging.Warning("Invalid reschedule annotation of job", "job", job.UID, "value", value)
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// staleTasks returns the placed tasks of job whose pods were created before
+// the job was requested to be re-scheduled.
+func staleTasks(job *api.JobInfo, requestedAt time.Time) []*api.TaskInfo {
+	var tasks []*api.TaskInfo
+	for _, task := range job.GetTasks(api.Bound, api.Running) {
+		if task.Pod != nil && task.Pod.CreationTimestamp.Time.Before(requestedAt) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+// evictFailedGangs evicts the tasks whose nodes failed longer than they
+// tolerate at now; if the gang is broken below minAvailable by them, the other
+// tasks of the gang are evicted too, so the gang is re-scheduled as a whole
+// instead of holding resources for the failed tasks.
+func evictFailedGangs(ssn *framework.Session, now time.Time) {
+	if api.NodeFailureToleration <= 0 {
+		return
+	}
+
+	for _, job := range ssn.Jobs {
+		placed := job.GetTasks(api.Bound, api.Running)
+
+		var failed []*api.TaskInfo
+		for _, task := range placed {
+			if node, found := ssn.NodeIndex[task.NodeName]; found && node.FailureExpired(task.Pod, now) {
+				failed = append(failed, task)
+			}
+		}
+		if len(failed) == 0 {
+			continue
+		}
+
+		victims := failed
+		if job.ReadyTaskNum()-len(failed) < job.MinAvailable {
+			victims = placed
+		}
+
+		logging.V(3).Info("Re-schedule job on failed nodes", "job", job.UID, "name", job.Name,
+			"failed", len(failed), "tasks", len(victims))
+		for _, task := range victims {
+			if err := ssn.Evict(task, NodeFailureReason); err != nil {
+				logging.Error(err, "Failed to evict task to re-schedule job",
+					"job", job.UID, "task", task.UID)
+			}
+		}
+	}
+}
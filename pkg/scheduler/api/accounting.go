@@ -0,0 +1,84 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+)
+
+// ValidateAccounting enables validating the aggregated resources of NodeInfo
+// and JobInfo against their tasks on every change. It's O(tasks) per change,
+// so it's only for debugging the incremental accounting.
+var ValidateAccounting = false
+
+// resourceEqual returns whether l and r are equal within the precision of
+// Resource.LessEqual.
+func resourceEqual(l, r *Resource) bool {
+	return l.LessEqual(r) && r.LessEqual(l)
+}
+
+// validate logs an error if the aggregated resources of node are not the sum
+// of its tasks, when ValidateAccounting is enabled.
+func (ni *NodeInfo) validate() {
+	if !ValidateAccounting || ni.Node == nil {
+		return
+	}
+
+	used := EmptyResource()
+	releasing := EmptyResource()
+	for _, task := range ni.Tasks {
+		used.Add(task.Resreq)
+		if task.Status == Releasing {
+			releasing.Add(task.Resreq)
+		}
+	}
+	// Not by Resource.Sub, which panics if the node is overcommitted.
+	idle := &Resource{
+		MilliCPU: ni.Allocatable.MilliCPU - used.MilliCPU,
+		Memory:   ni.Allocatable.Memory - used.Memory,
+		GPU:      ni.Allocatable.GPU - used.GPU,
+	}
+
+	if !resourceEqual(used, ni.Used) || !resourceEqual(releasing, ni.Releasing) || !resourceEqual(idle, ni.Idle) {
+		logging.Error(nil, "Resources of node mismatch its tasks", "node", ni.Name,
+			"idle", ni.Idle, "expectedIdle", idle, "used", ni.Used, "expectedUsed", used,
+			"releasing", ni.Releasing, "expectedReleasing", releasing)
+	}
+}
+
+// validate logs an error if the aggregated resources of job are not the sum
+// of its tasks, when ValidateAccounting is enabled.
+func (ps *JobInfo) validate() {
+	if !ValidateAccounting {
+		return
+	}
+
+	total := EmptyResource()
+	allocated := EmptyResource()
+	for _, task := range ps.Tasks {
+		total.Add(task.Resreq)
+		if OccupiedResources(task.Status) {
+			allocated.Add(task.Resreq)
+		}
+	}
+
+	if !resourceEqual(total, ps.TotalRequest) || !resourceEqual(allocated, ps.Allocated) {
+		logging.Error(nil, "Resources of job mismatch its tasks", "job", ps.UID,
+			"totalRequest", ps.TotalRequest, "expectedTotalRequest", total,
+			"allocated", ps.Allocated, "expectedAllocated", allocated)
+	}
+}
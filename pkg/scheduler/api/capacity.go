@@ -0,0 +1,74 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import "time"
+
+// ClusterCapacity is the capacity of cluster reported by scheduler, e.g. to
+// the federation controller which dispatches QueueJobs to member clusters.
+type ClusterCapacity struct {
+	// Allocatable is the total allocatable resources of nodes.
+	Allocatable *Resource `json:"allocatable"`
+	// Idle is the total idle resources of nodes.
+	Idle *Resource `json:"idle"`
+	// MaxNodeIdle is the maximum idle of each resource on a node; a task
+	// requesting more than it can't fit on any node, though a task requesting
+	// less may not fit either.
+	MaxNodeIdle *Resource `json:"maxNodeIdle"`
+	// PendingJobs is the number of jobs with pending tasks.
+	PendingJobs int `json:"pendingJobs"`
+	// Timestamp is when the capacity is reported.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewClusterCapacity returns the capacity of the nodes and jobs, e.g. of a
+// session; the overcommitted, unschedulable and cordoned nodes have no idle
+// resources.
+func NewClusterCapacity(nodes []*NodeInfo, jobs []*JobInfo) *ClusterCapacity {
+	c := &ClusterCapacity{
+		Allocatable: EmptyResource(),
+		Idle:        EmptyResource(),
+		MaxNodeIdle: EmptyResource(),
+		Timestamp:   time.Now(),
+	}
+
+	for _, node := range nodes {
+		c.Allocatable.Add(node.Allocatable)
+		if node.Overcommitted() || node.Unschedulable || node.Cordoned() {
+			continue
+		}
+
+		c.Idle.Add(node.Idle)
+		if node.Idle.MilliCPU > c.MaxNodeIdle.MilliCPU {
+			c.MaxNodeIdle.MilliCPU = node.Idle.MilliCPU
+		}
+		if node.Idle.Memory > c.MaxNodeIdle.Memory {
+			c.MaxNodeIdle.Memory = node.Idle.Memory
+		}
+		if node.Idle.GPU > c.MaxNodeIdle.GPU {
+			c.MaxNodeIdle.GPU = node.Idle.GPU
+		}
+	}
+
+	for _, job := range jobs {
+		if len(job.TaskStatusIndex[Pending]) != 0 {
+			c.PendingJobs++
+		}
+	}
+
+	return c
+}
@@ -22,6 +22,8 @@ type ClusterInfo struct {
 	Jobs []*JobInfo
 
 	Nodes []*NodeInfo
+
+	Queues []*QueueInfo
 }
 
 func (ci ClusterInfo) String() string {
@@ -31,8 +33,8 @@ func (ci ClusterInfo) String() string {
 	if len(ci.Nodes) != 0 {
 		str = str + "Nodes:\n"
 		for _, n := range ci.Nodes {
-			str = str + fmt.Sprintf("\t %s: idle(%v) used(%v) allocatable(%v) pods(%d)\n",
-				n.Name, n.Idle, n.Used, n.Allocatable, len(n.Tasks))
+			str = str + fmt.Sprintf("\t %s: idle(%v) used(%v) releasing(%v) allocatable(%v) pods(%d)\n",
+				n.Name, n.Idle, n.Used, n.Releasing, n.Allocatable, len(n.Tasks))
 
 			i := 0
 			for _, p := range n.Tasks {
@@ -0,0 +1,188 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/api/core/v1"
+)
+
+const (
+	// UnschedulableEvent is the reason of the event that job can not be scheduled.
+	UnschedulableEvent = "Unschedulable"
+
+	// RetriesExceededReason is the reason of the condition that job was
+	// unschedulable in its maxScheduleRetries sessions.
+	RetriesExceededReason = "ScheduleRetriesExceeded"
+
+	// ScheduledReason is the reason of the condition that job's minAvailable
+	// tasks are scheduled.
+	ScheduledReason = "Scheduled"
+
+	// PendingReason is the reason of the condition that job's minAvailable
+	// tasks are not all scheduled, e.g. some of them were deleted.
+	PendingReason = "Pending"
+
+	// NodeSelectorNotMatch is the reason of the node not matching job's node selector.
+	NodeSelectorNotMatch = "node(s) didn't match node selector"
+
+	// NodeHeartbeatStale is the reason of the node whose heartbeat is stale,
+	// e.g. it just died.
+	NodeHeartbeatStale = "node(s) had stale heartbeat"
+
+	// NodeUnschedulable is the reason of the node marked unschedulable, e.g.
+	// cordoned for maintenance.
+	NodeUnschedulable = "node(s) were unschedulable"
+
+	// NodeMemoryPressure, NodeDiskPressure and NodePIDPressure are the
+	// reasons of the nodes under pressure, whose kubelet evicts pods.
+	NodeMemoryPressure = "node(s) had memory pressure"
+	NodeDiskPressure   = "node(s) had disk pressure"
+	NodePIDPressure    = "node(s) had pid pressure"
+
+	// NodeNotReady and NodeUnreachable are the reasons of the nodes tainted
+	// as not ready or unreachable by the node controller.
+	NodeNotReady    = "node(s) were not ready"
+	NodeUnreachable = "node(s) were unreachable"
+
+	// NodeOSNotMatch is the reason of the node whose OS is not required by
+	// the pod, e.g. a Windows node for a Linux pod.
+	NodeOSNotMatch = "node(s) didn't match pod OS"
+
+	// NodeGPUShareNotFit is the reason of the node without a GPU fitting
+	// the GPU share of pod, or whose GPUs are not shared.
+	NodeGPUShareNotFit = "node(s) didn't have GPU fitting the GPU share"
+
+	// NodeGPUShared is the reason of the node whose GPUs are shared by GPU
+	// shares for the pod requesting whole GPUs.
+	NodeGPUShared = "node(s) shared GPUs"
+
+	// NodeFilteredOut is the reason of the node filtered out by a nodes filter
+	// func without reason, e.g. an extender.
+	NodeFilteredOut = "node(s) were filtered out"
+
+	// VolumeNodeAffinityConflict is the reason of the node not matching the
+	// node affinity of a bound PV, e.g. a local PV on another node.
+	VolumeNodeAffinityConflict = "node(s) had volume node affinity conflict"
+
+	// VolumeZoneConflict is the reason of the node not in the zone of a
+	// bound PV, e.g. a zonal disk.
+	VolumeZoneConflict = "node(s) had no available volume zone"
+
+	// VolumeBindConflict is the reason of the node without available PV for
+	// an unbound PVC.
+	VolumeBindConflict = "node(s) didn't find available persistent volumes to bind"
+)
+
+// FitErrors records why each node can not fit a task; the message aggregates
+// the nodes by reason in the same format as kube-scheduler, e.g.
+// "0/5 nodes are available: 3 Insufficient nvidia.com/gpu, 2 node(s) didn't match node selector."
+type FitErrors struct {
+	Task        *TaskInfo
+	NumAllNodes int
+
+	// FailedNodes is the reasons of each node which can not fit the task, by
+	// node name.
+	FailedNodes map[string][]string
+}
+
+// NewFitErrors creates FitErrors of task in the cluster with numAllNodes nodes.
+func NewFitErrors(task *TaskInfo, numAllNodes int) *FitErrors {
+	return &FitErrors{
+		Task:        task,
+		NumAllNodes: numAllNodes,
+		FailedNodes: map[string][]string{},
+	}
+}
+
+// SetNodeError records the reasons why the node can not fit the task.
+func (f *FitErrors) SetNodeError(nodeName string, reasons ...string) {
+	f.FailedNodes[nodeName] = append(f.FailedNodes[nodeName], reasons...)
+}
+
+// SetCandidateErrors records the nodes which are not in candidates as not
+// matching node selector; nil candidates means all nodes.
+func (f *FitErrors) SetCandidateErrors(nodes, candidates []*NodeInfo) {
+	if candidates == nil {
+		return
+	}
+
+	matched := map[string]bool{}
+	for _, node := range candidates {
+		matched[node.Name] = true
+	}
+
+	for _, node := range nodes {
+		if !matched[node.Name] {
+			f.SetNodeError(node.Name, NodeSelectorNotMatch)
+		}
+	}
+}
+
+// Error returns the message of the reasons aggregated by the number of nodes,
+// the most common one first.
+func (f *FitErrors) Error() string {
+	counts := map[string]int{}
+	for _, reasons := range f.FailedNodes {
+		for _, reason := range reasons {
+			counts[reason]++
+		}
+	}
+
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if counts[reasons[i]] != counts[reasons[j]] {
+			return counts[reasons[i]] > counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+
+	strs := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		strs = append(strs, fmt.Sprintf("%d %s", counts[reason], reason))
+	}
+
+	return fmt.Sprintf("0/%d nodes are available: %s.", f.NumAllNodes, strings.Join(strs, ", "))
+}
+
+// InsufficientReasons returns the reasons of the resources in req which are
+// more than idle, e.g. "Insufficient cpu".
+func InsufficientReasons(req, idle *Resource) []string {
+	var reasons []string
+
+	if req.MilliCPU > idle.MilliCPU {
+		reasons = append(reasons, insufficientReason(v1.ResourceCPU))
+	}
+	if req.Memory > idle.Memory {
+		reasons = append(reasons, insufficientReason(v1.ResourceMemory))
+	}
+	if req.GPU > idle.GPU {
+		reasons = append(reasons, insufficientReason(GPUResourceName))
+	}
+
+	return reasons
+}
+
+func insufficientReason(rn v1.ResourceName) string {
+	return fmt.Sprintf("Insufficient %s", rn)
+}
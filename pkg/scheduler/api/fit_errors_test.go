@@ -0,0 +1,53 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFitErrors(t *testing.T) {
+	req := &Resource{MilliCPU: 1000, Memory: 1024, GPU: 1}
+
+	nodes := []*NodeInfo{}
+	for _, name := range []string{"n1", "n2", "n3", "n4", "n5"} {
+		nodes = append(nodes, &NodeInfo{Name: name})
+	}
+
+	fitErrors := NewFitErrors(nil, len(nodes))
+	fitErrors.SetCandidateErrors(nodes, nodes[2:])
+	fitErrors.SetNodeError("n3", InsufficientReasons(req, &Resource{MilliCPU: 2000, Memory: 2048})...)
+	fitErrors.SetNodeError("n4", InsufficientReasons(req, &Resource{MilliCPU: 500, Memory: 2048})...)
+	fitErrors.SetNodeError("n5", InsufficientReasons(req, &Resource{MilliCPU: 2000, Memory: 512, GPU: 2})...)
+
+	expectedNodes := map[string][]string{
+		"n1": {NodeSelectorNotMatch},
+		"n2": {NodeSelectorNotMatch},
+		"n3": {"Insufficient nvidia.com/gpu"},
+		"n4": {"Insufficient cpu", "Insufficient nvidia.com/gpu"},
+		"n5": {"Insufficient memory"},
+	}
+	if !reflect.DeepEqual(fitErrors.FailedNodes, expectedNodes) {
+		t.Errorf("expected: %v, got: %v", expectedNodes, fitErrors.FailedNodes)
+	}
+
+	expected := "0/5 nodes are available: 2 Insufficient nvidia.com/gpu, 2 node(s) didn't match node selector, 1 Insufficient cpu, 1 Insufficient memory."
+	if got := fitErrors.Error(); got != expected {
+		t.Errorf("expected: %s, got: %s", expected, got)
+	}
+}
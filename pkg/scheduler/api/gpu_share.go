@@ -0,0 +1,251 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"strconv"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+)
+
+const (
+	// GPUCoreAnnotation is the annotation of pod requesting a share of the
+	// compute of one GPU, in percent, e.g. "30".
+	GPUCoreAnnotation = "kube-arbitrator.k8s.io/gpu-core"
+	// GPUMemoryAnnotation is the annotation of pod requesting a share of the
+	// memory of one GPU, e.g. "4Gi".
+	GPUMemoryAnnotation = "kube-arbitrator.k8s.io/gpu-memory"
+	// GPUIndexAnnotation is the index of the GPU assigned to pod, which is
+	// set when the pod is bound, so the device plugin exposes the device to
+	// the pod.
+	GPUIndexAnnotation = "kube-arbitrator.k8s.io/gpu-index"
+
+	// NodeGPUDevicesAnnotation is the annotation of node whose GPUs are
+	// shared by the pods requesting GPU shares, with the number of GPUs,
+	// e.g. "4"; the pods requesting whole GPUs are not placed on the node.
+	NodeGPUDevicesAnnotation = "kube-arbitrator.k8s.io/gpu-devices"
+	// NodeGPUDeviceMemoryAnnotation is the annotation of the memory of each
+	// GPU of node, e.g. "16Gi"; the memory of GPUs is not checked if not set.
+	NodeGPUDeviceMemoryAnnotation = "kube-arbitrator.k8s.io/gpu-device-memory"
+)
+
+// GPUShare is the share of one GPU requested by task.
+type GPUShare struct {
+	// Core is the percent of the compute of GPU.
+	Core int64
+	// Memory is the bytes of the memory of GPU.
+	Memory float64
+	// Device is the index of the GPU assigned to task, -1 if not assigned.
+	Device int
+}
+
+// newGPUShare returns the GPU share requested by pod, nil if none; the
+// invalid annotations are ignored.
+func newGPUShare(pod *v1.Pod) *GPUShare {
+	coreValue, hasCore := pod.Annotations[GPUCoreAnnotation]
+	memoryValue, hasMemory := pod.Annotations[GPUMemoryAnnotation]
+	if !hasCore && !hasMemory {
+		return nil
+	}
+
+	share := &GPUShare{Device: -1}
+	if hasCore {
+		core, err := strconv.ParseInt(coreValue, 10, 64)
+		if err != nil || core < 0 || core > 100 {
+			logging.Warning("Ignore invalid GPU core share of pod",
+				"pod", PodKey(pod), "value", coreValue)
+			return nil
+		}
+		share.Core = core
+	}
+	if hasMemory {
+		memory, err := resource.ParseQuantity(memoryValue)
+		if err != nil {
+			logging.Warning("Ignore invalid GPU memory share of pod",
+				"pod", PodKey(pod), "value", memoryValue)
+			return nil
+		}
+		share.Memory = float64(memory.Value())
+	}
+
+	if index, found := pod.Annotations[GPUIndexAnnotation]; found && len(pod.Spec.NodeName) != 0 {
+		if device, err := strconv.Atoi(index); err == nil && device >= 0 {
+			share.Device = device
+		}
+	}
+
+	return share
+}
+
+// GPUDevice is the usage of a shared GPU of node.
+type GPUDevice struct {
+	Index int
+	// Core is the percent of the compute used by tasks.
+	Core int64
+	// Memory is the bytes of memory used by tasks.
+	Memory float64
+	// MemoryCapacity is the bytes of memory of the GPU, 0 if unknown.
+	MemoryCapacity float64
+}
+
+// fits returns whether share fits the idle of device.
+func (d *GPUDevice) fits(share *GPUShare) bool {
+	if d.Core+share.Core > 100 {
+		return false
+	}
+	return d.MemoryCapacity == 0 || d.Memory+share.Memory <= d.MemoryCapacity
+}
+
+// newGPUDevices returns the shared GPUs of node by its annotations, nil if
+// its GPUs are not shared.
+func newGPUDevices(node *v1.Node) []*GPUDevice {
+	value, found := node.Annotations[NodeGPUDevicesAnnotation]
+	if !found {
+		return nil
+	}
+
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		logging.Warning("Ignore invalid number of shared GPUs of node",
+			"node", node.Name, "value", value)
+		return nil
+	}
+
+	memory := 0.0
+	if value, found := node.Annotations[NodeGPUDeviceMemoryAnnotation]; found {
+		if q, err := resource.ParseQuantity(value); err == nil {
+			memory = float64(q.Value())
+		} else {
+			logging.Warning("Ignore invalid GPU memory of node",
+				"node", node.Name, "value", value)
+		}
+	}
+
+	devices := make([]*GPUDevice, count)
+	for i := range devices {
+		devices[i] = &GPUDevice{Index: i, MemoryCapacity: memory}
+	}
+	return devices
+}
+
+// SharesGPU returns whether the GPUs of node are shared by GPU shares.
+func (ni *NodeInfo) SharesGPU() bool {
+	return len(ni.GPUDevices) != 0
+}
+
+// FitGPUShare returns the GPU of node which share fits best, i.e. the least
+// compute left after placing it, -1 if no GPU fits.
+func (ni *NodeInfo) FitGPUShare(share *GPUShare) int {
+	return fitGPUShare(ni.GPUDevices, share)
+}
+
+func fitGPUShare(devices []*GPUDevice, share *GPUShare) int {
+	best := -1
+	for _, d := range devices {
+		if !d.fits(share) {
+			continue
+		}
+		if best < 0 || d.Core > devices[best].Core {
+			best = d.Index
+		}
+	}
+	return best
+}
+
+// GPUShares is the usage of the shared GPUs of nodes by name, including the
+// GPU shares of the tasks assigned to them but not added yet, e.g. the tasks
+// of a gang placed together.
+type GPUShares map[string][]*GPUDevice
+
+// FitGPUShare returns the GPU of node which share fits best after the shares
+// added into gs, -1 if no GPU fits.
+func (gs GPUShares) FitGPUShare(node *NodeInfo, share *GPUShare) int {
+	devices, found := gs[node.Name]
+	if !found {
+		devices = node.GPUDevices
+	}
+	return fitGPUShare(devices, share)
+}
+
+// Add adds share into the GPU of node which it fits best.
+func (gs GPUShares) Add(node *NodeInfo, share *GPUShare) {
+	if share == nil || !node.SharesGPU() {
+		return
+	}
+
+	devices, found := gs[node.Name]
+	if !found {
+		devices = cloneGPUDevices(node.GPUDevices)
+		gs[node.Name] = devices
+	}
+	if i := fitGPUShare(devices, share); i >= 0 {
+		devices[i].Core += share.Core
+		devices[i].Memory += share.Memory
+	}
+}
+
+// addGPUShare adds the GPU share of p into its GPU, which is assigned to p if
+// not yet.
+func (ni *NodeInfo) addGPUShare(p *TaskInfo) {
+	if p.GPUShare == nil || !ni.SharesGPU() {
+		return
+	}
+
+	if p.GPUShare.Device < 0 {
+		p.GPUShare.Device = ni.FitGPUShare(p.GPUShare)
+		if p.GPUShare.Device < 0 {
+			logging.Warning("No GPU fits the GPU share of task",
+				"job", p.Job, "task", p.UID, "node", ni.Name)
+			return
+		}
+	}
+	if p.GPUShare.Device >= len(ni.GPUDevices) {
+		return
+	}
+
+	d := ni.GPUDevices[p.GPUShare.Device]
+	d.Core += p.GPUShare.Core
+	d.Memory += p.GPUShare.Memory
+}
+
+// subGPUShare subtracts the GPU share of p from its GPU.
+func (ni *NodeInfo) subGPUShare(p *TaskInfo) {
+	if p.GPUShare == nil || p.GPUShare.Device < 0 || p.GPUShare.Device >= len(ni.GPUDevices) {
+		return
+	}
+
+	d := ni.GPUDevices[p.GPUShare.Device]
+	d.Core -= p.GPUShare.Core
+	d.Memory -= p.GPUShare.Memory
+}
+
+// cloneGPUDevices returns a copy of devices.
+func cloneGPUDevices(devices []*GPUDevice) []*GPUDevice {
+	if devices == nil {
+		return nil
+	}
+
+	clones := make([]*GPUDevice, len(devices))
+	for i, d := range devices {
+		clone := *d
+		clones[i] = &clone
+	}
+	return clones
+}
@@ -32,16 +32,82 @@ func PodKey(pod *v1.Pod) TaskID {
 	}
 }
 
+// JobCreatedBefore returns whether job l is created before r, or its UID is
+// smaller if they're created at the same time; it breaks the ties of job
+// orders, so they don't depend on map iteration.
+func JobCreatedBefore(l, r *JobInfo) bool {
+	if !l.CreationTimestamp.Equal(&r.CreationTimestamp) {
+		return l.CreationTimestamp.Before(&r.CreationTimestamp)
+	}
+	return l.UID < r.UID
+}
+
+// TaskCreatedBefore returns whether the pod of task l is created before the
+// one of r, or its UID is smaller if they're created at the same time; it
+// breaks the ties of task orders, so they don't depend on map iteration.
+func TaskCreatedBefore(l, r *TaskInfo) bool {
+	if l.Pod != nil && r.Pod != nil && !l.Pod.CreationTimestamp.Equal(&r.Pod.CreationTimestamp) {
+		return l.Pod.CreationTimestamp.Before(&r.Pod.CreationTimestamp)
+	}
+	return l.UID < r.UID
+}
+
+// PodOSes returns the operating systems which pod can run on, by the OS labels
+// in its node selector and required node affinity; a pod without OS
+// requirements runs on Linux only, e.g. the Linux images of most workloads.
+func PodOSes(pod *v1.Pod) []string {
+	if pod == nil {
+		return []string{LinuxOS}
+	}
+
+	for _, label := range []string{NodeOSStableLabel, NodeOSLabel} {
+		if os, found := pod.Spec.NodeSelector[label]; found {
+			return []string{os}
+		}
+	}
+
+	var oses []string
+	if affinity := pod.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil &&
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			for _, req := range term.MatchExpressions {
+				if (req.Key == NodeOSStableLabel || req.Key == NodeOSLabel) && req.Operator == v1.NodeSelectorOpIn {
+					oses = append(oses, req.Values...)
+				}
+			}
+		}
+	}
+	if len(oses) == 0 {
+		return []string{LinuxOS}
+	}
+
+	return oses
+}
+
 func getTaskStatus(pod *v1.Pod) TaskStatus {
 	switch pod.Status.Phase {
 	case v1.PodRunning:
+		if pod.DeletionTimestamp != nil {
+			return Releasing
+		}
 		return Running
-	case v1.PodPending:
+	// The phase is empty until it's set, e.g. of a pod created with its node
+	// by a test or simulator; it's pending as well.
+	case v1.PodPending, "":
 		if len(pod.Spec.NodeName) == 0 {
 			return Pending
 		}
+		// The pod bound to node, including the one created with its node
+		// which is not scheduled, occupies the resources of the node and is
+		// ready for the gang of its job before it's running.
+		if pod.DeletionTimestamp != nil {
+			return Releasing
+		}
 		return Bound
 	case v1.PodUnknown:
+		if pod.DeletionTimestamp != nil {
+			return Releasing
+		}
 		return Unknown
 	case v1.PodSucceeded:
 		return Succeeded
@@ -18,13 +18,17 @@ package api
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
 	"k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
 	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
 )
 
 type TaskID types.UID
@@ -38,53 +42,108 @@ type TaskInfo struct {
 
 	Resreq *Resource
 
+	// NodeName and Status are the scheduling state of the task, which are
+	// changed by scheduler without changing Pod.
 	NodeName string
 	Status   TaskStatus
 	Priority int32
+	// RolePriority is the priority of the role of task in its job, e.g. the
+	// chief and ps before the workers, by RolePriorityAnnotation.
+	RolePriority int32
 
+	// GPUShare is the share of one GPU requested by the task, nil if none.
+	GPUShare *GPUShare
+
+	// Pod is shared by the informer cache, scheduler cache and snapshots, so
+	// it must not be modified; deep copy it before making any change.
 	Pod *v1.Pod
 }
 
 func NewTaskInfo(pod *v1.Pod) *TaskInfo {
 	req := EmptyResource()
+	for i := range pod.Spec.Containers {
+		addContainerRequest(req, &pod.Spec.Containers[i])
+	}
+
+	// The init containers run one by one before the containers, so the pod
+	// requests the larger of them, as kubelet admits it.
+	for i := range pod.Spec.InitContainers {
+		init := EmptyResource()
+		addContainerRequest(init, &pod.Spec.InitContainers[i])
+		req.SetMaxResource(init)
+	}
 
-	// TODO(k82cn): also includes initContainers' resource.
-	for _, c := range pod.Spec.Containers {
-		req.Add(NewResource(c.Resources.Requests))
+	// The overhead of RuntimeClass is used by the pod besides its containers.
+	if overhead := podOverhead(pod); overhead != nil {
+		req.addResourceList(overhead)
 	}
 
 	pi := &TaskInfo{
 		UID:       TaskID(pod.UID),
-		Job:       JobID(utils.GetController(pod)),
+		Job:       PodJobID(pod),
 		Name:      pod.Name,
 		Namespace: pod.Namespace,
 		NodeName:  pod.Spec.NodeName,
 		Status:    getTaskStatus(pod),
 		Priority:  1,
 
-		Pod:    pod,
-		Resreq: req,
+		Pod:      pod,
+		Resreq:   req,
+		GPUShare: newGPUShare(pod),
 	}
 
 	if pod.Spec.Priority != nil {
 		pi.Priority = *pod.Spec.Priority
 	}
+	pi.RolePriority = rolePriority(pod)
 
 	return pi
 }
 
+// rolePriority returns the role priority of pod by RolePriorityAnnotation, 0
+// if it's not set or invalid.
+func rolePriority(pod *v1.Pod) int32 {
+	value, found := pod.Annotations[RolePriorityAnnotation]
+	if !found {
+		return 0
+	}
+
+	priority, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		logging.Warning("Ignore invalid role priority of pod", "pod", PodKey(pod), "value", value)
+		return 0
+	}
+	return int32(priority)
+}
+
+// addContainerRequest adds the resources requested by container c into r.
+func addContainerRequest(r *Resource, c *v1.Container) {
+	r.addResourceList(c.Resources.Requests)
+
+	// GPUs are usually set in limits only, which are the requests of
+	// extended resources.
+	if _, found := c.Resources.Requests[GPUResourceName]; !found {
+		if limit, found := c.Resources.Limits[GPUResourceName]; found {
+			r.addResourceList(v1.ResourceList{GPUResourceName: limit})
+		}
+	}
+}
+
+// Clone returns a copy of the task from pool, which is put back to pool by
+// ReleaseTasks if it's in a snapshot.
 func (pi *TaskInfo) Clone() *TaskInfo {
-	return &TaskInfo{
-		UID:       pi.UID,
-		Job:       pi.Job,
-		Name:      pi.Name,
-		Namespace: pi.Namespace,
-		NodeName:  pi.NodeName,
-		Status:    pi.Status,
-		Priority:  pi.Priority,
-		Pod:       pi.Pod,
-		Resreq:    pi.Resreq.Clone(),
+	clone := newTaskInfoFromPool()
+
+	resreq := clone.Resreq
+	*resreq = *pi.Resreq
+	*clone = *pi
+	clone.Resreq = resreq
+	if pi.GPUShare != nil {
+		share := *pi.GPUShare
+		clone.GPUShare = &share
 	}
+
+	return clone
 }
 
 func (pi TaskInfo) String() string {
@@ -95,6 +154,120 @@ func (pi TaskInfo) String() string {
 // JobID is the type of JobInfo's ID.
 type JobID types.UID
 
+// DefaultGroupNameLabel is the default label used to group pods into a Job.
+const DefaultGroupNameLabel = "pod-group.arbitrator/name"
+
+// GroupNameLabel is the label used to group pods into a Job if they have no
+// controller, e.g. bare pods created by external systems. The pods with the
+// same label value in the same namespace belong to the same Job; set it
+// to empty to disable label grouping.
+var GroupNameLabel = DefaultGroupNameLabel
+
+// GroupJobID returns the ID of the Job grouped by GroupNameLabel.
+func GroupJobID(namespace, name string) JobID {
+	return JobID(fmt.Sprintf("%s/%s", namespace, name))
+}
+
+// The labels set by Spark on the driver and executor pods of an application;
+// the executors are owned by the driver pod instead of the application.
+const (
+	SparkAppSelectorLabel = "spark-app-selector"
+	SparkRoleLabel        = "spark-role"
+	SparkDriverRole       = "driver"
+)
+
+// MPIJobNameLabel is the label set by MPI operator on the launcher and worker
+// pods of MPIJob, which are owned by a Job and a StatefulSet respectively.
+const MPIJobNameLabel = "mpi_job_name"
+
+const (
+	// PreemptionPolicyAnnotation is the preemption policy of a pod, or of all
+	// pods of a job if it's set on the SchedulingSpec; the vendored PodSpec
+	// and PriorityClass have no preemptionPolicy, so it's annotated instead.
+	PreemptionPolicyAnnotation = "kube-arbitrator.k8s.io/preemption-policy"
+	// PreemptNever is the preemption policy of the pods which never preempt
+	// other pods, though they're still ordered by priority.
+	PreemptNever = "Never"
+
+	// RolePriorityAnnotation is the priority of a pod among the pods of its
+	// job, e.g. "10" on the chief and ps of a TFJob; the pods of higher role
+	// priority are placed first, then by the priority of pods, so the most
+	// important ones are placed if only part of a job fits.
+	RolePriorityAnnotation = "kube-arbitrator.k8s.io/role-priority"
+)
+
+// NeverPreempts returns whether task of job never preempts other tasks by its
+// preemption policy or the policy of job.
+func NeverPreempts(job *JobInfo, task *TaskInfo) bool {
+	if job != nil && job.SchedSpec != nil && job.SchedSpec.Annotations[PreemptionPolicyAnnotation] == PreemptNever {
+		return true
+	}
+	return task.Pod != nil && task.Pod.Annotations[PreemptionPolicyAnnotation] == PreemptNever
+}
+
+// IsSparkDriver returns whether pod is the driver of a Spark application.
+func IsSparkDriver(pod *v1.Pod) bool {
+	return pod.Labels[SparkRoleLabel] == SparkDriverRole
+}
+
+// PodJobID returns the ID of the Job which the pod belongs to.
+func PodJobID(pod *v1.Pod) JobID {
+	// The driver and executors of a Spark application are grouped by the
+	// application ID, the same as the pod group of the name; so its
+	// SchedulingSpec is the one named by the application ID.
+	if app, found := pod.Labels[SparkAppSelectorLabel]; found && len(app) != 0 && len(pod.Labels[SparkRoleLabel]) != 0 {
+		return GroupJobID(pod.Namespace, app)
+	}
+
+	// The launcher and workers of MPIJob are grouped by the MPIJob name.
+	if name, found := pod.Labels[MPIJobNameLabel]; found && len(name) != 0 {
+		return GroupJobID(pod.Namespace, name)
+	}
+
+	if ctl := utils.GetController(pod); len(ctl) != 0 {
+		return JobID(ctl)
+	}
+
+	if len(GroupNameLabel) != 0 {
+		if name, found := pod.Labels[GroupNameLabel]; found && len(name) != 0 {
+			return GroupJobID(pod.Namespace, name)
+		}
+	}
+
+	return ImplicitJobID(pod)
+}
+
+// ImplicitJobID returns the ID of the implicit job of pod, which is the job
+// of the pod without controller or pod group.
+func ImplicitJobID(pod *v1.Pod) JobID {
+	return JobID(pod.UID)
+}
+
+// SchedulingSpecJobID returns the ID of the Job which the SchedulingSpec
+// belongs to: the controller of SchedulingSpec, or the pod group of the same
+// name if it has no controller and label grouping is enabled.
+func SchedulingSpecJobID(ss *arbv1.SchedulingSpec) JobID {
+	return objectJobID(ss)
+}
+
+// PDBJobID returns the ID of the Job which the PDB belongs to, the same as
+// SchedulingSpec.
+func PDBJobID(pdb *policyv1.PodDisruptionBudget) JobID {
+	return objectJobID(pdb)
+}
+
+func objectJobID(obj metav1.Object) JobID {
+	if ctl := utils.GetController(obj); len(ctl) != 0 {
+		return JobID(ctl)
+	}
+
+	if len(GroupNameLabel) != 0 {
+		return GroupJobID(obj.GetNamespace(), obj.GetName())
+	}
+
+	return ""
+}
+
 type tasksMap map[TaskID]*TaskInfo
 
 type JobInfo struct {
@@ -106,6 +279,13 @@ type JobInfo struct {
 	NodeSelector map[string]string
 	MinAvailable int
 
+	// Queue is the name of Queue which the job belongs to.
+	Queue string
+
+	// CreationTimestamp is the time when the job was admitted, i.e. its
+	// SchedulingSpec or PDB was created.
+	CreationTimestamp metav1.Time
+
 	// All tasks of the Job.
 	TaskStatusIndex map[TaskStatus]tasksMap
 	Tasks           tasksMap
@@ -118,6 +298,29 @@ type JobInfo struct {
 
 	SchedSpec *arbv1.SchedulingSpec
 
+	// Implicit is whether the job is the implicit job of a pod without
+	// controller or pod group, e.g. a debug pod, which is scheduled as a job
+	// of the single task without SchedulingSpec.
+	Implicit bool
+
+	// Suspended means the job is skipped by scheduler.
+	Suspended bool
+
+	// BindFailures is the number of consecutive failed binds of the tasks of
+	// job; its pending tasks are not scheduled until BackoffUntil after the
+	// last failure.
+	BindFailures int
+	BackoffUntil time.Time
+
+	// ScheduleFailures is the number of consecutive sessions in which the
+	// job was unschedulable.
+	ScheduleFailures int
+
+	// OwnerUID is the UID of the SchedulingSpec which the job was last set
+	// by; it's kept after the SchedulingSpec is deleted, so the job keyed by
+	// name whose SchedulingSpec is recreated is found.
+	OwnerUID types.UID
+
 	// TODO(k82cn): keep backward compatbility, removed it when v1alpha1 finalized.
 	PDB *policyv1.PodDisruptionBudget
 }
@@ -141,21 +344,56 @@ func (ps *JobInfo) SetSchedulingSpec(spec *arbv1.SchedulingSpec) {
 	ps.Name = spec.Name
 	ps.Namespace = spec.Namespace
 	ps.MinAvailable = spec.Spec.MinAvailable
+	ps.Suspended = spec.Spec.Suspend
+	ps.Queue = utils.SchedulingSpecQueue(spec)
+	ps.CreationTimestamp = spec.CreationTimestamp
 
 	for k, v := range spec.Spec.NodeSelector {
 		ps.NodeSelector[k] = v
 	}
 
 	ps.SchedSpec = spec
+	ps.OwnerUID = spec.UID
+}
+
+func (ps *JobInfo) UnsetSchedulingSpec() {
+	ps.SchedSpec = nil
+	ps.Suspended = false
+}
+
+// RetriesExhausted returns whether the job was unschedulable in the
+// maxScheduleRetries of its SchedulingSpec, so it's not scheduled any more.
+func (ps *JobInfo) RetriesExhausted() bool {
+	if ps.SchedSpec == nil || ps.SchedSpec.Spec.MaxScheduleRetries <= 0 {
+		return false
+	}
+	return ps.ScheduleFailures >= int(ps.SchedSpec.Spec.MaxScheduleRetries)
+}
+
+// SetImplicitPod makes the job the implicit job of pod, whose MinAvailable is
+// 1 and queue is the one of its namespace, if any.
+func (ps *JobInfo) SetImplicitPod(pod *v1.Pod) {
+	ps.Name = pod.Name
+	ps.Namespace = pod.Namespace
+	ps.MinAvailable = 1
+	ps.Queue = utils.NamespaceQueues[pod.Namespace]
+	ps.CreationTimestamp = pod.CreationTimestamp
+
+	ps.Implicit = true
 }
 
 func (ps *JobInfo) SetPDB(pbd *policyv1.PodDisruptionBudget) {
 	ps.Name = pbd.Name
 	ps.MinAvailable = int(pbd.Spec.MinAvailable.IntVal)
+	ps.CreationTimestamp = pbd.CreationTimestamp
 
 	ps.PDB = pbd
 }
 
+func (ps *JobInfo) UnsetPDB() {
+	ps.PDB = nil
+}
+
 func (ps *JobInfo) GetTasks(statuses ...TaskStatus) []*TaskInfo {
 	var res []*TaskInfo
 
@@ -187,6 +425,8 @@ func (ps *JobInfo) AddTaskInfo(pi *TaskInfo) {
 	if OccupiedResources(pi.Status) {
 		ps.Allocated.Add(pi.Resreq)
 	}
+
+	ps.validate()
 }
 
 func (ps *JobInfo) UpdateTaskStatus(task *TaskInfo, status TaskStatus) error {
@@ -214,6 +454,9 @@ func (ps *JobInfo) deleteTaskIndex(pi *TaskInfo) {
 	}
 }
 
+// DeleteTaskInfo removes the task with the same UID as pi; the resources and
+// status index are updated by the task in job, whose status and requests may
+// be different from pi, e.g. it's being bound or resized.
 func (ps *JobInfo) DeleteTaskInfo(pi *TaskInfo) {
 	if task, found := ps.Tasks[pi.UID]; found {
 		ps.TotalRequest.Sub(task.Resreq)
@@ -223,23 +466,44 @@ func (ps *JobInfo) DeleteTaskInfo(pi *TaskInfo) {
 		}
 
 		delete(ps.Tasks, pi.UID)
+		ps.deleteTaskIndex(task)
+	} else {
+		ps.deleteTaskIndex(pi)
 	}
 
-	ps.deleteTaskIndex(pi)
+	ps.validate()
 }
 
 func (ps *JobInfo) Clone() *JobInfo {
+	return ps.CloneWith(nil)
+}
+
+// CloneWith returns a copy of the job whose tasks are cloned by clones, so the
+// tasks shared with nodes are still shared by the copies.
+func (ps *JobInfo) CloneWith(clones TaskClones) *JobInfo {
 	info := &JobInfo{
-		UID:  ps.UID,
-		Name: ps.Name,
+		UID:       ps.UID,
+		Name:      ps.Name,
+		Namespace: ps.Namespace,
 
 		MinAvailable: ps.MinAvailable,
+		Suspended:    ps.Suspended,
+		Implicit:     ps.Implicit,
+		BindFailures: ps.BindFailures,
+		BackoffUntil: ps.BackoffUntil,
 		NodeSelector: map[string]string{},
-		Allocated:    ps.Allocated.Clone(),
-		TotalRequest: ps.TotalRequest.Clone(),
 
-		TaskStatusIndex: map[TaskStatus]tasksMap{},
-		Tasks:           tasksMap{},
+		ScheduleFailures: ps.ScheduleFailures,
+
+		Queue:             ps.Queue,
+		CreationTimestamp: ps.CreationTimestamp,
+
+		// They are summed by AddTaskInfo.
+		Allocated:    EmptyResource(),
+		TotalRequest: EmptyResource(),
+
+		TaskStatusIndex: make(map[TaskStatus]tasksMap, len(ps.TaskStatusIndex)),
+		Tasks:           make(tasksMap, len(ps.Tasks)),
 	}
 
 	for k, v := range ps.NodeSelector {
@@ -247,12 +511,26 @@ func (ps *JobInfo) Clone() *JobInfo {
 	}
 
 	for _, task := range ps.Tasks {
-		info.AddTaskInfo(task.Clone())
+		info.AddTaskInfo(clones.clone(task))
 	}
 
 	return info
 }
 
+// ReadyTaskNum returns the number of tasks which occupied resources or
+// succeeded. The releasing tasks are not counted, as they're being deleted,
+// so the gang of their replacements is scheduled as a whole.
+func (ps *JobInfo) ReadyTaskNum() int {
+	occupied := 0
+	for status, tasks := range ps.TaskStatusIndex {
+		if (OccupiedResources(status) && status != Releasing) || status == Succeeded {
+			occupied = occupied + len(tasks)
+		}
+	}
+
+	return occupied
+}
+
 func (ps JobInfo) String() string {
 	res := ""
 
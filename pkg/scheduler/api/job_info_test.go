@@ -22,6 +22,9 @@ import (
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
 )
 
 func jobInfoEqual(l, r *JobInfo) bool {
@@ -192,3 +195,132 @@ func TestDeleteTaskInfo(t *testing.T) {
 		}
 	}
 }
+
+func TestSparkJobID(t *testing.T) {
+	driver := buildPod("c1", "driver", "", v1.PodPending, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{buildOwnerReference("app")},
+		map[string]string{SparkAppSelectorLabel: "spark-1", SparkRoleLabel: SparkDriverRole})
+	// The executors are owned by the driver pod.
+	executor := buildPod("c1", "exec-1", "", v1.PodPending, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{buildOwnerReference("driver")},
+		map[string]string{SparkAppSelectorLabel: "spark-1", SparkRoleLabel: "executor"})
+
+	expected := GroupJobID("c1", "spark-1")
+	if job := NewTaskInfo(driver).Job; job != expected {
+		t.Errorf("expected driver in job %v, got %v", expected, job)
+	}
+	if job := NewTaskInfo(executor).Job; job != expected {
+		t.Errorf("expected executor in job %v, got %v", expected, job)
+	}
+	if !IsSparkDriver(driver) || IsSparkDriver(executor) {
+		t.Errorf("expected only the driver recognized as Spark driver")
+	}
+}
+
+func TestMPIJobID(t *testing.T) {
+	// The launcher and workers are owned by a Job and a StatefulSet.
+	launcher := buildPod("c1", "launcher", "", v1.PodPending, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{buildOwnerReference("job")}, map[string]string{MPIJobNameLabel: "mpi"})
+	worker := buildPod("c1", "worker-0", "", v1.PodPending, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{buildOwnerReference("sts")}, map[string]string{MPIJobNameLabel: "mpi"})
+
+	expected := GroupJobID("c1", "mpi")
+	if NewTaskInfo(launcher).Job != expected || NewTaskInfo(worker).Job != expected {
+		t.Errorf("expected launcher and worker in job %v", expected)
+	}
+}
+
+func TestNamespaceQueue(t *testing.T) {
+	utils.NamespaceQueues = map[string]string{"tenant-a": "qa"}
+	defer func() { utils.NamespaceQueues = nil }()
+
+	ss := &arbv1.SchedulingSpec{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-a", Name: "ss"},
+		Spec:       arbv1.SchedulingSpecTemplate{Queue: "qb"},
+	}
+
+	job := NewJobInfo("job")
+	job.SetSchedulingSpec(ss)
+	if job.Queue != "qa" {
+		t.Errorf("expected job in queue of namespace qa, got %v", job.Queue)
+	}
+
+	ss.Namespace = "tenant-b"
+	job.SetSchedulingSpec(ss)
+	if job.Queue != "qb" {
+		t.Errorf("expected job in queue of SchedulingSpec qb, got %v", job.Queue)
+	}
+}
+
+func TestRuntimeClassOverhead(t *testing.T) {
+	RuntimeClassOverheads = map[string]v1.ResourceList{"kata": buildResourceList("250m", "160M")}
+	defer func() { RuntimeClassOverheads = nil }()
+
+	pod := buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1000m", "1G"), nil, nil)
+	if req := NewTaskInfo(pod).Resreq; req.MilliCPU != 1000 {
+		t.Errorf("expected 1000m cpu requested without RuntimeClass, got %v", req)
+	}
+
+	pod.Annotations = map[string]string{RuntimeClassAnnotation: "kata"}
+	if req := NewTaskInfo(pod).Resreq; req.MilliCPU != 1250 || req.Memory != 1160000000 {
+		t.Errorf("expected the overhead of kata requested, got %v", req)
+	}
+}
+
+func TestImplicitJob(t *testing.T) {
+	utils.NamespaceQueues = map[string]string{"c1": "qa"}
+	defer func() { utils.NamespaceQueues = nil }()
+
+	pod := buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1000m", "1G"), nil, nil)
+	task := NewTaskInfo(pod)
+	if task.Job != JobID(pod.UID) {
+		t.Fatalf("expected the pod without controller in its implicit job, got %v", task.Job)
+	}
+
+	job := NewJobInfo(task.Job)
+	job.SetImplicitPod(pod)
+	if !job.Implicit || job.MinAvailable != 1 || job.Queue != "qa" || job.Name != "p1" {
+		t.Errorf("expected an implicit job of p1 in queue qa, got %+v", job)
+	}
+	if clone := job.Clone(); !clone.Implicit {
+		t.Errorf("expected the clone of implicit job implicit")
+	}
+}
+
+func TestInitContainerRequest(t *testing.T) {
+	pod := buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1000m", "1G"), nil, nil)
+	pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{
+		Resources: v1.ResourceRequirements{Requests: buildResourceList("1000m", "1G")},
+	})
+	pod.Spec.InitContainers = []v1.Container{
+		{Resources: v1.ResourceRequirements{Requests: buildResourceList("3000m", "1G")}},
+		{Resources: v1.ResourceRequirements{Requests: buildResourceList("500m", "500M")}},
+	}
+
+	// The larger of the containers and each init container by resource.
+	if req := NewTaskInfo(pod).Resreq; req.MilliCPU != 3000 || req.Memory != 2000000000 {
+		t.Errorf("expected 3000m cpu and 2G memory requested, got %v", req)
+	}
+}
+
+func TestNeverPreempts(t *testing.T) {
+	pod := buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1000m", "1G"), nil, nil)
+	task := NewTaskInfo(pod)
+	job := NewJobInfo(task.Job)
+	job.SetSchedulingSpec(&arbv1.SchedulingSpec{ObjectMeta: metav1.ObjectMeta{Namespace: "c1", Name: "ss"}})
+
+	if NeverPreempts(job, task) {
+		t.Errorf("expected task preempts by default")
+	}
+
+	job.SchedSpec.Annotations = map[string]string{PreemptionPolicyAnnotation: PreemptNever}
+	if !NeverPreempts(job, task) {
+		t.Errorf("expected task of job annotated Never does not preempt")
+	}
+
+	job.SchedSpec.Annotations = nil
+	pod.Annotations = map[string]string{PreemptionPolicyAnnotation: PreemptNever}
+	if !NeverPreempts(job, task) {
+		t.Errorf("expected task annotated Never does not preempt")
+	}
+}
@@ -17,14 +17,64 @@ limitations under the License.
 package api
 
 import (
-	"github.com/golang/glog"
+	"time"
 
 	"k8s.io/api/core/v1"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+)
+
+// SystemReserved is the resources reserved on each node besides the
+// reservations of kubelet, e.g. for the daemons not accounted by pods; they're
+// subtracted from the allocatable resources of nodes.
+var SystemReserved *Resource
+
+// NodePoolLabel is the label of nodes whose value is the name of node pool,
+// e.g. the instance type; the node metrics are aggregated by pool if it's set.
+var NodePoolLabel string
+
+// NodeFailureToleration is how long the pods tolerate the NoExecute taints of
+// the nodes not ready or unreachable: it's the toleration of the pods not
+// tolerating them, and bounds the tolerationSeconds of the ones tolerating
+// them, so the gangs on failed nodes are re-scheduled soon instead of by the
+// default 5 minutes of apiserver. 0 disables it.
+var NodeFailureToleration time.Duration
+
+const (
+	// NodeOSLabel is the label of the operating system of node set by
+	// kubelet, e.g. "linux" or "windows".
+	NodeOSLabel = "beta.kubernetes.io/os"
+	// NodeOSStableLabel is the stable label of the operating system of node,
+	// which replaces NodeOSLabel in later releases of Kubernetes.
+	NodeOSStableLabel = "kubernetes.io/os"
+
+	// NodeUnschedulableTaint is the taint of the nodes marked unschedulable;
+	// the pods tolerating it, e.g. of DaemonSets, are still placed on them.
+	NodeUnschedulableTaint = "node.kubernetes.io/unschedulable"
+
+	// The taints of the nodes under pressure, set by the node controller by
+	// their conditions.
+	NodeMemoryPressureTaint = "node.kubernetes.io/memory-pressure"
+	NodeDiskPressureTaint   = "node.kubernetes.io/disk-pressure"
+	NodePIDPressureTaint    = "node.kubernetes.io/pid-pressure"
+
+	// The taints of the nodes not ready or unreachable, set by the node
+	// controller with both NoSchedule and NoExecute effects.
+	NodeNotReadyTaint    = "node.kubernetes.io/not-ready"
+	NodeUnreachableTaint = "node.kubernetes.io/unreachable"
+
+	// LinuxOS is the operating system of the nodes without OS label, and the
+	// pods without OS requirements.
+	LinuxOS = "linux"
+	// WindowsOS is the operating system of Windows nodes.
+	WindowsOS = "windows"
 )
 
 // NodeInfo is node level aggregated information.
 type NodeInfo struct {
 	Name string
+	// Node is shared by the informer cache, scheduler cache and snapshots, so
+	// it must not be modified.
 	Node *v1.Node
 
 	// The idle resource on that node
@@ -32,18 +82,63 @@ type NodeInfo struct {
 	// The used resource on that node, including running and terminating
 	// pods
 	Used *Resource
+	// The releasing resource on that node, of the terminating pods; it's
+	// still in Used until the pods are deleted.
+	Releasing *Resource
 
 	Allocatable *Resource
 	Capability  *Resource
 
 	Tasks map[TaskID]*TaskInfo
+
+	// GPUDevices is the usage of the GPUs of node shared by GPU shares, nil
+	// if its GPUs are not shared.
+	GPUDevices []*GPUDevice
+
+	// Unschedulable is whether no task is placed on the node, e.g. its
+	// heartbeat is stale; it's set in the snapshot of cache.
+	Unschedulable bool
+}
+
+// Pool returns the name of node pool by NodePoolLabel, empty if not set.
+func (ni *NodeInfo) Pool() string {
+	if len(NodePoolLabel) == 0 || ni.Node == nil {
+		return ""
+	}
+	return ni.Node.Labels[NodePoolLabel]
+}
+
+// OS returns the operating system of node by its OS labels, LinuxOS if it's
+// not labeled.
+func (ni *NodeInfo) OS() string {
+	if ni.Node == nil {
+		return LinuxOS
+	}
+	if os, found := ni.Node.Labels[NodeOSStableLabel]; found {
+		return os
+	}
+	if os, found := ni.Node.Labels[NodeOSLabel]; found {
+		return os
+	}
+	return LinuxOS
+}
+
+// nodeAllocatable returns the resources of node for pods: its allocatable
+// resources, as kubelet admits pods by, less SystemReserved.
+func nodeAllocatable(node *v1.Node) *Resource {
+	allocatable := NewResource(node.Status.Allocatable)
+	if SystemReserved != nil {
+		allocatable.SubFloor(SystemReserved)
+	}
+	return allocatable
 }
 
 func NewNodeInfo(node *v1.Node) *NodeInfo {
 	if node == nil {
 		return &NodeInfo{
-			Idle: EmptyResource(),
-			Used: EmptyResource(),
+			Idle:      EmptyResource(),
+			Used:      EmptyResource(),
+			Releasing: EmptyResource(),
 
 			Allocatable: EmptyResource(),
 			Capability:  EmptyResource(),
@@ -53,23 +148,32 @@ func NewNodeInfo(node *v1.Node) *NodeInfo {
 	}
 
 	return &NodeInfo{
-		Name: node.Name,
-		Node: node,
-		Idle: NewResource(node.Status.Allocatable),
-		Used: EmptyResource(),
+		Name:      node.Name,
+		Node:      node,
+		Idle:      nodeAllocatable(node),
+		Used:      EmptyResource(),
+		Releasing: EmptyResource(),
 
-		Allocatable: NewResource(node.Status.Allocatable),
+		Allocatable: nodeAllocatable(node),
 		Capability:  NewResource(node.Status.Capacity),
 
 		Tasks: make(map[TaskID]*TaskInfo),
+
+		GPUDevices: newGPUDevices(node),
 	}
 }
 
 func (ni *NodeInfo) Clone() *NodeInfo {
+	return ni.CloneWith(nil)
+}
+
+// CloneWith returns a copy of the node whose tasks are cloned by clones, so
+// the tasks shared with jobs are still shared by the copies.
+func (ni *NodeInfo) CloneWith(clones TaskClones) *NodeInfo {
 	pods := make(map[TaskID]*TaskInfo, len(ni.Tasks))
 
-	for _, p := range ni.Tasks {
-		pods[PodKey(p.Pod)] = p.Clone()
+	for key, p := range ni.Tasks {
+		pods[key] = clones.clone(p)
 	}
 
 	return &NodeInfo{
@@ -77,55 +181,299 @@ func (ni *NodeInfo) Clone() *NodeInfo {
 		Node:        ni.Node,
 		Idle:        ni.Idle.Clone(),
 		Used:        ni.Used.Clone(),
+		Releasing:   ni.Releasing.Clone(),
 		Allocatable: ni.Allocatable.Clone(),
 		Capability:  ni.Capability.Clone(),
 
 		Tasks: pods,
+
+		GPUDevices: cloneGPUDevices(ni.GPUDevices),
+
+		Unschedulable: ni.Unschedulable,
 	}
 }
 
 func (ni *NodeInfo) SetNode(node *v1.Node) {
+	ni.Name = node.Name
+	ni.Node = node
+	ni.Allocatable = nodeAllocatable(node)
+	ni.Capability = NewResource(node.Status.Capacity)
+
+	// The aggregated resources are rebuilt, as the allocatable resources may
+	// change, e.g. by the reservations of kubelet.
+	ni.Idle = ni.Allocatable.Clone()
+	ni.Used = EmptyResource()
+	ni.Releasing = EmptyResource()
+	for _, p := range ni.Tasks {
+		ni.addResource(p)
+	}
+
+	// The usage of GPUs is rebuilt, as the number of shared GPUs may change.
+	ni.GPUDevices = newGPUDevices(node)
+	for _, p := range ni.Tasks {
+		ni.addGPUShare(p)
+	}
+
+	ni.validate()
+}
+
+// UnsetNode makes the node a placeholder of its tasks after the node is
+// deleted: no resource is available until it's set again by SetNode, which
+// accounts the tasks again.
+func (ni *NodeInfo) UnsetNode() {
+	ni.Node = nil
+	ni.Idle = EmptyResource()
+	ni.Used = EmptyResource()
+	ni.Releasing = EmptyResource()
+	ni.Allocatable = EmptyResource()
+	ni.Capability = EmptyResource()
+	ni.GPUDevices = nil
+}
+
+// addResource adds the resource of p into the aggregated resources. The idle
+// resource is not subtracted by Resource.Sub, which panics if the node is
+// overcommitted, e.g. by the pods bound by another scheduler at the same time.
+func (ni *NodeInfo) addResource(p *TaskInfo) {
+	ni.Idle.MilliCPU -= p.Resreq.MilliCPU
+	ni.Idle.Memory -= p.Resreq.Memory
+	ni.Idle.GPU -= p.Resreq.GPU
+	ni.Used.Add(p.Resreq)
+	if p.Status == Releasing {
+		ni.Releasing.Add(p.Resreq)
+	}
+}
+
+// subResource subtracts the resource of p from the aggregated resources.
+func (ni *NodeInfo) subResource(p *TaskInfo) {
+	ni.Idle.Add(p.Resreq)
+	ni.Used.Sub(p.Resreq)
+	if p.Status == Releasing {
+		ni.Releasing.Sub(p.Resreq)
+	}
+}
+
+// Cordoned returns whether the node is marked unschedulable, e.g. by kubectl
+// cordon: no new task is placed on it unless the task tolerates it, but its
+// tasks are kept running.
+func (ni *NodeInfo) Cordoned() bool {
+	return ni.Node != nil && ni.Node.Spec.Unschedulable
+}
+
+// ToleratesCordon returns whether pod can be placed on the cordoned nodes by
+// tolerating NodeUnschedulableTaint.
+func ToleratesCordon(pod *v1.Pod) bool {
+	return toleratesNoSchedule(pod, NodeUnschedulableTaint)
+}
+
+// toleratesNoSchedule returns whether pod tolerates the NoSchedule taint of
+// key.
+func toleratesNoSchedule(pod *v1.Pod, key string) bool {
+	if pod == nil {
+		return false
+	}
+
+	taint := &v1.Taint{Key: key, Effect: v1.TaintEffectNoSchedule}
+	for i := range pod.Spec.Tolerations {
+		if pod.Spec.Tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodePressures is the conditions of node pressure, with their taints and
+// the reasons of the nodes under them.
+var nodePressures = []struct {
+	condition v1.NodeConditionType
+	taint     string
+	reason    string
+}{
+	{v1.NodeMemoryPressure, NodeMemoryPressureTaint, NodeMemoryPressure},
+	{v1.NodeDiskPressure, NodeDiskPressureTaint, NodeDiskPressure},
+	{v1.NodePIDPressure, NodePIDPressureTaint, NodePIDPressure},
+}
+
+// PressureReasons returns the reasons why pod is not placed on the node by
+// the pressure conditions or their taints, as kubelet evicts the pods under
+// pressure; the pressure whose taint is tolerated by pod is ignored.
+func (ni *NodeInfo) PressureReasons(pod *v1.Pod) []string {
 	if ni.Node == nil {
-		ni.Idle = NewResource(node.Status.Allocatable)
+		return nil
+	}
 
-		for _, p := range ni.Tasks {
-			ni.Idle.Sub(p.Resreq)
-			ni.Used.Add(p.Resreq)
+	var reasons []string
+	for _, pressure := range nodePressures {
+		if !ni.underPressure(pressure.condition, pressure.taint) || toleratesNoSchedule(pod, pressure.taint) {
+			continue
 		}
+		reasons = append(reasons, pressure.reason)
 	}
+	return reasons
+}
 
-	ni.Name = node.Name
-	ni.Node = node
-	ni.Allocatable = NewResource(node.Status.Allocatable)
-	ni.Capability = NewResource(node.Status.Capacity)
+// underPressure returns whether the node has the condition or the taint of
+// a pressure.
+func (ni *NodeInfo) underPressure(condition v1.NodeConditionType, taint string) bool {
+	for _, c := range ni.Node.Status.Conditions {
+		if c.Type == condition && c.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	for _, t := range ni.Node.Spec.Taints {
+		if t.Key == taint {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeFailures is the taints of the failed nodes, with the reasons of the
+// nodes tainted by them.
+var nodeFailures = []struct {
+	taint  string
+	reason string
+}{
+	{NodeNotReadyTaint, NodeNotReady},
+	{NodeUnreachableTaint, NodeUnreachable},
+}
+
+// FailureReasons returns the reasons why pod is not placed on the node which
+// is not ready or unreachable: the NoSchedule taints are ignored if pod
+// tolerates them, but the NoExecute ones only if pod tolerates them forever,
+// which it does not if NodeFailureToleration is set.
+func (ni *NodeInfo) FailureReasons(pod *v1.Pod) []string {
+	if ni.Node == nil {
+		return nil
+	}
+
+	var reasons []string
+	for _, failure := range nodeFailures {
+		for i := range ni.Node.Spec.Taints {
+			taint := &ni.Node.Spec.Taints[i]
+			if taint.Key != failure.taint {
+				continue
+			}
+			switch taint.Effect {
+			case v1.TaintEffectNoSchedule:
+				if toleratesNoSchedule(pod, taint.Key) {
+					continue
+				}
+			case v1.TaintEffectNoExecute:
+				if _, forever := failureToleration(pod, taint); forever {
+					continue
+				}
+			default:
+				continue
+			}
+			reasons = append(reasons, failure.reason)
+			break
+		}
+	}
+	return reasons
+}
+
+// FailureExpired returns whether pod on the node has tolerated the NoExecute
+// taint of node failure longer than NodeFailureToleration at now, so it's
+// evicted to be re-scheduled; it's never expired if NodeFailureToleration is
+// not set, as the pod is evicted by the node controller instead.
+func (ni *NodeInfo) FailureExpired(pod *v1.Pod, now time.Time) bool {
+	if NodeFailureToleration <= 0 || ni.Node == nil {
+		return false
+	}
+
+	for i := range ni.Node.Spec.Taints {
+		taint := &ni.Node.Spec.Taints[i]
+		if taint.Effect != v1.TaintEffectNoExecute || taint.TimeAdded == nil ||
+			(taint.Key != NodeNotReadyTaint && taint.Key != NodeUnreachableTaint) {
+			continue
+		}
+		if toleration, _ := failureToleration(pod, taint); now.Sub(taint.TimeAdded.Time) > toleration {
+			return true
+		}
+	}
+	return false
+}
+
+// failureToleration returns how long pod tolerates the NoExecute taint of
+// node failure, bounded by NodeFailureToleration if it's set, and whether it
+// tolerates the taint forever.
+func failureToleration(pod *v1.Pod, taint *v1.Taint) (time.Duration, bool) {
+	var toleration *v1.Toleration
+	if pod != nil {
+		for i := range pod.Spec.Tolerations {
+			t := &pod.Spec.Tolerations[i]
+			if t.ToleratesTaint(&v1.Taint{Key: taint.Key, Effect: v1.TaintEffectNoExecute}) {
+				toleration = t
+				break
+			}
+		}
+	}
+
+	switch {
+	case toleration == nil:
+		return NodeFailureToleration, false
+	case toleration.TolerationSeconds == nil:
+		if NodeFailureToleration > 0 {
+			return NodeFailureToleration, false
+		}
+		return 0, true
+	}
+
+	d := time.Duration(*toleration.TolerationSeconds) * time.Second
+	if d < 0 {
+		d = 0
+	}
+	if NodeFailureToleration > 0 && d > NodeFailureToleration {
+		d = NodeFailureToleration
+	}
+	return d, false
+}
+
+// FutureIdle returns the resources of node which are idle once its releasing
+// tasks are deleted.
+func (ni *NodeInfo) FutureIdle() *Resource {
+	return ni.Idle.Clone().Add(ni.Releasing)
+}
+
+// Overcommitted returns whether the tasks on node request more resources than
+// its allocatable.
+func (ni *NodeInfo) Overcommitted() bool {
+	return ni.Idle.MilliCPU < -0.01 || ni.Idle.Memory < -1 || ni.Idle.GPU < 0
 }
 
 func (ni *NodeInfo) AddTask(p *TaskInfo) {
 	key := PodKey(p.Pod)
 	if _, found := ni.Tasks[key]; found {
-		glog.Errorf("Task <%v/%v> already on node <%v>, should not add again.",
-			p.Namespace, p.Name, ni.Name)
+		logging.Error(nil, "Task already on node, should not add again",
+			"job", p.Job, "task", p.UID, "node", ni.Name)
 		return
 	}
 
 	if ni.Node != nil {
-		ni.Idle.Sub(p.Resreq)
-		ni.Used.Add(p.Resreq)
+		ni.addResource(p)
+		ni.addGPUShare(p)
 	}
 
 	ni.Tasks[key] = p
+
+	ni.validate()
 }
 
+// RemoveTask removes the task with the same key as p; the resources are
+// subtracted by the task on node, whose status may be different from p.
 func (ni *NodeInfo) RemoveTask(p *TaskInfo) {
 	key := PodKey(p.Pod)
-	if _, found := ni.Tasks[key]; !found {
+	task, found := ni.Tasks[key]
+	if !found {
 		return
 	}
 
 	if ni.Node != nil {
-		ni.Idle.Add(p.Resreq)
-		ni.Used.Sub(p.Resreq)
+		ni.subResource(task)
+		ni.subGPUShare(task)
 	}
 
-	delete(ni.Tasks, PodKey(p.Pod))
+	delete(ni.Tasks, key)
+
+	ni.validate()
 }
@@ -19,6 +19,7 @@ package api
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -38,6 +39,12 @@ func TestNodeInfo_AddPod(t *testing.T) {
 	case01_pod1 := buildPod("c1", "p1", "n1", v1.PodRunning, buildResourceList("1000m", "1G"), []metav1.OwnerReference{}, make(map[string]string))
 	case01_pod2 := buildPod("c1", "p2", "n1", v1.PodRunning, buildResourceList("2000m", "2G"), []metav1.OwnerReference{}, make(map[string]string))
 
+	// case2
+	case02_node := buildNode("n2", buildResourceList("8000m", "10G"))
+	case02_pod1 := buildPod("c2", "p1", "n2", v1.PodRunning, buildResourceList("1000m", "1G"), []metav1.OwnerReference{}, make(map[string]string))
+	case02_pod2 := buildPod("c2", "p2", "n2", v1.PodRunning, buildResourceList("2000m", "2G"), []metav1.OwnerReference{}, make(map[string]string))
+	case02_pod2.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
 	tests := []struct {
 		name     string
 		node     *v1.Node
@@ -53,6 +60,7 @@ func TestNodeInfo_AddPod(t *testing.T) {
 				Node:        case01_node,
 				Idle:        buildResource("5000m", "7G"),
 				Used:        buildResource("3000m", "3G"),
+				Releasing:   EmptyResource(),
 				Allocatable: buildResource("8000m", "10G"),
 				Capability:  buildResource("8000m", "10G"),
 				Tasks: map[TaskID]*TaskInfo{
@@ -61,6 +69,24 @@ func TestNodeInfo_AddPod(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "add 1 running pod and 1 releasing pod",
+			node: case02_node,
+			pods: []*v1.Pod{case02_pod1, case02_pod2},
+			expected: &NodeInfo{
+				Name:        "n2",
+				Node:        case02_node,
+				Idle:        buildResource("5000m", "7G"),
+				Used:        buildResource("3000m", "3G"),
+				Releasing:   buildResource("2000m", "2G"),
+				Allocatable: buildResource("8000m", "10G"),
+				Capability:  buildResource("8000m", "10G"),
+				Tasks: map[TaskID]*TaskInfo{
+					"c2/p1": NewTaskInfo(case02_pod1),
+					"c2/p2": NewTaskInfo(case02_pod2),
+				},
+			},
+		},
 	}
 
 	for i, test := range tests {
@@ -78,6 +104,27 @@ func TestNodeInfo_AddPod(t *testing.T) {
 	}
 }
 
+func TestNodeInfo_Allocatable(t *testing.T) {
+	SystemReserved = buildResource("500m", "1G")
+	defer func() { SystemReserved = nil }()
+
+	node := buildNode("n1", buildResourceList("8000m", "10G"))
+	node.Status.Capacity = buildResourceList("10000m", "12G")
+
+	ni := NewNodeInfo(node)
+	ni.AddTask(NewTaskInfo(buildPod("c1", "p1", "n1", v1.PodRunning, buildResourceList("1000m", "1G"), nil, nil)))
+	if !reflect.DeepEqual(ni.Idle, buildResource("6500m", "8G")) {
+		t.Errorf("expected idle of allocatable less reserved and pods, got %v", ni.Idle)
+	}
+
+	// The idle resources follow the allocatable resources of node.
+	updated := buildNode("n1", buildResourceList("6000m", "10G"))
+	ni.SetNode(updated)
+	if !reflect.DeepEqual(ni.Idle, buildResource("4500m", "8G")) || !reflect.DeepEqual(ni.Used, buildResource("1000m", "1G")) {
+		t.Errorf("expected idle rebuilt by the new allocatable, got idle %v, used %v", ni.Idle, ni.Used)
+	}
+}
+
 func TestNodeInfo_RemovePod(t *testing.T) {
 	// case1
 	case01_node := buildNode("n1", buildResourceList("8000m", "10G"))
@@ -102,6 +149,7 @@ func TestNodeInfo_RemovePod(t *testing.T) {
 				Node:        case01_node,
 				Idle:        buildResource("4000m", "6G"),
 				Used:        buildResource("4000m", "4G"),
+				Releasing:   EmptyResource(),
 				Allocatable: buildResource("8000m", "10G"),
 				Capability:  buildResource("8000m", "10G"),
 				Tasks: map[TaskID]*TaskInfo{
@@ -131,3 +179,189 @@ func TestNodeInfo_RemovePod(t *testing.T) {
 		}
 	}
 }
+
+func TestPodOSes(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     v1.PodSpec
+		expected []string
+	}{
+		{
+			name:     "no OS requirement runs on Linux",
+			expected: []string{LinuxOS},
+		},
+		{
+			name:     "node selector",
+			spec:     v1.PodSpec{NodeSelector: map[string]string{NodeOSLabel: WindowsOS}},
+			expected: []string{WindowsOS},
+		},
+		{
+			name: "node affinity",
+			spec: v1.PodSpec{Affinity: &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+					NodeSelectorTerms: []v1.NodeSelectorTerm{{
+						MatchExpressions: []v1.NodeSelectorRequirement{{
+							Key:      NodeOSStableLabel,
+							Operator: v1.NodeSelectorOpIn,
+							Values:   []string{LinuxOS, WindowsOS},
+						}},
+					}},
+				},
+			}}},
+			expected: []string{LinuxOS, WindowsOS},
+		},
+	}
+
+	for _, test := range tests {
+		if oses := PodOSes(&v1.Pod{Spec: test.spec}); !reflect.DeepEqual(oses, test.expected) {
+			t.Errorf("case %s: expected OSes %v, got %v", test.name, test.expected, oses)
+		}
+	}
+
+	node := NewNodeInfo(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1"}})
+	if os := node.OS(); os != LinuxOS {
+		t.Errorf("expected node without OS label is Linux, got %s", os)
+	}
+}
+
+func TestNodeInfo_GPUShare(t *testing.T) {
+	node := buildNode("n1", buildResourceList("8000m", "10G"))
+	node.Annotations = map[string]string{
+		NodeGPUDevicesAnnotation:      "2",
+		NodeGPUDeviceMemoryAnnotation: "16Gi",
+	}
+	ni := NewNodeInfo(node)
+
+	share := func(name, core, memory string) *TaskInfo {
+		pod := buildPod("c1", name, "", v1.PodPending, buildResourceList("1000m", "1G"), []metav1.OwnerReference{}, make(map[string]string))
+		pod.Annotations = map[string]string{GPUCoreAnnotation: core, GPUMemoryAnnotation: memory}
+		return NewTaskInfo(pod)
+	}
+
+	// The second share is placed onto the GPU used by the first one.
+	p1 := share("p1", "50", "8Gi")
+	p2 := share("p2", "30", "4Gi")
+	ni.AddTask(p1)
+	ni.AddTask(p2)
+	if p1.GPUShare.Device != 0 || p2.GPUShare.Device != 0 {
+		t.Errorf("expected both shares on GPU 0, got %d and %d", p1.GPUShare.Device, p2.GPUShare.Device)
+	}
+
+	// The memory of GPU 0 is not enough.
+	p3 := share("p3", "10", "8Gi")
+	if device := ni.FitGPUShare(p3.GPUShare); device != 1 {
+		t.Errorf("expected share fits GPU 1, got %d", device)
+	}
+
+	// The usage is kept by clones and rebuilt by SetNode.
+	clone := ni.Clone()
+	ni.SetNode(node)
+	for _, n := range []*NodeInfo{clone, ni} {
+		if d := n.GPUDevices[0]; d.Core != 80 || d.Memory != 12*1024*1024*1024 {
+			t.Errorf("expected 80%% core and 12Gi memory used on GPU 0, got %+v", d)
+		}
+	}
+
+	ni.RemoveTask(p1)
+	if d := ni.GPUDevices[0]; d.Core != 30 {
+		t.Errorf("expected 30%% core used on GPU 0 after removing share, got %d", d.Core)
+	}
+
+	if device := ni.FitGPUShare(share("p4", "10", "17Gi").GPUShare); device != -1 {
+		t.Errorf("expected no GPU fits, got %d", device)
+	}
+}
+
+func TestGPUShares(t *testing.T) {
+	node := buildNode("n1", buildResourceList("8000m", "10G"))
+	node.Annotations = map[string]string{NodeGPUDevicesAnnotation: "1"}
+	ni := NewNodeInfo(node)
+
+	share := &GPUShare{Core: 60, Device: -1}
+	shares := GPUShares{}
+	if device := shares.FitGPUShare(ni, share); device != 0 {
+		t.Errorf("expected share fits GPU 0, got %d", device)
+	}
+
+	// The share added is counted for the next one, but not on node.
+	shares.Add(ni, share)
+	if device := shares.FitGPUShare(ni, share); device != -1 {
+		t.Errorf("expected no GPU fits after the share added, got %d", device)
+	}
+	if d := ni.GPUDevices[0]; d.Core != 0 {
+		t.Errorf("expected no core used on GPU 0 of node, got %d", d.Core)
+	}
+}
+
+func TestNodeInfo_PressureReasons(t *testing.T) {
+	node := &v1.Node{
+		Spec: v1.NodeSpec{
+			Taints: []v1.Taint{{Key: NodePIDPressureTaint, Effect: v1.TaintEffectNoSchedule}},
+		},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{
+				{Type: v1.NodeMemoryPressure, Status: v1.ConditionTrue},
+				{Type: v1.NodeDiskPressure, Status: v1.ConditionFalse},
+			},
+		},
+	}
+	ni := NewNodeInfo(node)
+
+	pod := &v1.Pod{}
+	if reasons := ni.PressureReasons(pod); !reflect.DeepEqual(reasons, []string{NodeMemoryPressure, NodePIDPressure}) {
+		t.Errorf("expected memory and pid pressure, got %v", reasons)
+	}
+
+	pod.Spec.Tolerations = []v1.Toleration{
+		{Key: NodeMemoryPressureTaint, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+	}
+	if reasons := ni.PressureReasons(pod); !reflect.DeepEqual(reasons, []string{NodePIDPressure}) {
+		t.Errorf("expected pid pressure only by toleration, got %v", reasons)
+	}
+}
+
+func TestNodeInfo_FailureReasons(t *testing.T) {
+	defer func(d time.Duration) { NodeFailureToleration = d }(NodeFailureToleration)
+
+	added := metav1.NewTime(time.Now().Add(-time.Minute))
+	ni := NewNodeInfo(&v1.Node{
+		Spec: v1.NodeSpec{
+			Taints: []v1.Taint{
+				{Key: NodeUnreachableTaint, Effect: v1.TaintEffectNoSchedule},
+				{Key: NodeUnreachableTaint, Effect: v1.TaintEffectNoExecute, TimeAdded: &added},
+			},
+		},
+	})
+
+	seconds := int64(300)
+	forever := &v1.Pod{Spec: v1.PodSpec{Tolerations: []v1.Toleration{
+		{Key: NodeUnreachableTaint, Operator: v1.TolerationOpExists},
+	}}}
+	bounded := &v1.Pod{Spec: v1.PodSpec{Tolerations: []v1.Toleration{
+		{Key: NodeUnreachableTaint, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute,
+			TolerationSeconds: &seconds},
+	}}}
+
+	for _, test := range []struct {
+		toleration time.Duration
+		pod        *v1.Pod
+		reasons    []string
+		expired    bool
+	}{
+		{pod: &v1.Pod{}, reasons: []string{NodeUnreachable}},
+		{pod: forever},
+		{pod: bounded, reasons: []string{NodeUnreachable}},
+		// The tolerations are bounded or injected by NodeFailureToleration.
+		{toleration: 30 * time.Second, pod: forever, reasons: []string{NodeUnreachable}, expired: true},
+		{toleration: 30 * time.Second, pod: &v1.Pod{}, reasons: []string{NodeUnreachable}, expired: true},
+		{toleration: 10 * time.Minute, pod: bounded, reasons: []string{NodeUnreachable}},
+	} {
+		NodeFailureToleration = test.toleration
+		if reasons := ni.FailureReasons(test.pod); !reflect.DeepEqual(reasons, test.reasons) {
+			t.Errorf("expected reasons %v with toleration %v, got %v", test.reasons, test.toleration, reasons)
+		}
+		if expired := ni.FailureExpired(test.pod, time.Now()); expired != test.expired {
+			t.Errorf("expected expired %v with toleration %v, got %v", test.expired, test.toleration, expired)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"sync"
+)
+
+// taskInfoPool reuses the TaskInfo, and its Resreq, which are cloned into the
+// snapshot of every session.
+var taskInfoPool = sync.Pool{
+	New: func() interface{} {
+		return &TaskInfo{Resreq: EmptyResource()}
+	},
+}
+
+// newTaskInfoFromPool returns a TaskInfo from pool; all fields except Resreq
+// have to be set by caller.
+func newTaskInfoFromPool() *TaskInfo {
+	return taskInfoPool.Get().(*TaskInfo)
+}
+
+// releaseTaskInfo puts task back to pool; the task must not be used anymore.
+func releaseTaskInfo(task *TaskInfo) {
+	resreq := task.Resreq
+	if resreq == nil {
+		resreq = EmptyResource()
+	}
+	*resreq = Resource{}
+
+	// Reset all fields, so the pod is not kept alive by pool.
+	*task = TaskInfo{Resreq: resreq}
+	taskInfoPool.Put(task)
+}
+
+// TaskClones is the clones of tasks by the original ones, so a task shared by
+// a job and a node, e.g. bound to the node, is cloned once in the snapshot
+// and still shared; the pods are referenced instead of copied. A nil
+// TaskClones clones each task separately.
+type TaskClones map[*TaskInfo]*TaskInfo
+
+func (tc TaskClones) clone(task *TaskInfo) *TaskInfo {
+	if tc == nil {
+		return task.Clone()
+	}
+
+	clone, found := tc[task]
+	if !found {
+		clone = task.Clone()
+		tc[task] = clone
+	}
+	return clone
+}
+
+// ReleaseTasks puts the tasks of jobs and nodes back to pool, e.g. the
+// snapshot of a closed session. A task shared by a job and a node, e.g. bound
+// in the session, is released once. The jobs and nodes must not be used
+// anymore, except for their fields other than tasks.
+func ReleaseTasks(jobs []*JobInfo, nodes []*NodeInfo) {
+	released := map[*TaskInfo]bool{}
+
+	release := func(tasks map[TaskID]*TaskInfo) {
+		for _, task := range tasks {
+			if !released[task] {
+				released[task] = true
+				releaseTaskInfo(task)
+			}
+		}
+	}
+
+	for _, job := range jobs {
+		release(job.Tasks)
+		job.Tasks = nil
+		job.TaskStatusIndex = nil
+	}
+	for _, node := range nodes {
+		release(node.Tasks)
+		node.Tasks = nil
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+)
+
+// QueueInfo is the information of Queue which the jobs belong to, by
+// JobInfo.Queue.
+type QueueInfo struct {
+	Name string
+
+	Weight int32
+
+	// Capability is the upper limit of resources of the queue, nil means
+	// unlimited.
+	Capability *Resource
+
+	// Closed means the jobs of the queue not started yet are not scheduled.
+	Closed bool
+
+	Queue *arbv1.Queue
+}
+
+func NewQueueInfo(queue *arbv1.Queue) *QueueInfo {
+	qi := &QueueInfo{
+		Name:   queue.Name,
+		Weight: queue.Spec.Weight,
+		Closed: queue.Spec.State == arbv1.QueueStateClosed,
+		Queue:  queue,
+	}
+
+	if len(queue.Spec.Capability) != 0 {
+		qi.Capability = NewResource(queue.Spec.Capability)
+	}
+
+	return qi
+}
+
+func (qi *QueueInfo) Clone() *QueueInfo {
+	clone := &QueueInfo{
+		Name:   qi.Name,
+		Weight: qi.Weight,
+		Closed: qi.Closed,
+		Queue:  qi.Queue,
+	}
+
+	if qi.Capability != nil {
+		clone.Capability = qi.Capability.Clone()
+	}
+
+	return clone
+}
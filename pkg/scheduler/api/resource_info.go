@@ -19,8 +19,10 @@ package api
 import (
 	"fmt"
 	"math"
+	"strings"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 type Resource struct {
@@ -56,6 +58,12 @@ var minMemory float64 = 10 * 1024 * 1024
 
 func NewResource(rl v1.ResourceList) *Resource {
 	r := EmptyResource()
+	r.addResourceList(rl)
+	return r
+}
+
+// addResourceList adds the resources in rl, without allocating a Resource.
+func (r *Resource) addResourceList(rl v1.ResourceList) {
 	for rName, rQuant := range rl {
 		switch rName {
 		case v1.ResourceCPU:
@@ -67,7 +75,6 @@ func NewResource(rl v1.ResourceList) *Resource {
 			r.GPU += q
 		}
 	}
-	return r
 }
 
 func (r *Resource) IsEmpty() bool {
@@ -107,6 +114,26 @@ func (r *Resource) Sub(rr *Resource) *Resource {
 		r, rr))
 }
 
+// SubFloor subtracts rr from r, and floors each resource of r at zero.
+func (r *Resource) SubFloor(rr *Resource) *Resource {
+	r.MilliCPU = math.Max(r.MilliCPU-rr.MilliCPU, 0)
+	r.Memory = math.Max(r.Memory-rr.Memory, 0)
+	if r.GPU -= rr.GPU; r.GPU < 0 {
+		r.GPU = 0
+	}
+	return r
+}
+
+// SetMaxResource sets each resource of r to the larger of r and rr.
+func (r *Resource) SetMaxResource(rr *Resource) *Resource {
+	r.MilliCPU = math.Max(r.MilliCPU, rr.MilliCPU)
+	r.Memory = math.Max(r.Memory, rr.Memory)
+	if rr.GPU > r.GPU {
+		r.GPU = rr.GPU
+	}
+	return r
+}
+
 func (r *Resource) Less(rr *Resource) bool {
 	return r.MilliCPU < rr.MilliCPU && r.Memory < rr.Memory && r.GPU < rr.GPU
 }
@@ -122,6 +149,22 @@ func (r *Resource) String() string {
 		r.MilliCPU, r.Memory, r.GPU)
 }
 
+// ResourceList returns the non-zero resources of r as a ResourceList, e.g. to
+// format them as quantities.
+func (r *Resource) ResourceList() v1.ResourceList {
+	rl := v1.ResourceList{}
+	if r.MilliCPU > 0 {
+		rl[v1.ResourceCPU] = *resource.NewMilliQuantity(int64(r.MilliCPU), resource.DecimalSI)
+	}
+	if r.Memory > 0 {
+		rl[v1.ResourceMemory] = *resource.NewQuantity(int64(r.Memory), resource.BinarySI)
+	}
+	if r.GPU > 0 {
+		rl[GPUResourceName] = *resource.NewQuantity(r.GPU, resource.DecimalSI)
+	}
+	return rl
+}
+
 func (r *Resource) Get(rn v1.ResourceName) float64 {
 	switch rn {
 	case v1.ResourceCPU:
@@ -138,3 +181,31 @@ func (r *Resource) Get(rn v1.ResourceName) float64 {
 func ResourceNames() []v1.ResourceName {
 	return []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory, GPUResourceName}
 }
+
+// ParseResourceList parses the resources in spec, e.g. "cpu=500m,memory=1Gi".
+func ParseResourceList(spec string) (v1.ResourceList, error) {
+	rl := v1.ResourceList{}
+	if len(spec) == 0 {
+		return rl, nil
+	}
+
+	for _, item := range strings.Split(spec, ",") {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid resource %q, expected <name>=<quantity>", item)
+		}
+		name := v1.ResourceName(strings.TrimSpace(parts[0]))
+		if name != v1.ResourceCPU && name != v1.ResourceMemory && name != GPUResourceName {
+			return nil, fmt.Errorf("unsupported resource %q", name)
+		}
+		quantity, err := resource.ParseQuantity(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity of resource %q: %v", name, err)
+		}
+		if quantity.Sign() < 0 {
+			return nil, fmt.Errorf("negative quantity of resource %q", name)
+		}
+		rl[name] = quantity
+	}
+	return rl, nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// RuntimeClassAnnotation is the annotation of the RuntimeClass of pod, e.g.
+// "kata" or "gvisor"; the vendored PodSpec has no runtimeClassName, so the
+// RuntimeClass of pod is annotated by its workload.
+const RuntimeClassAnnotation = "kube-arbitrator.k8s.io/runtime-class"
+
+// RuntimeClassOverheads is the pod overhead of RuntimeClasses by name, which
+// is added to the requests of the pods of the RuntimeClass, so the pods
+// fitting a node by the scheduler are also admitted by kubelet.
+var RuntimeClassOverheads map[string]v1.ResourceList
+
+// podOverhead returns the overhead of the RuntimeClass of pod, nil if none.
+func podOverhead(pod *v1.Pod) v1.ResourceList {
+	if len(RuntimeClassOverheads) == 0 {
+		return nil
+	}
+
+	class, found := pod.Annotations[RuntimeClassAnnotation]
+	if !found {
+		return nil
+	}
+	return RuntimeClassOverheads[class]
+}
+
+// RuntimeClass is the part of RuntimeClass (node.k8s.io) used by scheduler.
+type RuntimeClass struct {
+	Name     string `json:"name"`
+	Overhead struct {
+		// PodFixed is the resources used by the runtime of each pod, e.g.
+		// the VM of a kata pod.
+		PodFixed v1.ResourceList `json:"podFixed"`
+	} `json:"overhead"`
+}
+
+// RuntimeClassesConfig is the file of RuntimeClasses in YAML or JSON, e.g.
+//
+//	runtimeClasses:
+//	- name: kata
+//	  overhead:
+//	    podFixed:
+//	      cpu: 250m
+//	      memory: 160Mi
+type RuntimeClassesConfig struct {
+	RuntimeClasses []RuntimeClass `json:"runtimeClasses"`
+}
+
+// LoadRuntimeClassOverheads loads the overhead of RuntimeClasses by name from
+// path.
+func LoadRuntimeClassOverheads(path string) (map[string]v1.ResourceList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config := &RuntimeClassesConfig{}
+	if err := yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(config); err != nil {
+		return nil, fmt.Errorf("failed to parse RuntimeClasses %s: %v", path, err)
+	}
+
+	overheads := map[string]v1.ResourceList{}
+	for _, rc := range config.RuntimeClasses {
+		if len(rc.Name) == 0 {
+			return nil, fmt.Errorf("name of RuntimeClass is required in %s", path)
+		}
+		if _, found := overheads[rc.Name]; found {
+			return nil, fmt.Errorf("duplicated RuntimeClass %s in %s", rc.Name, path)
+		}
+		overheads[rc.Name] = rc.Overhead.PodFixed
+	}
+
+	return overheads, nil
+}
@@ -81,3 +81,28 @@ type LessFn func(interface{}, interface{}) bool
 
 // CompareFn is the func declaration used by sort or priority queue.
 type CompareFn func(interface{}, interface{}) int
+
+// NodeOrderFn is the func declaration used to score a node for a task; the
+// node with higher score is preferred.
+type NodeOrderFn func(*TaskInfo, *NodeInfo) float64
+
+// NodesFilterFn is the func declaration used to filter nodes for a task in
+// one call, e.g. by an extender; it returns the nodes passed and the reasons of
+// the failed nodes by node name.
+type NodesFilterFn func(*TaskInfo, []*NodeInfo) ([]*NodeInfo, map[string]string, error)
+
+// NodesOrderFn is the func declaration used to score nodes for a task in one
+// call, e.g. by an extender; it returns the scores by node name.
+type NodesOrderFn func(*TaskInfo, []*NodeInfo) (map[string]float64, error)
+
+// VictimsFilterFn is the func declaration used to filter the victims to evict
+// for a task, by node name; the nodes not returned can not be preempted.
+type VictimsFilterFn func(*TaskInfo, map[string][]*TaskInfo) (map[string][]*TaskInfo, error)
+
+// VictimsCostFn is the func declaration used to cost the victims evicted for
+// a task; the victims of lower cost are preferred.
+type VictimsCostFn func(*TaskInfo, []*TaskInfo) float64
+
+// JobKeyFn is the func declaration used to order jobs by a key, e.g. share of
+// job; the job with lower key is ordered first.
+type JobKeyFn func(*JobInfo) float64
@@ -0,0 +1,102 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit records the scheduling decisions, e.g. which node a task is
+// bound to by which action, so the placement can be analyzed offline.
+package audit
+
+import (
+	"sync"
+	"time"
+)
+
+// DecisionType is the type of scheduling decision.
+type DecisionType string
+
+const (
+	// BindDecision means the task is bound to the node.
+	BindDecision DecisionType = "Bind"
+	// EvictDecision means the task is evicted from the node by the reason.
+	EvictDecision DecisionType = "Evict"
+	// NominateDecision means the task is nominated to the node, where the
+	// victims are evicted for it.
+	NominateDecision DecisionType = "Nominate"
+	// UnschedulableDecision means the task can not be scheduled by the reason.
+	UnschedulableDecision DecisionType = "Unschedulable"
+)
+
+// Decision is a scheduling decision of a task.
+type Decision struct {
+	Timestamp time.Time    `json:"timestamp"`
+	Type      DecisionType `json:"type"`
+	Session   string       `json:"session"`
+	Action    string       `json:"action"`
+
+	Job       string `json:"job"`
+	Namespace string `json:"namespace,omitempty"`
+	Task      string `json:"task,omitempty"`
+	Node      string `json:"node,omitempty"`
+
+	// Score is the score of the node for the task, if the node is ordered.
+	Score float64 `json:"score,omitempty"`
+	// Victims is the tasks evicted for the task.
+	Victims []string `json:"victims,omitempty"`
+	// Reason is why the task is evicted or can not be scheduled.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Sink writes the decisions.
+type Sink interface {
+	Write(d *Decision)
+}
+
+var (
+	sinkMutex sync.RWMutex
+	sink      Sink
+)
+
+// SetSink sets the sink of decisions; decisions are not recorded if it's nil.
+func SetSink(s Sink) {
+	sinkMutex.Lock()
+	defer sinkMutex.Unlock()
+
+	sink = s
+}
+
+// Enabled returns whether decisions are recorded, so callers can skip
+// building decisions.
+func Enabled() bool {
+	sinkMutex.RLock()
+	defer sinkMutex.RUnlock()
+
+	return sink != nil
+}
+
+// Record writes d to the sink, if any; the timestamp of d is set if empty.
+func Record(d *Decision) {
+	sinkMutex.RLock()
+	s := sink
+	sinkMutex.RUnlock()
+
+	if s == nil {
+		return
+	}
+
+	if d.Timestamp.IsZero() {
+		d.Timestamp = time.Now()
+	}
+	s.Write(d)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/golang/glog"
+)
+
+// FileSink writes one JSON decision per line into a file; the file is rotated
+// once it's larger than maxSize, and at most maxBackups rotated files are
+// kept as <path>.1 (the latest), <path>.2, ...
+type FileSink struct {
+	sync.Mutex
+
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	file *os.File
+	size int64
+}
+
+// NewFileSink creates a FileSink which appends to the file at path.
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	fs := &FileSink{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := fs.open(); err != nil {
+		return nil, err
+	}
+
+	return fs, nil
+}
+
+// Assumes that lock is already acquired.
+func (fs *FileSink) open() error {
+	file, err := os.OpenFile(fs.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	fs.file = file
+	fs.size = info.Size()
+	return nil
+}
+
+// Write appends d to the file; the error is logged, as decisions are
+// informative.
+func (fs *FileSink) Write(d *Decision) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		glog.Errorf("Failed to marshal decision %+v: %v", d, err)
+		return
+	}
+	data = append(data, '\n')
+
+	fs.Lock()
+	defer fs.Unlock()
+
+	if fs.maxSize > 0 && fs.size+int64(len(data)) > fs.maxSize && fs.size > 0 {
+		if err := fs.rotate(); err != nil {
+			glog.Errorf("Failed to rotate audit log %s: %v", fs.path, err)
+		}
+	}
+
+	if fs.file == nil {
+		return
+	}
+
+	n, err := fs.file.Write(data)
+	fs.size += int64(n)
+	if err != nil {
+		glog.Errorf("Failed to write audit log %s: %v", fs.path, err)
+	}
+}
+
+// rotate renames <path>.N-1 to <path>.N, ..., <path> to <path>.1, and opens a
+// new file at path; the file is truncated if maxBackups is 0.
+// Assumes that lock is already acquired.
+func (fs *FileSink) rotate() error {
+	if fs.file != nil {
+		fs.file.Close()
+		fs.file = nil
+	}
+
+	if fs.maxBackups > 0 {
+		for i := fs.maxBackups - 1; i > 0; i-- {
+			from := fmt.Sprintf("%s.%d", fs.path, i)
+			if err := os.Rename(from, fmt.Sprintf("%s.%d", fs.path, i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(fs.path, fs.path+".1"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else if err := os.Remove(fs.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return fs.open()
+}
+
+// Close closes the file.
+func (fs *FileSink) Close() error {
+	fs.Lock()
+	defer fs.Unlock()
+
+	if fs.file == nil {
+		return nil
+	}
+
+	err := fs.file.Close()
+	fs.file = nil
+	return err
+}
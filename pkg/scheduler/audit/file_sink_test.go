@@ -0,0 +1,76 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readDecisions(t *testing.T, path string) []*Decision {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var decisions []*Decision
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		d := &Decision{}
+		if err := json.Unmarshal(scanner.Bytes(), d); err != nil {
+			t.Fatalf("Failed to unmarshal %s: %v", scanner.Text(), err)
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions
+}
+
+func TestFileSinkRotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	// Each decision is larger than 64 bytes, so every write rotates the file.
+	fs, err := NewFileSink(path, 64, 2)
+	if err != nil {
+		t.Fatalf("Failed to create file sink: %v", err)
+	}
+	defer fs.Close()
+
+	for _, task := range []string{"t1", "t2", "t3", "t4"} {
+		fs.Write(&Decision{Type: BindDecision, Action: "allocate", Job: "j1", Task: task, Node: "n1"})
+	}
+
+	for path, task := range map[string]string{path: "t4", path + ".1": "t3", path + ".2": "t2"} {
+		decisions := readDecisions(t, path)
+		if len(decisions) != 1 || decisions[0].Task != task {
+			t.Errorf("expected decision of task %s in %s, got %+v", task, path, decisions)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected at most 2 backups, got %v", err)
+	}
+}
@@ -18,27 +18,61 @@ package cache
 
 import (
 	"fmt"
-	"strings"
+	"sort"
+	"strconv"
 	"sync"
-
-	"github.com/golang/glog"
+	"time"
 
 	"k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	clientv1 "k8s.io/client-go/informers/core/v1"
 	policyv1 "k8s.io/client-go/informers/policy/v1beta1"
+	storagev1 "k8s.io/client-go/informers/storage/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 
 	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
 	informerfactory "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers"
 	arbclient "github.com/kubernetes-incubator/kube-arbitrator/pkg/client/informers/v1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
 	arbapi "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/metrics"
+)
+
+// leakCheckPeriod is the interval to check the jobs whose owner was deleted.
+const leakCheckPeriod = 5 * time.Minute
+
+// jobEventPeriod is the minimal interval to record the same event of a job,
+// as the job is checked in every scheduling session.
+const jobEventPeriod = 5 * time.Minute
+
+// bindBackoffInitial and bindBackoffMax are the initial and maximal time to
+// delay scheduling a job after the bind of its task failed.
+const (
+	bindBackoffInitial = time.Second
+	bindBackoffMax     = time.Minute
 )
 
+// BindExtender binds the pods instead of the API server if it's set, e.g. an
+// extender of kube-scheduler with bind verb.
+var BindExtender Binder
+
+// ResyncPeriod is the interval to resync the informers, i.e. to replay the
+// cached objects to event handlers; 0 disables resync.
+var ResyncPeriod time.Duration
+
+// Namespace restricts the pods and SchedulingSpecs scheduled to a namespace,
+// all namespaces if empty; the pods of other namespaces are still accounted
+// on their nodes.
+var Namespace = v1.NamespaceAll
+
 // New returns a Cache implementation.
 func New(config *rest.Config, schedulerName string) Cache {
 	return newSchedulerCache(config, schedulerName)
@@ -48,16 +82,72 @@ type SchedulerCache struct {
 	sync.Mutex
 
 	kubeclient *kubernetes.Clientset
+	arbclient  *clientset.Clientset
+
+	// schedulerName is the name of scheduler whose pods are scheduled; the
+	// pods of other schedulers are only accounted on their nodes.
+	schedulerName string
 
-	podInformer            clientv1.PodInformer
+	podInformer            cache.SharedIndexInformer
 	nodeInformer           clientv1.NodeInformer
 	pdbInformer            policyv1.PodDisruptionBudgetInformer
+	pvInformer             clientv1.PersistentVolumeInformer
+	pvcInformer            clientv1.PersistentVolumeClaimInformer
+	storageClassInformer   storagev1.StorageClassInformer
 	schedulingSpecInformer arbclient.SchedulingSpecInformer
+	queueInformer          arbclient.QueueInformer
 
-	Binder Binder
+	// dispatcher executes the API writes of Binder, Evictor and
+	// StatusUpdater.
+	dispatcher *dispatcher
+	// dispatched is the writes executed without dispatcher.
+	dispatched sync.WaitGroup
+	// held is the writes held until released, if holding.
+	heldMutex sync.Mutex
+	holding   bool
+	held      []*apiWrite
+	// standby drops the API writes, e.g. of a scheduler without leadership
+	// which keeps the cache warm; it's guarded by heldMutex.
+	standby bool
 
-	Jobs  map[arbapi.JobID]*arbapi.JobInfo
-	Nodes map[string]*arbapi.NodeInfo
+	Binder        Binder
+	VolumeBinder  VolumeBinder
+	Evictor       Evictor
+	StatusUpdater StatusUpdater
+	Recorder      Recorder
+
+	// triggerCh is notified when a scheduling session is needed.
+	triggerCh chan struct{}
+
+	// the last event recorded of each job, by job ID.
+	jobEvents map[arbapi.JobID]*jobEvent
+
+	// the pods whose Unschedulable condition is being updated.
+	unschedulablePods map[arbapi.TaskID]bool
+
+	// the nodes of the tasks nominated, whose nominatedNodeName is being
+	// updated, by task ID.
+	nominations map[arbapi.TaskID]string
+
+	// the names of SimulatedNodes, whose pods are not bound.
+	simulatedNodes map[string]bool
+
+	// the names of the nodes whose heartbeat is stale in the last snapshot.
+	staleNodes map[string]bool
+
+	// the ResourceVersions of the pods in cache by UID, so the events of
+	// pods replayed by informers are skipped.
+	podVersions map[types.UID]string
+
+	Jobs   map[arbapi.JobID]*arbapi.JobInfo
+	Nodes  map[string]*arbapi.NodeInfo
+	Queues map[string]*arbapi.QueueInfo
+}
+
+type jobEvent struct {
+	reason    string
+	message   string
+	timestamp time.Time
 }
 
 type defaultBinder struct {
@@ -65,32 +155,114 @@ type defaultBinder struct {
 }
 
 func (db *defaultBinder) Bind(p *v1.Pod, hostname string) error {
+	// The annotations of Binding are set to the pod by apiserver.
+	var annotations map[string]string
+	if index, found := p.Annotations[arbapi.GPUIndexAnnotation]; found {
+		annotations = map[string]string{arbapi.GPUIndexAnnotation: index}
+	}
+
 	if err := db.kubeclient.CoreV1().Pods(p.Namespace).Bind(&v1.Binding{
-		ObjectMeta: metav1.ObjectMeta{Namespace: p.Namespace, Name: p.Name, UID: p.UID},
+		ObjectMeta: metav1.ObjectMeta{Namespace: p.Namespace, Name: p.Name, UID: p.UID, Annotations: annotations},
 		Target: v1.ObjectReference{
 			Kind: "Node",
 			Name: hostname,
 		},
 	}); err != nil {
-		glog.Infof("Failed to bind pod <%v/%v>: %#v", p.Namespace, p.Name, err)
+		logging.Error(err, "Failed to bind pod", "pod", arbapi.PodKey(p), "node", hostname)
 		return err
 	}
 	return nil
 }
 
+type defaultEvictor struct {
+	kubeclient *kubernetes.Clientset
+}
+
+// Evict evicts pod by the Eviction subresource, so it's rejected by apiserver
+// if it violates a PDB; the pod is deleted gracefully by its
+// terminationGracePeriodSeconds, and the UID precondition keeps the pod
+// recreated with the same name from being evicted.
+func (de *defaultEvictor) Evict(p *v1.Pod) error {
+	uid := p.UID
+	eviction := &policy.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Namespace: p.Namespace, Name: p.Name},
+		DeleteOptions: &metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{UID: &uid},
+		},
+	}
+	if err := de.kubeclient.PolicyV1beta1().Evictions(p.Namespace).Evict(eviction); err != nil {
+		logging.Error(err, "Failed to evict pod", "pod", arbapi.PodKey(p))
+		return err
+	}
+	return nil
+}
+
+type defaultStatusUpdater struct {
+	kubeclient *kubernetes.Clientset
+	arbclient  *clientset.Clientset
+}
+
+func (su *defaultStatusUpdater) UpdateSchedulingSpec(ss *arbv1.SchedulingSpec) error {
+	// TODO: replaced it with `UpdateStatus` after CRD supports status sub-resource.
+	_, err := su.arbclient.ArbV1().SchedulingSpecs(ss.Namespace).Update(ss)
+	return err
+}
+
+func (su *defaultStatusUpdater) UpdatePodCondition(pod *v1.Pod, condition *v1.PodCondition) error {
+	pod = pod.DeepCopy()
+	setPodCondition(&pod.Status, condition)
+	_, err := su.kubeclient.CoreV1().Pods(pod.Namespace).UpdateStatus(pod)
+	return err
+}
+
+func (su *defaultStatusUpdater) UpdatePodNominatedNode(pod *v1.Pod, nodeName string) error {
+	pod = pod.DeepCopy()
+	pod.Status.NominatedNodeName = nodeName
+	_, err := su.kubeclient.CoreV1().Pods(pod.Namespace).UpdateStatus(pod)
+	return err
+}
+
 func newSchedulerCache(config *rest.Config, schedulerName string) *SchedulerCache {
 	sc := &SchedulerCache{
-		Jobs:  make(map[arbapi.JobID]*arbapi.JobInfo),
-		Nodes: make(map[string]*arbapi.NodeInfo),
+		Jobs:          make(map[arbapi.JobID]*arbapi.JobInfo),
+		Nodes:         make(map[string]*arbapi.NodeInfo),
+		Queues:        make(map[string]*arbapi.QueueInfo),
+		jobEvents:     make(map[arbapi.JobID]*jobEvent),
+		triggerCh:     make(chan struct{}, 1),
+		schedulerName: schedulerName,
+	}
+	sc.addSimulatedNodes()
+
+	// The API writes are rate limited by dispatcher, so the clients are not
+	// limited lower than it.
+	config = rest.CopyConfig(config)
+	if config.QPS < DispatchQPS {
+		config.QPS = DispatchQPS
+	}
+	if config.Burst < DispatchBurst {
+		config.Burst = DispatchBurst
 	}
 
 	sc.kubeclient = kubernetes.NewForConfigOrDie(config)
+	sc.arbclient = clientset.NewForConfigOrDie(config)
+	sc.dispatcher = newDispatcher(DispatchWorkers, DispatchQPS, DispatchBurst)
 
 	sc.Binder = &defaultBinder{
 		kubeclient: sc.kubeclient,
 	}
+	if BindExtender != nil {
+		sc.Binder = BindExtender
+	}
+	sc.Evictor = &defaultEvictor{
+		kubeclient: sc.kubeclient,
+	}
+	sc.StatusUpdater = &defaultStatusUpdater{
+		kubeclient: sc.kubeclient,
+		arbclient:  sc.arbclient,
+	}
+	sc.Recorder = client.NewEventRecorder(sc.kubeclient, schedulerName)
 
-	informerFactory := informers.NewSharedInformerFactory(sc.kubeclient, 0)
+	informerFactory := informers.NewSharedInformerFactory(sc.kubeclient, ResyncPeriod)
 
 	// create informer for node information
 	sc.nodeInformer = informerFactory.Core().V1().Nodes()
@@ -100,21 +272,17 @@ func newSchedulerCache(config *rest.Config, schedulerName string) *SchedulerCach
 			UpdateFunc: sc.UpdateNode,
 			DeleteFunc: sc.DeleteNode,
 		},
-		0,
+		ResyncPeriod,
 	)
 
 	// create informer for pod information
-	sc.podInformer = informerFactory.Core().V1().Pods()
-	sc.podInformer.Informer().AddEventHandler(
+	sc.podInformer = newPodInformer(sc.kubeclient)
+	sc.podInformer.AddEventHandler(
 		cache.FilteringResourceEventHandler{
 			FilterFunc: func(obj interface{}) bool {
-				switch obj.(type) {
+				switch pod := obj.(type) {
 				case *v1.Pod:
-					pod := obj.(*v1.Pod)
-					if strings.Compare(pod.Spec.SchedulerName, schedulerName) == 0 && pod.Status.Phase == v1.PodPending {
-						return true
-					}
-					return pod.Status.Phase == v1.PodRunning
+					return sc.cached(pod)
 				default:
 					return false
 				}
@@ -126,6 +294,14 @@ func newSchedulerCache(config *rest.Config, schedulerName string) *SchedulerCach
 			},
 		})
 
+	// The informers of volumes have no event handlers, their listers are used
+	// by the volume binder.
+	sc.pvInformer = informerFactory.Core().V1().PersistentVolumes()
+	sc.pvcInformer = informerFactory.Core().V1().PersistentVolumeClaims()
+	sc.storageClassInformer = informerFactory.Storage().V1().StorageClasses()
+	sc.VolumeBinder = newVolumeBinder(sc.kubeclient, sc.pvInformer.Lister(),
+		sc.pvcInformer.Lister(), sc.storageClassInformer.Lister())
+
 	sc.pdbInformer = informerFactory.Policy().V1beta1().PodDisruptionBudgets()
 	sc.pdbInformer.Informer().AddEventHandler(
 		cache.FilteringResourceEventHandler{
@@ -142,7 +318,7 @@ func newSchedulerCache(config *rest.Config, schedulerName string) *SchedulerCach
 		panic(err)
 	}
 
-	schedulingSpecInformerFactory := informerfactory.NewSharedInformerFactory(queueClient, 0)
+	schedulingSpecInformerFactory := informerfactory.NewSharedInformerFactory(queueClient, ResyncPeriod)
 	// create informer for Queue information
 	sc.schedulingSpecInformer = schedulingSpecInformerFactory.SchedulingSpec().SchedulingSpecs()
 	sc.schedulingSpecInformer.Informer().AddEventHandler(
@@ -150,8 +326,8 @@ func newSchedulerCache(config *rest.Config, schedulerName string) *SchedulerCach
 			FilterFunc: func(obj interface{}) bool {
 				switch t := obj.(type) {
 				case *arbv1.SchedulingSpec:
-					glog.V(4).Infof("Filter Queue name(%s) namespace(%s)\n", t.Name, t.Namespace)
-					return true
+					logging.V(4).Info("Filter SchedulingSpec", "namespace", t.Namespace, "name", t.Name)
+					return len(Namespace) == 0 || t.Namespace == Namespace
 				default:
 					return false
 				}
@@ -163,20 +339,34 @@ func newSchedulerCache(config *rest.Config, schedulerName string) *SchedulerCach
 			},
 		})
 
+	// create informer for Queue information
+	sc.queueInformer = schedulingSpecInformerFactory.Queue().Queues()
+	sc.queueInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    sc.AddQueue,
+			UpdateFunc: sc.UpdateQueue,
+			DeleteFunc: sc.DeleteQueue,
+		})
+
 	return sc
 }
 
 func (sc *SchedulerCache) Run(stopCh <-chan struct{}) {
-	go sc.podInformer.Informer().Run(stopCh)
-	go sc.nodeInformer.Informer().Run(stopCh)
-	go sc.schedulingSpecInformer.Informer().Run(stopCh)
+	go sc.runStaged(stopCh)
+
+	go wait.Until(sc.checkLeakedJobs, leakCheckPeriod, stopCh)
+	go sc.dispatcher.Run(stopCh)
 }
 
 func (sc *SchedulerCache) WaitForCacheSync(stopCh <-chan struct{}) bool {
 	return cache.WaitForCacheSync(stopCh,
 		sc.pdbInformer.Informer().HasSynced,
-		sc.podInformer.Informer().HasSynced,
+		sc.pvInformer.Informer().HasSynced,
+		sc.pvcInformer.Informer().HasSynced,
+		sc.storageClassInformer.Informer().HasSynced,
+		sc.podInformer.HasSynced,
 		sc.schedulingSpecInformer.Informer().HasSynced,
+		sc.queueInformer.Informer().HasSynced,
 		sc.nodeInformer.Informer().HasSynced)
 }
 
@@ -213,6 +403,23 @@ func (sc *SchedulerCache) Bind(taskInfo *arbapi.TaskInfo, hostname string) error
 			task.UID, hostname)
 	}
 
+	// The task is not bound without a GPU for its GPU share, e.g. the GPU
+	// is taken by another task since it's placed.
+	if task.GPUShare != nil && task.GPUShare.Device < 0 && node.SharesGPU() && node.FitGPUShare(task.GPUShare) < 0 {
+		return fmt.Errorf("failed to bind Task %v to host %v, no GPU fits its GPU share",
+			task.UID, hostname)
+	}
+
+	p := task.Pod
+
+	// The PVs of task are assumed before the task, so they're not chosen for
+	// other tasks before bound.
+	if sc.VolumeBinder != nil && node.Node != nil {
+		if err := sc.VolumeBinder.AssumePodVolumes(p, node.Node); err != nil {
+			return err
+		}
+	}
+
 	err = job.UpdateTaskStatus(task, arbapi.Binding)
 	if err != nil {
 		return err
@@ -221,41 +428,645 @@ func (sc *SchedulerCache) Bind(taskInfo *arbapi.TaskInfo, hostname string) error
 	// Add task to the node.
 	node.AddTask(task)
 
+	// The GPU assigned to task by node is also assigned to the task of
+	// session, so the node of session assigns the same GPU.
+	if task.GPUShare != nil && taskInfo.GPUShare != nil {
+		taskInfo.GPUShare.Device = task.GPUShare.Device
+	}
+	bp := podWithGPUIndex(p, task.GPUShare)
+
+	sc.dispatch("bind", func() {
+		// The task may be unassumed by a conflict with another scheduler
+		// before the bind is dispatched.
+		if !sc.isBinding(p, hostname) {
+			logging.V(3).Info("Skip binding task unassumed from node",
+				"task", p.UID, "pod", arbapi.PodKey(p), "node", hostname)
+			return
+		}
+
+		if sc.simulatedNodes[hostname] {
+			logging.V(4).Info("Assume task on simulated node without binding",
+				"task", p.UID, "pod", arbapi.PodKey(p), "node", hostname)
+			return
+		}
+
+		bindStart := time.Now()
+		err := sc.bindVolumes(p)
+		if err == nil {
+			err = sc.Binder.Bind(bp, hostname)
+		}
+		metrics.UpdateBindingLatency(time.Since(bindStart))
+
+		sc.Mutex.Lock()
+		defer sc.Mutex.Unlock()
+
+		if err != nil {
+			logging.Error(err, "Failed to bind task to node", "job", task.Job, "task", p.UID,
+				"pod", arbapi.PodKey(p), "node", hostname)
+			sc.unassume(p, hostname, bindFailedTrigger)
+			sc.backoffJob(task.Job)
+			return
+		}
+		if job, found := sc.Jobs[task.Job]; found {
+			job.BindFailures = 0
+			job.BackoffUntil = time.Time{}
+		}
+	})
+
+	return nil
+}
+
+// podWithGPUIndex returns a copy of pod annotated by the GPU assigned to its
+// GPU share, or pod itself if no GPU is assigned.
+func podWithGPUIndex(pod *v1.Pod, share *arbapi.GPUShare) *v1.Pod {
+	if share == nil || share.Device < 0 {
+		return pod
+	}
+
+	pod = pod.DeepCopy()
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[arbapi.GPUIndexAnnotation] = strconv.Itoa(share.Device)
+	return pod
+}
+
+// bindVolumes binds the PVs assumed for pod before binding it, so the kubelet
+// mounts them once they're bound by PV controller.
+func (sc *SchedulerCache) bindVolumes(pod *v1.Pod) error {
+	if sc.VolumeBinder == nil {
+		return nil
+	}
+	return sc.VolumeBinder.BindPodVolumes(pod)
+}
+
+// CheckVolumeBinding returns the reasons why the volumes of task can not be
+// bound on node, e.g. its local PVs are on other nodes; empty if they can.
+func (sc *SchedulerCache) CheckVolumeBinding(task *arbapi.TaskInfo, node *arbapi.NodeInfo) []string {
+	if sc.VolumeBinder == nil || node.Node == nil {
+		return nil
+	}
+
+	reasons, err := sc.VolumeBinder.CheckVolumeBinding(task.Pod, node.Node)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	return reasons
+}
+
+// managed returns whether pod is scheduled by this scheduler, i.e. it's
+// in Namespace if set.
+func (sc *SchedulerCache) managed(pod *v1.Pod) bool {
+	if len(Namespace) != 0 && pod.Namespace != Namespace {
+		return false
+	}
+	return pod.Spec.SchedulerName == sc.schedulerName
+}
+
+// cached returns whether pod is cached: the pods bound to nodes are accounted
+// whichever scheduler bound them, so the nodes shared with other schedulers
+// are not double booked; the pending pods are only cached if they're
+// scheduled by this scheduler.
+func (sc *SchedulerCache) cached(pod *v1.Pod) bool {
+	if len(pod.Spec.NodeName) != 0 {
+		return true
+	}
+	return sc.managed(pod) && pod.Status.Phase == v1.PodPending
+}
+
+// isBinding returns whether the task of pod is still assumed on hostname.
+func (sc *SchedulerCache) isBinding(pod *v1.Pod, hostname string) bool {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	node, found := sc.Nodes[hostname]
+	if !found {
+		return false
+	}
+	task, found := node.Tasks[arbapi.PodKey(pod)]
+	return found && task.Status == arbapi.Binding
+}
+
+// unassume reverts the task of pod assumed on hostname to Pending, so it's
+// scheduled again in the next session triggered by reason; it's done if the
+// bind failed, or the node is overcommitted by the pods of another scheduler.
+// Assumes that lock is already acquired.
+func (sc *SchedulerCache) unassume(pod *v1.Pod, hostname, reason string) {
+	node, found := sc.Nodes[hostname]
+	if !found {
+		return
+	}
+	task, found := node.Tasks[arbapi.PodKey(pod)]
+	if !found || task.Status != arbapi.Binding {
+		return
+	}
+
+	logging.Warning("Unassume task from node to schedule it again", "job", task.Job,
+		"task", task.UID, "pod", arbapi.PodKey(pod), "node", hostname, "reason", reason)
+
+	node.RemoveTask(task)
+	if sc.VolumeBinder != nil {
+		sc.VolumeBinder.ForgetPodVolumes(pod)
+	}
+	if job, found := sc.Jobs[task.Job]; found {
+		job.UpdateTaskStatus(task, arbapi.Pending)
+	} else {
+		task.Status = arbapi.Pending
+	}
+
+	sc.trigger(reason)
+}
+
+// backoffJob delays scheduling the pending tasks of job after the bind of its
+// task failed, e.g. rejected by an admission webhook, so the failing binds are
+// not retried in every session; the backoff is doubled by each consecutive
+// failure up to bindBackoffMax, and a session is triggered when it expires.
+// Assumes that lock is already acquired.
+func (sc *SchedulerCache) backoffJob(uid arbapi.JobID) {
+	job, found := sc.Jobs[uid]
+	if !found {
+		return
+	}
+
+	backoff := bindBackoffInitial
+	for i := 0; i < job.BindFailures && backoff < bindBackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > bindBackoffMax {
+		backoff = bindBackoffMax
+	}
+	job.BindFailures++
+	job.BackoffUntil = time.Now().Add(backoff)
+
+	logging.Warning("Back off job after bind failed", "job", job.UID, "name", job.Name,
+		"failures", job.BindFailures, "backoff", backoff)
+	time.AfterFunc(backoff, func() {
+		sc.trigger(bindFailedTrigger)
+	})
+}
+
+// resolveConflicts unassumes the tasks being bound to node until it's not
+// overcommitted, when a pod of another scheduler is bound to the node at the
+// same time. The tasks whose bind was already sent are corrected by their pod
+// events later. Assumes that lock is already acquired.
+func (sc *SchedulerCache) resolveConflicts(node *arbapi.NodeInfo) {
+	for _, task := range node.Tasks {
+		if !node.Overcommitted() {
+			return
+		}
+		if task.Status == arbapi.Binding {
+			sc.unassume(task.Pod, node.Name, bindConflictTrigger)
+		}
+	}
+}
+
+// RecordJobStatusEvent records an event on the SchedulingSpec or PDB of job;
+// the same event is recorded at most once in jobEventPeriod.
+func (sc *SchedulerCache) RecordJobStatusEvent(job *arbapi.JobInfo, eventType, reason, message string) {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	ref := sc.jobReference(job.UID)
+	if ref == nil {
+		logging.V(4).Info("No SchedulingSpec or PDB of job to record event",
+			"job", job.UID, "reason", reason, "message", message)
+		return
+	}
+
+	now := time.Now()
+	if last, found := sc.jobEvents[job.UID]; found && last.reason == reason &&
+		last.message == message && now.Sub(last.timestamp) < jobEventPeriod {
+		return
+	}
+	if sc.jobEvents == nil {
+		sc.jobEvents = map[arbapi.JobID]*jobEvent{}
+	}
+	sc.jobEvents[job.UID] = &jobEvent{
+		reason:    reason,
+		message:   message,
+		timestamp: now,
+	}
+
+	go sc.Recorder.Eventf(ref, eventType, reason, "%s", message)
+}
+
+// RecordScheduleAttempt counts the consecutive sessions in which job was
+// unschedulable, or resets the count once job is scheduled.
+func (sc *SchedulerCache) RecordScheduleAttempt(job *arbapi.JobInfo, unschedulable bool) int {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	cached, found := sc.Jobs[job.UID]
+	if !found {
+		return 0
+	}
+
+	if unschedulable {
+		cached.ScheduleFailures++
+	} else {
+		cached.ScheduleFailures = 0
+	}
+	return cached.ScheduleFailures
+}
+
+// UpdateJobStatus sets the conditions of the SchedulingSpec of job in one
+// update, if any condition is changed; a False condition is not added if it
+// does not exist.
+func (sc *SchedulerCache) UpdateJobStatus(job *arbapi.JobInfo, conditions ...arbv1.SchedulingSpecCondition) {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	cached, found := sc.Jobs[job.UID]
+	if !found || cached.SchedSpec == nil {
+		return
+	}
+
+	ss := cached.SchedSpec.DeepCopy()
+	changed := false
+	for _, condition := range conditions {
+		if setSchedulingSpecCondition(&ss.Status, condition) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	sc.dispatch("status", func() {
+		if err := sc.StatusUpdater.UpdateSchedulingSpec(ss); err != nil {
+			logging.Error(err, "Failed to update status of SchedulingSpec",
+				"job", job.UID, "namespace", ss.Namespace, "name", ss.Name)
+		}
+	})
+}
+
+// UpdatePodsUnschedulable sets the PodScheduled condition of the pending
+// tasks of job to False by reason Unschedulable, the same as kube-scheduler,
+// so the cluster autoscaler sees the demand of the gang and scales up for it.
+// The condition is set once until the pod is scheduled, as the message is
+// changed in every session.
+func (sc *SchedulerCache) UpdatePodsUnschedulable(job *arbapi.JobInfo, message string) {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	cached, found := sc.Jobs[job.UID]
+	if !found {
+		return
+	}
+
+	if sc.unschedulablePods == nil {
+		sc.unschedulablePods = map[arbapi.TaskID]bool{}
+	}
+
+	for _, task := range cached.TaskStatusIndex[arbapi.Pending] {
+		if sc.unschedulablePods[task.UID] || isPodUnschedulable(task.Pod) {
+			continue
+		}
+		sc.unschedulablePods[task.UID] = true
+
+		id, p := task.UID, task.Pod
+		sc.dispatch("status", func() {
+			condition := &v1.PodCondition{
+				Type:    v1.PodScheduled,
+				Status:  v1.ConditionFalse,
+				Reason:  v1.PodReasonUnschedulable,
+				Message: message,
+			}
+			if err := sc.StatusUpdater.UpdatePodCondition(p, condition); err != nil {
+				logging.Error(err, "Failed to update condition of pod", "task", id, "pod", arbapi.PodKey(p))
+			}
+
+			sc.Mutex.Lock()
+			defer sc.Mutex.Unlock()
+			delete(sc.unschedulablePods, id)
+		})
+	}
+}
+
+// NominateTask sets the nominatedNodeName of task's pod to hostname, so the
+// observers, e.g. cluster autoscaler and kube-scheduler, know that the pod is
+// going to run on it after the victims are deleted.
+func (sc *SchedulerCache) NominateTask(task *arbapi.TaskInfo, hostname string) {
+	if task.Pod == nil || task.Pod.Status.NominatedNodeName == hostname {
+		return
+	}
+
+	id, p := task.UID, task.Pod
+
+	sc.Mutex.Lock()
+	if sc.nominations == nil {
+		sc.nominations = map[arbapi.TaskID]string{}
+	}
+	sc.nominations[id] = hostname
+	sc.Mutex.Unlock()
+
+	sc.dispatch("status", func() {
+		if err := sc.StatusUpdater.UpdatePodNominatedNode(p, hostname); err != nil {
+			logging.Error(err, "Failed to update nominated node of pod", "task", id,
+				"pod", arbapi.PodKey(p), "node", hostname)
+		}
+
+		sc.Mutex.Lock()
+		defer sc.Mutex.Unlock()
+		if sc.nominations[id] == hostname {
+			delete(sc.nominations, id)
+		}
+	})
+}
+
+// isPodUnschedulable returns whether the PodScheduled condition of pod is
+// already False by reason Unschedulable.
+func isPodUnschedulable(pod *v1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodScheduled {
+			return c.Status == v1.ConditionFalse && c.Reason == v1.PodReasonUnschedulable
+		}
+	}
+	return false
+}
+
+// setPodCondition sets condition in status, replacing the condition of the
+// same type.
+func setPodCondition(status *v1.PodStatus, condition *v1.PodCondition) {
+	condition.LastProbeTime = metav1.Now()
+	for i, c := range status.Conditions {
+		if c.Type != condition.Type {
+			continue
+		}
+
+		condition.LastTransitionTime = c.LastTransitionTime
+		if c.Status != condition.Status {
+			condition.LastTransitionTime = metav1.Now()
+		}
+		status.Conditions[i] = *condition
+		return
+	}
+
+	condition.LastTransitionTime = metav1.Now()
+	status.Conditions = append(status.Conditions, *condition)
+}
+
+// setSchedulingSpecCondition sets condition in status, and returns whether
+// the status is changed. Only the change of status or reason is taken as
+// changed, so the message (e.g. the number of pending tasks) changed in every
+// session does not cause an update storm; it's updated with the next change.
+func setSchedulingSpecCondition(status *arbv1.SchedulingSpecStatus, condition arbv1.SchedulingSpecCondition) bool {
+	for i, c := range status.Conditions {
+		if c.Type != condition.Type {
+			continue
+		}
+
+		if c.Status == condition.Status && c.Reason == condition.Reason {
+			return false
+		}
+
+		condition.LastTransitionTime = c.LastTransitionTime
+		if c.Status != condition.Status {
+			condition.LastTransitionTime = metav1.Now()
+		}
+		status.Conditions[i] = condition
+		return true
+	}
+
+	if condition.Status == v1.ConditionFalse {
+		return false
+	}
+
+	condition.LastTransitionTime = metav1.Now()
+	status.Conditions = append(status.Conditions, condition)
+	return true
+}
+
+// jobReference returns the reference of the SchedulingSpec or PDB of job, or
+// the pod of an implicit job.
+// Assumes that lock is already acquired.
+func (sc *SchedulerCache) jobReference(id arbapi.JobID) *v1.ObjectReference {
+	job, found := sc.Jobs[id]
+	if !found {
+		return nil
+	}
+
+	switch {
+	case job.SchedSpec != nil:
+		return &v1.ObjectReference{
+			Kind:            "SchedulingSpec",
+			APIVersion:      arbv1.SchemeGroupVersion.String(),
+			Namespace:       job.SchedSpec.Namespace,
+			Name:            job.SchedSpec.Name,
+			UID:             job.SchedSpec.UID,
+			ResourceVersion: job.SchedSpec.ResourceVersion,
+		}
+	case job.PDB != nil:
+		return &v1.ObjectReference{
+			Kind:            "PodDisruptionBudget",
+			APIVersion:      "policy/v1beta1",
+			Namespace:       job.PDB.Namespace,
+			Name:            job.PDB.Name,
+			UID:             job.PDB.UID,
+			ResourceVersion: job.PDB.ResourceVersion,
+		}
+	case job.Implicit:
+		// The events of an implicit job are of its only pod.
+		for _, task := range job.Tasks {
+			return &v1.ObjectReference{
+				Kind:            "Pod",
+				APIVersion:      "v1",
+				Namespace:       task.Pod.Namespace,
+				Name:            task.Pod.Name,
+				UID:             task.Pod.UID,
+				ResourceVersion: task.Pod.ResourceVersion,
+			}
+		}
+	}
+
+	return nil
+}
+
+// Evict evicts task by the Eviction subresource of its pod; the task is
+// Releasing until the pod is deleted, or reverted to its status by unevict if
+// the eviction is rejected, e.g. by the PDB of pod.
+func (sc *SchedulerCache) Evict(taskInfo *arbapi.TaskInfo, reason string) error {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	job, task, err := sc.findJobAndTask(taskInfo)
+	if err != nil {
+		return err
+	}
+
+	node, found := sc.Nodes[task.NodeName]
+	if !found {
+		return fmt.Errorf("failed to evict Task %v from host %v, host does not exist",
+			task.UID, task.NodeName)
+	}
+
+	// The task on node is the same object, so it's re-added to update the
+	// releasing resource of node by its new status; the resources are
+	// releasing until the pod is terminated or deleted.
+	status := task.Status
+	node.RemoveTask(task)
+	err = job.UpdateTaskStatus(task, arbapi.Releasing)
+	node.AddTask(task)
+	if err != nil {
+		return err
+	}
+
 	p := task.Pod
 
-	go func() {
-		sc.Binder.Bind(p, hostname)
-	}()
+	sc.dispatch("evict", func() {
+		if err := sc.Evictor.Evict(p); err != nil {
+			sc.Recorder.Eventf(podReference(p), v1.EventTypeWarning, "FailedEvict", "%s: %v", reason, err)
+
+			sc.Mutex.Lock()
+			defer sc.Mutex.Unlock()
+			sc.unevict(p, status)
+			return
+		}
+		sc.Recorder.Eventf(podReference(p), v1.EventTypeWarning, "Evict", "%s", reason)
+	})
 
 	return nil
 }
 
+// unevict reverts the task of pod to its status before eviction if the
+// eviction failed, e.g. rejected by the PDB of pod, so its resources are not
+// taken as releasing until the pod is updated; the task deleted or being
+// deleted by then is kept as it is.
+// Assumes that lock is already acquired.
+func (sc *SchedulerCache) unevict(pod *v1.Pod, status arbapi.TaskStatus) {
+	node, found := sc.Nodes[pod.Spec.NodeName]
+	if !found {
+		return
+	}
+	task, found := node.Tasks[arbapi.PodKey(pod)]
+	if !found || task.Status != arbapi.Releasing || task.Pod.DeletionTimestamp != nil {
+		return
+	}
+
+	logging.Warning("Revert task whose eviction failed", "job", task.Job, "task", task.UID,
+		"pod", arbapi.PodKey(pod), "node", node.Name, "status", status)
+
+	node.RemoveTask(task)
+	if job, found := sc.Jobs[task.Job]; found {
+		job.UpdateTaskStatus(task, status)
+	} else {
+		task.Status = status
+	}
+	node.AddTask(task)
+}
+
+func podReference(p *v1.Pod) *v1.ObjectReference {
+	return &v1.ObjectReference{
+		Kind:            "Pod",
+		APIVersion:      "v1",
+		Namespace:       p.Namespace,
+		Name:            p.Name,
+		UID:             p.UID,
+		ResourceVersion: p.ResourceVersion,
+	}
+}
+
 func (sc *SchedulerCache) Snapshot() *arbapi.ClusterInfo {
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
 
 	snapshot := &arbapi.ClusterInfo{
-		Nodes: make([]*arbapi.NodeInfo, 0, len(sc.Nodes)),
-		Jobs:  make([]*arbapi.JobInfo, 0, len(sc.Jobs)),
+		Nodes:  make([]*arbapi.NodeInfo, 0, len(sc.Nodes)),
+		Jobs:   make([]*arbapi.JobInfo, 0, len(sc.Jobs)),
+		Queues: make([]*arbapi.QueueInfo, 0, len(sc.Queues)),
 	}
 
+	// The tasks shared by jobs and nodes are still shared in snapshot, so they
+	// are cloned once.
+	clones := make(arbapi.TaskClones)
+
 	for _, value := range sc.Nodes {
-		snapshot.Nodes = append(snapshot.Nodes, value.Clone())
+		snapshot.Nodes = append(snapshot.Nodes, value.CloneWith(clones))
+	}
+	sc.markStaleNodes(snapshot.Nodes)
+
+	for _, value := range sc.Queues {
+		snapshot.Queues = append(snapshot.Queues, value.Clone())
 	}
 
 	for _, value := range sc.Jobs {
 		// If no scheduling spec, does not handle it.
-		if value.SchedSpec == nil && value.PDB == nil {
-			glog.V(3).Infof("The scheduling spec of Job <%v> is nil, ignore it.", value.UID)
+		if value.SchedSpec == nil && value.PDB == nil && !value.Implicit {
+			logging.V(3).Info("The scheduling spec of job is nil, ignore it", "job", value.UID)
 			continue
 		}
 
-		snapshot.Jobs = append(snapshot.Jobs, value.Clone())
+		snapshot.Jobs = append(snapshot.Jobs, value.CloneWith(clones))
 	}
 
+	// The nodes and jobs are sorted, so the actions iterating them, e.g. to
+	// find the first feasible node, decide the same in every session.
+	sort.Slice(snapshot.Nodes, func(i, j int) bool {
+		return snapshot.Nodes[i].Name < snapshot.Nodes[j].Name
+	})
+	sort.Slice(snapshot.Jobs, func(i, j int) bool {
+		return arbapi.JobCreatedBefore(snapshot.Jobs[i], snapshot.Jobs[j])
+	})
+
 	return snapshot
 }
 
+// checkLeakedJobs detects the jobs whose SchedulingSpec or PDB references a
+// deleted owner. Only the jobs without tasks are checked, as the pods are
+// deleted together with the owner.
+func (sc *SchedulerCache) checkLeakedJobs() {
+	type candidate struct {
+		job       arbapi.JobID
+		namespace string
+		ref       metav1.OwnerReference
+	}
+
+	var candidates []candidate
+
+	sc.Mutex.Lock()
+	for _, job := range sc.Jobs {
+		if len(job.Tasks) != 0 {
+			continue
+		}
+
+		var owner metav1.Object
+		if job.SchedSpec != nil {
+			owner = job.SchedSpec
+		} else if job.PDB != nil {
+			owner = job.PDB
+		} else {
+			continue
+		}
+
+		if ref := metav1.GetControllerOf(owner); ref != nil {
+			candidates = append(candidates, candidate{
+				job:       job.UID,
+				namespace: owner.GetNamespace(),
+				ref:       *ref,
+			})
+		}
+	}
+	sc.Mutex.Unlock()
+
+	// The leaked SchedulingSpecs and PDBs are deleted by controllers.
+	leaked := 0
+	for _, c := range candidates {
+		exists, err := client.OwnerExists(sc.kubeclient, sc.arbclient, c.namespace, &c.ref)
+		if err != nil {
+			logging.Error(err, "Failed to check owner of job", "job", c.job,
+				"kind", c.ref.Kind, "namespace", c.namespace, "name", c.ref.Name)
+			continue
+		}
+		if !exists {
+			logging.Warning("The owner of job was deleted, the job is leaked", "job", c.job,
+				"kind", c.ref.Kind, "namespace", c.namespace, "name", c.ref.Name)
+			leaked++
+		}
+	}
+
+	metrics.UpdateLeakedJobs(leaked)
+}
+
 func (sc *SchedulerCache) String() string {
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
@@ -265,8 +1076,8 @@ func (sc *SchedulerCache) String() string {
 	if len(sc.Nodes) != 0 {
 		str = str + "Nodes:\n"
 		for _, n := range sc.Nodes {
-			str = str + fmt.Sprintf("\t %s: idle(%v) used(%v) allocatable(%v) pods(%d)\n",
-				n.Name, n.Idle, n.Used, n.Allocatable, len(n.Tasks))
+			str = str + fmt.Sprintf("\t %s: idle(%v) used(%v) releasing(%v) allocatable(%v) pods(%d)\n",
+				n.Name, n.Idle, n.Used, n.Releasing, n.Allocatable, len(n.Tasks))
 
 			i := 0
 			for _, p := range n.Tasks {
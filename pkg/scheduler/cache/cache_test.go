@@ -20,12 +20,16 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
 )
 
@@ -198,6 +202,14 @@ func TestAddNode(t *testing.T) {
 	ni1 := api.NewNodeInfo(node1)
 	ni1.AddTask(pi2)
 
+	// The pods without controller are the implicit jobs of themselves.
+	j1 := api.NewJobInfo(api.ImplicitJobID(pod1))
+	j1.SetImplicitPod(pod1)
+	j1.AddTaskInfo(api.NewTaskInfo(pod1))
+	j2 := api.NewJobInfo(api.ImplicitJobID(pod2))
+	j2.SetImplicitPod(pod2)
+	j2.AddTaskInfo(pi2)
+
 	tests := []struct {
 		pods     []*v1.Pod
 		nodes    []*v1.Node
@@ -210,6 +222,10 @@ func TestAddNode(t *testing.T) {
 				Nodes: map[string]*api.NodeInfo{
 					"n1": ni1,
 				},
+				Jobs: map[api.JobID]*api.JobInfo{
+					j1.UID: j1,
+					j2.UID: j2,
+				},
 			},
 		},
 	}
@@ -234,3 +250,1053 @@ func TestAddNode(t *testing.T) {
 		}
 	}
 }
+
+func TestDeleteSchedulingSpec(t *testing.T) {
+	owner := buildOwnerReference("j1")
+
+	// case 1: the job still has tasks, keep it in cache.
+	pod1 := buildPod("c1", "p1", "n1", v1.PodRunning, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{owner}, make(map[string]string))
+	pi1 := api.NewTaskInfo(pod1)
+
+	j1 := api.NewJobInfo(api.JobID("j1"))
+	j1.Name = "ss1"
+	j1.Namespace = "c1"
+	j1.AddTaskInfo(pi1)
+
+	ss1 := &arbv1.SchedulingSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "ss1",
+			Namespace:       "c1",
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+	}
+
+	tests := []struct {
+		pods     []*v1.Pod
+		specs    []*arbv1.SchedulingSpec
+		rmPods   []*v1.Pod
+		rmSpecs  []*arbv1.SchedulingSpec
+		expected map[api.JobID]*api.JobInfo
+	}{
+		{
+			pods:    []*v1.Pod{pod1},
+			specs:   []*arbv1.SchedulingSpec{ss1},
+			rmSpecs: []*arbv1.SchedulingSpec{ss1},
+			expected: map[api.JobID]*api.JobInfo{
+				"j1": j1,
+			},
+		},
+		// case 2: both tasks and SchedulingSpec are deleted, release the job.
+		{
+			pods:     []*v1.Pod{pod1},
+			specs:    []*arbv1.SchedulingSpec{ss1},
+			rmPods:   []*v1.Pod{pod1},
+			rmSpecs:  []*arbv1.SchedulingSpec{ss1},
+			expected: map[api.JobID]*api.JobInfo{},
+		},
+	}
+
+	for i, test := range tests {
+		cache := &SchedulerCache{
+			Jobs:  make(map[api.JobID]*api.JobInfo),
+			Nodes: make(map[string]*api.NodeInfo),
+		}
+
+		for _, p := range test.pods {
+			cache.AddPod(p)
+		}
+
+		for _, ss := range test.specs {
+			cache.AddSchedulingSpec(ss)
+		}
+
+		for _, p := range test.rmPods {
+			cache.DeletePod(p)
+		}
+
+		for _, ss := range test.rmSpecs {
+			cache.DeleteSchedulingSpec(ss)
+		}
+
+		if !jobsEqual(cache.Jobs, test.expected) {
+			t.Errorf("case %d: \n expected %v, \n got %v \n",
+				i, test.expected, cache.Jobs)
+		}
+	}
+}
+
+func TestSnapshotSharesTasks(t *testing.T) {
+	owner := buildOwnerReference("j1")
+
+	pod1 := buildPod("c1", "p1", "n1", v1.PodRunning, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{owner}, make(map[string]string))
+	pod2 := buildPod("c1", "p2", "", v1.PodPending, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{owner}, make(map[string]string))
+
+	cache := &SchedulerCache{
+		Jobs:  make(map[api.JobID]*api.JobInfo),
+		Nodes: make(map[string]*api.NodeInfo),
+	}
+	cache.AddNode(buildNode("n1", buildResourceList("2000m", "10G")))
+	cache.AddPod(pod1)
+	cache.AddPod(pod2)
+	cache.AddSchedulingSpec(&arbv1.SchedulingSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "ss1",
+			Namespace:       "c1",
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+	})
+
+	snapshot := cache.Snapshot()
+	if len(snapshot.Jobs) != 1 || len(snapshot.Nodes) != 1 {
+		t.Fatalf("expected 1 job and 1 node in snapshot, got %d jobs and %d nodes",
+			len(snapshot.Jobs), len(snapshot.Nodes))
+	}
+
+	jobTask := snapshot.Jobs[0].Tasks[api.TaskID(pod1.UID)]
+	nodeTask := snapshot.Nodes[0].Tasks[api.PodKey(pod1)]
+	if jobTask == nil || jobTask != nodeTask {
+		t.Errorf("expected the task shared by job and node in snapshot, got %p and %p", jobTask, nodeTask)
+	}
+	if cached := cache.Jobs["j1"].Tasks[api.TaskID(pod1.UID)]; jobTask == cached {
+		t.Errorf("expected the task in snapshot cloned from cache")
+	}
+	if jobTask.Pod != pod1 {
+		t.Errorf("expected the pod referenced instead of copied in snapshot")
+	}
+}
+
+func TestSnapshotStaleNodes(t *testing.T) {
+	heartbeat := func(name string, age time.Duration) *v1.Node {
+		node := buildNode(name, buildResourceList("2000m", "10G"))
+		node.Status.Conditions = []v1.NodeCondition{{
+			Type:              v1.NodeReady,
+			Status:            v1.ConditionTrue,
+			LastHeartbeatTime: metav1.NewTime(time.Now().Add(-age)),
+		}}
+		return node
+	}
+
+	cache := &SchedulerCache{
+		Jobs:  make(map[api.JobID]*api.JobInfo),
+		Nodes: make(map[string]*api.NodeInfo),
+	}
+	cache.AddNode(heartbeat("fresh", time.Second))
+	cache.AddNode(heartbeat("stale", time.Minute))
+	cache.AddNode(buildNode("unknown", buildResourceList("2000m", "10G")))
+
+	for _, node := range cache.Snapshot().Nodes {
+		if expected := node.Name == "stale"; node.Unschedulable != expected {
+			t.Errorf("expected node %s unschedulable %v, got %v", node.Name, expected, node.Unschedulable)
+		}
+	}
+	if !cache.staleNodes["stale"] || len(cache.staleNodes) != 1 {
+		t.Errorf("expected only stale node recorded, got %v", cache.staleNodes)
+	}
+}
+
+type fakeBinder struct {
+	binds []string
+}
+
+func (fb *fakeBinder) Bind(p *v1.Pod, hostname string) error {
+	fb.binds = append(fb.binds, p.Name)
+	return nil
+}
+
+func TestBindConflict(t *testing.T) {
+	owner := buildOwnerReference("j1")
+
+	pod1 := buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1500m", "1G"),
+		[]metav1.OwnerReference{owner}, make(map[string]string))
+	pod1.Spec.SchedulerName = "kar-scheduler"
+	// pod2 is bound to the same node by another scheduler at the same time.
+	pod2 := buildPod("c1", "p2", "n1", v1.PodRunning, buildResourceList("1000m", "1G"),
+		nil, make(map[string]string))
+	pod2.Spec.SchedulerName = "default-scheduler"
+
+	binder := &fakeBinder{}
+	cache := &SchedulerCache{
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Nodes:         make(map[string]*api.NodeInfo),
+		Binder:        binder,
+		schedulerName: "kar-scheduler",
+		triggerCh:     make(chan struct{}, 1),
+		dispatcher:    newDispatcher(1, 100, 100),
+	}
+	cache.AddNode(buildNode("n1", buildResourceList("2000m", "10G")))
+	cache.AddPod(pod1)
+	<-cache.Triggered()
+
+	if err := cache.Bind(api.NewTaskInfo(pod1), "n1"); err != nil {
+		t.Fatalf("failed to bind task: %v", err)
+	}
+	cache.AddPod(pod2)
+
+	if len(cache.Jobs) != 1 {
+		t.Errorf("expected the pod of another scheduler not added to jobs, got %d jobs", len(cache.Jobs))
+	}
+	task := cache.Jobs["j1"].Tasks[api.TaskID(pod1.UID)]
+	if task.Status != api.Pending {
+		t.Errorf("expected the conflicted task unassumed to Pending, got %v", task.Status)
+	}
+	node := cache.Nodes["n1"]
+	if _, found := node.Tasks[api.PodKey(pod2)]; !found || len(node.Tasks) != 1 || node.Overcommitted() {
+		t.Errorf("expected only the pod of another scheduler on node, got %v", node)
+	}
+	select {
+	case <-cache.Triggered():
+	default:
+		t.Errorf("expected a session triggered to schedule the task again")
+	}
+
+	// The queued bind of the unassumed task is skipped.
+	cache.dispatcher.writes[0].fn()
+	if len(binder.binds) != 0 {
+		t.Errorf("expected the bind of unassumed task skipped, got %v", binder.binds)
+	}
+}
+
+type fakeStatusUpdater struct {
+	conditions map[string]*v1.PodCondition
+}
+
+func (fsu *fakeStatusUpdater) UpdateSchedulingSpec(ss *arbv1.SchedulingSpec) error {
+	return nil
+}
+
+func (fsu *fakeStatusUpdater) UpdatePodCondition(pod *v1.Pod, condition *v1.PodCondition) error {
+	fsu.conditions[pod.Name] = condition
+	return nil
+}
+
+func (fsu *fakeStatusUpdater) UpdatePodNominatedNode(pod *v1.Pod, nodeName string) error {
+	return nil
+}
+
+func TestUpdatePodsUnschedulable(t *testing.T) {
+	owner := buildOwnerReference("j1")
+
+	pod1 := buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{owner}, make(map[string]string))
+	// pod2 is already marked Unschedulable.
+	pod2 := buildPod("c1", "p2", "", v1.PodPending, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{owner}, make(map[string]string))
+	pod2.Status.Conditions = []v1.PodCondition{{
+		Type:   v1.PodScheduled,
+		Status: v1.ConditionFalse,
+		Reason: v1.PodReasonUnschedulable,
+	}}
+	pod3 := buildPod("c1", "p3", "n1", v1.PodRunning, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{owner}, make(map[string]string))
+
+	updater := &fakeStatusUpdater{conditions: map[string]*v1.PodCondition{}}
+	cache := &SchedulerCache{
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Nodes:         make(map[string]*api.NodeInfo),
+		StatusUpdater: updater,
+		dispatcher:    newDispatcher(1, 100, 100),
+	}
+	for _, pod := range []*v1.Pod{pod1, pod2, pod3} {
+		cache.AddPod(pod)
+	}
+
+	job := cache.Jobs["j1"]
+	cache.UpdatePodsUnschedulable(job, "1/3 tasks in gang unschedulable")
+	// The update in flight is not dispatched again.
+	cache.UpdatePodsUnschedulable(job, "1/3 tasks in gang unschedulable")
+
+	if len(cache.dispatcher.writes) != 1 {
+		t.Fatalf("expected 1 pod condition update dispatched, got %d", len(cache.dispatcher.writes))
+	}
+	cache.dispatcher.writes[0].fn()
+
+	condition, found := updater.conditions["p1"]
+	if !found || len(updater.conditions) != 1 {
+		t.Fatalf("expected the condition of p1 updated only, got %v", updater.conditions)
+	}
+	if condition.Type != v1.PodScheduled || condition.Status != v1.ConditionFalse ||
+		condition.Reason != v1.PodReasonUnschedulable {
+		t.Errorf("expected PodScheduled condition False by Unschedulable, got %v", condition)
+	}
+}
+
+func TestAddPodWithGroupName(t *testing.T) {
+	labels := map[string]string{api.DefaultGroupNameLabel: "pg1"}
+
+	// case 1: pods without controller are grouped by label.
+	pod1 := buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{}, labels)
+	pi1 := api.NewTaskInfo(pod1)
+	pod2 := buildPod("c1", "p2", "", v1.PodPending, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{}, labels)
+	pi2 := api.NewTaskInfo(pod2)
+
+	ss1 := &arbv1.SchedulingSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pg1",
+			Namespace: "c1",
+		},
+		Spec: arbv1.SchedulingSpecTemplate{
+			MinAvailable: 2,
+		},
+	}
+
+	j1 := api.NewJobInfo(api.GroupJobID("c1", "pg1"))
+	j1.SetSchedulingSpec(ss1)
+	j1.AddTaskInfo(pi1)
+	j1.AddTaskInfo(pi2)
+
+	tests := []struct {
+		pods     []*v1.Pod
+		specs    []*arbv1.SchedulingSpec
+		expected map[api.JobID]*api.JobInfo
+	}{
+		{
+			pods:  []*v1.Pod{pod1, pod2},
+			specs: []*arbv1.SchedulingSpec{ss1},
+			expected: map[api.JobID]*api.JobInfo{
+				"c1/pg1": j1,
+			},
+		},
+	}
+
+	for i, test := range tests {
+		cache := &SchedulerCache{
+			Jobs:  make(map[api.JobID]*api.JobInfo),
+			Nodes: make(map[string]*api.NodeInfo),
+		}
+
+		for _, p := range test.pods {
+			cache.AddPod(p)
+		}
+
+		for _, ss := range test.specs {
+			cache.AddSchedulingSpec(ss)
+		}
+
+		if !jobsEqual(cache.Jobs, test.expected) {
+			t.Errorf("case %d: \n expected %v, \n got %v \n",
+				i, test.expected, cache.Jobs)
+		}
+	}
+}
+
+func TestPDBWithGroupName(t *testing.T) {
+	cache := &SchedulerCache{
+		Jobs:  make(map[api.JobID]*api.JobInfo),
+		Nodes: make(map[string]*api.NodeInfo),
+	}
+
+	// The PDB without controller is in the pod group of its name, the same as
+	// SchedulingSpec.
+	minAvailable := intstr.FromInt(2)
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1", UID: "pdb1"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable},
+	}
+	cache.AddSchedulingSpec(&arbv1.SchedulingSpec{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+	})
+	cache.AddPDB(pdb)
+
+	job, found := cache.Jobs[api.GroupJobID("c1", "pg1")]
+	if !found || job.PDB == nil || job.SchedSpec == nil {
+		t.Fatalf("expected PDB and SchedulingSpec in job c1/pg1, got %v", cache.Jobs)
+	}
+
+	cache.DeletePDB(pdb)
+	if job.PDB != nil {
+		t.Errorf("expected PDB deleted from job c1/pg1")
+	}
+}
+
+func TestSetSchedulingSpecCondition(t *testing.T) {
+	transitionTime := metav1.NewTime(metav1.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name      string
+		status    arbv1.SchedulingSpecStatus
+		condition arbv1.SchedulingSpecCondition
+		changed   bool
+		// Whether LastTransitionTime is kept as transitionTime.
+		keepTime bool
+	}{
+		{
+			name: "add true condition",
+			condition: arbv1.SchedulingSpecCondition{
+				Type: arbv1.SchedulingSpecScheduled, Status: v1.ConditionTrue, Reason: "Scheduled",
+			},
+			changed: true,
+		},
+		{
+			name: "skip absent false condition",
+			condition: arbv1.SchedulingSpecCondition{
+				Type: arbv1.SchedulingSpecScheduled, Status: v1.ConditionFalse, Reason: "Pending",
+			},
+			changed: false,
+		},
+		{
+			name: "skip message only change",
+			status: arbv1.SchedulingSpecStatus{Conditions: []arbv1.SchedulingSpecCondition{{
+				Type: arbv1.SchedulingSpecUnschedulable, Status: v1.ConditionTrue, Reason: "Unschedulable",
+				Message: "1/2 tasks in gang unschedulable", LastTransitionTime: transitionTime,
+			}}},
+			condition: arbv1.SchedulingSpecCondition{
+				Type: arbv1.SchedulingSpecUnschedulable, Status: v1.ConditionTrue, Reason: "Unschedulable",
+				Message: "2/2 tasks in gang unschedulable",
+			},
+			changed:  false,
+			keepTime: true,
+		},
+		{
+			name: "keep transition time when reason changed",
+			status: arbv1.SchedulingSpecStatus{Conditions: []arbv1.SchedulingSpecCondition{{
+				Type: arbv1.SchedulingSpecScheduled, Status: v1.ConditionFalse, Reason: "Pending",
+				LastTransitionTime: transitionTime,
+			}}},
+			condition: arbv1.SchedulingSpecCondition{
+				Type: arbv1.SchedulingSpecScheduled, Status: v1.ConditionFalse, Reason: "Unschedulable",
+			},
+			changed:  true,
+			keepTime: true,
+		},
+		{
+			name: "update transition time when status changed",
+			status: arbv1.SchedulingSpecStatus{Conditions: []arbv1.SchedulingSpecCondition{{
+				Type: arbv1.SchedulingSpecScheduled, Status: v1.ConditionFalse, Reason: "Pending",
+				LastTransitionTime: transitionTime,
+			}}},
+			condition: arbv1.SchedulingSpecCondition{
+				Type: arbv1.SchedulingSpecScheduled, Status: v1.ConditionTrue, Reason: "Scheduled",
+			},
+			changed: true,
+		},
+	}
+
+	for i, test := range tests {
+		changed := setSchedulingSpecCondition(&test.status, test.condition)
+		if changed != test.changed {
+			t.Errorf("case %d (%s): expected changed %v, got %v", i, test.name, test.changed, changed)
+		}
+		if !changed {
+			continue
+		}
+
+		if len(test.status.Conditions) != 1 {
+			t.Fatalf("case %d (%s): expected 1 condition, got %d", i, test.name, len(test.status.Conditions))
+		}
+		c := test.status.Conditions[0]
+		if c.Status != test.condition.Status || c.Reason != test.condition.Reason {
+			t.Errorf("case %d (%s): expected condition %v, got %v", i, test.name, test.condition, c)
+		}
+		if c.LastTransitionTime.Equal(&transitionTime) != test.keepTime {
+			t.Errorf("case %d (%s): expected keeping transition time %v, got %v",
+				i, test.name, test.keepTime, c.LastTransitionTime)
+		}
+	}
+}
+
+func TestCachedNamespace(t *testing.T) {
+	defer func(ns string) { Namespace = ns }(Namespace)
+	Namespace = "c1"
+
+	cache := &SchedulerCache{schedulerName: "kar-scheduler"}
+	tests := []struct {
+		pod    *v1.Pod
+		cached bool
+	}{
+		{pod: buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1", "1G"), nil, nil), cached: true},
+		{pod: buildPod("c2", "p2", "", v1.PodPending, buildResourceList("1", "1G"), nil, nil), cached: false},
+		// The bound pods of other namespaces are accounted on nodes.
+		{pod: buildPod("c2", "p3", "n1", v1.PodRunning, buildResourceList("1", "1G"), nil, nil), cached: true},
+	}
+
+	for _, test := range tests {
+		test.pod.Spec.SchedulerName = "kar-scheduler"
+		if cached := cache.cached(test.pod); cached != test.cached {
+			t.Errorf("expected pod %s/%s cached %v, got %v",
+				test.pod.Namespace, test.pod.Name, test.cached, cached)
+		}
+	}
+}
+
+func TestDerivedState(t *testing.T) {
+	buildCache := func() *SchedulerCache {
+		cache := &SchedulerCache{
+			Jobs:          make(map[api.JobID]*api.JobInfo),
+			Nodes:         make(map[string]*api.NodeInfo),
+			schedulerName: "kar-scheduler",
+			triggerCh:     make(chan struct{}, 1),
+			dispatcher:    newDispatcher(1, 100, 100),
+		}
+		cache.AddNode(buildNode("n1", buildResourceList("4000m", "10G")))
+
+		for _, p := range []struct{ name, node, job string }{
+			{name: "p1", job: "j1"},
+			{name: "p2", node: "n1", job: "j2"},
+			{name: "p3", job: "j3"},
+		} {
+			phase := v1.PodPending
+			if len(p.node) != 0 {
+				phase = v1.PodRunning
+			}
+			pod := buildPod("c1", p.name, p.node, phase, buildResourceList("1000m", "1G"),
+				[]metav1.OwnerReference{buildOwnerReference(p.job)}, make(map[string]string))
+			pod.Spec.SchedulerName = "kar-scheduler"
+			cache.AddPod(pod)
+		}
+		return cache
+	}
+	task := func(cache *SchedulerCache, job, name string) *api.TaskInfo {
+		return cache.Jobs[api.JobID(job)].Tasks[api.TaskID("c1-"+name)]
+	}
+
+	// The writes are queued but not executed, e.g. when scheduler stops.
+	cache := buildCache()
+	if err := cache.Bind(task(cache, "j1", "p1"), "n1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Evict(task(cache, "j2", "p2"), "preempt"); err != nil {
+		t.Fatal(err)
+	}
+	cache.NominateTask(task(cache, "j3", "p3"), "n1")
+
+	state := cache.ExportDerivedState()
+	if len(state.Assumed) != 1 || state.Assumed[0].Name != "p1" || state.Assumed[0].Node != "n1" {
+		t.Errorf("expected p1 assumed on n1, got %v", state.Assumed)
+	}
+	if len(state.Evicting) != 1 || state.Evicting[0].Name != "p2" {
+		t.Errorf("expected p2 evicting, got %v", state.Evicting)
+	}
+	if len(state.Nominated) != 1 || state.Nominated[0].Name != "p3" || state.Nominated[0].Node != "n1" {
+		t.Errorf("expected p3 nominated to n1, got %v", state.Nominated)
+	}
+
+	restarted := buildCache()
+	restarted.ImportDerivedState(state)
+	if s := task(restarted, "j1", "p1").Status; s != api.Binding {
+		t.Errorf("expected p1 binding after import, got %v", s)
+	}
+	if s := task(restarted, "j2", "p2").Status; s != api.Releasing {
+		t.Errorf("expected p2 releasing after import, got %v", s)
+	}
+	if n := task(restarted, "j3", "p3").Pod.Status.NominatedNodeName; n != "n1" {
+		t.Errorf("expected p3 nominated to n1 after import, got %q", n)
+	}
+	if len(restarted.dispatcher.writes) != 3 {
+		t.Errorf("expected bind, evict and nomination dispatched, got %d writes", len(restarted.dispatcher.writes))
+	}
+
+	// The decisions already replayed are not replayed again.
+	restarted.ImportDerivedState(state)
+	if len(restarted.dispatcher.writes) != 3 {
+		t.Errorf("expected no more writes, got %d", len(restarted.dispatcher.writes))
+	}
+}
+
+func TestImportAssumedGang(t *testing.T) {
+	cache := &SchedulerCache{
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Nodes:         make(map[string]*api.NodeInfo),
+		schedulerName: "kar-scheduler",
+		triggerCh:     make(chan struct{}, 1),
+		dispatcher:    newDispatcher(1, 100, 100),
+	}
+	cache.AddNode(buildNode("n1", buildResourceList("4000m", "10G")))
+
+	// The gang j1 was assumed on n1, but n1 is taken by p0 since then, so
+	// only one of its tasks fits.
+	for _, p := range []struct{ name, node, job, cpu string }{
+		{name: "p0", node: "n1", job: "j0", cpu: "1000m"},
+		{name: "p1", job: "j1", cpu: "2000m"},
+		{name: "p2", job: "j1", cpu: "2000m"},
+		{name: "p3", job: "j2", cpu: "1000m"},
+	} {
+		phase := v1.PodPending
+		if len(p.node) != 0 {
+			phase = v1.PodRunning
+		}
+		pod := buildPod("c1", p.name, p.node, phase, buildResourceList(p.cpu, "1G"),
+			[]metav1.OwnerReference{buildOwnerReference(p.job)}, make(map[string]string))
+		pod.Spec.SchedulerName = "kar-scheduler"
+		cache.AddPod(pod)
+	}
+
+	state := &DerivedState{}
+	for _, name := range []string{"p1", "p2", "p3"} {
+		state.Assumed = append(state.Assumed, &TaskPlacement{
+			UID: types.UID("c1-" + name), Namespace: "c1", Name: name, Node: "n1",
+		})
+	}
+	cache.ImportDerivedState(state)
+
+	for _, p := range []struct {
+		job, name string
+		status    api.TaskStatus
+	}{
+		{job: "j1", name: "p1", status: api.Pending},
+		{job: "j1", name: "p2", status: api.Pending},
+		{job: "j2", name: "p3", status: api.Binding},
+	} {
+		if s := cache.Jobs[api.JobID(p.job)].Tasks[api.TaskID("c1-"+p.name)].Status; s != p.status {
+			t.Errorf("expected %s %v after import, got %v", p.name, p.status, s)
+		}
+	}
+	if len(cache.dispatcher.writes) != 1 {
+		t.Errorf("expected only bind of p3 dispatched, got %d writes", len(cache.dispatcher.writes))
+	}
+}
+
+func TestBindGPUShareNotFit(t *testing.T) {
+	cache := &SchedulerCache{
+		Nodes:     make(map[string]*api.NodeInfo),
+		Jobs:      make(map[api.JobID]*api.JobInfo),
+		triggerCh: make(chan struct{}, 1),
+	}
+	node := buildNode("n1", buildResourceList("4000m", "10G"))
+	node.Annotations = map[string]string{api.NodeGPUDevicesAnnotation: "1"}
+	cache.AddNode(node)
+
+	// The GPU of n1 is taken by p1 since p2 was placed.
+	for _, p := range []struct{ name, node string }{{"p1", "n1"}, {"p2", ""}} {
+		phase := v1.PodPending
+		if len(p.node) != 0 {
+			phase = v1.PodRunning
+		}
+		pod := buildPod("c1", p.name, p.node, phase, buildResourceList("1000m", "1G"),
+			[]metav1.OwnerReference{buildOwnerReference("j1")}, make(map[string]string))
+		pod.Annotations = map[string]string{api.GPUCoreAnnotation: "60", api.GPUIndexAnnotation: "0"}
+		cache.AddPod(pod)
+	}
+
+	task := cache.Jobs["j1"].Tasks["c1-p2"]
+	if err := cache.Bind(task, "n1"); err == nil {
+		t.Errorf("expected bind failed without GPU fitting the share")
+	}
+	if task.Status != api.Pending {
+		t.Errorf("expected task pending after bind failed, got %v", task.Status)
+	}
+	if d := cache.Nodes["n1"].GPUDevices[0]; d.Core != 60 {
+		t.Errorf("expected 60%% core used on GPU 0, got %d", d.Core)
+	}
+}
+
+func TestPreBoundPod(t *testing.T) {
+	owner := buildOwnerReference("j1")
+	cache := &SchedulerCache{
+		Nodes:     make(map[string]*api.NodeInfo),
+		Jobs:      make(map[api.JobID]*api.JobInfo),
+		triggerCh: make(chan struct{}, 1),
+	}
+	cache.AddNode(buildNode("n1", buildResourceList("4000m", "10G")))
+	<-cache.Triggered()
+
+	// The pods created with their node, whose phase may not be set yet.
+	cache.AddPod(buildPod("c1", "p1", "n1", "", buildResourceList("1000m", "1G"), []metav1.OwnerReference{owner}, nil))
+	select {
+	case <-cache.Triggered():
+	default:
+		t.Errorf("expected a session triggered by the pod created with its node")
+	}
+	cache.AddPod(buildPod("c1", "p2", "n1", v1.PodPending, buildResourceList("1000m", "1G"), []metav1.OwnerReference{owner}, nil))
+	cache.AddPod(buildPod("c1", "p3", "", v1.PodPending, buildResourceList("1000m", "1G"), []metav1.OwnerReference{owner}, nil))
+
+	job := cache.Jobs["j1"]
+	if ready, bound := job.ReadyTaskNum(), len(job.TaskStatusIndex[api.Bound]); ready != 2 || bound != 2 {
+		t.Errorf("expected 2 tasks bound and ready, got %d bound, %d ready", bound, ready)
+	}
+	if used := cache.Nodes["n1"].Used; !reflect.DeepEqual(used, buildResource("2000m", "2G")) {
+		t.Errorf("expected the pods created with node accounted on it, got %v", used)
+	}
+}
+
+func TestForeignPods(t *testing.T) {
+	cache := &SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		schedulerName: "kar-scheduler",
+	}
+	node := buildNode("n1", buildResourceList("4000m", "10G"))
+	foreign := buildPod("c1", "p1", "n1", v1.PodRunning, buildResourceList("1000m", "1G"), nil, nil)
+	foreign.Spec.SchedulerName = "default-scheduler"
+
+	// The pod of another scheduler is accounted on its node, whichever is
+	// added first, and after the node is registered again.
+	cache.AddPod(foreign)
+	cache.AddNode(node)
+	cache.DeleteNode(node)
+	if ni, found := cache.Nodes["n1"]; !found || ni.Node != nil || len(ni.Tasks) != 1 || !ni.Idle.IsEmpty() {
+		t.Fatalf("expected the deleted node kept for its task without resources, got %v", ni)
+	}
+	cache.AddNode(node)
+
+	ni := cache.Nodes["n1"]
+	if !reflect.DeepEqual(ni.Used, buildResource("1000m", "1G")) || !reflect.DeepEqual(ni.Idle, buildResource("3000m", "9G")) {
+		t.Errorf("expected the pod of another scheduler accounted, got used %v, idle %v", ni.Used, ni.Idle)
+	}
+	if len(cache.Jobs) != 0 {
+		t.Errorf("expected no job of the pod of another scheduler, got %v", cache.Jobs)
+	}
+
+	// The placeholder of the deleted node is removed with its last task.
+	cache.DeleteNode(node)
+	cache.DeletePod(foreign)
+	if _, found := cache.Nodes["n1"]; found {
+		t.Errorf("expected the deleted node removed with its last task")
+	}
+}
+
+func TestResizePod(t *testing.T) {
+	cache := &SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		schedulerName: "kar-scheduler",
+		triggerCh:     make(chan struct{}, 1),
+	}
+	cache.AddNode(buildNode("n1", buildResourceList("4000m", "10G")))
+
+	owner := buildOwnerReference("j1")
+	pod := buildPod("c1", "p1", "n1", v1.PodRunning, buildResourceList("1000m", "1G"), []metav1.OwnerReference{owner}, nil)
+	pod.Spec.SchedulerName = "kar-scheduler"
+	cache.AddPod(pod)
+	<-cache.triggerCh
+
+	resized := pod.DeepCopy()
+	resized.Spec.Containers[0].Resources.Requests = buildResourceList("2000m", "2G")
+	cache.UpdatePod(pod, resized)
+
+	select {
+	case <-cache.triggerCh:
+	default:
+		t.Errorf("expected a session triggered by the resized pod")
+	}
+
+	ni := cache.Nodes["n1"]
+	if !reflect.DeepEqual(ni.Used, buildResource("2000m", "2G")) || !reflect.DeepEqual(ni.Idle, buildResource("2000m", "8G")) {
+		t.Errorf("expected the node accounted by the resized pod, got used %v, idle %v", ni.Used, ni.Idle)
+	}
+	job := cache.Jobs[api.JobID("j1")]
+	if job == nil || len(job.Tasks) != 1 || !reflect.DeepEqual(job.TotalRequest, buildResource("2000m", "2G")) {
+		t.Fatalf("expected the job kept with the resized task, got %v", job)
+	}
+	if len(job.TaskStatusIndex) != 1 || len(job.TaskStatusIndex[api.Running]) != 1 {
+		t.Errorf("expected the task indexed once as running, got %v", job.TaskStatusIndex)
+	}
+
+	// The status index is updated by the task in cache, e.g. being bound,
+	// not by the status of the old pod.
+	pending := buildPod("c1", "p2", "", v1.PodPending, buildResourceList("1000m", "1G"), []metav1.OwnerReference{owner}, nil)
+	pending.Spec.SchedulerName = "kar-scheduler"
+	cache.AddPod(pending)
+	if err := job.UpdateTaskStatus(job.Tasks[api.TaskID(pending.UID)], api.Binding); err != nil {
+		t.Fatal(err)
+	}
+	bound := pending.DeepCopy()
+	bound.Spec.NodeName = "n1"
+	cache.UpdatePod(pending, bound)
+	if len(job.TaskStatusIndex[api.Binding]) != 0 || len(job.Tasks) != 2 {
+		t.Errorf("expected no task left binding, got %v", job.TaskStatusIndex)
+	}
+}
+
+type failingBinder struct{}
+
+func (fb *failingBinder) Bind(p *v1.Pod, hostname string) error {
+	return fmt.Errorf("admission webhook denied the request")
+}
+
+func TestBindFailure(t *testing.T) {
+	pod := buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{buildOwnerReference("j1")}, nil)
+	pod.Spec.SchedulerName = "kar-scheduler"
+
+	cache := &SchedulerCache{
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Nodes:         make(map[string]*api.NodeInfo),
+		Binder:        &failingBinder{},
+		schedulerName: "kar-scheduler",
+	}
+	cache.AddNode(buildNode("n1", buildResourceList("2000m", "10G")))
+	cache.AddPod(pod)
+
+	for i := 1; i <= 2; i++ {
+		if err := cache.Bind(api.NewTaskInfo(pod), "n1"); err != nil {
+			t.Fatalf("failed to bind task: %v", err)
+		}
+		cache.dispatched.Wait()
+
+		// The task is reverted to Pending, and its resources are idle again.
+		job := cache.Jobs["j1"]
+		if task := job.Tasks[api.TaskID(pod.UID)]; task.Status != api.Pending {
+			t.Errorf("expected the task reverted to Pending, got %v", task.Status)
+		}
+		if node := cache.Nodes["n1"]; len(node.Tasks) != 0 || !reflect.DeepEqual(node.Idle, buildResource("2000m", "10G")) {
+			t.Errorf("expected the resources of task released, got %v", node)
+		}
+
+		// The job backs off longer by each failure.
+		backoff := job.BackoffUntil.Sub(time.Now())
+		if job.BindFailures != i || backoff <= bindBackoffInitial*time.Duration(i-1) || backoff > bindBackoffInitial*time.Duration(i) {
+			t.Errorf("expected %d failures and backoff %v, got %d and %v", i, bindBackoffInitial*time.Duration(i),
+				job.BindFailures, backoff)
+		}
+	}
+}
+
+func TestScheduleRetries(t *testing.T) {
+	owner := buildOwnerReference("j1")
+	pod1 := buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{owner}, nil)
+	pod2 := buildPod("c1", "p2", "", v1.PodPending, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{owner}, nil)
+	ss := &arbv1.SchedulingSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "ss1",
+			Namespace:       "c1",
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Spec: arbv1.SchedulingSpecTemplate{
+			MinAvailable:       1,
+			MaxScheduleRetries: 2,
+		},
+	}
+
+	cache := &SchedulerCache{
+		Jobs:  make(map[api.JobID]*api.JobInfo),
+		Nodes: make(map[string]*api.NodeInfo),
+	}
+	cache.AddPod(pod1)
+	cache.AddSchedulingSpec(ss)
+	job := cache.Jobs["j1"]
+
+	// The job is given up after its retries.
+	for i := 1; i <= 2; i++ {
+		if failures := cache.RecordScheduleAttempt(job, true); failures != i {
+			t.Errorf("expected %d failures, got %d", i, failures)
+		}
+	}
+	if !job.RetriesExhausted() {
+		t.Errorf("expected retries of job exhausted after %d failures", job.ScheduleFailures)
+	}
+
+	// The update of pod does not retry the job, but a new pod does.
+	cache.UpdatePod(pod1, pod1)
+	if !job.RetriesExhausted() {
+		t.Errorf("expected retries of job exhausted after pod updated")
+	}
+	cache.AddPod(pod2)
+	if job.RetriesExhausted() {
+		t.Errorf("expected job retried after new pod added, got %d failures", job.ScheduleFailures)
+	}
+
+	// The change of spec retries the job.
+	cache.RecordScheduleAttempt(job, true)
+	cache.RecordScheduleAttempt(job, true)
+	updated := ss.DeepCopy()
+	updated.Spec.NodeSelector = map[string]string{"zone": "a"}
+	cache.UpdateSchedulingSpec(ss, updated)
+	if job.RetriesExhausted() {
+		t.Errorf("expected job retried after spec updated, got %d failures", job.ScheduleFailures)
+	}
+
+	// The count is reset once the job is scheduled.
+	cache.RecordScheduleAttempt(job, true)
+	if failures := cache.RecordScheduleAttempt(job, false); failures != 0 {
+		t.Errorf("expected failures reset after scheduled, got %d", failures)
+	}
+}
+
+func TestRecreateJobOwner(t *testing.T) {
+	now := time.Now()
+	labels := map[string]string{api.MPIJobNameLabel: "mpi"}
+	oldPod := buildPod("c1", "launcher-old", "", v1.PodSucceeded, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{buildOwnerReference("launcher-1")}, labels)
+	oldPod.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+	newPod := buildPod("c1", "launcher-new", "", v1.PodPending, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{buildOwnerReference("launcher-2")}, labels)
+	newPod.CreationTimestamp = metav1.NewTime(now.Add(time.Second))
+
+	buildSpec := func(uid string, created time.Time) *arbv1.SchedulingSpec {
+		return &arbv1.SchedulingSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				UID:               types.UID(uid),
+				Name:              "mpi",
+				Namespace:         "c1",
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: arbv1.SchedulingSpecTemplate{MinAvailable: 1},
+		}
+	}
+	ss1, ss2 := buildSpec("ss-1", now.Add(-2*time.Hour)), buildSpec("ss-2", now)
+
+	cache := &SchedulerCache{
+		Jobs:  make(map[api.JobID]*api.JobInfo),
+		Nodes: make(map[string]*api.NodeInfo),
+	}
+	cache.AddSchedulingSpec(ss1)
+	cache.AddPod(oldPod)
+	job := cache.Jobs[api.GroupJobID("c1", "mpi")]
+	if job == nil || job.ReadyTaskNum() != 1 {
+		t.Fatalf("expected the succeeded task counted in job, got %v", job)
+	}
+
+	// The MPIJob is deleted and recreated with the same name.
+	job.ScheduleFailures = 3
+	cache.DeleteSchedulingSpec(ss1)
+	cache.AddSchedulingSpec(ss2)
+	if len(job.Tasks) != 0 || job.ScheduleFailures != 0 {
+		t.Errorf("expected the job of previous owner reconciled, got %d tasks and %d failures",
+			len(job.Tasks), job.ScheduleFailures)
+	}
+
+	// The task of previous owner is not added back by its update, but the
+	// new one is added.
+	cache.UpdatePod(oldPod, oldPod)
+	cache.AddPod(newPod)
+	if _, found := job.Tasks[api.TaskID(newPod.UID)]; len(job.Tasks) != 1 || !found {
+		t.Errorf("expected only the task of new owner in job, got %v", job)
+	}
+
+	// The stale delete of the previous SchedulingSpec is skipped.
+	cache.DeleteSchedulingSpec(ss1)
+	if job.SchedSpec == nil || job.SchedSpec.UID != ss2.UID {
+		t.Errorf("expected the SchedulingSpec %v kept in job, got %v", ss2.UID, job.SchedSpec)
+	}
+
+	// The SchedulingSpec recreated for another controller is updated with
+	// another UID; the job of previous controller is deleted.
+	ssA := buildSpec("ss-a", now)
+	ssA.OwnerReferences = []metav1.OwnerReference{buildOwnerReference("jA")}
+	ssB := buildSpec("ss-b", now)
+	ssB.OwnerReferences = []metav1.OwnerReference{buildOwnerReference("jB")}
+	cache.AddSchedulingSpec(ssA)
+	cache.UpdateSchedulingSpec(ssA, ssB)
+	if _, found := cache.Jobs["jA"]; found {
+		t.Errorf("expected the job of previous controller deleted")
+	}
+	if job := cache.Jobs["jB"]; job == nil || job.SchedSpec.UID != ssB.UID {
+		t.Errorf("expected the job of new controller with SchedulingSpec %v, got %v", ssB.UID, job)
+	}
+}
+
+type fakeEvictor struct {
+	err error
+}
+
+func (fe *fakeEvictor) Evict(p *v1.Pod) error {
+	return fe.err
+}
+
+type fakeRecorder struct {
+	reasons []string
+}
+
+func (fr *fakeRecorder) Eventf(ref *v1.ObjectReference, eventType, reason, messageFmt string, args ...interface{}) {
+	fr.reasons = append(fr.reasons, reason)
+}
+
+func TestEvictFailure(t *testing.T) {
+	pod := buildPod("c1", "p1", "n1", v1.PodRunning, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{buildOwnerReference("j1")}, nil)
+
+	for _, test := range []struct {
+		err       error
+		status    api.TaskStatus
+		reason    string
+		idle      *api.Resource
+		releasing *api.Resource
+	}{
+		{
+			// The resources are releasing until the pod is deleted.
+			status:    api.Releasing,
+			reason:    "Evict",
+			idle:      buildResource("1000m", "9G"),
+			releasing: buildResource("1000m", "1G"),
+		},
+		{
+			// The task is reverted if the eviction is rejected, e.g. by PDB.
+			err:       fmt.Errorf("Cannot evict pod as it would violate the pod's disruption budget"),
+			status:    api.Running,
+			reason:    "FailedEvict",
+			idle:      buildResource("1000m", "9G"),
+			releasing: api.EmptyResource(),
+		},
+	} {
+		recorder := &fakeRecorder{}
+		cache := &SchedulerCache{
+			Jobs:     make(map[api.JobID]*api.JobInfo),
+			Nodes:    make(map[string]*api.NodeInfo),
+			Evictor:  &fakeEvictor{err: test.err},
+			Recorder: recorder,
+		}
+		cache.AddNode(buildNode("n1", buildResourceList("2000m", "10G")))
+		cache.AddPod(pod)
+
+		if err := cache.Evict(api.NewTaskInfo(pod), "Preempted"); err != nil {
+			t.Fatalf("failed to evict task: %v", err)
+		}
+		cache.dispatched.Wait()
+
+		task := cache.Jobs["j1"].Tasks[api.TaskID(pod.UID)]
+		node := cache.Nodes["n1"]
+		if task.Status != test.status || !reflect.DeepEqual(recorder.reasons, []string{test.reason}) {
+			t.Errorf("expected task %v with event %v, got %v with %v", test.status, test.reason,
+				task.Status, recorder.reasons)
+		}
+		if !reflect.DeepEqual(node.Idle, test.idle) || !reflect.DeepEqual(node.Releasing, test.releasing) {
+			t.Errorf("expected idle %v and releasing %v, got %v and %v", test.idle, test.releasing,
+				node.Idle, node.Releasing)
+		}
+	}
+}
+
+func TestDuplicatePodEvents(t *testing.T) {
+	pod := buildPod("c1", "p1", "n1", v1.PodRunning, buildResourceList("1000m", "1G"),
+		[]metav1.OwnerReference{buildOwnerReference("j1")}, nil)
+	pod.ResourceVersion = "1"
+
+	cache := &SchedulerCache{
+		Jobs:     make(map[api.JobID]*api.JobInfo),
+		Nodes:    make(map[string]*api.NodeInfo),
+		Evictor:  &fakeEvictor{},
+		Recorder: &fakeRecorder{},
+	}
+	cache.AddNode(buildNode("n1", buildResourceList("2000m", "10G")))
+	cache.AddPod(pod)
+	if err := cache.Evict(api.NewTaskInfo(pod), "Preempted"); err != nil {
+		t.Fatalf("failed to evict task: %v", err)
+	}
+	cache.dispatched.Wait()
+
+	// The replayed events of the same version do not revert the eviction.
+	cache.AddPod(pod)
+	cache.UpdatePod(pod, pod)
+	job, node := cache.Jobs["j1"], cache.Nodes["n1"]
+	if task := job.Tasks[api.TaskID(pod.UID)]; task.Status != api.Releasing {
+		t.Errorf("expected task still releasing, got %v", task.Status)
+	}
+	if !reflect.DeepEqual(node.Releasing, buildResource("1000m", "1G")) {
+		t.Errorf("expected the resources of task releasing, got %v", node.Releasing)
+	}
+
+	// The new version of pod is updated.
+	deleting := pod.DeepCopy()
+	deleting.ResourceVersion = "2"
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+	cache.UpdatePod(pod, deleting)
+	if task := job.Tasks[api.TaskID(pod.UID)]; task.Pod.ResourceVersion != "2" {
+		t.Errorf("expected task updated to version 2, got %v", task.Pod.ResourceVersion)
+	}
+
+	// The repeated Delete event is skipped.
+	cache.DeletePod(deleting)
+	cache.DeletePod(deleting)
+	if _, found := cache.Jobs["j1"]; found || len(node.Tasks) != 0 ||
+		!reflect.DeepEqual(node.Idle, buildResource("2000m", "10G")) {
+		t.Errorf("expected pod deleted once from cache, got node %v", node)
+	}
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	arbapi "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+// RestoredEvictionReason is the reason of the evictions restored from the
+// derived state of the last scheduler.
+const RestoredEvictionReason = "evicted before scheduler restarted"
+
+// DerivedState is the decisions in cache which are not written to apiserver
+// yet, so they're lost by a restart of scheduler unless exported and imported;
+// e.g. the victims of a preemption not evicted yet are evicted again for
+// other preemptors after restart.
+type DerivedState struct {
+	Timestamp time.Time `json:"timestamp"`
+
+	// Assumed is the tasks assumed on nodes, whose binds are not done.
+	Assumed []*TaskPlacement `json:"assumed,omitempty"`
+	// Evicting is the tasks being evicted, whose pods are not deleted yet.
+	Evicting []*TaskPlacement `json:"evicting,omitempty"`
+	// Nominated is the tasks nominated to the nodes of their victims, whose
+	// nominatedNodeName is not updated yet.
+	Nominated []*TaskPlacement `json:"nominated,omitempty"`
+}
+
+// TaskPlacement is a task and its node.
+type TaskPlacement struct {
+	UID       types.UID `json:"uid"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Node      string    `json:"node"`
+}
+
+func newTaskPlacement(task *arbapi.TaskInfo, node string) *TaskPlacement {
+	return &TaskPlacement{
+		UID:       types.UID(task.UID),
+		Namespace: task.Namespace,
+		Name:      task.Name,
+		Node:      node,
+	}
+}
+
+// ExportDerivedState returns the decisions in cache not written to apiserver.
+func (sc *SchedulerCache) ExportDerivedState() *DerivedState {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	state := &DerivedState{Timestamp: time.Now()}
+
+	// The tasks assumed are only on nodes, whose NodeName is not set.
+	for name, node := range sc.Nodes {
+		for _, task := range node.Tasks {
+			switch {
+			case task.Status == arbapi.Binding:
+				state.Assumed = append(state.Assumed, newTaskPlacement(task, name))
+			case task.Status == arbapi.Releasing && task.Pod != nil && task.Pod.DeletionTimestamp == nil:
+				state.Evicting = append(state.Evicting, newTaskPlacement(task, name))
+			}
+		}
+	}
+
+	for _, job := range sc.Jobs {
+		for _, task := range job.Tasks {
+			if node, found := sc.nominations[task.UID]; found {
+				state.Nominated = append(state.Nominated, newTaskPlacement(task, node))
+			}
+		}
+	}
+
+	return state
+}
+
+// ImportDerivedState replays the decisions of state which are still valid in
+// cache, e.g. exported by the last scheduler before it stopped: the tasks
+// still pending are assumed on their nodes if all the ones of their job fit,
+// the tasks not deleted yet are evicted, and the pending tasks are nominated.
+// It's called after cache synced and before the first session.
+func (sc *SchedulerCache) ImportDerivedState(state *DerivedState) {
+	var assumed, dropped, evicted, nominated int
+
+	for _, tp := range state.Evicting {
+		task := sc.findTask(tp, func(task *arbapi.TaskInfo) bool {
+			return task.Status != arbapi.Releasing && arbapi.OccupiedResources(task.Status) &&
+				task.NodeName == tp.Node
+		})
+		if task == nil {
+			continue
+		}
+		if err := sc.Evict(task, RestoredEvictionReason); err != nil {
+			logging.Error(err, "Failed to restore eviction", "task", tp.UID, "node", tp.Node)
+			continue
+		}
+		evicted++
+	}
+
+	// The assumed tasks are restored by job, so a gang is not left partially
+	// bound if some of its tasks don't fit any more.
+	var jobs []arbapi.JobID
+	restores := map[arbapi.JobID][]*assumedTask{}
+	for _, tp := range state.Assumed {
+		task := sc.findTask(tp, func(task *arbapi.TaskInfo) bool {
+			return task.Status == arbapi.Pending
+		})
+		if task == nil {
+			continue
+		}
+		if _, found := restores[task.Job]; !found {
+			jobs = append(jobs, task.Job)
+		}
+		restores[task.Job] = append(restores[task.Job], &assumedTask{task: task, node: tp.Node})
+	}
+
+	for _, job := range jobs {
+		if !sc.assumedTasksFit(restores[job]) {
+			logging.Info("Drop assumed tasks of job not fit any more", "job", job, "tasks", len(restores[job]))
+			dropped += len(restores[job])
+			continue
+		}
+		for _, at := range restores[job] {
+			if err := sc.Bind(at.task, at.node); err != nil {
+				logging.Error(err, "Failed to restore assumed task", "task", at.task.UID, "node", at.node)
+				continue
+			}
+			assumed++
+		}
+	}
+
+	for _, tp := range state.Nominated {
+		task := sc.findTask(tp, func(task *arbapi.TaskInfo) bool {
+			return task.Status == arbapi.Pending && task.Pod != nil && task.Pod.Status.NominatedNodeName != tp.Node
+		})
+		if task == nil {
+			continue
+		}
+		sc.NominateTask(task, tp.Node)
+
+		// The nominated node is set in cache at once, so the first session
+		// waits for the victims instead of preempting again.
+		sc.Mutex.Lock()
+		pod := task.Pod.DeepCopy()
+		pod.Status.NominatedNodeName = tp.Node
+		task.Pod = pod
+		sc.Mutex.Unlock()
+		nominated++
+	}
+
+	logging.Info("Imported derived state", "exportedAt", state.Timestamp,
+		"assumed", assumed, "dropped", dropped, "evicted", evicted, "nominated", nominated)
+}
+
+// assumedTask is a pending task to be assumed on node.
+type assumedTask struct {
+	task *arbapi.TaskInfo
+	node string
+}
+
+// assumedTasksFit returns whether all tasks fit their nodes together.
+func (sc *SchedulerCache) assumedTasksFit(tasks []*assumedTask) bool {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	idle := map[string]*arbapi.Resource{}
+	for _, at := range tasks {
+		node, found := sc.Nodes[at.node]
+		if !found || node.Node == nil || at.task.Status != arbapi.Pending {
+			return false
+		}
+		if _, found := idle[at.node]; !found {
+			idle[at.node] = node.Idle.Clone()
+		}
+		if !at.task.Resreq.LessEqual(idle[at.node]) {
+			return false
+		}
+		idle[at.node].Sub(at.task.Resreq)
+	}
+	return true
+}
+
+// findTask returns the task of tp in cache if valid returns true for it, nil
+// if it's not found; valid is called with lock acquired.
+func (sc *SchedulerCache) findTask(tp *TaskPlacement, valid func(task *arbapi.TaskInfo) bool) *arbapi.TaskInfo {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	for _, job := range sc.Jobs {
+		if task, found := job.Tasks[arbapi.TaskID(tp.UID)]; found && valid(task) {
+			return task
+		}
+	}
+	return nil
+}
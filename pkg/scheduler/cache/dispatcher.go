@@ -0,0 +1,186 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+
+	"k8s.io/client-go/util/flowcontrol"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/metrics"
+)
+
+var (
+	// DispatchWorkers is the maximum number of concurrent API writes, e.g.
+	// binding pods, updating status and evicting pods.
+	DispatchWorkers = 16
+	// DispatchQPS is the maximum QPS of API writes.
+	DispatchQPS float32 = 50
+	// DispatchBurst is the maximum burst of API writes.
+	DispatchBurst = 100
+)
+
+// dispatcher executes API writes asynchronously by bounded workers with
+// client side rate limiting, so a burst of binds does not block scheduling
+// nor flood apiserver. The writes are queued without limit, and executed in
+// the order of dispatching.
+type dispatcher struct {
+	sync.Mutex
+	cond *sync.Cond
+
+	writes  []*apiWrite
+	limiter flowcontrol.RateLimiter
+	workers int
+	stopped bool
+}
+
+// apiWrite is an API write of the kind, e.g. "bind".
+type apiWrite struct {
+	kind string
+	fn   func()
+}
+
+func newDispatcher(workers int, qps float32, burst int) *dispatcher {
+	d := &dispatcher{
+		limiter: flowcontrol.NewTokenBucketRateLimiter(qps, burst),
+		workers: workers,
+	}
+	d.cond = sync.NewCond(&d.Mutex)
+
+	return d
+}
+
+// Run starts workers until stopCh is closed; the queued writes are dropped
+// when stopped.
+func (d *dispatcher) Run(stopCh <-chan struct{}) {
+	for i := 0; i < d.workers; i++ {
+		go d.worker()
+	}
+
+	<-stopCh
+
+	d.Lock()
+	defer d.Unlock()
+
+	d.stopped = true
+	d.limiter.Stop()
+	d.cond.Broadcast()
+}
+
+// Dispatch queues fn as an API write of kind.
+func (d *dispatcher) Dispatch(kind string, fn func()) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.writes = append(d.writes, &apiWrite{kind: kind, fn: fn})
+	metrics.UpdatePendingAPIWrites(kind, 1)
+	d.cond.Signal()
+}
+
+func (d *dispatcher) worker() {
+	for {
+		d.Lock()
+		for len(d.writes) == 0 && !d.stopped {
+			d.cond.Wait()
+		}
+		if d.stopped {
+			d.Unlock()
+			return
+		}
+
+		w := d.writes[0]
+		d.writes[0] = nil
+		d.writes = d.writes[1:]
+		d.Unlock()
+
+		d.limiter.Accept()
+		metrics.UpdatePendingAPIWrites(w.kind, -1)
+		w.fn()
+	}
+}
+
+// dispatch executes fn by the dispatcher of cache; fn is executed in a new
+// goroutine if there's no dispatcher, e.g. in tests and simulator. fn is
+// queued instead if the writes are held, and dropped if standby.
+func (sc *SchedulerCache) dispatch(kind string, fn func()) {
+	sc.heldMutex.Lock()
+	defer sc.heldMutex.Unlock()
+
+	if sc.standby {
+		logging.Warning("Drop API write of standby scheduler", "kind", kind)
+		return
+	}
+	if sc.holding {
+		sc.held = append(sc.held, &apiWrite{kind: kind, fn: fn})
+		return
+	}
+	sc.execute(kind, fn)
+}
+
+func (sc *SchedulerCache) execute(kind string, fn func()) {
+	if sc.dispatcher == nil {
+		sc.dispatched.Add(1)
+		go func() {
+			defer sc.dispatched.Done()
+			fn()
+		}()
+		return
+	}
+
+	sc.dispatcher.Dispatch(kind, fn)
+}
+
+// HoldWrites holds the API writes until ReleaseWrites, e.g. so the plan of a
+// session is emitted before it's executed.
+func (sc *SchedulerCache) HoldWrites() {
+	sc.heldMutex.Lock()
+	defer sc.heldMutex.Unlock()
+
+	sc.holding = true
+}
+
+// ReleaseWrites executes the writes held since HoldWrites in order.
+func (sc *SchedulerCache) ReleaseWrites() {
+	sc.heldMutex.Lock()
+	defer sc.heldMutex.Unlock()
+
+	for _, w := range sc.held {
+		sc.execute(w.kind, w.fn)
+	}
+	sc.held = nil
+	sc.holding = false
+}
+
+// SetStandby sets whether the API writes are dropped; the held writes are
+// dropped too when it's set.
+func (sc *SchedulerCache) SetStandby(standby bool) {
+	sc.heldMutex.Lock()
+	defer sc.heldMutex.Unlock()
+
+	sc.standby = standby
+	if standby {
+		sc.held = nil
+		sc.holding = false
+	}
+}
+
+// WaitForDispatched waits for the writes executed without dispatcher, e.g. so
+// the simulator applies the binds of a session before the next one.
+func (sc *SchedulerCache) WaitForDispatched() {
+	sc.dispatched.Wait()
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcher(t *testing.T) {
+	const writes = 50
+	const workers = 2
+
+	d := newDispatcher(workers, 1000, writes)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go d.Run(stopCh)
+
+	var wg sync.WaitGroup
+	var running, maxRunning int32
+	wg.Add(writes)
+	for i := 0; i < writes; i++ {
+		d.Dispatch("test", func() {
+			defer wg.Done()
+
+			n := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timeout waiting for %d writes", writes)
+	}
+
+	if maxRunning > workers {
+		t.Errorf("expected at most %d concurrent writes, got %d", workers, maxRunning)
+	}
+}
+
+func TestHoldWrites(t *testing.T) {
+	sc := &SchedulerCache{}
+
+	var executed int32
+	write := func() { atomic.AddInt32(&executed, 1) }
+
+	sc.HoldWrites()
+	sc.dispatch("test", write)
+	sc.dispatch("test", write)
+	sc.WaitForDispatched()
+	if n := atomic.LoadInt32(&executed); n != 0 {
+		t.Errorf("expected no writes executed when held, got %d", n)
+	}
+
+	sc.ReleaseWrites()
+	sc.dispatch("test", write)
+	sc.WaitForDispatched()
+	if n := atomic.LoadInt32(&executed); n != 3 {
+		t.Errorf("expected 3 writes executed after released, got %d", n)
+	}
+}
+
+func TestStandbyWrites(t *testing.T) {
+	sc := &SchedulerCache{}
+
+	var executed int32
+	write := func() { atomic.AddInt32(&executed, 1) }
+
+	sc.HoldWrites()
+	sc.dispatch("test", write)
+	sc.SetStandby(true)
+	sc.dispatch("test", write)
+	sc.ReleaseWrites()
+	sc.WaitForDispatched()
+	if n := atomic.LoadInt32(&executed); n != 0 {
+		t.Errorf("expected no writes executed on standby, got %d", n)
+	}
+
+	sc.SetStandby(false)
+	sc.dispatch("test", write)
+	sc.WaitForDispatched()
+	if n := atomic.LoadInt32(&executed); n != 1 {
+		t.Errorf("expected 1 write executed after standby, got %d", n)
+	}
+}
@@ -18,15 +18,17 @@ package cache
 
 import (
 	"fmt"
-
-	"github.com/golang/glog"
+	"reflect"
+	"time"
 
 	"k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
 	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
 	arbapi "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
 )
 
@@ -38,23 +40,43 @@ func isTerminated(status arbapi.TaskStatus) bool {
 func (sc *SchedulerCache) addPod(pod *v1.Pod) error {
 	pi := arbapi.NewTaskInfo(pod)
 
-	if len(pi.Job) != 0 {
+	// The pods of other schedulers are not scheduled, but only accounted on
+	// their nodes.
+	if !sc.managed(pod) {
+		logging.V(4).Info("Pod is scheduled by another scheduler", "pod", arbapi.PodKey(pod),
+			"scheduler", pod.Spec.SchedulerName)
+	} else if len(pi.Job) != 0 {
 		if _, found := sc.Jobs[pi.Job]; !found {
 			sc.Jobs[pi.Job] = arbapi.NewJobInfo(pi.Job)
+			// The pod without controller or pod group is scheduled as a job
+			// of itself.
+			if pi.Job == arbapi.ImplicitJobID(pod) {
+				sc.Jobs[pi.Job].SetImplicitPod(pod)
+			}
+		}
+
+		// A new pod of the job, e.g. replacing a pod with impossible node
+		// selector, gives the job another maxScheduleRetries.
+		if _, found := sc.Jobs[pi.Job].Tasks[pi.UID]; !found {
+			sc.Jobs[pi.Job].ScheduleFailures = 0
 		}
 
-		// TODO(k82cn): it's found that the Add event will be sent
-		// multiple times without update/delete. That should be a
-		// client-go issue, we need to dig deeper for that.
+		// The task of an updated pod is replaced in place; the repeated Add
+		// events are skipped by duplicatePod.
 		sc.Jobs[pi.Job].DeleteTaskInfo(pi)
-		sc.Jobs[pi.Job].AddTaskInfo(pi)
+		if ss := sc.Jobs[pi.Job].SchedSpec; ss != nil && ownedByPrevious(pi, ss) {
+			logging.V(4).Info("Skip task of previous owner of job", "job", pi.Job, "task", pi.UID,
+				"pod", arbapi.PodKey(pod))
+		} else {
+			sc.Jobs[pi.Job].AddTaskInfo(pi)
+		}
 	} else {
-		glog.Warningf("The controller of pod %v/%v is empty, can not schedule it.",
-			pod.Namespace, pod.Name)
+		logging.Warning("The controller of pod is empty, can not schedule it",
+			"pod", arbapi.PodKey(pod))
 	}
 
 	if len(pi.NodeName) != 0 {
-		glog.V(3).Infof("Add task %v/%v into host %v", pi.Namespace, pi.Name, pi.NodeName)
+		logging.V(3).Info("Add task into node", "job", pi.Job, "task", pi.UID, "node", pi.NodeName)
 
 		if _, found := sc.Nodes[pi.NodeName]; !found {
 			sc.Nodes[pi.NodeName] = arbapi.NewNodeInfo(nil)
@@ -66,37 +88,85 @@ func (sc *SchedulerCache) addPod(pod *v1.Pod) error {
 		if !isTerminated(pi.Status) {
 			node.AddTask(pi)
 		}
+
+		// The node not added or deleted is only kept for its tasks.
+		if node.Node == nil && len(node.Tasks) == 0 {
+			delete(sc.Nodes, pi.NodeName)
+		}
+
+		if !sc.managed(pod) && node.Overcommitted() {
+			sc.resolveConflicts(node)
+		}
+	}
+
+	if sc.podVersions == nil {
+		sc.podVersions = map[types.UID]string{}
 	}
+	sc.podVersions[pod.UID] = pod.ResourceVersion
 
 	return nil
 }
 
+// duplicatePod returns whether the same ResourceVersion of pod is in cache,
+// e.g. by a repeated Add event or a resync, which is skipped so the status
+// of its task set by scheduler, e.g. Releasing, is not reverted; the pods
+// without ResourceVersion, e.g. of tests and simulator, are never duplicate.
+func (sc *SchedulerCache) duplicatePod(pod *v1.Pod) bool {
+	return len(pod.ResourceVersion) != 0 && sc.podVersions[pod.UID] == pod.ResourceVersion
+}
+
 // Assumes that lock is already acquired.
 func (sc *SchedulerCache) updatePod(oldPod, newPod *v1.Pod) error {
-	if err := sc.deletePod(oldPod); err != nil {
-		return err
+	// The task of the pod kept in its job and on its node, e.g. its status
+	// changed or its resources resized, is replaced in place by addPod; so
+	// the job or the node only kept for it is not deleted in between.
+	if !sameTaskOwner(oldPod, newPod) || sc.managed(oldPod) != sc.managed(newPod) {
+		if err := sc.deletePod(oldPod); err != nil {
+			return err
+		}
 	}
 	return sc.addPod(newPod)
 }
 
+// sameTaskOwner returns whether oldPod and newPod are the same task of the
+// same job on the same node.
+func sameTaskOwner(oldPod, newPod *v1.Pod) bool {
+	oldTask, newTask := arbapi.NewTaskInfo(oldPod), arbapi.NewTaskInfo(newPod)
+	return oldTask.UID == newTask.UID && oldTask.Job == newTask.Job && oldTask.NodeName == newTask.NodeName
+}
+
+// isResized returns whether the resource requests of pod are changed, which
+// changes the accounting of its node and the minimal resources of its gang.
+func isResized(oldPod, newPod *v1.Pod) bool {
+	oldReq, newReq := arbapi.NewTaskInfo(oldPod).Resreq, arbapi.NewTaskInfo(newPod).Resreq
+	return !oldReq.LessEqual(newReq) || !newReq.LessEqual(oldReq)
+}
+
 // Assumes that lock is already acquired.
 func (sc *SchedulerCache) deletePod(pod *v1.Pod) error {
 	pi := arbapi.NewTaskInfo(pod)
+	delete(sc.podVersions, pod.UID)
 
-	if len(pi.Job) != 0 {
+	if len(pi.Job) != 0 && sc.managed(pod) {
 		if job, found := sc.Jobs[pi.Job]; found {
 			job.DeleteTaskInfo(pi)
+			sc.deleteJob(job)
 		} else {
-			glog.Warningf("Failed to find Job for Task %v:%v/%v.",
-				pi.UID, pi.Namespace, pi.Name)
+			logging.Warning("Failed to find job of task", "job", pi.Job, "task", pi.UID,
+				"pod", arbapi.PodKey(pod))
 		}
 	}
 
 	if len(pi.NodeName) != 0 {
 		node := sc.Nodes[pi.NodeName]
 		if node != nil {
-			glog.V(3).Infof("Delete task %v/%v from host %v", pi.Namespace, pi.Name, pi.NodeName)
+			logging.V(3).Info("Delete task from node", "job", pi.Job, "task", pi.UID, "node", pi.NodeName)
 			node.RemoveTask(pi)
+
+			// The node not added or deleted is only kept for its tasks.
+			if node.Node == nil && len(node.Tasks) == 0 {
+				delete(sc.Nodes, pi.NodeName)
+			}
 		}
 	}
 
@@ -106,46 +176,89 @@ func (sc *SchedulerCache) deletePod(pod *v1.Pod) error {
 func (sc *SchedulerCache) AddPod(obj interface{}) {
 	pod, ok := obj.(*v1.Pod)
 	if !ok {
-		glog.Errorf("Cannot convert to *v1.Pod: %v", obj)
+		logging.Error(nil, "Cannot convert to *v1.Pod", "object", obj)
 		return
 	}
 
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
 
-	glog.V(4).Infof("Add pod(%s) into cache, status (%s)", pod.Name, pod.Status.Phase)
+	if sc.duplicatePod(pod) {
+		logging.V(4).Info("Skip duplicate Add event of pod", "pod", arbapi.PodKey(pod),
+			"resourceVersion", pod.ResourceVersion)
+		return
+	}
+
+	logging.V(4).Info("Add pod into cache", "pod", arbapi.PodKey(pod), "phase", pod.Status.Phase)
 	err := sc.addPod(pod)
 	if err != nil {
-		glog.Errorf("Failed to add pod %s into cache: %v", pod.Name, err)
+		logging.Error(err, "Failed to add pod into cache", "pod", arbapi.PodKey(pod))
 		return
 	}
+
+	// The pod created with its node is not scheduled, but may complete the
+	// gang of its job.
+	if len(pod.Spec.NodeName) == 0 || sc.managed(pod) {
+		sc.trigger(podAddedTrigger)
+	}
 	return
 }
 
 func (sc *SchedulerCache) UpdatePod(oldObj, newObj interface{}) {
 	oldPod, ok := oldObj.(*v1.Pod)
 	if !ok {
-		glog.Errorf("Cannot convert oldObj to *v1.Pod: %v", oldObj)
+		logging.Error(nil, "Cannot convert oldObj to *v1.Pod", "object", oldObj)
 		return
 	}
 	newPod, ok := newObj.(*v1.Pod)
 	if !ok {
-		glog.Errorf("Cannot convert newObj to *v1.Pod: %v", newObj)
+		logging.Error(nil, "Cannot convert newObj to *v1.Pod", "object", newObj)
 		return
 	}
 
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
 
-	glog.V(4).Infof("Update oldPod(%s) status(%s) newPod(%s) status(%s) in cache", oldPod.Name, oldPod.Status.Phase, newPod.Name, newPod.Status.Phase)
+	if sc.duplicatePod(newPod) {
+		logging.V(4).Info("Skip duplicate Update event of pod", "pod", arbapi.PodKey(newPod),
+			"resourceVersion", newPod.ResourceVersion)
+		return
+	}
+
+	logging.V(4).Info("Update pod in cache", "pod", arbapi.PodKey(newPod),
+		"oldPhase", oldPod.Status.Phase, "phase", newPod.Status.Phase)
 	err := sc.updatePod(oldPod, newPod)
 	if err != nil {
-		glog.Errorf("Failed to update pod %v in cache: %v", oldPod.Name, err)
+		logging.Error(err, "Failed to update pod in cache", "pod", arbapi.PodKey(newPod))
 		return
 	}
+
+	if isReleased(oldPod, newPod) {
+		sc.trigger(podReleasedTrigger)
+	} else if isResized(oldPod, newPod) {
+		logging.V(3).Info("Pod resources resized", "pod", arbapi.PodKey(newPod),
+			"node", newPod.Spec.NodeName)
+		sc.trigger(podResizedTrigger)
+	}
 	return
 }
 
+// isReleased returns whether the resources of pod on node are released or
+// being released, by pod terminated or deleted.
+func isReleased(oldPod, newPod *v1.Pod) bool {
+	if len(newPod.Spec.NodeName) == 0 {
+		return false
+	}
+
+	oldStatus := arbapi.NewTaskInfo(oldPod).Status
+	newStatus := arbapi.NewTaskInfo(newPod).Status
+	if oldStatus == newStatus {
+		return false
+	}
+
+	return isTerminated(newStatus) || newStatus == arbapi.Releasing
+}
+
 func (sc *SchedulerCache) DeletePod(obj interface{}) {
 	var pod *v1.Pod
 	switch t := obj.(type) {
@@ -155,23 +268,33 @@ func (sc *SchedulerCache) DeletePod(obj interface{}) {
 		var ok bool
 		pod, ok = t.Obj.(*v1.Pod)
 		if !ok {
-			glog.Errorf("Cannot convert to *v1.Pod: %v", t.Obj)
+			logging.Error(nil, "Cannot convert to *v1.Pod", "object", t.Obj)
 			return
 		}
 	default:
-		glog.Errorf("Cannot convert to *v1.Pod: %v", t)
+		logging.Error(nil, "Cannot convert to *v1.Pod", "object", t)
 		return
 	}
 
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
 
-	glog.V(4).Infof("Delete pod(%s) status(%s) from cache", pod.Name, pod.Status.Phase)
+	// The pod not in cache was deleted, e.g. by a repeated Delete event.
+	if _, found := sc.podVersions[pod.UID]; !found {
+		logging.V(4).Info("Skip duplicate Delete event of pod", "pod", arbapi.PodKey(pod))
+		return
+	}
+
+	logging.V(4).Info("Delete pod from cache", "pod", arbapi.PodKey(pod), "phase", pod.Status.Phase)
 	err := sc.deletePod(pod)
 	if err != nil {
-		glog.Errorf("Failed to delete pod %v from cache: %v", pod.Name, err)
+		logging.Error(err, "Failed to delete pod from cache", "pod", arbapi.PodKey(pod))
 		return
 	}
+
+	if len(pod.Spec.NodeName) != 0 {
+		sc.trigger(podReleasedTrigger)
+	}
 	return
 }
 
@@ -199,9 +322,19 @@ func (sc *SchedulerCache) updateNode(oldNode, newNode *v1.Node) error {
 
 // Assumes that lock is already acquired.
 func (sc *SchedulerCache) deleteNode(node *v1.Node) error {
-	if _, ok := sc.Nodes[node.Name]; !ok {
+	ni, ok := sc.Nodes[node.Name]
+	if !ok {
 		return fmt.Errorf("node <%s> does not exist", node.Name)
 	}
+
+	// The tasks on the node, of any scheduler, are kept until their pods are
+	// deleted, so they're still accounted if the node is registered again,
+	// e.g. by its kubelet.
+	if len(ni.Tasks) != 0 {
+		ni.UnsetNode()
+		return nil
+	}
+
 	delete(sc.Nodes, node.Name)
 	return nil
 }
@@ -209,43 +342,49 @@ func (sc *SchedulerCache) deleteNode(node *v1.Node) error {
 func (sc *SchedulerCache) AddNode(obj interface{}) {
 	node, ok := obj.(*v1.Node)
 	if !ok {
-		glog.Errorf("Cannot convert to *v1.Node: %v", obj)
+		logging.Error(nil, "Cannot convert to *v1.Node", "object", obj)
 		return
 	}
 
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
 
-	glog.V(4).Infof("Add node(%s) into cache", node.Name)
+	logging.V(4).Info("Add node into cache", "node", node.Name)
 	err := sc.addNode(node)
 	if err != nil {
-		glog.Errorf("Failed to add node %s into cache: %v", node.Name, err)
+		logging.Error(err, "Failed to add node into cache", "node", node.Name)
 		return
 	}
+
+	sc.trigger(nodeUpdatedTrigger)
 	return
 }
 
 func (sc *SchedulerCache) UpdateNode(oldObj, newObj interface{}) {
 	oldNode, ok := oldObj.(*v1.Node)
 	if !ok {
-		glog.Errorf("Cannot convert oldObj to *v1.Node: %v", oldObj)
+		logging.Error(nil, "Cannot convert oldObj to *v1.Node", "object", oldObj)
 		return
 	}
 	newNode, ok := newObj.(*v1.Node)
 	if !ok {
-		glog.Errorf("Cannot convert newObj to *v1.Node: %v", newObj)
+		logging.Error(nil, "Cannot convert newObj to *v1.Node", "object", newObj)
 		return
 	}
 
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
 
-	glog.V(4).Infof("Update oldNode(%s) newNode(%s) in cache", oldNode.Name, newNode.Name)
+	logging.V(4).Info("Update node in cache", "node", newNode.Name)
 	err := sc.updateNode(oldNode, newNode)
 	if err != nil {
-		glog.Errorf("Failed to update node %v in cache: %v", oldNode.Name, err)
+		logging.Error(err, "Failed to update node in cache", "node", newNode.Name)
 		return
 	}
+
+	if !reflect.DeepEqual(oldNode.Status.Allocatable, newNode.Status.Allocatable) {
+		sc.trigger(nodeUpdatedTrigger)
+	}
 	return
 }
 
@@ -258,21 +397,21 @@ func (sc *SchedulerCache) DeleteNode(obj interface{}) {
 		var ok bool
 		node, ok = t.Obj.(*v1.Node)
 		if !ok {
-			glog.Errorf("Cannot convert to *v1.Node: %v", t.Obj)
+			logging.Error(nil, "Cannot convert to *v1.Node", "object", t.Obj)
 			return
 		}
 	default:
-		glog.Errorf("Cannot convert to *v1.Node: %v", t)
+		logging.Error(nil, "Cannot convert to *v1.Node", "object", t)
 		return
 	}
 
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
 
-	glog.V(4).Infof("Delete node(%s) from cache", node.Name)
+	logging.V(4).Info("Delete node from cache", "node", node.Name)
 	err := sc.deleteNode(node)
 	if err != nil {
-		glog.Errorf("Failed to delete node %s from cache: %v", node.Name, err)
+		logging.Error(err, "Failed to delete node from cache", "node", node.Name)
 		return
 	}
 	return
@@ -280,7 +419,7 @@ func (sc *SchedulerCache) DeleteNode(obj interface{}) {
 
 // Assumes that lock is already acquired.
 func (sc *SchedulerCache) setSchedulingSpec(ss *arbv1.SchedulingSpec) error {
-	job := arbapi.JobID(utils.GetController(ss))
+	job := arbapi.SchedulingSpecJobID(ss)
 
 	if len(job) == 0 {
 		return fmt.Errorf("the controller of SchedulingSpec is empty")
@@ -288,6 +427,8 @@ func (sc *SchedulerCache) setSchedulingSpec(ss *arbv1.SchedulingSpec) error {
 
 	if _, found := sc.Jobs[job]; !found {
 		sc.Jobs[job] = arbapi.NewJobInfo(job)
+	} else {
+		sc.reconcileJob(sc.Jobs[job], ss)
 	}
 
 	sc.Jobs[job].SetSchedulingSpec(ss)
@@ -295,57 +436,127 @@ func (sc *SchedulerCache) setSchedulingSpec(ss *arbv1.SchedulingSpec) error {
 	return nil
 }
 
+// ownedByPrevious returns whether the task is a terminated one of the previous
+// owner of its job keyed by name, e.g. of a MPIJob deleted and recreated with
+// the same name, whose pod was created before the SchedulingSpec of the job.
+func ownedByPrevious(task *arbapi.TaskInfo, ss *arbv1.SchedulingSpec) bool {
+	if task.Pod == nil || !isTerminated(task.Status) || arbapi.JobID(utils.GetController(task.Pod)) == task.Job {
+		return false
+	}
+	return task.Pod.CreationTimestamp.Before(&ss.CreationTimestamp)
+}
+
+// reconcileJob removes the terminated tasks of the previous owner from the
+// job of ss, so they're not counted in the gang; if ss is recreated, the bind
+// and schedule failures and events of the previous one are reset too.
 // Assumes that lock is already acquired.
-func (sc *SchedulerCache) updateSchedulingSpec(oldQueue, newQueue *arbv1.SchedulingSpec) error {
-	return sc.setSchedulingSpec(newQueue)
+func (sc *SchedulerCache) reconcileJob(job *arbapi.JobInfo, ss *arbv1.SchedulingSpec) {
+	for _, task := range job.Tasks {
+		if ownedByPrevious(task, ss) {
+			logging.V(4).Info("Remove task of previous owner from job", "job", job.UID, "task", task.UID,
+				"pod", arbapi.PodKey(task.Pod))
+			job.DeleteTaskInfo(task)
+		}
+	}
+
+	if len(job.OwnerUID) == 0 || job.OwnerUID == ss.UID {
+		return
+	}
+	logging.V(3).Info("Reconcile job of recreated SchedulingSpec", "job", job.UID, "schedulingSpec", ss.Name,
+		"previousUID", job.OwnerUID, "uid", ss.UID)
+	job.BindFailures = 0
+	job.BackoffUntil = time.Time{}
+	job.ScheduleFailures = 0
+	delete(sc.jobEvents, job.UID)
+}
+
+// Assumes that lock is already acquired.
+func (sc *SchedulerCache) updateSchedulingSpec(oldSS, newSS *arbv1.SchedulingSpec) error {
+	// The SchedulingSpec deleted and recreated between relists is updated
+	// with another UID; the job of its previous owner is reconciled as if the
+	// old one is deleted.
+	if oldSS.UID != newSS.UID && arbapi.SchedulingSpecJobID(oldSS) != arbapi.SchedulingSpecJobID(newSS) {
+		if err := sc.deleteSchedulingSpec(oldSS); err != nil {
+			logging.Error(err, "Failed to delete previous SchedulingSpec from cache", "schedulingSpec", oldSS.Name)
+		}
+	}
+	return sc.setSchedulingSpec(newSS)
 }
 
 // Assumes that lock is already acquired.
-func (sc *SchedulerCache) deleteSchedulingSpec(queue *arbv1.SchedulingSpec) error {
+func (sc *SchedulerCache) deleteSchedulingSpec(ss *arbv1.SchedulingSpec) error {
+	jobID := arbapi.SchedulingSpecJobID(ss)
+
+	job, found := sc.Jobs[jobID]
+	if !found {
+		return fmt.Errorf("can not found job %v:%v/%v", jobID, ss.Namespace, ss.Name)
+	}
+
+	// The stale delete of a recreated SchedulingSpec does not unset the new
+	// one.
+	if job.SchedSpec != nil && job.SchedSpec.UID != ss.UID {
+		logging.V(3).Info("Skip deleting recreated SchedulingSpec", "job", jobID, "schedulingSpec", ss.Name,
+			"uid", ss.UID, "currentUID", job.SchedSpec.UID)
+		return nil
+	}
+
+	job.UnsetSchedulingSpec()
+	sc.deleteJob(job)
+
 	return nil
 }
 
 func (sc *SchedulerCache) AddSchedulingSpec(obj interface{}) {
 	ss, ok := obj.(*arbv1.SchedulingSpec)
 	if !ok {
-		glog.Errorf("Cannot convert to *arbv1.Queue: %v", obj)
+		logging.Error(nil, "Cannot convert to *arbv1.Queue", "object", obj)
 		return
 	}
 
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
 
-	glog.V(4).Infof("Add SchedulingSpec(%s) into cache, spec(%#v)", ss.Name, ss.Spec)
+	logging.V(4).Info("Add SchedulingSpec into cache", "schedulingSpec", ss.Name, "spec", fmt.Sprintf("%#v", ss.Spec))
 	err := sc.setSchedulingSpec(ss)
 	if err != nil {
-		glog.Errorf("Failed to add SchedulingSpec %s into cache: %v", ss.Name, err)
+		logging.Error(err, "Failed to add SchedulingSpec into cache", "schedulingSpec", ss.Name)
 		return
 	}
+
+	sc.trigger(jobUpdatedTrigger)
 	return
 }
 
 func (sc *SchedulerCache) UpdateSchedulingSpec(oldObj, newObj interface{}) {
 	oldSS, ok := oldObj.(*arbv1.SchedulingSpec)
 	if !ok {
-		glog.Errorf("Cannot convert oldObj to *arbv1.SchedulingSpec: %v", oldObj)
+		logging.Error(nil, "Cannot convert oldObj to *arbv1.SchedulingSpec", "object", oldObj)
 		return
 	}
 	newSS, ok := newObj.(*arbv1.SchedulingSpec)
 	if !ok {
-		glog.Errorf("Cannot convert newObj to *arbv1.SchedulingSpec: %v", newObj)
+		logging.Error(nil, "Cannot convert newObj to *arbv1.SchedulingSpec", "object", newObj)
 		return
 	}
 
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
 
-	glog.V(4).Infof("Update oldSchedulingSpec(%s) in cache, spec(%#v)", oldSS.Name, oldSS.Spec)
-	glog.V(4).Infof("Update newSchedulingSpec(%s) in cache, spec(%#v)", newSS.Name, newSS.Spec)
+	logging.V(4).Info("Update SchedulingSpec in cache", "schedulingSpec", newSS.Name,
+		"oldSpec", fmt.Sprintf("%#v", oldSS.Spec), "spec", fmt.Sprintf("%#v", newSS.Spec))
 	err := sc.updateSchedulingSpec(oldSS, newSS)
 	if err != nil {
-		glog.Errorf("Failed to update SchedulingSpec %s into cache: %v", oldSS.Name, err)
+		logging.Error(err, "Failed to update SchedulingSpec in cache", "schedulingSpec", newSS.Name)
 		return
 	}
+
+	// The status is updated by scheduler itself, which does not need a session.
+	if !reflect.DeepEqual(oldSS.Spec, newSS.Spec) {
+		if job, found := sc.Jobs[arbapi.SchedulingSpecJobID(newSS)]; found {
+			job.ScheduleFailures = 0
+		}
+		sc.trigger(jobUpdatedTrigger)
+	}
 	return
 }
 
@@ -358,11 +569,11 @@ func (sc *SchedulerCache) DeleteSchedulingSpec(obj interface{}) {
 		var ok bool
 		ss, ok = t.Obj.(*arbv1.SchedulingSpec)
 		if !ok {
-			glog.Errorf("Cannot convert to *arbv1.SchedulingSpec: %v", t.Obj)
+			logging.Error(nil, "Cannot convert to *arbv1.SchedulingSpec", "object", t.Obj)
 			return
 		}
 	default:
-		glog.Errorf("Cannot convert to *arbv1.SchedulingSpec: %v", t)
+		logging.Error(nil, "Cannot convert to *arbv1.SchedulingSpec", "object", t)
 		return
 	}
 
@@ -371,7 +582,7 @@ func (sc *SchedulerCache) DeleteSchedulingSpec(obj interface{}) {
 
 	err := sc.deleteSchedulingSpec(ss)
 	if err != nil {
-		glog.Errorf("Failed to delete SchedulingSpec %s from cache: %v", ss.Name, err)
+		logging.Error(err, "Failed to delete SchedulingSpec from cache", "schedulingSpec", ss.Name)
 		return
 	}
 	return
@@ -379,10 +590,10 @@ func (sc *SchedulerCache) DeleteSchedulingSpec(obj interface{}) {
 
 // Assumes that lock is already acquired.
 func (sc *SchedulerCache) setPDB(pdb *policyv1.PodDisruptionBudget) error {
-	job := arbapi.JobID(utils.GetController(pdb))
+	job := arbapi.PDBJobID(pdb)
 
 	if len(job) == 0 {
-		return fmt.Errorf("the controller of SchedulingSpec is empty")
+		return fmt.Errorf("the controller of PodDisruptionBudget is empty")
 	}
 
 	if _, found := sc.Jobs[job]; !found {
@@ -395,54 +606,92 @@ func (sc *SchedulerCache) setPDB(pdb *policyv1.PodDisruptionBudget) error {
 }
 
 // Assumes that lock is already acquired.
-func (sc *SchedulerCache) updatePDB(oldQueue, newQueue *policyv1.PodDisruptionBudget) error {
-	return sc.setPDB(newQueue)
+func (sc *SchedulerCache) updatePDB(oldPDB, newPDB *policyv1.PodDisruptionBudget) error {
+	// The same as SchedulingSpec, the PDB recreated for another owner is
+	// deleted from the job of the previous one.
+	if arbapi.PDBJobID(oldPDB) != arbapi.PDBJobID(newPDB) {
+		if err := sc.deletePDB(oldPDB); err != nil {
+			logging.Error(err, "Failed to delete previous PodDisruptionBudget from cache", "pdb", oldPDB.Name)
+		}
+	}
+	return sc.setPDB(newPDB)
 }
 
 // Assumes that lock is already acquired.
-func (sc *SchedulerCache) deletePDB(queue *policyv1.PodDisruptionBudget) error {
+func (sc *SchedulerCache) deletePDB(pdb *policyv1.PodDisruptionBudget) error {
+	jobID := arbapi.PDBJobID(pdb)
+
+	job, found := sc.Jobs[jobID]
+	if !found {
+		return fmt.Errorf("can not found job %v:%v/%v", jobID, pdb.Namespace, pdb.Name)
+	}
+
+	if job.PDB != nil && job.PDB.UID != pdb.UID {
+		logging.V(3).Info("Skip deleting recreated PodDisruptionBudget", "job", jobID, "pdb", pdb.Name,
+			"uid", pdb.UID, "currentUID", job.PDB.UID)
+		return nil
+	}
+
+	job.UnsetPDB()
+	sc.deleteJob(job)
+
 	return nil
 }
 
+// deleteJob removes the job from cache once it has neither tasks nor
+// SchedulingSpec/PDB, so finished and deleted gangs do not leak in cache.
+// Assumes that lock is already acquired.
+func (sc *SchedulerCache) deleteJob(job *arbapi.JobInfo) {
+	if job.SchedSpec != nil || job.PDB != nil || len(job.Tasks) != 0 {
+		return
+	}
+
+	logging.V(3).Info("Delete job from cache", "job", job.UID, "namespace", job.Namespace, "name", job.Name)
+	delete(sc.Jobs, job.UID)
+	delete(sc.jobEvents, job.UID)
+}
+
 func (sc *SchedulerCache) AddPDB(obj interface{}) {
 	pdb, ok := obj.(*policyv1.PodDisruptionBudget)
 	if !ok {
-		glog.Errorf("Cannot convert to *policyv1.PodDisruptionBudget: %v", obj)
+		logging.Error(nil, "Cannot convert to *policyv1.PodDisruptionBudget", "object", obj)
 		return
 	}
 
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
 
-	glog.V(4).Infof("Add PodDisruptionBudget(%s) into cache, spec(%#v)", pdb.Name, pdb.Spec)
+	logging.V(4).Info("Add PodDisruptionBudget into cache", "pdb", pdb.Name, "spec", fmt.Sprintf("%#v", pdb.Spec))
 	err := sc.setPDB(pdb)
 	if err != nil {
-		glog.Errorf("Failed to add PodDisruptionBudget %s into cache: %v", pdb.Name, err)
+		logging.Error(err, "Failed to add PodDisruptionBudget into cache", "pdb", pdb.Name)
 		return
 	}
+
+	sc.trigger(jobUpdatedTrigger)
 	return
 }
 
 func (sc *SchedulerCache) UpdatePDB(oldObj, newObj interface{}) {
 	oldPDB, ok := oldObj.(*policyv1.PodDisruptionBudget)
 	if !ok {
-		glog.Errorf("Cannot convert oldObj to *policyv1.PodDisruptionBudget: %v", oldObj)
+		logging.Error(nil, "Cannot convert oldObj to *policyv1.PodDisruptionBudget", "object", oldObj)
 		return
 	}
 	newPDB, ok := newObj.(*policyv1.PodDisruptionBudget)
 	if !ok {
-		glog.Errorf("Cannot convert newObj to *policyv1.PodDisruptionBudget: %v", newObj)
+		logging.Error(nil, "Cannot convert newObj to *policyv1.PodDisruptionBudget", "object", newObj)
 		return
 	}
 
 	sc.Mutex.Lock()
 	defer sc.Mutex.Unlock()
 
-	glog.V(4).Infof("Update oldPDB(%s) in cache, spec(%#v)", oldPDB.Name, oldPDB.Spec)
-	glog.V(4).Infof("Update newPDB(%s) in cache, spec(%#v)", newPDB.Name, newPDB.Spec)
+	logging.V(4).Info("Update PodDisruptionBudget in cache", "pdb", newPDB.Name,
+		"oldSpec", fmt.Sprintf("%#v", oldPDB.Spec), "spec", fmt.Sprintf("%#v", newPDB.Spec))
 	err := sc.updatePDB(oldPDB, newPDB)
 	if err != nil {
-		glog.Errorf("Failed to update PodDisruptionBudget %s into cache: %v", oldPDB.Name, err)
+		logging.Error(err, "Failed to update PodDisruptionBudget in cache", "pdb", newPDB.Name)
 		return
 	}
 	return
@@ -457,11 +706,11 @@ func (sc *SchedulerCache) DeletePDB(obj interface{}) {
 		var ok bool
 		pdb, ok = t.Obj.(*policyv1.PodDisruptionBudget)
 		if !ok {
-			glog.Errorf("Cannot convert to *policyv1.PodDisruptionBudget: %v", t.Obj)
+			logging.Error(nil, "Cannot convert to *policyv1.PodDisruptionBudget", "object", t.Obj)
 			return
 		}
 	default:
-		glog.Errorf("Cannot convert to *policyv1.PodDisruptionBudget: %v", t)
+		logging.Error(nil, "Cannot convert to *policyv1.PodDisruptionBudget", "object", t)
 		return
 	}
 
@@ -470,8 +719,62 @@ func (sc *SchedulerCache) DeletePDB(obj interface{}) {
 
 	err := sc.deletePDB(pdb)
 	if err != nil {
-		glog.Errorf("Failed to delete PodDisruptionBudget %s from cache: %v", pdb.Name, err)
+		logging.Error(err, "Failed to delete PodDisruptionBudget from cache", "pdb", pdb.Name)
 		return
 	}
 	return
 }
+
+func (sc *SchedulerCache) AddQueue(obj interface{}) {
+	queue, ok := obj.(*arbv1.Queue)
+	if !ok {
+		logging.Error(nil, "Cannot convert to *arbv1.Queue", "object", obj)
+		return
+	}
+
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	logging.V(4).Info("Add Queue into cache", "queue", queue.Name, "spec", fmt.Sprintf("%#v", queue.Spec))
+	sc.Queues[queue.Name] = arbapi.NewQueueInfo(queue)
+	sc.trigger(queueUpdateTrigger)
+}
+
+func (sc *SchedulerCache) UpdateQueue(oldObj, newObj interface{}) {
+	newQueue, ok := newObj.(*arbv1.Queue)
+	if !ok {
+		logging.Error(nil, "Cannot convert newObj to *arbv1.Queue", "object", newObj)
+		return
+	}
+
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	logging.V(4).Info("Update Queue in cache", "queue", newQueue.Name, "spec", fmt.Sprintf("%#v", newQueue.Spec))
+	sc.Queues[newQueue.Name] = arbapi.NewQueueInfo(newQueue)
+	sc.trigger(queueUpdateTrigger)
+}
+
+func (sc *SchedulerCache) DeleteQueue(obj interface{}) {
+	var queue *arbv1.Queue
+	switch t := obj.(type) {
+	case *arbv1.Queue:
+		queue = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		queue, ok = t.Obj.(*arbv1.Queue)
+		if !ok {
+			logging.Error(nil, "Cannot convert to *arbv1.Queue", "object", t.Obj)
+			return
+		}
+	default:
+		logging.Error(nil, "Cannot convert to *arbv1.Queue", "object", t)
+		return
+	}
+
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	logging.V(4).Info("Delete Queue from cache", "queue", queue.Name)
+	delete(sc.Queues, queue.Name)
+}
@@ -19,6 +19,7 @@ package cache
 import (
 	"k8s.io/api/core/v1"
 
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
 )
 
@@ -31,14 +32,100 @@ type Cache interface {
 	// Snapshot deep copy overall cache information into snapshot
 	Snapshot() *api.ClusterInfo
 
+	// State returns the objects of cluster in cache, e.g. to replay them by
+	// simulator.
+	State() *ClusterState
+
+	// ExportDerivedState returns the decisions in cache not written to
+	// apiserver yet, e.g. to import them after restart.
+	ExportDerivedState() *DerivedState
+
+	// ImportDerivedState replays the decisions of state which are still
+	// valid in cache.
+	ImportDerivedState(state *DerivedState)
+
 	// WaitForCacheSync waits for all cache synced
 	WaitForCacheSync(stopCh <-chan struct{}) bool
 
+	// Triggered returns the channel notified when events need a scheduling
+	// session, e.g. a job is added or resources are released.
+	Triggered() <-chan struct{}
+
 	// Bind binds Task to the target host.
 	// TODO(jinzhej): clean up expire Tasks.
 	Bind(task *api.TaskInfo, hostname string) error
+
+	// CheckVolumeBinding returns the reasons why the volumes of task can not
+	// be bound on node, e.g. its local PVs are on other nodes; empty if they
+	// can.
+	CheckVolumeBinding(task *api.TaskInfo, node *api.NodeInfo) []string
+
+	// HoldWrites holds the API writes, e.g. binds and evictions, until
+	// ReleaseWrites is called.
+	HoldWrites()
+
+	// ReleaseWrites executes the API writes held in order.
+	ReleaseWrites()
+
+	// SetStandby sets whether the API writes are dropped, e.g. while the
+	// scheduler has no leadership.
+	SetStandby(standby bool)
+
+	// Evict evicts the task to release its resources.
+	Evict(task *api.TaskInfo, reason string) error
+
+	// RecordJobStatusEvent records an event on the SchedulingSpec or PDB of job.
+	RecordJobStatusEvent(job *api.JobInfo, eventType, reason, message string)
+
+	// RecordScheduleAttempt counts the consecutive sessions in which job was
+	// unschedulable, or resets the count once job is scheduled, and returns
+	// the count.
+	RecordScheduleAttempt(job *api.JobInfo, unschedulable bool) int
+
+	// UpdatePodsUnschedulable sets the PodScheduled condition of the pending
+	// tasks of job to Unschedulable, so the cluster autoscaler scales up for
+	// them.
+	UpdatePodsUnschedulable(job *api.JobInfo, message string)
+
+	// NominateTask sets the nominatedNodeName of task's pod to hostname, where
+	// the victims are evicted for it, the same as kube-scheduler preemption.
+	NominateTask(task *api.TaskInfo, hostname string)
+
+	// UpdateJobStatus sets the conditions of job's SchedulingSpec if any of
+	// them is changed.
+	UpdateJobStatus(job *api.JobInfo, conditions ...arbv1.SchedulingSpecCondition)
 }
 
 type Binder interface {
 	Bind(task *v1.Pod, hostname string) error
 }
+
+// VolumeBinder binds the PVCs of pods to the PVs accessible from their nodes,
+// e.g. local PVs.
+type VolumeBinder interface {
+	// CheckVolumeBinding returns the reasons why the volumes of pod can not
+	// be bound on node, empty if they can.
+	CheckVolumeBinding(pod *v1.Pod, node *v1.Node) ([]string, error)
+	// AssumePodVolumes assumes the PVs chosen for the unbound PVCs of pod on
+	// node, so they're not chosen for other pods.
+	AssumePodVolumes(pod *v1.Pod, node *v1.Node) error
+	// BindPodVolumes binds the PVs assumed for pod to its PVCs.
+	BindPodVolumes(pod *v1.Pod) error
+	// ForgetPodVolumes forgets the PVs assumed for pod, e.g. when its bind is
+	// cancelled.
+	ForgetPodVolumes(pod *v1.Pod)
+}
+
+type Evictor interface {
+	Evict(pod *v1.Pod) error
+}
+
+type StatusUpdater interface {
+	UpdateSchedulingSpec(ss *arbv1.SchedulingSpec) error
+	UpdatePodCondition(pod *v1.Pod, condition *v1.PodCondition) error
+	UpdatePodNominatedNode(pod *v1.Pod, nodeName string) error
+}
+
+type Recorder interface {
+	Eventf(ref *v1.ObjectReference, eventType, reason, messageFmt string, args ...interface{})
+}
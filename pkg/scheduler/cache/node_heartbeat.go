@@ -0,0 +1,74 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"time"
+
+	"k8s.io/api/core/v1"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	arbapi "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+// NodeHeartbeatGracePeriod is the age of the last heartbeat of node, i.e. of
+// its Ready condition, after which no task is placed on the node; it's
+// shorter than the grace period of node controller, so the gangs are not
+// bound onto the nodes which just died before they're NotReady. 0 disables
+// the check.
+var NodeHeartbeatGracePeriod = 25 * time.Second
+
+// heartbeatStale returns whether the last heartbeat of node is older than
+// NodeHeartbeatGracePeriod at now; the node without heartbeat, e.g. a
+// simulated node, is not stale.
+func heartbeatStale(node *v1.Node, now time.Time) bool {
+	if NodeHeartbeatGracePeriod <= 0 || node == nil {
+		return false
+	}
+
+	for _, c := range node.Status.Conditions {
+		if c.Type != v1.NodeReady || c.LastHeartbeatTime.IsZero() {
+			continue
+		}
+		return now.Sub(c.LastHeartbeatTime.Time) > NodeHeartbeatGracePeriod
+	}
+	return false
+}
+
+// markStaleNodes marks the nodes in snapshot whose heartbeat is stale as
+// unschedulable, and logs the nodes becoming stale or fresh again; it's
+// called with the lock of cache.
+func (sc *SchedulerCache) markStaleNodes(nodes []*arbapi.NodeInfo) {
+	if sc.staleNodes == nil {
+		sc.staleNodes = map[string]bool{}
+	}
+
+	now := time.Now()
+	for _, node := range nodes {
+		stale := heartbeatStale(node.Node, now)
+		node.Unschedulable = stale
+
+		switch {
+		case stale && !sc.staleNodes[node.Name]:
+			logging.Warning("The heartbeat of node is stale, no task is placed on it", "node", node.Name)
+			sc.staleNodes[node.Name] = true
+		case !stale && sc.staleNodes[node.Name]:
+			logging.Info("The heartbeat of node is fresh again", "node", node.Name)
+			delete(sc.staleNodes, node.Name)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// SimulatedNodes is the nodes added into cache besides the nodes of cluster,
+// e.g. to scale test the policies with thousands of nodes without hardware.
+// The pods are assumed on them without binding, so they're never started.
+var SimulatedNodes []*v1.Node
+
+// NodeTemplate is the template of simulated nodes in the file.
+type NodeTemplate struct {
+	// Name is the prefix of node names; the nodes are named <name>-<index>.
+	Name string `json:"name"`
+	// Count is the number of nodes of the template.
+	Count int `json:"count"`
+	// Labels is the labels of the nodes, e.g. node pool.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Allocatable is the allocatable resources of the nodes, which is also
+	// their capacity.
+	Allocatable v1.ResourceList `json:"allocatable"`
+}
+
+// SimulatedNodesConfig is the file of simulated nodes in YAML or JSON, e.g.
+//
+//	nodes:
+//	- name: hollow-gpu
+//	  count: 1000
+//	  allocatable: {cpu: "32", memory: 128Gi, nvidia.com/gpu: "8"}
+type SimulatedNodesConfig struct {
+	Nodes []NodeTemplate `json:"nodes"`
+}
+
+// LoadSimulatedNodes loads the simulated nodes from the templates in path.
+func LoadSimulatedNodes(path string) ([]*v1.Node, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config := &SimulatedNodesConfig{}
+	if err := yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(config); err != nil {
+		return nil, fmt.Errorf("failed to parse simulated nodes %s: %v", path, err)
+	}
+
+	var nodes []*v1.Node
+	names := map[string]bool{}
+	for _, t := range config.Nodes {
+		if len(t.Name) == 0 || t.Count <= 0 {
+			return nil, fmt.Errorf("name and positive count of simulated nodes are required in %s", path)
+		}
+
+		for i := 0; i < t.Count; i++ {
+			name := fmt.Sprintf("%s-%d", t.Name, i)
+			if names[name] {
+				return nil, fmt.Errorf("duplicated simulated node %s in %s", name, path)
+			}
+			names[name] = true
+
+			nodes = append(nodes, newSimulatedNode(name, &t))
+		}
+	}
+
+	return nodes, nil
+}
+
+func newSimulatedNode(name string, t *NodeTemplate) *v1.Node {
+	labels := map[string]string{"kubernetes.io/hostname": name}
+	for k, v := range t.Labels {
+		labels[k] = v
+	}
+
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			UID:    types.UID("simulated-" + name),
+			Labels: labels,
+		},
+		Status: v1.NodeStatus{
+			Allocatable: t.Allocatable,
+			Capacity:    t.Allocatable,
+			Conditions: []v1.NodeCondition{{
+				Type:   v1.NodeReady,
+				Status: v1.ConditionTrue,
+			}},
+		},
+	}
+}
+
+// addSimulatedNodes adds SimulatedNodes into cache; it's called before the
+// cache runs, so simulatedNodes is not changed afterwards.
+func (sc *SchedulerCache) addSimulatedNodes() {
+	if len(SimulatedNodes) == 0 {
+		return
+	}
+
+	sc.simulatedNodes = make(map[string]bool, len(SimulatedNodes))
+	for _, node := range SimulatedNodes {
+		sc.addNode(node)
+		sc.simulatedNodes[node.Name] = true
+	}
+}
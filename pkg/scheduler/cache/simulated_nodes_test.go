@@ -0,0 +1,61 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+func TestLoadSimulatedNodes(t *testing.T) {
+	f, err := ioutil.TempFile("", "simulated-nodes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	f.WriteString(`
+nodes:
+- name: cpu
+  count: 3
+  allocatable: {cpu: "16", memory: 64Gi}
+- name: gpu
+  count: 2
+  labels: {pool: gpu}
+  allocatable: {cpu: "32", memory: 128Gi, nvidia.com/gpu: "8"}
+`)
+	f.Close()
+
+	nodes, err := LoadSimulatedNodes(f.Name())
+	if err != nil {
+		t.Fatalf("failed to load simulated nodes: %v", err)
+	}
+	if len(nodes) != 5 {
+		t.Fatalf("expected 5 simulated nodes, got %d", len(nodes))
+	}
+
+	gpu := api.NewNodeInfo(nodes[4])
+	if gpu.Name != "gpu-1" || gpu.Node.Labels["pool"] != "gpu" {
+		t.Errorf("expected node gpu-1 labeled by pool, got %v %v", gpu.Name, gpu.Node.Labels)
+	}
+	if gpu.Allocatable.GPU != 8 || gpu.Allocatable.MilliCPU != 32000 {
+		t.Errorf("expected 32 cpu and 8 gpu allocatable, got %v", gpu.Allocatable)
+	}
+}
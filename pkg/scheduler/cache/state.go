@@ -0,0 +1,235 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client/clientset"
+	arbapi "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+// ClusterState is the objects of cluster known by scheduler, which is dumped
+// by scheduler or listed from cluster, and replayed by simulator.
+type ClusterState struct {
+	Nodes                []*v1.Node                      `json:"nodes"`
+	Pods                 []*v1.Pod                       `json:"pods"`
+	SchedulingSpecs      []*arbv1.SchedulingSpec         `json:"schedulingSpecs,omitempty"`
+	PodDisruptionBudgets []*policyv1.PodDisruptionBudget `json:"podDisruptionBudgets,omitempty"`
+	Queues               []*arbv1.Queue                  `json:"queues,omitempty"`
+}
+
+// State returns the objects in cache; the pods assumed on nodes are returned
+// as bound, and the simulated nodes are not returned.
+func (sc *SchedulerCache) State() *ClusterState {
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	state := &ClusterState{}
+
+	for name, node := range sc.Nodes {
+		if node.Node != nil && !sc.simulatedNodes[name] {
+			state.Nodes = append(state.Nodes, node.Node)
+		}
+	}
+
+	pods := map[arbapi.TaskID]bool{}
+	addPod := func(task *arbapi.TaskInfo) {
+		if pods[task.UID] || task.Pod == nil {
+			return
+		}
+		pods[task.UID] = true
+
+		pod := task.Pod
+		if len(pod.Spec.NodeName) == 0 && len(task.NodeName) != 0 {
+			pod = pod.DeepCopy()
+			pod.Spec.NodeName = task.NodeName
+		}
+		state.Pods = append(state.Pods, pod)
+	}
+
+	for _, job := range sc.Jobs {
+		for _, task := range job.Tasks {
+			addPod(task)
+		}
+		if job.SchedSpec != nil {
+			state.SchedulingSpecs = append(state.SchedulingSpecs, job.SchedSpec)
+		}
+		if job.PDB != nil {
+			state.PodDisruptionBudgets = append(state.PodDisruptionBudgets, job.PDB)
+		}
+	}
+	for name, node := range sc.Nodes {
+		if sc.simulatedNodes[name] {
+			continue
+		}
+		for _, task := range node.Tasks {
+			addPod(task)
+		}
+	}
+
+	for _, queue := range sc.Queues {
+		if queue.Queue != nil {
+			state.Queues = append(state.Queues, queue.Queue)
+		}
+	}
+
+	return state
+}
+
+// LoadClusterState loads the cluster state in YAML or JSON from path, e.g.
+// dumped by the /debug/cluster-state endpoint of scheduler.
+func LoadClusterState(path string) (*ClusterState, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	state := &ClusterState{}
+	if err := yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(state); err != nil {
+		return nil, fmt.Errorf("failed to parse cluster state %s: %v", path, err)
+	}
+
+	return state, nil
+}
+
+// ListClusterState lists the state of live cluster by config, without any
+// writes.
+func ListClusterState(config *rest.Config) (*ClusterState, error) {
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	arbClient, err := clientset.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &ClusterState{}
+
+	nodes, err := kubeClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range nodes.Items {
+		state.Nodes = append(state.Nodes, &nodes.Items[i])
+	}
+
+	pods, err := kubeClient.CoreV1().Pods(v1.NamespaceAll).List(metav1.ListOptions{
+		FieldSelector: terminatedPodSelector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods.Items {
+		state.Pods = append(state.Pods, &pods.Items[i])
+	}
+
+	pdbs, err := kubeClient.PolicyV1beta1().PodDisruptionBudgets(v1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pdbs.Items {
+		state.PodDisruptionBudgets = append(state.PodDisruptionBudgets, &pdbs.Items[i])
+	}
+
+	specs, err := arbClient.ArbV1().SchedulingSpecs(v1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range specs.Items {
+		state.SchedulingSpecs = append(state.SchedulingSpecs, &specs.Items[i])
+	}
+
+	queues, err := arbClient.ArbV1().Queues().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range queues.Items {
+		state.Queues = append(state.Queues, &queues.Items[i])
+	}
+
+	return state, nil
+}
+
+// NewOffline creates the cache of state without API access, e.g. for
+// simulator: the binds and evictions are done by binder and evictor, and
+// the status updates and events are dropped.
+func NewOffline(schedulerName string, state *ClusterState, binder Binder, evictor Evictor) *SchedulerCache {
+	sc := &SchedulerCache{
+		Jobs:          make(map[arbapi.JobID]*arbapi.JobInfo),
+		Nodes:         make(map[string]*arbapi.NodeInfo),
+		Queues:        make(map[string]*arbapi.QueueInfo),
+		jobEvents:     make(map[arbapi.JobID]*jobEvent),
+		schedulerName: schedulerName,
+
+		Binder:        binder,
+		Evictor:       evictor,
+		StatusUpdater: &offlineStatusUpdater{},
+		Recorder:      &offlineRecorder{},
+	}
+	sc.addSimulatedNodes()
+
+	for _, node := range state.Nodes {
+		sc.AddNode(node)
+	}
+	for _, queue := range state.Queues {
+		sc.AddQueue(queue)
+	}
+	for _, ss := range state.SchedulingSpecs {
+		sc.AddSchedulingSpec(ss)
+	}
+	for _, pdb := range state.PodDisruptionBudgets {
+		sc.AddPDB(pdb)
+	}
+	for _, pod := range state.Pods {
+		if pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed && sc.cached(pod) {
+			sc.AddPod(pod)
+		}
+	}
+
+	return sc
+}
+
+type offlineStatusUpdater struct{}
+
+func (osu *offlineStatusUpdater) UpdateSchedulingSpec(ss *arbv1.SchedulingSpec) error {
+	return nil
+}
+
+func (osu *offlineStatusUpdater) UpdatePodCondition(pod *v1.Pod, condition *v1.PodCondition) error {
+	return nil
+}
+
+func (osu *offlineStatusUpdater) UpdatePodNominatedNode(pod *v1.Pod, nodeName string) error {
+	return nil
+}
+
+type offlineRecorder struct{}
+
+func (or *offlineRecorder) Eventf(ref *v1.ObjectReference, eventType, reason, messageFmt string, args ...interface{}) {
+}
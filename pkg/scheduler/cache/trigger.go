@@ -0,0 +1,55 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/metrics"
+)
+
+// The reasons of triggering a scheduling session.
+const (
+	podAddedTrigger     = "PodAdded"
+	podReleasedTrigger  = "PodReleased"
+	podResizedTrigger   = "PodResized"
+	nodeUpdatedTrigger  = "NodeUpdated"
+	jobUpdatedTrigger   = "JobUpdated"
+	queueUpdateTrigger  = "QueueUpdated"
+	bindFailedTrigger   = "BindFailed"
+	bindConflictTrigger = "BindConflict"
+)
+
+// trigger notifies that a scheduling session is needed because of reason,
+// e.g. a new job is admitted or resources are released; the notifications
+// are coalesced until the scheduler receives them.
+func (sc *SchedulerCache) trigger(reason string) {
+	if sc.triggerCh == nil {
+		return
+	}
+
+	metrics.UpdateSessionTriggers(reason)
+	select {
+	case sc.triggerCh <- struct{}{}:
+		logging.V(5).Info("Trigger scheduling session", "reason", reason)
+	default:
+	}
+}
+
+// Triggered returns the channel notified when a scheduling session is needed.
+func (sc *SchedulerCache) Triggered() <-chan struct{} {
+	return sc.triggerCh
+}
@@ -0,0 +1,375 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	storagelisters "k8s.io/client-go/listers/storage/v1"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/utils"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	arbapi "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+// The labels of zonal volumes and nodes, e.g. cloud disks.
+var zoneLabels = []string{
+	"failure-domain.beta.kubernetes.io/zone",
+	"failure-domain.beta.kubernetes.io/region",
+}
+
+// volumeBinding is a PV chosen for an unbound PVC of pod.
+type volumeBinding struct {
+	pvc *v1.PersistentVolumeClaim
+	pv  *v1.PersistentVolume
+}
+
+// defaultVolumeBinder checks the PVs of pods against nodes, the same as the
+// volume scheduling of kube-scheduler: the bound PVs must be accessible from
+// the node by their node affinity and zone, and the unbound PVCs of
+// WaitForFirstConsumer StorageClasses must have available PVs accessible from
+// the node. The chosen PVs are assumed until they're bound by PV controller.
+// The PVs are not provisioned dynamically.
+type defaultVolumeBinder struct {
+	sync.Mutex
+
+	kubeclient  kubernetes.Interface
+	pvLister    corelisters.PersistentVolumeLister
+	pvcLister   corelisters.PersistentVolumeClaimLister
+	classLister storagelisters.StorageClassLister
+
+	// assumedPVs is the key of PVC which each assumed PV is chosen for, by
+	// PV name.
+	assumedPVs map[string]string
+	// podBindings is the PVs assumed for each pod to bind, by pod key.
+	podBindings map[string][]*volumeBinding
+}
+
+func newVolumeBinder(kubeclient kubernetes.Interface, pvLister corelisters.PersistentVolumeLister,
+	pvcLister corelisters.PersistentVolumeClaimLister, classLister storagelisters.StorageClassLister) *defaultVolumeBinder {
+	return &defaultVolumeBinder{
+		kubeclient:  kubeclient,
+		pvLister:    pvLister,
+		pvcLister:   pvcLister,
+		classLister: classLister,
+		assumedPVs:  map[string]string{},
+		podBindings: map[string][]*volumeBinding{},
+	}
+}
+
+// CheckVolumeBinding returns the reasons why the volumes of pod can not be
+// bound on node, empty if they can.
+func (vb *defaultVolumeBinder) CheckVolumeBinding(pod *v1.Pod, node *v1.Node) ([]string, error) {
+	vb.Lock()
+	defer vb.Unlock()
+
+	_, reasons, err := vb.findPodVolumes(pod, node)
+	return reasons, err
+}
+
+// AssumePodVolumes chooses the PVs for the unbound PVCs of pod on node, and
+// assumes them until they're bound, so they're not chosen for other pods.
+func (vb *defaultVolumeBinder) AssumePodVolumes(pod *v1.Pod, node *v1.Node) error {
+	vb.Lock()
+	defer vb.Unlock()
+
+	bindings, reasons, err := vb.findPodVolumes(pod, node)
+	if err != nil {
+		return err
+	}
+	if len(reasons) != 0 {
+		return fmt.Errorf("volumes of pod %s can not be bound on node %s: %s",
+			arbapi.PodKey(pod), node.Name, strings.Join(reasons, ", "))
+	}
+	if len(bindings) == 0 {
+		return nil
+	}
+
+	for _, b := range bindings {
+		vb.assumedPVs[b.pv.Name] = pvcKey(b.pvc)
+	}
+	vb.podBindings[string(arbapi.PodKey(pod))] = bindings
+
+	return nil
+}
+
+// BindPodVolumes binds the PVs assumed for pod to its PVCs, by setting the
+// claimRef of PVs; the PVCs are bound by PV controller later.
+func (vb *defaultVolumeBinder) BindPodVolumes(pod *v1.Pod) error {
+	vb.Lock()
+	bindings := vb.podBindings[string(arbapi.PodKey(pod))]
+	delete(vb.podBindings, string(arbapi.PodKey(pod)))
+	vb.Unlock()
+
+	for i, b := range bindings {
+		pv := b.pv.DeepCopy()
+		pv.Spec.ClaimRef = &v1.ObjectReference{
+			Kind:            "PersistentVolumeClaim",
+			APIVersion:      "v1",
+			Namespace:       b.pvc.Namespace,
+			Name:            b.pvc.Name,
+			UID:             b.pvc.UID,
+			ResourceVersion: b.pvc.ResourceVersion,
+		}
+
+		if _, err := vb.kubeclient.CoreV1().PersistentVolumes().Update(pv); err != nil {
+			logging.Error(err, "Failed to bind volume of pod", "pod", arbapi.PodKey(pod),
+				"pvc", pvcKey(b.pvc), "pv", pv.Name)
+			vb.forget(bindings[i:])
+			return err
+		}
+		logging.V(3).Info("Bind volume of pod", "pod", arbapi.PodKey(pod), "pvc", pvcKey(b.pvc), "pv", pv.Name)
+	}
+
+	return nil
+}
+
+// ForgetPodVolumes forgets the PVs assumed for pod, e.g. when its bind is
+// cancelled.
+func (vb *defaultVolumeBinder) ForgetPodVolumes(pod *v1.Pod) {
+	vb.Lock()
+	bindings := vb.podBindings[string(arbapi.PodKey(pod))]
+	delete(vb.podBindings, string(arbapi.PodKey(pod)))
+	vb.Unlock()
+
+	vb.forget(bindings)
+}
+
+func (vb *defaultVolumeBinder) forget(bindings []*volumeBinding) {
+	vb.Lock()
+	defer vb.Unlock()
+
+	for _, b := range bindings {
+		delete(vb.assumedPVs, b.pv.Name)
+	}
+}
+
+// findPodVolumes returns the PVs chosen for the unbound PVCs of pod on node,
+// or the reasons why its volumes can not be bound on node.
+// Assumes that lock is already acquired.
+func (vb *defaultVolumeBinder) findPodVolumes(pod *v1.Pod, node *v1.Node) ([]*volumeBinding, []string, error) {
+	var bindings []*volumeBinding
+	var reasons []string
+
+	// The PVs chosen for the PVCs of the same pod.
+	chosen := map[string]bool{}
+
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim == nil {
+			continue
+		}
+
+		pvc, err := vb.pvcLister.PersistentVolumeClaims(pod.Namespace).Get(vol.PersistentVolumeClaim.ClaimName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get PersistentVolumeClaim %s/%s: %v",
+				pod.Namespace, vol.PersistentVolumeClaim.ClaimName, err)
+		}
+
+		if len(pvc.Spec.VolumeName) != 0 {
+			pv, err := vb.pvLister.Get(pvc.Spec.VolumeName)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get PersistentVolume %s of claim %s: %v",
+					pvc.Spec.VolumeName, pvcKey(pvc), err)
+			}
+			if !volumeNodeAffinityMatches(pv, node) {
+				reasons = append(reasons, arbapi.VolumeNodeAffinityConflict)
+			} else if !volumeZoneMatches(pv, node) {
+				reasons = append(reasons, arbapi.VolumeZoneConflict)
+			}
+			continue
+		}
+
+		if !vb.isDelayedBinding(pvc) {
+			return nil, nil, fmt.Errorf("PersistentVolumeClaim %s is not bound", pvcKey(pvc))
+		}
+
+		pv, err := vb.findMatchingVolume(pvc, node, chosen)
+		if err != nil {
+			return nil, nil, err
+		}
+		if pv == nil {
+			reasons = append(reasons, arbapi.VolumeBindConflict)
+			continue
+		}
+
+		chosen[pv.Name] = true
+		bindings = append(bindings, &volumeBinding{pvc: pvc, pv: pv})
+	}
+
+	return bindings, reasons, nil
+}
+
+// isDelayedBinding returns whether pvc is bound when its pod is scheduled,
+// by the WaitForFirstConsumer binding mode of its StorageClass.
+func (vb *defaultVolumeBinder) isDelayedBinding(pvc *v1.PersistentVolumeClaim) bool {
+	className := claimClassName(pvc)
+	if len(className) == 0 {
+		return false
+	}
+
+	class, err := vb.classLister.Get(className)
+	if err != nil || class.VolumeBindingMode == nil {
+		return false
+	}
+
+	return *class.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer
+}
+
+// findMatchingVolume returns the smallest available PV for pvc on node which
+// is neither assumed nor chosen, nil if not found.
+// Assumes that lock is already acquired.
+func (vb *defaultVolumeBinder) findMatchingVolume(pvc *v1.PersistentVolumeClaim, node *v1.Node,
+	chosen map[string]bool) (*v1.PersistentVolume, error) {
+	pvs, err := vb.pvLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	var selector labels.Selector
+	if pvc.Spec.Selector != nil {
+		if selector, err = metav1.LabelSelectorAsSelector(pvc.Spec.Selector); err != nil {
+			return nil, fmt.Errorf("invalid selector of PersistentVolumeClaim %s: %v", pvcKey(pvc), err)
+		}
+	}
+
+	request := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+	var smallest *v1.PersistentVolume
+	for _, pv := range pvs {
+		if key, found := vb.assumedPVs[pv.Name]; found {
+			// The assumed PV is released once it's bound by PV controller.
+			if pv.Spec.ClaimRef != nil {
+				delete(vb.assumedPVs, pv.Name)
+			}
+			if key != pvcKey(pvc) {
+				continue
+			}
+		}
+		if chosen[pv.Name] || pv.Spec.StorageClassName != claimClassName(pvc) {
+			continue
+		}
+		// The PV is available, or pre-bound to pvc.
+		if ref := pv.Spec.ClaimRef; ref != nil &&
+			(ref.Namespace != pvc.Namespace || ref.Name != pvc.Name || (len(ref.UID) != 0 && ref.UID != pvc.UID)) {
+			continue
+		}
+		if pv.Spec.ClaimRef == nil && pv.Status.Phase != v1.VolumeAvailable {
+			continue
+		}
+
+		capacity := pv.Spec.Capacity[v1.ResourceStorage]
+		if capacity.Cmp(request) < 0 || !accessModesContained(pv.Spec.AccessModes, pvc.Spec.AccessModes) {
+			continue
+		}
+		if selector != nil && !selector.Matches(labels.Set(pv.Labels)) {
+			continue
+		}
+		if !volumeNodeAffinityMatches(pv, node) || !volumeZoneMatches(pv, node) {
+			continue
+		}
+
+		if smallest == nil {
+			smallest = pv
+			continue
+		}
+		smallestCapacity := smallest.Spec.Capacity[v1.ResourceStorage]
+		if capacity.Cmp(smallestCapacity) < 0 {
+			smallest = pv
+		}
+	}
+
+	return smallest, nil
+}
+
+func pvcKey(pvc *v1.PersistentVolumeClaim) string {
+	return pvc.Namespace + "/" + pvc.Name
+}
+
+// claimClassName returns the StorageClass name of pvc, by its field or the
+// beta annotation.
+func claimClassName(pvc *v1.PersistentVolumeClaim) string {
+	if class, found := pvc.Annotations[v1.BetaStorageClassAnnotation]; found {
+		return class
+	}
+	if pvc.Spec.StorageClassName != nil {
+		return *pvc.Spec.StorageClassName
+	}
+	return ""
+}
+
+func accessModesContained(modes, requested []v1.PersistentVolumeAccessMode) bool {
+	for _, r := range requested {
+		found := false
+		for _, m := range modes {
+			if m == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// volumeNodeAffinityMatches returns whether node matches the node affinity of
+// pv, e.g. the node of a local PV.
+func volumeNodeAffinityMatches(pv *v1.PersistentVolume, node *v1.Node) bool {
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return true
+	}
+
+	// The terms are ORed, and the requirements of a term are ANDed.
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		if utils.MatchNodeSelectorTerm(term, node.Labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// volumeZoneMatches returns whether node is in the zone and region of pv,
+// e.g. a cloud disk; the zone label may have multiple zones joined by "__".
+func volumeZoneMatches(pv *v1.PersistentVolume, node *v1.Node) bool {
+	for _, label := range zoneLabels {
+		value, found := pv.Labels[label]
+		if !found {
+			continue
+		}
+
+		nodeValue := node.Labels[label]
+		matched := false
+		for _, v := range strings.Split(value, "__") {
+			if v == nodeValue {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	storagelisters "k8s.io/client-go/listers/storage/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+func buildLocalPV(name, nodeName, class string) *v1.PersistentVolume {
+	return &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1.PersistentVolumeSpec{
+			Capacity:         v1.ResourceList{v1.ResourceStorage: resource.MustParse("10Gi")},
+			AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			StorageClassName: class,
+			NodeAffinity: &v1.VolumeNodeAffinity{
+				Required: &v1.NodeSelector{
+					NodeSelectorTerms: []v1.NodeSelectorTerm{{
+						MatchExpressions: []v1.NodeSelectorRequirement{{
+							Key:      "kubernetes.io/hostname",
+							Operator: v1.NodeSelectorOpIn,
+							Values:   []string{nodeName},
+						}},
+					}},
+				},
+			},
+		},
+		Status: v1.PersistentVolumeStatus{Phase: v1.VolumeAvailable},
+	}
+}
+
+func buildPVC(name, class, volumeName string) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "c1", Name: name},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			StorageClassName: &class,
+			VolumeName:       volumeName,
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("5Gi")},
+			},
+		},
+	}
+}
+
+func buildVolumePod(name string, claims ...string) *v1.Pod {
+	pod := buildPod("c1", name, "", v1.PodPending, buildResourceList("1", "1G"), nil, nil)
+	for _, claim := range claims {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+			Name: claim,
+			VolumeSource: v1.VolumeSource{
+				PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+			},
+		})
+	}
+	return pod
+}
+
+func buildHostNode(name string) *v1.Node {
+	node := buildNode(name, buildResourceList("4", "10G"))
+	node.Labels = map[string]string{"kubernetes.io/hostname": name}
+	return node
+}
+
+func TestVolumeBinder(t *testing.T) {
+	delayed := storagev1.VolumeBindingWaitForFirstConsumer
+
+	pvIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	classIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+
+	classIndexer.Add(&storagev1.StorageClass{
+		ObjectMeta:        metav1.ObjectMeta{Name: "local"},
+		VolumeBindingMode: &delayed,
+	})
+	// pv1 is bound to claim1, and pv2 is available; both are local PVs of n1.
+	pv1 := buildLocalPV("pv1", "n1", "local")
+	pv1.Status.Phase = v1.VolumeBound
+	pvIndexer.Add(pv1)
+	pvIndexer.Add(buildLocalPV("pv2", "n1", "local"))
+	pvcIndexer.Add(buildPVC("claim1", "local", "pv1"))
+	pvcIndexer.Add(buildPVC("claim2", "local", ""))
+	pvcIndexer.Add(buildPVC("claim3", "local", ""))
+
+	vb := newVolumeBinder(nil, corelisters.NewPersistentVolumeLister(pvIndexer),
+		corelisters.NewPersistentVolumeClaimLister(pvcIndexer), storagelisters.NewStorageClassLister(classIndexer))
+
+	n1, n2 := buildHostNode("n1"), buildHostNode("n2")
+
+	bound := buildVolumePod("p1", "claim1")
+	if reasons, err := vb.CheckVolumeBinding(bound, n1); err != nil || len(reasons) != 0 {
+		t.Errorf("expected bound local PV accessible from n1, got %v, %v", reasons, err)
+	}
+	if reasons, _ := vb.CheckVolumeBinding(bound, n2); !reflect.DeepEqual(reasons, []string{api.VolumeNodeAffinityConflict}) {
+		t.Errorf("expected volume node affinity conflict on n2, got %v", reasons)
+	}
+
+	unbound := buildVolumePod("p2", "claim2")
+	if reasons, _ := vb.CheckVolumeBinding(unbound, n2); !reflect.DeepEqual(reasons, []string{api.VolumeBindConflict}) {
+		t.Errorf("expected no available PV on n2, got %v", reasons)
+	}
+	if err := vb.AssumePodVolumes(unbound, n1); err != nil {
+		t.Fatalf("failed to assume volumes of pod on n1: %v", err)
+	}
+
+	// The PV assumed for claim2 is not chosen for claim3.
+	other := buildVolumePod("p3", "claim3")
+	if reasons, _ := vb.CheckVolumeBinding(other, n1); !reflect.DeepEqual(reasons, []string{api.VolumeBindConflict}) {
+		t.Errorf("expected the assumed PV not available to other pods, got %v", reasons)
+	}
+
+	vb.ForgetPodVolumes(unbound)
+	if reasons, err := vb.CheckVolumeBinding(other, n1); err != nil || len(reasons) != 0 {
+		t.Errorf("expected the forgotten PV available to other pods, got %v, %v", reasons, err)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"runtime"
+	"time"
+
+	"golang.org/x/net/context"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	kuberuntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/pager"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+)
+
+// ListPageSize is the maximum number of pods in a page of the initial list,
+// so a large cluster is listed in chunks instead of one huge response; 0
+// lists all pods at once.
+var ListPageSize int64 = 500
+
+// terminatedPodSelector excludes the terminated pods, which never occupy
+// resources, so they're neither listed nor kept in the informer.
+var terminatedPodSelector = fields.ParseSelectorOrDie(
+	"status.phase!=" + string(v1.PodSucceeded) + ",status.phase!=" + string(v1.PodFailed))
+
+// newPodInformer creates the informer of non-terminated pods, whose initial
+// list is paginated by ListPageSize.
+func newPodInformer(client kubernetes.Interface) cache.SharedIndexInformer {
+	list := func(options metav1.ListOptions) (kuberuntime.Object, error) {
+		options.FieldSelector = terminatedPodSelector.String()
+		return client.CoreV1().Pods(v1.NamespaceAll).List(options)
+	}
+
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (kuberuntime.Object, error) {
+				if ListPageSize <= 0 {
+					return list(options)
+				}
+
+				// The list served from the watch cache of apiserver, i.e.
+				// resourceVersion "0", is never paginated.
+				options.ResourceVersion = ""
+				p := pager.New(pager.SimplePageFunc(list))
+				p.PageSize = ListPageSize
+				return p.List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				options.FieldSelector = terminatedPodSelector.String()
+				return client.CoreV1().Pods(v1.NamespaceAll).Watch(options)
+			},
+		},
+		&v1.Pod{},
+		ResyncPeriod,
+		cache.Indexers{},
+	)
+}
+
+// runStaged runs the informers in stages: the nodes, SchedulingSpecs, PDBs
+// and queues are synced before the pods are listed, so the pods are added to
+// the known nodes and jobs instead of creating placeholders for them.
+func (sc *SchedulerCache) runStaged(stopCh <-chan struct{}) {
+	start := time.Now()
+
+	go sc.pdbInformer.Informer().Run(stopCh)
+	go sc.pvInformer.Informer().Run(stopCh)
+	go sc.pvcInformer.Informer().Run(stopCh)
+	go sc.storageClassInformer.Informer().Run(stopCh)
+	go sc.nodeInformer.Informer().Run(stopCh)
+	go sc.schedulingSpecInformer.Informer().Run(stopCh)
+	go sc.queueInformer.Informer().Run(stopCh)
+
+	if !cache.WaitForCacheSync(stopCh,
+		sc.pdbInformer.Informer().HasSynced,
+		sc.pvInformer.Informer().HasSynced,
+		sc.pvcInformer.Informer().HasSynced,
+		sc.storageClassInformer.Informer().HasSynced,
+		sc.nodeInformer.Informer().HasSynced,
+		sc.schedulingSpecInformer.Informer().HasSynced,
+		sc.queueInformer.Informer().HasSynced) {
+		return
+	}
+	logging.Info("Synced nodes and jobs, start listing pods",
+		"duration", time.Since(start), "pageSize", ListPageSize)
+
+	go sc.podInformer.Run(stopCh)
+
+	if !cache.WaitForCacheSync(stopCh, sc.podInformer.HasSynced) {
+		return
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	logging.Info("Cache warmed up", "duration", time.Since(start),
+		"nodes", len(sc.Nodes), "jobs", len(sc.Jobs), "queues", len(sc.Queues),
+		"heapBytes", mem.HeapAlloc)
+}
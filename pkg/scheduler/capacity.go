@@ -0,0 +1,57 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+// recordCapacity keeps the capacity of cluster after the decisions of ssn.
+func (pc *Scheduler) recordCapacity(ssn *framework.Session) {
+	capacity := api.NewClusterCapacity(ssn.Nodes, ssn.Jobs)
+
+	pc.debugMutex.Lock()
+	defer pc.debugMutex.Unlock()
+
+	pc.capacity = capacity
+}
+
+// CapacityHandler returns the HTTP handler which reports the capacity of
+// cluster in the last session, e.g. to the federation controller; it's
+// unavailable until the first session completes.
+func (pc *Scheduler) CapacityHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pc.debugMutex.Lock()
+		capacity := pc.capacity
+		pc.debugMutex.Unlock()
+
+		if capacity == nil {
+			http.Error(w, "no session completed", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(capacity); err != nil {
+			logging.Error(err, "Failed to write cluster capacity")
+		}
+	})
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+var (
+	// ConfigFile is the scheduler config file, which is reloaded when it's
+	// changed, e.g. a mounted ConfigMap; all actions and plugins are used if
+	// it's empty.
+	ConfigFile string
+	// ConfigReloadPeriod is the interval between two checks of ConfigFile.
+	ConfigReloadPeriod = 10 * time.Second
+)
+
+// Config is the actions and plugins of scheduler in YAML or JSON, e.g.
+//
+//	actions: [decorate, garantee, allocate, preempt]
+//	disabledPlugins: [usage]
+type Config struct {
+	// Actions is the names of the actions executed in order; all actions if
+	// empty.
+	Actions []string `json:"actions,omitempty"`
+	// DisabledPlugins is the names of the plugins not opened in sessions.
+	DisabledPlugins []string `json:"disabledPlugins,omitempty"`
+}
+
+// LoadConfig loads the scheduler config from path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := parseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse scheduler config %s: %v", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scheduler config %s: %v", path, err)
+	}
+	return config, nil
+}
+
+func parseConfig(data []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096).Decode(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate returns an error if any action or plugin of config is unknown.
+func (c *Config) Validate() error {
+	if _, err := c.BuildActions(); err != nil {
+		return err
+	}
+
+	plugins := map[string]bool{}
+	for _, name := range framework.PluginNames() {
+		plugins[name] = true
+	}
+	for _, name := range c.DisabledPlugins {
+		if !plugins[name] {
+			return fmt.Errorf("unknown plugin %q", name)
+		}
+	}
+
+	return nil
+}
+
+// BuildActions returns the actions of config by name.
+func (c *Config) BuildActions() ([]framework.Action, error) {
+	if len(c.Actions) == 0 {
+		return Actions, nil
+	}
+
+	byName := map[string]framework.Action{}
+	for _, action := range allActions {
+		byName[action.Name()] = action
+	}
+
+	var actions []framework.Action
+	for _, name := range c.Actions {
+		action, found := byName[name]
+		if !found {
+			return nil, fmt.Errorf("unknown action %q", name)
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// DisabledPluginSet returns the names of disabled plugins as a set.
+func (c *Config) DisabledPluginSet() map[string]bool {
+	disabled := map[string]bool{}
+	for _, name := range c.DisabledPlugins {
+		disabled[name] = true
+	}
+	return disabled
+}
+
+// policy is the actions and disabled plugins built from a config.
+type policy struct {
+	actions         []framework.Action
+	disabledPlugins map[string]bool
+}
+
+// newPolicy builds the policy of config.
+func newPolicy(config *Config) (*policy, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	actions, err := config.BuildActions()
+	if err != nil {
+		return nil, err
+	}
+	return &policy{actions: actions, disabledPlugins: config.DisabledPluginSet()}, nil
+}
+
+// loadPolicy builds the policy of the config in data.
+func loadPolicy(data []byte) (*policy, error) {
+	config, err := parseConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	return newPolicy(config)
+}
+
+// reloadConfig loads ConfigFile if it's changed since last loaded; the new
+// policy is applied at the next session. The invalid config is logged and
+// ignored, so the scheduler keeps the last valid policy.
+func (pc *Scheduler) reloadConfig() {
+	data, err := ioutil.ReadFile(ConfigFile)
+	if err != nil {
+		logging.Error(err, "Failed to read scheduler config", "file", ConfigFile)
+		return
+	}
+
+	pc.configMutex.Lock()
+	defer pc.configMutex.Unlock()
+
+	if bytes.Equal(data, pc.configData) {
+		return
+	}
+	pc.configData = data
+
+	p, err := loadPolicy(data)
+	if err != nil {
+		logging.Error(err, "Invalid scheduler config, keep the current policy", "file", ConfigFile)
+		return
+	}
+
+	logging.Info("Scheduler config changed, apply it at the next session", "file", ConfigFile)
+	pc.nextPolicy = p
+}
+
+// sessionActions applies the policy reloaded since the last session, and
+// returns the actions of this session; it's only called between sessions.
+func (pc *Scheduler) sessionActions() []framework.Action {
+	pc.configMutex.Lock()
+	defer pc.configMutex.Unlock()
+
+	if next := pc.nextPolicy; next != nil {
+		pc.policy = next
+		pc.nextPolicy = nil
+		framework.DisabledPlugins = next.disabledPlugins
+	}
+
+	if pc.policy == nil {
+		return Actions
+	}
+	return pc.policy.actions
+}
+
+// loadConfigFile loads the policy of ConfigFile when scheduler starts.
+func (pc *Scheduler) loadConfigFile() error {
+	data, err := ioutil.ReadFile(ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	p, err := loadPolicy(data)
+	if err != nil {
+		return fmt.Errorf("invalid scheduler config %s: %v", ConfigFile, err)
+	}
+
+	pc.configMutex.Lock()
+	defer pc.configMutex.Unlock()
+
+	pc.configData = data
+	pc.nextPolicy = p
+	return nil
+}
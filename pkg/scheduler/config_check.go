@@ -0,0 +1,197 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+// ConfigError is a problem of a field in the scheduler config.
+type ConfigError struct {
+	// Field is the path of the field, e.g. actions[2]; empty for the whole
+	// config.
+	Field   string
+	Message string
+}
+
+func (e *ConfigError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// configFields is the fields of Config by their names in YAML or JSON.
+var configFields = []string{"actions", "disabledPlugins"}
+
+// CheckConfig returns all the problems of the scheduler config in data,
+// including the ones LoadConfig does not report: the unknown fields, e.g. a
+// misspelled field ignored silently, the values of wrong types and the names
+// listed twice. Each error suggests the closest known name if any.
+func CheckConfig(data []byte) []*ConfigError {
+	doc, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return []*ConfigError{{Message: fmt.Sprintf("invalid YAML or JSON: %v", err)}}
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		return []*ConfigError{{Message: "config must be a map of actions and disabledPlugins"}}
+	}
+
+	actions := map[string]bool{}
+	for _, action := range allActions {
+		actions[action.Name()] = true
+	}
+	plugins := map[string]bool{}
+	for _, name := range framework.PluginNames() {
+		plugins[name] = true
+	}
+
+	var errs []*ConfigError
+	for _, field := range sortedKeys(fields) {
+		switch field {
+		case "actions":
+			errs = append(errs, checkNames(field, fields[field], "action", actions)...)
+		case "disabledPlugins":
+			errs = append(errs, checkNames(field, fields[field], "plugin", plugins)...)
+		default:
+			errs = append(errs, &ConfigError{
+				Field:   field,
+				Message: "unknown field, it's ignored" + suggest(field, configFields),
+			})
+		}
+	}
+	return errs
+}
+
+// checkNames checks the value of field is a list of the known names of kind.
+func checkNames(field string, value interface{}, kind string, known map[string]bool) []*ConfigError {
+	if value == nil {
+		return nil
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return []*ConfigError{{Field: field, Message: fmt.Sprintf("must be a list of %s names, got %s", kind, typeName(value))}}
+	}
+
+	knownNames := make([]string, 0, len(known))
+	for name := range known {
+		knownNames = append(knownNames, name)
+	}
+	sort.Strings(knownNames)
+
+	var errs []*ConfigError
+	listed := map[string]int{}
+	for i, item := range list {
+		path := fmt.Sprintf("%s[%d]", field, i)
+		name, ok := item.(string)
+		if !ok {
+			errs = append(errs, &ConfigError{Field: path, Message: fmt.Sprintf("must be a string, got %s", typeName(item))})
+			continue
+		}
+		if !known[name] {
+			errs = append(errs, &ConfigError{
+				Field: path,
+				Message: fmt.Sprintf("unknown %s %q%s (known %ss: %s)",
+					kind, name, suggest(name, knownNames), kind, strings.Join(knownNames, ", ")),
+			})
+			continue
+		}
+		if j, found := listed[name]; found {
+			errs = append(errs, &ConfigError{Field: path, Message: fmt.Sprintf("%s %q is already listed at %s[%d]", kind, name, field, j)})
+			continue
+		}
+		listed[name] = i
+	}
+	return errs
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// typeName returns the type of a value decoded from JSON.
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	}
+	return "null"
+}
+
+// suggest returns a hint of the known name closest to name, if any is close
+// enough to be a typo of it.
+func suggest(name string, known []string) string {
+	best, bestDistance := "", len(name)/2+1
+	for _, candidate := range known {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf("; did you mean %q?", best)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < cur[j] {
+				cur[j] = d
+			}
+			if d := cur[j-1] + 1; d < cur[j] {
+				cur[j] = d
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		config string
+		valid  bool
+	}{
+		{config: "actions: [allocate, preempt]\ndisabledPlugins: [usage]", valid: true},
+		{config: "{}", valid: true},
+		{config: "actions: [allocate, unknown]", valid: false},
+		{config: "disabledPlugins: [unknown]", valid: false},
+	}
+
+	for _, test := range tests {
+		if _, err := loadPolicy([]byte(test.config)); (err == nil) != test.valid {
+			t.Errorf("expected valid %v of config %q, got error %v", test.valid, test.config, err)
+		}
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	tests := []struct {
+		config string
+		fields []string
+	}{
+		{config: "actions: [allocate, preempt]\ndisabledPlugins: [usage]"},
+		{config: "{}"},
+		{config: "actions: [alocate, allocate, allocate]", fields: []string{"actions[0]", "actions[2]"}},
+		{config: "actions: allocate\ndisabledPlugins: [1]", fields: []string{"actions", "disabledPlugins[0]"}},
+		{config: "disabledPlugin: [usage]", fields: []string{"disabledPlugin"}},
+		{config: "[allocate]", fields: []string{""}},
+	}
+
+	for _, test := range tests {
+		errs := CheckConfig([]byte(test.config))
+		var fields []string
+		for _, err := range errs {
+			fields = append(fields, err.Field)
+		}
+		if !reflect.DeepEqual(fields, test.fields) {
+			t.Errorf("expected problems of %v in config %q, got %v", test.fields, test.config, errs)
+		}
+	}
+
+	if errs := CheckConfig([]byte("actions: [alocate]")); len(errs) != 1 || !strings.Contains(errs[0].Message, `did you mean "allocate"`) {
+		t.Errorf("expected allocate suggested, got %v", errs)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scheduler-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(file string) { ConfigFile = file }(ConfigFile)
+	defer func(disabled map[string]bool) { framework.DisabledPlugins = disabled }(framework.DisabledPlugins)
+
+	ConfigFile = filepath.Join(dir, "config.yaml")
+	write := func(config string) {
+		if err := ioutil.WriteFile(ConfigFile, []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("actions: [allocate]\ndisabledPlugins: [usage]")
+	pc := &Scheduler{}
+	if err := pc.loadConfigFile(); err != nil {
+		t.Fatal(err)
+	}
+	if actions := pc.sessionActions(); len(actions) != 1 || actions[0].Name() != "allocate" {
+		t.Errorf("expected allocate only, got %v", actions)
+	}
+	if !framework.DisabledPlugins["usage"] {
+		t.Errorf("expected usage disabled, got %v", framework.DisabledPlugins)
+	}
+
+	// The invalid config is ignored.
+	write("actions: [unknown]")
+	pc.reloadConfig()
+	if actions := pc.sessionActions(); len(actions) != 1 {
+		t.Errorf("expected the last valid policy kept, got %v", actions)
+	}
+
+	// The new config is applied at the next session.
+	write("actions: [allocate, preempt]")
+	pc.reloadConfig()
+	if actions := pc.sessionActions(); len(actions) != 2 || actions[1].Name() != "preempt" {
+		t.Errorf("expected allocate and preempt, got %v", actions)
+	}
+	if framework.DisabledPlugins["usage"] {
+		t.Errorf("expected no plugin disabled, got %v", framework.DisabledPlugins)
+	}
+}
+
+func TestConfigz(t *testing.T) {
+	p, err := loadPolicy([]byte("actions: [allocate, preempt]\ndisabledPlugins: [usage]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc := &Scheduler{policy: p}
+
+	c := pc.configz(map[string]string{"enable-binpack": "true"})
+	if len(c.Actions) != 2 || c.Actions[0] != "allocate" || c.Actions[1] != "preempt" {
+		t.Errorf("expected actions allocate and preempt, got %v", c.Actions)
+	}
+	if len(c.DisabledPlugins) != 1 || c.DisabledPlugins[0] != "usage" {
+		t.Errorf("expected usage disabled, got %v", c.DisabledPlugins)
+	}
+	for _, name := range c.Plugins {
+		if name == "usage" {
+			t.Errorf("expected usage not in enabled plugins %v", c.Plugins)
+		}
+	}
+	if len(c.Plugins)+len(c.DisabledPlugins) != len(framework.PluginNames()) {
+		t.Errorf("expected all plugins reported, got %v and %v", c.Plugins, c.DisabledPlugins)
+	}
+	if c.Flags["enable-binpack"] != "true" || len(c.Version.GitVersion) == 0 {
+		t.Errorf("expected flags and version reported, got %+v", c)
+	}
+}
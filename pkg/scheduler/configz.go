@@ -0,0 +1,92 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/version"
+)
+
+// Configz is the version and the policy which scheduler is running, e.g. for
+// support to tell which policy a cluster runs.
+type Configz struct {
+	Version version.Info `json:"version"`
+	// ConfigFile is the scheduler config file, empty if not set.
+	ConfigFile string `json:"configFile,omitempty"`
+	// Actions is the names of the actions executed in order by sessions.
+	Actions []string `json:"actions"`
+	// Plugins is the names of the plugins opened in sessions.
+	Plugins []string `json:"plugins"`
+	// DisabledPlugins is the names of the plugins not opened in sessions.
+	DisabledPlugins []string `json:"disabledPlugins"`
+	// Flags is the command line flags of scheduler by name, including the
+	// arguments of plugins, e.g. enable-binpack.
+	Flags map[string]string `json:"flags"`
+}
+
+// configz returns the policy applied by the last session, which is also
+// used by the next one unless the config is reloaded.
+func (pc *Scheduler) configz(flags map[string]string) *Configz {
+	pc.configMutex.Lock()
+	actions := Actions
+	disabled := framework.DisabledPlugins
+	if pc.policy != nil {
+		actions = pc.policy.actions
+		disabled = pc.policy.disabledPlugins
+	}
+	pc.configMutex.Unlock()
+
+	c := &Configz{
+		Version:         version.Get(),
+		ConfigFile:      ConfigFile,
+		Actions:         []string{},
+		Plugins:         []string{},
+		DisabledPlugins: []string{},
+		Flags:           flags,
+	}
+
+	for _, action := range actions {
+		c.Actions = append(c.Actions, action.Name())
+	}
+	for _, name := range framework.PluginNames() {
+		if disabled[name] {
+			c.DisabledPlugins = append(c.DisabledPlugins, name)
+		} else {
+			c.Plugins = append(c.Plugins, name)
+		}
+	}
+	sort.Strings(c.Plugins)
+	sort.Strings(c.DisabledPlugins)
+
+	return c
+}
+
+// ConfigzHandler returns the HTTP handler which reports the version, the
+// actions and plugins of sessions, and flags of scheduler.
+func (pc *Scheduler) ConfigzHandler(flags map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(pc.configz(flags)); err != nil {
+			logging.Error(err, "Failed to write configz")
+		}
+	})
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+// unschedulableJob is the job which can not be scheduled in the last session.
+type unschedulableJob struct {
+	UID       string `json:"uid"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Task      string `json:"task,omitempty"`
+	Message   string `json:"message"`
+
+	// The reasons of each node which can not fit the task, by node name.
+	FailedNodes map[string][]string `json:"failedNodes"`
+}
+
+// recordUnschedulable keeps the unschedulable jobs of ssn for debugging.
+func (pc *Scheduler) recordUnschedulable(ssn *framework.Session) {
+	jobs := make([]*unschedulableJob, 0, len(ssn.FitErrors))
+	for uid, fitErrors := range ssn.FitErrors {
+		job := &unschedulableJob{
+			UID:         string(uid),
+			Message:     fitErrors.Error(),
+			FailedNodes: fitErrors.FailedNodes,
+		}
+		if info, found := ssn.JobIndex[uid]; found {
+			job.Namespace = info.Namespace
+			job.Name = info.Name
+		}
+		if fitErrors.Task != nil {
+			job.Task = fitErrors.Task.Name
+		}
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].UID < jobs[j].UID
+	})
+
+	pc.debugMutex.Lock()
+	defer pc.debugMutex.Unlock()
+
+	pc.unschedulable = jobs
+}
+
+// UnschedulableHandler returns the HTTP handler which shows the jobs can not
+// be scheduled in the last session, and why each node can not fit them.
+func (pc *Scheduler) UnschedulableHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pc.debugMutex.Lock()
+		jobs := pc.unschedulable
+		pc.debugMutex.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(jobs); err != nil {
+			logging.Error(err, "Failed to write unschedulable jobs")
+		}
+	})
+}
+
+// ClusterStateHandler returns the HTTP handler which dumps the objects in
+// cache, e.g. to replay them by simulator.
+func (pc *Scheduler) ClusterStateHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := pc.cache.State()
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(state); err != nil {
+			logging.Error(err, "Failed to write cluster state")
+		}
+	})
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+// maxDefragJobs is the maximal number of unschedulable jobs analyzed in a
+// session, as the analysis filters nodes for the tasks to move.
+const maxDefragJobs = 10
+
+// taskMove is a running task to re-place from a node to another.
+type taskMove struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+
+	uid api.TaskID
+}
+
+// defragHint is the re-placements which make an unschedulable job fit.
+type defragHint struct {
+	UID       string `json:"uid"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	// Fits is whether the job fits after Moves; if not, Message is why.
+	Fits    bool        `json:"fits"`
+	Moves   []*taskMove `json:"moves"`
+	Message string      `json:"message,omitempty"`
+}
+
+// defragmenter places the pending tasks of jobs on the idle resources of
+// nodes, moving the running tasks of other jobs if they don't fit; nothing in
+// session is changed.
+type defragmenter struct {
+	ssn  *framework.Session
+	idle map[string]*api.Resource
+	// moved is the tasks moved by the previous hints.
+	moved map[api.TaskID]bool
+	// feasible is the nodes passed the filters for each task.
+	feasible map[api.TaskID][]*api.NodeInfo
+}
+
+func newDefragmenter(ssn *framework.Session) *defragmenter {
+	d := &defragmenter{
+		ssn:      ssn,
+		idle:     make(map[string]*api.Resource, len(ssn.Nodes)),
+		moved:    map[api.TaskID]bool{},
+		feasible: map[api.TaskID][]*api.NodeInfo{},
+	}
+	for _, node := range ssn.Nodes {
+		d.idle[node.Name] = node.Idle.Clone()
+	}
+	return d
+}
+
+// feasibleNodes returns the nodes passed the filters for task, other than
+// the resources.
+func (d *defragmenter) feasibleNodes(task *api.TaskInfo) []*api.NodeInfo {
+	if nodes, found := d.feasible[task.UID]; found {
+		return nodes
+	}
+	nodes := d.ssn.FilterNodes(task, d.ssn.Nodes, api.NewFitErrors(task, len(d.ssn.Nodes)))
+	d.feasible[task.UID] = nodes
+	return nodes
+}
+
+// movable returns the tasks on node which may be moved for job, the largest
+// first, so the fewest tasks are moved. The tasks of implicit jobs are not
+// moved, as nothing re-creates their pods.
+func (d *defragmenter) movable(node *api.NodeInfo, job *api.JobInfo) []*api.TaskInfo {
+	var tasks []*api.TaskInfo
+	for _, task := range node.Tasks {
+		if task.Job == job.UID || task.Status != api.Running || d.moved[task.UID] || task.Resreq.IsEmpty() {
+			continue
+		}
+		if owner, found := d.ssn.JobIndex[task.Job]; !found || owner.Implicit {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		l, r := tasks[i].Resreq, tasks[j].Resreq
+		if l.GPU != r.GPU {
+			return l.GPU > r.GPU
+		}
+		if l.MilliCPU != r.MilliCPU {
+			return l.MilliCPU > r.MilliCPU
+		}
+		if l.Memory != r.Memory {
+			return l.Memory > r.Memory
+		}
+		return tasks[i].UID < tasks[j].UID
+	})
+	return tasks
+}
+
+// makeRoom returns the moves which free node for task, and the idle
+// resources of the nodes changed by them; it returns false if task does not
+// fit on node by moving the other tasks.
+func (d *defragmenter) makeRoom(task *api.TaskInfo, node *api.NodeInfo, job *api.JobInfo) ([]*taskMove, map[string]*api.Resource, bool) {
+	changed := map[string]*api.Resource{node.Name: d.idle[node.Name].Clone()}
+	idleOf := func(name string) *api.Resource {
+		if idle, found := changed[name]; found {
+			return idle
+		}
+		return d.idle[name]
+	}
+
+	var moves []*taskMove
+	for _, victim := range d.movable(node, job) {
+		if task.Resreq.LessEqual(changed[node.Name]) {
+			break
+		}
+		for _, target := range d.feasibleNodes(victim) {
+			if target.Name == node.Name || !victim.Resreq.LessEqual(idleOf(target.Name)) {
+				continue
+			}
+			changed[target.Name] = idleOf(target.Name).Clone().Sub(victim.Resreq)
+			changed[node.Name].Add(victim.Resreq)
+			moves = append(moves, &taskMove{
+				Namespace: victim.Namespace,
+				Name:      victim.Name,
+				From:      node.Name,
+				To:        target.Name,
+				uid:       victim.UID,
+			})
+			break
+		}
+	}
+
+	if !task.Resreq.LessEqual(changed[node.Name]) {
+		return nil, nil, false
+	}
+	return moves, changed, true
+}
+
+// analyze returns the hint of job, which places its pending tasks until its
+// minAvailable is met.
+func (d *defragmenter) analyze(job *api.JobInfo) *defragHint {
+	hint := &defragHint{
+		UID:       string(job.UID),
+		Namespace: job.Namespace,
+		Name:      job.Name,
+		Moves:     []*taskMove{},
+	}
+
+	pending := job.GetTasks(api.Pending)
+	sort.Slice(pending, func(i, j int) bool {
+		return d.ssn.TaskOrderFn(pending[i], pending[j])
+	})
+	need := job.MinAvailable - job.ReadyTaskNum()
+	if need < 1 {
+		need = 1
+	}
+	if need > len(pending) {
+		hint.Message = fmt.Sprintf("%d tasks are needed, but %d are pending", need, len(pending))
+		return hint
+	}
+
+	// The idle resources and moved tasks are restored if job does not fit.
+	idle := make(map[string]*api.Resource, len(d.idle))
+	for name, r := range d.idle {
+		idle[name] = r.Clone()
+	}
+	defer func() {
+		if hint.Fits {
+			return
+		}
+		d.idle = idle
+		for _, move := range hint.Moves {
+			delete(d.moved, move.uid)
+		}
+		hint.Moves = []*taskMove{}
+	}()
+
+	for _, task := range pending[:need] {
+		nodes := d.feasibleNodes(task)
+
+		var placed *api.NodeInfo
+		for _, node := range nodes {
+			if task.Resreq.LessEqual(d.idle[node.Name]) {
+				placed = node
+				break
+			}
+		}
+		if placed != nil {
+			d.idle[placed.Name].Sub(task.Resreq)
+			continue
+		}
+
+		// Otherwise, the node which needs the fewest moves is chosen.
+		var best []*taskMove
+		var bestChanged map[string]*api.Resource
+		for _, node := range nodes {
+			moves, changed, ok := d.makeRoom(task, node, job)
+			if ok && (bestChanged == nil || len(moves) < len(best)) {
+				best, bestChanged, placed = moves, changed, node
+			}
+		}
+		if placed == nil {
+			hint.Message = fmt.Sprintf("task %s/%s does not fit on any node by moving other tasks", task.Namespace, task.Name)
+			return hint
+		}
+		for name, idle := range bestChanged {
+			d.idle[name] = idle
+		}
+		d.idle[placed.Name].Sub(task.Resreq)
+		for _, move := range best {
+			d.moved[move.uid] = true
+			hint.Moves = append(hint.Moves, move)
+		}
+	}
+
+	hint.Fits = true
+	return hint
+}
+
+// recordDefragHints keeps the re-placements which make the unschedulable
+// jobs of ssn fit, in the order of jobs; the moves of a job are planned after
+// the ones of the jobs before it.
+func (pc *Scheduler) recordDefragHints(ssn *framework.Session) {
+	var jobs []*api.JobInfo
+	for _, job := range ssn.Jobs {
+		if _, found := ssn.FitErrors[job.UID]; found {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return ssn.JobOrderFn(jobs[i], jobs[j])
+	})
+	if len(jobs) > maxDefragJobs {
+		jobs = jobs[:maxDefragJobs]
+	}
+
+	d := newDefragmenter(ssn)
+	hints := make([]*defragHint, 0, len(jobs))
+	for _, job := range jobs {
+		hints = append(hints, d.analyze(job))
+	}
+
+	pc.debugMutex.Lock()
+	defer pc.debugMutex.Unlock()
+
+	pc.defragHints = hints
+}
+
+// DefragHandler returns the HTTP handler which shows, for the unschedulable
+// jobs of the last session, the running tasks to re-place so they fit; the
+// tasks are not moved by scheduler, e.g. if the rebalancer is disabled.
+func (pc *Scheduler) DefragHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pc.debugMutex.Lock()
+		hints := pc.defragHints
+		pc.debugMutex.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(hints); err != nil {
+			logging.Error(err, "Failed to write defragmentation hints")
+		}
+	})
+}
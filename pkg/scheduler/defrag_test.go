@@ -0,0 +1,111 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+func TestDefragHints(t *testing.T) {
+	ssn := &framework.Session{
+		JobIndex:  map[api.JobID]*api.JobInfo{},
+		NodeIndex: map[string]*api.NodeInfo{},
+		FitErrors: map[api.JobID]*api.FitErrors{},
+	}
+	for _, name := range []string{"n1", "n2"} {
+		node := api.NewNodeInfo(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{
+				Capacity:    buildResourceList("4", "8Gi"),
+				Allocatable: buildResourceList("4", "8Gi"),
+			},
+		})
+		ssn.Nodes = append(ssn.Nodes, node)
+		ssn.NodeIndex[name] = node
+	}
+
+	controller := true
+	addTask := func(job, name, node, cpu string, phase v1.PodPhase) {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "c1",
+				Name:      name,
+				UID:       types.UID(name),
+				OwnerReferences: []metav1.OwnerReference{
+					{UID: types.UID(job), Controller: &controller},
+				},
+			},
+			Spec: v1.PodSpec{
+				NodeName: node,
+				Containers: []v1.Container{
+					{Resources: v1.ResourceRequirements{Requests: buildResourceList(cpu, "1Gi")}},
+				},
+			},
+			Status: v1.PodStatus{Phase: phase},
+		}
+		task := api.NewTaskInfo(pod)
+		info, found := ssn.JobIndex[task.Job]
+		if !found {
+			info = api.NewJobInfo(task.Job)
+			info.Namespace, info.Name, info.MinAvailable = "c1", job, 1
+			ssn.Jobs = append(ssn.Jobs, info)
+			ssn.JobIndex[task.Job] = info
+		}
+		info.AddTaskInfo(task)
+		if len(node) != 0 {
+			ssn.NodeIndex[node].AddTask(task)
+		}
+	}
+
+	// Each node has 2 CPUs idle, so the pending task of 4 CPUs fits after a
+	// running task is moved.
+	addTask("j1", "a", "n1", "2", v1.PodRunning)
+	addTask("j2", "b", "n2", "2", v1.PodRunning)
+	addTask("gang", "g1", "", "4", v1.PodPending)
+	// The task of 8 CPUs does not fit on any node.
+	addTask("huge", "h1", "", "8", v1.PodPending)
+
+	for _, job := range []string{"gang", "huge"} {
+		ssn.FitErrors[api.JobID(job)] = api.NewFitErrors(nil, len(ssn.Nodes))
+	}
+
+	pc := &Scheduler{}
+	pc.recordDefragHints(ssn)
+
+	if len(pc.defragHints) != 2 {
+		t.Fatalf("expected 2 hints, got %d", len(pc.defragHints))
+	}
+	for _, hint := range pc.defragHints {
+		switch hint.Name {
+		case "gang":
+			if !hint.Fits || len(hint.Moves) != 1 {
+				t.Errorf("expected gang fits by 1 move, got %+v", hint)
+			}
+		case "huge":
+			if hint.Fits || len(hint.Moves) != 0 || len(hint.Message) == 0 {
+				t.Errorf("expected huge does not fit, got %+v", hint)
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/audit"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+// sessionRecord is the job order and decisions of a session, which are dumped
+// for debugging.
+type sessionRecord struct {
+	ID    string
+	Start time.Time
+
+	// JobOrder is the first jobs ordered by the plugins when session opened.
+	JobOrder []*api.JobInfo
+
+	Decisions []*audit.Decision
+}
+
+// maxDumpedJobs is the maximum number of jobs in the dumped job order, so
+// recording the order does not sort all jobs in every session.
+const maxDumpedJobs = 100
+
+// recordJobOrder keeps the order of the first jobs in ssn before actions
+// executed.
+func (pc *Scheduler) recordJobOrder(ssn *framework.Session, start time.Time) {
+	queue := ssn.JobQueue()
+	var jobs []*api.JobInfo
+	for !queue.Empty() && len(jobs) < maxDumpedJobs {
+		jobs = append(jobs, queue.Pop().(*api.JobInfo))
+	}
+
+	pc.debugMutex.Lock()
+	defer pc.debugMutex.Unlock()
+
+	pc.currentSession = &sessionRecord{
+		ID:       string(ssn.ID),
+		Start:    start,
+		JobOrder: jobs,
+	}
+}
+
+// recordDecisions keeps the decisions of ssn; the session becomes the last one.
+func (pc *Scheduler) recordDecisions(ssn *framework.Session) {
+	pc.debugMutex.Lock()
+	defer pc.debugMutex.Unlock()
+
+	if pc.currentSession == nil {
+		return
+	}
+	pc.currentSession.Decisions = ssn.Decisions
+	pc.lastSessionRecord = pc.currentSession
+	pc.currentSession = nil
+}
+
+// HandleDumpSignals dumps the scheduler into a temp file when SIGUSR1 or
+// SIGQUIT is received, so the state is still available after the logs have
+// rolled. The scheduler keeps running on SIGUSR1, and quits with goroutine
+// stacks as default on SIGQUIT after dumping.
+func (pc *Scheduler) HandleDumpSignals() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR1, syscall.SIGQUIT)
+
+	for sig := range sigCh {
+		path, err := pc.dumpToTempFile()
+		if err != nil {
+			logging.Error(err, "Failed to dump scheduler", "signal", sig)
+		} else {
+			logging.Info("Dumped scheduler", "signal", sig, "path", path)
+		}
+
+		if sig == syscall.SIGQUIT {
+			logging.Flush()
+			signal.Reset(syscall.SIGQUIT)
+			syscall.Kill(os.Getpid(), syscall.SIGQUIT)
+			return
+		}
+	}
+}
+
+func (pc *Scheduler) dumpToTempFile() (string, error) {
+	f, err := ioutil.TempFile("", "kar-scheduler-dump-")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	pc.Dump(w)
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+
+	return f.Name(), f.Sync()
+}
+
+// Dump writes the snapshot of cache, and the job order and decisions of the
+// last session into w.
+func (pc *Scheduler) Dump(w io.Writer) {
+	fmt.Fprintf(w, "Dumped at %v\n\n", time.Now().Format(time.RFC3339Nano))
+
+	snapshot := pc.cache.Snapshot()
+
+	fmt.Fprintf(w, "Snapshot:\n")
+	fmt.Fprintf(w, "Nodes:\n")
+	for _, n := range snapshot.Nodes {
+		fmt.Fprintf(w, "\t %s: idle(%v) used(%v) releasing(%v) allocatable(%v) pods(%d)\n",
+			n.Name, n.Idle, n.Used, n.Releasing, n.Allocatable, len(n.Tasks))
+		for _, t := range n.Tasks {
+			fmt.Fprintf(w, "\t\t %v\n", t)
+		}
+	}
+	fmt.Fprintf(w, "Jobs:\n")
+	for _, job := range snapshot.Jobs {
+		fmt.Fprintf(w, "\t Job(%s) namespace(%s) name(%s) queue(%s) minAvailable(%v) suspended(%v)\n",
+			job.UID, job.Namespace, job.Name, job.Queue, job.MinAvailable, job.Suspended)
+		for _, t := range job.Tasks {
+			fmt.Fprintf(w, "\t\t %v\n", t)
+		}
+	}
+	fmt.Fprintf(w, "Queues:\n")
+	for _, queue := range snapshot.Queues {
+		fmt.Fprintf(w, "\t Queue(%s) weight(%d) capability(%v)\n",
+			queue.Name, queue.Weight, queue.Capability)
+	}
+
+	pc.debugMutex.Lock()
+	record := pc.lastSessionRecord
+	pc.debugMutex.Unlock()
+
+	if record == nil {
+		fmt.Fprintf(w, "\nNo session completed.\n")
+		return
+	}
+
+	fmt.Fprintf(w, "\nLast session %s started at %v:\n", record.ID, record.Start.Format(time.RFC3339Nano))
+	fmt.Fprintf(w, "Job order (first %d):\n", maxDumpedJobs)
+	for i, job := range record.JobOrder {
+		fmt.Fprintf(w, "\t %d: Job(%s) namespace(%s) name(%s) queue(%s)\n",
+			i, job.UID, job.Namespace, job.Name, job.Queue)
+	}
+	fmt.Fprintf(w, "Decisions:\n")
+	enc := json.NewEncoder(w)
+	for _, d := range record.Decisions {
+		fmt.Fprintf(w, "\t ")
+		if err := enc.Encode(d); err != nil {
+			logging.Error(err, "Failed to dump decision")
+		}
+	}
+}
@@ -0,0 +1,343 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extender
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/api/core/v1"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+// DefaultHTTPTimeout is the timeout of calls to extender if it's not set,
+// the same as kube-scheduler.
+const DefaultHTTPTimeout = 5 * time.Second
+
+// extenders is the extenders called by scheduler, in the order of policy.
+var extenders []*HTTPExtender
+
+// SetExtenders sets the extenders called by scheduler; it's not thread safe,
+// and has to be called before scheduler runs.
+func SetExtenders(configs []*Config) {
+	extenders = nil
+	for _, config := range configs {
+		extenders = append(extenders, NewHTTPExtender(config))
+	}
+}
+
+// Extenders returns the extenders called by scheduler.
+func Extenders() []*HTTPExtender {
+	return extenders
+}
+
+// Binder returns the first extender which binds pods, nil if none.
+func Binder() *HTTPExtender {
+	for _, e := range extenders {
+		if e.IsBinder() {
+			return e
+		}
+	}
+	return nil
+}
+
+// LoadPolicy loads the extenders from the policy file of kube-scheduler in
+// JSON; the other parts of the policy are ignored.
+func LoadPolicy(path string) ([]*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse extender policy %s: %v", path, err)
+	}
+
+	for _, config := range policy.Extenders {
+		if len(config.URLPrefix) == 0 {
+			return nil, fmt.Errorf("urlPrefix of extender is not set in %s", path)
+		}
+	}
+
+	return policy.Extenders, nil
+}
+
+// HTTPExtender calls an extender of kube-scheduler by its HTTP API.
+type HTTPExtender struct {
+	config *Config
+	client *http.Client
+}
+
+// NewHTTPExtender creates the HTTPExtender of config.
+func NewHTTPExtender(config *Config) *HTTPExtender {
+	timeout := config.HTTPTimeout
+	if timeout <= 0 {
+		timeout = DefaultHTTPTimeout
+	}
+
+	return &HTTPExtender{
+		config: config,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the URL prefix of extender as its name.
+func (e *HTTPExtender) Name() string {
+	return e.config.URLPrefix
+}
+
+// IsIgnorable returns whether the extender is skipped if it's unavailable.
+func (e *HTTPExtender) IsIgnorable() bool {
+	return e.config.Ignorable
+}
+
+// IsBinder returns whether the extender binds pods.
+func (e *HTTPExtender) IsBinder() bool {
+	return len(e.config.BindVerb) != 0
+}
+
+// splitNodes returns the nodes to send to extender, and the other nodes which
+// skip it, i.e. the non-Linux nodes if the extender is Linux only.
+func (e *HTTPExtender) splitNodes(nodes []*api.NodeInfo) ([]*api.NodeInfo, []*api.NodeInfo) {
+	if !e.config.LinuxOnly {
+		return nodes, nil
+	}
+
+	var sent, skipped []*api.NodeInfo
+	for _, node := range nodes {
+		if node.OS() == api.LinuxOS {
+			sent = append(sent, node)
+		} else {
+			skipped = append(skipped, node)
+		}
+	}
+	return sent, skipped
+}
+
+// SupportsFilter returns whether the extender filters nodes.
+func (e *HTTPExtender) SupportsFilter() bool {
+	return len(e.config.FilterVerb) != 0
+}
+
+// SupportsPrioritize returns whether the extender scores nodes.
+func (e *HTTPExtender) SupportsPrioritize() bool {
+	return len(e.config.PrioritizeVerb) != 0
+}
+
+// SupportsPreemption returns whether the extender filters preemption victims.
+func (e *HTTPExtender) SupportsPreemption() bool {
+	return len(e.config.PreemptVerb) != 0
+}
+
+// args returns the arguments of filter and prioritize calls for task on
+// nodes; only node names are sent if the extender caches nodes.
+func (e *HTTPExtender) args(task *api.TaskInfo, nodes []*api.NodeInfo) *ExtenderArgs {
+	args := &ExtenderArgs{Pod: task.Pod}
+
+	if e.config.NodeCacheCapable {
+		names := make([]string, 0, len(nodes))
+		for _, node := range nodes {
+			names = append(names, node.Name)
+		}
+		args.NodeNames = &names
+		return args
+	}
+
+	list := &v1.NodeList{Items: make([]v1.Node, 0, len(nodes))}
+	for _, node := range nodes {
+		if node.Node != nil {
+			list.Items = append(list.Items, *node.Node)
+		}
+	}
+	args.Nodes = list
+	return args
+}
+
+// Filter returns the nodes which pass the filter of extender for task, and
+// the reasons of the failed nodes by node name; the nodes skipping extender
+// are passed.
+func (e *HTTPExtender) Filter(task *api.TaskInfo, nodes []*api.NodeInfo) ([]*api.NodeInfo, map[string]string, error) {
+	if !e.SupportsFilter() {
+		return nodes, nil, nil
+	}
+
+	nodes, skipped := e.splitNodes(nodes)
+	if len(nodes) == 0 {
+		return skipped, nil, nil
+	}
+
+	result := &ExtenderFilterResult{}
+	if err := e.send(e.config.FilterVerb, e.args(task, nodes), result); err != nil {
+		return nil, nil, err
+	}
+	if len(result.Error) != 0 {
+		return nil, nil, fmt.Errorf("%s", result.Error)
+	}
+
+	passed := map[string]bool{}
+	if e.config.NodeCacheCapable && result.NodeNames != nil {
+		for _, name := range *result.NodeNames {
+			passed[name] = true
+		}
+	} else if result.Nodes != nil {
+		for _, node := range result.Nodes.Items {
+			passed[node.Name] = true
+		}
+	}
+
+	filtered := make([]*api.NodeInfo, 0, len(passed)+len(skipped))
+	for _, node := range nodes {
+		if passed[node.Name] {
+			filtered = append(filtered, node)
+		}
+	}
+	filtered = append(filtered, skipped...)
+
+	return filtered, result.FailedNodes, nil
+}
+
+// Prioritize returns the weighted scores of nodes for task by node name; the
+// nodes skipping extender are not scored.
+func (e *HTTPExtender) Prioritize(task *api.TaskInfo, nodes []*api.NodeInfo) (map[string]float64, error) {
+	if !e.SupportsPrioritize() {
+		return nil, nil
+	}
+
+	if nodes, _ = e.splitNodes(nodes); len(nodes) == 0 {
+		return nil, nil
+	}
+
+	result := HostPriorityList{}
+	if err := e.send(e.config.PrioritizeVerb, e.args(task, nodes), &result); err != nil {
+		return nil, err
+	}
+
+	scores := make(map[string]float64, len(result))
+	for _, hp := range result {
+		scores[hp.Host] = float64(hp.Score * e.config.Weight)
+	}
+
+	return scores, nil
+}
+
+// Bind binds pod to hostname by extender.
+func (e *HTTPExtender) Bind(pod *v1.Pod, hostname string) error {
+	if !e.IsBinder() {
+		return fmt.Errorf("extender %s does not bind pods", e.Name())
+	}
+
+	args := &ExtenderBindingArgs{
+		PodName:      pod.Name,
+		PodNamespace: pod.Namespace,
+		PodUID:       pod.UID,
+		Node:         hostname,
+	}
+	result := &ExtenderBindingResult{}
+	if err := e.send(e.config.BindVerb, args, result); err != nil {
+		return err
+	}
+	if len(result.Error) != 0 {
+		return fmt.Errorf("%s", result.Error)
+	}
+
+	return nil
+}
+
+// ProcessPreemption returns the victims accepted by extender to preempt for
+// task, by node name; the nodes not returned can not be preempted for task.
+func (e *HTTPExtender) ProcessPreemption(task *api.TaskInfo,
+	victims map[string][]*api.TaskInfo) (map[string][]*api.TaskInfo, error) {
+	if !e.SupportsPreemption() {
+		return victims, nil
+	}
+
+	args := &ExtenderPreemptionArgs{Pod: task.Pod}
+	if e.config.NodeCacheCapable {
+		args.NodeNameToMetaVictims = map[string]*MetaVictims{}
+		for name, tasks := range victims {
+			mv := &MetaVictims{}
+			for _, t := range tasks {
+				mv.Pods = append(mv.Pods, &MetaPod{UID: string(t.UID)})
+			}
+			args.NodeNameToMetaVictims[name] = mv
+		}
+	} else {
+		args.NodeNameToVictims = map[string]*Victims{}
+		for name, tasks := range victims {
+			v := &Victims{}
+			for _, t := range tasks {
+				v.Pods = append(v.Pods, t.Pod)
+			}
+			args.NodeNameToVictims[name] = v
+		}
+	}
+
+	result := &ExtenderPreemptionResult{}
+	if err := e.send(e.config.PreemptVerb, args, result); err != nil {
+		return nil, err
+	}
+
+	// The victims in result are referenced by UID, which are mapped back to
+	// the tasks of the same node.
+	accepted := map[string][]*api.TaskInfo{}
+	for name, mv := range result.NodeNameToMetaVictims {
+		tasks := map[string]*api.TaskInfo{}
+		for _, t := range victims[name] {
+			tasks[string(t.UID)] = t
+		}
+
+		accepted[name] = []*api.TaskInfo{}
+		for _, mp := range mv.Pods {
+			t, found := tasks[mp.UID]
+			if !found {
+				return nil, fmt.Errorf("unknown victim %s on node %s from extender %s", mp.UID, name, e.Name())
+			}
+			accepted[name] = append(accepted[name], t)
+		}
+	}
+
+	return accepted, nil
+}
+
+// send posts args to the verb of extender, and decodes the response into
+// result.
+func (e *HTTPExtender) send(verb string, args, result interface{}) error {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+
+	url := strings.TrimRight(e.config.URLPrefix, "/") + "/" + verb
+	resp, err := e.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed %s with extender at %s, code %d", verb, url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extender
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+func buildTask(name, nodeName string) *api.TaskInfo {
+	return api.NewTaskInfo(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "c1",
+			UID:       types.UID(name),
+		},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+		},
+	})
+}
+
+func buildNodes(names ...string) []*api.NodeInfo {
+	var nodes []*api.NodeInfo
+	for _, name := range names {
+		nodes = append(nodes, api.NewNodeInfo(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		}))
+	}
+	return nodes
+}
+
+func nodeNames(nodes []*api.NodeInfo) []string {
+	var names []string
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	return names
+}
+
+// fakeExtender serves the verbs of an extender which accepts the nodes and
+// victims named in accepted, and scores each node by 1.
+type fakeExtender struct {
+	accepted map[string]bool
+	bound    *ExtenderBindingArgs
+}
+
+func (fe *fakeExtender) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var result interface{}
+
+	switch r.URL.Path {
+	case "/filter":
+		args := &ExtenderArgs{}
+		json.NewDecoder(r.Body).Decode(args)
+
+		var names []string
+		if args.NodeNames != nil {
+			names = *args.NodeNames
+		} else {
+			for _, node := range args.Nodes.Items {
+				names = append(names, node.Name)
+			}
+		}
+
+		passed := []string{}
+		nodes := &v1.NodeList{}
+		failed := FailedNodesMap{}
+		for _, name := range names {
+			if fe.accepted[name] {
+				passed = append(passed, name)
+				nodes.Items = append(nodes.Items, v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}})
+			} else {
+				failed[name] = "no license"
+			}
+		}
+		if args.NodeNames != nil {
+			result = &ExtenderFilterResult{NodeNames: &passed, FailedNodes: failed}
+		} else {
+			result = &ExtenderFilterResult{Nodes: nodes, FailedNodes: failed}
+		}
+	case "/prioritize":
+		args := &ExtenderArgs{}
+		json.NewDecoder(r.Body).Decode(args)
+
+		list := HostPriorityList{}
+		for _, name := range *args.NodeNames {
+			list = append(list, HostPriority{Host: name, Score: 1})
+		}
+		result = list
+	case "/bind":
+		fe.bound = &ExtenderBindingArgs{}
+		json.NewDecoder(r.Body).Decode(fe.bound)
+		result = &ExtenderBindingResult{}
+	case "/preempt":
+		args := &ExtenderPreemptionArgs{}
+		json.NewDecoder(r.Body).Decode(args)
+
+		accepted := map[string]*MetaVictims{}
+		for name, victims := range args.NodeNameToMetaVictims {
+			if fe.accepted[name] {
+				accepted[name] = victims
+			}
+		}
+		result = &ExtenderPreemptionResult{NodeNameToMetaVictims: accepted}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+func TestHTTPExtender(t *testing.T) {
+	fe := &fakeExtender{accepted: map[string]bool{"n1": true, "n3": true}}
+	server := httptest.NewServer(fe)
+	defer server.Close()
+
+	task := buildTask("p1", "")
+	nodes := buildNodes("n1", "n2", "n3")
+
+	for _, nodeCacheCapable := range []bool{true, false} {
+		e := NewHTTPExtender(&Config{
+			URLPrefix:        server.URL,
+			FilterVerb:       "filter",
+			NodeCacheCapable: nodeCacheCapable,
+		})
+
+		filtered, failed, err := e.Filter(task, nodes)
+		if err != nil {
+			t.Fatalf("nodeCacheCapable %v: failed to filter nodes: %v", nodeCacheCapable, err)
+		}
+		if got := nodeNames(filtered); !reflect.DeepEqual(got, []string{"n1", "n3"}) {
+			t.Errorf("nodeCacheCapable %v: expected nodes [n1 n3] filtered, got %v", nodeCacheCapable, got)
+		}
+		if !reflect.DeepEqual(failed, map[string]string{"n2": "no license"}) {
+			t.Errorf("nodeCacheCapable %v: expected n2 failed, got %v", nodeCacheCapable, failed)
+		}
+	}
+
+	// The Windows node skips the Linux-only extender, and is passed.
+	windows := api.NewNodeInfo(&v1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   "w1",
+		Labels: map[string]string{api.NodeOSLabel: api.WindowsOS},
+	}})
+	e := NewHTTPExtender(&Config{
+		URLPrefix:  server.URL,
+		FilterVerb: "filter",
+		LinuxOnly:  true,
+	})
+	filtered, failed, err := e.Filter(task, append(buildNodes("n1", "n2"), windows))
+	if err != nil {
+		t.Fatalf("failed to filter nodes by Linux-only extender: %v", err)
+	}
+	if got := nodeNames(filtered); !reflect.DeepEqual(got, []string{"n1", "w1"}) || len(failed) != 1 {
+		t.Errorf("expected nodes [n1 w1] filtered and n2 failed, got %v and %v", got, failed)
+	}
+
+	e = NewHTTPExtender(&Config{
+		URLPrefix:        server.URL,
+		PrioritizeVerb:   "prioritize",
+		PreemptVerb:      "preempt",
+		BindVerb:         "bind",
+		Weight:           5,
+		NodeCacheCapable: true,
+	})
+
+	scores, err := e.Prioritize(task, nodes)
+	if err != nil {
+		t.Fatalf("failed to prioritize nodes: %v", err)
+	}
+	if expected := map[string]float64{"n1": 5, "n2": 5, "n3": 5}; !reflect.DeepEqual(scores, expected) {
+		t.Errorf("expected weighted scores %v, got %v", expected, scores)
+	}
+
+	if err := e.Bind(task.Pod, "n1"); err != nil {
+		t.Fatalf("failed to bind pod: %v", err)
+	}
+	expectedBinding := &ExtenderBindingArgs{PodName: "p1", PodNamespace: "c1", PodUID: "p1", Node: "n1"}
+	if !reflect.DeepEqual(fe.bound, expectedBinding) {
+		t.Errorf("expected binding %v, got %v", expectedBinding, fe.bound)
+	}
+
+	victim1, victim2 := buildTask("v1", "n1"), buildTask("v2", "n2")
+	accepted, err := e.ProcessPreemption(task, map[string][]*api.TaskInfo{
+		"n1": {victim1},
+		"n2": {victim2},
+	})
+	if err != nil {
+		t.Fatalf("failed to process preemption: %v", err)
+	}
+	if expected := map[string][]*api.TaskInfo{"n1": {victim1}}; !reflect.DeepEqual(accepted, expected) {
+		t.Errorf("expected victims %v accepted, got %v", expected, accepted)
+	}
+}
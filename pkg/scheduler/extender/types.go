@@ -0,0 +1,138 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extender
+
+import (
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// The types are the same as the extender API (v1) of kube-scheduler in JSON,
+// so the existing extenders work without change.
+
+// Config is the configuration of an extender, the same as the "extenders"
+// of kube-scheduler policy file.
+type Config struct {
+	// URLPrefix is the URL prefix of the extender, e.g. http://gpu-extender:8888.
+	URLPrefix string `json:"urlPrefix"`
+	// FilterVerb is the verb of filter call, empty if not supported.
+	FilterVerb string `json:"filterVerb,omitempty"`
+	// PreemptVerb is the verb of preempt call, empty if not supported.
+	PreemptVerb string `json:"preemptVerb,omitempty"`
+	// PrioritizeVerb is the verb of prioritize call, empty if not supported.
+	PrioritizeVerb string `json:"prioritizeVerb,omitempty"`
+	// Weight is the multiplier of the node scores of prioritize call.
+	Weight int `json:"weight,omitempty"`
+	// BindVerb is the verb of bind call, empty if not supported; the
+	// extender binds the pods instead of the API server if it's set.
+	BindVerb string `json:"bindVerb,omitempty"`
+	// HTTPTimeout is the timeout of calls in nanoseconds, the same as
+	// time.Duration; it's DefaultHTTPTimeout if not set.
+	HTTPTimeout time.Duration `json:"httpTimeout,omitempty"`
+	// NodeCacheCapable is whether the extender caches the nodes, so only
+	// node names are sent to it instead of the whole nodes.
+	NodeCacheCapable bool `json:"nodeCacheCapable,omitempty"`
+	// Ignorable is whether the extender is skipped if it's unavailable,
+	// instead of failing the scheduling of the task.
+	Ignorable bool `json:"ignorable,omitempty"`
+	// LinuxOnly is whether the extender only filters and scores Linux nodes,
+	// e.g. an extender of Linux device plugins; the other nodes skip it. It's
+	// not in the policy of kube-scheduler.
+	LinuxOnly bool `json:"linuxOnly,omitempty"`
+}
+
+// Policy is the part of kube-scheduler policy file for extenders, so the
+// existing policy file can be used as it is.
+type Policy struct {
+	Extenders []*Config `json:"extenders"`
+}
+
+// ExtenderArgs is the arguments of filter and prioritize calls.
+type ExtenderArgs struct {
+	Pod *v1.Pod `json:"pod"`
+	// Nodes is set if the extender is not NodeCacheCapable.
+	Nodes *v1.NodeList `json:"nodes,omitempty"`
+	// NodeNames is set if the extender is NodeCacheCapable.
+	NodeNames *[]string `json:"nodenames,omitempty"`
+}
+
+// FailedNodesMap is the reasons of the nodes which failed the filter, by
+// node name.
+type FailedNodesMap map[string]string
+
+// ExtenderFilterResult is the result of filter call.
+type ExtenderFilterResult struct {
+	Nodes       *v1.NodeList   `json:"nodes,omitempty"`
+	NodeNames   *[]string      `json:"nodenames,omitempty"`
+	FailedNodes FailedNodesMap `json:"failedNodes,omitempty"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// HostPriority is the score of a node by prioritize call.
+type HostPriority struct {
+	Host  string `json:"host"`
+	Score int    `json:"score"`
+}
+
+// HostPriorityList is the result of prioritize call.
+type HostPriorityList []HostPriority
+
+// ExtenderBindingArgs is the arguments of bind call.
+type ExtenderBindingArgs struct {
+	PodName      string    `json:"podName"`
+	PodNamespace string    `json:"podNamespace"`
+	PodUID       types.UID `json:"podUID"`
+	Node         string    `json:"node"`
+}
+
+// ExtenderBindingResult is the result of bind call.
+type ExtenderBindingResult struct {
+	Error string `json:"error,omitempty"`
+}
+
+// Victims is the pods to evict on a node for preemption.
+type Victims struct {
+	Pods             []*v1.Pod `json:"pods"`
+	NumPDBViolations int       `json:"numPDBViolations"`
+}
+
+// MetaPod is the pod referenced by UID.
+type MetaPod struct {
+	UID string `json:"uid"`
+}
+
+// MetaVictims is the victims referenced by UID.
+type MetaVictims struct {
+	Pods             []*MetaPod `json:"pods"`
+	NumPDBViolations int        `json:"numPDBViolations"`
+}
+
+// ExtenderPreemptionArgs is the arguments of preempt call; the victims are
+// referenced by UID if the extender is NodeCacheCapable.
+type ExtenderPreemptionArgs struct {
+	Pod                   *v1.Pod                 `json:"pod"`
+	NodeNameToVictims     map[string]*Victims     `json:"nodeNameToVictims,omitempty"`
+	NodeNameToMetaVictims map[string]*MetaVictims `json:"nodeNameToMetaVictims,omitempty"`
+}
+
+// ExtenderPreemptionResult is the result of preempt call, the nodes and
+// victims accepted by the extender.
+type ExtenderPreemptionResult struct {
+	NodeNameToMetaVictims map[string]*MetaVictims `json:"nodeNameToMetaVictims,omitempty"`
+}
@@ -20,15 +20,37 @@ import (
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/actions/allocate"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/actions/decorate"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/actions/garantee"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/actions/preempt"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/actions/shuffle"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
 
+	// Import binpack plugins
+	_ "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/plugins/binpack"
 	// Import drf plugins
 	_ "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/plugins/drf"
+	// Import extender plugins
+	_ "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/plugins/extender"
+	// Import gpushare plugins
+	_ "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/plugins/gpushare"
+	// Import nodeos plugins
+	_ "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/plugins/nodeos"
+	// Import proportion plugins
+	_ "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/plugins/proportion"
+	// Import spark plugins
+	_ "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/plugins/spark"
+	// Import usage plugins
+	_ "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/plugins/usage"
 )
 
-// Actions is a list of action that should be executed in order.
-var Actions = []framework.Action{
+// allActions is all the actions of scheduler, which are named by config.
+var allActions = []framework.Action{
 	decorate.New(),
 	garantee.New(),
 	allocate.New(),
+	preempt.New(),
+	shuffle.New(),
 }
+
+// Actions is a list of action that should be executed in order, if config
+// does not name them.
+var Actions = allActions
@@ -17,27 +17,58 @@ limitations under the License.
 package framework
 
 import (
+	"time"
+
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/cache"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/metrics"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/tracing"
 )
 
 func OpenSession(cache cache.Cache) *Session {
+	span := tracing.StartSpan("session")
+
+	snapshotSpan := span.StartChild("snapshot")
 	ssn := openSession(cache)
+	snapshotSpan.Finish()
+
+	ssn.Span = span
+	ssn.Span.SetTag("session", ssn.ID)
 
 	for _, pb := range pluginBuilders {
-		ssn.plugins = append(ssn.plugins, pb())
+		if plugin := pb(); !DisabledPlugins[plugin.Name()] {
+			ssn.plugins = append(ssn.plugins, plugin)
+		}
 	}
 
+	openSpan := ssn.Span.StartChild(metrics.OnSessionOpen)
 	for _, plugin := range ssn.plugins {
+		pluginSpan := openSpan.StartChild(plugin.Name())
+		onSessionOpenStart := time.Now()
 		plugin.OnSessionOpen(ssn)
+		metrics.UpdatePluginLatency(plugin.Name(), metrics.OnSessionOpen, time.Since(onSessionOpenStart))
+		pluginSpan.Finish()
 	}
+	openSpan.Finish()
 
 	return ssn
 }
 
 func CloseSession(ssn *Session) {
+	span := ssn.Span
+
+	closeSpan := span.StartChild(metrics.OnSessionClose)
 	for _, plugin := range ssn.plugins {
+		pluginSpan := closeSpan.StartChild(plugin.Name())
+		onSessionCloseStart := time.Now()
 		plugin.OnSessionClose(ssn)
+		metrics.UpdatePluginLatency(plugin.Name(), metrics.OnSessionClose, time.Since(onSessionCloseStart))
+		pluginSpan.Finish()
 	}
+	closeSpan.Finish()
 
+	statusSpan := span.StartChild("updateJobStatus")
 	closeSession(ssn)
+	statusSpan.Finish()
+
+	span.Finish()
 }
@@ -0,0 +1,200 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"sort"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+// idleIndex is the nodes sorted by the idle amount of a resource, so the nodes
+// with enough idle resource for a task are found by binary search instead of
+// checking every node.
+type idleIndex struct {
+	idleFn func(node *api.NodeInfo) float64
+
+	// nodes is sorted by idle and then name; idle is the idle amount of each
+	// node when it was indexed.
+	nodes []*api.NodeInfo
+	idle  []float64
+
+	// indexed is the idle amount of each node when it was indexed, by name,
+	// to find the node after its idle changed.
+	indexed map[string]float64
+}
+
+func newIdleIndex(nodes []*api.NodeInfo, idleFn func(node *api.NodeInfo) float64) *idleIndex {
+	ii := &idleIndex{
+		idleFn:  idleFn,
+		nodes:   make([]*api.NodeInfo, len(nodes)),
+		idle:    make([]float64, len(nodes)),
+		indexed: make(map[string]float64, len(nodes)),
+	}
+
+	copy(ii.nodes, nodes)
+	for _, node := range nodes {
+		ii.indexed[node.Name] = idleFn(node)
+	}
+	sort.Slice(ii.nodes, func(i, j int) bool {
+		return ii.less(ii.indexed[ii.nodes[i].Name], ii.nodes[i].Name,
+			ii.indexed[ii.nodes[j].Name], ii.nodes[j].Name)
+	})
+	for i, node := range ii.nodes {
+		ii.idle[i] = ii.indexed[node.Name]
+	}
+
+	return ii
+}
+
+func (ii *idleIndex) less(lIdle float64, lName string, rIdle float64, rName string) bool {
+	if lIdle != rIdle {
+		return lIdle < rIdle
+	}
+	return lName < rName
+}
+
+// search returns the position of the node with idle and name in the first n
+// nodes, or where it would be inserted.
+func (ii *idleIndex) search(n int, idle float64, name string) int {
+	return sort.Search(n, func(i int) bool {
+		return !ii.less(ii.idle[i], ii.nodes[i].Name, idle, name)
+	})
+}
+
+// update moves node to its position by the current idle amount.
+func (ii *idleIndex) update(node *api.NodeInfo) {
+	old, found := ii.indexed[node.Name]
+	if !found {
+		return
+	}
+	idle := ii.idleFn(node)
+	if idle == old {
+		return
+	}
+
+	// Remove the node, and insert it back by the new idle.
+	n := len(ii.nodes)
+	i := ii.search(n, old, node.Name)
+	copy(ii.nodes[i:], ii.nodes[i+1:])
+	copy(ii.idle[i:], ii.idle[i+1:])
+
+	j := ii.search(n-1, idle, node.Name)
+	copy(ii.nodes[j+1:], ii.nodes[j:n-1])
+	copy(ii.idle[j+1:], ii.idle[j:n-1])
+	ii.nodes[j] = node
+	ii.idle[j] = idle
+
+	ii.indexed[node.Name] = idle
+}
+
+// atLeast returns the nodes whose idle amount is not less than amount, in
+// increasing order of idle; the result is invalid after the index updated.
+func (ii *idleIndex) atLeast(amount float64) []*api.NodeInfo {
+	i := sort.SearchFloat64s(ii.idle, amount)
+	return ii.nodes[i:len(ii.nodes):len(ii.nodes)]
+}
+
+func idleCPU(node *api.NodeInfo) float64 {
+	return node.Idle.MilliCPU
+}
+
+func idleGPU(node *api.NodeInfo) float64 {
+	return float64(node.Idle.GPU)
+}
+
+// NodesWithIdle returns the nodes whose idle GPU is enough for req if it
+// requests GPU, or else whose idle CPU is enough; the other resources are not
+// checked. The result is invalid after a task is bound or evicted.
+func (ssn *Session) NodesWithIdle(req *api.Resource) []*api.NodeInfo {
+	if req.GPU > 0 {
+		return ssn.idleGPUIndex.atLeast(float64(req.GPU))
+	}
+	return ssn.idleCPUIndex.atLeast(req.MilliCPU)
+}
+
+// updateNodeIndexes updates the indexes after the resources of node changed.
+func (ssn *Session) updateNodeIndexes(node *api.NodeInfo) {
+	ssn.idleCPUIndex.update(node)
+	ssn.idleGPUIndex.update(node)
+}
+
+// labelIndex is the nodes by the value of each label, by label key, so the
+// nodes matching a node selector are found from the nodes of its least common
+// label instead of checking every node.
+type labelIndex map[string]map[string][]*api.NodeInfo
+
+func newLabelIndex(nodes []*api.NodeInfo) labelIndex {
+	li := labelIndex{}
+	for _, node := range nodes {
+		if node.Node == nil {
+			continue
+		}
+		for key, value := range node.Node.Labels {
+			if li[key] == nil {
+				li[key] = map[string][]*api.NodeInfo{}
+			}
+			li[key][value] = append(li[key][value], node)
+		}
+	}
+	return li
+}
+
+// match returns the nodes whose labels include all labels of selector.
+func (li labelIndex) match(selector map[string]string) []*api.NodeInfo {
+	var shortest []*api.NodeInfo
+	first := true
+	for key, value := range selector {
+		nodes := li[key][value]
+		if first || len(nodes) < len(shortest) {
+			shortest = nodes
+			first = false
+		}
+	}
+
+	matched := []*api.NodeInfo{}
+	for _, node := range shortest {
+		if nodeMatches(node, selector) {
+			matched = append(matched, node)
+		}
+	}
+	return matched
+}
+
+func nodeMatches(node *api.NodeInfo, selector map[string]string) bool {
+	for key, value := range selector {
+		if v, found := node.Node.Labels[key]; !found || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// NodesWithLabels returns the nodes whose labels include all labels of
+// selector, in the order of Nodes; nil selector means all nodes.
+func (ssn *Session) NodesWithLabels(selector map[string]string) []*api.NodeInfo {
+	if len(selector) == 0 {
+		return ssn.Nodes
+	}
+
+	// The labels of nodes are not changed in session, so the index is built
+	// once when it's used.
+	if ssn.labelIndex == nil {
+		ssn.labelIndex = newLabelIndex(ssn.Nodes)
+	}
+	return ssn.labelIndex.match(selector)
+}
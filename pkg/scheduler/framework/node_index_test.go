@@ -0,0 +1,166 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+func buildNodeWithIdleCPU(name string, milliCPU float64) *api.NodeInfo {
+	node := api.NewNodeInfo(nil)
+	node.Name = name
+	node.Idle.MilliCPU = milliCPU
+	return node
+}
+
+func nodeNames(nodes []*api.NodeInfo) []string {
+	names := []string{}
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	return names
+}
+
+func TestIdleIndex(t *testing.T) {
+	n1 := buildNodeWithIdleCPU("n1", 4000)
+	n2 := buildNodeWithIdleCPU("n2", 1000)
+	n3 := buildNodeWithIdleCPU("n3", 2000)
+	n4 := buildNodeWithIdleCPU("n4", 2000)
+
+	index := newIdleIndex([]*api.NodeInfo{n1, n2, n3, n4}, idleCPU)
+
+	tests := []struct {
+		name     string
+		update   func()
+		amount   float64
+		expected []string
+	}{
+		{
+			name:     "sorted by idle and name",
+			amount:   0,
+			expected: []string{"n2", "n3", "n4", "n1"},
+		},
+		{
+			name:     "nodes with enough idle",
+			amount:   2000,
+			expected: []string{"n3", "n4", "n1"},
+		},
+		{
+			name:     "no node with enough idle",
+			amount:   5000,
+			expected: []string{},
+		},
+		{
+			name: "allocated on the node with most idle",
+			update: func() {
+				n1.Idle.MilliCPU = 500
+				index.update(n1)
+			},
+			amount:   0,
+			expected: []string{"n1", "n2", "n3", "n4"},
+		},
+		{
+			name: "released on the node with least idle",
+			update: func() {
+				n1.Idle.MilliCPU = 3000
+				index.update(n1)
+			},
+			amount:   2000,
+			expected: []string{"n3", "n4", "n1"},
+		},
+		{
+			name: "moved between nodes with the same idle",
+			update: func() {
+				n2.Idle.MilliCPU = 2000
+				index.update(n2)
+			},
+			amount:   1500,
+			expected: []string{"n2", "n3", "n4", "n1"},
+		},
+	}
+
+	for _, test := range tests {
+		if test.update != nil {
+			test.update()
+		}
+		got := nodeNames(index.atLeast(test.amount))
+		if !reflect.DeepEqual(test.expected, got) {
+			t.Errorf("case <%s>: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
+
+func buildNodeWithLabels(name string, labels map[string]string) *api.NodeInfo {
+	return api.NewNodeInfo(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	})
+}
+
+func TestNodesWithLabels(t *testing.T) {
+	nodes := []*api.NodeInfo{
+		buildNodeWithLabels("n1", map[string]string{"gpu": "true", "zone": "a"}),
+		buildNodeWithLabels("n2", map[string]string{"zone": "a"}),
+		buildNodeWithLabels("n3", map[string]string{"gpu": "true", "zone": "b"}),
+		buildNodeWithLabels("n4", nil),
+		// The node of tasks which is not added yet.
+		api.NewNodeInfo(nil),
+	}
+
+	tests := []struct {
+		name     string
+		selector map[string]string
+		expected []string
+	}{
+		{
+			name:     "one label",
+			selector: map[string]string{"gpu": "true"},
+			expected: []string{"n1", "n3"},
+		},
+		{
+			name:     "all labels matched",
+			selector: map[string]string{"gpu": "true", "zone": "a"},
+			expected: []string{"n1"},
+		},
+		{
+			name:     "label value not matched",
+			selector: map[string]string{"zone": "c"},
+			expected: []string{},
+		},
+		{
+			name:     "label key not matched",
+			selector: map[string]string{"gpu": "true", "arch": "arm64"},
+			expected: []string{},
+		},
+	}
+
+	ssn := &Session{Nodes: nodes}
+	for _, test := range tests {
+		got := nodeNames(ssn.NodesWithLabels(test.selector))
+		if !reflect.DeepEqual(test.expected, got) {
+			t.Errorf("case <%s>: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
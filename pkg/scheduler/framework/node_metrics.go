@@ -0,0 +1,95 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/metrics"
+)
+
+// poolUsage is the resource usage of a node pool.
+type poolUsage struct {
+	allocatable *api.Resource
+	used        *api.Resource
+	idle        *api.Resource
+	largestIdle *api.Resource
+}
+
+// updateNodeMetrics exports the allocated ratio of nodes, and how fragmented
+// the idle resources of each node pool are; the nodes are in one pool if
+// api.NodePoolLabel is not set.
+func updateNodeMetrics(nodes []*api.NodeInfo) {
+	metrics.ResetNodeMetrics()
+
+	pools := map[string]*poolUsage{}
+	for _, node := range nodes {
+		pool := node.Pool()
+		usage, found := pools[pool]
+		if !found {
+			usage = &poolUsage{
+				allocatable: api.EmptyResource(),
+				used:        api.EmptyResource(),
+				idle:        api.EmptyResource(),
+				largestIdle: api.EmptyResource(),
+			}
+			pools[pool] = usage
+		}
+
+		usage.allocatable.Add(node.Allocatable)
+		usage.used.Add(node.Used)
+		usage.idle.Add(node.Idle)
+
+		// The nodes are aggregated by pool to bound the cardinality.
+		if len(api.NodePoolLabel) == 0 {
+			for _, rn := range api.ResourceNames() {
+				metrics.UpdateNodeAllocatedRatio(node.Name, string(rn),
+					ratio(node.Used.Get(rn), node.Allocatable.Get(rn)))
+			}
+		}
+
+		if node.Idle.MilliCPU > usage.largestIdle.MilliCPU {
+			usage.largestIdle.MilliCPU = node.Idle.MilliCPU
+		}
+		if node.Idle.Memory > usage.largestIdle.Memory {
+			usage.largestIdle.Memory = node.Idle.Memory
+		}
+		if node.Idle.GPU > usage.largestIdle.GPU {
+			usage.largestIdle.GPU = node.Idle.GPU
+		}
+	}
+
+	for pool, usage := range pools {
+		for _, rn := range api.ResourceNames() {
+			largestIdle := usage.largestIdle.Get(rn)
+			fragmentation := 0.0
+			if idle := usage.idle.Get(rn); idle > 0 {
+				fragmentation = 1 - largestIdle/idle
+			}
+
+			metrics.UpdatePoolFragmentation(pool, string(rn),
+				ratio(usage.used.Get(rn), usage.allocatable.Get(rn)), largestIdle, fragmentation)
+		}
+	}
+}
+
+// ratio returns used / total, or 0 if total is 0.
+func ratio(used, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return used / total
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+const (
+	// DefaultPercentageOfNodesToScore is the default percentage of nodes to
+	// find feasible for a task before scoring.
+	DefaultPercentageOfNodesToScore = 50
+
+	// minFeasibleNodesToFind is the minimum number of feasible nodes to find,
+	// so the percentage does not take effect in small clusters.
+	minFeasibleNodesToFind = 100
+)
+
+// PercentageOfNodesToScore is the percentage of all nodes that, once found
+// feasible for a task, the scheduler stops searching more feasible nodes and
+// scores them; 0 or 100 means all nodes.
+var PercentageOfNodesToScore int32 = DefaultPercentageOfNodesToScore
+
+// NumFeasibleNodesToFind returns the number of feasible nodes to find in
+// numAllNodes nodes by PercentageOfNodesToScore.
+func NumFeasibleNodesToFind(numAllNodes int) int {
+	if numAllNodes < minFeasibleNodesToFind || PercentageOfNodesToScore <= 0 ||
+		PercentageOfNodesToScore >= 100 {
+		return numAllNodes
+	}
+
+	numNodes := numAllNodes * int(PercentageOfNodesToScore) / 100
+	if numNodes < minFeasibleNodesToFind {
+		return minFeasibleNodesToFind
+	}
+
+	return numNodes
+}
+
+// FeasibleNodes returns at most numNodesToFind nodes which fit task by fits
+// and pass FilterNodes, searched in nodes from start; it also returns the
+// number of nodes searched. The nodes fit by fits are filtered in batches of
+// the number still needed, so the nodes filtered out, e.g. cordoned or
+// rejected by extenders, are not counted and the search goes on.
+func (ssn *Session) FeasibleNodes(task *api.TaskInfo, nodes []*api.NodeInfo, start, numNodesToFind int,
+	fits func(node *api.NodeInfo) bool, fitErrors *api.FitErrors) ([]*api.NodeInfo, int) {
+	var feasible []*api.NodeInfo
+	processed := 0
+	for processed < len(nodes) && len(feasible) < numNodesToFind {
+		var batch []*api.NodeInfo
+		for ; processed < len(nodes) && len(feasible)+len(batch) < numNodesToFind; processed++ {
+			if node := nodes[(start+processed)%len(nodes)]; fits(node) {
+				batch = append(batch, node)
+			}
+		}
+		if len(batch) != 0 {
+			feasible = append(feasible, ssn.FilterNodes(task, batch, fitErrors)...)
+		}
+	}
+	return feasible, processed
+}
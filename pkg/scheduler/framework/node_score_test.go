@@ -0,0 +1,87 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+func TestNumFeasibleNodesToFind(t *testing.T) {
+	defer func(p int32) { PercentageOfNodesToScore = p }(PercentageOfNodesToScore)
+
+	tests := []struct {
+		percentage int32
+		numNodes   int
+		expected   int
+	}{
+		{percentage: 50, numNodes: 10, expected: 10},
+		{percentage: 50, numNodes: 150, expected: 100},
+		{percentage: 50, numNodes: 2000, expected: 1000},
+		{percentage: 10, numNodes: 5000, expected: 500},
+		{percentage: 0, numNodes: 5000, expected: 5000},
+		{percentage: 100, numNodes: 5000, expected: 5000},
+	}
+
+	for i, test := range tests {
+		PercentageOfNodesToScore = test.percentage
+		if got := NumFeasibleNodesToFind(test.numNodes); got != test.expected {
+			t.Errorf("case %d: expected %d nodes of %d with %d%%, got %d",
+				i, test.expected, test.numNodes, test.percentage, got)
+		}
+	}
+}
+
+func TestFeasibleNodes(t *testing.T) {
+	// n1 and n2 fit by resources but are cordoned, n3 does not fit.
+	var nodes []*api.NodeInfo
+	for _, n := range []struct {
+		name     string
+		cordoned bool
+	}{{"n1", true}, {"n2", true}, {"n3", false}, {"n4", false}, {"n5", false}} {
+		nodes = append(nodes, api.NewNodeInfo(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: n.name},
+			Spec:       v1.NodeSpec{Unschedulable: n.cordoned},
+		}))
+	}
+	fits := func(node *api.NodeInfo) bool {
+		return node.Name != "n3"
+	}
+
+	ssn := &Session{}
+	task := api.NewTaskInfo(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p1"}})
+	fitErrors := api.NewFitErrors(task, len(nodes))
+
+	// The cordoned nodes are not counted, so the search goes on to n5.
+	feasible, processed := ssn.FeasibleNodes(task, nodes, 0, 2, fits, fitErrors)
+	if len(feasible) != 2 || feasible[0].Name != "n4" || feasible[1].Name != "n5" || processed != 5 {
+		t.Errorf("expected n4 and n5 feasible after 5 nodes, got %v after %d", feasible, processed)
+	}
+	if reasons := fitErrors.FailedNodes["n1"]; len(reasons) != 1 || reasons[0] != api.NodeUnschedulable {
+		t.Errorf("expected n1 unschedulable, got %v", reasons)
+	}
+
+	// The search stops once enough nodes are found, from start.
+	feasible, processed = ssn.FeasibleNodes(task, nodes, 3, 1, fits, api.NewFitErrors(task, len(nodes)))
+	if len(feasible) != 1 || feasible[0].Name != "n4" || processed != 1 {
+		t.Errorf("expected n4 feasible after 1 node, got %v after %d", feasible, processed)
+	}
+}
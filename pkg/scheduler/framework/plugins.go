@@ -28,3 +28,19 @@ func RegisterPluginBuilder(pc func() Plugin) {
 
 	pluginBuilders = append(pluginBuilders, pc)
 }
+
+// PluginNames returns the names of the plugins registered.
+func PluginNames() []string {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	names := make([]string, 0, len(pluginBuilders))
+	for _, pb := range pluginBuilders {
+		names = append(names, pb().Name())
+	}
+	return names
+}
+
+// DisabledPlugins is the names of the plugins not opened in sessions, e.g.
+// to evaluate a policy without a plugin by simulator.
+var DisabledPlugins map[string]bool
@@ -18,14 +18,20 @@ package framework
 
 import (
 	"fmt"
+	"time"
 
-	"github.com/golang/glog"
-
+	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/uuid"
 
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/audit"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/cache"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/metrics"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/util"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/tracing"
 )
 
 type Session struct {
@@ -39,64 +45,189 @@ type Session struct {
 	NodeIndex map[string]*api.NodeInfo
 	Backlog   []*api.JobInfo
 
+	// idleCPUIndex and idleGPUIndex are Nodes sorted by idle CPU and GPU,
+	// updated when tasks are bound or evicted.
+	idleCPUIndex *idleIndex
+	idleGPUIndex *idleIndex
+	// labelIndex is Nodes by labels, built when it's used.
+	labelIndex labelIndex
+
+	Queues     []*api.QueueInfo
+	QueueIndex map[string]*api.QueueInfo
+
+	// Action is the name of the action being executed in this session.
+	Action string
+
+	// Span is the tracing span of this session, nil if tracing is disabled.
+	Span *tracing.Span
+
+	// FitErrors is the reasons why the jobs can not be scheduled in this
+	// session, by job ID.
+	FitErrors map[api.JobID]*api.FitErrors
+
+	// Decisions is the scheduling decisions made in this session.
+	Decisions []*audit.Decision
+
+	// jobQueue is Jobs ordered by JobOrderFn, which is built once in the
+	// session and fixed incrementally when the allocation of a job changed.
+	jobQueue *util.PriorityQueue
+
 	plugins       []Plugin
 	eventHandlers []*EventHandler
-	jobOrderFns   []api.CompareFn
+	jobOrderFns   []*jobOrderFn
 	taskOrderFns  []api.CompareFn
+	nodeOrderFns  []api.NodeOrderFn
+
+	nodesFilterFns   []api.NodesFilterFn
+	nodesOrderFns    []api.NodesOrderFn
+	victimsFilterFns []api.VictimsFilterFn
+	victimsCostFns   []api.VictimsCostFn
+
+	// jobKeys is the keys of jobs by JobKeyFns, which are computed when
+	// they're compared and kept until the allocation of job changed.
+	jobKeys map[api.JobID]*jobKeys
 }
 
 func openSession(cache cache.Cache) *Session {
 	ssn := &Session{
-		ID:        uuid.NewUUID(),
-		cache:     cache,
-		JobIndex:  map[api.JobID]*api.JobInfo{},
-		NodeIndex: map[string]*api.NodeInfo{},
+		ID:         uuid.NewUUID(),
+		cache:      cache,
+		JobIndex:   map[api.JobID]*api.JobInfo{},
+		NodeIndex:  map[string]*api.NodeInfo{},
+		QueueIndex: map[string]*api.QueueInfo{},
+		FitErrors:  map[api.JobID]*api.FitErrors{},
 	}
 
 	snapshot := cache.Snapshot()
 
-	ssn.Jobs = snapshot.Jobs
-	for _, job := range ssn.Jobs {
+	ssn.Queues = snapshot.Queues
+	for _, queue := range ssn.Queues {
+		ssn.QueueIndex[queue.Name] = queue
+	}
+
+	for _, job := range snapshot.Jobs {
 		ssn.JobIndex[job.UID] = job
+
+		// The suspended jobs are kept in backlog, so they're skipped by actions.
+		if job.Suspended {
+			logging.V(3).Info("Skip suspended job", "job", job.UID, "name", job.Name, "session", ssn.ID)
+			ssn.Backlog = append(ssn.Backlog, job)
+			continue
+		}
+		// The jobs which exhausted their retries are not scheduled until
+		// their spec or pods are changed.
+		if job.RetriesExhausted() {
+			logging.V(3).Info("Skip job exhausted schedule retries", "job", job.UID, "name", job.Name,
+				"failures", job.ScheduleFailures, "session", ssn.ID)
+			ssn.Backlog = append(ssn.Backlog, job)
+			continue
+		}
+		// The jobs whose binds failed are kept in backlog until their
+		// backoff expires.
+		if time.Now().Before(job.BackoffUntil) {
+			logging.V(3).Info("Skip job backing off after bind failed", "job", job.UID, "name", job.Name,
+				"until", job.BackoffUntil, "session", ssn.ID)
+			ssn.Backlog = append(ssn.Backlog, job)
+			continue
+		}
+		// The jobs of closed queues are not started, but the started ones
+		// keep running, e.g. while the queue is drained.
+		if queue, found := ssn.QueueIndex[job.Queue]; found && queue.Closed && job.ReadyTaskNum() == 0 {
+			logging.V(3).Info("Skip job of closed queue", "job", job.UID, "name", job.Name,
+				"queue", job.Queue, "session", ssn.ID)
+			ssn.Backlog = append(ssn.Backlog, job)
+			continue
+		}
+		ssn.Jobs = append(ssn.Jobs, job)
 	}
 
 	ssn.Nodes = snapshot.Nodes
 	for _, node := range ssn.Nodes {
 		ssn.NodeIndex[node.Name] = node
 	}
+	ssn.idleCPUIndex = newIdleIndex(ssn.Nodes, idleCPU)
+	ssn.idleGPUIndex = newIdleIndex(ssn.Nodes, idleGPU)
 
 	return ssn
 }
 
 func closeSession(ssn *Session) {
+	for _, job := range ssn.JobIndex {
+		// The status of suspended jobs, and the terminal condition of the jobs
+		// which exhausted their retries, are kept as it is.
+		if !job.Suspended && !job.RetriesExhausted() {
+			ssn.updateJobStatus(job)
+		}
+	}
+
+	updateNodeMetrics(ssn.Nodes)
+
+	// The tasks in snapshot are not referenced after session closed; the
+	// index includes both jobs and backlog.
+	jobs := make([]*api.JobInfo, 0, len(ssn.JobIndex))
+	for _, job := range ssn.JobIndex {
+		jobs = append(jobs, job)
+	}
+	api.ReleaseTasks(jobs, ssn.Nodes)
+
 	ssn.Jobs = nil
 	ssn.JobIndex = nil
 	ssn.Nodes = nil
 	ssn.NodeIndex = nil
+	ssn.idleCPUIndex = nil
+	ssn.idleGPUIndex = nil
+	ssn.labelIndex = nil
 	ssn.Backlog = nil
+	ssn.Queues = nil
+	ssn.QueueIndex = nil
+	ssn.FitErrors = nil
+	ssn.Decisions = nil
+	ssn.jobQueue = nil
+	ssn.Span = nil
 	ssn.plugins = nil
 	ssn.eventHandlers = nil
 	ssn.jobOrderFns = nil
+	ssn.jobKeys = nil
+	ssn.taskOrderFns = nil
+	ssn.nodeOrderFns = nil
+	ssn.nodesFilterFns = nil
+	ssn.nodesOrderFns = nil
+	ssn.victimsFilterFns = nil
+	ssn.victimsCostFns = nil
 }
 
 func (ssn *Session) Bind(task *api.TaskInfo, hostname string) error {
 	if err := ssn.cache.Bind(task, hostname); err != nil {
+		metrics.UpdateScheduleAttempts(metrics.ErrorResult)
 		return err
 	}
+	metrics.UpdateScheduleAttempts(metrics.ScheduledResult)
+
+	ssn.recordDecision(&audit.Decision{
+		Type:      audit.BindDecision,
+		Session:   string(ssn.ID),
+		Action:    ssn.Action,
+		Job:       string(task.Job),
+		Namespace: task.Namespace,
+		Task:      task.Name,
+		Node:      hostname,
+	})
 
 	// Update status in session
 	if job, found := ssn.JobIndex[task.Job]; found {
 		job.UpdateTaskStatus(task, api.Binding)
+		updateE2eSchedulingLatency(job, task)
 	} else {
-		glog.Errorf("Failed to found Job <%s> in Session <%s> index when binding.",
-			task.Job, ssn.ID)
+		logging.Error(nil, "Failed to find job in session index when binding",
+			"job", task.Job, "task", task.UID, "session", ssn.ID)
 	}
 
 	if node, found := ssn.NodeIndex[hostname]; found {
 		node.AddTask(task)
+		ssn.updateNodeIndexes(node)
 	} else {
-		glog.Errorf("Failed to found Node <%s> in Session <%s> index when binding.",
-			hostname, ssn.ID)
+		logging.Error(nil, "Failed to find node in session index when binding",
+			"task", task.UID, "node", hostname, "session", ssn.ID)
 	}
 
 	// Callbacks
@@ -105,12 +236,211 @@ func (ssn *Session) Bind(task *api.TaskInfo, hostname string) error {
 			Task: task,
 		})
 	}
+	ssn.fixJobOrder(task.Job)
 
 	return nil
 }
 
-func (ssn *Session) Evict(task *api.TaskInfo) error {
-	return fmt.Errorf("not supported")
+// updateE2eSchedulingLatency updates the latency from creation to bind of
+// task, and of job if its minAvailable tasks are all bound by this binding.
+func updateE2eSchedulingLatency(job *api.JobInfo, task *api.TaskInfo) {
+	now := time.Now()
+
+	if task.Pod != nil {
+		metrics.UpdatePodE2eSchedulingLatency(job.Queue, len(job.Tasks),
+			now.Sub(task.Pod.CreationTimestamp.Time))
+	}
+
+	minAvailable := job.MinAvailable
+	if minAvailable == 0 {
+		minAvailable = 1
+	}
+	if job.ReadyTaskNum() == minAvailable && !job.CreationTimestamp.IsZero() {
+		metrics.UpdateJobE2eSchedulingLatency(job.Queue, len(job.Tasks),
+			now.Sub(job.CreationTimestamp.Time))
+	}
+}
+
+// JobUnschedulable records that the pending tasks of job can not be scheduled
+// because of fitErrors; it's reported by event and status when session closed.
+func (ssn *Session) JobUnschedulable(job *api.JobInfo, fitErrors *api.FitErrors) {
+	logging.V(3).Info("Job is unschedulable", "job", job.UID, "namespace", job.Namespace,
+		"name", job.Name, "session", ssn.ID, "reason", fitErrors)
+
+	ssn.FitErrors[job.UID] = fitErrors
+
+	decision := &audit.Decision{
+		Type:      audit.UnschedulableDecision,
+		Session:   string(ssn.ID),
+		Action:    ssn.Action,
+		Job:       string(job.UID),
+		Namespace: job.Namespace,
+		Reason:    fitErrors.Error(),
+	}
+	if fitErrors.Task != nil {
+		decision.Task = fitErrors.Task.Name
+	}
+	ssn.recordDecision(decision)
+}
+
+// RecordJobEvent records an event on the SchedulingSpec or PDB of job, e.g. to
+// explain a decision on it.
+func (ssn *Session) RecordJobEvent(job *api.JobInfo, eventType, reason, message string) {
+	ssn.cache.RecordJobStatusEvent(job, eventType, reason, message)
+}
+
+// recordDecision keeps the decision in this session, and writes it to the
+// audit log if enabled.
+func (ssn *Session) recordDecision(d *audit.Decision) {
+	d.Timestamp = time.Now()
+	ssn.Decisions = append(ssn.Decisions, d)
+	audit.Record(d)
+}
+
+// updateJobStatus reports whether job is scheduled or unschedulable in this
+// session by event and the conditions of its SchedulingSpec.
+func (ssn *Session) updateJobStatus(job *api.JobInfo) {
+	minAvailable := job.MinAvailable
+	if minAvailable == 0 {
+		minAvailable = 1
+	}
+
+	scheduled := arbv1.SchedulingSpecCondition{
+		Type:    arbv1.SchedulingSpecScheduled,
+		Status:  v1.ConditionTrue,
+		Reason:  api.ScheduledReason,
+		Message: fmt.Sprintf("%v/%v tasks in gang scheduled", job.ReadyTaskNum(), len(job.Tasks)),
+	}
+	if job.ReadyTaskNum() < minAvailable {
+		scheduled.Status = v1.ConditionFalse
+		scheduled.Reason = api.PendingReason
+	}
+
+	fitErrors, found := ssn.FitErrors[job.UID]
+	if !found {
+		if job.ScheduleFailures != 0 && scheduled.Status == v1.ConditionTrue {
+			ssn.cache.RecordScheduleAttempt(job, false)
+		}
+		ssn.cache.UpdateJobStatus(job, arbv1.SchedulingSpecCondition{
+			Type:   arbv1.SchedulingSpecUnschedulable,
+			Status: v1.ConditionFalse,
+		}, scheduled)
+		return
+	}
+
+	msg := fmt.Sprintf("%v/%v tasks in gang unschedulable: %v",
+		len(job.TaskStatusIndex[api.Pending]), len(job.Tasks), fitErrors)
+
+	// Only the gangs which are not started count against their retries,
+	// e.g. not a running gang whose extra tasks do not fit.
+	reason := api.UnschedulableEvent
+	if scheduled.Status == v1.ConditionFalse {
+		failures := ssn.cache.RecordScheduleAttempt(job, true)
+		if job.SchedSpec != nil && job.SchedSpec.Spec.MaxScheduleRetries > 0 &&
+			failures >= int(job.SchedSpec.Spec.MaxScheduleRetries) {
+			reason = api.RetriesExceededReason
+			msg = fmt.Sprintf("gave up after %v unschedulable attempts: %v", failures, msg)
+		}
+	} else if job.ScheduleFailures != 0 {
+		ssn.cache.RecordScheduleAttempt(job, false)
+	}
+
+	ssn.cache.RecordJobStatusEvent(job, v1.EventTypeWarning, reason, msg)
+	ssn.cache.UpdatePodsUnschedulable(job, msg)
+	ssn.cache.UpdateJobStatus(job, arbv1.SchedulingSpecCondition{
+		Type:    arbv1.SchedulingSpecUnschedulable,
+		Status:  v1.ConditionTrue,
+		Reason:  reason,
+		Message: msg,
+	}, scheduled)
+}
+
+// Evict evicts task by the reason, e.g. to preempt or reclaim its resources;
+// the task is Releasing, whose resources are still occupied until it's deleted.
+func (ssn *Session) Evict(task *api.TaskInfo, reason string) error {
+	if err := ssn.cache.Evict(task, reason); err != nil {
+		return err
+	}
+
+	// The task on node is the same object as the one of job in snapshot, so
+	// it's removed from node before its status is updated, and re-added to
+	// update the releasing resource of node.
+	node, nodeFound := ssn.NodeIndex[task.NodeName]
+	if nodeFound {
+		node.RemoveTask(task)
+	} else {
+		logging.Error(nil, "Failed to find node in session index when evicting",
+			"task", task.UID, "node", task.NodeName, "session", ssn.ID)
+	}
+
+	job, found := ssn.JobIndex[task.Job]
+	if found {
+		job.UpdateTaskStatus(task, api.Releasing)
+	} else {
+		logging.Error(nil, "Failed to find job in session index when evicting",
+			"job", task.Job, "task", task.UID, "session", ssn.ID)
+	}
+
+	if nodeFound {
+		node.AddTask(task)
+		ssn.updateNodeIndexes(node)
+	}
+
+	queue := ""
+	if job != nil {
+		queue = job.Queue
+	}
+	var runtime time.Duration
+	if task.Pod != nil && task.Pod.Status.StartTime != nil {
+		runtime = time.Since(task.Pod.Status.StartTime.Time)
+	}
+	metrics.UpdatePodEviction(ssn.Action, queue, reason, runtime)
+
+	ssn.recordDecision(&audit.Decision{
+		Type:      audit.EvictDecision,
+		Session:   string(ssn.ID),
+		Action:    ssn.Action,
+		Job:       string(task.Job),
+		Namespace: task.Namespace,
+		Task:      task.Name,
+		Node:      task.NodeName,
+		Reason:    reason,
+	})
+
+	// Callbacks
+	for _, eh := range ssn.eventHandlers {
+		if eh.EvictFunc != nil {
+			eh.EvictFunc(&Event{
+				Task: task,
+			})
+		}
+	}
+	ssn.fixJobOrder(task.Job)
+
+	return nil
+}
+
+// Nominate nominates task to the node of hostname, where the victims are
+// evicted for it; the task is still pending until it's bound after the victims
+// are deleted.
+func (ssn *Session) Nominate(task *api.TaskInfo, hostname string, victims []*api.TaskInfo) {
+	ssn.cache.NominateTask(task, hostname)
+	metrics.UpdatePreemptionVictims(len(victims))
+
+	names := make([]string, 0, len(victims))
+	for _, v := range victims {
+		names = append(names, v.Namespace+"/"+v.Name)
+	}
+	ssn.recordDecision(&audit.Decision{
+		Type:      audit.NominateDecision,
+		Session:   string(ssn.ID),
+		Action:    ssn.Action,
+		Job:       string(task.Job),
+		Namespace: task.Namespace,
+		Task:      task.Name,
+		Node:      hostname,
+		Victims:   names,
+	})
 }
 
 func (ssn *Session) ForgetJob(job *api.JobInfo) error {
@@ -119,6 +449,9 @@ func (ssn *Session) ForgetJob(job *api.JobInfo) error {
 			ssn.Backlog = append(ssn.Backlog, j)
 			ssn.Jobs[i] = ssn.Jobs[len(ssn.Jobs)-1]
 			ssn.Jobs = ssn.Jobs[:len(ssn.Jobs)-1]
+			if ssn.jobQueue != nil {
+				ssn.jobQueue.Remove(j)
+			}
 			// NOTES: did not update JobIndex, the index is used for both
 			// backlog & jobs.
 		}
@@ -127,30 +460,99 @@ func (ssn *Session) ForgetJob(job *api.JobInfo) error {
 	return nil
 }
 
+// JobQueue returns a priority queue of Jobs ordered by JobOrderFn; it's a
+// copy of the queue kept in session, so it's built in O(n) instead of sorting
+// all jobs in every action. It has to be called after plugins opened.
+func (ssn *Session) JobQueue() *util.PriorityQueue {
+	if ssn.jobQueue == nil {
+		jobs := make([]interface{}, 0, len(ssn.Jobs))
+		for _, job := range ssn.Jobs {
+			jobs = append(jobs, job)
+		}
+		ssn.jobQueue = util.NewPriorityQueueFrom(ssn.JobOrderFn, jobs)
+	}
+
+	return ssn.jobQueue.Clone()
+}
+
+// fixJobOrder re-orders the job in the queue of session after its allocation
+// changed, e.g. its share is changed by binding; its keys are re-computed
+// when it's compared.
+func (ssn *Session) fixJobOrder(id api.JobID) {
+	delete(ssn.jobKeys, id)
+
+	if ssn.jobQueue == nil {
+		return
+	}
+
+	if job, found := ssn.JobIndex[id]; found {
+		ssn.jobQueue.Fix(job)
+	}
+}
+
 func (ssn *Session) AddEventHandler(eh *EventHandler) {
 	ssn.eventHandlers = append(ssn.eventHandlers, eh)
 }
 
 func (ssn *Session) AddJobOrderFn(cf api.CompareFn) {
-	ssn.jobOrderFns = append(ssn.jobOrderFns, cf)
+	ssn.jobOrderFns = append(ssn.jobOrderFns, &jobOrderFn{compareFn: cf})
+}
+
+// AddJobKeyFn adds the func ordering jobs by key; it's cheaper than the
+// CompareFn of the same order, as the key of each job is computed once until
+// the tasks of job are bound or evicted, which is the only time the key may
+// change.
+func (ssn *Session) AddJobKeyFn(kf api.JobKeyFn) {
+	ssn.jobOrderFns = append(ssn.jobOrderFns, &jobOrderFn{keyFn: kf})
 }
 
 func (ssn *Session) AddTaskOrderFn(cf api.CompareFn) {
 	ssn.taskOrderFns = append(ssn.taskOrderFns, cf)
 }
 
-func (ssn *Session) JobOrderFn(l, r interface{}) bool {
-	for _, jof := range ssn.jobOrderFns {
-		if j := jof(l, r); j != 0 {
-			return j < 0
-		}
-	}
+func (ssn *Session) AddNodeOrderFn(nof api.NodeOrderFn) {
+	ssn.nodeOrderFns = append(ssn.nodeOrderFns, nof)
+}
 
-	// If no job order funcs, order job by UID.
+func (ssn *Session) AddNodesFilterFn(nff api.NodesFilterFn) {
+	ssn.nodesFilterFns = append(ssn.nodesFilterFns, nff)
+}
+
+func (ssn *Session) AddNodesOrderFn(nof api.NodesOrderFn) {
+	ssn.nodesOrderFns = append(ssn.nodesOrderFns, nof)
+}
+
+func (ssn *Session) AddVictimsFilterFn(vff api.VictimsFilterFn) {
+	ssn.victimsFilterFns = append(ssn.victimsFilterFns, vff)
+}
+
+func (ssn *Session) AddVictimsCostFn(vcf api.VictimsCostFn) {
+	ssn.victimsCostFns = append(ssn.victimsCostFns, vcf)
+}
+
+// JobOrderFn orders jobs by the job order funcs in the order they're added;
+// the later funcs, and the keys of them, are not evaluated once an earlier one
+// decides the order.
+func (ssn *Session) JobOrderFn(l, r interface{}) bool {
 	lv := l.(*api.JobInfo)
 	rv := r.(*api.JobInfo)
 
-	return lv.UID < rv.UID
+	for i, jof := range ssn.jobOrderFns {
+		if jof.compareFn != nil {
+			if j := jof.compareFn(l, r); j != 0 {
+				return j < 0
+			}
+			continue
+		}
+
+		if lk, rk := ssn.jobKey(lv, i), ssn.jobKey(rv, i); lk != rk {
+			return lk < rk
+		}
+	}
+
+	// If no job order funcs or tie, order job by creation time, then UID, so
+	// the order is the same in every session.
+	return api.JobCreatedBefore(lv, rv)
 }
 
 func (ssn *Session) TaskOrderFn(l, r interface{}) bool {
@@ -160,9 +562,196 @@ func (ssn *Session) TaskOrderFn(l, r interface{}) bool {
 		}
 	}
 
-	// If no task order funcs, order task by UID.
-	lv := l.(*api.TaskInfo)
-	rv := r.(*api.TaskInfo)
+	// If no task order funcs or tie, order task by creation time, then UID.
+	return api.TaskCreatedBefore(l.(*api.TaskInfo), r.(*api.TaskInfo))
+}
+
+// NodeOrderFn returns the score of node for task, which is the sum of the
+// scores by plugins; it's 0 if no node order funcs.
+func (ssn *Session) NodeOrderFn(task *api.TaskInfo, node *api.NodeInfo) float64 {
+	score := 0.0
+	for _, nof := range ssn.nodeOrderFns {
+		score += nof(task, node)
+	}
+
+	return score
+}
+
+// FilterNodes returns the schedulable nodes which pass the volume binding
+// check and all nodes filter funcs for task; the reasons of the other nodes
+// are set in fitErrors. If a func fails, all nodes are taken as failed by its
+// error.
+func (ssn *Session) FilterNodes(task *api.TaskInfo, nodes []*api.NodeInfo, fitErrors *api.FitErrors) []*api.NodeInfo {
+	// The unschedulable nodes, e.g. cordoned, under pressure or failed, are
+	// filtered first, so they're not sent to extenders.
+	toleratesCordon := api.ToleratesCordon(task.Pod)
+	schedulable := make([]*api.NodeInfo, 0, len(nodes))
+	for _, node := range nodes {
+		if node.Unschedulable {
+			fitErrors.SetNodeError(node.Name, api.NodeHeartbeatStale)
+			continue
+		}
+		if node.Cordoned() && !toleratesCordon {
+			fitErrors.SetNodeError(node.Name, api.NodeUnschedulable)
+			continue
+		}
+		if reasons := node.PressureReasons(task.Pod); len(reasons) != 0 {
+			fitErrors.SetNodeError(node.Name, reasons...)
+			continue
+		}
+		if reasons := node.FailureReasons(task.Pod); len(reasons) != 0 {
+			fitErrors.SetNodeError(node.Name, reasons...)
+			continue
+		}
+		schedulable = append(schedulable, node)
+	}
+	nodes = schedulable
+
+	// The volumes are checked before the filter funcs, e.g. extenders, as
+	// they're checked in cache without API calls.
+	if hasVolumeClaims(task.Pod) {
+		passed := make([]*api.NodeInfo, 0, len(nodes))
+		for _, node := range nodes {
+			if reasons := ssn.cache.CheckVolumeBinding(task, node); len(reasons) != 0 {
+				fitErrors.SetNodeError(node.Name, reasons...)
+				continue
+			}
+			passed = append(passed, node)
+		}
+		nodes = passed
+	}
+
+	for _, nff := range ssn.nodesFilterFns {
+		if len(nodes) == 0 {
+			break
+		}
+
+		passed, failed, err := nff(task, nodes)
+		if err != nil {
+			logging.Error(err, "Failed to filter nodes", "job", task.Job, "task", task.UID, "session", ssn.ID)
+			for _, node := range nodes {
+				fitErrors.SetNodeError(node.Name, err.Error())
+			}
+			return nil
+		}
+
+		passedNodes := make(map[string]bool, len(passed))
+		for _, node := range passed {
+			passedNodes[node.Name] = true
+		}
+		for _, node := range nodes {
+			if passedNodes[node.Name] {
+				continue
+			}
+			if reason, found := failed[node.Name]; found && len(reason) != 0 {
+				fitErrors.SetNodeError(node.Name, reason)
+			} else {
+				fitErrors.SetNodeError(node.Name, api.NodeFilteredOut)
+			}
+		}
+
+		nodes = passed
+	}
+
+	return nodes
+}
+
+// ScoreNodes returns the scores of nodes for task by node name, which are
+// the sum of NodeOrderFn and nodes order funcs; the failed funcs are skipped.
+func (ssn *Session) ScoreNodes(task *api.TaskInfo, nodes []*api.NodeInfo) map[string]float64 {
+	scores := make(map[string]float64, len(nodes))
+	for _, node := range nodes {
+		scores[node.Name] = ssn.NodeOrderFn(task, node)
+	}
+
+	for _, nof := range ssn.nodesOrderFns {
+		nodeScores, err := nof(task, nodes)
+		if err != nil {
+			logging.Error(err, "Failed to score nodes", "job", task.Job, "task", task.UID, "session", ssn.ID)
+			continue
+		}
+		for name, score := range nodeScores {
+			if _, found := scores[name]; found {
+				scores[name] += score
+			}
+		}
+	}
+
+	return scores
+}
+
+// FilterVictims returns the victims to evict for task by node name, which are
+// accepted by all victims filter funcs; the nodes not returned can not be
+// preempted for task.
+func (ssn *Session) FilterVictims(task *api.TaskInfo, victims map[string][]*api.TaskInfo) (map[string][]*api.TaskInfo, error) {
+	for _, vff := range ssn.victimsFilterFns {
+		if len(victims) == 0 {
+			break
+		}
+
+		var err error
+		if victims, err = vff(task, victims); err != nil {
+			return nil, err
+		}
+	}
 
-	return lv.UID < rv.UID
+	return victims, nil
+}
+
+// VictimsCost returns the cost of evicting victims for task, which is the sum
+// of victims cost funcs.
+func (ssn *Session) VictimsCost(task *api.TaskInfo, victims []*api.TaskInfo) float64 {
+	cost := 0.0
+	for _, vcf := range ssn.victimsCostFns {
+		cost += vcf(task, victims)
+	}
+	return cost
+}
+
+// jobOrderFn is a func ordering jobs, either by comparing them or by the key
+// of each job.
+type jobOrderFn struct {
+	compareFn api.CompareFn
+	keyFn     api.JobKeyFn
+}
+
+// jobKeys is the keys of a job by the index of jobOrderFns; computed[i] is
+// whether keys[i] is computed.
+type jobKeys struct {
+	keys     []float64
+	computed []bool
+}
+
+// jobKey returns the key of job by the i-th job order func, computing it if
+// it's not computed since the allocation of job changed.
+func (ssn *Session) jobKey(job *api.JobInfo, i int) float64 {
+	if ssn.jobKeys == nil {
+		ssn.jobKeys = map[api.JobID]*jobKeys{}
+	}
+
+	jk, found := ssn.jobKeys[job.UID]
+	if !found {
+		jk = &jobKeys{
+			keys:     make([]float64, len(ssn.jobOrderFns)),
+			computed: make([]bool, len(ssn.jobOrderFns)),
+		}
+		ssn.jobKeys[job.UID] = jk
+	}
+
+	if !jk.computed[i] {
+		jk.keys[i] = ssn.jobOrderFns[i].keyFn(job)
+		jk.computed[i] = true
+	}
+
+	return jk.keys[i]
+}
+
+// hasVolumeClaims returns whether pod has volumes of PVCs.
+func hasVolumeClaims(pod *v1.Pod) bool {
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim != nil {
+			return true
+		}
+	}
+	return false
 }
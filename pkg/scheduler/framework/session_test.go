@@ -0,0 +1,122 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+func TestJobOrderFnByKeys(t *testing.T) {
+	j1 := api.NewJobInfo("j1")
+	j2 := api.NewJobInfo("j2")
+	j3 := api.NewJobInfo("j3")
+
+	ssn := &Session{
+		JobIndex: map[api.JobID]*api.JobInfo{"j1": j1, "j2": j2, "j3": j3},
+	}
+
+	shares := map[api.JobID]float64{"j1": 0.5, "j2": 0.2, "j3": 0.5}
+	priorities := map[api.JobID]float64{"j1": 2, "j2": 1, "j3": 1}
+	shareCalls, priorityCalls := 0, 0
+
+	ssn.AddJobKeyFn(func(job *api.JobInfo) float64 {
+		shareCalls++
+		return shares[job.UID]
+	})
+	ssn.AddJobKeyFn(func(job *api.JobInfo) float64 {
+		priorityCalls++
+		return priorities[job.UID]
+	})
+
+	if !ssn.JobOrderFn(j2, j1) || ssn.JobOrderFn(j1, j2) {
+		t.Errorf("expected j2 with lower share ordered before j1")
+	}
+	if priorityCalls != 0 {
+		t.Errorf("expected priority not evaluated when share decides, got %d calls", priorityCalls)
+	}
+	if !ssn.JobOrderFn(j3, j1) {
+		t.Errorf("expected j3 with lower priority ordered before j1 of the same share")
+	}
+	if shareCalls != 3 || priorityCalls != 2 {
+		t.Errorf("expected each key evaluated once per job, got %d share and %d priority calls",
+			shareCalls, priorityCalls)
+	}
+
+	// The keys of j1 are re-computed after its allocation changed.
+	shares["j1"] = 0.1
+	ssn.fixJobOrder("j1")
+	if !ssn.JobOrderFn(j1, j2) {
+		t.Errorf("expected j1 with lower share ordered before j2 after its share changed")
+	}
+	if shareCalls != 4 {
+		t.Errorf("expected the share of j1 re-computed only, got %d share calls", shareCalls)
+	}
+}
+
+func TestJobOrderFnTieBreak(t *testing.T) {
+	now := time.Now()
+	j1 := api.NewJobInfo("j1")
+	j1.CreationTimestamp = metav1.NewTime(now)
+	j2 := api.NewJobInfo("j2")
+	j2.CreationTimestamp = metav1.NewTime(now.Add(-time.Minute))
+	j3 := api.NewJobInfo("j3")
+	j3.CreationTimestamp = metav1.NewTime(now)
+
+	ssn := &Session{
+		JobIndex: map[api.JobID]*api.JobInfo{"j1": j1, "j2": j2, "j3": j3},
+	}
+	ssn.AddJobKeyFn(func(job *api.JobInfo) float64 {
+		return 0.5
+	})
+
+	if !ssn.JobOrderFn(j2, j1) || ssn.JobOrderFn(j1, j2) {
+		t.Errorf("expected j2 created earlier ordered before j1 of the same share")
+	}
+	if !ssn.JobOrderFn(j1, j3) || ssn.JobOrderFn(j3, j1) {
+		t.Errorf("expected j1 of smaller UID ordered before j3 created at the same time")
+	}
+}
+
+func TestFilterCordonedNodes(t *testing.T) {
+	n1 := api.NewNodeInfo(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1"}})
+	n2 := api.NewNodeInfo(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n2"}, Spec: v1.NodeSpec{Unschedulable: true}})
+	nodes := []*api.NodeInfo{n1, n2}
+	ssn := &Session{}
+
+	task := api.NewTaskInfo(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p1"}})
+	fitErrors := api.NewFitErrors(task, len(nodes))
+	if passed := ssn.FilterNodes(task, nodes, fitErrors); len(passed) != 1 || passed[0].Name != "n1" {
+		t.Errorf("expected the cordoned node filtered out, got %v", passed)
+	}
+	if reasons := fitErrors.FailedNodes["n2"]; len(reasons) != 1 || reasons[0] != api.NodeUnschedulable {
+		t.Errorf("expected the cordoned node unschedulable, got %v", reasons)
+	}
+
+	// The pod tolerating the taint of cordoned nodes is placed on them.
+	task.Pod.Spec.Tolerations = []v1.Toleration{
+		{Key: api.NodeUnschedulableTaint, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+	}
+	if passed := ssn.FilterNodes(task, nodes, api.NewFitErrors(task, len(nodes))); len(passed) != 2 {
+		t.Errorf("expected the cordoned node passed by toleration, got %v", passed)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// livenessIntervals is the number of maximum session intervals without any
+// completed session, after which the scheduler is taken as not alive.
+const livenessIntervals = 30
+
+// markSynced records that the cache is synced and the scheduling loop starts.
+func (pc *Scheduler) markSynced() {
+	pc.healthMutex.Lock()
+	defer pc.healthMutex.Unlock()
+
+	pc.synced = true
+	pc.lastSession = time.Now()
+}
+
+// markSessionCompleted records that a scheduling session completes.
+func (pc *Scheduler) markSessionCompleted() {
+	pc.healthMutex.Lock()
+	defer pc.healthMutex.Unlock()
+
+	pc.lastSession = time.Now()
+}
+
+// checkReady returns an error if the cache is not synced; the scheduler
+// without leadership is also not ready, as it does not schedule even if its
+// cache is warmed up on standby.
+func (pc *Scheduler) checkReady() error {
+	pc.healthMutex.Lock()
+	defer pc.healthMutex.Unlock()
+
+	if !pc.synced {
+		return fmt.Errorf("cache is not synced")
+	}
+	return nil
+}
+
+// checkAlive returns an error if no session completed in livenessIntervals
+// session intervals after the scheduling loop starts.
+func (pc *Scheduler) checkAlive() error {
+	pc.healthMutex.Lock()
+	defer pc.healthMutex.Unlock()
+
+	if !pc.synced {
+		return nil
+	}
+
+	if since := time.Since(pc.lastSession); since > livenessIntervals*sessionInterval() {
+		return fmt.Errorf("no scheduling session completed in %v", since)
+	}
+	return nil
+}
+
+// HealthzHandler returns the HTTP handler of liveness probe.
+func (pc *Scheduler) HealthzHandler() http.Handler {
+	return healthHandler(pc.checkAlive)
+}
+
+// ReadyzHandler returns the HTTP handler of readiness probe.
+func (pc *Scheduler) ReadyzHandler() http.Handler {
+	return healthHandler(pc.checkReady)
+}
+
+func healthHandler(check func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := check(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	})
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"time"
+)
+
+// The metrics are named as kube-scheduler, so its dashboards can be reused.
+const schedulerSubsystem = "scheduler"
+
+const (
+	// ScheduledResult is the result of the task bound to a host.
+	ScheduledResult = "scheduled"
+	// UnschedulableResult is the result of the task without enough resources.
+	UnschedulableResult = "unschedulable"
+	// ErrorResult is the result of the task failed to bind.
+	ErrorResult = "error"
+
+	// OnSessionOpen is the label of plugin latency when session opened.
+	OnSessionOpen = "OnSessionOpen"
+	// OnSessionClose is the label of plugin latency when session closed.
+	OnSessionClose = "OnSessionClose"
+)
+
+var latencyBuckets = exponentialBuckets(1000, 2, 15)
+
+// e2eLatencyBuckets are the buckets of the latency from creation to bind in
+// seconds, which includes the time waiting for the gang; from 0.1s to ~55min.
+var e2eLatencyBuckets = exponentialBuckets(0.1, 2, 16)
+
+// runtimeBuckets are the buckets of the runtime of evicted tasks in seconds;
+// from 1s to ~6 days.
+var runtimeBuckets = exponentialBuckets(1, 2, 20)
+
+var (
+	e2eSchedulingLatency = NewHistogramVec(
+		schedulerSubsystem+"_e2e_scheduling_latency_microseconds",
+		"E2e scheduling latency (scheduling session)",
+		latencyBuckets,
+	)
+
+	actionSchedulingLatency = NewHistogramVec(
+		schedulerSubsystem+"_action_scheduling_latency_microseconds",
+		"Action scheduling latency",
+		latencyBuckets,
+		"action",
+	)
+
+	pluginSchedulingLatency = NewHistogramVec(
+		schedulerSubsystem+"_plugin_scheduling_latency_microseconds",
+		"Plugin scheduling latency",
+		latencyBuckets,
+		"plugin", "OnSession",
+	)
+
+	bindingLatency = NewHistogramVec(
+		schedulerSubsystem+"_binding_latency_microseconds",
+		"Binding latency",
+		latencyBuckets,
+	)
+
+	scheduleAttempts = NewCounterVec(
+		schedulerSubsystem+"_schedule_attempts_total",
+		"Number of attempts to schedule tasks, by the result. 'unschedulable' means a task could not be scheduled, while 'error' means an internal scheduler problem.",
+		"result",
+	)
+
+	preemptionAttempts = NewCounterVec(
+		schedulerSubsystem+"_total_preemption_attempts",
+		"Total preemption attempts in the cluster till now",
+	)
+
+	preemptionVictims = NewCounterVec(
+		schedulerSubsystem+"_pod_preemption_victims",
+		"Number of selected preemption victims",
+	)
+
+	podE2eSchedulingLatency = NewHistogramVec(
+		schedulerSubsystem+"_pod_e2e_scheduling_latency_seconds",
+		"Latency from pod creation to bind, by the queue and size of its job",
+		e2eLatencyBuckets,
+		"queue", "job_size",
+	)
+
+	jobE2eSchedulingLatency = NewHistogramVec(
+		schedulerSubsystem+"_job_e2e_scheduling_latency_seconds",
+		"Latency from job admission to its minAvailable tasks bound, by the queue and size of job",
+		e2eLatencyBuckets,
+		"queue", "job_size",
+	)
+
+	podEvictions = NewCounterVec(
+		schedulerSubsystem+"_pod_evictions_total",
+		"Number of evicted tasks, by the action, queue of the task and the reason",
+		"action", "queue", "reason",
+	)
+
+	evictedPodRuntime = NewHistogramVec(
+		schedulerSubsystem+"_evicted_pod_runtime_seconds",
+		"Runtime of the tasks when evicted, which estimates the wasted work, by the action and queue of the task",
+		runtimeBuckets,
+		"action", "queue",
+	)
+
+	queueDeserved = NewGaugeVec(
+		schedulerSubsystem+"_queue_deserved",
+		"Deserved resources of the queue divided by weight; cpu in millicores and memory in bytes",
+		"queue", "resource",
+	)
+
+	queueAllocated = NewGaugeVec(
+		schedulerSubsystem+"_queue_allocated",
+		"Allocated resources of the queue; cpu in millicores and memory in bytes",
+		"queue", "resource",
+	)
+
+	queueRequest = NewGaugeVec(
+		schedulerSubsystem+"_queue_request",
+		"Requested resources of the allocated and pending tasks in the queue; cpu in millicores and memory in bytes",
+		"queue", "resource",
+	)
+
+	nodeAllocatedRatio = NewGaugeVec(
+		schedulerSubsystem+"_node_allocated_ratio",
+		"Ratio of allocated to allocatable resources of the node",
+		"node", "resource",
+	)
+
+	poolAllocatedRatio = NewGaugeVec(
+		schedulerSubsystem+"_pool_allocated_ratio",
+		"Ratio of allocated to allocatable resources of the node pool",
+		"pool", "resource",
+	)
+
+	poolLargestIdle = NewGaugeVec(
+		schedulerSubsystem+"_pool_largest_idle",
+		"Largest idle resources on a single node of the pool, which is the largest pod that can be scheduled; cpu in millicores and memory in bytes",
+		"pool", "resource",
+	)
+
+	poolFragmentation = NewGaugeVec(
+		schedulerSubsystem+"_pool_fragmentation",
+		"Fragmentation of idle resources in the node pool, 1 - largest idle on a node / total idle; 0 means all idle resources are on one node",
+		"pool", "resource",
+	)
+
+	pendingAPIWrites = NewGaugeVec(
+		schedulerSubsystem+"_pending_api_writes",
+		"Number of API writes waiting to be dispatched, by the kind of write",
+		"kind",
+	)
+
+	sessionTriggers = NewCounterVec(
+		schedulerSubsystem+"_session_triggers_total",
+		"Number of events triggering scheduling sessions, by the reason; the events are coalesced into sessions",
+		"reason",
+	)
+
+	leakedJobs = NewGaugeVec(
+		schedulerSubsystem+"_leaked_jobs",
+		"Number of jobs in scheduler cache whose owner was deleted",
+	)
+)
+
+// UpdateE2eSchedulingLatency updates the latency of a scheduling session.
+func UpdateE2eSchedulingLatency(duration time.Duration) {
+	e2eSchedulingLatency.Observe(DurationInMicroseconds(duration))
+}
+
+// UpdateActionLatency updates the latency of the action.
+func UpdateActionLatency(action string, duration time.Duration) {
+	actionSchedulingLatency.Observe(DurationInMicroseconds(duration), action)
+}
+
+// UpdatePluginLatency updates the latency of the plugin when session opened
+// or closed.
+func UpdatePluginLatency(plugin, onSession string, duration time.Duration) {
+	pluginSchedulingLatency.Observe(DurationInMicroseconds(duration), plugin, onSession)
+}
+
+// UpdateBindingLatency updates the latency of binding a task to host.
+func UpdateBindingLatency(duration time.Duration) {
+	bindingLatency.Observe(DurationInMicroseconds(duration))
+}
+
+// UpdateScheduleAttempts increases the schedule attempts of result.
+func UpdateScheduleAttempts(result string) {
+	scheduleAttempts.Inc(result)
+}
+
+// UpdatePodE2eSchedulingLatency updates the latency from pod creation to bind.
+func UpdatePodE2eSchedulingLatency(queue string, jobSize int, duration time.Duration) {
+	podE2eSchedulingLatency.Observe(duration.Seconds(), queue, JobSizeLabel(jobSize))
+}
+
+// UpdateJobE2eSchedulingLatency updates the latency from job admission to its
+// minAvailable tasks bound.
+func UpdateJobE2eSchedulingLatency(queue string, jobSize int, duration time.Duration) {
+	jobE2eSchedulingLatency.Observe(duration.Seconds(), queue, JobSizeLabel(jobSize))
+}
+
+// JobSizeLabel returns the label of job size by its number of tasks, so the
+// cardinality of the metrics is bounded.
+func JobSizeLabel(size int) string {
+	switch {
+	case size <= 1:
+		return "1"
+	case size <= 4:
+		return "2-4"
+	case size <= 16:
+		return "5-16"
+	case size <= 64:
+		return "17-64"
+	default:
+		return "65+"
+	}
+}
+
+// UpdatePreemptionVictims increases the preemption attempts, and adds the
+// number of evicted tasks as victims.
+func UpdatePreemptionVictims(victims int) {
+	preemptionAttempts.Inc()
+	preemptionVictims.Add(float64(victims))
+}
+
+// UpdatePodEviction increases the evictions of action, queue and reason, and
+// observes the runtime of the evicted task.
+func UpdatePodEviction(action, queue, reason string, runtime time.Duration) {
+	podEvictions.Inc(action, queue, reason)
+	evictedPodRuntime.Observe(runtime.Seconds(), action, queue)
+}
+
+// UpdateQueueShare sets the deserved, allocated and requested resource of
+// the queue.
+func UpdateQueueShare(queue, resource string, deserved, allocated, request float64) {
+	queueDeserved.Set(deserved, queue, resource)
+	queueAllocated.Set(allocated, queue, resource)
+	queueRequest.Set(request, queue, resource)
+}
+
+// ResetQueueShares deletes the shares of all queues, so the deleted queues are
+// not exported.
+func ResetQueueShares() {
+	queueDeserved.Reset()
+	queueAllocated.Reset()
+	queueRequest.Reset()
+}
+
+// UpdateNodeAllocatedRatio sets the allocated ratio of resource on node.
+func UpdateNodeAllocatedRatio(node, resource string, ratio float64) {
+	nodeAllocatedRatio.Set(ratio, node, resource)
+}
+
+// UpdatePoolFragmentation sets the allocated ratio, largest idle and
+// fragmentation of resource in the node pool.
+func UpdatePoolFragmentation(pool, resource string, ratio, largestIdle, fragmentation float64) {
+	poolAllocatedRatio.Set(ratio, pool, resource)
+	poolLargestIdle.Set(largestIdle, pool, resource)
+	poolFragmentation.Set(fragmentation, pool, resource)
+}
+
+// ResetNodeMetrics deletes the metrics of all nodes and pools, so the deleted
+// ones are not exported.
+func ResetNodeMetrics() {
+	nodeAllocatedRatio.Reset()
+	poolAllocatedRatio.Reset()
+	poolLargestIdle.Reset()
+	poolFragmentation.Reset()
+}
+
+// UpdatePendingAPIWrites adds delta to the pending API writes of kind.
+func UpdatePendingAPIWrites(kind string, delta float64) {
+	pendingAPIWrites.Add(delta, kind)
+}
+
+// UpdateSessionTriggers increases the events triggering sessions of reason.
+func UpdateSessionTriggers(reason string) {
+	sessionTriggers.Inc(reason)
+}
+
+// UpdateLeakedJobs sets the number of leaked jobs in scheduler cache.
+func UpdateLeakedJobs(count int) {
+	leakedJobs.Set(float64(count))
+}
+
+// DurationInMicroseconds gets the time in microseconds.
+func DurationInMicroseconds(duration time.Duration) float64 {
+	return float64(duration.Nanoseconds()) / float64(time.Microsecond.Nanoseconds())
+}
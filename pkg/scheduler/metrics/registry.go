@@ -0,0 +1,301 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The metrics are exposed in Prometheus text format (version 0.0.4).
+const contentType = "text/plain; version=0.0.4"
+
+// collector is a metric family which writes its samples in text format.
+type collector interface {
+	write(w io.Writer)
+}
+
+var (
+	registryMutex sync.Mutex
+	registry      []collector
+)
+
+func register(c collector) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	registry = append(registry, c)
+}
+
+// Handler returns the HTTP handler which exposes all metrics.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		WriteTo(w)
+	})
+}
+
+// WriteTo writes all metrics in text format into w.
+func WriteTo(w io.Writer) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	for _, c := range registry {
+		c.write(w)
+	}
+}
+
+// exponentialBuckets returns count buckets, the first one is start, and each
+// one is factor times of previous.
+func exponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+	return buckets
+}
+
+// series is the samples of a metric with the same label values.
+type series struct {
+	labelValues []string
+
+	value float64
+
+	// for histogram
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// metricVec is the metric family partitioned by labels.
+type metricVec struct {
+	sync.Mutex
+
+	name       string
+	help       string
+	metricType string
+	labelNames []string
+	buckets    []float64
+
+	series map[string]*series
+}
+
+func newMetricVec(name, help, metricType string, buckets []float64, labelNames []string) *metricVec {
+	v := &metricVec{
+		name:       name,
+		help:       help,
+		metricType: metricType,
+		labelNames: labelNames,
+		buckets:    buckets,
+		series:     map[string]*series{},
+	}
+	register(v)
+	return v
+}
+
+// Assumes that lock is already acquired.
+func (v *metricVec) get(labelValues []string) *series {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d",
+			v.name, len(v.labelNames), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	s, found := v.series[key]
+	if !found {
+		s = &series{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(v.buckets)),
+		}
+		v.series[key] = s
+	}
+
+	return s
+}
+
+func (v *metricVec) write(w io.Writer) {
+	v.Lock()
+	defer v.Unlock()
+
+	if len(v.series) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, escapeHelp(v.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.name, v.metricType)
+
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := v.series[key]
+		if v.metricType != "histogram" {
+			fmt.Fprintf(w, "%s%s %s\n", v.name, v.labels(s, "", 0), formatFloat(s.value))
+			continue
+		}
+
+		cumulative := uint64(0)
+		for i, bound := range v.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, v.labels(s, "le", bound), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, v.labels(s, "le", math.Inf(1)), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", v.name, v.labels(s, "", 0), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", v.name, v.labels(s, "", 0), s.count)
+	}
+}
+
+// labels returns the labels of series in text format, with the extra label
+// (e.g. "le" of histogram bucket) if it's not empty.
+func (v *metricVec) labels(s *series, extraName string, extraValue float64) string {
+	if len(v.labelNames) == 0 && len(extraName) == 0 {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range v.labelNames {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, "%s=\"%s\"", name, escapeLabelValue(s.labelValues[i]))
+	}
+	if len(extraName) != 0 {
+		if len(v.labelNames) != 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, "%s=\"%s\"", extraName, formatFloat(extraValue))
+	}
+	buf.WriteByte('}')
+
+	return buf.String()
+}
+
+// CounterVec is a counter partitioned by labels.
+type CounterVec struct {
+	vec *metricVec
+}
+
+// NewCounterVec creates and registers a CounterVec.
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{vec: newMetricVec(name, help, "counter", nil, labelNames)}
+}
+
+// Add adds delta to the counter of the label values.
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	c.vec.Lock()
+	defer c.vec.Unlock()
+
+	c.vec.get(labelValues).value += delta
+}
+
+// Inc increases the counter of the label values by 1.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// GaugeVec is a gauge partitioned by labels.
+type GaugeVec struct {
+	vec *metricVec
+}
+
+// NewGaugeVec creates and registers a GaugeVec.
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{vec: newMetricVec(name, help, "gauge", nil, labelNames)}
+}
+
+// Set sets the gauge of the label values.
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.vec.Lock()
+	defer g.vec.Unlock()
+
+	g.vec.get(labelValues).value = value
+}
+
+// Add adds delta to the gauge of the label values.
+func (g *GaugeVec) Add(delta float64, labelValues ...string) {
+	g.vec.Lock()
+	defer g.vec.Unlock()
+
+	g.vec.get(labelValues).value += delta
+}
+
+// Reset deletes all series of the gauge, e.g. of the deleted objects.
+func (g *GaugeVec) Reset() {
+	g.vec.Lock()
+	defer g.vec.Unlock()
+
+	g.vec.series = map[string]*series{}
+}
+
+// HistogramVec is a histogram partitioned by labels.
+type HistogramVec struct {
+	vec *metricVec
+}
+
+// NewHistogramVec creates and registers a HistogramVec with the upper bounds
+// of buckets in increasing order.
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	return &HistogramVec{vec: newMetricVec(name, help, "histogram", buckets, labelNames)}
+}
+
+// Observe adds an observation of the label values.
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.vec.Lock()
+	defer h.vec.Unlock()
+
+	s := h.vec.get(labelValues)
+	for i, bound := range h.vec.buckets {
+		if value <= bound {
+			s.counts[i]++
+			break
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMetricVecWrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		update   func() *metricVec
+		expected string
+	}{
+		{
+			name: "counter with labels",
+			update: func() *metricVec {
+				c := &CounterVec{vec: &metricVec{
+					name: "test_total", help: "Test counter", metricType: "counter",
+					labelNames: []string{"result"}, series: map[string]*series{},
+				}}
+				c.Inc("scheduled")
+				c.Add(2, "error")
+				c.Inc("scheduled")
+				return c.vec
+			},
+			expected: `# HELP test_total Test counter
+# TYPE test_total counter
+test_total{result="error"} 2
+test_total{result="scheduled"} 2
+`,
+		},
+		{
+			name: "histogram without labels",
+			update: func() *metricVec {
+				h := &HistogramVec{vec: &metricVec{
+					name: "test_latency", help: "Test histogram", metricType: "histogram",
+					buckets: exponentialBuckets(1, 2, 3), series: map[string]*series{},
+				}}
+				h.Observe(0.5)
+				h.Observe(3)
+				h.Observe(10)
+				return h.vec
+			},
+			expected: `# HELP test_latency Test histogram
+# TYPE test_latency histogram
+test_latency_bucket{le="1"} 1
+test_latency_bucket{le="2"} 1
+test_latency_bucket{le="4"} 2
+test_latency_bucket{le="+Inf"} 3
+test_latency_sum 13.5
+test_latency_count 3
+`,
+		},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		test.update().write(&buf)
+
+		if buf.String() != test.expected {
+			t.Errorf("case <%s>: expected\n%s\ngot\n%s", test.name, test.expected, buf.String())
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/audit"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+// PlanFile is the file which the plan of each session is written into
+// before its binds and evictions are executed, e.g. for external approval or
+// debugging preemptions; no plan is emitted if empty.
+var PlanFile string
+
+// Plan is the assignments and evictions decided by a session.
+type Plan struct {
+	Session   string    `json:"session"`
+	Timestamp time.Time `json:"timestamp"`
+
+	// Binds is the tasks assigned to nodes.
+	Binds []*audit.Decision `json:"binds"`
+	// Evictions is the victims evicted, e.g. to preempt or reclaim.
+	Evictions []*audit.Decision `json:"evictions"`
+	// Nominations is the tasks nominated to the nodes whose victims are
+	// evicted for them.
+	Nominations []*audit.Decision `json:"nominations"`
+}
+
+// newPlan returns the plan of the decisions of ssn.
+func newPlan(ssn *framework.Session) *Plan {
+	plan := &Plan{
+		Session:     string(ssn.ID),
+		Timestamp:   time.Now(),
+		Binds:       []*audit.Decision{},
+		Evictions:   []*audit.Decision{},
+		Nominations: []*audit.Decision{},
+	}
+
+	for _, d := range ssn.Decisions {
+		switch d.Type {
+		case audit.BindDecision:
+			plan.Binds = append(plan.Binds, d)
+		case audit.EvictDecision:
+			plan.Evictions = append(plan.Evictions, d)
+		case audit.NominateDecision:
+			plan.Nominations = append(plan.Nominations, d)
+		}
+	}
+
+	return plan
+}
+
+// writePlan replaces the file at path by plan in JSON; it's written into a
+// temp file and renamed, so readers never see a partial plan.
+func writePlan(path string, plan *Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// emitPlan writes the plan of ssn into PlanFile, then executes the writes
+// held in ssn; the writes are executed even if the plan is not written.
+func (pc *Scheduler) emitPlan(ssn *framework.Session) {
+	defer pc.cache.ReleaseWrites()
+
+	plan := newPlan(ssn)
+	if err := writePlan(PlanFile, plan); err != nil {
+		logging.Error(err, "Failed to emit plan", "file", PlanFile, "session", ssn.ID)
+		return
+	}
+
+	logging.V(3).Info("Emitted plan", "file", PlanFile, "session", ssn.ID,
+		"binds", len(plan.Binds), "evictions", len(plan.Evictions))
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/audit"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+func TestWritePlan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ssn := &framework.Session{
+		ID: "s1",
+		Decisions: []*audit.Decision{
+			{Type: audit.EvictDecision, Task: "victim", Node: "n1", Reason: "preempt"},
+			{Type: audit.NominateDecision, Task: "t1", Node: "n1", Victims: []string{"c1/victim"}},
+			{Type: audit.BindDecision, Task: "t2", Node: "n2"},
+			{Type: audit.UnschedulableDecision, Task: "t3", Reason: "insufficient cpu"},
+		},
+	}
+
+	path := filepath.Join(dir, "plan.json")
+	if err := writePlan(path, newPlan(ssn)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := &Plan{}
+	if err := json.Unmarshal(data, plan); err != nil {
+		t.Fatal(err)
+	}
+
+	if plan.Session != "s1" || len(plan.Binds) != 1 || len(plan.Evictions) != 1 || len(plan.Nominations) != 1 {
+		t.Errorf("expected 1 bind, eviction and nomination of session s1, got %s", data)
+	}
+	if plan.Binds[0].Node != "n2" || plan.Evictions[0].Task != "victim" {
+		t.Errorf("unexpected plan %s", data)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package binpack
+
+import (
+	"k8s.io/api/core/v1"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+func init() {
+	framework.RegisterPluginBuilder(New)
+}
+
+// MaxNodeScore is the score of a node fully used after placing a task, the
+// same as the maximum score of kube-scheduler priorities.
+const MaxNodeScore = 10
+
+var (
+	// Enabled is whether the nodes are scored by binpack; the tasks are
+	// placed in the order of nodes found feasible if it's not enabled.
+	Enabled = false
+	// GPUWeight is the weight of GPU relative to CPU and memory, whose weight
+	// is 1, so the GPU nodes are packed by GPU first.
+	GPUWeight float64 = 10
+)
+
+// binpackPlugin scores nodes by their utilization after placing the task, so
+// the tasks are packed onto fewer nodes and the idle nodes are kept for large
+// tasks. The tasks without GPU avoid the nodes with idle GPUs, which are kept
+// for GPU tasks.
+type binpackPlugin struct{}
+
+func New() framework.Plugin {
+	return &binpackPlugin{}
+}
+
+func (bp *binpackPlugin) Name() string {
+	return "binpack"
+}
+
+func (bp *binpackPlugin) OnSessionOpen(ssn *framework.Session) {
+	if !Enabled {
+		return
+	}
+
+	ssn.AddNodeOrderFn(score)
+}
+
+func (bp *binpackPlugin) OnSessionClose(ssn *framework.Session) {}
+
+// score returns the weighted utilization of the resources requested by task
+// on node after placing it, in [0, MaxNodeScore]; it's -MaxNodeScore for a
+// task without GPU on a node with idle GPUs.
+func score(task *api.TaskInfo, node *api.NodeInfo) float64 {
+	if task.Resreq.GPU == 0 && node.Idle.GPU > 0 {
+		return -MaxNodeScore
+	}
+
+	weights := map[v1.ResourceName]float64{
+		v1.ResourceCPU:      1,
+		v1.ResourceMemory:   1,
+		api.GPUResourceName: GPUWeight,
+	}
+
+	total, sum := 0.0, 0.0
+	for rn, weight := range weights {
+		req := task.Resreq.Get(rn)
+		allocatable := node.Allocatable.Get(rn)
+		if req == 0 || allocatable == 0 {
+			continue
+		}
+
+		used := node.Used.Get(rn) + req
+		if used > allocatable {
+			used = allocatable
+		}
+
+		total += weight * used / allocatable
+		sum += weight
+	}
+
+	if sum == 0 {
+		return 0
+	}
+
+	return MaxNodeScore * total / sum
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package binpack
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+func buildTask(name, cpu, gpu string) *api.TaskInfo {
+	return api.NewTaskInfo(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+					// GPUs are requested by limits only.
+					Limits: v1.ResourceList{api.GPUResourceName: resource.MustParse(gpu)},
+				},
+			}},
+		},
+	})
+}
+
+func buildNode(name, cpu, gpu string) *api.NodeInfo {
+	return api.NewNodeInfo(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:      resource.MustParse(cpu),
+				v1.ResourceMemory:   resource.MustParse("10G"),
+				api.GPUResourceName: resource.MustParse(gpu),
+			},
+		},
+	})
+}
+
+func TestScore(t *testing.T) {
+	busy := buildNode("busy", "4", "4")
+	busy.AddTask(buildTask("t1", "1", "2"))
+	idle := buildNode("idle", "4", "4")
+	cpuOnly := buildNode("cpu", "4", "0")
+
+	gpuTask := buildTask("gpu", "1", "2")
+	if s1, s2 := score(gpuTask, busy), score(gpuTask, idle); s1 <= s2 {
+		t.Errorf("expected GPU task packed onto busy node, got score %v of busy and %v of idle", s1, s2)
+	}
+	if s := score(gpuTask, busy); s != MaxNodeScore*(0.5+GPUWeight)/(1+GPUWeight) {
+		t.Errorf("expected score weighted by GPU, got %v", s)
+	}
+
+	cpuTask := buildTask("cpu", "1", "0")
+	if s1, s2 := score(cpuTask, cpuOnly), score(cpuTask, busy); s1 <= s2 {
+		t.Errorf("expected task without GPU placed on node without GPU, got score %v and %v", s1, s2)
+	}
+}
@@ -19,6 +19,7 @@ package drf
 import (
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/policy"
 )
 
 func init() {
@@ -57,62 +58,53 @@ func (drf *drfPlugin) OnSessionOpen(ssn *framework.Session) {
 
 	for _, job := range ssn.Jobs {
 		attr := &drfAttr{
-			allocated: api.EmptyResource(),
-		}
-
-		for status, tasks := range job.TaskStatusIndex {
-			if api.OccupiedResources(status) {
-				for _, t := range tasks {
-					attr.allocated.Add(t.Resreq)
-				}
-			}
+			allocated: policy.JobAllocated(job),
 		}
 
+		drf.updateShare(attr)
 		drf.jobOpts[job.UID] = attr
 	}
 
-	// Add Job Order function.
-	ssn.AddJobOrderFn(func(l interface{}, r interface{}) int {
-		lv := l.(*api.JobInfo)
-		rv := r.(*api.JobInfo)
-
-		if drf.jobOpts[lv.UID].share == drf.jobOpts[rv.UID].share {
-			return 0
-		}
-
-		if drf.jobOpts[lv.UID].share < drf.jobOpts[rv.UID].share {
-			return -1
-		}
-
-		return 1
+	// Add Job Order function, the job with lower share first.
+	ssn.AddJobKeyFn(func(job *api.JobInfo) float64 {
+		return drf.jobOpts[job.UID].share
 	})
 
 	// Add Task Order function
 	ssn.AddTaskOrderFn(func(l interface{}, r interface{}) int {
-		lv := l.(*api.TaskInfo)
-		rv := r.(*api.TaskInfo)
-
-		if lv.Priority == rv.Priority {
-			return 0
-		}
+		return policy.CompareTasks(l.(*api.TaskInfo), r.(*api.TaskInfo))
+	})
 
-		if lv.Priority > rv.Priority {
-			return -1
+	// The victims of the jobs of higher share are preferred, which are
+	// fairer to lose their resources.
+	ssn.AddVictimsCostFn(func(task *api.TaskInfo, victims []*api.TaskInfo) float64 {
+		cost := 0.0
+		for _, v := range victims {
+			if attr, found := drf.jobOpts[v.Job]; found {
+				cost -= attr.share
+			}
 		}
-
-		return 1
+		return cost
 	})
 
 	// Register event handlers.
 	ssn.AddEventHandler(&framework.EventHandler{
 		BindFunc: func(event *framework.Event) {
-			attr := drf.jobOpts[event.Task.Job]
+			attr, found := drf.jobOpts[event.Task.Job]
+			if !found {
+				return
+			}
 			attr.allocated.Add(event.Task.Resreq)
 
 			drf.updateShare(attr)
 		},
 		EvictFunc: func(event *framework.Event) {
-			attr := drf.jobOpts[event.Task.Job]
+			// The tasks of the jobs not in session, e.g. the pods of
+			// other schedulers, have no share.
+			attr, found := drf.jobOpts[event.Task.Job]
+			if !found {
+				return
+			}
 			attr.allocated.Sub(event.Task.Resreq)
 
 			drf.updateShare(attr)
@@ -120,14 +112,12 @@ func (drf *drfPlugin) OnSessionOpen(ssn *framework.Session) {
 	})
 }
 
+// updateShare sets the dominant share of job by all resources including
+// GPUs.
 func (drf *drfPlugin) updateShare(attr *drfAttr) {
-	attr.share = 0
-	for _, rn := range api.ResourceNames() {
-		share := attr.allocated.Get(rn) / drf.totalResource.Get(rn)
-		if share > attr.share {
-			attr.share = share
-		}
-	}
+	share, rn := policy.DominantShare(attr.allocated, drf.totalResource)
+	attr.share = share
+	attr.dominantResource = string(rn)
 }
 
 func (drf *drfPlugin) OnSessionClose(session *framework.Session) {
@@ -0,0 +1,77 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extender
+
+import (
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/extender"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+func init() {
+	framework.RegisterPluginBuilder(New)
+}
+
+// extenderPlugin calls the extenders of kube-scheduler to filter and score
+// nodes, and to filter preemption victims; the binding by extender is done by
+// the cache. The ignorable extenders are skipped if they fail.
+type extenderPlugin struct{}
+
+func New() framework.Plugin {
+	return &extenderPlugin{}
+}
+
+func (ep *extenderPlugin) Name() string {
+	return "extender"
+}
+
+func (ep *extenderPlugin) OnSessionOpen(ssn *framework.Session) {
+	for _, e := range extender.Extenders() {
+		e := e
+
+		if e.SupportsFilter() {
+			ssn.AddNodesFilterFn(func(task *api.TaskInfo, nodes []*api.NodeInfo) ([]*api.NodeInfo, map[string]string, error) {
+				filtered, failed, err := e.Filter(task, nodes)
+				if err != nil && e.IsIgnorable() {
+					logging.Warning("Skip ignorable extender failed to filter nodes",
+						"extender", e.Name(), "task", task.UID, "error", err)
+					return nodes, nil, nil
+				}
+				return filtered, failed, err
+			})
+		}
+
+		if e.SupportsPrioritize() {
+			ssn.AddNodesOrderFn(e.Prioritize)
+		}
+
+		if e.SupportsPreemption() {
+			ssn.AddVictimsFilterFn(func(task *api.TaskInfo, victims map[string][]*api.TaskInfo) (map[string][]*api.TaskInfo, error) {
+				accepted, err := e.ProcessPreemption(task, victims)
+				if err != nil && e.IsIgnorable() {
+					logging.Warning("Skip ignorable extender failed to process preemption",
+						"extender", e.Name(), "task", task.UID, "error", err)
+					return victims, nil
+				}
+				return accepted, err
+			})
+		}
+	}
+}
+
+func (ep *extenderPlugin) OnSessionClose(ssn *framework.Session) {}
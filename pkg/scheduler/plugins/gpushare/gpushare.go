@@ -0,0 +1,66 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gpushare
+
+import (
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+func init() {
+	framework.RegisterPluginBuilder(New)
+}
+
+// gpuSharePlugin places the tasks requesting GPU shares, e.g. inference and
+// notebooks, onto the nodes whose GPUs are shared, so several tasks run on one
+// GPU; the tasks requesting whole GPUs are placed onto the other nodes.
+type gpuSharePlugin struct{}
+
+func New() framework.Plugin {
+	return &gpuSharePlugin{}
+}
+
+func (gp *gpuSharePlugin) Name() string {
+	return "gpushare"
+}
+
+func (gp *gpuSharePlugin) OnSessionOpen(ssn *framework.Session) {
+	ssn.AddNodesFilterFn(filter)
+}
+
+func (gp *gpuSharePlugin) OnSessionClose(ssn *framework.Session) {}
+
+func filter(task *api.TaskInfo, nodes []*api.NodeInfo) ([]*api.NodeInfo, map[string]string, error) {
+	if task.GPUShare == nil && task.Resreq.GPU == 0 {
+		return nodes, nil, nil
+	}
+
+	passed := make([]*api.NodeInfo, 0, len(nodes))
+	failed := map[string]string{}
+	for _, node := range nodes {
+		switch {
+		case task.GPUShare != nil && node.FitGPUShare(task.GPUShare) < 0:
+			failed[node.Name] = api.NodeGPUShareNotFit
+		case task.GPUShare == nil && node.SharesGPU():
+			failed[node.Name] = api.NodeGPUShared
+		default:
+			passed = append(passed, node)
+		}
+	}
+
+	return passed, failed, nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeos
+
+import (
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+func init() {
+	framework.RegisterPluginBuilder(New)
+}
+
+// nodeOSPlugin filters the nodes whose OS is not required by the task, so the
+// Linux pods are not bound onto Windows nodes in mixed-OS clusters, and vice
+// versa. The tasks which run on several OSes prefer the other nodes to the
+// Windows nodes, which are kept for the Windows-only tasks.
+type nodeOSPlugin struct{}
+
+func New() framework.Plugin {
+	return &nodeOSPlugin{}
+}
+
+func (np *nodeOSPlugin) Name() string {
+	return "nodeos"
+}
+
+func (np *nodeOSPlugin) OnSessionOpen(ssn *framework.Session) {
+	ssn.AddNodesFilterFn(filter)
+	ssn.AddNodeOrderFn(score)
+}
+
+func (np *nodeOSPlugin) OnSessionClose(ssn *framework.Session) {}
+
+func filter(task *api.TaskInfo, nodes []*api.NodeInfo) ([]*api.NodeInfo, map[string]string, error) {
+	oses := api.PodOSes(task.Pod)
+
+	passed := make([]*api.NodeInfo, 0, len(nodes))
+	failed := map[string]string{}
+	for _, node := range nodes {
+		if contains(oses, node.OS()) {
+			passed = append(passed, node)
+		} else {
+			failed[node.Name] = api.NodeOSNotMatch
+		}
+	}
+
+	return passed, failed, nil
+}
+
+// score returns -1 for a Windows node if task also runs on other OSes.
+func score(task *api.TaskInfo, node *api.NodeInfo) float64 {
+	if oses := api.PodOSes(task.Pod); len(oses) > 1 && node.OS() == api.WindowsOS {
+		return -1
+	}
+	return 0
+}
+
+func contains(oses []string, os string) bool {
+	for _, o := range oses {
+		if o == os {
+			return true
+		}
+	}
+	return false
+}
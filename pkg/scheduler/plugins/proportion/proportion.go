@@ -0,0 +1,130 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proportion
+
+import (
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/metrics"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/policy"
+)
+
+func init() {
+	framework.RegisterPluginBuilder(New)
+}
+
+type queueAttr struct {
+	policy.QueueShare
+
+	allocated policy.ResourceList
+}
+
+// proportionPlugin divides the cluster resources into queues by their weight,
+// which is the deserved share of each queue; the share of a queue is not more
+// than its request and capability, and the rest is divided into other queues.
+// The shares are exported as metrics to compare with the allocated resources.
+type proportionPlugin struct {
+	// Key is Queue name
+	queueOpts map[string]*queueAttr
+}
+
+func New() framework.Plugin {
+	return &proportionPlugin{
+		queueOpts: map[string]*queueAttr{},
+	}
+}
+
+func (pp *proportionPlugin) Name() string {
+	return "proportion"
+}
+
+func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
+	totalResource := api.EmptyResource()
+	for _, n := range ssn.Nodes {
+		totalResource.Add(n.Allocatable)
+	}
+
+	for _, queue := range ssn.Queues {
+		attr := &queueAttr{
+			QueueShare: policy.QueueShare{
+				Name:       queue.Name,
+				Weight:     queue.Weight,
+				Capability: policy.ResourceList{},
+			},
+		}
+		if queue.Capability != nil {
+			for _, rn := range api.ResourceNames() {
+				if _, found := queue.Queue.Spec.Capability[rn]; found {
+					attr.Capability[rn] = queue.Capability.Get(rn)
+				}
+			}
+		}
+		pp.queueOpts[queue.Name] = attr
+	}
+	pp.updateUsage(ssn)
+
+	shares := make([]*policy.QueueShare, 0, len(pp.queueOpts))
+	for _, attr := range pp.queueOpts {
+		shares = append(shares, &attr.QueueShare)
+	}
+	policy.Divide(shares, totalResource)
+}
+
+func (pp *proportionPlugin) OnSessionClose(ssn *framework.Session) {
+	// Update the allocated resources by the decisions of this session.
+	pp.updateUsage(ssn)
+
+	metrics.ResetQueueShares()
+	for _, attr := range pp.queueOpts {
+		for _, rn := range api.ResourceNames() {
+			metrics.UpdateQueueShare(attr.Name, string(rn),
+				attr.Deserved[rn], attr.allocated[rn], attr.Request[rn])
+		}
+	}
+
+	pp.queueOpts = nil
+}
+
+// updateUsage sums the allocated and requested resources of the jobs in each
+// queue; the request includes both allocated and pending tasks.
+func (pp *proportionPlugin) updateUsage(ssn *framework.Session) {
+	for _, attr := range pp.queueOpts {
+		attr.allocated = policy.ResourceList{}
+		attr.Request = policy.ResourceList{}
+	}
+
+	for _, job := range ssn.JobIndex {
+		attr, found := pp.queueOpts[job.Queue]
+		if !found {
+			continue
+		}
+
+		for status, tasks := range job.TaskStatusIndex {
+			occupied := api.OccupiedResources(status)
+			if !occupied && status != api.Pending {
+				continue
+			}
+
+			for _, t := range tasks {
+				attr.Request.Add(t.Resreq)
+				if occupied {
+					attr.allocated.Add(t.Resreq)
+				}
+			}
+		}
+	}
+}
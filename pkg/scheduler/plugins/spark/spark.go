@@ -0,0 +1,62 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spark
+
+import (
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+func init() {
+	framework.RegisterPluginBuilder(New)
+}
+
+// sparkPlugin orders the driver of a Spark application before its executors,
+// so the resources of application are not consumed by the executors while its
+// driver, which runs the application, is still pending.
+type sparkPlugin struct{}
+
+func New() framework.Plugin {
+	return &sparkPlugin{}
+}
+
+func (sp *sparkPlugin) Name() string {
+	return "spark"
+}
+
+func (sp *sparkPlugin) OnSessionOpen(ssn *framework.Session) {
+	ssn.AddTaskOrderFn(driverFirst)
+}
+
+func (sp *sparkPlugin) OnSessionClose(ssn *framework.Session) {}
+
+func driverFirst(l, r interface{}) int {
+	lv := l.(*api.TaskInfo)
+	rv := r.(*api.TaskInfo)
+
+	ld := lv.Pod != nil && api.IsSparkDriver(lv.Pod)
+	rd := rv.Pod != nil && api.IsSparkDriver(rv.Pod)
+
+	switch {
+	case ld == rd:
+		return 0
+	case ld:
+		return -1
+	default:
+		return 1
+	}
+}
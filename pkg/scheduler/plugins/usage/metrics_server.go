@@ -0,0 +1,128 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+)
+
+// nodeMetricsPath is the node metrics of metrics-server.
+const nodeMetricsPath = "/apis/metrics.k8s.io/v1beta1/nodes"
+
+var (
+	// PullPeriod is the interval between two pulls of node metrics.
+	PullPeriod = 30 * time.Second
+	// StaleAfter is the age after which the usage of node is not used, e.g.
+	// the node is not reported anymore.
+	StaleAfter = 3 * time.Minute
+)
+
+// The types are the part of NodeMetrics (metrics.k8s.io/v1beta1) used by
+// the plugin, as the metrics API is not vendored.
+type nodeMetrics struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Timestamp metav1.Time     `json:"timestamp"`
+	Usage     v1.ResourceList `json:"usage"`
+}
+
+type nodeMetricsList struct {
+	Items []nodeMetrics `json:"items"`
+}
+
+// nodeUsage is the usage of a node when it's reported.
+type nodeUsage struct {
+	milliCPU  float64
+	memory    float64
+	timestamp time.Time
+}
+
+var (
+	mutex sync.Mutex
+	// usage is the latest node usage by node name.
+	usage = map[string]*nodeUsage{}
+)
+
+// Run pulls the node metrics from metrics-server every PullPeriod until
+// stopCh is closed.
+func Run(config *rest.Config, stopCh <-chan struct{}) {
+	// metrics-server only serves JSON.
+	config = rest.CopyConfig(config)
+	config.ContentType = "application/json"
+	clients := kubernetes.NewForConfigOrDie(config)
+
+	go wait.Until(func() {
+		if err := pull(clients); err != nil {
+			logging.Error(err, "Failed to pull node metrics from metrics-server")
+		}
+	}, PullPeriod, stopCh)
+}
+
+func pull(clients kubernetes.Interface) error {
+	data, err := clients.CoreV1().RESTClient().Get().AbsPath(nodeMetricsPath).DoRaw()
+	if err != nil {
+		return err
+	}
+
+	list := &nodeMetricsList{}
+	if err := json.Unmarshal(data, list); err != nil {
+		return err
+	}
+
+	latest := make(map[string]*nodeUsage, len(list.Items))
+	for _, m := range list.Items {
+		u := &nodeUsage{timestamp: m.Timestamp.Time}
+		if cpu, found := m.Usage[v1.ResourceCPU]; found {
+			u.milliCPU = float64(cpu.MilliValue())
+		}
+		if memory, found := m.Usage[v1.ResourceMemory]; found {
+			u.memory = float64(memory.Value())
+		}
+		latest[m.Name] = u
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	usage = latest
+
+	logging.V(4).Info("Pulled node metrics", "nodes", len(latest))
+	return nil
+}
+
+// snapshot returns the usage of nodes which is not stale.
+func snapshot() map[string]*nodeUsage {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	result := make(map[string]*nodeUsage, len(usage))
+	for name, u := range usage {
+		if time.Since(u.timestamp) <= StaleAfter {
+			result[name] = u
+		}
+	}
+	return result
+}
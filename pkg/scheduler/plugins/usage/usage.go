@@ -0,0 +1,89 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+func init() {
+	framework.RegisterPluginBuilder(New)
+}
+
+// MaxNodeScore is the score of an idle node, the same as the maximum score of
+// binpack.
+const MaxNodeScore = 10
+
+// Enabled is whether the nodes are scored by their usage reported by
+// metrics-server; the usage is pulled only if it's enabled.
+var Enabled = false
+
+// usagePlugin scores nodes by their actual usage instead of the requests of
+// pods, so the tasks avoid the nodes loaded by the pods using more than their
+// requests, e.g. the best-effort services sharing nodes with batch jobs. The
+// nodes whose usage is not reported or stale are not scored.
+type usagePlugin struct {
+	// usage is the node usage when session opened.
+	usage map[string]*nodeUsage
+}
+
+func New() framework.Plugin {
+	return &usagePlugin{}
+}
+
+func (up *usagePlugin) Name() string {
+	return "usage"
+}
+
+func (up *usagePlugin) OnSessionOpen(ssn *framework.Session) {
+	if !Enabled {
+		return
+	}
+
+	up.usage = snapshot()
+	ssn.AddNodeOrderFn(up.score)
+}
+
+func (up *usagePlugin) OnSessionClose(ssn *framework.Session) {
+	up.usage = nil
+}
+
+// score returns -MaxNodeScore times the load of node, which is the maximum
+// utilization of its allocatable CPU and memory.
+func (up *usagePlugin) score(task *api.TaskInfo, node *api.NodeInfo) float64 {
+	u, found := up.usage[node.Name]
+	if !found {
+		return 0
+	}
+
+	return -MaxNodeScore * load(u, node.Allocatable)
+}
+
+func load(u *nodeUsage, allocatable *api.Resource) float64 {
+	l := 0.0
+	if allocatable.MilliCPU > 0 {
+		l = u.milliCPU / allocatable.MilliCPU
+	}
+	if allocatable.Memory > 0 && u.memory/allocatable.Memory > l {
+		l = u.memory / allocatable.Memory
+	}
+	if l > 1 {
+		l = 1
+	}
+	return l
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+func TestScore(t *testing.T) {
+	mutex.Lock()
+	usage = map[string]*nodeUsage{
+		"busy":  {milliCPU: 3000, memory: 1000, timestamp: time.Now()},
+		"idle":  {milliCPU: 400, memory: 1000, timestamp: time.Now()},
+		"stale": {milliCPU: 4000, memory: 4000, timestamp: time.Now().Add(-time.Hour)},
+	}
+	mutex.Unlock()
+
+	up := &usagePlugin{usage: snapshot()}
+	allocatable := &api.Resource{MilliCPU: 4000, Memory: 4000}
+
+	tests := map[string]float64{
+		"busy":  -7.5,
+		"idle":  -2.5,
+		"stale": 0,
+	}
+	for name, expected := range tests {
+		node := &api.NodeInfo{Name: name, Allocatable: allocatable}
+		if score := up.score(nil, node); score != expected {
+			t.Errorf("expected score %v of node %s, got %v", expected, name, score)
+		}
+	}
+}
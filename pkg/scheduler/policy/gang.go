@@ -0,0 +1,88 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package policy is the scheduling policies of kar-scheduler on the api
+// types only: the gang semantics, the fair share of jobs and the proportion
+// of queues. It does not depend on the cache and session of kar-scheduler, so
+// it can be compiled into other schedulers, e.g. as a plugin set of
+// kube-scheduler framework for the clusters which can not run a second
+// scheduler; see doc/design/library-mode.md.
+package policy
+
+import (
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+// GangReady returns whether minAvailable tasks of job occupy resources or
+// succeeded, i.e. the job can make progress.
+func GangReady(job *api.JobInfo) bool {
+	return job.ReadyTaskNum() >= job.MinAvailable
+}
+
+// GangPermitted returns whether the tasks of job assumed on nodes, but not
+// bound yet, can be bound: they're held until minAvailable tasks of job are
+// ready or assumed, so a partial gang does not occupy resources.
+func GangPermitted(job *api.JobInfo, assumed int) bool {
+	return job.ReadyTaskNum()+assumed >= job.MinAvailable
+}
+
+// JobAllocated returns the resources occupied by the tasks of job.
+func JobAllocated(job *api.JobInfo) *api.Resource {
+	allocated := api.EmptyResource()
+	for status, tasks := range job.TaskStatusIndex {
+		if api.OccupiedResources(status) {
+			for _, t := range tasks {
+				allocated.Add(t.Resreq)
+			}
+		}
+	}
+	return allocated
+}
+
+// CompareJobs orders the job of lower dominant share of total first, and by
+// UID if tie, the same as the job order of kar-scheduler with drf plugin.
+func CompareJobs(l, r *api.JobInfo, total *api.Resource) int {
+	ls, _ := DominantShare(JobAllocated(l), total)
+	rs, _ := DominantShare(JobAllocated(r), total)
+
+	switch {
+	case ls < rs:
+		return -1
+	case ls > rs:
+		return 1
+	case l.UID < r.UID:
+		return -1
+	case l.UID > r.UID:
+		return 1
+	}
+	return 0
+}
+
+// CompareTasks orders the task of higher role priority first, then the one
+// of higher priority.
+func CompareTasks(l, r *api.TaskInfo) int {
+	switch {
+	case l.RolePriority > r.RolePriority:
+		return -1
+	case l.RolePriority < r.RolePriority:
+		return 1
+	case l.Priority > r.Priority:
+		return -1
+	case l.Priority < r.Priority:
+		return 1
+	}
+	return 0
+}
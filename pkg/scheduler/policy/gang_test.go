@@ -0,0 +1,52 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+func TestCompareTasks(t *testing.T) {
+	buildTask := func(role string, priority int32) *api.TaskInfo {
+		pod := &v1.Pod{Spec: v1.PodSpec{Priority: &priority}}
+		if len(role) != 0 {
+			pod.ObjectMeta = metav1.ObjectMeta{
+				Annotations: map[string]string{api.RolePriorityAnnotation: role},
+			}
+		}
+		return api.NewTaskInfo(pod)
+	}
+
+	chief := buildTask("10", 0)
+	worker := buildTask("", 100)
+	invalid := buildTask("chief", 10)
+
+	if CompareTasks(chief, worker) != -1 || CompareTasks(worker, chief) != 1 {
+		t.Errorf("expected the task of higher role priority first")
+	}
+	if CompareTasks(worker, invalid) != -1 {
+		t.Errorf("expected the task of higher priority first if the role priority is invalid")
+	}
+	if CompareTasks(worker, buildTask("0", 100)) != 0 {
+		t.Errorf("expected the tasks of the same priorities equal")
+	}
+}
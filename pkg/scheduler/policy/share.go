@@ -0,0 +1,134 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"k8s.io/api/core/v1"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+// The resources less than it are taken as zero when dividing.
+const minResource = 0.1
+
+// ResourceList is the amount of resources by name, which only includes the
+// resources set, e.g. the resources limited by a queue.
+type ResourceList map[v1.ResourceName]float64
+
+// Add adds r to all resources of rl.
+func (rl ResourceList) Add(r *api.Resource) {
+	for _, rn := range api.ResourceNames() {
+		rl[rn] += r.Get(rn)
+	}
+}
+
+// DominantShare returns the dominant share of allocated in total, i.e. the
+// maximum share of all resources including GPUs, and the dominant resource;
+// the resources not in total, e.g. GPUs of a CPU only cluster, are not shared.
+func DominantShare(allocated, total *api.Resource) (float64, v1.ResourceName) {
+	var dominant v1.ResourceName
+	max := 0.0
+	for _, rn := range api.ResourceNames() {
+		t := total.Get(rn)
+		if t <= 0 {
+			continue
+		}
+
+		if share := allocated.Get(rn) / t; share > max {
+			max = share
+			dominant = rn
+		}
+	}
+	return max, dominant
+}
+
+// QueueShare is the share of a queue divided by Divide.
+type QueueShare struct {
+	Name   string
+	Weight int32
+
+	// Capability only includes the resources limited by the queue.
+	Capability ResourceList
+	// Request is the resources requested by the jobs in queue, including
+	// both allocated and pending tasks.
+	Request ResourceList
+
+	// Deserved is the share of queue set by Divide.
+	Deserved ResourceList
+}
+
+// limit returns the maximum deserved resource rn of the queue.
+func (qs *QueueShare) limit(rn v1.ResourceName) float64 {
+	limit := qs.Request[rn]
+	if capability, found := qs.Capability[rn]; found && capability < limit {
+		limit = capability
+	}
+	return limit
+}
+
+// Divide divides the total resources into queues by weight, as their
+// Deserved: the share of a queue is not more than its request and
+// capability, and the rest is divided into other queues until all queues get
+// their limit or nothing is left.
+func Divide(queues []*QueueShare, total *api.Resource) {
+	for _, qs := range queues {
+		qs.Deserved = ResourceList{}
+	}
+
+	for _, rn := range api.ResourceNames() {
+		divide(queues, rn, total.Get(rn))
+	}
+}
+
+func divide(queues []*QueueShare, rn v1.ResourceName, total float64) {
+	var active []*QueueShare
+	for _, qs := range queues {
+		if qs.Weight > 0 && qs.limit(rn) > minResource {
+			active = append(active, qs)
+		}
+	}
+
+	remaining := total
+	for remaining > minResource && len(active) != 0 {
+		totalWeight := 0.0
+		for _, qs := range active {
+			totalWeight += float64(qs.Weight)
+		}
+
+		divided := 0.0
+		var unsatisfied []*QueueShare
+		for _, qs := range active {
+			share := remaining * float64(qs.Weight) / totalWeight
+			limit := qs.limit(rn)
+			if qs.Deserved[rn]+share >= limit {
+				share = limit - qs.Deserved[rn]
+			} else {
+				unsatisfied = append(unsatisfied, qs)
+			}
+
+			qs.Deserved[rn] += share
+			divided += share
+		}
+
+		remaining -= divided
+		if len(unsatisfied) == len(active) {
+			// All queues got their share by weight.
+			break
+		}
+		active = unsatisfied
+	}
+}
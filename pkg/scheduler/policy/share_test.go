@@ -0,0 +1,49 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+)
+
+func TestDominantShare(t *testing.T) {
+	total := &api.Resource{MilliCPU: 10000, Memory: 1000}
+	share, rn := DominantShare(&api.Resource{MilliCPU: 2000, Memory: 500, GPU: 1}, total)
+	if share != 0.5 || rn != "memory" {
+		t.Errorf("expected dominant share 0.5 of memory, got %v of %v", share, rn)
+	}
+}
+
+func TestDivide(t *testing.T) {
+	queues := []*QueueShare{
+		{Name: "q1", Weight: 1, Request: ResourceList{"cpu": 1000}},
+		{Name: "q2", Weight: 1, Request: ResourceList{"cpu": 10000}},
+		{Name: "q3", Weight: 2, Request: ResourceList{"cpu": 10000}, Capability: ResourceList{"cpu": 4000}},
+	}
+
+	Divide(queues, &api.Resource{MilliCPU: 12000})
+
+	// q1 gets its request, q3 its capability, and q2 the rest.
+	expected := map[string]float64{"q1": 1000, "q2": 7000, "q3": 4000}
+	for _, qs := range queues {
+		if got := qs.Deserved["cpu"]; got < expected[qs.Name]-1 || got > expected[qs.Name]+1 {
+			t.Errorf("expected queue %s deserves %v cpu, got %v", qs.Name, expected[qs.Name], got)
+		}
+	}
+}
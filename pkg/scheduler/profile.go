@@ -0,0 +1,115 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+// Profile is a named policy for a common goal, selected by the --profile
+// flag of kar-scheduler: its config is the default actions and disabled
+// plugins, and its flags preset the arguments of plugins. The flags set on
+// command line and the config of --scheduler-config take precedence.
+type Profile struct {
+	Name        string
+	Description string
+	Config      Config
+	// Flags is the values of kar-scheduler flags by name.
+	Flags map[string]string
+}
+
+// Profiles is the built-in profiles by name.
+var Profiles = map[string]*Profile{
+	"throughput": {
+		Name: "throughput",
+		Description: "Start as many gangs as possible with the least scheduling latency: " +
+			"no preemption, and a tenth of nodes scored for each task",
+		Config: Config{
+			Actions:         []string{"decorate", "garantee", "allocate"},
+			DisabledPlugins: []string{"binpack", "usage"},
+		},
+		Flags: map[string]string{
+			"percentage-of-nodes-to-score": "10",
+			"enable-binpack":               "false",
+		},
+	},
+	"fairness": {
+		Name: "fairness",
+		Description: "Keep queues at their deserved shares: preempt for starving queues " +
+			"and rebalance gangs, and score all nodes",
+		Config: Config{
+			Actions: []string{"decorate", "garantee", "allocate", "preempt", "shuffle"},
+		},
+		Flags: map[string]string{
+			"percentage-of-nodes-to-score": "100",
+			"enable-binpack":               "false",
+		},
+	},
+	"packing": {
+		Name: "packing",
+		Description: "Pack tasks onto the fewest nodes, keeping idle nodes and GPUs for large tasks, " +
+			"e.g. with cluster autoscaler",
+		Config: Config{
+			Actions: []string{"decorate", "garantee", "allocate", "preempt"},
+		},
+		Flags: map[string]string{
+			"percentage-of-nodes-to-score": "100",
+			"enable-binpack":               "true",
+			"binpack-gpu-weight":           "10",
+		},
+	},
+}
+
+// ProfileNames returns the names of the built-in profiles in order.
+func ProfileNames() []string {
+	names := make([]string, 0, len(Profiles))
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetProfile returns the built-in profile of name.
+func GetProfile(name string) (*Profile, error) {
+	p, found := Profiles[name]
+	if !found {
+		return nil, fmt.Errorf("unknown profile %q, expected one of %s", name, strings.Join(ProfileNames(), ", "))
+	}
+	return p, nil
+}
+
+// Apply sets the actions and disabled plugins of profile as the default
+// policy of sessions.
+func (p *Profile) Apply() error {
+	if err := p.Config.Validate(); err != nil {
+		return fmt.Errorf("invalid config of profile %s: %v", p.Name, err)
+	}
+
+	actions, err := p.Config.BuildActions()
+	if err != nil {
+		return err
+	}
+	Actions = actions
+	framework.DisabledPlugins = p.Config.DisabledPluginSet()
+
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+func TestProfiles(t *testing.T) {
+	defer func(actions []framework.Action) { Actions = actions }(Actions)
+	defer func(disabled map[string]bool) { framework.DisabledPlugins = disabled }(framework.DisabledPlugins)
+
+	for _, name := range ProfileNames() {
+		profile, err := GetProfile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := profile.Config.Validate(); err != nil {
+			t.Errorf("invalid config of profile %s: %v", name, err)
+		}
+	}
+
+	if _, err := GetProfile("unknown"); err == nil {
+		t.Errorf("expected error of unknown profile")
+	}
+
+	profile, _ := GetProfile("throughput")
+	if err := profile.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if len(Actions) != 3 || Actions[2].Name() != "allocate" {
+		t.Errorf("expected actions of throughput profile, got %v", Actions)
+	}
+	if !framework.DisabledPlugins["binpack"] {
+		t.Errorf("expected binpack disabled, got %v", framework.DisabledPlugins)
+	}
+
+	// The config names the actions not in profile.
+	if _, err := loadPolicy([]byte("actions: [allocate, preempt]")); err != nil {
+		t.Errorf("expected preempt named by config, got %v", err)
+	}
+}
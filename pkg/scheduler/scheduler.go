@@ -17,23 +17,69 @@ limitations under the License.
 package scheduler
 
 import (
+	"sync"
 	"time"
 
-	"github.com/golang/glog"
-
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/rest"
 
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/client"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
 	schedcache "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/cache"
 	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/metrics"
+)
+
+var (
+	// SchedulePeriod is the interval between scheduling sessions if they're
+	// not triggered by events.
+	SchedulePeriod = 2 * time.Second
+
+	// EventDrivenSessions enables triggering scheduling sessions by events,
+	// e.g. a job is added or resources are released, instead of running them
+	// every SchedulePeriod.
+	EventDrivenSessions = true
+	// MaxSessionInterval is the maximum interval between event driven
+	// sessions, e.g. to retry unschedulable jobs without any event.
+	MaxSessionInterval = 10 * time.Second
+	// SessionDebounce is the time to wait for more events after an event, so
+	// a burst of events triggers one session.
+	SessionDebounce = 100 * time.Millisecond
+	// SessionMaxWait is the maximum time to wait for more events after the
+	// first event, so a session is not delayed forever by continuous events.
+	SessionMaxWait = time.Second
 )
 
 type Scheduler struct {
 	cache  schedcache.Cache
 	config *rest.Config
+
+	// cacheOnce starts the cache once, by either WarmUp or Run.
+	cacheOnce sync.Once
+
+	debugMutex    sync.Mutex
+	unschedulable []*unschedulableJob
+	capacity      *api.ClusterCapacity
+	defragHints   []*defragHint
+
+	currentSession    *sessionRecord
+	lastSessionRecord *sessionRecord
+	lastSummary       *sessionSummary
+
+	// policy is the actions and disabled plugins of sessions, nil for all
+	// of them; nextPolicy is the policy reloaded from ConfigFile, which is
+	// applied at the next session. They're guarded by configMutex.
+	policy      *policy
+	configMutex sync.Mutex
+	configData  []byte
+	nextPolicy  *policy
+
+	healthMutex sync.Mutex
+	synced      bool
+	lastSession time.Time
 }
 
 func NewScheduler(config *rest.Config, schedulerName string) (*Scheduler, error) {
@@ -42,30 +88,127 @@ func NewScheduler(config *rest.Config, schedulerName string) (*Scheduler, error)
 		cache:  schedcache.New(config, schedulerName),
 	}
 
+	if len(ConfigFile) != 0 {
+		if err := scheduler.loadConfigFile(); err != nil {
+			return nil, err
+		}
+	}
+
 	return scheduler, nil
 }
 
 func (pc *Scheduler) Run(stopCh <-chan struct{}) {
 	createSchedulingSpecKind(pc.config)
 
-	// Start cache for policy.
-	go pc.cache.Run(stopCh)
-	pc.cache.WaitForCacheSync(stopCh)
+	// Start cache for policy, unless it's warmed up on standby.
+	pc.startCache(stopCh)
+	pc.cache.SetStandby(false)
+	if !pc.cache.WaitForCacheSync(stopCh) {
+		return
+	}
+	if len(WarmRestartFile) != 0 {
+		pc.importDerivedState()
+	}
+	pc.markSynced()
+
+	if len(ConfigFile) != 0 {
+		go wait.Until(pc.reloadConfig, ConfigReloadPeriod, stopCh)
+	}
+
+	if EventDrivenSessions {
+		go pc.runOnEvents(stopCh)
+		return
+	}
+	go wait.Until(pc.runOnce, SchedulePeriod, stopCh)
+}
+
+// WarmUp starts the cache on standby, i.e. its API writes are dropped, so a
+// scheduler without leadership keeps its cache synced and starts scheduling
+// in seconds once it becomes leader by Run.
+func (pc *Scheduler) WarmUp(stopCh <-chan struct{}) {
+	pc.cache.SetStandby(true)
+	pc.startCache(stopCh)
+
+	go func() {
+		if pc.cache.WaitForCacheSync(stopCh) {
+			logging.Info("Cache of standby scheduler is synced")
+		}
+	}()
+}
+
+func (pc *Scheduler) startCache(stopCh <-chan struct{}) {
+	pc.cacheOnce.Do(func() {
+		go pc.cache.Run(stopCh)
+	})
+}
 
-	go wait.Until(pc.runOnce, 2*time.Second, stopCh)
+// sessionInterval returns the maximum interval between sessions.
+func sessionInterval() time.Duration {
+	if EventDrivenSessions {
+		return MaxSessionInterval
+	}
+	return SchedulePeriod
 }
 
 func (pc *Scheduler) runOnce() {
-	glog.V(4).Infof("Start scheduling ...")
-	defer glog.V(4).Infof("End scheduling ...")
+	logging.V(4).Info("Start scheduling")
+	defer logging.V(4).Info("End scheduling")
+
+	scheduleStart := time.Now()
+	defer func() {
+		metrics.UpdateE2eSchedulingLatency(time.Since(scheduleStart))
+	}()
+
+	actions := pc.sessionActions()
+
+	// The state is taken right before the snapshot of session, so the
+	// session is replayed on the same objects unless an event is handled
+	// in between.
+	var state *schedcache.ClusterState
+	if len(TraceFile) != 0 {
+		state = pc.cache.State()
+	}
 
 	ssn := framework.OpenSession(pc.cache)
-	defer framework.CloseSession(ssn)
+	summary := newSessionSummary(ssn, scheduleStart)
+	defer func() {
+		closeStart := time.Now()
+		framework.CloseSession(ssn)
+		summary.finish(closeStart)
+		pc.recordSummary(summary)
+	}()
+
+	pc.recordJobOrder(ssn, scheduleStart)
+
+	if len(PlanFile) != 0 {
+		pc.cache.HoldWrites()
+	}
 
-	for _, action := range Actions {
+	for _, action := range actions {
+		ssn.Action = action.Name()
+		actionSpan := ssn.Span.StartChild(action.Name())
+		actionStart := time.Now()
 		action.Execute(ssn)
+		actionDuration := time.Since(actionStart)
+		metrics.UpdateActionLatency(action.Name(), actionDuration)
+		summary.addAction(action.Name(), actionDuration)
+		actionSpan.Finish()
+	}
+
+	if len(PlanFile) != 0 {
+		pc.emitPlan(ssn)
+	}
+
+	if len(TraceFile) != 0 {
+		pc.recordTrace(ssn, state, actions)
 	}
 
+	summary.countDecisions(ssn.Decisions)
+	pc.recordUnschedulable(ssn)
+	pc.recordDefragHints(ssn)
+	pc.recordCapacity(ssn)
+	pc.recordDecisions(ssn)
+	pc.markSessionCompleted()
 }
 
 func createSchedulingSpecKind(config *rest.Config) error {
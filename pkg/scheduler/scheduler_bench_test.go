@@ -0,0 +1,289 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	schedcache "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/cache"
+)
+
+// The benchmarks replay synthetic clusters through the cache and a full
+// scheduling session with all Actions and plugins, e.g.
+//
+//   make benchmark BENCH=BenchmarkSession/nodes=1000
+//
+// so the performance regressions of new plugins are caught before release.
+
+var benchmarkClusterSizes = []int{100, 1000, 5000}
+
+// jobKind describes a kind of jobs in the synthetic cluster.
+type jobKind struct {
+	// jobsPerNode is the number of jobs of this kind per node.
+	jobsPerNode  float64
+	tasks        int
+	minAvailable int
+	// running is the number of tasks of each job already running; the
+	// others are pending.
+	running int
+	request v1.ResourceList
+}
+
+// jobMix is the jobs of a synthetic cluster.
+type jobMix struct {
+	name  string
+	kinds []jobKind
+}
+
+var (
+	batchJobs = jobKind{
+		jobsPerNode:  0.5,
+		tasks:        4,
+		minAvailable: 1,
+		running:      2,
+		request:      buildResourceList("2", "4Gi"),
+	}
+
+	gangJobs = jobKind{
+		jobsPerNode:  0.1,
+		tasks:        32,
+		minAvailable: 32,
+		request:      buildResourceList("4", "8Gi"),
+	}
+
+	benchmarkJobMixes = []jobMix{
+		{name: "batch", kinds: []jobKind{batchJobs}},
+		{name: "gang", kinds: []jobKind{gangJobs}},
+		{name: "mixed", kinds: []jobKind{batchJobs, gangJobs}},
+	}
+)
+
+var benchmarkNodeAllocatable = buildResourceList("32", "128Gi")
+
+func buildResourceList(cpu, memory string) v1.ResourceList {
+	return v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+// syntheticCluster is the objects replayed into the cache.
+type syntheticCluster struct {
+	nodes      []*v1.Node
+	pods       []*v1.Pod
+	schedSpecs []*arbv1.SchedulingSpec
+}
+
+// buildSyntheticCluster builds a cluster of numNodes nodes with the jobs of
+// mix; the running tasks are spread over the nodes round robin.
+func buildSyntheticCluster(numNodes int, mix jobMix) *syntheticCluster {
+	cluster := &syntheticCluster{}
+
+	for i := 0; i < numNodes; i++ {
+		cluster.nodes = append(cluster.nodes, &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("node-%d", i),
+			},
+			Status: v1.NodeStatus{
+				Capacity:    benchmarkNodeAllocatable,
+				Allocatable: benchmarkNodeAllocatable,
+			},
+		})
+	}
+
+	nextNode := 0
+	for k, kind := range mix.kinds {
+		numJobs := int(kind.jobsPerNode * float64(numNodes))
+		for j := 0; j < numJobs; j++ {
+			name := fmt.Sprintf("job-%d-%d", k, j)
+			controller := true
+			owner := metav1.OwnerReference{
+				Name:       name,
+				UID:        types.UID(name),
+				Controller: &controller,
+			}
+
+			cluster.schedSpecs = append(cluster.schedSpecs, &arbv1.SchedulingSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            name,
+					Namespace:       "bench",
+					OwnerReferences: []metav1.OwnerReference{owner},
+				},
+				Spec: arbv1.SchedulingSpecTemplate{
+					MinAvailable: kind.minAvailable,
+				},
+			})
+
+			for t := 0; t < kind.tasks; t++ {
+				pod := &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            fmt.Sprintf("%s-%d", name, t),
+						Namespace:       "bench",
+						UID:             types.UID(fmt.Sprintf("%s-%d", name, t)),
+						OwnerReferences: []metav1.OwnerReference{owner},
+					},
+					Spec: v1.PodSpec{
+						Containers: []v1.Container{
+							{
+								Resources: v1.ResourceRequirements{
+									Requests: kind.request,
+								},
+							},
+						},
+					},
+					Status: v1.PodStatus{
+						Phase: v1.PodPending,
+					},
+				}
+				if t < kind.running {
+					pod.Spec.NodeName = cluster.nodes[nextNode%numNodes].Name
+					pod.Status.Phase = v1.PodRunning
+					nextNode++
+				}
+				cluster.pods = append(cluster.pods, pod)
+			}
+		}
+	}
+
+	return cluster
+}
+
+// newCache creates a scheduler cache without informers, whose API writes
+// are discarded.
+func newCache() *schedcache.SchedulerCache {
+	return &schedcache.SchedulerCache{
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Nodes:         make(map[string]*api.NodeInfo),
+		Queues:        make(map[string]*api.QueueInfo),
+		Binder:        &fakeBinder{},
+		Evictor:       &fakeEvictor{},
+		StatusUpdater: &fakeStatusUpdater{},
+		Recorder:      &fakeRecorder{},
+	}
+}
+
+// replay adds the objects of cluster into cache.
+func (cluster *syntheticCluster) replay(cache *schedcache.SchedulerCache) {
+	for _, node := range cluster.nodes {
+		cache.AddNode(node)
+	}
+	for _, ss := range cluster.schedSpecs {
+		cache.AddSchedulingSpec(ss)
+	}
+	for _, pod := range cluster.pods {
+		cache.AddPod(pod)
+	}
+}
+
+// The fakes are called concurrently as the API writes are dispatched in
+// goroutines, so they keep no state.
+
+type fakeBinder struct{}
+
+func (fb *fakeBinder) Bind(p *v1.Pod, hostname string) error {
+	return nil
+}
+
+type fakeEvictor struct{}
+
+func (fe *fakeEvictor) Evict(p *v1.Pod) error {
+	return nil
+}
+
+type fakeStatusUpdater struct{}
+
+func (fsu *fakeStatusUpdater) UpdateSchedulingSpec(ss *arbv1.SchedulingSpec) error {
+	return nil
+}
+
+func (fsu *fakeStatusUpdater) UpdatePodCondition(pod *v1.Pod, condition *v1.PodCondition) error {
+	return nil
+}
+
+func (fsu *fakeStatusUpdater) UpdatePodNominatedNode(pod *v1.Pod, nodeName string) error {
+	return nil
+}
+
+type fakeRecorder struct{}
+
+func (fr *fakeRecorder) Eventf(ref *v1.ObjectReference, eventType, reason, messageFmt string, args ...interface{}) {
+}
+
+// runBenchmarks runs fn for each cluster size and job mix.
+func runBenchmarks(b *testing.B, fn func(b *testing.B, cluster *syntheticCluster)) {
+	for _, size := range benchmarkClusterSizes {
+		for _, mix := range benchmarkJobMixes {
+			cluster := buildSyntheticCluster(size, mix)
+			b.Run(fmt.Sprintf("nodes=%d/jobs=%s", size, mix.name), func(b *testing.B) {
+				fn(b, cluster)
+			})
+		}
+	}
+}
+
+// BenchmarkCacheReplay measures adding the nodes, jobs and pods of the
+// cluster into an empty cache, e.g. when the informers are synced.
+func BenchmarkCacheReplay(b *testing.B) {
+	runBenchmarks(b, func(b *testing.B, cluster *syntheticCluster) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			cluster.replay(newCache())
+		}
+	})
+}
+
+// BenchmarkCacheSnapshot measures taking the snapshot of the cache at the
+// beginning of each session.
+func BenchmarkCacheSnapshot(b *testing.B) {
+	runBenchmarks(b, func(b *testing.B, cluster *syntheticCluster) {
+		cache := newCache()
+		cluster.replay(cache)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			snapshot := cache.Snapshot()
+			api.ReleaseTasks(snapshot.Jobs, snapshot.Nodes)
+		}
+	})
+}
+
+// BenchmarkSession measures a full scheduling session, from opening it to
+// closing it, on the cluster whose pending tasks are not bound yet.
+func BenchmarkSession(b *testing.B) {
+	runBenchmarks(b, func(b *testing.B, cluster *syntheticCluster) {
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			cache := newCache()
+			cluster.replay(cache)
+			sched := &Scheduler{cache: cache}
+			b.StartTimer()
+
+			sched.runOnce()
+		}
+	})
+}
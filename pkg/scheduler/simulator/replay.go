@@ -0,0 +1,141 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/audit"
+)
+
+// ReplayReport is the differences between the decisions recorded in the trace
+// of a session and the decisions of its replay; the replay is exact if there
+// is no difference.
+type ReplayReport struct {
+	Session  string `json:"session"`
+	Recorded int    `json:"recorded"`
+	Replayed int    `json:"replayed"`
+	// Missing is the decisions recorded but not made by the replay.
+	Missing []*audit.Decision `json:"missing"`
+	// Unexpected is the decisions made by the replay but not recorded.
+	Unexpected []*audit.Decision `json:"unexpected"`
+}
+
+// Exact returns whether the replay makes the same decisions as recorded.
+func (r *ReplayReport) Exact() bool {
+	return len(r.Missing) == 0 && len(r.Unexpected) == 0
+}
+
+// Replay runs the session of trace on its state by its config, scheduling the
+// pods of schedulerName, and compares the decisions with the recorded ones.
+func Replay(trace *scheduler.SessionTrace, schedulerName string) (*ReplayReport, error) {
+	if trace.State == nil {
+		return nil, fmt.Errorf("no state in trace of session %s", trace.Session)
+	}
+
+	sim, err := New(trace.State, &Config{Config: trace.Config, Sessions: 1}, schedulerName)
+	if err != nil {
+		return nil, err
+	}
+	replayed := sim.runSession()
+
+	report := &ReplayReport{
+		Session:  trace.Session,
+		Recorded: len(trace.Decisions),
+		Replayed: len(replayed),
+	}
+	report.Missing, report.Unexpected = diffDecisions(trace.Decisions, replayed)
+
+	return report, nil
+}
+
+// decisionKey is the fields of a decision compared by replay; the session,
+// timestamp, score and reason are not, because they're different or
+// formatted differently in each run.
+type decisionKey struct {
+	Type      audit.DecisionType
+	Action    string
+	Job       string
+	Namespace string
+	Task      string
+	Node      string
+}
+
+func keyOf(d *audit.Decision) decisionKey {
+	return decisionKey{
+		Type:      d.Type,
+		Action:    d.Action,
+		Job:       d.Job,
+		Namespace: d.Namespace,
+		Task:      d.Task,
+		Node:      d.Node,
+	}
+}
+
+// diffDecisions returns the decisions in recorded but not in replayed, and
+// the decisions in replayed but not in recorded, in their order.
+func diffDecisions(recorded, replayed []*audit.Decision) ([]*audit.Decision, []*audit.Decision) {
+	counts := map[decisionKey]int{}
+	for _, d := range replayed {
+		counts[keyOf(d)]++
+	}
+
+	var missing []*audit.Decision
+	for _, d := range recorded {
+		key := keyOf(d)
+		if counts[key] == 0 {
+			missing = append(missing, d)
+			continue
+		}
+		counts[key]--
+	}
+
+	var unexpected []*audit.Decision
+	for _, d := range replayed {
+		key := keyOf(d)
+		if counts[key] == 0 {
+			continue
+		}
+		counts[key]--
+		unexpected = append(unexpected, d)
+	}
+
+	return missing, unexpected
+}
+
+// Print writes the report in text into w.
+func (r *ReplayReport) Print(w io.Writer) {
+	fmt.Fprintf(w, "Replayed session %s: %d decision(s) recorded, %d replayed\n\n",
+		r.Session, r.Recorded, r.Replayed)
+
+	if r.Exact() {
+		fmt.Fprintf(w, "The replay is exact.\n")
+		return
+	}
+
+	fmt.Fprintf(w, "Missing:\n")
+	for _, d := range r.Missing {
+		fmt.Fprintf(w, "\t %s %s: %s/%s (job %s) %s\n", d.Action, d.Type, d.Namespace, d.Task, d.Job, d.Node)
+	}
+
+	fmt.Fprintf(w, "Unexpected:\n")
+	for _, d := range r.Unexpected {
+		fmt.Fprintf(w, "\t %s %s: %s/%s (job %s) %s\n", d.Action, d.Type, d.Namespace, d.Task, d.Job, d.Node)
+	}
+}
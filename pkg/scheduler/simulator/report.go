@@ -0,0 +1,181 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/audit"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/policy"
+)
+
+// Placement is a task bound to a node in a session.
+type Placement struct {
+	Session   int    `json:"session"`
+	Action    string `json:"action"`
+	Job       string `json:"job"`
+	Namespace string `json:"namespace"`
+	Task      string `json:"task"`
+	Node      string `json:"node"`
+}
+
+// Preemption is a task evicted from a node in a session.
+type Preemption struct {
+	Session   int    `json:"session"`
+	Action    string `json:"action"`
+	Job       string `json:"job"`
+	Namespace string `json:"namespace"`
+	Task      string `json:"task"`
+	Node      string `json:"node"`
+	Reason    string `json:"reason"`
+}
+
+// QueueReport is the resources allocated to a queue after the sessions.
+type QueueReport struct {
+	Name        string        `json:"name"`
+	Weight      int32         `json:"weight"`
+	Jobs        int           `json:"jobs"`
+	PendingJobs int           `json:"pendingJobs"`
+	Allocated   *api.Resource `json:"allocated"`
+	// Share is the dominant share of the resources allocated in cluster.
+	Share float64 `json:"share"`
+}
+
+// Report is the result of simulation.
+type Report struct {
+	Sessions    int            `json:"sessions"`
+	Placements  []*Placement   `json:"placements"`
+	Preemptions []*Preemption  `json:"preemptions"`
+	Queues      []*QueueReport `json:"queues"`
+	// Fairness is the Jain's index of the shares of queues divided by their
+	// weights, in (0, 1]; 1 means the queues are allocated by their weights.
+	Fairness float64 `json:"fairness"`
+}
+
+// addDecisions adds the binds and evictions of a session.
+func (r *Report) addDecisions(session int, decisions []*audit.Decision) {
+	for _, d := range decisions {
+		switch d.Type {
+		case audit.BindDecision:
+			r.Placements = append(r.Placements, &Placement{
+				Session:   session,
+				Action:    d.Action,
+				Job:       d.Job,
+				Namespace: d.Namespace,
+				Task:      d.Task,
+				Node:      d.Node,
+			})
+		case audit.EvictDecision:
+			r.Preemptions = append(r.Preemptions, &Preemption{
+				Session:   session,
+				Action:    d.Action,
+				Job:       d.Job,
+				Namespace: d.Namespace,
+				Task:      d.Task,
+				Node:      d.Node,
+				Reason:    d.Reason,
+			})
+		}
+	}
+}
+
+// addQueues adds the allocation of queues in snapshot and their fairness; the
+// queues of jobs which are not created have weight 1.
+func (r *Report) addQueues(snapshot *api.ClusterInfo) {
+	total := api.EmptyResource()
+	for _, node := range snapshot.Nodes {
+		total.Add(node.Allocatable)
+	}
+
+	queues := map[string]*QueueReport{}
+	for _, q := range snapshot.Queues {
+		queues[q.Name] = &QueueReport{Name: q.Name, Weight: q.Weight, Allocated: api.EmptyResource()}
+	}
+
+	for _, job := range snapshot.Jobs {
+		q, found := queues[job.Queue]
+		if !found {
+			q = &QueueReport{Name: job.Queue, Weight: 1, Allocated: api.EmptyResource()}
+			queues[job.Queue] = q
+		}
+
+		q.Jobs++
+		if len(job.TaskStatusIndex[api.Pending]) != 0 {
+			q.PendingJobs++
+		}
+		q.Allocated.Add(job.Allocated)
+	}
+
+	var shares []float64
+	for _, q := range queues {
+		q.Share, _ = policy.DominantShare(q.Allocated, total)
+		r.Queues = append(r.Queues, q)
+
+		// The queues without jobs do not compete for resources.
+		if q.Jobs != 0 && q.Weight > 0 {
+			shares = append(shares, q.Share/float64(q.Weight))
+		}
+	}
+	sort.Slice(r.Queues, func(i, j int) bool {
+		return r.Queues[i].Name < r.Queues[j].Name
+	})
+
+	r.Fairness = jainIndex(shares)
+}
+
+// jainIndex returns the Jain's fairness index of values, 1 if they're all 0.
+func jainIndex(values []float64) float64 {
+	sum, sumSquares := 0.0, 0.0
+	for _, v := range values {
+		sum += v
+		sumSquares += v * v
+	}
+
+	if sumSquares == 0 {
+		return 1
+	}
+	return sum * sum / (float64(len(values)) * sumSquares)
+}
+
+// Print writes the report in text into w.
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "Simulated %d session(s): %d placement(s), %d preemption(s)\n\n",
+		r.Sessions, len(r.Placements), len(r.Preemptions))
+
+	fmt.Fprintf(w, "Placements:\n")
+	for _, p := range r.Placements {
+		fmt.Fprintf(w, "\t session %d %s: %s/%s (job %s) -> %s\n",
+			p.Session, p.Action, p.Namespace, p.Task, p.Job, p.Node)
+	}
+
+	fmt.Fprintf(w, "Preemptions:\n")
+	for _, p := range r.Preemptions {
+		fmt.Fprintf(w, "\t session %d %s: %s/%s (job %s) on %s: %s\n",
+			p.Session, p.Action, p.Namespace, p.Task, p.Job, p.Node, p.Reason)
+	}
+
+	fmt.Fprintf(w, "Queues:\n")
+	for _, q := range r.Queues {
+		fmt.Fprintf(w, "\t %s: weight(%d) jobs(%d) pending(%d) allocated(%v) share(%.3f)\n",
+			q.Name, q.Weight, q.Jobs, q.PendingJobs, q.Allocated, q.Share)
+	}
+
+	fmt.Fprintf(w, "Fairness: %.3f\n", r.Fairness)
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulator replays the state of a cluster by the scheduler offline,
+// so the changes of policies are evaluated before rollout: the sessions run
+// without any API writes, and the placements, preemptions and fairness of
+// queues are reported.
+package simulator
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/audit"
+	schedcache "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/cache"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+// Config is the proposed scheduler config in YAML or JSON, with the number of
+// sessions to run, e.g.
+//
+//	actions: [decorate, garantee, allocate]
+//	disabledPlugins: [usage]
+//	sessions: 3
+type Config struct {
+	scheduler.Config `json:",inline"`
+
+	// Sessions is the number of sessions to run, 1 if not set.
+	Sessions int `json:"sessions,omitempty"`
+}
+
+// LoadConfig loads the scheduler config from path.
+func LoadConfig(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config := &Config{}
+	if err := yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(config); err != nil {
+		return nil, fmt.Errorf("failed to parse scheduler config %s: %v", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scheduler config %s: %v", path, err)
+	}
+
+	return config, nil
+}
+
+// Simulator runs the sessions of scheduler on an offline cache.
+type Simulator struct {
+	cache    *schedcache.SchedulerCache
+	actions  []framework.Action
+	sessions int
+	writes   *writes
+}
+
+// New creates a Simulator of state by config, scheduling the pods of
+// schedulerName. The disabled plugins of config are set to framework, so only
+// one Simulator runs in a process.
+func New(state *schedcache.ClusterState, config *Config, schedulerName string) (*Simulator, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	actions, err := config.BuildActions()
+	if err != nil {
+		return nil, err
+	}
+	framework.DisabledPlugins = config.DisabledPluginSet()
+
+	sessions := config.Sessions
+	if sessions <= 0 {
+		sessions = 1
+	}
+
+	w := &writes{}
+	return &Simulator{
+		cache:    schedcache.NewOffline(schedulerName, state, w, w),
+		actions:  actions,
+		sessions: sessions,
+		writes:   w,
+	}, nil
+}
+
+// Run runs the sessions, and reports the decisions and the fairness of queues
+// after them. The pods bound in a session are running in the next one, and
+// the pods evicted are deleted at once.
+func (s *Simulator) Run() *Report {
+	report := &Report{Sessions: s.sessions}
+
+	for i := 1; i <= s.sessions; i++ {
+		report.addDecisions(i, s.runSession())
+	}
+
+	snapshot := s.cache.Snapshot()
+	report.addQueues(snapshot)
+	api.ReleaseTasks(snapshot.Jobs, snapshot.Nodes)
+
+	return report
+}
+
+// runSession runs a session and applies its writes, and returns its
+// decisions.
+func (s *Simulator) runSession() []*audit.Decision {
+	ssn := framework.OpenSession(s.cache)
+	for _, action := range s.actions {
+		ssn.Action = action.Name()
+		action.Execute(ssn)
+	}
+	decisions := ssn.Decisions
+	framework.CloseSession(ssn)
+
+	s.cache.WaitForDispatched()
+	s.applyWrites()
+
+	return decisions
+}
+
+// applyWrites applies the binds and evictions of a session to cache, as the
+// informers of a cluster do.
+func (s *Simulator) applyWrites() {
+	bound, evicted := s.writes.flush()
+
+	for _, b := range bound {
+		pod := b.pod.DeepCopy()
+		pod.Spec.NodeName = b.hostname
+		pod.Status.Phase = v1.PodRunning
+		s.cache.UpdatePod(b.pod, pod)
+	}
+
+	for _, pod := range evicted {
+		s.cache.DeletePod(pod)
+	}
+}
+
+// binding is a pod bound to hostname.
+type binding struct {
+	pod      *v1.Pod
+	hostname string
+}
+
+// writes records the binds and evictions of cache instead of API writes.
+type writes struct {
+	sync.Mutex
+	bound   []binding
+	evicted []*v1.Pod
+}
+
+func (w *writes) Bind(pod *v1.Pod, hostname string) error {
+	w.Lock()
+	defer w.Unlock()
+
+	logging.V(4).Info("Simulate binding pod", "pod", api.PodKey(pod), "node", hostname)
+	w.bound = append(w.bound, binding{pod: pod, hostname: hostname})
+	return nil
+}
+
+func (w *writes) Evict(pod *v1.Pod) error {
+	w.Lock()
+	defer w.Unlock()
+
+	logging.V(4).Info("Simulate evicting pod", "pod", api.PodKey(pod))
+	w.evicted = append(w.evicted, pod)
+	return nil
+}
+
+// flush returns the writes recorded, and resets them.
+func (w *writes) flush() ([]binding, []*v1.Pod) {
+	w.Lock()
+	defer w.Unlock()
+
+	bound, evicted := w.bound, w.evicted
+	w.bound, w.evicted = nil, nil
+	return bound, evicted
+}
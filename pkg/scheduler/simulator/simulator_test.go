@@ -0,0 +1,158 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	arbv1 "github.com/kubernetes-incubator/kube-arbitrator/pkg/apis/v1alpha1"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/api"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/audit"
+	schedcache "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/cache"
+)
+
+func buildPod(name, group string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       types.UID("default-" + name),
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{api.DefaultGroupNameLabel: group},
+		},
+		Spec: v1.PodSpec{
+			SchedulerName: "kar-scheduler",
+			Containers: []v1.Container{{
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+				},
+			}},
+		},
+		Status: v1.PodStatus{Phase: v1.PodPending},
+	}
+}
+
+func TestSimulatorRun(t *testing.T) {
+	alloc := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("8Gi"),
+	}
+	state := &schedcache.ClusterState{
+		Nodes: []*v1.Node{{
+			ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+			Status:     v1.NodeStatus{Allocatable: alloc, Capacity: alloc},
+		}},
+		SchedulingSpecs: []*arbv1.SchedulingSpec{{
+			ObjectMeta: metav1.ObjectMeta{Name: "j1", Namespace: "default"},
+			Spec:       arbv1.SchedulingSpecTemplate{MinAvailable: 2, Queue: "q1"},
+		}},
+	}
+	for i := 0; i < 2; i++ {
+		state.Pods = append(state.Pods, buildPod(fmt.Sprintf("p%d", i), "j1"))
+	}
+
+	sim, err := New(state, &Config{Sessions: 2}, "kar-scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := sim.Run()
+
+	if len(report.Placements) != 2 {
+		t.Fatalf("expected 2 placements, got %d", len(report.Placements))
+	}
+	for _, p := range report.Placements {
+		if p.Session != 1 || p.Node != "n1" {
+			t.Errorf("expected task placed on n1 in session 1, got %+v", p)
+		}
+	}
+
+	if len(report.Queues) != 1 {
+		t.Fatalf("expected 1 queue, got %d", len(report.Queues))
+	}
+	if q := report.Queues[0]; q.Name != "q1" || q.PendingJobs != 0 || q.Allocated.MilliCPU != 2000 || q.Share != 0.5 {
+		t.Errorf("expected 2 cpu allocated to q1, got %+v", q)
+	}
+	if report.Fairness != 1 {
+		t.Errorf("expected fairness 1 of one queue, got %v", report.Fairness)
+	}
+
+	if _, err := New(state, &Config{Actions: []string{"unknown"}}, "kar-scheduler"); err == nil {
+		t.Errorf("expected error of unknown action")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	alloc := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("8Gi"),
+	}
+	state := &schedcache.ClusterState{
+		Nodes: []*v1.Node{{
+			ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+			Status:     v1.NodeStatus{Allocatable: alloc, Capacity: alloc},
+		}},
+		SchedulingSpecs: []*arbv1.SchedulingSpec{{
+			ObjectMeta: metav1.ObjectMeta{Name: "j1", Namespace: "default"},
+			Spec:       arbv1.SchedulingSpecTemplate{MinAvailable: 1, Queue: "q1"},
+		}},
+		Pods: []*v1.Pod{buildPod("p0", "j1")},
+	}
+
+	sim, err := New(state, &Config{}, "kar-scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trace := &scheduler.SessionTrace{Session: "s1", State: state, Decisions: sim.runSession()}
+	if len(trace.Decisions) == 0 {
+		t.Fatalf("expected decisions of session")
+	}
+
+	report, err := Replay(trace, "kar-scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Exact() {
+		t.Errorf("expected exact replay, got missing %v and unexpected %v", report.Missing, report.Unexpected)
+	}
+
+	// The recorded bind to another node is missing in replay.
+	recorded := *trace.Decisions[0]
+	recorded.Node = "n2"
+	trace.Decisions = append([]*audit.Decision{&recorded}, trace.Decisions[1:]...)
+
+	if report, err = Replay(trace, "kar-scheduler"); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].Node != "n2" || len(report.Unexpected) != 1 {
+		t.Errorf("expected the bind to n2 missing, got missing %v and unexpected %v", report.Missing, report.Unexpected)
+	}
+}
+
+func TestJainIndex(t *testing.T) {
+	if index := jainIndex([]float64{0.2, 0.2}); index != 1 {
+		t.Errorf("expected 1 of equal shares, got %v", index)
+	}
+	if index := jainIndex([]float64{0.4, 0}); index != 0.5 {
+		t.Errorf("expected 0.5 of one queue allocated, got %v", index)
+	}
+}
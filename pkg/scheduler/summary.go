@@ -0,0 +1,119 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/audit"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+// actionSummary is the time taken by an action.
+type actionSummary struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+}
+
+// sessionSummary is what the scheduler did in a session; the durations are in
+// nanoseconds.
+type sessionSummary struct {
+	ID    string    `json:"id"`
+	Start time.Time `json:"start"`
+
+	// Jobs is the number of jobs considered by actions, and Backlog is the
+	// number of jobs skipped, e.g. suspended.
+	Jobs    int `json:"jobs"`
+	Backlog int `json:"backlog"`
+	Nodes   int `json:"nodes"`
+
+	// The number of tasks by the decisions in the session.
+	Allocated     int `json:"allocated"`
+	Evicted       int `json:"evicted"`
+	Unschedulable int `json:"unschedulable"`
+
+	OpenDuration  time.Duration   `json:"openDuration"`
+	Actions       []actionSummary `json:"actions"`
+	CloseDuration time.Duration   `json:"closeDuration"`
+	Duration      time.Duration   `json:"duration"`
+}
+
+func newSessionSummary(ssn *framework.Session, start time.Time) *sessionSummary {
+	return &sessionSummary{
+		ID:           string(ssn.ID),
+		Start:        start,
+		Jobs:         len(ssn.Jobs),
+		Backlog:      len(ssn.Backlog),
+		Nodes:        len(ssn.Nodes),
+		OpenDuration: time.Since(start),
+	}
+}
+
+func (s *sessionSummary) addAction(name string, duration time.Duration) {
+	s.Actions = append(s.Actions, actionSummary{Name: name, Duration: duration})
+}
+
+// countDecisions counts the decisions of session by type; it has to be called
+// before session closed.
+func (s *sessionSummary) countDecisions(decisions []*audit.Decision) {
+	for _, d := range decisions {
+		switch d.Type {
+		case audit.BindDecision:
+			s.Allocated++
+		case audit.EvictDecision:
+			s.Evicted++
+		case audit.UnschedulableDecision:
+			s.Unschedulable++
+		}
+	}
+}
+
+func (s *sessionSummary) finish(closeStart time.Time) {
+	now := time.Now()
+	s.CloseDuration = now.Sub(closeStart)
+	s.Duration = now.Sub(s.Start)
+}
+
+func (pc *Scheduler) recordSummary(s *sessionSummary) {
+	pc.debugMutex.Lock()
+	defer pc.debugMutex.Unlock()
+
+	pc.lastSummary = s
+}
+
+// LastSessionHandler returns the HTTP handler which shows the summary of the
+// last session, e.g. how many tasks are allocated and the time of each action.
+func (pc *Scheduler) LastSessionHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pc.debugMutex.Lock()
+		summary := pc.lastSummary
+		pc.debugMutex.Unlock()
+
+		if summary == nil {
+			http.Error(w, "no session completed", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(summary); err != nil {
+			logging.Error(err, "Failed to write last session")
+		}
+	})
+}
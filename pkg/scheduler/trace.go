@@ -0,0 +1,153 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/audit"
+	schedcache "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/cache"
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/framework"
+)
+
+// TraceFile is the file which the trace of each session is appended to, e.g.
+// to replay a production session by kar-simulator; the trace is compressed
+// by gzip if it ends with ".gz", and no trace is recorded if empty.
+var TraceFile string
+
+// SessionTrace is the inputs and decisions of a session, one JSON line in
+// TraceFile.
+type SessionTrace struct {
+	Session   string    `json:"session"`
+	Timestamp time.Time `json:"timestamp"`
+	// Config is the actions and disabled plugins of the session.
+	Config Config `json:"config"`
+	// State is the objects in cache when the session is opened.
+	State *schedcache.ClusterState `json:"state"`
+	// Decisions is the decisions of the session.
+	Decisions []*audit.Decision `json:"decisions"`
+}
+
+// newSessionTrace returns the trace of ssn, which is opened on state and
+// executes actions.
+func newSessionTrace(ssn *framework.Session, state *schedcache.ClusterState, actions []framework.Action) *SessionTrace {
+	trace := &SessionTrace{
+		Session:   string(ssn.ID),
+		Timestamp: time.Now(),
+		State:     state,
+		Decisions: ssn.Decisions,
+	}
+
+	for _, action := range actions {
+		trace.Config.Actions = append(trace.Config.Actions, action.Name())
+	}
+	for name, disabled := range framework.DisabledPlugins {
+		if disabled {
+			trace.Config.DisabledPlugins = append(trace.Config.DisabledPlugins, name)
+		}
+	}
+	sort.Strings(trace.Config.DisabledPlugins)
+
+	return trace
+}
+
+// appendTrace appends trace to the file at path as a JSON line; each trace is
+// a gzip member of its own if path ends with ".gz", so the file is still one
+// valid gzip stream.
+func appendTrace(path string, trace *SessionTrace) error {
+	data, err := json.Marshal(trace)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if !strings.HasSuffix(path, ".gz") {
+		_, err = f.Write(data)
+		return err
+	}
+
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// LoadSessionTrace loads the trace of session from the trace file at path, or
+// the first trace if session is empty.
+func LoadSessionTrace(path, session string) (*SessionTrace, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read trace %s: %v", path, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		trace := &SessionTrace{}
+		if err := dec.Decode(trace); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse trace %s: %v", path, err)
+		}
+
+		if len(session) == 0 || trace.Session == session {
+			return trace, nil
+		}
+	}
+
+	if len(session) == 0 {
+		return nil, fmt.Errorf("no session in trace %s", path)
+	}
+	return nil, fmt.Errorf("session %s not found in trace %s", session, path)
+}
+
+// recordTrace appends the trace of ssn to TraceFile; the trace is dropped if
+// it's not written.
+func (pc *Scheduler) recordTrace(ssn *framework.Session, state *schedcache.ClusterState, actions []framework.Action) {
+	if err := appendTrace(TraceFile, newSessionTrace(ssn, state, actions)); err != nil {
+		logging.Error(err, "Failed to record trace", "file", TraceFile, "session", ssn.ID)
+		return
+	}
+
+	logging.V(4).Info("Recorded trace", "file", TraceFile, "session", ssn.ID)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/audit"
+	schedcache "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/cache"
+)
+
+func TestSessionTrace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"trace.jsonl", "trace.jsonl.gz"} {
+		path := filepath.Join(dir, name)
+		for _, session := range []string{"s1", "s2"} {
+			trace := &SessionTrace{
+				Session: session,
+				Config:  Config{Actions: []string{"allocate"}},
+				State: &schedcache.ClusterState{
+					Nodes: []*v1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "n1"}}},
+				},
+				Decisions: []*audit.Decision{{Type: audit.BindDecision, Task: session + "-t1", Node: "n1"}},
+			}
+			if err := appendTrace(path, trace); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		trace, err := LoadSessionTrace(path, "s2")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if trace.Session != "s2" || len(trace.Decisions) != 1 || trace.Decisions[0].Task != "s2-t1" ||
+			len(trace.State.Nodes) != 1 || len(trace.Config.Actions) != 1 {
+			t.Errorf("%s: expected trace of s2, got %+v", name, trace)
+		}
+
+		if trace, err := LoadSessionTrace(path, ""); err != nil || trace.Session != "s1" {
+			t.Errorf("%s: expected the first trace s1, got %v, %v", name, trace, err)
+		}
+		if _, err := LoadSessionTrace(path, "s3"); err == nil {
+			t.Errorf("%s: expected error of unknown session", name)
+		}
+	}
+}
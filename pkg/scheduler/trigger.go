@@ -0,0 +1,70 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"time"
+)
+
+// runOnEvents runs a session when the cache is triggered by events, after no
+// more events in SessionDebounce or SessionMaxWait since the first event; a
+// session is also run if no events in MaxSessionInterval.
+func (pc *Scheduler) runOnEvents(stopCh <-chan struct{}) {
+	triggered := pc.cache.Triggered()
+
+	for {
+		interval := time.NewTimer(MaxSessionInterval)
+		select {
+		case <-stopCh:
+			interval.Stop()
+			return
+		case <-interval.C:
+		case <-triggered:
+			interval.Stop()
+			if !debounce(triggered, stopCh) {
+				return
+			}
+		}
+
+		pc.runOnce()
+	}
+}
+
+// debounce waits until no more events in SessionDebounce, or SessionMaxWait
+// passed; it returns false if stopped.
+func debounce(triggered <-chan struct{}, stopCh <-chan struct{}) bool {
+	maxWait := time.NewTimer(SessionMaxWait)
+	defer maxWait.Stop()
+	quiet := time.NewTimer(SessionDebounce)
+	defer quiet.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return false
+		case <-maxWait.C:
+			return true
+		case <-quiet.C:
+			return true
+		case <-triggered:
+			if !quiet.Stop() {
+				<-quiet.C
+			}
+			quiet.Reset(SessionDebounce)
+		}
+	}
+}
@@ -29,17 +29,86 @@ type PriorityQueue struct {
 type priorityQueue struct {
 	items  []interface{}
 	lessFn api.LessFn
+
+	// indexes is the index of each item in items, so an item can be fixed
+	// in place when its priority changed; the items have to be comparable,
+	// e.g. pointers.
+	indexes map[interface{}]int
 }
 
 func NewPriorityQueue(lessFn api.LessFn) *PriorityQueue {
 	return &PriorityQueue{
 		queue: priorityQueue{
-			items:  make([]interface{}, 0),
-			lessFn: lessFn,
+			items:   make([]interface{}, 0),
+			lessFn:  lessFn,
+			indexes: map[interface{}]int{},
 		},
 	}
 }
 
+// NewPriorityQueueFrom creates a PriorityQueue of items in O(n), instead of
+// O(n log n) by pushing them one by one.
+func NewPriorityQueueFrom(lessFn api.LessFn, items []interface{}) *PriorityQueue {
+	q := &PriorityQueue{
+		queue: priorityQueue{
+			items:   make([]interface{}, len(items)),
+			lessFn:  lessFn,
+			indexes: make(map[interface{}]int, len(items)),
+		},
+	}
+
+	copy(q.queue.items, items)
+	for i, it := range q.queue.items {
+		q.queue.indexes[it] = i
+	}
+	heap.Init(&q.queue)
+
+	return q
+}
+
+// Clone returns a copy of the queue in O(n), whose items are shared; it's
+// cheaper than creating a new queue of the items.
+func (q *PriorityQueue) Clone() *PriorityQueue {
+	clone := &PriorityQueue{
+		queue: priorityQueue{
+			items:   make([]interface{}, len(q.queue.items)),
+			lessFn:  q.queue.lessFn,
+			indexes: make(map[interface{}]int, len(q.queue.items)),
+		},
+	}
+
+	copy(clone.queue.items, q.queue.items)
+	for it, i := range q.queue.indexes {
+		clone.queue.indexes[it] = i
+	}
+
+	return clone
+}
+
+// Fix re-orders it in the queue after its priority changed, in O(log n);
+// it returns false if it's not in the queue.
+func (q *PriorityQueue) Fix(it interface{}) bool {
+	i, found := q.queue.indexes[it]
+	if !found {
+		return false
+	}
+
+	heap.Fix(&q.queue, i)
+	return true
+}
+
+// Remove removes it from the queue in O(log n); it returns false if it's not
+// in the queue.
+func (q *PriorityQueue) Remove(it interface{}) bool {
+	i, found := q.queue.indexes[it]
+	if !found {
+		return false
+	}
+
+	heap.Remove(&q.queue, i)
+	return true
+}
+
 func (q *PriorityQueue) Push(it interface{}) {
 	heap.Push(&q.queue, it)
 }
@@ -73,9 +142,12 @@ func (pq *priorityQueue) Less(i, j int) bool {
 
 func (pq priorityQueue) Swap(i, j int) {
 	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
+	pq.indexes[pq.items[i]] = i
+	pq.indexes[pq.items[j]] = j
 }
 
 func (pq *priorityQueue) Push(x interface{}) {
+	(*pq).indexes[x] = len((*pq).items)
 	(*pq).items = append((*pq).items, x)
 }
 
@@ -84,5 +156,6 @@ func (pq *priorityQueue) Pop() interface{} {
 	n := len(old)
 	item := old[n-1]
 	(*pq).items = old[0 : n-1]
+	delete((*pq).indexes, item)
 	return item
 }
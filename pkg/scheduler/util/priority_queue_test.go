@@ -0,0 +1,67 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+type item struct {
+	name  string
+	share int
+}
+
+func popAll(q *PriorityQueue) []string {
+	var names []string
+	for !q.Empty() {
+		names = append(names, q.Pop().(*item).name)
+	}
+	return names
+}
+
+func TestPriorityQueueFix(t *testing.T) {
+	a, b, c, d := &item{"a", 3}, &item{"b", 1}, &item{"c", 4}, &item{"d", 2}
+	lessFn := func(l, r interface{}) bool {
+		return l.(*item).share < r.(*item).share
+	}
+
+	q := NewPriorityQueueFrom(lessFn, []interface{}{a, b, c, d})
+
+	// The clone is not changed by the queue.
+	clone := q.Clone()
+
+	b.share = 5
+	if !q.Fix(b) {
+		t.Errorf("expected b in queue")
+	}
+	if !q.Remove(d) {
+		t.Errorf("expected d in queue")
+	}
+	if q.Remove(d) {
+		t.Errorf("expected d removed from queue")
+	}
+
+	if names, expected := popAll(q), []string{"a", "c", "b"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+
+	b.share = 1
+	if names, expected := popAll(clone), []string{"b", "d", "a", "c"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected clone %v, got %v", expected, names)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kubernetes-incubator/kube-arbitrator/pkg/logging"
+	schedcache "github.com/kubernetes-incubator/kube-arbitrator/pkg/scheduler/cache"
+)
+
+var (
+	// WarmRestartFile is the file which the derived state of cache, e.g. the
+	// evictions not done yet, is exported to when scheduler is terminated,
+	// and imported from when it starts leading; it should be on a volume kept
+	// across restarts. Nothing is exported if empty.
+	WarmRestartFile string
+	// WarmRestartMaxAge is the maximum age of the derived state imported; the
+	// older state is ignored, as the cluster has changed too much.
+	WarmRestartMaxAge = 5 * time.Minute
+)
+
+// importDerivedState imports the derived state in WarmRestartFile into cache,
+// and removes the file, so it's imported once.
+func (pc *Scheduler) importDerivedState() {
+	data, err := ioutil.ReadFile(WarmRestartFile)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		logging.Error(err, "Failed to read derived state", "file", WarmRestartFile)
+		return
+	}
+	if err := os.Remove(WarmRestartFile); err != nil {
+		logging.Error(err, "Failed to remove derived state", "file", WarmRestartFile)
+	}
+
+	state := &schedcache.DerivedState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		logging.Error(err, "Failed to parse derived state", "file", WarmRestartFile)
+		return
+	}
+	if age := time.Since(state.Timestamp); age > WarmRestartMaxAge {
+		logging.Warning("Ignore stale derived state", "file", WarmRestartFile, "age", age)
+		return
+	}
+
+	pc.cache.ImportDerivedState(state)
+}
+
+// exportDerivedState writes the derived state of cache into WarmRestartFile;
+// it's written into a temp file and renamed, so a partial state is never
+// imported.
+func (pc *Scheduler) exportDerivedState() error {
+	data, err := json.Marshal(pc.cache.ExportDerivedState())
+	if err != nil {
+		return err
+	}
+
+	tmp := WarmRestartFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, WarmRestartFile)
+}
+
+// HandleTerminationSignals exports the derived state of cache into
+// WarmRestartFile and exits when SIGTERM or SIGINT is received. The API
+// writes are held before exporting, so no decision is both executed and
+// exported. The scheduler without leadership exports nothing, so it does not
+// overwrite the state of the leader.
+func (pc *Scheduler) HandleTerminationSignals() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+
+	sig := <-sigCh
+	if pc.checkReady() == nil {
+		pc.cache.HoldWrites()
+		if err := pc.exportDerivedState(); err != nil {
+			logging.Error(err, "Failed to export derived state", "file", WarmRestartFile, "signal", sig)
+		} else {
+			logging.Info("Exported derived state", "file", WarmRestartFile, "signal", sig)
+		}
+	}
+
+	logging.Flush()
+	os.Exit(0)
+}
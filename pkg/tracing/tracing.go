@@ -0,0 +1,153 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing records the spans of a trace, e.g. a scheduling session, and
+// reports them to a tracing backend once the root span finished. All methods
+// of Span are no-op on nil span, which is returned if tracing is disabled.
+package tracing
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Exporter reports the finished spans of a trace to a tracing backend.
+type Exporter interface {
+	Export(spans []*Span)
+}
+
+var (
+	exporterMutex sync.RWMutex
+	exporter      Exporter
+
+	idMutex sync.Mutex
+	idRand  = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// SetExporter sets the exporter of traces; tracing is disabled if it's nil.
+func SetExporter(e Exporter) {
+	exporterMutex.Lock()
+	defer exporterMutex.Unlock()
+
+	exporter = e
+}
+
+func getExporter() Exporter {
+	exporterMutex.RLock()
+	defer exporterMutex.RUnlock()
+
+	return exporter
+}
+
+func newID() string {
+	idMutex.Lock()
+	defer idMutex.Unlock()
+
+	return fmt.Sprintf("%016x", idRand.Uint64())
+}
+
+// trace is the spans which share the same root span.
+type trace struct {
+	sync.Mutex
+
+	id       string
+	exporter Exporter
+	spans    []*Span
+}
+
+// Span is a timed operation in a trace.
+type Span struct {
+	TraceID  string
+	ID       string
+	ParentID string
+	Name     string
+	Start    time.Time
+	Duration time.Duration
+	Tags     map[string]string
+
+	trace *trace
+}
+
+// StartSpan starts the root span of a new trace; it returns nil if tracing
+// is disabled.
+func StartSpan(name string) *Span {
+	e := getExporter()
+	if e == nil {
+		return nil
+	}
+
+	t := &trace{
+		id:       newID(),
+		exporter: e,
+	}
+
+	return newSpan(t, name, "")
+}
+
+func newSpan(t *trace, name, parentID string) *Span {
+	return &Span{
+		TraceID:  t.id,
+		ID:       newID(),
+		ParentID: parentID,
+		Name:     name,
+		Start:    time.Now(),
+		Tags:     map[string]string{},
+		trace:    t,
+	}
+}
+
+// StartChild starts a child span of s.
+func (s *Span) StartChild(name string) *Span {
+	if s == nil {
+		return nil
+	}
+
+	return newSpan(s.trace, name, s.ID)
+}
+
+// SetTag sets the tag of s, e.g. the ID of session.
+func (s *Span) SetTag(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.trace.Lock()
+	defer s.trace.Unlock()
+
+	s.Tags[key] = fmt.Sprintf("%v", value)
+}
+
+// Finish ends s; the trace is exported if s is the root span, so the child
+// spans must be finished before their root.
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+
+	t := s.trace
+
+	t.Lock()
+	s.Duration = time.Since(s.Start)
+	t.spans = append(t.spans, s)
+	spans := t.spans
+	t.Unlock()
+
+	if len(s.ParentID) == 0 {
+		go t.exporter.Export(spans)
+	}
+}
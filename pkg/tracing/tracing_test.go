@@ -0,0 +1,64 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeExporter struct {
+	c chan []*Span
+}
+
+func (fe *fakeExporter) Export(spans []*Span) {
+	fe.c <- spans
+}
+
+func TestSpan(t *testing.T) {
+	SetExporter(nil)
+	if span := StartSpan("session"); span != nil {
+		t.Errorf("expected nil span if tracing is disabled, got %v", span)
+	}
+
+	exporter := &fakeExporter{c: make(chan []*Span, 1)}
+	SetExporter(exporter)
+	defer SetExporter(nil)
+
+	root := StartSpan("session")
+	root.SetTag("session", "uid")
+	child := root.StartChild("allocate")
+	child.Finish()
+	root.Finish()
+
+	var spans []*Span
+	select {
+	case spans = <-exporter.c:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("Failed to get exported spans.")
+	}
+
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Name != "allocate" || spans[0].ParentID != root.ID || spans[0].TraceID != root.TraceID {
+		t.Errorf("unexpected child span: %+v", spans[0])
+	}
+	if spans[1].Name != "session" || len(spans[1].ParentID) != 0 || spans[1].Tags["session"] != "uid" {
+		t.Errorf("unexpected root span: %+v", spans[1])
+	}
+}
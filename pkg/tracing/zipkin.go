@@ -0,0 +1,111 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// zipkinSpan is the span in Zipkin v2 JSON format, which is also accepted by
+// Jaeger collector.
+type zipkinSpan struct {
+	TraceID       string            `json:"traceId"`
+	ID            string            `json:"id"`
+	ParentID      string            `json:"parentId,omitempty"`
+	Name          string            `json:"name"`
+	Timestamp     int64             `json:"timestamp"`
+	Duration      int64             `json:"duration"`
+	LocalEndpoint zipkinEndpoint    `json:"localEndpoint"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+}
+
+// ZipkinExporter reports spans to the Zipkin v2 HTTP API.
+type ZipkinExporter struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+}
+
+// NewZipkinExporter creates a ZipkinExporter which posts the spans of
+// serviceName to endpoint, e.g. http://zipkin:9411/api/v2/spans.
+func NewZipkinExporter(endpoint, serviceName string) *ZipkinExporter {
+	return &ZipkinExporter{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Export posts spans to Zipkin; the error is logged, as tracing is
+// informative.
+func (z *ZipkinExporter) Export(spans []*Span) {
+	if err := z.export(spans); err != nil {
+		glog.Errorf("Failed to export %d spans to %s: %v", len(spans), z.endpoint, err)
+	}
+}
+
+func (z *ZipkinExporter) export(spans []*Span) error {
+	body, err := json.Marshal(z.convert(spans))
+	if err != nil {
+		return err
+	}
+
+	resp, err := z.client.Post(z.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (z *ZipkinExporter) convert(spans []*Span) []*zipkinSpan {
+	zspans := make([]*zipkinSpan, 0, len(spans))
+	for _, s := range spans {
+		// The duration of Zipkin span is at least 1 microsecond.
+		duration := int64(s.Duration / time.Microsecond)
+		if duration < 1 {
+			duration = 1
+		}
+
+		zspans = append(zspans, &zipkinSpan{
+			TraceID:       s.TraceID,
+			ID:            s.ID,
+			ParentID:      s.ParentID,
+			Name:          s.Name,
+			Timestamp:     s.Start.UnixNano() / int64(time.Microsecond),
+			Duration:      duration,
+			LocalEndpoint: zipkinEndpoint{ServiceName: z.serviceName},
+			Tags:          s.Tags,
+		})
+	}
+	return zspans
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package version is the version of the binaries of kube-arbitrator, which
+// is set at build time, e.g.
+//
+//	go build -ldflags "-X github.com/kubernetes-incubator/kube-arbitrator/pkg/version.GitVersion=v0.1.0"
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+)
+
+var (
+	// GitVersion is the version of source, e.g. the output of git describe.
+	GitVersion = "v0.0.0-unknown"
+	// GitCommit is the commit of source.
+	GitCommit = "unknown"
+	// BuildDate is when the binary is built, in RFC3339.
+	BuildDate = "unknown"
+)
+
+// Info is the version of a binary.
+type Info struct {
+	GitVersion string `json:"gitVersion"`
+	GitCommit  string `json:"gitCommit"`
+	BuildDate  string `json:"buildDate"`
+	GoVersion  string `json:"goVersion"`
+	Platform   string `json:"platform"`
+}
+
+// Get returns the version of this binary.
+func Get() Info {
+	return Info{
+		GitVersion: GitVersion,
+		GitCommit:  GitCommit,
+		BuildDate:  BuildDate,
+		GoVersion:  runtime.Version(),
+		Platform:   fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+	}
+}
+
+// String returns the version in one line.
+func (i Info) String() string {
+	return fmt.Sprintf("%s (commit %s, built %s, %s %s)",
+		i.GitVersion, i.GitCommit, i.BuildDate, i.GoVersion, i.Platform)
+}
+
+// Handler returns the HTTP handler which reports the version in JSON.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Get())
+	})
+}